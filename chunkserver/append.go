@@ -0,0 +1,190 @@
+package chunkserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/harshvardha/distributed_file_system/common"
+	pb "github.com/harshvardha/distributed_file_system/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// AppendRecord appends data to the end of a chunk, creating the chunk if needed, and returns the offset it was
+// written at. A record that doesn't fit pads the chunk with zeros to common.ChunkSize instead and reports the chunk full.
+// The chunk file is rewritten so its checksum covers the record
+func (s *Storage) AppendRecord(ctx context.Context, chunkHandle string, chunkVersion int32, data []byte) (int64, bool, error) {
+	s.appendMu.Lock()
+	defer s.appendMu.Unlock()
+
+	payload, err := s.appendTarget(chunkHandle, chunkVersion)
+	if err != nil {
+		return 0, false, err
+	}
+
+	offset := int64(len(payload))
+	if offset+int64(len(data)) > common.ChunkSize {
+		if offset < common.ChunkSize {
+			payload = append(payload, make([]byte, common.ChunkSize-offset)...)
+			if err := s.WriteChunk(ctx, chunkHandle, chunkVersion, payload, true); err != nil {
+				return 0, false, err
+			}
+		}
+		return common.ChunkSize, true, nil
+	}
+
+	if err := s.WriteChunk(ctx, chunkHandle, chunkVersion, append(payload, data...), true); err != nil {
+		return 0, false, err
+	}
+
+	return offset, false, nil
+}
+
+// WriteAt writes data into a chunk at offset, creating the chunk if needed and padding it with zeros up to offset.
+// Secondaries write appended records at the offset the primary chose this way
+func (s *Storage) WriteAt(ctx context.Context, chunkHandle string, chunkVersion int32, offset int64, data []byte) error {
+	end := offset + int64(len(data))
+	if offset < 0 || end > common.ChunkSize {
+		return fmt.Errorf("write at offset %d of %d bytes out of bounds for chunk of %d bytes", offset, len(data), common.ChunkSize)
+	}
+
+	s.appendMu.Lock()
+	defer s.appendMu.Unlock()
+
+	payload, err := s.appendTarget(chunkHandle, chunkVersion)
+	if err != nil {
+		return err
+	}
+
+	if int64(len(payload)) < end {
+		payload = append(payload, make([]byte, end-int64(len(payload)))...)
+	}
+	copy(payload[offset:], data)
+
+	return s.WriteChunk(ctx, chunkHandle, chunkVersion, payload, true)
+}
+
+// appendTarget returns the payload of a chunk a record is appended to, empty if the chunk doesn't exist yet.
+// Replicas with a newer chunk version than the append aren't appended to. The caller must hold appendMu
+func (s *Storage) appendTarget(chunkHandle string, chunkVersion int32) ([]byte, error) {
+	if !s.HasChunk(chunkHandle) {
+		return nil, nil
+	}
+
+	header, payload, err := s.readChunk(chunkHandle)
+	if err != nil {
+		return nil, err
+	}
+	if header.chunkVersion > chunkVersion {
+		return nil, fmt.Errorf("%s has version %d, append has version %d", chunkHandle, header.chunkVersion, chunkVersion)
+	}
+
+	return payload, nil
+}
+
+// RecordAppend handles record appends sent to this server as the primary holding the chunk's lease. The primary
+// picks the offset by appending locally, then writes the record at the same offset on every secondary
+func (s *Server) RecordAppend(ctx context.Context, req *pb.RecordAppendRequest) (*pb.RecordAppendResponse, error) {
+	log.Printf("Appending record to chunk: %s (%d bytes)", req.ChunkHandle, len(req.Data))
+
+	if len(req.Data) > common.MaxRecordSize {
+		return nil, status.Errorf(codes.InvalidArgument, "record of %d bytes is larger than the limit of %d bytes", len(req.Data), common.MaxRecordSize)
+	}
+	if checksum(req.Data) != req.Checksum {
+		return nil, status.Errorf(codes.DataLoss, "%v: checksum mismatch, data corrupted in transit", errCorruptChunk)
+	}
+	if err := s.authorize(req.AccessToken, req.ChunkHandle, common.ChunkWrite); err != nil {
+		return nil, err
+	}
+	if err := s.checkLease(req.ChunkHandle, req.ChunkVersion, req.LeaseExpiresAt, req.LeaseToken); err != nil {
+		log.Printf("rejecting append to chunk %s: %v", req.ChunkHandle, err)
+		return nil, err
+	}
+
+	release, err := s.beginIO(ctx)
+	if err != nil {
+		log.Printf("rejecting append to chunk %s: %v", req.ChunkHandle, err)
+		return nil, err
+	}
+	defer release()
+
+	offset, full, err := s.storage.AppendRecord(ctx, req.ChunkHandle, req.ChunkVersion, req.Data)
+	if err != nil {
+		log.Printf("failed to append to chunk %s: %v", req.ChunkHandle, err)
+		return nil, appendError(ctx, err)
+	}
+
+	// secondaries pad a full chunk too, so the next record goes to the next chunk on every replica
+	apply := &pb.ApplyAppendRequest{
+		ChunkHandle:  req.ChunkHandle,
+		ChunkVersion: req.ChunkVersion,
+		Offset:       offset,
+		Data:         req.Data,
+		Checksum:     req.Checksum,
+		AccessToken:  req.AccessToken,
+		ForwardToken: s.tokens.Sign(req.ChunkHandle, common.ChunkForward),
+	}
+	if full {
+		apply.Data = nil
+		apply.Checksum = checksum(nil)
+	}
+	_, err = s.forwardToSecondaries(ctx, req.SecondaryAddresses, func(ctx context.Context, client pb.ChunkServerClient) error {
+		_, err := client.ApplyAppend(ctx, apply)
+		return err
+	})
+	if err != nil {
+		log.Printf("failed to append to secondaries of chunk %s: %v", req.ChunkHandle, err)
+		return nil, status.Errorf(codes.Aborted, "record written at offset %d on the primary only, retry the append: %v", offset, err)
+	}
+
+	return &pb.RecordAppendResponse{
+		Offset:    offset,
+		ChunkFull: full,
+	}, nil
+}
+
+// ApplyAppend handles records forwarded by the primary of a chunk, written at the offset the primary chose
+func (s *Server) ApplyAppend(ctx context.Context, req *pb.ApplyAppendRequest) (*pb.ApplyAppendResponse, error) {
+	log.Printf("Applying append to chunk: %s (offset: %d, %d bytes)", req.ChunkHandle, req.Offset, len(req.Data))
+
+	if checksum(req.Data) != req.Checksum {
+		return &pb.ApplyAppendResponse{Success: false}, status.Errorf(codes.DataLoss, "%v: checksum mismatch, data corrupted in transit", errCorruptChunk)
+	}
+	if err := s.authorize(req.AccessToken, req.ChunkHandle, common.ChunkWrite); err != nil {
+		return &pb.ApplyAppendResponse{Success: false}, err
+	}
+	// the offset is the primary's to choose, clients append through RecordAppend
+	if err := s.checkForwarded(req.ChunkHandle, req.ForwardToken); err != nil {
+		log.Printf("rejecting append to chunk %s: %v", req.ChunkHandle, err)
+		return &pb.ApplyAppendResponse{Success: false}, err
+	}
+
+	release, err := s.beginIO(ctx)
+	if err != nil {
+		log.Printf("rejecting append to chunk %s: %v", req.ChunkHandle, err)
+		return &pb.ApplyAppendResponse{Success: false}, err
+	}
+	defer release()
+
+	if err := s.storage.WriteAt(ctx, req.ChunkHandle, req.ChunkVersion, req.Offset, req.Data); err != nil {
+		log.Printf("failed to append to chunk %s: %v", req.ChunkHandle, err)
+		return &pb.ApplyAppendResponse{Success: false}, appendError(ctx, err)
+	}
+
+	return &pb.ApplyAppendResponse{Success: true}, nil
+}
+
+// appendError converts storage errors of appends to grpc status errors
+func appendError(ctx context.Context, err error) error {
+	switch {
+	case ctx.Err() != nil:
+		return abandoned(ctx)
+	case errors.Is(err, ErrQuotaExceeded):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, errCorruptChunk):
+		return status.Error(codes.DataLoss, err.Error())
+	}
+	return err
+}
@@ -0,0 +1,162 @@
+package chunkserver
+
+import (
+	"container/list"
+	"slices"
+	"sync"
+)
+
+// chunkCache keeps the verified data of recently read chunks in memory up to a size, evicting the least
+// recently used chunks first, so hot chunks are served without touching the disk. A nil cache caches nothing
+type chunkCache struct {
+	mu        sync.Mutex
+	maxBytes  int64
+	usedBytes int64
+	entries   map[string]*list.Element // key: chunk handle, value: element of lru holding a *cachedChunk
+	lru       *list.List               // most recently used first
+
+	// bumped by every invalidation, so data read from disk before a write isn't cached after it
+	generation uint64
+
+	hits      int64
+	misses    int64
+	evictions int64
+}
+
+// cachedChunk is the header and payload of a chunk, never modified once cached
+type cachedChunk struct {
+	chunkHandle string
+	header      chunkHeader
+	data        []byte
+}
+
+// CacheStats counts the reads served by the chunk cache and what it holds
+type CacheStats struct {
+	Hits          int64
+	Misses        int64
+	Evictions     int64
+	Entries       int
+	UsedBytes     int64
+	CapacityBytes int64
+}
+
+// newChunkCache returns a cache of at most maxBytes of chunk data, nil when maxBytes is 0
+func newChunkCache(maxBytes int64) *chunkCache {
+	if maxBytes <= 0 {
+		return nil
+	}
+
+	return &chunkCache{
+		maxBytes: maxBytes,
+		entries:  make(map[string]*list.Element),
+		lru:      list.New(),
+	}
+}
+
+// get returns the cached header and payload of a chunk, counting a hit or a miss. The payload must not be modified
+func (c *chunkCache) get(chunkHandle string) (chunkHeader, []byte, bool) {
+	if c == nil {
+		return chunkHeader{}, nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, exists := c.entries[chunkHandle]
+	if !exists {
+		c.misses++
+		return chunkHeader{}, nil, false
+	}
+
+	c.hits++
+	c.lru.MoveToFront(element)
+	chunk := element.Value.(*cachedChunk)
+	return chunk.header, chunk.data, true
+}
+
+// currentGeneration returns the generation to pass to put for data read from now on
+func (c *chunkCache) currentGeneration() uint64 {
+	if c == nil {
+		return 0
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.generation
+}
+
+// put caches a chunk read from disk at generation, unless a chunk was invalidated since or the chunk is
+// larger than the whole cache. The cache keeps data, which must not be modified afterwards
+func (c *chunkCache) put(chunkHandle string, generation uint64, header chunkHeader, data []byte) {
+	if c == nil || int64(len(data)) > c.maxBytes {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+	if element, exists := c.entries[chunkHandle]; exists {
+		c.remove(element)
+	}
+
+	data = slices.Clip(data)
+	c.entries[chunkHandle] = c.lru.PushFront(&cachedChunk{chunkHandle: chunkHandle, header: header, data: data})
+	c.usedBytes += int64(len(data))
+
+	for c.usedBytes > c.maxBytes {
+		c.remove(c.lru.Back())
+		c.evictions++
+	}
+}
+
+// invalidate drops a chunk that was written or removed from the cache
+func (c *chunkCache) invalidate(chunkHandles ...string) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	for _, chunkHandle := range chunkHandles {
+		if element, exists := c.entries[chunkHandle]; exists {
+			c.remove(element)
+		}
+	}
+}
+
+// remove drops an element of the lru list. The caller must hold the lock
+func (c *chunkCache) remove(element *list.Element) {
+	chunk := c.lru.Remove(element).(*cachedChunk)
+	delete(c.entries, chunk.chunkHandle)
+	c.usedBytes -= int64(len(chunk.data))
+}
+
+// stats returns the counters of the cache, all zero for a nil cache
+func (c *chunkCache) stats() CacheStats {
+	if c == nil {
+		return CacheStats{}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return CacheStats{
+		Hits:          c.hits,
+		Misses:        c.misses,
+		Evictions:     c.evictions,
+		Entries:       len(c.entries),
+		UsedBytes:     c.usedBytes,
+		CapacityBytes: c.maxBytes,
+	}
+}
+
+// CacheStats returns the hit and miss counts and the contents of the chunk cache, all zero when it is off
+func (s *Storage) CacheStats() CacheStats {
+	return s.cache.stats()
+}
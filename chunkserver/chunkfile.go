@@ -0,0 +1,197 @@
+package chunkserver
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+
+	"github.com/harshvardha/distributed_file_system/common"
+)
+
+// Chunk file layout, all integers big endian:
+//
+//	offset  size  field
+//	0       4     magic ("DFSC")
+//	4       2     format version
+//	6       2     header size, lets newer formats append header fields
+//	8       4     chunk version assigned by the master
+//	12      8     payload length
+//	20      4     CRC-32C checksum of the payload
+//	24      ...   payload
+const (
+	chunkMagic         uint32 = 0x44465343
+	chunkFormatVersion uint16 = 1
+	chunkHeaderSize           = 24
+)
+
+// errCorruptChunk is returned when a chunk file fails header, length or checksum verification
+var errCorruptChunk = errors.New("chunk corrupted")
+
+// errChunkNotFound is returned for chunks the server doesn't store
+var errChunkNotFound = errors.New("chunk not found")
+
+// castagnoliTable is used for chunk payload checksums
+var castagnoliTable = crc32.MakeTable(crc32.Castagnoli)
+
+// chunkHeader is the metadata stored in front of every chunk payload on disk
+type chunkHeader struct {
+	formatVersion uint16
+	headerSize    uint16
+	chunkVersion  int32
+	dataLength    uint64
+	checksum      uint32
+}
+
+// checksum computes the checksum stored in chunk headers
+func checksum(data []byte) uint32 {
+	return common.Checksum(data)
+}
+
+// encodeChunk prepends the chunk header to the payload
+func encodeChunk(chunkVersion int32, data []byte) []byte {
+	buf := make([]byte, chunkHeaderSize+len(data))
+	binary.BigEndian.PutUint32(buf[0:4], chunkMagic)
+	binary.BigEndian.PutUint16(buf[4:6], chunkFormatVersion)
+	binary.BigEndian.PutUint16(buf[6:8], chunkHeaderSize)
+	binary.BigEndian.PutUint32(buf[8:12], uint32(chunkVersion))
+	binary.BigEndian.PutUint64(buf[12:20], uint64(len(data)))
+	binary.BigEndian.PutUint32(buf[20:24], checksum(data))
+	copy(buf[chunkHeaderSize:], data)
+
+	return buf
+}
+
+// parseChunkHeader parses and validates the fixed part of a chunk header
+func parseChunkHeader(buf []byte) (chunkHeader, error) {
+	if len(buf) < chunkHeaderSize {
+		return chunkHeader{}, fmt.Errorf("%w: file shorter than header", errCorruptChunk)
+	}
+
+	if binary.BigEndian.Uint32(buf[0:4]) != chunkMagic {
+		return chunkHeader{}, fmt.Errorf("%w: bad magic number", errCorruptChunk)
+	}
+
+	header := chunkHeader{
+		formatVersion: binary.BigEndian.Uint16(buf[4:6]),
+		headerSize:    binary.BigEndian.Uint16(buf[6:8]),
+		chunkVersion:  int32(binary.BigEndian.Uint32(buf[8:12])),
+		dataLength:    binary.BigEndian.Uint64(buf[12:20]),
+		checksum:      binary.BigEndian.Uint32(buf[20:24]),
+	}
+
+	if header.formatVersion > chunkFormatVersion {
+		return chunkHeader{}, fmt.Errorf("unsupported chunk format version %d", header.formatVersion)
+	}
+
+	if header.headerSize < chunkHeaderSize {
+		return chunkHeader{}, fmt.Errorf("%w: bad header size %d", errCorruptChunk, header.headerSize)
+	}
+
+	return header, nil
+}
+
+// decodeChunk validates a chunk file and returns its header and payload
+func decodeChunk(buf []byte) (chunkHeader, []byte, error) {
+	header, err := parseChunkHeader(buf)
+	if err != nil {
+		return chunkHeader{}, nil, err
+	}
+
+	data := buf[header.headerSize:]
+	if uint64(len(data)) != header.dataLength {
+		return chunkHeader{}, nil, fmt.Errorf("%w: expected %d bytes of data, found %d", errCorruptChunk, header.dataLength, len(data))
+	}
+
+	if checksum(data) != header.checksum {
+		return chunkHeader{}, nil, fmt.Errorf("%w: checksum mismatch", errCorruptChunk)
+	}
+
+	return header, data, nil
+}
+
+// hasChunkHeader checks whether the file at path starts with a chunk header,
+// files without one were written before the chunk file format existed
+func hasChunkHeader(path string) (bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	buf := make([]byte, 4)
+	if _, err := io.ReadFull(file, buf); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return binary.BigEndian.Uint32(buf) == chunkMagic, nil
+}
+
+// upgradeLegacyChunk rewrites a headerless chunk file in the current chunk file format
+func upgradeLegacyChunk(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(context.Background(), path, encodeChunk(1, data), true)
+}
+
+// readChunkHeader reads and parses the header of the chunk file at path
+func readChunkHeader(path string) (chunkHeader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return chunkHeader{}, err
+	}
+	defer file.Close()
+
+	buf := make([]byte, chunkHeaderSize)
+	if _, err := io.ReadFull(file, buf); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return chunkHeader{}, fmt.Errorf("%w: file shorter than header", errCorruptChunk)
+		}
+		return chunkHeader{}, err
+	}
+
+	return parseChunkHeader(buf)
+}
+
+// checkChunkLength verifies the chunk file at path is as long as its header claims,
+// catching chunks truncated by a crash without reading the whole payload
+func checkChunkLength(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	buf := make([]byte, chunkHeaderSize)
+	if _, err := io.ReadFull(file, buf); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return fmt.Errorf("%w: file shorter than header", errCorruptChunk)
+		}
+		return err
+	}
+
+	header, err := parseChunkHeader(buf)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	if expected := int64(header.headerSize) + int64(header.dataLength); info.Size() != expected {
+		return fmt.Errorf("%w: expected %d bytes, found %d", errCorruptChunk, expected, info.Size())
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package chunkserver
+
+import "sync"
+
+// chunkLock is the lock of a single chunk handle, shared by every operation currently using the chunk
+type chunkLock struct {
+	mu   sync.Mutex
+	refs int // operations holding or waiting for the lock
+}
+
+// chunkLocks hands out per chunk handle locks so writes to the same chunk don't interleave, while
+// writes to different chunks run in parallel. Locks exist only while someone uses them
+type chunkLocks struct {
+	mu    sync.Mutex
+	locks map[string]*chunkLock // key: chunk handle, value: lock of the chunk
+}
+
+// newChunkLocks creates a new chunk lock manager
+func newChunkLocks() *chunkLocks {
+	return &chunkLocks{
+		locks: make(map[string]*chunkLock),
+	}
+}
+
+// Lock takes the lock of a chunk and returns the function releasing it
+func (l *chunkLocks) Lock(chunkHandle string) func() {
+	l.mu.Lock()
+	lock, exists := l.locks[chunkHandle]
+	if !exists {
+		lock = &chunkLock{}
+		l.locks[chunkHandle] = lock
+	}
+	lock.refs++
+	l.mu.Unlock()
+
+	lock.mu.Lock()
+
+	return func() {
+		lock.mu.Unlock()
+
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		lock.refs--
+		if lock.refs == 0 {
+			delete(l.locks, chunkHandle)
+		}
+	}
+}
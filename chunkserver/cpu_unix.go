@@ -0,0 +1,18 @@
+//go:build !windows
+
+package chunkserver
+
+import (
+	"syscall"
+	"time"
+)
+
+// processCPUTime returns the user and system cpu time consumed by the chunk server process
+func processCPUTime() (time.Duration, error) {
+	var usage syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &usage); err != nil {
+		return 0, err
+	}
+
+	return time.Duration(usage.Utime.Nano() + usage.Stime.Nano()), nil
+}
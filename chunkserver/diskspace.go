@@ -0,0 +1,8 @@
+package chunkserver
+
+// diskSpace describes the filesystem a storage directory lives on
+type diskSpace struct {
+	total        int64
+	free         int64
+	filesystemID string // identifies directories sharing the same filesystem
+}
@@ -0,0 +1,28 @@
+//go:build !windows
+
+package chunkserver
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+)
+
+// getDiskSpace returns the size and free space of the filesystem holding path
+func getDiskSpace(path string) (diskSpace, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return diskSpace{}, err
+	}
+
+	return diskSpace{
+		total:        int64(stat.Blocks) * int64(stat.Bsize),
+		free:         int64(stat.Bavail) * int64(stat.Bsize),
+		filesystemID: fmt.Sprint(stat.Fsid),
+	}, nil
+}
+
+// isDiskError reports whether err means the underlying disk is failing rather than a single file problem
+func isDiskError(err error) bool {
+	return errors.Is(err, syscall.EIO) || errors.Is(err, syscall.EROFS) || errors.Is(err, syscall.ENXIO) || errors.Is(err, syscall.ENODEV)
+}
@@ -0,0 +1,40 @@
+//go:build windows
+
+package chunkserver
+
+import (
+	"errors"
+	"path/filepath"
+
+	"golang.org/x/sys/windows"
+)
+
+// getDiskSpace returns the size and free space of the volume holding path
+func getDiskSpace(path string) (diskSpace, error) {
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return diskSpace{}, err
+	}
+
+	var freeBytesAvailable, totalBytes, totalFreeBytes uint64
+	if err := windows.GetDiskFreeSpaceEx(pathPtr, &freeBytesAvailable, &totalBytes, &totalFreeBytes); err != nil {
+		return diskSpace{}, err
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		absPath = path
+	}
+
+	return diskSpace{
+		total:        int64(totalBytes),
+		free:         int64(freeBytesAvailable),
+		filesystemID: filepath.VolumeName(absPath),
+	}, nil
+}
+
+// isDiskError reports whether err means the underlying disk is failing rather than a single file problem
+func isDiskError(err error) bool {
+	return errors.Is(err, windows.ERROR_CRC) || errors.Is(err, windows.ERROR_IO_DEVICE) || errors.Is(err, windows.ERROR_NOT_READY) ||
+		errors.Is(err, windows.ERROR_DEVICE_NOT_CONNECTED) || errors.Is(err, windows.ERROR_WRITE_PROTECT)
+}
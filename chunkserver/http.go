@@ -0,0 +1,106 @@
+package chunkserver
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// StartHTTP serves the liveness and readiness endpoints for orchestrators that can't speak grpc health,
+// and the chunk cache metrics
+func (s *Server) StartHTTP(httpAddress string) error {
+	mux := http.NewServeMux()
+
+	// liveness: the process is up and able to answer
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+
+	// readiness: storage is initialized, the grpc server is accepting requests and the master is reachable
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		if s.storage == nil {
+			http.Error(w, "storage not initialized", http.StatusServiceUnavailable)
+			return
+		}
+
+		if !s.ready.Load() {
+			http.Error(w, "grpc server not started", http.StatusServiceUnavailable)
+			return
+		}
+
+		if err := s.checkMaster(r.Context()); err != nil {
+			http.Error(w, fmt.Sprintf("master unreachable: %v", err), http.StatusServiceUnavailable)
+			return
+		}
+
+		fmt.Fprintln(w, "ready")
+	})
+
+	// chunk cache and rpc counters in the prometheus text format
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		if s.storage == nil {
+			http.Error(w, "storage not initialized", http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		writeCacheMetrics(w, s.storage.CacheStats())
+		s.interceptors.WriteMetrics(w, "dfs_chunkserver")
+	})
+
+	log.Printf("Chunk server http endpoints starting on %s", httpAddress)
+
+	if err := http.ListenAndServe(httpAddress, mux); err != nil {
+		return fmt.Errorf("failed to serve http: %v", err)
+	}
+
+	return nil
+}
+
+// writeCacheMetrics writes the chunk cache counters as prometheus metrics
+func writeCacheMetrics(w io.Writer, stats CacheStats) {
+	metrics := []struct {
+		name, kind, help string
+		value            int64
+	}{
+		{"dfs_chunkserver_cache_hits_total", "counter", "Chunk reads served from the chunk cache.", stats.Hits},
+		{"dfs_chunkserver_cache_misses_total", "counter", "Chunk reads that went to disk.", stats.Misses},
+		{"dfs_chunkserver_cache_evictions_total", "counter", "Chunks evicted from the chunk cache to make room.", stats.Evictions},
+		{"dfs_chunkserver_cache_entries", "gauge", "Chunks held in the chunk cache.", int64(stats.Entries)},
+		{"dfs_chunkserver_cache_bytes", "gauge", "Bytes of chunk data held in the chunk cache.", stats.UsedBytes},
+		{"dfs_chunkserver_cache_capacity_bytes", "gauge", "Size limit of the chunk cache, 0 when it is off.", stats.CapacityBytes},
+	}
+
+	for _, metric := range metrics {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %d\n", metric.name, metric.help, metric.name, metric.kind, metric.name, metric.value)
+	}
+}
+
+// checkMaster checks the master is reachable using its grpc health service
+func (s *Server) checkMaster(ctx context.Context) error {
+	conn, err := grpc.NewClient(s.masterAddress, s.conn.DialOptions()...)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	response, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		return err
+	}
+
+	if response.Status != healthpb.HealthCheckResponse_SERVING {
+		return fmt.Errorf("master status is %s", response.Status)
+	}
+
+	return nil
+}
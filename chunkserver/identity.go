@@ -0,0 +1,90 @@
+package chunkserver
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// files kept at the top of every storage directory, next to the chunk shard directories
+const (
+	lockFileName     = ".dfs-lock"      // locked while a chunk server uses the directory
+	serverIDFileName = ".dfs-server-id" // identity of the chunk server owning the directory
+)
+
+// isStorageMetadataFile reports whether name is one of the files the storage keeps next to the chunks
+func isStorageMetadataFile(name string) bool {
+	return name == lockFileName || name == serverIDFileName
+}
+
+// lockStorageDirs locks every storage directory for this process, failing if another
+// chunk server process already uses one of them. The locks are held until the files are closed
+func lockStorageDirs(storagePaths []string) ([]*os.File, error) {
+	locks := make([]*os.File, 0, len(storagePaths))
+	for _, storagePath := range storagePaths {
+		file, err := os.OpenFile(filepath.Join(storagePath, lockFileName), os.O_RDWR|os.O_CREATE, 0644)
+		if err != nil {
+			closeAll(locks)
+			return nil, fmt.Errorf("failed to open lock file of %s: %v", storagePath, err)
+		}
+
+		if err := lockFile(file); err != nil {
+			file.Close()
+			closeAll(locks)
+			return nil, fmt.Errorf("storage directory %s is in use by another chunk server process: %v", storagePath, err)
+		}
+		locks = append(locks, file)
+	}
+
+	return locks, nil
+}
+
+func closeAll(files []*os.File) {
+	for _, file := range files {
+		file.Close()
+	}
+}
+
+// loadServerID returns the identity recorded in the storage directories, creating one for a new server.
+// Directories that have none yet, e.g. a newly added disk, get the server's identity written to them
+func loadServerID(storagePaths []string) (string, error) {
+	serverID := ""
+	owner := ""
+	for _, storagePath := range storagePaths {
+		data, err := os.ReadFile(filepath.Join(storagePath, serverIDFileName))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to read server id of %s: %v", storagePath, err)
+		}
+
+		id := strings.TrimSpace(string(data))
+		if serverID != "" && id != serverID {
+			return "", fmt.Errorf("storage directories %s and %s belong to different chunk servers (%s and %s)", owner, storagePath, serverID, id)
+		}
+		serverID, owner = id, storagePath
+	}
+
+	if serverID == "" {
+		buf := make([]byte, 16)
+		rand.Read(buf)
+		serverID = hex.EncodeToString(buf)
+	}
+
+	for _, storagePath := range storagePaths {
+		path := filepath.Join(storagePath, serverIDFileName)
+		if _, err := os.Stat(path); err == nil {
+			continue
+		}
+		if err := os.WriteFile(path, []byte(serverID+"\n"), 0644); err != nil {
+			return "", fmt.Errorf("failed to write server id to %s: %v", storagePath, err)
+		}
+	}
+
+	return serverID, nil
+}
@@ -0,0 +1,70 @@
+package chunkserver
+
+import (
+	"context"
+	"sync/atomic"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ioLimiter caps the number of disk operations running at once, queueing a bounded
+// number of operations behind them and rejecting the rest
+type ioLimiter struct {
+	slots     chan struct{}
+	queued    atomic.Int64
+	maxQueued int64
+}
+
+// newIOLimiter creates a limiter running maxConcurrent operations at once with up to maxQueued waiting.
+// A maxConcurrent of 0 or less disables the limit
+func newIOLimiter(maxConcurrent, maxQueued int) *ioLimiter {
+	if maxConcurrent <= 0 {
+		return nil
+	}
+
+	return &ioLimiter{
+		slots:     make(chan struct{}, maxConcurrent),
+		maxQueued: int64(maxQueued),
+	}
+}
+
+// pressure reports how close the limiter is to rejecting operations, from 0 with free slots
+// to 1 with a full queue. Clients slow down their writes as it rises
+func (l *ioLimiter) pressure() float32 {
+	if l == nil || l.maxQueued <= 0 {
+		return 0
+	}
+
+	return min(float32(l.queued.Load())/float32(l.maxQueued), 1)
+}
+
+// acquire waits for a free slot and returns the function releasing it. It fails with
+// ResourceExhausted when the queue is full, and with the context error if ctx ends while queued
+func (l *ioLimiter) acquire(ctx context.Context) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+
+	release := func() { <-l.slots }
+
+	// taking a free slot right away when there is one
+	select {
+	case l.slots <- struct{}{}:
+		return release, nil
+	default:
+	}
+
+	if l.queued.Add(1) > l.maxQueued {
+		l.queued.Add(-1)
+		return nil, status.Errorf(codes.ResourceExhausted, "chunk server overloaded: %d operations running and %d queued", cap(l.slots), l.maxQueued)
+	}
+	defer l.queued.Add(-1)
+
+	select {
+	case l.slots <- struct{}{}:
+		return release, nil
+	case <-ctx.Done():
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+}
@@ -0,0 +1,100 @@
+//go:build !windows
+
+package chunkserver
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestIOLimiterRunsReadsDuringSlowWrite(t *testing.T) {
+	storage, err := NewStorage([]string{t.TempDir()}, Config{SyncMode: SyncNone})
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	defer storage.Close()
+
+	ctx := context.Background()
+	data := []byte("written before the slow write")
+	if err := storage.WriteChunk(ctx, "ready-chunk", 1, data, false); err != nil {
+		t.Fatalf("failed to write chunk: %v", err)
+	}
+
+	// a fifo in place of the temp file blocks the write opening it until the test reads it, like a stalled disk
+	fifoPath := chunkPath(storage.storagePaths[0], "stuck-chunk") + tempChunkSuffix
+	if err := os.MkdirAll(filepath.Dir(fifoPath), 0755); err != nil {
+		t.Fatalf("failed to create shard directory: %v", err)
+	}
+	if err := syscall.Mkfifo(fifoPath, 0644); err != nil {
+		t.Fatalf("failed to create fifo: %v", err)
+	}
+
+	limiter := newIOLimiter(2, 0)
+	written := make(chan error, 1)
+	go func() {
+		release, err := limiter.acquire(ctx)
+		if err != nil {
+			written <- err
+			return
+		}
+		defer release()
+		written <- storage.WriteChunk(ctx, "stuck-chunk", 1, []byte("slow"), false)
+	}()
+
+	// waiting for the write to reserve its space, after which it only waits for the disk
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		storage.mu.RLock()
+		reserved := storage.reserved
+		storage.mu.RUnlock()
+		if reserved > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("slow write never started")
+		}
+	}
+
+	read := make(chan error, 1)
+	go func() {
+		release, err := limiter.acquire(ctx)
+		if err != nil {
+			read <- err
+			return
+		}
+		defer release()
+
+		readData, err := storage.ReadChunk("ready-chunk")
+		if err == nil && !bytes.Equal(readData, data) {
+			t.Errorf("read %q, expected %q", readData, data)
+		}
+		read <- err
+	}()
+
+	select {
+	case err := <-read:
+		if err != nil {
+			t.Fatalf("failed to read chunk during slow write: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("read of another chunk waited for the slow write")
+	}
+
+	fifo, err := os.Open(fifoPath)
+	if err != nil {
+		t.Fatalf("failed to open fifo: %v", err)
+	}
+	defer fifo.Close()
+	if _, err := io.Copy(io.Discard, fifo); err != nil {
+		t.Fatalf("failed to drain fifo: %v", err)
+	}
+
+	if err := <-written; err != nil {
+		t.Fatalf("slow write failed: %v", err)
+	}
+}
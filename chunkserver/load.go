@@ -0,0 +1,130 @@
+package chunkserver
+
+import (
+	"context"
+	"log"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	pb "github.com/harshvardha/distributed_file_system/proto"
+	"google.golang.org/grpc/stats"
+)
+
+// loadTracker measures how busy the chunk server is, reported to the master with every heartbeat.
+// It also implements stats.Handler to count the bytes the grpc server sends and receives
+type loadTracker struct {
+	ops      atomic.Int64 // chunk reads and writes started since the last sample
+	pending  atomic.Int64 // chunk reads and writes running or waiting for a slot
+	bytesIn  atomic.Int64 // bytes received since the last sample
+	bytesOut atomic.Int64 // bytes sent since the last sample
+
+	mu         sync.Mutex
+	lastSample time.Time
+	lastCPU    time.Duration
+
+	readsMu    sync.Mutex
+	chunkReads map[string]int64 // key: chunk handle, value: reads since the last heartbeat
+}
+
+// newLoadTracker creates a new load tracker
+func newLoadTracker() *loadTracker {
+	cpu, err := processCPUTime()
+	if err != nil {
+		log.Printf("Warning: failed to read process cpu time: %v", err)
+	}
+
+	return &loadTracker{
+		lastSample: time.Now(),
+		lastCPU:    cpu,
+		chunkReads: make(map[string]int64),
+	}
+}
+
+// recordRead counts a read of a chunk, letting the master find heavily read chunks
+func (t *loadTracker) recordRead(chunkHandle string) {
+	t.readsMu.Lock()
+	defer t.readsMu.Unlock()
+
+	t.chunkReads[chunkHandle]++
+}
+
+// takeChunkReads returns the reads per chunk since the previous call and resets the counts
+func (t *loadTracker) takeChunkReads() map[string]int64 {
+	t.readsMu.Lock()
+	defer t.readsMu.Unlock()
+
+	reads := t.chunkReads
+	t.chunkReads = make(map[string]int64)
+
+	return reads
+}
+
+// sample returns the load since the previous sample and starts a new sampling period
+func (t *loadTracker) sample() *pb.LoadMetrics {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	elapsed := now.Sub(t.lastSample).Seconds()
+	if elapsed <= 0 {
+		elapsed = 1
+	}
+
+	load := &pb.LoadMetrics{
+		Iops:                  float64(t.ops.Swap(0)) / elapsed,
+		QueueDepth:            int32(t.pending.Load()),
+		NetworkInBytesPerSec:  float64(t.bytesIn.Swap(0)) / elapsed,
+		NetworkOutBytesPerSec: float64(t.bytesOut.Swap(0)) / elapsed,
+	}
+
+	if cpu, err := processCPUTime(); err == nil {
+		load.CpuUtilization = (cpu - t.lastCPU).Seconds() / elapsed / float64(runtime.NumCPU())
+		t.lastCPU = cpu
+	}
+	t.lastSample = now
+
+	return load
+}
+
+// TagRPC implements stats.Handler
+func (t *loadTracker) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+// HandleRPC implements stats.Handler, counting payload bytes on the wire
+func (t *loadTracker) HandleRPC(_ context.Context, rpcStats stats.RPCStats) {
+	switch payload := rpcStats.(type) {
+	case *stats.InPayload:
+		t.bytesIn.Add(int64(payload.WireLength))
+	case *stats.OutPayload:
+		t.bytesOut.Add(int64(payload.WireLength))
+	}
+}
+
+// TagConn implements stats.Handler
+func (t *loadTracker) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+// HandleConn implements stats.Handler
+func (t *loadTracker) HandleConn(context.Context, stats.ConnStats) {}
+
+// beginIO waits for an IO slot for a chunk read or write, counting the operation towards the
+// load reported to the master, and returns the function ending it
+func (s *Server) beginIO(ctx context.Context) (func(), error) {
+	s.load.pending.Add(1)
+
+	release, err := s.io.Load().acquire(ctx)
+	if err != nil {
+		s.load.pending.Add(-1)
+		return nil, err
+	}
+	s.load.ops.Add(1)
+
+	return func() {
+		release()
+		s.load.pending.Add(-1)
+	}, nil
+}
@@ -0,0 +1,14 @@
+//go:build !windows
+
+package chunkserver
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockFile takes an exclusive lock on file without waiting, released when the file is closed
+// or the process exits
+func lockFile(file *os.File) error {
+	return syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+}
@@ -0,0 +1,102 @@
+package chunkserver
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/harshvardha/distributed_file_system/common"
+	pb "github.com/harshvardha/distributed_file_system/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// checkLease rejects writes sent to this server as primary unless the master granted it the lease on the chunk
+// version and the lease hasn't expired, another replica may be primary by then. With a chunk token key the lease
+// token the master signed is verified, without one the lease expiry the client passed on is all there is to check
+func (s *Server) checkLease(chunkHandle string, chunkVersion int32, leaseExpiresAt int64, leaseToken string) error {
+	if s.tokens != nil {
+		if err := s.tokens.VerifyLease(leaseToken, chunkHandle, s.address, chunkVersion); err != nil {
+			return status.Errorf(codes.FailedPrecondition, "%v", err)
+		}
+		return nil
+	}
+
+	if leaseExpiresAt == 0 {
+		return status.Errorf(codes.FailedPrecondition, "write to chunk %s carries no lease", chunkHandle)
+	}
+
+	if expires := time.Unix(0, leaseExpiresAt); !time.Now().Before(expires) {
+		return status.Errorf(codes.FailedPrecondition, "lease on chunk %s expired at %s", chunkHandle, expires.Format(time.TimeOnly))
+	}
+
+	return nil
+}
+
+// checkWriteOrder rejects chunk writes that bypass the chunk's primary. Writes carrying a forward token come from
+// the primary or the master, all others must come under the lease. Without a chunk token key nothing tells
+// forwarded writes apart, so only writes claiming a lease are checked
+func (s *Server) checkWriteOrder(req *pb.WriteChunkRequest, chunkVersion int32) error {
+	if s.tokens != nil && req.ForwardToken != "" {
+		return s.checkForwarded(req.ChunkHandle, req.ForwardToken)
+	}
+	if s.tokens == nil && len(req.SecondaryAddresses) == 0 && req.LeaseExpiresAt == 0 && req.LeaseToken == "" {
+		return nil
+	}
+
+	return s.checkLease(req.ChunkHandle, chunkVersion, req.LeaseExpiresAt, req.LeaseToken)
+}
+
+// checkForwarded rejects writes without a forward token signed for the chunk, which only the primary holding the
+// chunk's lease and the master hand out. Without a chunk token key there are no forward tokens and nothing to check
+func (s *Server) checkForwarded(chunkHandle, forwardToken string) error {
+	if err := s.tokens.Verify(forwardToken, chunkHandle, common.ChunkForward); err != nil {
+		return status.Errorf(codes.FailedPrecondition, "write to chunk %s was not forwarded by its primary: %v", chunkHandle, err)
+	}
+
+	return nil
+}
+
+// forwardToSecondaries runs call against every secondary concurrently once the primary applied a write.
+// It returns the secondaries call failed on and the last failure
+func (s *Server) forwardToSecondaries(ctx context.Context, secondaryAddresses []string, call func(context.Context, pb.ChunkServerClient) error) ([]string, error) {
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		failed  []string
+		lastErr error
+	)
+	for _, address := range secondaryAddresses {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			err := s.callPeer(ctx, address, call)
+			if err != nil {
+				log.Printf("Warning: failed to forward write to secondary %s: %v", address, err)
+
+				mu.Lock()
+				failed = append(failed, address)
+				lastErr = fmt.Errorf("failed to forward write to %s: %v", address, err)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	return failed, lastErr
+}
+
+// callPeer runs call against another chunk server
+func (s *Server) callPeer(ctx context.Context, peerAddress string, call func(context.Context, pb.ChunkServerClient) error) error {
+	conn, err := grpc.NewClient(peerAddress, s.conn.DialOptions()...)
+	if err != nil {
+		return fmt.Errorf("failed to connect to chunk server %s: %v", peerAddress, err)
+	}
+	defer conn.Close()
+
+	return call(ctx, pb.NewChunkServerClient(conn))
+}
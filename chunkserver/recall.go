@@ -0,0 +1,154 @@
+package chunkserver
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"github.com/harshvardha/distributed_file_system/common"
+	pb "github.com/harshvardha/distributed_file_system/proto"
+)
+
+// RecallChunks handles requests from the master to decompress the chunks of an archived file into new chunks,
+// so the master isn't busy decompressing while it serves requests
+func (s *Server) RecallChunks(ctx context.Context, req *pb.RecallChunksRequest) (*pb.RecallChunksResponse, error) {
+	log.Printf("Recalling %d archived chunks into %d chunks", len(req.ArchivedChunks), len(req.Chunks))
+
+	// only the master hands out write tokens of chunks that don't exist yet, the archived chunks are read with
+	// tokens the servers holding them check
+	for _, chunk := range req.Chunks {
+		if err := s.authorize(chunk.AccessToken, chunk.ChunkHandle, common.ChunkWrite); err != nil {
+			return nil, err
+		}
+	}
+
+	// no turn at the disk is held while decompressing, the chunks are written with WriteChunk, to this server
+	// too, each taking its own turn
+	started := time.Now()
+	decompressor, err := gzip.NewReader(&archiveReader{ctx: ctx, server: s, chunks: req.ArchivedChunks, size: req.ArchivedSize})
+	if err != nil {
+		return nil, recallError(ctx, fmt.Errorf("failed to read archive: %v", err))
+	}
+
+	response := &pb.RecallChunksResponse{}
+	data := make([]byte, common.ChunkSize)
+	for i, chunk := range req.Chunks {
+		length := common.ChunkLength(req.Filesize, i)
+		if _, err := io.ReadFull(decompressor, data[:length]); err != nil {
+			return nil, recallError(ctx, fmt.Errorf("failed to decompress chunk %d: %v", i, err))
+		}
+
+		written := s.writeRecalledChunk(ctx, chunk, int32(i), data[:length])
+		if len(written) == 0 {
+			return nil, recallError(ctx, fmt.Errorf("failed to write chunk %s on any server", chunk.ChunkHandle))
+		}
+		response.Chunks = append(response.Chunks, &pb.RecalledChunk{
+			ChunkHandle:  chunk.ChunkHandle,
+			ChunkVersion: chunk.ChunkVersion,
+			Addresses:    written,
+		})
+	}
+
+	// reading to the end checks the archive's checksum, and that it holds no more than the file
+	if extra, err := io.Copy(io.Discard, decompressor); err != nil || extra > 0 {
+		if err == nil {
+			err = fmt.Errorf("archive holds %d bytes more than the expected %d", extra, req.Filesize)
+		}
+		return nil, recallError(ctx, err)
+	}
+
+	log.Printf("Recalled %d bytes into %d chunks in %v", req.Filesize, len(req.Chunks), time.Since(started).Round(time.Millisecond))
+	return response, nil
+}
+
+// writeRecalledChunk writes a recalled chunk to each of its servers, returning those it was written to
+func (s *Server) writeRecalledChunk(ctx context.Context, chunk *pb.RecalledChunk, chunkIndex int32, data []byte) []string {
+	checksum := common.Checksum(data)
+
+	var written []string
+	for _, address := range chunk.Addresses {
+		err := s.callPeer(ctx, address, func(ctx context.Context, client pb.ChunkServerClient) error {
+			_, err := client.WriteChunk(ctx, &pb.WriteChunkRequest{
+				ChunkHandle:  chunk.ChunkHandle,
+				Data:         data,
+				ChunkIndex:   chunkIndex,
+				ChunkVersion: chunk.ChunkVersion,
+				Checksum:     &checksum,
+				AccessToken:  chunk.AccessToken,
+				ForwardToken: s.tokens.Sign(chunk.ChunkHandle, common.ChunkForward), // run for the master, which placed the chunk
+			})
+			return err
+		})
+		if err != nil {
+			log.Printf("Warning: failed to write recalled chunk %s to %s: %v", chunk.ChunkHandle, address, err)
+			continue
+		}
+		written = append(written, address)
+	}
+
+	return written
+}
+
+// recallError reports a recall stopped by the master giving up as abandoned
+func recallError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return abandoned(ctx)
+	}
+
+	log.Printf("failed to recall archived chunks: %v", err)
+	return err
+}
+
+// archiveReader reads the archived chunks one after the other from the servers holding them, cut to the
+// archive's size
+type archiveReader struct {
+	ctx     context.Context
+	server  *Server
+	chunks  []*pb.ArchivedChunk
+	size    int64 // compressed bytes held by the chunks
+	index   int   // next chunk to read
+	pending []byte
+}
+
+func (r *archiveReader) Read(p []byte) (int, error) {
+	for len(r.pending) == 0 {
+		if r.index >= len(r.chunks) {
+			return 0, io.EOF
+		}
+
+		data, err := r.fetch(r.chunks[r.index])
+		if err != nil {
+			return 0, err
+		}
+		length := common.ChunkLength(r.size, r.index)
+		if int64(len(data)) < length {
+			return 0, fmt.Errorf("chunk %s holds %d bytes, expected %d", r.chunks[r.index].ChunkHandle, len(data), length)
+		}
+
+		r.pending = data[:length]
+		r.index++
+	}
+
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+// fetch reads an archived chunk from the first of its servers that returns it intact
+func (r *archiveReader) fetch(chunk *pb.ArchivedChunk) ([]byte, error) {
+	lastErr := fmt.Errorf("chunk %s has no replicas", chunk.ChunkHandle)
+	for _, address := range chunk.Addresses {
+		data, err := r.server.fetchChunkFromPeer(r.ctx, address, chunk.ChunkHandle, chunk.AccessToken)
+		if err != nil {
+			log.Printf("Warning: failed to read archived chunk %s from %s: %v", chunk.ChunkHandle, address, err)
+			lastErr = err
+			continue
+		}
+		return data, nil
+	}
+
+	return nil, lastErr
+}
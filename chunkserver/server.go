@@ -1,45 +1,210 @@
 package chunkserver
 
 import (
+	"bytes"
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"maps"
 	"net"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/harshvardha/distributed_file_system/common"
 	pb "github.com/harshvardha/distributed_file_system/proto"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/codes"
+	_ "google.golang.org/grpc/encoding/gzip" // lets clients compress chunk data, zstd is registered by common
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
 )
 
+// defaultHeartbeatInterval is how often heartbeats are sent until the master says otherwise
+const defaultHeartbeatInterval = 10 * time.Second
+
+// errIncompatibleCluster is returned when registering with a master whose cluster parameters the server can't work with
+var errIncompatibleCluster = errors.New("incompatible cluster")
+
 // Server represents a chunk server
 type Server struct {
 	pb.UnimplementedChunkServerServer
 	storage       *Storage
-	address       string
+	health        *health.Server
+	ready         atomic.Bool // set once the grpc server is listening
+	serving       atomic.Pointer[grpc.Server]
+	fenced        atomic.Bool   // set once the server must not take part in the cluster, its chunks are no longer served
+	done          chan struct{} // closed by Stop to end heartbeats
+	stopOnce      sync.Once
+	backgroundMu  sync.Mutex                // orders starting background goroutines before Stop waits for them
+	background    sync.WaitGroup            // background goroutines, joined by Stop before it closes the storage
+	io            atomic.Pointer[ioLimiter] // bounds concurrent chunk reads and writes, nil when unbounded
+	load          *loadTracker              // load reported to the master in heartbeats
+	address       string                    // advertised to the master and through it to clients and other chunk servers
+	bindAddress   string                    // address the grpc server listens on
 	masterAddress string
+	zone          string
+	tier          string
+	labels        map[string]string // announced to the master when registering
+	conn          common.ConnTuning
+	faults        *common.Faults         // injected failures, nil outside of tests and game days
+	tokens        *common.ChunkTokens    // verifies chunk access tokens, nil accepts requests without one
+	debugServices bool                   // grpc reflection and channelz are served
+	slowRequests  *common.SlowRequestLog // logs requests taking longer than the threshold
+	interceptors  *common.ServerInterceptors
+	tunables      atomic.Pointer[Tunables]
+
+	heartbeatInterval time.Duration // set by the master when the server registers
+}
+
+// Config holds the tunables of a chunk server
+type Config struct {
+	SyncMode        SyncMode          // when chunk writes are flushed to disk
+	SyncInterval    time.Duration     // flush interval for SyncPeriodic
+	MaxIO           int               // chunk reads and writes running at once, 0 for no limit
+	MaxIOQueue      int               // chunk reads and writes waiting for a slot before requests are rejected
+	MaxStorageBytes int64             // bytes of chunks the server may store, 0 for no quota
+	CacheBytes      int64             // memory for keeping hot chunks to serve repeated reads from, 0 disables the cache
+	BindAddress     string            // address to listen on, e.g. 0.0.0.0:9001, the advertised address when empty
+	Zone            string            // failure domain reported to the master for placement
+	Tier            string            // storage media reported to the master for placement, e.g. ssd or hdd
+	Labels          map[string]string // descriptive key value labels announced to the master, e.g. rack=r12
+	Conn            common.ConnTuning // grpc connection settings, zero for common.DefaultConnTuning
+	Faults          *common.Faults    // failures to inject, nil for none
+	DebugServices   bool              // serve grpc reflection and channelz, see common.RegisterDebugServices
+	// SlowRequestThreshold logs requests taking longer, e.g. chunk writes stuck on a failing disk, with their
+	// parameters and caller, see common.SlowRequestLog. 0 logs none
+	SlowRequestThreshold time.Duration
+	LogRequests          bool // log every request with its caller, duration and status
+	// ChunkTokens verifies the access tokens every chunk request must carry, signed by a master holding the
+	// same key. nil serves requests without one
+	ChunkTokens *common.ChunkTokens
 }
 
 // NewServer creates a new chunk server
-func NewServer(address, storagePath, masterAddress string) (*Server, error) {
-	storage, err := NewStorage(storagePath)
+func NewServer(address string, storagePaths []string, masterAddress string, config Config) (*Server, error) {
+	if config.Conn == (common.ConnTuning{}) {
+		config.Conn = common.DefaultConnTuning()
+	}
+
+	storage, err := NewStorage(storagePaths, config)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Server{
+	labels := maps.Clone(config.Labels)
+	if config.Zone != "" {
+		if labels == nil {
+			labels = make(map[string]string)
+		}
+		labels["zone"] = config.Zone
+	}
+	if config.Tier != "" {
+		if labels == nil {
+			labels = make(map[string]string)
+		}
+		labels["tier"] = config.Tier
+	}
+
+	server := &Server{
 		storage:       storage,
+		health:        health.NewServer(),
+		load:          newLoadTracker(),
 		address:       address,
+		bindAddress:   cmp.Or(config.BindAddress, address),
 		masterAddress: masterAddress,
-	}, nil
+		done:          make(chan struct{}),
+		zone:          config.Zone,
+		tier:          config.Tier,
+		labels:        labels,
+		conn:          config.Conn,
+		faults:        config.Faults,
+		tokens:        config.ChunkTokens,
+		debugServices: config.DebugServices,
+		slowRequests:  common.NewSlowRequestLog(config.SlowRequestThreshold),
+
+		heartbeatInterval: defaultHeartbeatInterval,
+	}
+	server.interceptors = common.NewServerInterceptors(server.slowRequests, config.LogRequests)
+	if err := server.SetTunables(Tunables{
+		MaxIO:                config.MaxIO,
+		MaxIOQueue:           config.MaxIOQueue,
+		SlowRequestThreshold: config.SlowRequestThreshold,
+		LogRequests:          config.LogRequests,
+	}); err != nil {
+		storage.Close()
+		return nil, err
+	}
+
+	// Reporting chunks lost with a failed disk so the master stops sending clients to them
+	storage.SetChunksLostHandler(func(chunkHandles []string) {
+		go server.reportLostChunks(chunkHandles, "storage directory failed")
+	})
+
+	// Reporting replicas failing verification so the master repairs them from a good copy
+	storage.SetChunkCorruptHandler(func(chunkHandle string, err error) {
+		go server.reportCorruptChunk(chunkHandle, err.Error())
+	})
+
+	return server, nil
 }
 
 // WriteChunk handles chunk write requests
 func (s *Server) WriteChunk(ctx context.Context, req *pb.WriteChunkRequest) (*pb.WriteChunkResponse, error) {
 	log.Printf("Writing chunk: %s (index: %d, size: %d bytes)", req.ChunkHandle, req.ChunkIndex, len(req.Data))
 
-	if err := s.storage.WriteChunk(req.ChunkHandle, req.Data); err != nil {
+	// writes from clients predating chunk versions start at the initial version
+	chunkVersion := req.ChunkVersion
+	if chunkVersion == 0 {
+		chunkVersion = 1
+	}
+
+	if err := s.authorize(req.AccessToken, req.ChunkHandle, common.ChunkWrite); err != nil {
+		return &pb.WriteChunkResponse{Success: false}, err
+	}
+
+	release, err := s.beginIO(ctx)
+	if err != nil {
+		log.Printf("rejecting write of chunk %s: %v", req.ChunkHandle, err)
+		return &pb.WriteChunkResponse{Success: false}, err
+	}
+	defer release()
+
+	// catching data corrupted on the way here before it is stored, writes from older clients carry no checksum
+	if req.Checksum != nil && checksum(req.Data) != *req.Checksum {
+		log.Printf("rejecting write of chunk %s: checksum mismatch", req.ChunkHandle)
+		return &pb.WriteChunkResponse{Success: false}, status.Errorf(codes.DataLoss, "%v: checksum mismatch, data corrupted in transit", errCorruptChunk)
+	}
+
+	// the client may have given up while the write waited for a turn at the disk
+	if err := abandoned(ctx); err != nil {
+		log.Printf("abandoning write of chunk %s: %v", req.ChunkHandle, err)
+		return &pb.WriteChunkResponse{Success: false}, err
+	}
+
+	// every mutation goes through the primary: writes come from a client under the primary's unexpired lease, or are
+	// forwarded by the primary or directed by the master. Without a chunk token key leases are all there is to check
+	if err := s.checkWriteOrder(req, chunkVersion); err != nil {
+		log.Printf("rejecting write of chunk %s: %v", req.ChunkHandle, err)
+		return &pb.WriteChunkResponse{Success: false}, err
+	}
+
+	if err := s.storage.WriteChunk(ctx, req.ChunkHandle, chunkVersion, req.Data, req.Overwrite); err != nil {
 		log.Printf("failed to write chunk %s to disk: %v", req.ChunkHandle, err)
+		switch {
+		case ctx.Err() != nil:
+			return &pb.WriteChunkResponse{Success: false}, abandoned(ctx)
+		case errors.Is(err, ErrQuotaExceeded):
+			return &pb.WriteChunkResponse{Success: false}, status.Error(codes.ResourceExhausted, err.Error())
+		case errors.Is(err, ErrChunkExists):
+			return &pb.WriteChunkResponse{Success: false}, status.Error(codes.AlreadyExists, err.Error())
+		}
 		return &pb.WriteChunkResponse{Success: false}, err
 	}
 
@@ -47,26 +212,336 @@ func (s *Server) WriteChunk(ctx context.Context, req *pb.WriteChunkRequest) (*pb
 	go s.reportChunkToMaster(req.ChunkHandle)
 
 	log.Printf("Successfully wrote chunk: %s to disk", req.ChunkHandle)
-	return &pb.WriteChunkResponse{Success: true}, nil
+
+	// as primary, forwarding the stored chunk to the secondaries. Failed secondaries are reported back
+	// rather than failing the write, uploads succeed as long as one replica stored the chunk
+	forward := &pb.WriteChunkRequest{
+		ChunkHandle:  req.ChunkHandle,
+		Data:         req.Data,
+		ChunkIndex:   req.ChunkIndex,
+		ChunkVersion: req.ChunkVersion,
+		Overwrite:    req.Overwrite,
+		Checksum:     req.Checksum,
+		AccessToken:  req.AccessToken,
+		ForwardToken: s.tokens.Sign(req.ChunkHandle, common.ChunkForward),
+	}
+	// the client paces writes by the busiest replica, not only by the primary it talks to
+	var pressureMu sync.Mutex
+	pressure := s.io.Load().pressure()
+	failed, _ := s.forwardToSecondaries(ctx, req.SecondaryAddresses, func(ctx context.Context, client pb.ChunkServerClient) error {
+		response, err := client.WriteChunk(ctx, forward)
+
+		pressureMu.Lock()
+		defer pressureMu.Unlock()
+		if status.Code(err) == codes.ResourceExhausted {
+			pressure = 1
+		}
+		pressure = max(pressure, response.GetQueuePressure())
+		return err
+	})
+
+	return &pb.WriteChunkResponse{Success: true, FailedSecondaries: failed, QueuePressure: pressure}, nil
 }
 
 // ReadChunk handles read chunk requests
 func (s *Server) ReadChunk(ctx context.Context, req *pb.ReadChunkRequest) (*pb.ReadChunkResponse, error) {
 	log.Printf("Reading chunk: %s from disk", req.ChunkHandle)
 
-	data, err := s.storage.ReadChunk(req.ChunkHandle)
+	if err := s.authorize(req.AccessToken, req.ChunkHandle, common.ChunkRead); err != nil {
+		return nil, err
+	}
+
+	release, err := s.beginIO(ctx)
 	if err != nil {
-		log.Printf("failed to read chunk %s from disk: %v", req.ChunkHandle, err)
+		log.Printf("rejecting read of chunk %s: %v", req.ChunkHandle, err)
+		return nil, err
+	}
+	defer release()
+
+	if err := abandoned(ctx); err != nil {
+		log.Printf("abandoning read of chunk %s: %v", req.ChunkHandle, err)
 		return nil, err
 	}
 
+	var data []byte
+	if req.Offset == 0 && req.Length == 0 {
+		data, err = s.storage.ReadChunk(req.ChunkHandle)
+	} else {
+		data, err = s.storage.ReadChunkRange(req.ChunkHandle, req.Offset, req.Length)
+	}
+	if err != nil {
+		log.Printf("failed to read chunk %s from disk: %v", req.ChunkHandle, err)
+		return nil, readError(err)
+	}
+
+	s.load.recordRead(req.ChunkHandle)
 	log.Printf("Successfully read chunk %s with size %d from disk", req.ChunkHandle, len(data))
-	return &pb.ReadChunkResponse{Data: data}, nil
+	sum := checksum(data)
+	return &pb.ReadChunkResponse{Data: data, Checksum: &sum}, nil
+}
+
+// abandoned returns the status of a request whose client gave up or ran out of time, nil while the client still waits.
+// Handlers check it before starting disk work nobody would wait for
+func abandoned(ctx context.Context) error {
+	if ctx.Err() == nil {
+		return nil
+	}
+
+	return status.FromContextError(ctx.Err()).Err()
+}
+
+// authorize rejects a request on a chunk unless its token lets the holder perform operation on the chunk.
+// A fenced server rejects every request, its chunks may be stale or another server may own them now
+func (s *Server) authorize(token, chunkHandle string, operation common.ChunkOperation) error {
+	if s.fenced.Load() {
+		return status.Errorf(codes.FailedPrecondition, "failed to %s chunk %s: chunk server %s is fenced out of the cluster", operation, chunkHandle, s.address)
+	}
+
+	if err := s.tokens.Verify(token, chunkHandle, operation); err != nil {
+		log.Printf("rejecting %s of chunk %s: %v", operation, chunkHandle, err)
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	return nil
+}
+
+// readError reports replicas failing verification as lost data, so clients can tell them from unreachable replicas
+func readError(err error) error {
+	if errors.Is(err, errCorruptChunk) {
+		return status.Error(codes.DataLoss, err.Error())
+	}
+
+	return err
+}
+
+// ReadChunkStream handles streaming read chunk requests
+func (s *Server) ReadChunkStream(req *pb.ReadChunkRequest, stream pb.ChunkServer_ReadChunkStreamServer) error {
+	log.Printf("Streaming chunk: %s from disk", req.ChunkHandle)
+
+	if err := s.authorize(req.AccessToken, req.ChunkHandle, common.ChunkRead); err != nil {
+		return err
+	}
+
+	release, err := s.beginIO(stream.Context())
+	if err != nil {
+		log.Printf("rejecting read of chunk %s: %v", req.ChunkHandle, err)
+		return err
+	}
+	defer release()
+
+	var (
+		size int
+		sum  uint32
+	)
+	err = s.storage.StreamChunk(req.ChunkHandle, req.Offset, req.Length, func(data []byte) error {
+		size += len(data)
+		sum = common.UpdateChecksum(sum, data)
+		// Send marshals the message before returning so the buffer can be reused
+		return stream.Send(&pb.ReadChunkResponse{Data: data})
+	})
+	if err != nil {
+		log.Printf("failed to stream chunk %s from disk: %v", req.ChunkHandle, err)
+		return readError(err)
+	}
+
+	// letting the reader verify what it received, including ranges the stored checksum doesn't cover
+	if err := stream.Send(&pb.ReadChunkResponse{Checksum: &sum}); err != nil {
+		return err
+	}
+
+	s.load.recordRead(req.ChunkHandle)
+	log.Printf("Successfully streamed chunk %s with size %d from disk", req.ChunkHandle, size)
+	return nil
+}
+
+// CopyChunk handles local chunk copy requests from the master
+func (s *Server) CopyChunk(ctx context.Context, req *pb.CopyChunkRequest) (*pb.CopyChunkResponse, error) {
+	log.Printf("Copying chunk: %s to %s", req.SourceChunkHandle, req.DestinationChunkHandle)
+
+	if err := s.authorize(req.SourceAccessToken, req.SourceChunkHandle, common.ChunkRead); err != nil {
+		return &pb.CopyChunkResponse{Success: false}, err
+	}
+	if err := s.authorize(req.AccessToken, req.DestinationChunkHandle, common.ChunkWrite); err != nil {
+		return &pb.CopyChunkResponse{Success: false}, err
+	}
+
+	release, err := s.beginIO(ctx)
+	if err != nil {
+		log.Printf("rejecting copy of chunk %s: %v", req.SourceChunkHandle, err)
+		return &pb.CopyChunkResponse{Success: false}, err
+	}
+	defer release()
+
+	if err := s.storage.CopyChunk(ctx, req.SourceChunkHandle, req.DestinationChunkHandle, req.ChunkVersion); err != nil {
+		log.Printf("failed to copy chunk %s to %s: %v", req.SourceChunkHandle, req.DestinationChunkHandle, err)
+		if ctx.Err() != nil {
+			return &pb.CopyChunkResponse{Success: false}, abandoned(ctx)
+		}
+		return &pb.CopyChunkResponse{Success: false}, err
+	}
+
+	log.Printf("Successfully copied chunk: %s to %s", req.SourceChunkHandle, req.DestinationChunkHandle)
+	return &pb.CopyChunkResponse{Success: true}, nil
+}
+
+// DeleteChunk handles chunk deletion requests from the master
+func (s *Server) DeleteChunk(ctx context.Context, req *pb.DeleteChunkRequest) (*pb.DeleteChunkResponse, error) {
+	log.Printf("Deleting chunk: %s from disk", req.ChunkHandle)
+
+	if err := s.authorize(req.AccessToken, req.ChunkHandle, common.ChunkDelete); err != nil {
+		return &pb.DeleteChunkResponse{Success: false}, err
+	}
+
+	if err := s.storage.DeleteChunk(req.ChunkHandle); err != nil {
+		log.Printf("failed to delete chunk %s from disk: %v", req.ChunkHandle, err)
+		return &pb.DeleteChunkResponse{Success: false}, err
+	}
+
+	log.Printf("Successfully deleted chunk: %s from disk", req.ChunkHandle)
+	return &pb.DeleteChunkResponse{Success: true}, nil
+}
+
+// VerifyChunk handles requests from the master to check a replica reported corrupt. A replica failing
+// verification is dropped, and reported to the master, like on any other read
+func (s *Server) VerifyChunk(ctx context.Context, req *pb.VerifyChunkRequest) (*pb.VerifyChunkResponse, error) {
+	log.Printf("Verifying chunk: %s", req.ChunkHandle)
+
+	if err := s.authorize(req.AccessToken, req.ChunkHandle, common.ChunkRead); err != nil {
+		return nil, err
+	}
+
+	release, err := s.beginIO(ctx)
+	if err != nil {
+		log.Printf("rejecting verification of chunk %s: %v", req.ChunkHandle, err)
+		return nil, err
+	}
+	defer release()
+
+	err = s.storage.VerifyChunk(req.ChunkHandle)
+	switch {
+	case errors.Is(err, errCorruptChunk):
+		log.Printf("Chunk %s failed verification: %v", req.ChunkHandle, err)
+		return &pb.VerifyChunkResponse{Corrupt: true}, nil
+	case errors.Is(err, errChunkNotFound):
+		return nil, status.Error(codes.NotFound, err.Error())
+	case err != nil:
+		log.Printf("failed to verify chunk %s: %v", req.ChunkHandle, err)
+		return nil, err
+	}
+
+	return &pb.VerifyChunkResponse{Corrupt: false}, nil
+}
+
+// ReplicateChunk handles requests from the master to pull a chunk from a peer chunk server
+func (s *Server) ReplicateChunk(ctx context.Context, req *pb.ReplicateChunkRequest) (*pb.ReplicateChunkResponse, error) {
+	log.Printf("Replicating chunk: %s from %s", req.ChunkHandle, req.SourceAddress)
+
+	if err := s.authorize(req.AccessToken, req.ChunkHandle, common.ChunkWrite); err != nil {
+		return &pb.ReplicateChunkResponse{Success: false}, err
+	}
+
+	release, err := s.beginIO(ctx)
+	if err != nil {
+		log.Printf("rejecting replication of chunk %s: %v", req.ChunkHandle, err)
+		return &pb.ReplicateChunkResponse{Success: false}, err
+	}
+	defer release()
+
+	data, err := s.fetchChunkFromPeer(ctx, req.SourceAddress, req.ChunkHandle, req.SourceAccessToken)
+	if err != nil {
+		log.Printf("failed to fetch chunk %s from %s: %v", req.ChunkHandle, req.SourceAddress, err)
+		return &pb.ReplicateChunkResponse{Success: false}, err
+	}
+
+	chunkVersion := req.ChunkVersion
+	if chunkVersion == 0 {
+		chunkVersion = 1
+	}
+
+	// replication is directed by the master, which knows the local copy is missing or stale
+	if err := s.storage.WriteChunk(ctx, req.ChunkHandle, chunkVersion, data, true); err != nil {
+		log.Printf("failed to write chunk %s to disk: %v", req.ChunkHandle, err)
+		if ctx.Err() != nil {
+			return &pb.ReplicateChunkResponse{Success: false}, abandoned(ctx)
+		}
+		return &pb.ReplicateChunkResponse{Success: false}, err
+	}
+
+	// Reporting the new replica to master
+	go s.reportChunkToMaster(req.ChunkHandle)
+
+	log.Printf("Successfully replicated chunk %s (%d bytes) from %s", req.ChunkHandle, len(data), req.SourceAddress)
+	return &pb.ReplicateChunkResponse{Success: true}, nil
+}
+
+// GetServerInfo handles requests for the chunk server's version, so mixed-version clusters can be diagnosed
+func (s *Server) GetServerInfo(ctx context.Context, req *pb.GetServerInfoRequest) (*pb.GetServerInfoResponse, error) {
+	return &pb.GetServerInfoResponse{
+		Version:   common.Version,
+		Commit:    common.BuildCommit(),
+		GoVersion: runtime.Version(),
+		Features:  common.ProtocolFeatures,
+	}, nil
+}
+
+// fetchChunkFromPeer reads a whole chunk from another chunk server, which verifies it while streaming, and
+// checks what arrived against the checksum the peer sends last. The read token comes from the master directing
+// the replication
+func (s *Server) fetchChunkFromPeer(ctx context.Context, peerAddress, chunkHandle, accessToken string) ([]byte, error) {
+	conn, err := grpc.NewClient(peerAddress, s.conn.DialOptions()...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to chunk server %s: %v", peerAddress, err)
+	}
+	defer conn.Close()
+
+	stream, err := pb.NewChunkServerClient(conn).ReadChunkStream(ctx, &pb.ReadChunkRequest{
+		ChunkHandle: chunkHandle,
+		AccessToken: accessToken,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		data bytes.Buffer
+		sum  uint32
+	)
+	for {
+		response, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		data.Write(response.Data)
+		sum = common.UpdateChecksum(sum, response.Data)
+
+		// peers predating read checksums send none, their data is taken as is
+		if response.Checksum != nil && *response.Checksum != sum {
+			return nil, status.Errorf(codes.DataLoss, "chunk %s read from %s doesn't match the checksum it sent", chunkHandle, peerAddress)
+		}
+	}
+
+	return data.Bytes(), nil
+}
+
+// dialMaster connects to the master, authenticating every request as this chunk server's with a token signed
+// with the cluster's key
+func (s *Server) dialMaster() (*grpc.ClientConn, error) {
+	authenticate := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if s.tokens != nil {
+			ctx = common.WithServerToken(ctx, s.tokens.SignServer(s.address))
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+
+	return grpc.NewClient(s.masterAddress, append(s.conn.DialOptions(), grpc.WithChainUnaryInterceptor(authenticate))...)
 }
 
 // reportChunkToMaster reports chunk storage to master
 func (s *Server) reportChunkToMaster(chunkHandle string) {
-	conn, err := grpc.NewClient(s.masterAddress, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := s.dialMaster()
 	if err != nil {
 		log.Printf("failed to connect to master: %v", err)
 		return
@@ -87,22 +562,165 @@ func (s *Server) reportChunkToMaster(chunkHandle string) {
 	}
 }
 
-// startHeartbeat sends periodic heartbeats to master
+// reportLostChunks reports chunks this server no longer holds to the master
+func (s *Server) reportLostChunks(chunkHandles []string, reason string) {
+	conn, err := s.dialMaster()
+	if err != nil {
+		log.Printf("failed to connect to master: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	client := pb.NewMasterClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err = client.ReportLostChunks(ctx, &pb.ReportLostChunksRequest{
+		ChunkServerAddress: s.address,
+		ChunkHandles:       chunkHandles,
+		Reason:             reason,
+	})
+	if err != nil {
+		log.Printf("Chunk Server %s failed to report %d lost chunks to Master %s: %v", s.address, len(chunkHandles), s.masterAddress, err)
+	}
+}
+
+// reportCorruptChunk reports a replica dropped after failing verification to the master
+func (s *Server) reportCorruptChunk(chunkHandle string, reason string) {
+	conn, err := s.dialMaster()
+	if err != nil {
+		log.Printf("failed to connect to master: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	client := pb.NewMasterClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err = client.ReportCorruptChunk(ctx, &pb.ReportCorruptChunkRequest{
+		ChunkServerAddress: s.address,
+		ChunkHandle:        chunkHandle,
+		Reason:             reason,
+	})
+	if err != nil {
+		log.Printf("Chunk Server %s failed to report corrupt chunk %s to Master %s: %v", s.address, chunkHandle, s.masterAddress, err)
+	}
+}
+
+// startHeartbeat registers with the master and then sends periodic heartbeats to it
 func (s *Server) startHeartbeat() {
-	ticker := time.NewTicker(10 * time.Second)
+	// retrying until the master is up, a server of an incompatible build never joins
+	for {
+		if s.stopped() {
+			return
+		}
+
+		err := s.register()
+		if err == nil {
+			break
+		}
+		if errors.Is(err, errIncompatibleCluster) {
+			log.Printf("Not joining the cluster: %v", err)
+			s.stopServing()
+			return
+		}
+
+		// another live server holding our address or identity keeps us out until it goes away
+		log.Printf("Failed to register with master, retrying in %s: %v", s.heartbeatInterval, err)
+		select {
+		case <-s.done:
+			return
+		case <-time.After(s.heartbeatInterval):
+		}
+	}
+
+	ticker := time.NewTicker(s.heartbeatInterval)
 	defer ticker.Stop()
 
-	for range ticker.C {
-		s.sendHeartbeat()
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+		}
+
+		if err := s.sendHeartbeat(); status.Code(err) == codes.FailedPrecondition {
+			log.Printf("Master fenced this chunk server, another server took over its address or identity: %v", err)
+			s.stopServing()
+			return
+		}
+	}
+}
+
+// stopServing fences the server, which then rejects chunk requests and reports as not serving to health checks,
+// for a server that must not take part in the cluster
+func (s *Server) stopServing() {
+	s.fenced.Store(true)
+	s.health.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	s.health.SetServingStatus(pb.ChunkServer_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_NOT_SERVING)
+}
+
+// register announces the server to the master and adopts the cluster parameters it returns
+func (s *Server) register() error {
+	conn, err := s.dialMaster()
+	if err != nil {
+		return fmt.Errorf("failed to connect to master: %v", err)
+	}
+	defer conn.Close()
+
+	client := pb.NewMasterClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	directories := make([]*pb.StorageDirectory, 0)
+	for _, directory := range s.storage.Directories() {
+		directories = append(directories, &pb.StorageDirectory{
+			Path:          directory.Path,
+			CapacityBytes: directory.CapacityBytes,
+			FreeBytes:     directory.FreeBytes,
+			ChunkCount:    int64(directory.ChunkCount),
+			Healthy:       directory.Healthy,
+		})
 	}
+	capacity, free := s.storage.Capacity()
+
+	response, err := client.RegisterChunkServer(ctx, &pb.RegisterChunkServerRequest{
+		ChunkServerAddress: s.address,
+		CapacityBytes:      capacity,
+		FreeBytes:          free,
+		Labels:             s.labels,
+		Version:            common.Version,
+		StorageDirectories: directories,
+		ChunkHandles:       s.storage.ListChunks(),
+		ServerId:           s.storage.ServerID(),
+	})
+	if status.Code(err) == codes.Unimplemented {
+		// masters predating registration learn about the server from its heartbeats
+		log.Printf("Master doesn't support registration, joining through heartbeats")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if response.ChunkSize != common.ChunkSize {
+		return fmt.Errorf("%w: the cluster uses %d byte chunks, this server was built for %d", errIncompatibleCluster, response.ChunkSize, common.ChunkSize)
+	}
+	if response.HeartbeatIntervalMs > 0 {
+		s.heartbeatInterval = time.Duration(response.HeartbeatIntervalMs) * time.Millisecond
+	}
+
+	log.Printf("Registered with master %s, heartbeat every %s", s.masterAddress, s.heartbeatInterval)
+	return nil
 }
 
 // sendHeartbeat sends heartbeat to master
-func (s *Server) sendHeartbeat() {
-	conn, err := grpc.NewClient(s.masterAddress, grpc.WithTransportCredentials(insecure.NewCredentials()))
+func (s *Server) sendHeartbeat() error {
+	conn, err := s.dialMaster()
 	if err != nil {
 		log.Printf("Failed to connect to master for sending heartbeat: %v", err)
-		return
+		return err
 	}
 	defer conn.Close()
 
@@ -111,10 +729,18 @@ func (s *Server) sendHeartbeat() {
 	defer cancel()
 
 	chunks := s.storage.ListChunks()
+	capacity, free := s.storage.Capacity()
 
 	_, err = client.Heartbeat(ctx, &pb.HeartbeatRequest{
 		ChunkServerAddress: s.address,
 		ChunkHandles:       chunks,
+		CapacityBytes:      capacity,
+		FreeBytes:          free,
+		Load:               s.load.sample(),
+		Zone:               s.zone,
+		Tier:               s.tier,
+		ChunkReads:         s.load.takeChunkReads(),
+		ServerId:           s.storage.ServerID(),
 	})
 
 	if err != nil {
@@ -122,24 +748,49 @@ func (s *Server) sendHeartbeat() {
 	} else {
 		log.Printf("Heartbeat sent: %d chunks", len(chunks))
 	}
+	return err
 }
 
 // Start starts the chunk server
 func (s *Server) Start() error {
-	listen, err := net.Listen("tcp", s.address)
+	listen, err := net.Listen("tcp", s.bindAddress)
 	if err != nil {
-		return fmt.Errorf("chunk server %s failed to listen: %v", s.address, err)
+		return fmt.Errorf("chunk server %s failed to listen on %s: %v", s.address, s.bindAddress, err)
 	}
 
-	grpcServer := grpc.NewServer()
+	return s.Serve(listen)
+}
+
+// Serve runs the chunk server on an existing listener, e.g. one bound to an ephemeral port, until Stop is called
+func (s *Server) Serve(listen net.Listener) error {
+	serverOptions := append(s.conn.ServerOptions(), s.interceptors.ServerOptions()...)
+	serverOptions = append(serverOptions, s.faults.ServerOptions()...)
+	grpcServer := grpc.NewServer(append(serverOptions, grpc.StatsHandler(s.load))...)
 	pb.RegisterChunkServerServer(grpcServer, s)
+	if s.debugServices {
+		common.RegisterDebugServices(grpcServer)
+	}
+
+	// Registering standard grpc health checking service for load balancers and probes
+	healthpb.RegisterHealthServer(grpcServer, s.health)
+	s.health.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	s.health.SetServingStatus(pb.ChunkServer_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
 
 	// Starting heartbeat in background
-	go s.startHeartbeat()
+	s.goBackground(s.startHeartbeat)
 
-	log.Printf("chunk server starting on %s", s.address)
-	log.Printf("Storage path: %s", s.storage.storagePath)
+	log.Printf("chunk server starting on %s, advertised as %s", listen.Addr(), s.address)
+	log.Printf("Storage paths: %s", strings.Join(s.storage.storagePaths, ", "))
+	if unhealthy := s.storage.UnhealthyStoragePaths(); len(unhealthy) > 0 {
+		log.Printf("Warning: isolated unhealthy storage paths: %s", strings.Join(unhealthy, ", "))
+	}
 	log.Printf("Master address: %s", s.masterAddress)
+	log.Printf("Server id: %s", s.storage.ServerID())
+	s.serving.Store(grpcServer)
+	if s.stopped() {
+		grpcServer.Stop() // Stop ran before there was a grpc server to stop
+	}
+	s.ready.Store(true)
 
 	if err := grpcServer.Serve(listen); err != nil {
 		return fmt.Errorf("failed to start chunk server %s: %v", s.address, err)
@@ -147,3 +798,47 @@ func (s *Server) Start() error {
 
 	return nil
 }
+
+// Stop closes the connections and listener of the grpc server, stops heartbeats, so the master
+// declares the server dead, and closes the storage, releasing its storage directories
+func (s *Server) Stop() {
+	s.stopOnce.Do(func() {
+		s.ready.Store(false)
+		s.backgroundMu.Lock()
+		close(s.done)
+		s.backgroundMu.Unlock()
+		if grpcServer := s.serving.Load(); grpcServer != nil {
+			grpcServer.Stop()
+		}
+		s.background.Wait()
+		if err := s.storage.Close(); err != nil {
+			log.Printf("Warning: failed to close storage: %v", err)
+		}
+	})
+}
+
+// goBackground runs fn in a goroutine Stop waits for, fn returns once s.done is closed. Nothing is started
+// once Stop was called
+func (s *Server) goBackground(fn func()) {
+	s.backgroundMu.Lock()
+	defer s.backgroundMu.Unlock()
+
+	if s.stopped() {
+		return
+	}
+	s.background.Add(1)
+	go func() {
+		defer s.background.Done()
+		fn()
+	}()
+}
+
+// stopped reports whether Stop was called
+func (s *Server) stopped() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
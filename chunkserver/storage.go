@@ -1,94 +1,771 @@
 package chunkserver
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"hash/crc32"
+	"io"
+	"io/fs"
+	"log"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"sync"
+
+	"github.com/harshvardha/distributed_file_system/common"
 )
 
-// Storage manages chunk storage on disk
+// Storage manages chunk storage on disk, spread across one or more storage directories
 type Storage struct {
-	mu          sync.RWMutex
-	storagePath string
-	chunks      map[string]bool // key: chunk handle, value: exists(true/false)
+	mu           sync.RWMutex
+	storagePaths []string
+	chunks       map[string]string   // key: chunk handle, value: storage directory holding the chunk
+	chunkCounts  map[string]int      // key: storage directory, value: number of chunks stored in it
+	unhealthy    map[string]bool     // key: storage directory, value: disk failed and is isolated
+	sizes        map[string]int64    // key: chunk handle, value: size of the chunk file on disk
+	usedBytes    int64               // sum of sizes
+	reserved     int64               // bytes of chunk writes in progress, counted against the quota
+	maxBytes     int64               // storage quota, 0 for none
+	onChunksLost func([]string)      // called with the chunks lost when a storage directory fails
+	onCorrupt    func(string, error) // called with chunks dropped after failing verification
+	syncMode     SyncMode
+	syncMu       sync.Mutex
+	dirty        map[string]bool // key: chunk file path written but not yet synced in periodic mode
+	dirtyDirs    map[string]bool // key: directory whose entries changed but aren't synced yet in periodic mode
+	chunkLocks   *chunkLocks     // serializes writes and deletes of the same chunk
+	appendMu     sync.Mutex      // serializes record appends, which read and rewrite the chunk
+	serverID     string          // identity of the chunk server, kept in every storage directory
+	locks        []*os.File      // lock files keeping other processes out of the storage directories
+	closed       chan struct{}   // closed by Close to end periodic syncs
+	closeOnce    sync.Once
+	faults       *common.Faults // partial writes and corrupted reads to inject, nil for none
+	cache        *chunkCache    // verified data of hot chunks, nil when caching is off
 }
 
+// ErrChunkExists is returned when a write would replace an existing chunk without a newer chunk version
+var ErrChunkExists = errors.New("chunk already exists")
+
+// ErrQuotaExceeded is returned when a write would take the chunk server over its storage quota
+var ErrQuotaExceeded = errors.New("storage quota exceeded")
+
 // NewStorage creates a new storage manager
-func NewStorage(storagePath string) (*Storage, error) {
-	// Creating storage directory if it doesn't exist
-	if err := os.MkdirAll(storagePath, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create storage dictionary: %v", err)
+func NewStorage(storagePaths []string, config Config) (*Storage, error) {
+	if len(storagePaths) == 0 {
+		return nil, fmt.Errorf("at least one storage directory is required")
+	}
+
+	// Creating storage directories if they don't exist
+	for _, storagePath := range storagePaths {
+		if err := os.MkdirAll(storagePath, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create storage dictionary %s: %v", storagePath, err)
+		}
+	}
+
+	// two processes sharing a directory would each think they own the other's chunks
+	locks, err := lockStorageDirs(storagePaths)
+	if err != nil {
+		return nil, err
+	}
+
+	serverID, err := loadServerID(storagePaths)
+	if err != nil {
+		closeAll(locks)
+		return nil, err
 	}
 
 	storage := &Storage{
-		storagePath: storagePath,
-		chunks:      make(map[string]bool),
+		storagePaths: storagePaths,
+		serverID:     serverID,
+		locks:        locks,
+		chunks:       make(map[string]string),
+		chunkCounts:  make(map[string]int),
+		unhealthy:    make(map[string]bool),
+		sizes:        make(map[string]int64),
+		maxBytes:     config.MaxStorageBytes,
+		syncMode:     config.SyncMode,
+		dirty:        make(map[string]bool),
+		dirtyDirs:    make(map[string]bool),
+		chunkLocks:   newChunkLocks(),
+		closed:       make(chan struct{}),
+		faults:       config.Faults,
+		cache:        newChunkCache(config.CacheBytes),
 	}
 
 	// Loading existing chunks
 	if err := storage.loadExistingChunks(); err != nil {
+		closeAll(locks)
 		return nil, fmt.Errorf("failed to load existing chunks: %v", err)
 	}
 
+	if storage.maxBytes > 0 && storage.usedBytes > storage.maxBytes {
+		log.Printf("Warning: existing chunks use %d bytes, more than the storage quota of %d bytes", storage.usedBytes, storage.maxBytes)
+	}
+
+	if config.SyncMode == SyncPeriodic {
+		go storage.startPeriodicSync(config.SyncInterval)
+	}
+
 	return storage, nil
 }
 
-// loadExistingChunks scans the storage directory for existing chunks
+// Close flushes chunks not yet synced and releases the storage directories to other processes
+func (s *Storage) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		close(s.closed)
+		err = s.Sync()
+		closeAll(s.locks)
+	})
+
+	return err
+}
+
+// chunkPath returns where a chunk lives inside a storage directory. Chunks are sharded into
+// two levels of sub directories named after the start of the handle (ab/cd/abcd...) so no single
+// directory ends up with hundreds of thousands of files
+func chunkPath(storagePath, chunkHandle string) string {
+	if len(chunkHandle) < 4 {
+		return filepath.Join(storagePath, chunkHandle)
+	}
+
+	return filepath.Join(storagePath, chunkHandle[:2], chunkHandle[2:4], chunkHandle)
+}
+
+// loadExistingChunks scans the storage directories for existing chunks, migrating chunks
+// stored in the old flat layout into shard directories first.
+// Directories that can't be read are isolated as long as at least one directory is healthy
 func (s *Storage) loadExistingChunks() error {
-	files, err := os.ReadDir(s.storagePath)
+	for _, storagePath := range s.storagePaths {
+		if err := migrateFlatChunks(storagePath); err != nil {
+			log.Printf("Warning: failed to migrate storage directory %s, marking it unhealthy: %v", storagePath, err)
+			s.unhealthy[storagePath] = true
+			continue
+		}
+
+		err := filepath.WalkDir(storagePath, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if entry.IsDir() {
+				return nil
+			}
+
+			// temp files are left behind by writes interrupted by a crash
+			if strings.HasSuffix(path, tempChunkSuffix) {
+				log.Printf("Removing incomplete chunk write: %s", path)
+				return os.Remove(path)
+			}
+
+			chunkHandle := entry.Name()
+			if filepath.Dir(path) == filepath.Clean(storagePath) && isStorageMetadataFile(chunkHandle) {
+				return nil
+			}
+			if path != chunkPath(storagePath, chunkHandle) {
+				log.Printf("Warning: ignoring unexpected file in storage directory: %s", path)
+				return nil
+			}
+
+			if existing, exists := s.chunks[chunkHandle]; exists {
+				log.Printf("Warning: chunk %s found in both %s and %s, using %s", chunkHandle, existing, storagePath, existing)
+				return nil
+			}
+
+			// chunks written before the chunk file format existed get a header added
+			hasHeader, err := hasChunkHeader(path)
+			if err != nil {
+				return err
+			}
+			if !hasHeader {
+				if err := upgradeLegacyChunk(path); err != nil {
+					return err
+				}
+				log.Printf("Upgraded chunk %s to chunk file format version %d", chunkHandle, chunkFormatVersion)
+			}
+
+			// chunks torn by a crash are dropped so they are never served
+			if err := checkChunkLength(path); err != nil {
+				if !errors.Is(err, errCorruptChunk) {
+					return err
+				}
+				log.Printf("Warning: removing torn chunk %s: %v", chunkHandle, err)
+				return os.Remove(path)
+			}
+
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+
+			s.chunks[chunkHandle] = storagePath
+			s.chunkCounts[storagePath]++
+			s.sizes[chunkHandle] = info.Size()
+			s.usedBytes += info.Size()
+
+			return nil
+		})
+		if err != nil {
+			log.Printf("Warning: failed to scan storage directory %s, marking it unhealthy: %v", storagePath, err)
+			s.unhealthy[storagePath] = true
+		}
+	}
+
+	if len(s.unhealthy) == len(s.storagePaths) {
+		return fmt.Errorf("no healthy storage directories")
+	}
+
+	return nil
+}
+
+// migrateFlatChunks moves chunks stored directly in the storage directory into their shard directories
+func migrateFlatChunks(storagePath string) error {
+	files, err := os.ReadDir(storagePath)
 	if err != nil {
 		return err
 	}
 
+	migrated := 0
 	for _, file := range files {
-		if !file.IsDir() {
-			chunkHandle := file.Name()
-			s.chunks[chunkHandle] = true
+		if file.IsDir() || isStorageMetadataFile(file.Name()) {
+			continue
+		}
+
+		chunkHandle := file.Name()
+		newPath := chunkPath(storagePath, chunkHandle)
+		if err := os.MkdirAll(filepath.Dir(newPath), 0755); err != nil {
+			return err
+		}
+
+		if err := os.Rename(filepath.Join(storagePath, chunkHandle), newPath); err != nil {
+			return err
 		}
+		migrated++
+	}
+
+	if migrated > 0 {
+		log.Printf("Migrated %d chunks in %s to the sharded layout", migrated, storagePath)
 	}
 
 	return nil
 }
 
-// WriteChunk writes chunk data to disk
-func (s *Storage) WriteChunk(chunkHandle string, data []byte) error {
+// SetChunksLostHandler registers a callback receiving the chunk handles lost when a storage directory fails
+func (s *Storage) SetChunksLostHandler(handler func([]string)) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	chunkPath := filepath.Join(s.storagePath, chunkHandle)
-	if err := os.WriteFile(chunkPath, data, 0644); err != nil {
+	s.onChunksLost = handler
+}
+
+// handleDiskError isolates storagePath if err is a disk failure, forgetting every chunk stored in it
+// and reporting them as lost. The caller must not hold the lock
+func (s *Storage) handleDiskError(storagePath string, err error) {
+	if !isDiskError(err) {
+		return
+	}
+
+	s.mu.Lock()
+	if s.unhealthy[storagePath] {
+		s.mu.Unlock()
+		return
+	}
+
+	log.Printf("Storage directory %s failed, isolating it: %v", storagePath, err)
+	s.unhealthy[storagePath] = true
+
+	lost := make([]string, 0, s.chunkCounts[storagePath])
+	for chunkHandle, chunkStoragePath := range s.chunks {
+		if chunkStoragePath == storagePath {
+			lost = append(lost, chunkHandle)
+			delete(s.chunks, chunkHandle)
+			s.usedBytes -= s.sizes[chunkHandle]
+			delete(s.sizes, chunkHandle)
+		}
+	}
+	s.chunkCounts[storagePath] = 0
+	s.cache.invalidate(lost...)
+	handler := s.onChunksLost
+	s.mu.Unlock()
+
+	log.Printf("%d chunks lost with storage directory %s", len(lost), storagePath)
+	if handler != nil && len(lost) > 0 {
+		handler(lost)
+	}
+}
+
+// SetChunkCorruptHandler registers a callback receiving chunks dropped after failing verification
+func (s *Storage) SetChunkCorruptHandler(handler func(chunkHandle string, err error)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.onCorrupt = handler
+}
+
+// checkCorrupt drops the local replica of a chunk when err shows it failed verification, so it is
+// never served again, and returns err. The caller must not hold the lock
+func (s *Storage) checkCorrupt(chunkHandle string, err error) error {
+	if !errors.Is(err, errCorruptChunk) {
+		return err
+	}
+
+	s.mu.Lock()
+	storagePath, exists := s.chunks[chunkHandle]
+	if !exists {
+		s.mu.Unlock()
+		return err
+	}
+
+	log.Printf("Dropping corrupt replica of chunk %s: %v", chunkHandle, err)
+	if removeErr := os.Remove(chunkPath(storagePath, chunkHandle)); removeErr != nil {
+		log.Printf("Warning: failed to remove corrupt chunk %s: %v", chunkHandle, removeErr)
+	}
+
+	delete(s.chunks, chunkHandle)
+	s.chunkCounts[storagePath]--
+	s.usedBytes -= s.sizes[chunkHandle]
+	delete(s.sizes, chunkHandle)
+	s.cache.invalidate(chunkHandle)
+	handler := s.onCorrupt
+	s.mu.Unlock()
+
+	if handler != nil {
+		handler(chunkHandle, err)
+	}
+
+	return err
+}
+
+// StorageDirectoryInfo describes one of the storage directories for the master
+type StorageDirectoryInfo struct {
+	Path          string
+	CapacityBytes int64
+	FreeBytes     int64
+	ChunkCount    int
+	Healthy       bool
+}
+
+// Directories returns the storage directories with their space and chunk counts
+func (s *Storage) Directories() []StorageDirectoryInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	directories := make([]StorageDirectoryInfo, 0, len(s.storagePaths))
+	for _, storagePath := range s.storagePaths {
+		directory := StorageDirectoryInfo{
+			Path:       storagePath,
+			ChunkCount: s.chunkCounts[storagePath],
+			Healthy:    !s.unhealthy[storagePath],
+		}
+		if space, err := getDiskSpace(storagePath); err == nil {
+			directory.CapacityBytes = space.total
+			directory.FreeBytes = space.free
+		}
+		directories = append(directories, directory)
+	}
+
+	return directories
+}
+
+// ServerID returns the identity of the chunk server owning the storage directories
+func (s *Storage) ServerID() string {
+	return s.serverID
+}
+
+// UnhealthyStoragePaths returns the storage directories isolated after disk failures
+func (s *Storage) UnhealthyStoragePaths() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	paths := make([]string, 0, len(s.unhealthy))
+	for _, storagePath := range s.storagePaths {
+		if s.unhealthy[storagePath] {
+			paths = append(paths, storagePath)
+		}
+	}
+
+	return paths
+}
+
+// pickStoragePath chooses the healthy storage directory with the most free space for a new chunk,
+// falling back to the fewest chunks for directories sharing a filesystem. The caller must hold the lock
+func (s *Storage) pickStoragePath() (string, error) {
+	best := ""
+	var bestFree int64 = -1
+
+	for _, storagePath := range s.storagePaths {
+		if s.unhealthy[storagePath] {
+			continue
+		}
+
+		if best == "" {
+			best = storagePath
+		}
+
+		space, err := getDiskSpace(storagePath)
+		if err != nil {
+			log.Printf("Warning: failed to get free space of %s: %v", storagePath, err)
+			continue
+		}
+
+		if space.free > bestFree || (space.free == bestFree && s.chunkCounts[storagePath] < s.chunkCounts[best]) {
+			best = storagePath
+			bestFree = space.free
+		}
+	}
+
+	if best == "" {
+		return "", fmt.Errorf("no healthy storage directories")
+	}
+
+	return best, nil
+}
+
+// WriteChunk writes chunk data to disk, prefixed with the chunk file header. An existing chunk is only
+// replaced by a newer chunk version, or by any version when overwrite is set. A write abandoned by ctx
+// stops part way and leaves the chunk as it was. The storage lock is only held to check and reserve
+// room for the chunk and to record it once written, so reads and writes of other chunks aren't held
+// up by the disk
+func (s *Storage) WriteChunk(ctx context.Context, chunkHandle string, chunkVersion int32, data []byte, overwrite bool) error {
+	unlock := s.chunkLocks.Lock(chunkHandle)
+	defer unlock()
+
+	s.mu.Lock()
+
+	// overwriting a chunk keeps it in the directory it already lives in
+	storagePath, exists := s.chunks[chunkHandle]
+	if exists && !overwrite {
+		header, err := readChunkHeader(chunkPath(storagePath, chunkHandle))
+		if err == nil && chunkVersion <= header.chunkVersion {
+			s.mu.Unlock()
+			return fmt.Errorf("%w: %s has version %d, write has version %d", ErrChunkExists, chunkHandle, header.chunkVersion, chunkVersion)
+		}
+		// an unreadable existing chunk is replaced, the write repairs it
+	}
+	if !exists {
+		var err error
+		if storagePath, err = s.pickStoragePath(); err != nil {
+			s.mu.Unlock()
+			return err
+		}
+	}
+
+	// overwrites only need room for the growth of the chunk
+	size := int64(chunkHeaderSize + len(data))
+	if s.maxBytes > 0 && s.usedBytes+s.reserved-s.sizes[chunkHandle]+size > s.maxBytes {
+		s.mu.Unlock()
+		return fmt.Errorf("%w: %d of %d bytes used, chunk needs %d", ErrQuotaExceeded, s.usedBytes+s.reserved, s.maxBytes, size)
+	}
+	s.reserved += size
+	s.mu.Unlock()
+
+	path := chunkPath(storagePath, chunkHandle)
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err == nil {
+		encoded := encodeChunk(chunkVersion, data)
+		if s.faults.PartialWrite(chunkHandle) {
+			// a torn write, acknowledged as if the whole chunk made it to disk
+			encoded = encoded[:chunkHeaderSize+len(data)/2]
+		}
+		err = s.writeChunkFile(ctx, storagePath, path, encoded)
+	}
+
+	s.mu.Lock()
+	s.reserved -= size
+	if err == nil && s.unhealthy[storagePath] {
+		// the directory failed while the chunk was written, and its chunks were reported lost
+		err = fmt.Errorf("storage directory %s failed", storagePath)
+	}
+	if err == nil {
+		if _, stored := s.chunks[chunkHandle]; !stored {
+			s.chunkCounts[storagePath]++
+		}
+		s.chunks[chunkHandle] = storagePath
+		s.usedBytes += size - s.sizes[chunkHandle]
+		s.sizes[chunkHandle] = size
+	}
+	// even a failed write may have changed the chunk file
+	s.cache.invalidate(chunkHandle)
+	s.mu.Unlock()
+
+	if err != nil {
+		// an abandoned write says nothing about the disk
+		if ctx.Err() != nil {
+			return fmt.Errorf("write abandoned: %w", err)
+		}
+		s.handleDiskError(storagePath, err)
 		return fmt.Errorf("failed to write chunk to disk: %v", err)
 	}
 
-	s.chunks[chunkHandle] = true
 	return nil
 }
 
-// ReadChunk reads chunk data from disk
+// ReadChunk reads chunk data from disk, verifying it against the chunk file header
 func (s *Storage) ReadChunk(chunkHandle string) ([]byte, error) {
+	_, data, err := s.readChunk(chunkHandle)
+	return data, err
+}
+
+// readChunk reads and verifies a chunk file, returning its header and payload. The payload belongs to
+// the caller, chunks served from the cache are copied
+func (s *Storage) readChunk(chunkHandle string) (chunkHeader, []byte, error) {
+	if header, data, ok := s.cache.get(chunkHandle); ok {
+		return header, bytes.Clone(data), nil
+	}
+
+	header, data, generation, err := s.readChunkFile(chunkHandle)
+	if err != nil {
+		return chunkHeader{}, nil, err
+	}
+	if s.cache != nil {
+		s.cache.put(chunkHandle, generation, header, bytes.Clone(data))
+	}
+
+	return header, data, nil
+}
+
+// VerifyChunk reads a chunk from disk, bypassing the cache, and checks it against its checksum. A corrupt
+// replica is dropped and reported like on any other read
+func (s *Storage) VerifyChunk(chunkHandle string) error {
+	_, _, _, err := s.readChunkFile(chunkHandle)
+	return err
+}
+
+// readChunkFile reads and verifies a chunk file from disk. Also returns the cache generation the data can be cached at
+func (s *Storage) readChunkFile(chunkHandle string) (chunkHeader, []byte, uint64, error) {
+	// the file is read without the lock, so a slow read doesn't hold up writers and the readers queued
+	// behind them. Writes replace chunk files by renaming, the read sees either the old or the new file
 	s.mu.RLock()
-	defer s.mu.RUnlock()
+	storagePath, exists := s.chunks[chunkHandle]
+	generation := s.cache.currentGeneration()
+	s.mu.RUnlock()
+	if !exists {
+		return chunkHeader{}, nil, 0, fmt.Errorf("%w: %s", errChunkNotFound, chunkHandle)
+	}
+
+	raw, err := os.ReadFile(chunkPath(storagePath, chunkHandle))
+	if errors.Is(err, os.ErrNotExist) {
+		// deleted since it was looked up
+		return chunkHeader{}, nil, 0, fmt.Errorf("%w: %s", errChunkNotFound, chunkHandle)
+	}
+	if err != nil {
+		s.handleDiskError(storagePath, err)
+		return chunkHeader{}, nil, 0, fmt.Errorf("failed to read chunk: %v", err)
+	}
+
+	if len(raw) > chunkHeaderSize && s.faults.CorruptRead(chunkHandle) {
+		common.FlipBit(raw[chunkHeaderSize:])
+	}
+
+	header, data, err := decodeChunk(raw)
+	if err != nil {
+		return chunkHeader{}, nil, 0, s.checkCorrupt(chunkHandle, fmt.Errorf("invalid chunk file: %w", err))
+	}
+
+	return header, data, generation, nil
+}
+
+// openChunk opens a chunk file and parses its header. The open file stays readable
+// even if the chunk is deleted while the caller reads it. Also returns the cache generation
+// data read from the file can be cached at
+func (s *Storage) openChunk(chunkHandle string) (*os.File, chunkHeader, uint64, error) {
+	s.mu.RLock()
+	storagePath, exists := s.chunks[chunkHandle]
+	if !exists {
+		s.mu.RUnlock()
+		return nil, chunkHeader{}, 0, fmt.Errorf("%w: %s", errChunkNotFound, chunkHandle)
+	}
+
+	generation := s.cache.currentGeneration()
+	file, err := os.Open(chunkPath(storagePath, chunkHandle))
+	s.mu.RUnlock()
+	if err != nil {
+		s.handleDiskError(storagePath, err)
+		return nil, chunkHeader{}, 0, fmt.Errorf("failed to read chunk: %v", err)
+	}
+
+	buf := make([]byte, chunkHeaderSize)
+	if _, err := io.ReadFull(file, buf); err != nil {
+		file.Close()
+		return nil, chunkHeader{}, 0, s.checkCorrupt(chunkHandle, fmt.Errorf("invalid chunk file: %w: file shorter than header", errCorruptChunk))
+	}
+
+	header, err := parseChunkHeader(buf)
+	if err != nil {
+		file.Close()
+		return nil, chunkHeader{}, 0, s.checkCorrupt(chunkHandle, fmt.Errorf("invalid chunk file: %w", err))
+	}
+
+	return file, header, generation, nil
+}
+
+// chunkRange clamps a requested range to the chunk payload, a length of 0 meaning the rest of the chunk
+func chunkRange(header chunkHeader, offset, length int64) (int64, int64, error) {
+	dataLength := int64(header.dataLength)
+	if offset < 0 || offset > dataLength || length < 0 {
+		return 0, 0, fmt.Errorf("range at offset %d out of bounds for chunk of %d bytes", offset, dataLength)
+	}
+
+	if length == 0 || offset+length > dataLength {
+		length = dataLength - offset
+	}
+
+	return offset, length, nil
+}
+
+// ReadChunkRange reads length bytes of chunk data starting at offset without loading the rest of the chunk.
+// Partial reads can't be verified against the chunk checksum
+func (s *Storage) ReadChunkRange(chunkHandle string, offset, length int64) ([]byte, error) {
+	if header, data, ok := s.cache.get(chunkHandle); ok {
+		offset, length, err := chunkRange(header, offset, length)
+		if err != nil {
+			return nil, err
+		}
+		return bytes.Clone(data[offset : offset+length]), nil
+	}
 
-	if !s.chunks[chunkHandle] {
-		return nil, fmt.Errorf("chunk not found: %s", chunkHandle)
+	file, header, _, err := s.openChunk(chunkHandle)
+	if err != nil {
+		return nil, err
 	}
+	defer file.Close()
 
-	chunkPath := filepath.Join(s.storagePath, chunkHandle)
-	data, err := os.ReadFile(chunkPath)
+	offset, length, err = chunkRange(header, offset, length)
 	if err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, length)
+	if _, err := file.ReadAt(data, int64(header.headerSize)+offset); err != nil {
+		if err == io.EOF {
+			return nil, s.checkCorrupt(chunkHandle, fmt.Errorf("invalid chunk file: %w: shorter than header claims", errCorruptChunk))
+		}
 		return nil, fmt.Errorf("failed to read chunk: %v", err)
 	}
 
 	return data, nil
 }
 
+// readBufferSize is the size of the pieces chunks are streamed in
+const readBufferSize = 1024 * 1024
+
+// readBufferPool recycles streaming read buffers across requests to keep garbage low
+var readBufferPool = sync.Pool{
+	New: func() any {
+		buf := make([]byte, readBufferSize)
+		return &buf
+	},
+}
+
+// StreamChunk reads length bytes of a chunk starting at offset piece by piece, passing each piece to send.
+// The buffer passed to send is reused once send returns. Whole chunk reads are verified against the
+// checksum as the chunk is read, so a corrupted chunk fails with an error after its data has been sent
+// and the receiver must discard it. Verified whole chunks are kept in the cache for later reads
+func (s *Storage) StreamChunk(chunkHandle string, offset, length int64, send func([]byte) error) error {
+	if header, data, ok := s.cache.get(chunkHandle); ok {
+		offset, length, err := chunkRange(header, offset, length)
+		if err != nil {
+			return err
+		}
+		for piece := range slices.Chunk(data[offset:offset+length], readBufferSize) {
+			if err := send(piece); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	file, header, generation, err := s.openChunk(chunkHandle)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	offset, length, err = chunkRange(header, offset, length)
+	if err != nil {
+		return err
+	}
+	verify := offset == 0 && length == int64(header.dataLength)
+	corrupt := verify && length > 0 && s.faults.CorruptRead(chunkHandle)
+
+	// whole chunks are collected for the cache as they are streamed
+	var collected []byte
+	if verify && s.cache != nil && length <= s.cache.maxBytes {
+		collected = make([]byte, 0, length)
+	}
+
+	bufPtr := readBufferPool.Get().(*[]byte)
+	defer readBufferPool.Put(bufPtr)
+	buf := *bufPtr
+
+	reader := io.NewSectionReader(file, int64(header.headerSize)+offset, length)
+	crc := crc32.New(castagnoliTable)
+	var read int64
+	for {
+		n, err := reader.Read(buf)
+		if n > 0 {
+			if corrupt {
+				common.FlipBit(buf[:n])
+				corrupt = false
+			}
+			if verify {
+				crc.Write(buf[:n])
+			}
+			if collected != nil {
+				collected = append(collected, buf[:n]...)
+			}
+			read += int64(n)
+			if sendErr := send(buf[:n]); sendErr != nil {
+				return sendErr
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read chunk: %v", err)
+		}
+	}
+
+	if read != length {
+		return s.checkCorrupt(chunkHandle, fmt.Errorf("invalid chunk file: %w: expected %d bytes of data, found %d", errCorruptChunk, length, read))
+	}
+
+	if verify && crc.Sum32() != header.checksum {
+		return s.checkCorrupt(chunkHandle, fmt.Errorf("invalid chunk file: %w: checksum mismatch", errCorruptChunk))
+	}
+	if collected != nil {
+		s.cache.put(chunkHandle, generation, header, collected)
+	}
+
+	return nil
+}
+
+// CopyChunk copies an existing chunk on disk to a new chunk handle with the given chunk version,
+// a version of 0 keeping the version of the source chunk. Copies are directed by the master,
+// so they replace whatever is stored under the destination handle
+func (s *Storage) CopyChunk(ctx context.Context, sourceHandle, destinationHandle string, chunkVersion int32) error {
+	header, data, err := s.readChunk(sourceHandle)
+	if err != nil {
+		return err
+	}
+
+	if chunkVersion == 0 {
+		chunkVersion = header.chunkVersion
+	}
+
+	return s.WriteChunk(ctx, destinationHandle, chunkVersion, data, true)
+}
+
 // HasChunk checks if a chunk exists
 func (s *Storage) HasChunk(chunkHandle string) bool {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	return s.chunks[chunkHandle]
+	_, exists := s.chunks[chunkHandle]
+	return exists
 }
 
 // ListChunks retuns all chunk handles
@@ -106,15 +783,66 @@ func (s *Storage) ListChunks() []string {
 
 // DeleteChunk deletes a chunk from disk
 func (s *Storage) DeleteChunk(chunkHandle string) error {
+	// waiting for a write of the chunk in progress, which would bring the chunk back
+	unlock := s.chunkLocks.Lock(chunkHandle)
+	defer unlock()
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	chunkPath := filepath.Join(s.storagePath, chunkHandle)
+	storagePath, exists := s.chunks[chunkHandle]
+	if !exists {
+		return fmt.Errorf("%w: %s", errChunkNotFound, chunkHandle)
+	}
 
-	if err := os.Remove(chunkPath); err != nil {
+	if err := os.Remove(chunkPath(storagePath, chunkHandle)); err != nil {
+		// handleDiskError takes the lock itself
+		go s.handleDiskError(storagePath, err)
 		return fmt.Errorf("failed to delete chunk: %v", err)
 	}
 
 	delete(s.chunks, chunkHandle)
+	s.chunkCounts[storagePath]--
+	s.usedBytes -= s.sizes[chunkHandle]
+	delete(s.sizes, chunkHandle)
+	s.cache.invalidate(chunkHandle)
 	return nil
 }
+
+// Capacity returns the total and free bytes across all healthy storage directories,
+// counting directories that share a filesystem only once. With a storage quota the
+// capacity is capped at the quota and free space at what is left of it
+func (s *Storage) Capacity() (int64, int64) {
+	var total, free int64
+	seen := make(map[string]bool)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, storagePath := range s.storagePaths {
+		if s.unhealthy[storagePath] {
+			continue
+		}
+
+		space, err := getDiskSpace(storagePath)
+		if err != nil {
+			log.Printf("Warning: failed to get disk space of %s: %v", storagePath, err)
+			continue
+		}
+
+		if seen[space.filesystemID] {
+			continue
+		}
+		seen[space.filesystemID] = true
+
+		total += space.total
+		free += space.free
+	}
+
+	if s.maxBytes > 0 {
+		total = min(total, s.maxBytes)
+		free = max(min(free, s.maxBytes-s.usedBytes-s.reserved), 0)
+	}
+
+	return total, free
+}
@@ -0,0 +1,173 @@
+package chunkserver
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// SyncMode controls when chunk writes are flushed to stable storage
+type SyncMode string
+
+const (
+	// SyncAlways fsyncs every chunk and its directory entries before acknowledging the write
+	SyncAlways SyncMode = "always"
+
+	// SyncPeriodic acknowledges writes immediately and fsyncs written chunks in the background
+	SyncPeriodic SyncMode = "periodic"
+
+	// SyncNone leaves flushing entirely to the operating system
+	SyncNone SyncMode = "none"
+)
+
+// ParseSyncMode parses a sync mode flag value
+func ParseSyncMode(mode string) (SyncMode, error) {
+	switch SyncMode(mode) {
+	case SyncAlways, SyncPeriodic, SyncNone:
+		return SyncMode(mode), nil
+	default:
+		return "", fmt.Errorf("unknown sync mode %q, expected always, periodic or none", mode)
+	}
+}
+
+// tempChunkSuffix marks chunk files still being written, they are renamed into place once complete
+const tempChunkSuffix = ".tmp"
+
+// writeSliceSize is how much of a chunk file is written between checks whether the write was abandoned
+const writeSliceSize = 4 << 20
+
+// writeChunkFile atomically writes a chunk file honoring the storage sync mode
+func (s *Storage) writeChunkFile(ctx context.Context, storagePath, path string, data []byte) error {
+	if err := writeFileAtomic(ctx, path, data, s.syncMode == SyncAlways); err != nil {
+		return err
+	}
+
+	switch s.syncMode {
+	case SyncAlways:
+		// making the directory entries of the shard directories durable too
+		for _, dir := range shardDirs(storagePath, path) {
+			if err := syncDir(dir); err != nil {
+				return err
+			}
+		}
+	case SyncPeriodic:
+		s.syncMu.Lock()
+		s.dirty[path] = true
+		// the rename only survives a crash once the directory holding the chunk is synced, and
+		// new shard directories once their parents are
+		s.dirtyDirs[filepath.Dir(path)] = true
+		for _, dir := range shardDirs(storagePath, path) {
+			s.dirtyDirs[dir] = true
+		}
+		s.syncMu.Unlock()
+	}
+
+	return nil
+}
+
+// shardDirs returns the directories above the shard directory holding the chunk file at path, up to
+// and including the storage directory, whose entries a new shard directory is added to
+func shardDirs(storagePath, path string) []string {
+	var dirs []string
+	for dir := filepath.Dir(filepath.Dir(path)); ; dir = filepath.Dir(dir) {
+		dirs = append(dirs, dir)
+		if dir == filepath.Clean(storagePath) || dir == filepath.Dir(dir) {
+			return dirs
+		}
+	}
+}
+
+// writeFileAtomic writes data to a temp file next to path and renames it into place, so a crash
+// never leaves a partially written file at path. With durable set the file and the rename are
+// fsynced before returning. A write abandoned by ctx is given up before the rename, leaving path as it was
+func writeFileAtomic(ctx context.Context, path string, data []byte, durable bool) error {
+	tempPath := path + tempChunkSuffix
+
+	file, err := os.OpenFile(tempPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	for written := 0; written < len(data) && err == nil; written += writeSliceSize {
+		if err = ctx.Err(); err == nil {
+			_, err = file.Write(data[written:min(written+writeSliceSize, len(data))])
+		}
+	}
+	if err == nil && durable {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = ctx.Err()
+	}
+	if err == nil {
+		err = os.Rename(tempPath, path)
+	}
+	if err != nil {
+		os.Remove(tempPath)
+		return err
+	}
+
+	if durable {
+		return syncDir(filepath.Dir(path))
+	}
+
+	return nil
+}
+
+// Sync flushes every chunk written since the last sync to stable storage, and then the directories
+// the chunks were renamed into
+func (s *Storage) Sync() error {
+	s.syncMu.Lock()
+	dirty, dirtyDirs := s.dirty, s.dirtyDirs
+	s.dirty, s.dirtyDirs = make(map[string]bool), make(map[string]bool)
+	s.syncMu.Unlock()
+
+	var firstErr error
+	for path := range dirty {
+		if err := syncFile(path); err != nil && !os.IsNotExist(err) && firstErr == nil {
+			firstErr = err
+		}
+	}
+	for dir := range dirtyDirs {
+		if err := syncDir(dir); err != nil && !os.IsNotExist(err) && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+// startPeriodicSync flushes written chunks every interval
+func (s *Storage) startPeriodicSync(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.closed:
+			return
+		case <-ticker.C:
+		}
+
+		if err := s.Sync(); err != nil {
+			log.Printf("Warning: periodic sync failed: %v", err)
+		}
+	}
+}
+
+// syncFile fsyncs an existing file
+func syncFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return file.Sync()
+}
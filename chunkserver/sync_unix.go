@@ -0,0 +1,16 @@
+//go:build !windows
+
+package chunkserver
+
+import "os"
+
+// syncDir fsyncs a directory so entries created in it survive a crash
+func syncDir(path string) error {
+	dir, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+
+	return dir.Sync()
+}
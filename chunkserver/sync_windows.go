@@ -0,0 +1,9 @@
+//go:build windows
+
+package chunkserver
+
+// syncDir is a no-op on windows, where directories can't be opened for syncing
+// and NTFS journals directory entries itself
+func syncDir(path string) error {
+	return nil
+}
@@ -0,0 +1,42 @@
+package chunkserver
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+// Tunables are the settings of a chunk server that SetTunables changes while it runs, without dropping
+// the transfers in flight
+type Tunables struct {
+	MaxIO                int           // chunk reads and writes running at once, 0 for no limit
+	MaxIOQueue           int           // chunk reads and writes waiting for a slot before requests are rejected
+	SlowRequestThreshold time.Duration // requests taking longer are logged, 0 logs none
+	LogRequests          bool          // every request is logged with its caller, duration and status
+}
+
+// Tunables returns the settings the chunk server currently runs with
+func (s *Server) Tunables() Tunables {
+	return *s.tunables.Load()
+}
+
+// SetTunables changes the settings of the running chunk server, e.g. when its configuration is reloaded.
+// Reads and writes already running under the previous IO limit finish, new ones count against the new limit
+func (s *Server) SetTunables(tunables Tunables) error {
+	if tunables.MaxIO < 0 || tunables.MaxIOQueue < 0 || tunables.SlowRequestThreshold < 0 {
+		return fmt.Errorf("invalid tunables: must not be negative")
+	}
+
+	previous := s.tunables.Swap(&tunables)
+	if previous == nil || previous.MaxIO != tunables.MaxIO || previous.MaxIOQueue != tunables.MaxIOQueue {
+		s.io.Store(newIOLimiter(tunables.MaxIO, tunables.MaxIOQueue))
+	}
+	s.slowRequests.SetThreshold(tunables.SlowRequestThreshold)
+	s.interceptors.SetLogRequests(tunables.LogRequests)
+
+	if previous != nil && *previous != tunables {
+		log.Printf("Tunables changed from %+v to %+v", *previous, tunables)
+	}
+
+	return nil
+}
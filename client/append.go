@@ -0,0 +1,133 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/harshvardha/distributed_file_system/common"
+	pb "github.com/harshvardha/distributed_file_system/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// record append retry settings, each retry may append the record again
+const (
+	maxAppendAttempts  = 5
+	appendRetryBackoff = 200 * time.Millisecond
+)
+
+// RecordAppend appends a record to a remote file, creating the file if needed, and returns the offset in the file it
+// was written at. Records appended by any number of clients at once never interleave, each is written whole at an offset
+// chosen by the primary replica of the file's last chunk. Appends are at least once: a failed attempt is retried and may
+// leave a duplicate or partial copy of the record in the file, so readers must be able to skip those, e.g. by record ids
+func (c *Client) RecordAppend(remoteName string, record []byte) (int64, error) {
+	if len(record) == 0 || len(record) > common.MaxRecordSize {
+		return 0, fmt.Errorf("record must hold between 1 and %d bytes, has %d", common.MaxRecordSize, len(record))
+	}
+
+	// Connecting to master server
+	conn, err := c.getConn(c.masterAddress)
+	if err != nil {
+		return 0, fmt.Errorf("failed to connect to master server: %v", err)
+	}
+	masterClient := pb.NewMasterClient(conn)
+
+	checksum := common.Checksum(record)
+	var fullChunkIndex *int32
+	var lastErr error
+	for attempt := 0; attempt < maxAppendAttempts; {
+		ctx, cancel := context.WithTimeout(context.Background(), c.timeouts.Metadata)
+		prepared, err := masterClient.PrepareAppend(ctx, &pb.PrepareAppendRequest{
+			Filename:       remoteName,
+			FullChunkIndex: fullChunkIndex,
+		})
+		cancel()
+		if status.Code(err) == codes.Aborted {
+			// an upload of the file is running, or the chunk's primary is gone and its lease hasn't expired yet
+			log.Printf("Warning: failed to prepare append to %s: %v", remoteName, err)
+			if attempt+1 < maxAppendAttempts {
+				c.observeRetry("record-append", c.masterAddress, attempt+1, err)
+			}
+			lastErr = err
+			time.Sleep(appendRetryBackoff << attempt)
+			attempt++
+			continue
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to prepare append: %w", checkMasterError(err))
+		}
+
+		chunkLoc := prepared.ChunkLocation
+		response, err := c.appendToPrimary(chunkLoc, record, checksum)
+		if err != nil {
+			log.Printf("Warning: failed to append record to chunk %d of %s: %v", chunkLoc.ChunkIndex, remoteName, err)
+			if code := status.Code(err); code == codes.InvalidArgument || code == codes.ResourceExhausted {
+				return 0, fmt.Errorf("failed to append record: %v", err)
+			}
+
+			if attempt+1 < maxAppendAttempts {
+				c.observeRetry("record-append", chunkLoc.PrimaryAddress, attempt+1, err)
+			}
+			lastErr = err
+			time.Sleep(appendRetryBackoff << attempt)
+			attempt++
+			continue
+		}
+
+		// the primary padded the chunk, the record goes to the next chunk
+		if response.ChunkFull {
+			fullChunkIndex = &chunkLoc.ChunkIndex
+			continue
+		}
+
+		ctx, cancel = context.WithTimeout(context.Background(), c.timeouts.Metadata)
+		_, err = masterClient.CompleteAppend(ctx, &pb.CompleteAppendRequest{
+			Filename:   remoteName,
+			Generation: prepared.Generation,
+			ChunkIndex: chunkLoc.ChunkIndex,
+			EndOffset:  response.Offset + int64(len(record)),
+		})
+		cancel()
+		if err != nil {
+			return 0, fmt.Errorf("failed to complete append: %w", checkMasterError(err))
+		}
+
+		return int64(chunkLoc.ChunkIndex)*common.ChunkSize + response.Offset, nil
+	}
+
+	return 0, fmt.Errorf("failed to append record to %s after %d attempts: %w", remoteName, maxAppendAttempts, lastErr)
+}
+
+// appendToPrimary sends a record to the replica holding the chunk's lease, which writes it to the secondaries
+func (c *Client) appendToPrimary(chunkLoc *pb.ChunkLocation, record []byte, checksum uint32) (*pb.RecordAppendResponse, error) {
+	conn, err := c.getConn(chunkLoc.PrimaryAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to chunk server %s: %v", chunkLoc.PrimaryAddress, err)
+	}
+
+	secondaries := slices.DeleteFunc(slices.Clone(chunkLoc.ChunkServerAddresses), func(address string) bool {
+		return address == chunkLoc.PrimaryAddress
+	})
+
+	chunkClient := pb.NewChunkServerClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeouts.ChunkWrite)
+	defer cancel()
+
+	start := time.Now()
+	response, err := chunkClient.RecordAppend(ctx, &pb.RecordAppendRequest{
+		ChunkHandle:        chunkLoc.ChunkHandle,
+		ChunkVersion:       chunkLoc.ChunkVersion,
+		Data:               record,
+		Checksum:           checksum,
+		SecondaryAddresses: secondaries,
+		LeaseExpiresAt:     chunkLoc.LeaseExpiresAt,
+		LeaseToken:         chunkLoc.LeaseToken,
+		AccessToken:        chunkLoc.AccessToken,
+	}, c.chunkCallOptions()...)
+	c.observeTransfer(chunkLoc.PrimaryAddress, chunkLoc.ChunkHandle, TransferUpload, int64(len(record)), start, err)
+
+	return response, err
+}
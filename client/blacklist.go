@@ -0,0 +1,88 @@
+package client
+
+import (
+	"log"
+	"slices"
+	"sync"
+	"time"
+)
+
+// defaultBlacklistWindow is how long a replica that failed a read or write is tried last, see WithReplicaBlacklist
+const defaultBlacklistWindow = time.Minute
+
+// WithReplicaBlacklist sets how long a chunk server that failed to read or write a chunk is tried after
+// the other replicas when reading further chunks. A window of 0 disables blacklisting. Writes still go to
+// every replica the master assigned, the circuit breaker keeps them from waiting on unreachable servers
+func WithReplicaBlacklist(window time.Duration) ClientOption {
+	return func(c *Client) {
+		c.blacklist.window = window
+	}
+}
+
+// replicaBlacklist remembers chunk servers that recently failed a chunk read or write
+type replicaBlacklist struct {
+	window time.Duration
+
+	mu    sync.Mutex
+	until map[string]time.Time // key: chunk server address, value: end of its blacklisting
+}
+
+func newReplicaBlacklist(window time.Duration) *replicaBlacklist {
+	return &replicaBlacklist{
+		window: window,
+		until:  make(map[string]time.Time),
+	}
+}
+
+// add blacklists a chunk server for the window
+func (b *replicaBlacklist) add(address string) {
+	if b.window <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, exists := b.until[address]; !exists {
+		log.Printf("Trying %s after other replicas for %s", address, b.window)
+	}
+	b.until[address] = time.Now().Add(b.window)
+}
+
+// remove takes a chunk server that served a request off the blacklist
+func (b *replicaBlacklist) remove(address string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.until, address)
+}
+
+// order returns the addresses with blacklisted chunk servers moved after the others, keeping the order otherwise
+func (b *replicaBlacklist) order(addresses []string) []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	blacklisted := func(address string) bool {
+		until, exists := b.until[address]
+		if exists && !now.Before(until) {
+			delete(b.until, address)
+			return false
+		}
+		return exists
+	}
+
+	ordered := slices.Clone(addresses)
+	slices.SortStableFunc(ordered, func(a, b string) int {
+		switch aListed, bListed := blacklisted(a), blacklisted(b); {
+		case aListed == bListed:
+			return 0
+		case aListed:
+			return 1
+		default:
+			return -1
+		}
+	})
+
+	return ordered
+}
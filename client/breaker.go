@@ -0,0 +1,155 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	pb "github.com/harshvardha/distributed_file_system/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// default circuit breaker settings, see WithCircuitBreaker
+const (
+	defaultBreakerThreshold = 5
+	defaultBreakerCooldown  = 10 * time.Second
+)
+
+// maxCachedDownloads is the number of download lookups kept to fall back on while the master is unreachable
+const maxCachedDownloads = 1024
+
+// WithCircuitBreaker sets how many consecutive calls to a server must fail, because it is unreachable or
+// doesn't answer in time, before further calls to it fail fast with ErrCircuitOpen for cooldown. After
+// the cooldown a single call is let through to probe the server, closing the circuit if it succeeds.
+// A threshold of 0 disables the circuit breaker
+func WithCircuitBreaker(threshold int, cooldown time.Duration) ClientOption {
+	return func(c *Client) {
+		c.breakerThreshold = threshold
+		c.breakerCooldown = cooldown
+	}
+}
+
+// circuitBreaker tracks consecutive failures of calls to one server
+type circuitBreaker struct {
+	address   string
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	failures int       // consecutive failed calls
+	openedAt time.Time // when the circuit last opened, zero while closed
+	probing  bool      // a call probing the server after the cooldown is in flight
+}
+
+func newCircuitBreaker(address string, threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		address:   address,
+		threshold: threshold,
+		cooldown:  cooldown,
+	}
+}
+
+// allow returns an error matching ErrCircuitOpen if the call must fail fast, otherwise the caller
+// must pass the call's outcome to record
+func (b *circuitBreaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.openedAt.IsZero() {
+		return nil
+	}
+	if time.Since(b.openedAt) >= b.cooldown && !b.probing {
+		b.probing = true
+		return nil
+	}
+
+	err := status.Errorf(codes.Unavailable, "%v to %s after %d failed calls", ErrCircuitOpen, b.address, b.failures)
+	return &typedError{err: err, target: ErrCircuitOpen}
+}
+
+// record counts the outcome of an allowed call
+func (b *circuitBreaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	// a server asking to be called again later answered, so it is reachable
+	if _, asked := retryDelay(err); asked {
+		err = nil
+	}
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		b.failures++
+		if b.failures >= b.threshold {
+			if b.openedAt.IsZero() {
+				log.Printf("Warning: %d calls to %s failed in a row, failing further calls fast for %s", b.failures, b.address, b.cooldown)
+			}
+			b.openedAt = time.Now()
+		}
+	default:
+		b.failures = 0
+		b.openedAt = time.Time{}
+	}
+}
+
+// unaryInterceptor fails unary calls fast while the circuit is open
+func (b *circuitBreaker) unaryInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if err := b.allow(); err != nil {
+		return err
+	}
+
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	b.record(err)
+	return err
+}
+
+// streamInterceptor fails opening streams fast while the circuit is open, only failures to open a stream count
+func (b *circuitBreaker) streamInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	if err := b.allow(); err != nil {
+		return nil, err
+	}
+
+	stream, err := streamer(ctx, desc, cc, method, opts...)
+	b.record(err)
+	return stream, err
+}
+
+// requestDownload asks the master for the size and chunk locations of a file version. While the master
+// is unreachable it falls back to the answer of an earlier lookup of the same version, if any, whose
+// chunks may since have been replaced or moved
+func (c *Client) requestDownload(ctx context.Context, masterClient pb.MasterClient, remoteName string, generation int64) (*pb.DownloadFileResponse, error) {
+	key := fmt.Sprintf("%s@%d", remoteName, generation)
+
+	response, err := masterClient.DownloadFile(ctx, &pb.DownloadFileRequest{
+		Filename:   remoteName,
+		Generation: generation,
+	})
+	if err == nil {
+		c.mu.Lock()
+		if len(c.downloads) >= maxCachedDownloads {
+			clear(c.downloads)
+		}
+		c.downloads[key] = response
+		c.mu.Unlock()
+
+		return response, nil
+	}
+
+	if _, asked := retryDelay(err); (status.Code(err) == codes.Unavailable && !asked) || errors.Is(err, ErrCircuitOpen) {
+		c.mu.Lock()
+		cached, exists := c.downloads[key]
+		c.mu.Unlock()
+
+		if exists {
+			log.Printf("Warning: master unavailable, using cached chunk locations of %s: %v", remoteName, err)
+			return cached, nil
+		}
+	}
+
+	return nil, fmt.Errorf("failed to request download: %w", checkMasterError(err))
+}
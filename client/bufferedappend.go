@@ -0,0 +1,124 @@
+package client
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/harshvardha/distributed_file_system/common"
+)
+
+// DefaultAppendBufferSize is the batch size of a BufferedAppender created with a size of 0
+const DefaultAppendBufferSize = 1024 * 1024
+
+// BufferedAppender collects small records appended to a remote file in memory and appends them to the file as a
+// single record once the buffer fills up or Flush is called, so each small record doesn't cost a round trip to the
+// master and the replicas. The records of a batch stay in order and land next to each other within one chunk,
+// batches are appended at least once like any record. It is safe for concurrent use
+type BufferedAppender struct {
+	client     *Client
+	remoteName string
+	size       int
+
+	mu     sync.Mutex
+	buffer []byte
+	err    error // failure of a flush, returned by every later call
+}
+
+// NewBufferedAppender returns an appender batching records to remoteName up to size bytes, at most
+// common.MaxRecordSize so a batch always fits in a chunk. A size of 0 uses DefaultAppendBufferSize
+func (c *Client) NewBufferedAppender(remoteName string, size int) *BufferedAppender {
+	if size <= 0 {
+		size = DefaultAppendBufferSize
+	}
+
+	return &BufferedAppender{
+		client:     c,
+		remoteName: remoteName,
+		size:       min(size, common.MaxRecordSize),
+	}
+}
+
+// Append adds a record to the buffer, first flushing the records buffered so far if it doesn't fit.
+// A record larger than the buffer is appended on its own right away
+func (a *BufferedAppender) Append(record []byte) error {
+	if len(record) == 0 || len(record) > common.MaxRecordSize {
+		return fmt.Errorf("record must hold between 1 and %d bytes, has %d", common.MaxRecordSize, len(record))
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.err != nil {
+		return a.err
+	}
+
+	if len(a.buffer)+len(record) > a.size {
+		if err := a.flush(); err != nil {
+			return err
+		}
+	}
+	if len(record) > a.size {
+		_, err := a.client.RecordAppend(a.remoteName, record)
+		return err
+	}
+
+	a.buffer = append(a.buffer, record...)
+	return nil
+}
+
+// Buffered returns the number of bytes appended but not flushed yet
+func (a *BufferedAppender) Buffered() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return len(a.buffer)
+}
+
+// Flush appends the buffered records to the file, returning once the replicas acknowledged them
+func (a *BufferedAppender) Flush() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.err != nil {
+		return a.err
+	}
+
+	return a.flush()
+}
+
+// Sync is Flush, for callers used to syncing files
+func (a *BufferedAppender) Sync() error {
+	return a.Flush()
+}
+
+// Close flushes the buffered records. The appender can't be used afterwards
+func (a *BufferedAppender) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.err != nil {
+		return a.err
+	}
+
+	err := a.flush()
+	if err == nil {
+		a.err = fmt.Errorf("appender to %s closed", a.remoteName)
+	}
+	return err
+}
+
+// flush appends the buffer as one record. A failed flush keeps failing, since the file may or may not
+// hold the batch. The caller must hold the lock
+func (a *BufferedAppender) flush() error {
+	if len(a.buffer) == 0 {
+		return nil
+	}
+
+	if _, err := a.client.RecordAppend(a.remoteName, a.buffer); err != nil {
+		a.err = fmt.Errorf("failed to flush %d buffered bytes to %s: %w", len(a.buffer), a.remoteName, err)
+		return a.err
+	}
+
+	a.buffer = a.buffer[:0]
+	return nil
+}
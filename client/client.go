@@ -1,32 +1,163 @@
 package client
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/harshvardha/distributed_file_system/common"
 	pb "github.com/harshvardha/distributed_file_system/proto"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+// maxParallelDownloads is the number of files downloaded concurrently by DownloadPrefix
+const maxParallelDownloads = 4
+
+// maxParallelReplicaWrites is the number of replicas of a chunk written concurrently
+const maxParallelReplicaWrites = 3
+
 // Client represents a dfs client
 type Client struct {
 	masterAddress string
+	mu            sync.Mutex
+	conns         map[string]*grpc.ClientConn // key: server address, value: cached connection
+	progress      ProgressFunc
+	compression   string // wire compressor of chunk data, see SetCompression
+	tuning        common.ConnTuning
+	timeouts      Timeouts
+	interceptors  []grpc.UnaryClientInterceptor
+	priority      common.Priority // priority every request is tagged with, empty for untagged
+	token         string          // user token authenticating requests to the master, empty for anonymous
+
+	breakerThreshold int                                 // consecutive failures opening a server's circuit, 0 disables it
+	breakerCooldown  time.Duration                       // how long an open circuit fails calls fast
+	downloads        map[string]*pb.DownloadFileResponse // key: file@generation, value: latest download lookup
+	blacklist        *replicaBlacklist                   // replicas tried last after failing
+	maxInFlightBytes int64                               // largest flow window of a chunk server, 0 disables flow control
+	windows          map[string]*flowWindow              // key: chunk server address, value: its flow window
+	readAhead        int                                 // chunks a FileReader downloads ahead of the one being read
+	metrics          MetricsRecorder                     // receives measurements of calls, transfers and retries, nil when not recorded
 }
 
 // NewClient creates a new DFS Client
-func NewClient(masterAddress string) *Client {
-	return &Client{
+func NewClient(masterAddress string, options ...ClientOption) *Client {
+	c := &Client{
 		masterAddress: masterAddress,
+		conns:         make(map[string]*grpc.ClientConn),
+		tuning:        common.DefaultConnTuning(),
+		timeouts:      DefaultTimeouts(),
+
+		breakerThreshold: defaultBreakerThreshold,
+		breakerCooldown:  defaultBreakerCooldown,
+		downloads:        make(map[string]*pb.DownloadFileResponse),
+		blacklist:        newReplicaBlacklist(defaultBlacklistWindow),
+		maxInFlightBytes: defaultMaxInFlightBytes,
+		windows:          make(map[string]*flowWindow),
+		readAhead:        defaultReadAhead,
+	}
+	for _, option := range options {
+		option(c)
+	}
+
+	return c
+}
+
+// getConn returns a cached connection to the given server, creating it on first use
+// so repeated operations don't reconnect every time
+func (c *Client) getConn(address string) (*grpc.ClientConn, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if conn, exists := c.conns[address]; exists {
+		return conn, nil
+	}
+
+	dialOptions := c.tuning.DialOptions()
+	if c.metrics != nil {
+		dialOptions = append(dialOptions, metricsDialOptions(c.metrics, address)...)
+	}
+	dialOptions = append(dialOptions, grpc.WithChainUnaryInterceptor(c.interceptors...))
+	if c.priority != "" {
+		dialOptions = append(dialOptions, priorityDialOptions(c.priority)...)
+	}
+	if c.token != "" && address == c.masterAddress {
+		// chunk servers authorize requests by the master's chunk tokens, the user token only goes to the master
+		dialOptions = append(dialOptions, common.TokenDialOptions(c.token)...)
+	}
+	if c.breakerThreshold > 0 {
+		// the breaker runs after the caller's interceptors, so retries are refused fast too
+		breaker := newCircuitBreaker(address, c.breakerThreshold, c.breakerCooldown)
+		dialOptions = append(dialOptions, grpc.WithChainUnaryInterceptor(breaker.unaryInterceptor), grpc.WithStreamInterceptor(breaker.streamInterceptor))
+	}
+	conn, err := grpc.NewClient(address, dialOptions...)
+	if err != nil {
+		return nil, err
+	}
+
+	c.conns[address] = conn
+	return conn, nil
+}
+
+// Close closes all the connections held by the client
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var errs []error
+	for address, conn := range c.conns {
+		if err := conn.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close connection to %s: %v", address, err))
+		}
+		delete(c.conns, address)
 	}
+
+	return errors.Join(errs...)
+}
+
+// UploadOptions are optional settings for an upload
+type UploadOptions struct {
+	Hints *pb.PlacementHints // preferences for where the master places the file's chunks
+
+	// IfGenerationMatch only overwrites this generation of the file, 0 if the file must not exist yet.
+	// Uploads whose condition fails return an error matching ErrGenerationMismatch
+	IfGenerationMatch *int64
+
+	// Immutable rejects deleting, renaming or overwriting the file once the upload completes,
+	// for Retention or forever when Retention is 0
+	Immutable bool
+	Retention time.Duration
+
+	Tags map[string]string // user defined key value tags of the file
+
+	// Exclusive only creates the file, failing with an error matching ErrFileExists when the file
+	// already exists or another client is uploading it, so the first of several writers wins
+	Exclusive bool
+
+	Mode uint32 // permission bits of a new file, 0 for 0644, an overwritten file keeps its own
+
+	Tier string // storage tier of the chunk servers the file is placed on, e.g. ssd, empty for any
+
+	StorageClass string // standard, reduced-redundancy or archive, empty for standard
 }
 
 // UploadFile uploads a file to the dfs
 func (c *Client) UploadFile(localPath, remoteName string) error {
+	return c.UploadFileWithOptions(localPath, remoteName, UploadOptions{})
+}
+
+// UploadFileWithOptions uploads a file to the DFS using the given upload options
+func (c *Client) UploadFileWithOptions(localPath, remoteName string, options UploadOptions) error {
 	log.Printf("Uploading file: %s as %s", localPath, remoteName)
 
 	// Reading file
@@ -35,195 +166,1507 @@ func (c *Client) UploadFile(localPath, remoteName string) error {
 		return fmt.Errorf("failed to read file: %v", err)
 	}
 
+	return c.upload(data, remoteName, options)
+}
+
+// UploadReader uploads everything read from r to the dfs. Streams longer than a chunk are written one
+// chunk at a time as they are read, so only a chunk of the stream is held in memory
+func (c *Client) UploadReader(r io.Reader, remoteName string) error {
+	return c.UploadReaderWithOptions(r, remoteName, UploadOptions{})
+}
+
+// UploadReaderWithOptions uploads everything read from r to the dfs using the given upload options
+func (c *Client) UploadReaderWithOptions(r io.Reader, remoteName string, options UploadOptions) error {
+	log.Printf("Uploading stream as %s", remoteName)
+
+	// a stream ending within the first chunk is uploaded like a file, its size is known once it is read
+	first, err := io.ReadAll(io.LimitReader(r, common.ChunkSize))
+	if err != nil {
+		return fmt.Errorf("failed to read input: %v", err)
+	}
+	if len(first) < common.ChunkSize {
+		return c.upload(first, remoteName, options)
+	}
+
+	return c.uploadStream(first, r, remoteName, options)
+}
+
+// upload allocates chunks for data on the master and writes them to the chunk servers
+func (c *Client) upload(data []byte, remoteName string, options UploadOptions) error {
 	filesize := int64(len(data))
 	log.Printf("File size: %d bytes", filesize)
 
 	// Creating a connection to master server
-	conn, err := grpc.NewClient(c.masterAddress, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := c.getConn(c.masterAddress)
 	if err != nil {
 		return fmt.Errorf("failed to connect to master server: %v", err)
 	}
-	defer conn.Close()
 
 	masterClient := pb.NewMasterClient(conn)
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeouts.Upload)
 	defer cancel()
 
-	// Request chunk allocation
+	// Request chunk allocation, small files are sent along so the master can store them inline
+	var inlineData []byte
+	if filesize <= common.MaxInlineSize {
+		inlineData = data
+	}
 	response, err := masterClient.UploadFile(ctx, &pb.UploadFileRequest{
-		Filename: remoteName,
-		Filesize: filesize,
+		Filename:          remoteName,
+		Filesize:          filesize,
+		Hints:             options.Hints,
+		IfGenerationMatch: options.IfGenerationMatch,
+		Immutable:         options.Immutable,
+		RetentionSeconds:  int64(options.Retention / time.Second),
+		Tags:              options.Tags,
+		Exclusive:         options.Exclusive,
+		Mode:              options.Mode,
+		Data:              inlineData,
+		StorageTier:       options.Tier,
+		StorageClass:      options.StorageClass,
 	})
 	if err != nil {
-		return fmt.Errorf("failed to request file upload: %v", err)
+		return fmt.Errorf("failed to request file upload: %w", checkMasterError(err))
+	}
+
+	if response.Inline {
+		log.Printf("File stored inline by the master")
+	} else {
+		log.Printf("Recieved %d chunk locations", len(response.ChunkLocations))
 	}
 
-	log.Printf("Recieved %d chunk locations", len(response.ChunkLocations))
+	// the master abandons the upload if the session runs out while chunks are written, an overwritten file stays as it was
+	stopRenewing := c.renewUpload(masterClient, remoteName, response.UploadId, response.ExpiresAt)
 
-	// Uploading chunks to chunk servers
+	// Uploading chunks to chunk servers, several at once so one slow replica only holds up the chunks it stores
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	progress := Progress{Filename: remoteName, TotalBytes: filesize, TotalChunks: len(response.ChunkLocations)}
+	sem := make(chan struct{}, maxParallelChunkWrites)
 	for _, chunkLoc := range response.ChunkLocations {
-		if err := c.uploadChunk(data, chunkLoc); err != nil {
-			return fmt.Errorf("failed to upload chunk %d: %v", chunkLoc.ChunkIndex, err)
+		sem <- struct{}{}
+
+		// not starting further chunks once one failed, the upload is abandoned anyway
+		mu.Lock()
+		failed := firstErr != nil
+		mu.Unlock()
+		if failed {
+			<-sem
+			break
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			err := c.uploadChunk(data, chunkLoc)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("failed to upload chunk %d: %w", chunkLoc.ChunkIndex, err)
+				}
+				return
+			}
+
+			progress.ChunksDone++
+			progress.BytesTransferred += common.ChunkLength(filesize, int(chunkLoc.ChunkIndex))
+			c.reportProgress(progress)
+		}()
+	}
+	wg.Wait()
+	stopRenewing()
+
+	if firstErr != nil {
+		// releasing the file so it can be uploaded again right away
+		if completeErr := c.completeUpload(masterClient, remoteName, response.UploadId, true, nil); completeErr != nil {
+			log.Printf("Warning: %v", completeErr)
 		}
+		return firstErr
+	}
+
+	if err := c.completeUpload(masterClient, remoteName, response.UploadId, false, nil); err != nil {
+		return err
+	}
+
+	log.Printf("Successfully uploaded file: %s, generation: %d", remoteName, response.Generation)
+	return nil
+}
+
+// completeUpload tells the master the upload ended, failing if a newer upload of the file replaced it.
+// Streaming uploads pass the size of the file, other uploads nil
+func (c *Client) completeUpload(masterClient pb.MasterClient, remoteName, uploadID string, failed bool, filesize *int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeouts.Metadata)
+	defer cancel()
+
+	_, err := masterClient.CompleteUpload(ctx, &pb.CompleteUploadRequest{
+		Filename: remoteName,
+		UploadId: uploadID,
+		Failed:   failed,
+		Filesize: filesize,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to complete upload: %v", err)
 	}
 
-	log.Printf("Successfully uploaded file: %s", remoteName)
 	return nil
 }
 
-// uploadChunk uploads a single chunk to chunk servers
+// renewUpload renews the upload session in the background once a third of its remaining time has passed,
+// until the returned function is called. Masters not reporting a session expiry don't expire uploads
+func (c *Client) renewUpload(masterClient pb.MasterClient, remoteName, uploadID string, expiresAt int64) func() {
+	if expiresAt == 0 {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-time.After(max(time.Until(time.Unix(0, expiresAt))/3, time.Second)):
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), c.timeouts.Metadata)
+			response, err := masterClient.RenewUpload(ctx, &pb.RenewUploadRequest{
+				Filename: remoteName,
+				UploadId: uploadID,
+			})
+			cancel()
+			if status.Code(err) == codes.Aborted {
+				log.Printf("Warning: upload session of %s ended, the upload will fail: %v", remoteName, err)
+				return
+			}
+			if err != nil {
+				// retried until the session runs out, the master may be restarting
+				log.Printf("Warning: failed to renew upload session of %s: %v", remoteName, err)
+				continue
+			}
+			expiresAt = response.ExpiresAt
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// uploadChunk uploads a single chunk of the file data to chunk servers
 func (c *Client) uploadChunk(fileData []byte, chunkLoc *pb.ChunkLocation) error {
 	// Calculating chunk data range
-	chunkIndex := int(chunkLoc.ChunkIndex)
-	start := chunkIndex * common.ChunkSize
+	start := int(chunkLoc.ChunkIndex) * common.ChunkSize
 	end := min(start+common.ChunkSize, len(fileData))
 
-	chunkData := fileData[start:end]
+	return c.writeChunkData(fileData[start:end], chunkLoc)
+}
 
+// writeChunkData writes the data of a chunk to the chunk servers it was placed on
+func (c *Client) writeChunkData(chunkData []byte, chunkLoc *pb.ChunkLocation) error {
+	chunkIndex := int(chunkLoc.ChunkIndex)
 	log.Printf("Uploading chunk %d (%s): %d bytes to %d servers", chunkIndex, chunkLoc.ChunkHandle, len(chunkData), len(chunkLoc.ChunkServerAddresses))
 
-	// Upload to all replica servers
-	for _, serverAddr := range chunkLoc.ChunkServerAddresses {
-		if err := c.writeChunkToServer(serverAddr, chunkLoc.ChunkHandle, chunkData, chunkLoc.ChunkIndex); err != nil {
-			log.Printf("Warning: failed to write chunk to %s: %v", serverAddr, err)
-			// Continuing with other replicas
-		} else {
-			log.Printf("Successfully wrote chunk %d to %s", chunkIndex, serverAddr)
+	if len(chunkLoc.ChunkServerAddresses) == 0 {
+		return ErrNoChunkServers
+	}
+
+	// replicas verify the checksum before storing the chunk, rejecting data corrupted on the way
+	checksum := common.Checksum(chunkData)
+	req := &pb.WriteChunkRequest{
+		ChunkHandle:  chunkLoc.ChunkHandle,
+		Data:         chunkData,
+		ChunkIndex:   chunkLoc.ChunkIndex,
+		ChunkVersion: chunkLoc.ChunkVersion,
+		Checksum:     &checksum,
+		AccessToken:  chunkLoc.AccessToken,
+	}
+
+	// the primary holding the chunk's lease stores it and forwards it to the secondaries
+	addresses := chunkLoc.ChunkServerAddresses
+	var lastErr error
+	if chunkLoc.PrimaryAddress != "" {
+		primaryReq := &pb.WriteChunkRequest{
+			ChunkHandle:  req.ChunkHandle,
+			Data:         req.Data,
+			ChunkIndex:   req.ChunkIndex,
+			ChunkVersion: req.ChunkVersion,
+			Checksum:     req.Checksum,
+			SecondaryAddresses: slices.DeleteFunc(slices.Clone(addresses), func(address string) bool {
+				return address == chunkLoc.PrimaryAddress
+			}),
+			LeaseExpiresAt: chunkLoc.LeaseExpiresAt,
+			LeaseToken:     chunkLoc.LeaseToken,
+			AccessToken:    req.AccessToken,
+		}
+
+		response, err := c.writeChunkPaced(chunkLoc.PrimaryAddress, primaryReq)
+		if err == nil {
+			for _, serverAddr := range response.FailedSecondaries {
+				log.Printf("Warning: primary %s failed to write chunk %d to %s", chunkLoc.PrimaryAddress, chunkIndex, serverAddr)
+				c.blacklist.add(serverAddr)
+			}
+			log.Printf("Successfully wrote chunk %d through primary %s", chunkIndex, chunkLoc.PrimaryAddress)
+			return nil
 		}
+
+		// a cluster signing leases has its replicas take writes from the primary only
+		if chunkLoc.LeaseToken != "" {
+			c.blacklist.add(chunkLoc.PrimaryAddress)
+			return replicaError(fmt.Errorf("failed to write chunk to primary %s: %v", chunkLoc.PrimaryAddress, err), false)
+		}
+
+		// nothing else writes a new chunk, so without the primary its replicas can take the whole chunk directly
+		log.Printf("Warning: failed to write chunk %d to primary %s, writing the other replicas directly: %v", chunkIndex, chunkLoc.PrimaryAddress, err)
+		c.blacklist.add(chunkLoc.PrimaryAddress)
+		c.observeRetry("write-chunk", chunkLoc.PrimaryAddress, 1, err)
+		addresses = primaryReq.SecondaryAddresses
+		lastErr = err
+	}
+
+	// Upload to all replica servers concurrently, so a chunk takes as long as its slowest replica rather than all of them
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		written int
+	)
+	sem := make(chan struct{}, maxParallelReplicaWrites)
+
+	for _, serverAddr := range addresses {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			_, err := c.writeChunkPaced(serverAddr, req)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				log.Printf("Warning: failed to write chunk to %s: %v", serverAddr, err)
+				c.blacklist.add(serverAddr)
+				lastErr = err
+				// Continuing with other replicas
+				return
+			}
+			log.Printf("Successfully wrote chunk %d to %s", chunkIndex, serverAddr)
+			written++
+		}()
+	}
+	wg.Wait()
+
+	if written == 0 {
+		return replicaError(fmt.Errorf("failed to write chunk to any server: %v", lastErr), false)
 	}
 
 	return nil
 }
 
-// writeChunkToServer writes chunk data to a specific chunk server
-func (c *Client) writeChunkToServer(serverAddr string, chunkHandle string, data []byte, chunkIndex int32) error {
-	conn, err := grpc.NewClient(serverAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+// writeChunk sends a chunk write to a specific chunk server
+func (c *Client) writeChunk(serverAddr string, req *pb.WriteChunkRequest) (*pb.WriteChunkResponse, error) {
+	conn, err := c.getConn(serverAddr)
 	if err != nil {
-		return fmt.Errorf("failed to connect to chunk server %s: %v", serverAddr, err)
+		return nil, fmt.Errorf("failed to connect to chunk server %s: %v", serverAddr, err)
 	}
-	defer conn.Close()
 
 	chunkClient := pb.NewChunkServerClient(conn)
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeouts.ChunkWrite)
 	defer cancel()
 
-	_, err = chunkClient.WriteChunk(ctx, &pb.WriteChunkRequest{
-		ChunkHandle: chunkHandle,
-		Data:        data,
-		ChunkIndex:  chunkIndex,
-	})
+	start := time.Now()
+	response, err := chunkClient.WriteChunk(ctx, req, c.chunkCallOptions()...)
+	c.observeTransfer(serverAddr, req.ChunkHandle, TransferUpload, int64(len(req.Data)), start, err)
 
-	return err
+	return response, err
 }
 
 // DownloadFile downloads a file from the DFS
 func (c *Client) DownloadFile(remoteName string, localPath string) error {
+	return c.DownloadFileVersion(remoteName, 0, localPath)
+}
+
+// DownloadFileVersion downloads the version of a file with the given generation, 0 for the current one
+func (c *Client) DownloadFileVersion(remoteName string, generation int64, localPath string) error {
 	log.Printf("Downloading file: %s to %s", remoteName, localPath)
 
+	file, err := os.Create(localPath)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %v", err)
+	}
+
+	if err := c.DownloadVersion(remoteName, generation, file); err != nil {
+		// not leaving a partially written file behind
+		file.Close()
+		os.Remove(localPath)
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write file: %v", err)
+	}
+
+	log.Printf("Successfully downloaded file: %s", remoteName)
+	return nil
+}
+
+// Download streams a file from the DFS into w one chunk at a time, in chunk order
+func (c *Client) Download(remoteName string, w io.Writer) error {
+	return c.DownloadVersion(remoteName, 0, w)
+}
+
+// DownloadVersion streams the version of a file with the given generation into w, 0 for the current one
+func (c *Client) DownloadVersion(remoteName string, generation int64, w io.Writer) error {
 	// Connecting to master server
-	conn, err := grpc.NewClient(c.masterAddress, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := c.getConn(c.masterAddress)
 	if err != nil {
 		return fmt.Errorf("failed to connect to master server: %v", err)
 	}
-	defer conn.Close()
 
 	masterClient := pb.NewMasterClient(conn)
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeouts.Download)
 	defer cancel()
 
 	// Requesting file metadata and chunk locations
-	response, err := masterClient.DownloadFile(ctx, &pb.DownloadFileRequest{
-		Filename: remoteName,
-	})
+	response, err := c.requestDownload(ctx, masterClient, remoteName, generation)
 	if err != nil {
-		return fmt.Errorf("failed to request download: %v", err)
+		return err
 	}
 
 	log.Printf("File size: %d bytes, %d chunks", response.Filesize, len(response.ChunkLocation))
 
-	// Downloading chunks
-	fileData := make([]byte, response.Filesize)
-	for _, chunkLoc := range response.ChunkLocation {
+	// files stored inline come with their contents
+	if len(response.Data) > 0 {
+		if _, err := w.Write(response.Data); err != nil {
+			return fmt.Errorf("failed to write file: %v", err)
+		}
+		return nil
+	}
+
+	chunkLocations := slices.Clone(response.ChunkLocation)
+	slices.SortFunc(chunkLocations, func(a, b *pb.ChunkLocation) int {
+		return int(a.ChunkIndex - b.ChunkIndex)
+	})
+
+	// Downloading chunks and writing them out in order
+	progress := Progress{Filename: remoteName, TotalBytes: response.Filesize, TotalChunks: len(chunkLocations)}
+	for _, chunkLoc := range chunkLocations {
+		// chunks being appended to may hold records that aren't part of the file yet, or none at all
+		length := common.ChunkLength(response.Filesize, int(chunkLoc.ChunkIndex))
+		if length == 0 {
+			continue
+		}
+
 		chunkData, err := c.downloadChunk(chunkLoc)
 		if err != nil {
-			return fmt.Errorf("failed to download chunk %d: %v", chunkLoc.ChunkIndex, err)
+			return fmt.Errorf("failed to download chunk %d: %w", chunkLoc.ChunkIndex, err)
+		}
+		if int64(len(chunkData)) > length {
+			chunkData = chunkData[:length]
 		}
 
-		// Copying chunk data to file buffer
-		chunkIndex := int(chunkLoc.ChunkIndex)
-		start := chunkIndex * common.ChunkSize
-		copy(fileData[start:], chunkData)
+		if _, err := w.Write(chunkData); err != nil {
+			return fmt.Errorf("failed to write chunk %d: %v", chunkLoc.ChunkIndex, err)
+		}
+
+		progress.ChunksDone++
+		progress.BytesTransferred += int64(len(chunkData))
+		c.reportProgress(progress)
 	}
 
-	// Writing file to local disk
-	if err := os.WriteFile(localPath, fileData, 0644); err != nil {
-		return fmt.Errorf("failed to write file: %v", err)
+	return nil
+}
+
+// DownloadPrefix downloads every file whose name starts with prefix into outputDir,
+// recreating the remote directory layout locally
+func (c *Client) DownloadPrefix(prefix string, outputDir string) error {
+	log.Printf("Downloading prefix: %s to %s", prefix, outputDir)
+
+	files, err := c.ListFiles()
+	if err != nil {
+		return err
 	}
 
-	log.Printf("Successfully downloaded file: %s", remoteName)
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	sem := make(chan struct{}, maxParallelDownloads)
+	downloaded := 0
+
+	for _, file := range files {
+		if !strings.HasPrefix(file.Filename, prefix) {
+			continue
+		}
+
+		// Building local path relative to the prefix and making sure it stays inside outputDir
+		relativePath := strings.TrimPrefix(strings.TrimPrefix(file.Filename, prefix), "/")
+		if relativePath == "" {
+			relativePath = filepath.Base(file.Filename)
+		}
+		localPath := filepath.Join(outputDir, filepath.FromSlash(relativePath))
+		if rel, err := filepath.Rel(outputDir, localPath); err != nil || strings.HasPrefix(rel, "..") {
+			mu.Lock()
+			errs = append(errs, fmt.Errorf("refusing to download %s outside of %s", file.Filename, outputDir))
+			mu.Unlock()
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(localPath), 0755); err != nil {
+			mu.Lock()
+			errs = append(errs, fmt.Errorf("failed to create directory for %s: %v", file.Filename, err))
+			mu.Unlock()
+			continue
+		}
+
+		downloaded++
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(remoteName, localPath string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if err := c.DownloadFile(remoteName, localPath); err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("%s: %v", remoteName, err))
+				mu.Unlock()
+			}
+		}(file.Filename, localPath)
+	}
+
+	wg.Wait()
+
+	if downloaded == 0 && len(errs) == 0 {
+		return fmt.Errorf("no files found with prefix: %s", prefix)
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	log.Printf("Successfully downloaded %d files with prefix: %s", downloaded, prefix)
 	return nil
 }
 
 // downloadChunk downloads a single chunk from the chunk servers
 func (c *Client) downloadChunk(chunkLoc *pb.ChunkLocation) ([]byte, error) {
+	return c.downloadChunkRange(chunkLoc, 0, 0)
+}
+
+// downloadChunkRange downloads length bytes of a chunk starting at offset, a length of 0 reading to the end of the chunk
+func (c *Client) downloadChunkRange(chunkLoc *pb.ChunkLocation, offset, length int64) ([]byte, error) {
 	log.Printf("Downloading chunk %d (%s) from %d servers", chunkLoc.ChunkIndex, chunkLoc.ChunkHandle, len(chunkLoc.ChunkServerAddresses))
 
 	// Trying each server until on successfully downloads the chunk
-	for _, serverAddr := range chunkLoc.ChunkServerAddresses {
-		data, err := c.readChunkFromServer(serverAddr, chunkLoc.ChunkHandle)
+	lastErr := errors.New("chunk has no replicas")
+	corrupt := false
+	servers := c.blacklist.order(chunkLoc.ChunkServerAddresses)
+	for i, serverAddr := range servers {
+		data, err := c.readChunkFromServer(serverAddr, chunkLoc.ChunkHandle, chunkLoc.AccessToken, offset, length)
 		if err != nil {
 			log.Printf("Warning: failed to read chunk from %s: %v", serverAddr, err)
+			c.blacklist.add(serverAddr)
+			if i < len(servers)-1 {
+				c.observeRetry("read-chunk", serverAddr, i+1, err)
+			}
+			lastErr = err
+			corrupt = corrupt || status.Code(err) == codes.DataLoss || errors.Is(err, ErrChecksumMismatch)
+
+			// a server finding its own replica corrupt reports it itself, a mismatch seen only here is reported by us
+			if errors.Is(err, ErrChecksumMismatch) {
+				c.reportCorruptReplica(serverAddr, chunkLoc.ChunkHandle, err.Error())
+			}
 			continue
 		}
 
+		c.blacklist.remove(serverAddr)
 		log.Printf("Successfully read chunk %d from %s (%d bytes)", chunkLoc.ChunkIndex, serverAddr, len(data))
 		return data, nil
 	}
 
-	return nil, fmt.Errorf("failed to download chunk from any server")
+	return nil, replicaError(fmt.Errorf("failed to download chunk from any server: %v", lastErr), corrupt)
+}
+
+// readChunkFromServer reads a range of chunk data from a specific chunk server, presenting the read token the master handed out
+func (c *Client) readChunkFromServer(serverAddr, chunkHandle, accessToken string, offset, length int64) ([]byte, error) {
+	start := time.Now()
+	data, err := c.readChunkStream(serverAddr, chunkHandle, accessToken, offset, length)
+	c.observeTransfer(serverAddr, chunkHandle, TransferDownload, int64(len(data)), start, err)
+
+	return data, err
 }
 
-// readChunkFromServer reads chunk data from a specific chunk server
-func (c *Client) readChunkFromServer(serverAddr, chunkHandle string) ([]byte, error) {
-	conn, err := grpc.NewClient(serverAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+// readChunkStream receives a range of chunk data from a chunk server, verifying the pieces against the checksums it sends
+func (c *Client) readChunkStream(serverAddr, chunkHandle, accessToken string, offset, length int64) ([]byte, error) {
+	conn, err := c.getConn(serverAddr)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to chunk server: %v", err)
 	}
-	defer conn.Close()
 
 	chunkClient := pb.NewChunkServerClient(conn)
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeouts.ChunkRead)
 	defer cancel()
 
-	response, err := chunkClient.ReadChunk(ctx, &pb.ReadChunkRequest{
+	stream, err := chunkClient.ReadChunkStream(ctx, &pb.ReadChunkRequest{
 		ChunkHandle: chunkHandle,
-	})
+		Offset:      offset,
+		Length:      length,
+		AccessToken: accessToken,
+	}, c.chunkCallOptions()...)
 	if err != nil {
 		return nil, err
 	}
 
-	return response.Data, nil
+	// Collecting the chunk pieces, an error after some pieces means the chunk is unusable
+	var (
+		data bytes.Buffer
+		sum  uint32
+	)
+	for {
+		response, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		data.Write(response.Data)
+		sum = common.UpdateChecksum(sum, response.Data)
+
+		// servers predating read checksums send none, their data is taken as is
+		if response.Checksum != nil && *response.Checksum != sum {
+			return nil, fmt.Errorf("%w: data read from %s doesn't match the checksum it sent", ErrChecksumMismatch, serverAddr)
+		}
+	}
+
+	return data.Bytes(), nil
+}
+
+// reportCorruptReplica tells the master a replica returned corrupt data, so it stops handing out
+// the replica and repairs the chunk from a good copy
+func (c *Client) reportCorruptReplica(serverAddr, chunkHandle, reason string) {
+	conn, err := c.getConn(c.masterAddress)
+	if err != nil {
+		log.Printf("Warning: failed to connect to master server: %v", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeouts.Metadata)
+	defer cancel()
+
+	_, err = pb.NewMasterClient(conn).ReportCorruptChunk(ctx, &pb.ReportCorruptChunkRequest{
+		ChunkServerAddress: serverAddr,
+		ChunkHandle:        chunkHandle,
+		Reason:             reason,
+	})
+	if err != nil {
+		log.Printf("Warning: failed to report corrupt replica of chunk %s on %s: %v", chunkHandle, serverAddr, err)
+	}
 }
 
 // ListFiles lists all the files in the DFS
 func (c *Client) ListFiles() ([]*pb.FileInfo, error) {
+	return c.ListFilesWithOptions(ListOptions{})
+}
+
+// ListOptions are optional filters and ordering for listing files
+type ListOptions struct {
+	Tags         map[string]string // only list files having all of these tags
+	SortBy       pb.ListSortKey    // files are sorted by name by default
+	Descending   bool
+	MinSize      *int64    // only list files of at least this many bytes
+	MaxSize      *int64    // only list files of at most this many bytes
+	CreatedAfter time.Time // only list files created after this time
+}
+
+// listPageSize is how many files ListFilesWithOptions asks the master for at a time
+const listPageSize = 1000
+
+// ListFilesWithOptions lists the files matching the given list options, fetching them from the master a page at a time
+func (c *Client) ListFilesWithOptions(options ListOptions) ([]*pb.FileInfo, error) {
 	log.Printf("Listing files...")
 
+	var files []*pb.FileInfo
+	pageToken := ""
+	for {
+		page, nextPageToken, err := c.ListFilesPage(options, listPageSize, pageToken)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, page...)
+		if nextPageToken == "" {
+			return files, nil
+		}
+		pageToken = nextPageToken
+	}
+}
+
+// ListFilesPage lists up to pageSize files matching the list options, starting after the page pageToken came with,
+// or at the first file for an empty token. It returns the token of the next page, empty once no files are left
+func (c *Client) ListFilesPage(options ListOptions, pageSize int, pageToken string) ([]*pb.FileInfo, string, error) {
 	// Connecting to master server
-	conn, err := grpc.NewClient(c.masterAddress, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := c.getConn(c.masterAddress)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to master server: %v", err)
+		return nil, "", fmt.Errorf("failed to connect to master server: %v", err)
 	}
-	defer conn.Close()
 
 	masterClient := pb.NewMasterClient(conn)
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeouts.Metadata)
 	defer cancel()
 
-	response, err := masterClient.ListFiles(ctx, &pb.ListFilesRequest{})
+	response, err := masterClient.ListFiles(ctx, &pb.ListFilesRequest{
+		Tags:         options.Tags,
+		SortBy:       options.SortBy,
+		Descending:   options.Descending,
+		MinSize:      options.MinSize,
+		MaxSize:      options.MaxSize,
+		CreatedAfter: unixSeconds(options.CreatedAfter),
+		PageSize:     int32(pageSize),
+		PageToken:    pageToken,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to list files: %v", err)
+		return nil, "", fmt.Errorf("failed to list files: %v", err)
 	}
 
-	return response.Files, nil
+	return response.Files, response.NextPageToken, nil
+}
+
+// ListFilesStream calls fn with every file matching the list options as the master sends them, so listing a huge
+// namespace starts right away and never holds all of it in memory. It stops at the first error fn returns
+func (c *Client) ListFilesStream(options ListOptions, fn func(*pb.FileInfo) error) error {
+	log.Printf("Streaming file list...")
+
+	// Connecting to master server
+	conn, err := c.getConn(c.masterAddress)
+	if err != nil {
+		return fmt.Errorf("failed to connect to master server: %v", err)
+	}
+
+	// a long listing may take any time as a whole, the master timeout bounds the wait for each batch
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	idle := time.AfterFunc(c.timeouts.Metadata, cancel)
+	defer idle.Stop()
+
+	masterClient := pb.NewMasterClient(conn)
+	stream, err := masterClient.ListFilesStream(ctx, &pb.ListFilesRequest{
+		Tags:         options.Tags,
+		SortBy:       options.SortBy,
+		Descending:   options.Descending,
+		MinSize:      options.MinSize,
+		MaxSize:      options.MaxSize,
+		CreatedAfter: unixSeconds(options.CreatedAfter),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list files: %v", err)
+	}
+
+	for {
+		response, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to list files: %v", err)
+		}
+		idle.Reset(c.timeouts.Metadata)
+
+		for _, file := range response.Files {
+			if err := fn(file); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// SearchQuery selects files to search for, zero fields match every file
+type SearchQuery struct {
+	NameContains   string
+	Tags           map[string]string // only match files having all of these tags
+	MinSize        *int64
+	MaxSize        *int64
+	CreatedAfter   time.Time
+	CreatedBefore  time.Time
+	ModifiedAfter  time.Time
+	ModifiedBefore time.Time
+	Limit          int32 // most files to return, 0 for the master's default
+}
+
+// SearchFiles finds files matching the query using the master's search index. It returns the matching files
+// in filename order and whether more files matched than the limit
+func (c *Client) SearchFiles(query SearchQuery) ([]*pb.FileInfo, bool, error) {
+	log.Printf("Searching files...")
+
+	// Connecting to master server
+	conn, err := c.getConn(c.masterAddress)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to connect to master server: %v", err)
+	}
+
+	masterClient := pb.NewMasterClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeouts.Metadata)
+	defer cancel()
+
+	response, err := masterClient.SearchFiles(ctx, &pb.SearchFilesRequest{
+		NameContains:   query.NameContains,
+		Tags:           query.Tags,
+		MinSize:        query.MinSize,
+		MaxSize:        query.MaxSize,
+		CreatedAfter:   unixSeconds(query.CreatedAfter),
+		CreatedBefore:  unixSeconds(query.CreatedBefore),
+		ModifiedAfter:  unixSeconds(query.ModifiedAfter),
+		ModifiedBefore: unixSeconds(query.ModifiedBefore),
+		Limit:          query.Limit,
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to search files: %v", err)
+	}
+
+	return response.Files, response.Truncated, nil
+}
+
+// unixSeconds converts a time to unix seconds, the zero time to 0
+func unixSeconds(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+
+	return t.Unix()
+}
+
+// CopyFile copies a file inside the DFS without downloading and re-uploading it
+func (c *Client) CopyFile(sourceName, destinationName string) error {
+	return c.copyFile(sourceName, destinationName, nil)
+}
+
+// CopyFileIfGeneration copies a file only if the destination is at the given generation,
+// 0 if the destination must not exist. A failed condition returns an error matching ErrGenerationMismatch
+func (c *Client) CopyFileIfGeneration(sourceName, destinationName string, destinationGeneration int64) error {
+	return c.copyFile(sourceName, destinationName, &destinationGeneration)
+}
+
+func (c *Client) copyFile(sourceName, destinationName string, destinationGeneration *int64) error {
+	log.Printf("Copying file: %s to %s", sourceName, destinationName)
+
+	// Connecting to master server
+	conn, err := c.getConn(c.masterAddress)
+	if err != nil {
+		return fmt.Errorf("failed to connect to master server: %v", err)
+	}
+
+	masterClient := pb.NewMasterClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeouts.Copy)
+	defer cancel()
+
+	response, err := masterClient.CopyFile(ctx, &pb.CopyFileRequest{
+		SourceFilename:      sourceName,
+		DestinationFilename: destinationName,
+		IfGenerationMatch:   destinationGeneration,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to copy file: %w", checkMasterError(err))
+	}
+
+	log.Printf("Successfully copied file: %s to %s, generation: %d", sourceName, destinationName, response.Generation)
+	return nil
+}
+
+// CloneFile creates a copy of a file sharing the source's chunks, so it is cheap whatever the file's size.
+// Chunks are only copied once either file appends to them
+func (c *Client) CloneFile(sourceName, destinationName string) error {
+	return c.cloneFile(sourceName, destinationName, nil)
+}
+
+// CloneFileIfGeneration clones a file only if the destination is at the given generation,
+// 0 if the destination must not exist. A failed condition returns an error matching ErrGenerationMismatch
+func (c *Client) CloneFileIfGeneration(sourceName, destinationName string, destinationGeneration int64) error {
+	return c.cloneFile(sourceName, destinationName, &destinationGeneration)
+}
+
+func (c *Client) cloneFile(sourceName, destinationName string, destinationGeneration *int64) error {
+	log.Printf("Cloning file: %s to %s", sourceName, destinationName)
+
+	// Connecting to master server
+	conn, err := c.getConn(c.masterAddress)
+	if err != nil {
+		return fmt.Errorf("failed to connect to master server: %v", err)
+	}
+
+	masterClient := pb.NewMasterClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeouts.Metadata)
+	defer cancel()
+
+	response, err := masterClient.CloneFile(ctx, &pb.CloneFileRequest{
+		SourceFilename:      sourceName,
+		DestinationFilename: destinationName,
+		IfGenerationMatch:   destinationGeneration,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to clone file: %w", checkMasterError(err))
+	}
+
+	log.Printf("Successfully cloned file: %s to %s, generation: %d", sourceName, destinationName, response.Generation)
+	return nil
+}
+
+// RenameFile renames a file inside the DFS, the destination must not exist.
+// An existing destination returns an error matching ErrFileExists
+func (c *Client) RenameFile(sourceName, destinationName string) error {
+	return c.renameFile(sourceName, destinationName, nil)
+}
+
+// RenameFileIfGeneration renames a file only if it is at the given generation.
+// A failed condition returns an error matching ErrGenerationMismatch
+func (c *Client) RenameFileIfGeneration(sourceName, destinationName string, generation int64) error {
+	return c.renameFile(sourceName, destinationName, &generation)
+}
+
+func (c *Client) renameFile(sourceName, destinationName string, generation *int64) error {
+	log.Printf("Renaming file: %s to %s", sourceName, destinationName)
+
+	// Connecting to master server
+	conn, err := c.getConn(c.masterAddress)
+	if err != nil {
+		return fmt.Errorf("failed to connect to master server: %v", err)
+	}
+
+	masterClient := pb.NewMasterClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeouts.Metadata)
+	defer cancel()
+
+	response, err := masterClient.RenameFile(ctx, &pb.RenameFileRequest{
+		SourceFilename:      sourceName,
+		DestinationFilename: destinationName,
+		IfGenerationMatch:   generation,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to rename file: %w", checkMasterError(err))
+	}
+
+	log.Printf("Successfully renamed file: %s to %s, generation: %d", sourceName, destinationName, response.Generation)
+	return nil
+}
+
+// Watch subscribes to namespace events for files matching prefix and calls handler for each event
+// until ctx is cancelled or the stream fails
+func (c *Client) Watch(ctx context.Context, prefix string, handler func(*pb.FileEvent)) error {
+	return c.WatchSubscribed(ctx, prefix, func() {}, handler)
+}
+
+// WatchSubscribed is Watch calling subscribed once the master has subscribed to the events, so no
+// event published after that call is missed
+func (c *Client) WatchSubscribed(ctx context.Context, prefix string, subscribed func(), handler func(*pb.FileEvent)) error {
+	log.Printf("Watching prefix: %q", prefix)
+
+	// Connecting to master server
+	conn, err := c.getConn(c.masterAddress)
+	if err != nil {
+		return fmt.Errorf("failed to connect to master server: %v", err)
+	}
+
+	masterClient := pb.NewMasterClient(conn)
+	stream, err := masterClient.Watch(ctx, &pb.WatchRequest{
+		Prefix: prefix,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to watch: %v", err)
+	}
+
+	// the master sends the headers once it has subscribed
+	if _, err := stream.Header(); err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return fmt.Errorf("watch stream failed: %v", err)
+	}
+	subscribed()
+
+	for {
+		event, err := stream.Recv()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("watch stream failed: %v", err)
+		}
+
+		handler(event)
+	}
+}
+
+// DeleteFile deletes a file from the DFS
+func (c *Client) DeleteFile(remoteName string) error {
+	return c.deleteFile(remoteName, nil)
+}
+
+// DeleteFileIfGeneration deletes a file only if it is at the given generation.
+// A failed condition returns an error matching ErrGenerationMismatch
+func (c *Client) DeleteFileIfGeneration(remoteName string, generation int64) error {
+	return c.deleteFile(remoteName, &generation)
+}
+
+func (c *Client) deleteFile(remoteName string, generation *int64) error {
+	log.Printf("Deleting file: %s", remoteName)
+
+	// Connecting to master server
+	conn, err := c.getConn(c.masterAddress)
+	if err != nil {
+		return fmt.Errorf("failed to connect to master server: %v", err)
+	}
+
+	masterClient := pb.NewMasterClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeouts.Metadata)
+	defer cancel()
+
+	_, err = masterClient.DeleteFile(ctx, &pb.DeleteFileRequest{
+		Filename:          remoteName,
+		IfGenerationMatch: generation,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete file: %w", checkMasterError(err))
+	}
+
+	log.Printf("Successfully deleted file: %s", remoteName)
+	return nil
+}
+
+// UndeleteFile restores a deleted file before the master reclaims its chunks and returns the restored file's generation
+func (c *Client) UndeleteFile(remoteName string) (int64, error) {
+	log.Printf("Undeleting file: %s", remoteName)
+
+	// Connecting to master server
+	conn, err := c.getConn(c.masterAddress)
+	if err != nil {
+		return 0, fmt.Errorf("failed to connect to master server: %v", err)
+	}
+
+	masterClient := pb.NewMasterClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeouts.Metadata)
+	defer cancel()
+
+	response, err := masterClient.UndeleteFile(ctx, &pb.UndeleteFileRequest{
+		Filename: remoteName,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to undelete file: %w", checkMasterError(err))
+	}
+
+	return response.Generation, nil
+}
+
+// GetFileInfo fetches the metadata and chunk locations of a single file
+func (c *Client) GetFileInfo(remoteName string) (*pb.GetFileInfoResponse, error) {
+	// Connecting to master server
+	conn, err := c.getConn(c.masterAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to master server: %v", err)
+	}
+
+	masterClient := pb.NewMasterClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeouts.Metadata)
+	defer cancel()
+
+	response, err := masterClient.GetFileInfo(ctx, &pb.GetFileInfoRequest{
+		Filename: remoteName,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file info: %w", checkMasterError(err))
+	}
+
+	return response, nil
+}
+
+// UpdateFileTags sets the tags in set and removes the tags keyed by remove on an existing file,
+// returning the resulting tags of the file
+func (c *Client) UpdateFileTags(remoteName string, set map[string]string, remove []string) (map[string]string, error) {
+	// Connecting to master server
+	conn, err := c.getConn(c.masterAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to master server: %v", err)
+	}
+
+	masterClient := pb.NewMasterClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeouts.Metadata)
+	defer cancel()
+
+	response, err := masterClient.UpdateFileTags(ctx, &pb.UpdateFileTagsRequest{
+		Filename: remoteName,
+		Set:      set,
+		Remove:   remove,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update tags: %w", checkMasterError(err))
+	}
+
+	return response.Tags, nil
+}
+
+// AttributeUpdate lists changes to the mutable attributes of a file, nil and empty fields are left unchanged
+type AttributeUpdate struct {
+	SetTags           map[string]string
+	RemoveTags        []string
+	TTL               *time.Duration // delete the file this long from now, 0 removes the expiry
+	ReplicationFactor *int32         // 0 restores the default
+	Tier              *string        // storage tier of the chunk servers holding the file's chunks, empty for any
+	StorageClass      *string        // standard, reduced-redundancy or archive, converted in background
+}
+
+// GetFileAttributes fetches the mutable attributes of a file
+func (c *Client) GetFileAttributes(remoteName string) (*pb.FileAttributes, error) {
+	// Connecting to master server
+	conn, err := c.getConn(c.masterAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to master server: %v", err)
+	}
+
+	masterClient := pb.NewMasterClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeouts.Metadata)
+	defer cancel()
+
+	response, err := masterClient.GetFileAttributes(ctx, &pb.GetFileAttributesRequest{
+		Filename: remoteName,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attributes: %w", checkMasterError(err))
+	}
+
+	return response.Attributes, nil
+}
+
+// SetFileAttributes changes the mutable attributes of an existing file and returns the resulting attributes
+func (c *Client) SetFileAttributes(remoteName string, update AttributeUpdate) (*pb.FileAttributes, error) {
+	log.Printf("Setting attributes of file: %s", remoteName)
+
+	// Connecting to master server
+	conn, err := c.getConn(c.masterAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to master server: %v", err)
+	}
+
+	masterClient := pb.NewMasterClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeouts.Metadata)
+	defer cancel()
+
+	req := &pb.SetFileAttributesRequest{
+		Filename:          remoteName,
+		SetTags:           update.SetTags,
+		RemoveTags:        update.RemoveTags,
+		ReplicationFactor: update.ReplicationFactor,
+		StorageTier:       update.Tier,
+		StorageClass:      update.StorageClass,
+	}
+	if update.TTL != nil {
+		ttlSeconds := int64(*update.TTL / time.Second)
+		req.TtlSeconds = &ttlSeconds
+	}
+
+	response, err := masterClient.SetFileAttributes(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set attributes: %w", checkMasterError(err))
+	}
+
+	return response.Attributes, nil
+}
+
+// ListFileVersions lists the current and kept previous versions of a file, newest first
+func (c *Client) ListFileVersions(remoteName string) ([]*pb.FileVersion, error) {
+	// Connecting to master server
+	conn, err := c.getConn(c.masterAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to master server: %v", err)
+	}
+
+	masterClient := pb.NewMasterClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeouts.Metadata)
+	defer cancel()
+
+	response, err := masterClient.ListFileVersions(ctx, &pb.ListFileVersionsRequest{
+		Filename: remoteName,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list versions: %w", checkMasterError(err))
+	}
+
+	return response.Versions, nil
+}
+
+// DiskUsage reports the space consumed by files under prefix
+func (c *Client) DiskUsage(prefix string) (*pb.DiskUsageResponse, error) {
+	// Connecting to master server
+	conn, err := c.getConn(c.masterAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to master server: %v", err)
+	}
+
+	masterClient := pb.NewMasterClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeouts.Metadata)
+	defer cancel()
+
+	response, err := masterClient.DiskUsage(ctx, &pb.DiskUsageRequest{
+		Prefix: prefix,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get disk usage: %v", err)
+	}
+
+	return response, nil
+}
+
+// ListUnaccessedFiles fetches up to limit of the largest files not read for at least idle, 0 fetches all of them
+func (c *Client) ListUnaccessedFiles(idle time.Duration, limit int) ([]*pb.FileInfo, error) {
+	// Connecting to master server
+	conn, err := c.getConn(c.masterAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to master server: %v", err)
+	}
+
+	masterClient := pb.NewMasterClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeouts.Metadata)
+	defer cancel()
+
+	response, err := masterClient.ListUnaccessedFiles(ctx, &pb.ListUnaccessedFilesRequest{
+		IdleSeconds: int64(idle.Seconds()),
+		Limit:       int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list unaccessed files: %v", err)
+	}
+
+	return response.Files, nil
+}
+
+// GetClusterStats fetches the aggregate statistics of the cluster
+func (c *Client) GetClusterStats() (*pb.GetClusterStatsResponse, error) {
+	// Connecting to master server
+	conn, err := c.getConn(c.masterAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to master server: %v", err)
+	}
+
+	masterClient := pb.NewMasterClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeouts.Metadata)
+	defer cancel()
+
+	response, err := masterClient.GetClusterStats(ctx, &pb.GetClusterStatsRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster stats: %v", err)
+	}
+
+	return response, nil
+}
+
+// GetChunkDistribution fetches the per chunk server chunk counts and the replication histogram
+func (c *Client) GetChunkDistribution() (*pb.GetChunkDistributionResponse, error) {
+	// Connecting to master server
+	conn, err := c.getConn(c.masterAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to master server: %v", err)
+	}
+
+	masterClient := pb.NewMasterClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeouts.Metadata)
+	defer cancel()
+
+	response, err := masterClient.GetChunkDistribution(ctx, &pb.GetChunkDistributionRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chunk distribution: %v", err)
+	}
+
+	return response, nil
+}
+
+// GetGeoReplicationStatus fetches how far mirroring to the master's remote cluster lags behind
+func (c *Client) GetGeoReplicationStatus() (*pb.GetGeoReplicationStatusResponse, error) {
+	// Connecting to master server
+	conn, err := c.getConn(c.masterAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to master server: %v", err)
+	}
+
+	masterClient := pb.NewMasterClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeouts.Metadata)
+	defer cancel()
+
+	response, err := masterClient.GetGeoReplicationStatus(ctx, &pb.GetGeoReplicationStatusRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get geo-replication status: %v", err)
+	}
+
+	return response, nil
+}
+
+// ReclaimDeleted deletes the replicas of every deleted chunk now instead of after the master's reclaim delay,
+// returning the number of chunks reclaimed
+func (c *Client) ReclaimDeleted() (int64, error) {
+	// Connecting to master server
+	conn, err := c.getConn(c.masterAddress)
+	if err != nil {
+		return 0, fmt.Errorf("failed to connect to master server: %v", err)
+	}
+
+	masterClient := pb.NewMasterClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeouts.Metadata)
+	defer cancel()
+
+	response, err := masterClient.ReclaimDeleted(ctx, &pb.ReclaimDeletedRequest{})
+	if err != nil {
+		return 0, fmt.Errorf("failed to reclaim deleted chunks: %v", err)
+	}
+
+	return response.ReclaimedChunks, nil
+}
+
+// PresignDownload asks the master for a url downloading the current version of a file over http until ttl
+// passes, 0 for the master's default of an hour. Anyone holding the url can download the file
+func (c *Client) PresignDownload(remoteName string, ttl time.Duration) (*pb.PresignDownloadResponse, error) {
+	// Connecting to master server
+	conn, err := c.getConn(c.masterAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to master server: %v", err)
+	}
+
+	masterClient := pb.NewMasterClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeouts.Metadata)
+	defer cancel()
+
+	response, err := masterClient.PresignDownload(ctx, &pb.PresignDownloadRequest{
+		Filename:   remoteName,
+		TtlSeconds: int64(ttl.Seconds()),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to presign %s: %v", remoteName, err)
+	}
+
+	return response, nil
+}
+
+// WhoAmI fetches the user and groups the master authenticates the client's token as
+func (c *Client) WhoAmI() (*pb.WhoAmIResponse, error) {
+	// Connecting to master server
+	conn, err := c.getConn(c.masterAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to master server: %v", err)
+	}
+
+	masterClient := pb.NewMasterClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeouts.Metadata)
+	defer cancel()
+
+	response, err := masterClient.WhoAmI(ctx, &pb.WhoAmIRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up identity: %v", err)
+	}
+
+	return response, nil
+}
+
+// GetDelegationToken mints a token reading the files under prefix as the client's user, for batch jobs. The token
+// expires after ttl unless renewed, and can't be renewed past maxLifetime. Zero durations use the master's defaults
+func (c *Client) GetDelegationToken(prefix string, ttl time.Duration, maxLifetime time.Duration) (*pb.GetDelegationTokenResponse, error) {
+	// Connecting to master server
+	conn, err := c.getConn(c.masterAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to master server: %v", err)
+	}
+
+	masterClient := pb.NewMasterClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeouts.Metadata)
+	defer cancel()
+
+	response, err := masterClient.GetDelegationToken(ctx, &pb.GetDelegationTokenRequest{
+		Prefix:             prefix,
+		TtlSeconds:         int64(ttl.Seconds()),
+		MaxLifetimeSeconds: int64(maxLifetime.Seconds()),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to mint delegation token: %v", err)
+	}
+
+	return response, nil
+}
+
+// RenewDelegationToken extends a delegation token by ttl, zero for the master's default, and returns the renewed token
+func (c *Client) RenewDelegationToken(token string, ttl time.Duration) (*pb.RenewDelegationTokenResponse, error) {
+	// Connecting to master server
+	conn, err := c.getConn(c.masterAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to master server: %v", err)
+	}
+
+	masterClient := pb.NewMasterClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeouts.Metadata)
+	defer cancel()
+
+	response, err := masterClient.RenewDelegationToken(ctx, &pb.RenewDelegationTokenRequest{
+		Token:      token,
+		TtlSeconds: int64(ttl.Seconds()),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to renew delegation token: %v", err)
+	}
+
+	return response, nil
+}
+
+// RevokeDelegationToken stops the master accepting a delegation token, given either the token or, for superusers, its id
+func (c *Client) RevokeDelegationToken(token string, id string) error {
+	// Connecting to master server
+	conn, err := c.getConn(c.masterAddress)
+	if err != nil {
+		return fmt.Errorf("failed to connect to master server: %v", err)
+	}
+
+	masterClient := pb.NewMasterClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeouts.Metadata)
+	defer cancel()
+
+	if _, err := masterClient.RevokeDelegationToken(ctx, &pb.RevokeDelegationTokenRequest{Token: token, Id: id}); err != nil {
+		return fmt.Errorf("failed to revoke delegation token: %v", err)
+	}
+
+	return nil
+}
+
+// SetFileMode changes the permission bits of a file, e.g. 0640, and returns the updated file
+func (c *Client) SetFileMode(remoteName string, mode uint32) (*pb.FileInfo, error) {
+	// Connecting to master server
+	conn, err := c.getConn(c.masterAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to master server: %v", err)
+	}
+
+	masterClient := pb.NewMasterClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeouts.Metadata)
+	defer cancel()
+
+	response, err := masterClient.SetFileMode(ctx, &pb.SetFileModeRequest{
+		Filename: remoteName,
+		Mode:     mode,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to set mode of %s: %w", remoteName, checkMasterError(err))
+	}
+
+	return response.File, nil
+}
+
+// SetFileOwner changes the owner and group of a file, keeping either when empty, and returns the updated file
+func (c *Client) SetFileOwner(remoteName, owner, group string) (*pb.FileInfo, error) {
+	// Connecting to master server
+	conn, err := c.getConn(c.masterAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to master server: %v", err)
+	}
+
+	masterClient := pb.NewMasterClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeouts.Metadata)
+	defer cancel()
+
+	response, err := masterClient.SetFileOwner(ctx, &pb.SetFileOwnerRequest{
+		Filename: remoteName,
+		Owner:    owner,
+		Group:    group,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to set owner of %s: %w", remoteName, checkMasterError(err))
+	}
+
+	return response.File, nil
+}
+
+// Symlink adds a symlink named linkName pointing at the file named target, which doesn't have to exist, and returns
+// its generation. A file already using the name is only replaced when overwrite is set
+func (c *Client) Symlink(target, linkName string, overwrite bool) (int64, error) {
+	// Connecting to master server
+	conn, err := c.getConn(c.masterAddress)
+	if err != nil {
+		return 0, fmt.Errorf("failed to connect to master server: %v", err)
+	}
+
+	masterClient := pb.NewMasterClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeouts.Metadata)
+	defer cancel()
+
+	response, err := masterClient.SymlinkFile(ctx, &pb.SymlinkFileRequest{
+		LinkName:  linkName,
+		Target:    target,
+		Overwrite: overwrite,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to create symlink %s: %w", linkName, checkMasterError(err))
+	}
+
+	return response.Generation, nil
+}
+
+// Link adds a hard link named linkName to the file named target, sharing its chunks instead of copying them,
+// and returns its generation. The link name must not be in use
+func (c *Client) Link(target, linkName string) (int64, error) {
+	// Connecting to master server
+	conn, err := c.getConn(c.masterAddress)
+	if err != nil {
+		return 0, fmt.Errorf("failed to connect to master server: %v", err)
+	}
+
+	masterClient := pb.NewMasterClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeouts.Metadata)
+	defer cancel()
+
+	response, err := masterClient.LinkFile(ctx, &pb.LinkFileRequest{
+		SourceFilename:      target,
+		DestinationFilename: linkName,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to link %s to %s: %w", linkName, target, checkMasterError(err))
+	}
+
+	return response.Generation, nil
+}
+
+// GetServerInfo fetches the master's software version, build commit and supported protocol features
+func (c *Client) GetServerInfo() (*pb.GetServerInfoResponse, error) {
+	// Connecting to master server
+	conn, err := c.getConn(c.masterAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to master server: %v", err)
+	}
+
+	masterClient := pb.NewMasterClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeouts.Metadata)
+	defer cancel()
+
+	response, err := masterClient.GetServerInfo(ctx, &pb.GetServerInfoRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get master server info: %v", err)
+	}
+
+	return response, nil
+}
+
+// GetChunkServerInfo fetches the software version, build commit and supported protocol features of a chunk server
+func (c *Client) GetChunkServerInfo(serverAddr string) (*pb.GetServerInfoResponse, error) {
+	conn, err := c.getConn(serverAddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to chunk server %s: %v", serverAddr, err)
+	}
+
+	chunkClient := pb.NewChunkServerClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeouts.Metadata)
+	defer cancel()
+
+	response, err := chunkClient.GetServerInfo(ctx, &pb.GetServerInfoRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get server info of chunk server %s: %v", serverAddr, err)
+	}
+
+	return response, nil
 }
@@ -0,0 +1,45 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/harshvardha/distributed_file_system/common"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/encoding/gzip"
+)
+
+// CompressionNone sends chunk data uncompressed
+const CompressionNone = "none"
+
+// CompressionGzip compresses chunk data with gzip on the wire, trading cpu for bandwidth
+const CompressionGzip = gzip.Name
+
+// CompressionZstd compresses chunk data with zstd on the wire, cheaper on cpu than gzip at a similar ratio.
+// Chunk servers of builds before zstd support reject it
+const CompressionZstd = common.ZstdCompressor
+
+// SetCompression selects the compressor used for chunk data sent to and read from chunk servers.
+// Chunk servers reply with the compressor the request used, so it applies to both directions
+func (c *Client) SetCompression(name string) error {
+	if name != CompressionNone && encoding.GetCompressor(name) == nil {
+		return fmt.Errorf("unknown compression %q, expected %s, %s or %s", name, CompressionNone, CompressionGzip, CompressionZstd)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.compression = name
+	return nil
+}
+
+// chunkCallOptions returns the call options of chunk data RPCs
+func (c *Client) chunkCallOptions() []grpc.CallOption {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.compression == "" || c.compression == CompressionNone {
+		return nil
+	}
+	return []grpc.CallOption{grpc.UseCompressor(c.compression)}
+}
@@ -0,0 +1,77 @@
+package client
+
+import (
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Errors returned by the client can be matched against these with errors.Is to branch on the cause of a failure
+var (
+	// ErrFileNotFound is matched when the file, or the requested version of it, doesn't exist
+	ErrFileNotFound = errors.New("file not found")
+
+	// ErrGenerationMismatch is matched when a conditional operation failed because the file's generation
+	// changed, or the file appeared or disappeared, since the caller looked at it
+	ErrGenerationMismatch = errors.New("generation mismatch")
+
+	// ErrFileExists is matched when an exclusive upload or a rename found its target name already taken
+	ErrFileExists = errors.New("file already exists")
+
+	// ErrNoChunkServers is matched when the master had no chunk servers to place a chunk on
+	ErrNoChunkServers = errors.New("no chunk servers available")
+
+	// ErrChecksumMismatch is matched when no replica of a chunk could be read and at least one
+	// of them failed checksum verification
+	ErrChecksumMismatch = errors.New("chunk checksum mismatch")
+
+	// ErrReplicaUnavailable is matched when none of the replicas of a chunk could be written or read
+	ErrReplicaUnavailable = errors.New("no replica available")
+
+	// ErrCircuitOpen is matched when a call was refused without being sent because the latest calls
+	// to the server kept failing, see WithCircuitBreaker
+	ErrCircuitOpen = errors.New("circuit open")
+)
+
+// typedError keeps the original message while matching one of the client's sentinel errors
+type typedError struct {
+	err    error
+	target error
+}
+
+func (e *typedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *typedError) Is(target error) bool {
+	return target == e.target
+}
+
+func (e *typedError) Unwrap() error {
+	return e.err
+}
+
+// checkMasterError marks master errors callers can branch on, keeping the master's message
+func checkMasterError(err error) error {
+	switch status.Code(err) {
+	case codes.NotFound:
+		return &typedError{err: err, target: ErrFileNotFound}
+	case codes.FailedPrecondition:
+		return &typedError{err: err, target: ErrGenerationMismatch}
+	case codes.AlreadyExists:
+		return &typedError{err: err, target: ErrFileExists}
+	}
+
+	return err
+}
+
+// replicaError returns the error of a chunk none of whose replicas could be used, err describing the
+// last failure and corrupt whether any replica failed checksum verification
+func replicaError(err error, corrupt bool) error {
+	if corrupt {
+		return &typedError{err: err, target: ErrChecksumMismatch}
+	}
+
+	return &typedError{err: err, target: ErrReplicaUnavailable}
+}
@@ -0,0 +1,160 @@
+package client
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/harshvardha/distributed_file_system/common"
+	pb "github.com/harshvardha/distributed_file_system/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// default upload flow control settings, see WithUploadFlowControl
+const (
+	defaultMaxInFlightBytes = 4 * common.ChunkSize
+	initialInFlightBytes    = common.ChunkSize
+)
+
+const (
+	// highQueuePressure is the queue pressure reported by a chunk server above which writes to it slow down
+	highQueuePressure = 0.5
+
+	// maxOverloadedWriteAttempts is the number of times a write rejected by an overloaded chunk server is sent
+	maxOverloadedWriteAttempts = 4
+
+	// overloadedWriteBackoff is the wait before resending a rejected write, doubled after each attempt
+	overloadedWriteBackoff = 250 * time.Millisecond
+)
+
+// maxParallelChunkWrites is the number of chunks of an upload written concurrently
+const maxParallelChunkWrites = 4
+
+// WithUploadFlowControl caps the bytes of chunk writes in flight to any one chunk server. Writes start
+// at one chunk per server, the window grows while the server reports a short queue and halves when its
+// queue fills up or it rejects a write as overloaded, so a slow replica slows down only the chunks it
+// stores. A limit of 0 disables the window, overloaded writes are still retried
+func WithUploadFlowControl(maxInFlightBytes int64) ClientOption {
+	return func(c *Client) {
+		c.maxInFlightBytes = maxInFlightBytes
+	}
+}
+
+// flowWindow bounds the bytes of writes in flight to one chunk server
+type flowWindow struct {
+	address string
+	max     int64
+
+	mu       sync.Mutex
+	cond     *sync.Cond
+	limit    int64 // current window, between one chunk and max
+	inFlight int64
+}
+
+func newFlowWindow(address string, max int64) *flowWindow {
+	w := &flowWindow{
+		address: address,
+		max:     max,
+		limit:   min(initialInFlightBytes, max),
+	}
+	w.cond = sync.NewCond(&w.mu)
+	return w
+}
+
+// acquire waits until n more bytes fit in the window. A write always goes through
+// when nothing else is in flight, so chunks larger than the window still make progress
+func (w *flowWindow) acquire(n int64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for w.inFlight > 0 && w.inFlight+n > w.limit {
+		w.cond.Wait()
+	}
+	w.inFlight += n
+}
+
+// release returns n bytes to the window
+func (w *flowWindow) release(n int64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.inFlight -= n
+	w.cond.Broadcast()
+}
+
+// grow widens the window by n bytes after a write the server took without strain
+func (w *flowWindow) grow(n int64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.limit = min(w.limit+n, w.max)
+	w.cond.Broadcast()
+}
+
+// shrink halves the window after the server reported a full queue
+func (w *flowWindow) shrink() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	limit := max(w.limit/2, common.ChunkSize)
+	if limit < w.limit {
+		log.Printf("Chunk server %s is under pressure, limiting writes to %d bytes in flight", w.address, limit)
+	}
+	w.limit = limit
+}
+
+// window returns the flow window of a chunk server, nil when flow control is disabled
+func (c *Client) window(address string) *flowWindow {
+	if c.maxInFlightBytes <= 0 {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	w, exists := c.windows[address]
+	if !exists {
+		w = newFlowWindow(address, c.maxInFlightBytes)
+		c.windows[address] = w
+	}
+	return w
+}
+
+// writeChunkPaced sends a chunk write within the server's flow window, adapting the window to the queue
+// pressure the server reports and resending writes it rejected as overloaded after a backoff
+func (c *Client) writeChunkPaced(serverAddr string, req *pb.WriteChunkRequest) (*pb.WriteChunkResponse, error) {
+	w := c.window(serverAddr)
+	size := int64(len(req.Data))
+
+	backoff := overloadedWriteBackoff
+	for attempt := 1; ; attempt++ {
+		if w != nil {
+			w.acquire(size)
+		}
+		response, err := c.writeChunk(serverAddr, req)
+		if w != nil {
+			w.release(size)
+		}
+
+		overloaded := status.Code(err) == codes.ResourceExhausted
+		if w != nil {
+			switch {
+			case overloaded || response.GetQueuePressure() >= highQueuePressure:
+				w.shrink()
+			case err == nil:
+				w.grow(size)
+			}
+		}
+
+		// a full disk answers the same way, so the retries are bounded
+		if !overloaded || attempt == maxOverloadedWriteAttempts {
+			return response, err
+		}
+
+		log.Printf("Chunk server %s is overloaded, retrying write of chunk %s in %s", serverAddr, req.ChunkHandle, backoff)
+		c.observeRetry("write-chunk", serverAddr, attempt, err)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
@@ -0,0 +1,155 @@
+package client
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// MetricsRecorder receives measurements of the client's requests, so programs embedding the client can feed
+// them to their own monitoring. Its methods are called concurrently and shouldn't block, they run on the request path
+type MetricsRecorder interface {
+	// ObserveCall is called after every call to the master or a chunk server, including any retries of it
+	ObserveCall(call CallMetrics)
+
+	// ObserveTransfer is called after chunk data was written to or read from a chunk server
+	ObserveTransfer(transfer TransferMetrics)
+
+	// ObserveRetry is called before a failed request is sent again, to the same server or another replica
+	ObserveRetry(retry RetryMetrics)
+}
+
+// CallMetrics describes a call to the master or a chunk server. Streams, e.g. Watch, end when the last message is received
+type CallMetrics struct {
+	Server   string        // address of the server called
+	Method   string        // full gRPC method name, e.g. /dfs.Master/GetFileInfo
+	Duration time.Duration // time from sending the call to the end of its response
+	Err      error         // nil for successful calls
+}
+
+// TransferDirection is whether chunk data went to or came from a chunk server
+type TransferDirection string
+
+const (
+	TransferUpload   TransferDirection = "upload"
+	TransferDownload TransferDirection = "download"
+)
+
+// TransferMetrics describes chunk data sent to or received from one chunk server
+type TransferMetrics struct {
+	Server      string
+	ChunkHandle string
+	Direction   TransferDirection
+	Bytes       int64         // bytes sent or received, 0 for failed transfers
+	Duration    time.Duration // time the transfer took, including waiting for the server's answer
+	Err         error         // nil for successful transfers
+}
+
+// RetryMetrics describes a request sent again after it failed
+type RetryMetrics struct {
+	Operation string // what is retried, e.g. write-chunk, read-chunk, record-append or a gRPC method name
+	Server    string // server the failed attempt went to, empty when unknown
+	Attempt   int    // number of the attempt that failed, 1 for the first
+	Err       error  // error of the failed attempt
+}
+
+// WithMetrics reports the client's calls, chunk transfers and retries to recorder
+func WithMetrics(recorder MetricsRecorder) ClientOption {
+	return func(c *Client) {
+		c.metrics = recorder
+	}
+}
+
+// observeTransfer reports a chunk transfer that started at start, if metrics are recorded
+func (c *Client) observeTransfer(server, chunkHandle string, direction TransferDirection, bytes int64, start time.Time, err error) {
+	if c.metrics == nil {
+		return
+	}
+	if err != nil {
+		bytes = 0
+	}
+
+	c.metrics.ObserveTransfer(TransferMetrics{
+		Server:      server,
+		ChunkHandle: chunkHandle,
+		Direction:   direction,
+		Bytes:       bytes,
+		Duration:    time.Since(start),
+		Err:         err,
+	})
+}
+
+// observeRetry reports a retry, if metrics are recorded
+func (c *Client) observeRetry(operation, server string, attempt int, err error) {
+	if c.metrics != nil {
+		c.metrics.ObserveRetry(RetryMetrics{Operation: operation, Server: server, Attempt: attempt, Err: err})
+	}
+}
+
+// retryObserverKey is the context key of the function RetryInterceptor reports its retries to
+type retryObserverKey struct{}
+
+// observeCallRetry reports a retry of a call made with ctx to the client's metrics, if the call carries them
+func observeCallRetry(ctx context.Context, method string, attempt int, err error) {
+	if observe, ok := ctx.Value(retryObserverKey{}).(func(string, int, error)); ok {
+		observe(method, attempt, err)
+	}
+}
+
+// metricsDialOptions returns the interceptors reporting every call on a connection to address to recorder.
+// They run before the caller's interceptors, so a call's duration includes the retries of RetryInterceptor
+func metricsDialOptions(recorder MetricsRecorder, address string) []grpc.DialOption {
+	observeRetry := func(method string, attempt int, err error) {
+		recorder.ObserveRetry(RetryMetrics{Operation: method, Server: address, Attempt: attempt, Err: err})
+	}
+	observe := func(method string, start time.Time, err error) {
+		recorder.ObserveCall(CallMetrics{Server: address, Method: method, Duration: time.Since(start), Err: err})
+	}
+
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			start := time.Now()
+			err := invoker(context.WithValue(ctx, retryObserverKey{}, observeRetry), method, req, reply, cc, opts...)
+			observe(method, start, err)
+			return err
+		}),
+		grpc.WithChainStreamInterceptor(func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			start := time.Now()
+			stream, err := streamer(ctx, desc, cc, method, opts...)
+			if err != nil {
+				observe(method, start, err)
+				return nil, err
+			}
+
+			return &observedStream{ClientStream: stream, serverStreams: desc.ServerStreams, done: func(err error) {
+				observe(method, start, err)
+			}}, nil
+		}),
+	}
+}
+
+// observedStream reports a stream once its response ended
+type observedStream struct {
+	grpc.ClientStream
+	serverStreams bool
+	once          sync.Once
+	done          func(err error)
+}
+
+func (s *observedStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	switch {
+	case err == io.EOF:
+		s.once.Do(func() { s.done(nil) })
+	case err != nil:
+		s.once.Do(func() { s.done(err) })
+	case !s.serverStreams:
+		// the single response of a client stream ends it
+		s.once.Do(func() { s.done(nil) })
+	}
+
+	return err
+}
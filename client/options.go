@@ -0,0 +1,168 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/harshvardha/distributed_file_system/common"
+	pb "github.com/harshvardha/distributed_file_system/proto"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Timeouts bounds how long each kind of operation may take
+type Timeouts struct {
+	Metadata   time.Duration // master requests not listed below, e.g. list, stat, rename and delete
+	Upload     time.Duration // asking the master to allocate the chunks of an upload
+	Download   time.Duration // asking the master for the chunk locations of a download or read
+	Copy       time.Duration // server-side copies, which copy every chunk before answering
+	ChunkWrite time.Duration // writing a chunk to one replica
+	ChunkRead  time.Duration // reading a chunk, or part of it, from one replica
+}
+
+// DefaultTimeouts returns the timeouts used unless configured otherwise
+func DefaultTimeouts() Timeouts {
+	return Timeouts{
+		Metadata:   10 * time.Second,
+		Upload:     30 * time.Second,
+		Download:   30 * time.Second,
+		Copy:       30 * time.Second,
+		ChunkWrite: 30 * time.Second,
+		ChunkRead:  30 * time.Second,
+	}
+}
+
+// ClientOption configures a Client created by NewClient
+type ClientOption func(*Client)
+
+// WithTimeouts overrides the default timeouts, zero fields keep their default
+func WithTimeouts(timeouts Timeouts) ClientOption {
+	return func(c *Client) {
+		override := func(field *time.Duration, value time.Duration) {
+			if value > 0 {
+				*field = value
+			}
+		}
+		override(&c.timeouts.Metadata, timeouts.Metadata)
+		override(&c.timeouts.Upload, timeouts.Upload)
+		override(&c.timeouts.Download, timeouts.Download)
+		override(&c.timeouts.Copy, timeouts.Copy)
+		override(&c.timeouts.ChunkWrite, timeouts.ChunkWrite)
+		override(&c.timeouts.ChunkRead, timeouts.ChunkRead)
+	}
+}
+
+// WithConnTuning sets the keepalive, flow control and message size settings of the client's connections
+func WithConnTuning(tuning common.ConnTuning) ClientOption {
+	return func(c *Client) {
+		c.tuning = tuning
+	}
+}
+
+// WithUnaryInterceptors adds interceptors to every unary call to the master and chunk servers,
+// e.g. for logging, metrics, authentication or RetryInterceptor. They run in the given order
+func WithUnaryInterceptors(interceptors ...grpc.UnaryClientInterceptor) ClientOption {
+	return func(c *Client) {
+		c.interceptors = append(c.interceptors, interceptors...)
+	}
+}
+
+// WithPriority tags every request of the client with priority, e.g. common.PriorityBatch for bulk jobs,
+// so a saturated master serves interactive requests first. Requests are interactive unless set
+func WithPriority(priority common.Priority) ClientOption {
+	return func(c *Client) {
+		c.priority = priority
+	}
+}
+
+// priorityDialOptions returns the interceptors tagging every call on a connection with priority
+func priorityDialOptions(priority common.Priority) []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			return invoker(common.WithPriority(ctx, priority), method, req, reply, cc, opts...)
+		}),
+		grpc.WithChainStreamInterceptor(func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			return streamer(common.WithPriority(ctx, priority), desc, cc, method, opts...)
+		}),
+	}
+}
+
+// WithToken authenticates the client's requests to the master as the user the token belongs to, for masters
+// with an identity provider. The master records the user as the owner of the files it creates
+func WithToken(token string) ClientOption {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// retryableMethods are the unary calls RetryInterceptor retries: reads, and metadata changes setting values
+// rather than adding to them, which have the same effect when a call that reached the server is repeated
+var retryableMethods = map[string]bool{
+	pb.Master_DownloadFile_FullMethodName:            true,
+	pb.Master_ListFiles_FullMethodName:               true,
+	pb.Master_SearchFiles_FullMethodName:             true,
+	pb.Master_GetFileInfo_FullMethodName:             true,
+	pb.Master_GetFileAttributes_FullMethodName:       true,
+	pb.Master_ListFileVersions_FullMethodName:        true,
+	pb.Master_DiskUsage_FullMethodName:               true,
+	pb.Master_GetChunkDistribution_FullMethodName:    true,
+	pb.Master_ListUnaccessedFiles_FullMethodName:     true,
+	pb.Master_GetClusterStats_FullMethodName:         true,
+	pb.Master_GetGeoReplicationStatus_FullMethodName: true,
+	pb.Master_GetServerInfo_FullMethodName:           true,
+	pb.Master_PresignDownload_FullMethodName:         true,
+	pb.Master_WhoAmI_FullMethodName:                  true,
+	pb.Master_RenewUpload_FullMethodName:             true,
+	pb.Master_UpdateFileTags_FullMethodName:          true,
+	pb.Master_SetFileAttributes_FullMethodName:       true,
+	pb.Master_SetFileMode_FullMethodName:             true,
+	pb.Master_SetFileOwner_FullMethodName:            true,
+	pb.ChunkServer_ReadChunk_FullMethodName:          true,
+	pb.ChunkServer_GetServerInfo_FullMethodName:      true,
+}
+
+// RetryInterceptor retries unary calls failing with codes.Unavailable, e.g. while the master restarts or recalls
+// an archived file, making up to attempts calls in total. It waits backoff before the first retry and doubles the
+// wait after each one, waiting at least as long as the server asks to.
+// A call may have reached the server before failing, so only reads and idempotent metadata changes are retried,
+// uploads, appends, copies, renames and deletes fail on the first error
+func RetryInterceptor(attempts int, backoff time.Duration) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if !retryableMethods[method] {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		for attempt := 1; attempt < attempts && status.Code(err) == codes.Unavailable; attempt++ {
+			wait := backoff
+			if delay, asked := retryDelay(err); asked {
+				wait = max(wait, delay)
+			}
+			select {
+			case <-ctx.Done():
+				return err
+			case <-time.After(wait):
+			}
+			backoff *= 2
+
+			observeCallRetry(ctx, method, attempt, err)
+			err = invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		return err
+	}
+}
+
+// retryDelay returns how long a server failing a call asked to wait before calling again, with false if it
+// didn't ask, e.g. because it couldn't be reached
+func retryDelay(err error) (time.Duration, bool) {
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.RetryInfo); ok {
+			return info.GetRetryDelay().AsDuration(), true
+		}
+	}
+
+	return 0, false
+}
@@ -0,0 +1,34 @@
+package client
+
+// Progress describes the state of an in-flight upload or download. TotalBytes and TotalChunks
+// are 0 while the size of an upload of a stream isn't known yet
+type Progress struct {
+	Filename         string
+	BytesTransferred int64
+	TotalBytes       int64
+	ChunksDone       int
+	TotalChunks      int
+}
+
+// ProgressFunc receives progress updates after every transferred chunk. It may be called
+// concurrently when several files are transferred at once, e.g. by DownloadPrefix
+type ProgressFunc func(Progress)
+
+// SetProgressFunc registers a callback receiving progress of uploads and downloads
+func (c *Client) SetProgressFunc(fn ProgressFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.progress = fn
+}
+
+// reportProgress calls the registered progress callback, if any
+func (c *Client) reportProgress(progress Progress) {
+	c.mu.Lock()
+	fn := c.progress
+	c.mu.Unlock()
+
+	if fn != nil {
+		fn(progress)
+	}
+}
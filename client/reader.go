@@ -0,0 +1,159 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/harshvardha/distributed_file_system/common"
+	pb "github.com/harshvardha/distributed_file_system/proto"
+)
+
+// defaultReadAhead is the number of chunks a FileReader downloads ahead of the one being read, see WithReadAhead
+const defaultReadAhead = 1
+
+// WithReadAhead sets how many chunks past the one being read a FileReader downloads in background, so a
+// sequential reader doesn't wait for the next chunk at every chunk boundary. Every chunk read ahead holds
+// up to a chunk of memory, 0 downloads each chunk only once the reader gets to it
+func WithReadAhead(chunks int) ClientOption {
+	return func(c *Client) {
+		c.readAhead = max(chunks, 0)
+	}
+}
+
+// FileReader reads a version of a file from start to end, downloading its chunks one after the other.
+// It is not safe for concurrent use
+type FileReader struct {
+	client    *Client
+	filesize  int64
+	chunks    []*pb.ChunkLocation // chunks holding file data, in chunk order
+	next      int                 // index in chunks of the next chunk to download
+	fetches   []*chunkFetch       // downloads started and not yet read, in chunk order
+	readAhead int
+	current   []byte // unread data of the chunk being read
+	err       error  // returned by every read once set, io.EOF at the end of the file
+}
+
+// chunkFetch is the download of a chunk running in background
+type chunkFetch struct {
+	chunkIndex int32
+	done       chan struct{} // closed once data or err is set
+	data       []byte
+	err        error
+}
+
+// Open opens the current version of a file for reading
+func (c *Client) Open(remoteName string) (*FileReader, error) {
+	return c.OpenVersion(remoteName, 0)
+}
+
+// OpenVersion opens the version of a file with the given generation for reading, 0 for the current one.
+// The first chunks start downloading right away
+func (c *Client) OpenVersion(remoteName string, generation int64) (*FileReader, error) {
+	// Connecting to master server
+	conn, err := c.getConn(c.masterAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to master server: %v", err)
+	}
+
+	masterClient := pb.NewMasterClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeouts.Download)
+	defer cancel()
+
+	response, err := c.requestDownload(ctx, masterClient, remoteName, generation)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &FileReader{
+		client:    c,
+		filesize:  response.Filesize,
+		readAhead: c.readAhead,
+	}
+
+	// files stored inline come with their contents
+	if len(response.Data) > 0 {
+		r.current = response.Data
+		return r, nil
+	}
+
+	// chunks being appended to may hold records that aren't part of the file yet, or none at all
+	for _, chunkLoc := range response.ChunkLocation {
+		if common.ChunkLength(response.Filesize, int(chunkLoc.ChunkIndex)) > 0 {
+			r.chunks = append(r.chunks, chunkLoc)
+		}
+	}
+	slices.SortFunc(r.chunks, func(a, b *pb.ChunkLocation) int {
+		return int(a.ChunkIndex - b.ChunkIndex)
+	})
+
+	r.startFetches()
+	return r, nil
+}
+
+// Size returns the size of the file version being read
+func (r *FileReader) Size() int64 {
+	return r.filesize
+}
+
+// Read reads the next bytes of the file, waiting for the download of the next chunk at chunk boundaries
+// unless it was read ahead
+func (r *FileReader) Read(p []byte) (int, error) {
+	for len(r.current) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		if len(r.fetches) == 0 {
+			r.err = io.EOF
+			return 0, r.err
+		}
+
+		fetch := r.fetches[0]
+		r.fetches = r.fetches[1:]
+		<-fetch.done
+		if fetch.err != nil {
+			r.err = fmt.Errorf("failed to download chunk %d: %w", fetch.chunkIndex, fetch.err)
+			return 0, r.err
+		}
+
+		r.current = fetch.data
+		r.startFetches()
+	}
+
+	n := copy(p, r.current)
+	r.current = r.current[n:]
+	return n, nil
+}
+
+// Close stops reading ahead. Downloads already running finish in background and are dropped
+func (r *FileReader) Close() error {
+	if r.err == nil {
+		r.err = fmt.Errorf("file reader closed")
+	}
+	r.fetches = nil
+	r.current = nil
+	r.next = len(r.chunks)
+	return nil
+}
+
+// startFetches starts downloading the chunk to be read next and the chunks after it up to the read-ahead
+func (r *FileReader) startFetches() {
+	for len(r.fetches) <= r.readAhead && r.next < len(r.chunks) {
+		chunkLoc := r.chunks[r.next]
+		r.next++
+
+		fetch := &chunkFetch{chunkIndex: chunkLoc.ChunkIndex, done: make(chan struct{})}
+		r.fetches = append(r.fetches, fetch)
+
+		go func() {
+			defer close(fetch.done)
+
+			data, err := r.client.downloadChunk(chunkLoc)
+			if length := common.ChunkLength(r.filesize, int(chunkLoc.ChunkIndex)); int64(len(data)) > length {
+				data = data[:length]
+			}
+			fetch.data, fetch.err = data, err
+		}()
+	}
+}
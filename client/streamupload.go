@@ -0,0 +1,139 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	pb "github.com/harshvardha/distributed_file_system/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// uploadStream uploads a stream of unknown size starting with the chunk first and continuing with the rest of r.
+// The master allocates the chunks one at a time as they are read, and learns the size of the file once r ends
+func (c *Client) uploadStream(first []byte, r io.Reader, remoteName string, options UploadOptions) error {
+	// Creating a connection to master server
+	conn, err := c.getConn(c.masterAddress)
+	if err != nil {
+		return fmt.Errorf("failed to connect to master server: %v", err)
+	}
+
+	masterClient := pb.NewMasterClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeouts.Upload)
+	defer cancel()
+
+	response, err := masterClient.UploadFile(ctx, &pb.UploadFileRequest{
+		Filename:          remoteName,
+		Hints:             options.Hints,
+		IfGenerationMatch: options.IfGenerationMatch,
+		Immutable:         options.Immutable,
+		RetentionSeconds:  int64(options.Retention / time.Second),
+		Tags:              options.Tags,
+		Exclusive:         options.Exclusive,
+		Mode:              options.Mode,
+		StorageTier:       options.Tier,
+		StorageClass:      options.StorageClass,
+		Streaming:         true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to request file upload: %w", checkMasterError(err))
+	}
+
+	// the master abandons the upload and removes the file if the session runs out while chunks are written
+	stopRenewing := c.renewUpload(masterClient, remoteName, response.UploadId, response.ExpiresAt)
+
+	// the size is unknown until the stream ends, progress reports no totals until then
+	progress := Progress{Filename: remoteName}
+	chunk := first
+	var filesize int64
+	var uploadErr error
+	for chunkIndex := int32(0); len(chunk) > 0; chunkIndex++ {
+		chunkLoc, err := c.allocateChunk(masterClient, remoteName, response.UploadId, chunkIndex)
+		if status.Code(err) == codes.Unimplemented {
+			// masters predating streaming uploads need the size up front
+			stopRenewing()
+			if completeErr := c.completeUpload(masterClient, remoteName, response.UploadId, true, nil); completeErr != nil {
+				log.Printf("Warning: %v", completeErr)
+			}
+			return c.uploadBuffered(first, r, remoteName, options)
+		}
+		if err != nil {
+			uploadErr = fmt.Errorf("failed to allocate chunk %d: %w", chunkIndex, checkMasterError(err))
+			break
+		}
+
+		if err := c.writeChunkData(chunk, chunkLoc); err != nil {
+			uploadErr = fmt.Errorf("failed to upload chunk %d: %w", chunkIndex, err)
+			break
+		}
+		filesize += int64(len(chunk))
+
+		progress.ChunksDone++
+		progress.BytesTransferred = filesize
+		c.reportProgress(progress)
+
+		// the chunk was written, its buffer holds the next one
+		n, err := io.ReadFull(r, first)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			uploadErr = fmt.Errorf("failed to read input: %v", err)
+			break
+		}
+		chunk = first[:n]
+	}
+	stopRenewing()
+
+	if uploadErr != nil {
+		// releasing the file so it can be uploaded again right away
+		if completeErr := c.completeUpload(masterClient, remoteName, response.UploadId, true, nil); completeErr != nil {
+			log.Printf("Warning: %v", completeErr)
+		}
+		return uploadErr
+	}
+
+	if err := c.completeUpload(masterClient, remoteName, response.UploadId, false, &filesize); err != nil {
+		return err
+	}
+
+	progress.TotalBytes = filesize
+	progress.TotalChunks = progress.ChunksDone
+	c.reportProgress(progress)
+
+	log.Printf("Successfully uploaded stream: %s, %d bytes, generation: %d", remoteName, filesize, response.Generation)
+	return nil
+}
+
+// allocateChunk asks the master for the location of the next chunk of a streaming upload
+func (c *Client) allocateChunk(masterClient pb.MasterClient, remoteName, uploadID string, chunkIndex int32) (*pb.ChunkLocation, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeouts.Metadata)
+	defer cancel()
+
+	response, err := masterClient.AllocateChunk(ctx, &pb.AllocateChunkRequest{
+		Filename:   remoteName,
+		UploadId:   uploadID,
+		ChunkIndex: chunkIndex,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if response.ChunkLocation == nil {
+		return nil, errors.New("master returned no chunk location")
+	}
+
+	return response.ChunkLocation, nil
+}
+
+// uploadBuffered reads the rest of a stream starting with first into memory and uploads it with its size known
+func (c *Client) uploadBuffered(first []byte, r io.Reader, remoteName string, options UploadOptions) error {
+	log.Printf("Master doesn't support streaming uploads, buffering %s in memory", remoteName)
+
+	rest, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("failed to read input: %v", err)
+	}
+
+	return c.upload(append(first, rest...), remoteName, options)
+}
@@ -0,0 +1,104 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/harshvardha/distributed_file_system/common"
+	pb "github.com/harshvardha/distributed_file_system/proto"
+)
+
+// ReadRange reads length bytes of a file starting at offset, downloading only the bytes covering the range.
+// The range is clamped to the end of the file
+func (c *Client) ReadRange(remoteName string, offset, length int64) ([]byte, error) {
+	// Connecting to master server
+	conn, err := c.getConn(c.masterAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to master server: %v", err)
+	}
+
+	masterClient := pb.NewMasterClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeouts.Download)
+	defer cancel()
+
+	response, err := c.requestDownload(ctx, masterClient, remoteName, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if offset < 0 || offset > response.Filesize {
+		return nil, fmt.Errorf("offset %d out of range for file of %d bytes", offset, response.Filesize)
+	}
+	end := min(offset+length, response.Filesize)
+	if end <= offset {
+		return []byte{}, nil
+	}
+	if len(response.Data) > 0 {
+		return slices.Clone(response.Data[offset:min(end, int64(len(response.Data)))]), nil
+	}
+
+	firstChunk := int32(offset / common.ChunkSize)
+	lastChunk := int32((end - 1) / common.ChunkSize)
+
+	data := make([]byte, end-offset)
+	for _, chunkLoc := range response.ChunkLocation {
+		if chunkLoc.ChunkIndex < firstChunk || chunkLoc.ChunkIndex > lastChunk {
+			continue
+		}
+
+		// Reading only the part of the chunk overlapping the range
+		chunkStart := int64(chunkLoc.ChunkIndex) * common.ChunkSize
+		from := max(offset, chunkStart)
+		to := min(end, chunkStart+common.ChunkSize)
+
+		chunkData, err := c.downloadChunkRange(chunkLoc, from-chunkStart, to-from)
+		if err != nil {
+			return nil, fmt.Errorf("failed to download chunk %d: %v", chunkLoc.ChunkIndex, err)
+		}
+		copy(data[from-offset:], chunkData)
+	}
+
+	return data, nil
+}
+
+// Follow polls a remote file every interval and writes bytes appended after offset to w,
+// until ctx is cancelled. If the file shrinks it is treated as truncated and followed from the start
+func (c *Client) Follow(ctx context.Context, remoteName string, offset int64, interval time.Duration, w io.Writer) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		info, err := c.GetFileInfo(remoteName)
+		if err != nil {
+			return err
+		}
+
+		filesize := info.File.Filesize
+		if filesize < offset {
+			log.Printf("File %s truncated, following from the start", remoteName)
+			offset = 0
+		}
+
+		if filesize > offset {
+			data, err := c.ReadRange(remoteName, offset, filesize-offset)
+			if err != nil {
+				return err
+			}
+
+			if _, err := w.Write(data); err != nil {
+				return fmt.Errorf("failed to write output: %v", err)
+			}
+			offset += int64(len(data))
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
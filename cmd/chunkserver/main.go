@@ -3,6 +3,10 @@ package main
 import (
 	"flag"
 	"log"
+	"net"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/harshvardha/distributed_file_system/chunkserver"
 	"github.com/harshvardha/distributed_file_system/common"
@@ -10,23 +14,154 @@ import (
 
 func main() {
 	port := flag.String("port", "9001", "Port to listen on")
-	storage := flag.String("storage", "./storage", "Storage directory path")
+	bind := flag.String("bind", "", "Address to listen on, e.g. 0.0.0.0:9001 (defaults to the advertised address)")
+	advertise := flag.String("advertise", "", "Address clients and other servers reach this server at, e.g. 10.0.0.5:9001 (defaults to localhost:<port>)")
+	storage := flag.String("storage", "./storage", "Storage directory path, or a comma separated list of directories to spread chunks across")
 	master := flag.String("master", common.MasterAddress, "Master server address")
+	syncMode := flag.String("fsync", "always", "When chunk writes are flushed to disk: always, periodic or none")
+	syncInterval := flag.Duration("fsync-interval", time.Second, "Flush interval when -fsync=periodic")
+	maxIO := flag.Int("max-io", 8, "Chunk reads and writes running at once, 0 for no limit")
+	maxIOQueue := flag.Int("max-io-queue", 64, "Chunk reads and writes queued behind -max-io before requests are rejected as overloaded")
+	maxStorageBytes := flag.Int64("max-storage-bytes", 0, "Bytes of chunks this server may store across all storage directories, 0 for no quota")
+	cacheBytes := flag.Int64("cache-bytes", 0, "Memory for keeping hot chunks to serve repeated reads without touching the disk, 0 disables the cache")
+	zone := flag.String("zone", "", "Failure domain of this server, e.g. rack or availability zone, used by placement hints")
+	labels := labelFlag{}
+	tier := flag.String("tier", "", "Storage media of this server, e.g. ssd or hdd, files asking for a tier are placed on servers of that tier")
+	flag.Var(labels, "label", "Label announced to the master as key=value, e.g. rack=r12, may be repeated (-zone and -tier set the zone and tier labels)")
+	httpAddress := flag.String("http", "", "Address for the /healthz, /readyz and /metrics http endpoints, e.g. :9101 (disabled when empty)")
+	debugServices := flag.Bool("grpc-debug", false, "Serve grpc reflection and channelz, for inspecting the chunk server with tools like grpcurl")
+	slowRequests := flag.Duration("slow-request-threshold", common.DefaultSlowRequestThreshold, "Log requests taking longer than this, e.g. chunk writes stuck on a failing disk, with their parameters and caller (0 disables it)")
+	logRequests := flag.Bool("log-requests", false, "Log every request with its caller, duration and status")
+	tunablesFile := flag.String("tunables-file", "", "File of flag=value lines overriding the flags that can change at runtime, read again on SIGHUP: "+strings.Join(chunkServerTunables, ", "))
+	chunkTokenKeyFile := flag.String("chunk-token-key-file", "", "File holding the key of the master's chunk access tokens, required on every chunk request once set and authenticating the server to the master (defaults to $DFS_CHUNK_TOKEN_KEY)")
+	faultSpec := flag.String("faults", os.Getenv(common.FaultsEnv), "Failures to inject for testing recovery, e.g. delay:WriteChunk=2s,partial-write=0.1,corrupt-read=0.01 (defaults to $DFS_FAULTS)")
+	connTuning := common.DefaultConnTuning()
+	connTuning.RegisterFlags(flag.CommandLine)
 	flag.Parse()
 
-	address := "localhost:" + *port
+	address := *advertise
+	if address == "" {
+		address = net.JoinHostPort("localhost", *port)
+	}
+	address, err := common.ParseAddress(address)
+	if err != nil {
+		log.Fatalf("Invalid -advertise flag: %v", err)
+	}
+	masterAddress, err := common.ParseAddress(*master)
+	if err != nil {
+		log.Fatalf("Invalid -master flag: %v", err)
+	}
+	if *bind != "" {
+		if _, _, err := net.SplitHostPort(*bind); err != nil {
+			log.Fatalf("Invalid -bind flag %q: %v", *bind, err)
+		}
+	}
 
 	log.Printf("Starting Chunk Server...")
 	log.Printf("Address: %s", address)
+	if *bind != "" {
+		log.Printf("Listening on: %s", *bind)
+	}
 	log.Printf("Storage: %s", *storage)
-	log.Printf("Master: %s", *master)
+	log.Printf("Master: %s", masterAddress)
+	log.Printf("Fsync: %s", *syncMode)
+	log.Printf("Version: %s (commit %s)", common.Version, common.BuildCommit())
+
+	mode, err := chunkserver.ParseSyncMode(*syncMode)
+	if err != nil {
+		log.Fatalf("Invalid -fsync flag: %v", err)
+	}
+
+	faults, err := common.ParseFaults(*faultSpec)
+	if err != nil {
+		log.Fatalf("Invalid -faults flag: %v", err)
+	}
+	if faults != nil {
+		log.Printf("Warning: fault injection enabled: %s", faults)
+	}
+
+	chunkTokenKey, err := common.LoadKey(*chunkTokenKeyFile, common.ChunkTokenKeyEnv)
+	if err != nil {
+		log.Fatalf("Invalid -chunk-token-key-file flag: %v", err)
+	}
+	chunkTokens, err := common.NewChunkTokens(chunkTokenKey, 0)
+	if err != nil {
+		log.Fatalf("Invalid chunk token key: %v", err)
+	}
+	if chunkTokens != nil {
+		log.Printf("Chunk access tokens: required")
+	}
+
+	server, err := chunkserver.NewServer(address, strings.Split(*storage, ","), masterAddress, chunkserver.Config{
+		SyncMode:        mode,
+		SyncInterval:    *syncInterval,
+		MaxIO:           *maxIO,
+		MaxIOQueue:      *maxIOQueue,
+		MaxStorageBytes: *maxStorageBytes,
+		CacheBytes:      *cacheBytes,
+		BindAddress:     *bind,
+		Zone:            *zone,
+		Tier:            *tier,
+		Labels:          labels,
+		Conn:            connTuning,
+		Faults:          faults,
+		DebugServices:   *debugServices,
+		ChunkTokens:     chunkTokens,
 
-	server, err := chunkserver.NewServer(address, *storage, *master)
+		SlowRequestThreshold: *slowRequests,
+		LogRequests:          *logRequests,
+	})
 	if err != nil {
 		log.Fatalf("Failed to create chunk server: %v", err)
 	}
 
+	if *tunablesFile != "" {
+		// the file overrides the command line, so a line removed from it restores the flag's value on reload
+		flags := server.Tunables()
+		reload := func() error {
+			tunables, err := loadChunkServerTunables(*tunablesFile, flags)
+			if err != nil {
+				return err
+			}
+			return server.SetTunables(tunables)
+		}
+		if err := reload(); err != nil {
+			log.Fatalf("Invalid -tunables-file flag: %v", err)
+		}
+
+		common.OnHangup(func() {
+			if err := reload(); err != nil {
+				log.Printf("Warning: failed to reload tunables, keeping the current ones: %v", err)
+				return
+			}
+			log.Printf("Reloaded tunables from %s", *tunablesFile)
+		})
+	}
+
+	if *httpAddress != "" {
+		go func() {
+			if err := server.StartHTTP(*httpAddress); err != nil {
+				log.Fatalf("Chunk server http server failed: %v", err)
+			}
+		}()
+	}
+
 	if err := server.Start(); err != nil {
 		log.Fatalf("Failed to start chunk server: %s", err)
 	}
 }
+
+// chunkServerTunables are the flags a -tunables-file may set
+var chunkServerTunables = []string{"max-io", "max-io-queue", "slow-request-threshold", "log-requests"}
+
+// loadChunkServerTunables returns tunables with the flags set in a tunables file changed
+func loadChunkServerTunables(path string, tunables chunkserver.Tunables) (chunkserver.Tunables, error) {
+	set := flag.NewFlagSet("tunables", flag.ContinueOnError)
+	set.IntVar(&tunables.MaxIO, "max-io", tunables.MaxIO, "")
+	set.IntVar(&tunables.MaxIOQueue, "max-io-queue", tunables.MaxIOQueue, "")
+	set.DurationVar(&tunables.SlowRequestThreshold, "slow-request-threshold", tunables.SlowRequestThreshold, "")
+	set.BoolVar(&tunables.LogRequests, "log-requests", tunables.LogRequests, "")
+
+	err := common.ParseFlagFile(path, set)
+	return tunables, err
+}
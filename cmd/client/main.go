@@ -1,26 +1,191 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
+	"context"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"os/signal"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/harshvardha/distributed_file_system/client"
 	"github.com/harshvardha/distributed_file_system/common"
+	pb "github.com/harshvardha/distributed_file_system/proto"
 )
 
 func main() {
 	// Creating subcommands
 	uploadCmd := flag.NewFlagSet("upload", flag.ExitOnError)
-	uploadFile := uploadCmd.String("file", "", "Local file path to upload")
+	uploadFile := uploadCmd.String("file", "", "Local file path to upload, or pass - as argument to read from stdin")
 	uploadName := uploadCmd.String("name", "", "Remote file name")
+	uploadVerbose := uploadCmd.Bool("v", false, "Show client log output instead of a progress bar")
+	uploadZone := uploadCmd.String("zone", "", "Prefer chunk servers in this zone")
+	uploadLocalHost := uploadCmd.String("local-host", "", "Put the first replica on a chunk server running on this host")
+	uploadAntiAffinity := uploadCmd.String("anti-affinity", "", "Avoid chunk servers holding chunks of this remote file")
+	uploadImmutable := uploadCmd.Bool("immutable", false, "Reject deleting, renaming or overwriting the file once uploaded")
+	uploadRetention := uploadCmd.Duration("retention", 0, "How long the file stays immutable, e.g. 8760h (implies -immutable, forever when 0)")
+	uploadTags := tagFlag{}
+	uploadCmd.Var(uploadTags, "tag", "Tag the file with key=value, may be repeated")
+	uploadIfGeneration := uploadCmd.Int64("if-generation", -1, "Only overwrite this generation of the remote file, 0 if it must not exist")
+	uploadOverwrite := uploadCmd.Bool("overwrite", false, "Replace the remote file if it already exists instead of failing")
+	uploadMaxInFlight := uploadCmd.Int64("max-in-flight-mb", 256, "Most megabytes of chunk writes in flight to one chunk server, lowered while it reports a full queue (0 for no limit)")
+	uploadCompress := uploadCmd.String("compress", client.CompressionNone, "Compress chunk data on the wire: none, gzip or zstd")
+	uploadMode := uploadCmd.String("mode", "", "Permission bits of a new file in octal, e.g. 0640 (0644 when empty)")
+	uploadTier := uploadCmd.String("tier", "", "Place the file on chunk servers of this storage tier, e.g. ssd or hdd")
+	uploadStorageClass := uploadCmd.String("storage-class", "", "Storage class of the file: standard, reduced-redundancy or archive (standard when empty)")
 
 	downloadCmd := flag.NewFlagSet("download", flag.ExitOnError)
 	downloadName := downloadCmd.String("name", "", "Remote file name to download")
-	downloadOutput := downloadCmd.String("output", "", "Local output file path")
+	downloadPrefix := downloadCmd.String("prefix", "", "Remote prefix to download recursively")
+	downloadOutput := downloadCmd.String("output", "", "Local output file path (directory when -prefix is used)")
+	downloadVerbose := downloadCmd.Bool("v", false, "Show client log output instead of a progress bar")
+	downloadGeneration := downloadCmd.Int64("generation", 0, "Version of the file to download, as listed by versions (0 for the current one)")
+	downloadCompress := downloadCmd.String("compress", client.CompressionNone, "Compress chunk data on the wire: none, gzip or zstd")
 
 	listCmd := flag.NewFlagSet("list", flag.ExitOnError)
+	listTags := tagFlag{}
+	listCmd.Var(listTags, "tag", "Only list files tagged key=value, may be repeated")
+	listSort := listCmd.String("sort", "name", "Sort files by name, size or mtime")
+	listReverse := listCmd.Bool("reverse", false, "Reverse the sort order")
+	listMinSize := listCmd.Int64("min-size", -1, "Only list files of at least this many bytes")
+	listMaxSize := listCmd.Int64("max-size", -1, "Only list files of at most this many bytes")
+	listCreatedAfter := listCmd.String("created-after", "", "Only list files created after this time (RFC 3339 or YYYY-MM-DD)")
+
+	searchCmd := flag.NewFlagSet("search", flag.ExitOnError)
+	searchName := searchCmd.String("name", "", "Only match files whose name contains this")
+	searchTags := tagFlag{}
+	searchCmd.Var(searchTags, "tag", "Only match files tagged key=value, may be repeated")
+	searchMinSize := searchCmd.Int64("min-size", -1, "Only match files of at least this many bytes")
+	searchMaxSize := searchCmd.Int64("max-size", -1, "Only match files of at most this many bytes")
+	searchCreatedAfter := searchCmd.String("created-after", "", "Only match files created after this time (RFC 3339 or YYYY-MM-DD)")
+	searchCreatedBefore := searchCmd.String("created-before", "", "Only match files created before this time (RFC 3339 or YYYY-MM-DD)")
+	searchModifiedAfter := searchCmd.String("modified-after", "", "Only match files modified after this time (RFC 3339 or YYYY-MM-DD)")
+	searchModifiedBefore := searchCmd.String("modified-before", "", "Only match files modified before this time (RFC 3339 or YYYY-MM-DD)")
+	searchLimit := searchCmd.Int("limit", 0, "Most files to return (0 for the master's default of 1000)")
+
+	tagCmd := flag.NewFlagSet("tag", flag.ExitOnError)
+	tagName := tagCmd.String("name", "", "Remote file name to tag")
+	tagSet := tagFlag{}
+	tagCmd.Var(tagSet, "set", "Add or change the tag key=value, may be repeated")
+	tagRemove := listFlag{}
+	tagCmd.Var(&tagRemove, "remove", "Remove the tag with this key, may be repeated")
+
+	catCmd := flag.NewFlagSet("cat", flag.ExitOnError)
+	catName := catCmd.String("name", "", "Remote file name to write to stdout")
+	catVerbose := catCmd.Bool("v", false, "Show client log output")
+	catReadAhead := catCmd.Int("read-ahead", 1, "Chunks downloaded in background ahead of the one being written out, 0 to download one chunk at a time")
+
+	tailCmd := flag.NewFlagSet("tail", flag.ExitOnError)
+	tailName := tailCmd.String("name", "", "Remote file name to tail")
+	tailLinesFlag := tailCmd.Int("n", 10, "Number of trailing lines to print first")
+	tailFollow := tailCmd.Bool("f", false, "Keep printing data appended to the file")
+	tailInterval := tailCmd.Duration("interval", time.Second, "Polling interval when following")
+
+	appendCmd := flag.NewFlagSet("append", flag.ExitOnError)
+	appendName := appendCmd.String("name", "", "Remote file name to append to, created if missing")
+	appendFile := appendCmd.String("file", "", "Local file whose lines are appended, stdin when empty")
+	appendBuffer := appendCmd.Int("buffer", 0, "Collect lines up to this many bytes and append them as one record, 0 appends every line as its own record")
+
+	duCmd := flag.NewFlagSet("du", flag.ExitOnError)
+	duPrefix := duCmd.String("prefix", "", "Only count files under this prefix")
+
+	cpCmd := flag.NewFlagSet("cp", flag.ExitOnError)
+	cpIfGeneration := cpCmd.Int64("if-generation", -1, "Only overwrite this generation of the destination, 0 if it must not exist")
+
+	cloneCmd := flag.NewFlagSet("clone", flag.ExitOnError)
+	cloneIfGeneration := cloneCmd.Int64("if-generation", -1, "Only overwrite this generation of the destination, 0 if it must not exist")
+
+	mvCmd := flag.NewFlagSet("mv", flag.ExitOnError)
+	mvIfGeneration := mvCmd.Int64("if-generation", -1, "Only rename this generation of the source")
+
+	watchCmd := flag.NewFlagSet("watch", flag.ExitOnError)
+	watchPrefix := watchCmd.String("prefix", "", "Only report events for files under this prefix")
+
+	rmCmd := flag.NewFlagSet("rm", flag.ExitOnError)
+	rmName := rmCmd.String("name", "", "Remote file name to delete")
+	rmIfGeneration := rmCmd.Int64("if-generation", -1, "Only delete this generation of the file")
+
+	undeleteCmd := flag.NewFlagSet("undelete", flag.ExitOnError)
+	undeleteName := undeleteCmd.String("name", "", "Remote file name to restore")
+
+	setattrCmd := flag.NewFlagSet("setattr", flag.ExitOnError)
+	setattrName := setattrCmd.String("name", "", "Remote file name to change")
+	setattrTags := tagFlag{}
+	setattrCmd.Var(setattrTags, "tag", "Add or change the tag key=value, may be repeated")
+	setattrRemoveTags := listFlag{}
+	setattrCmd.Var(&setattrRemoveTags, "remove-tag", "Remove the tag with this key, may be repeated")
+	setattrTTL := setattrCmd.Duration("ttl", 0, "Delete the file this long from now, 0 removes the expiry")
+	setattrReplication := setattrCmd.Int("replication", 0, "Number of replicas of the file's chunks, 0 restores the default")
+	setattrTier := setattrCmd.String("tier", "", "Move the file's chunks to chunk servers of this storage tier, empty for any")
+	setattrStorageClass := setattrCmd.String("storage-class", "", "Convert the file to this storage class in background: standard, reduced-redundancy or archive")
+
+	versionsCmd := flag.NewFlagSet("versions", flag.ExitOnError)
+	versionsName := versionsCmd.String("name", "", "Remote file name to list the versions of")
+
+	statCmd := flag.NewFlagSet("stat", flag.ExitOnError)
+	statName := statCmd.String("name", "", "Remote file name to inspect")
+
+	presignCmd := flag.NewFlagSet("presign", flag.ExitOnError)
+	presignName := presignCmd.String("name", "", "Remote file name to share")
+	presignTTL := presignCmd.Duration("ttl", time.Hour, "How long the url stays valid, at most 168h")
+
+	chmodCmd := flag.NewFlagSet("chmod", flag.ExitOnError)
+
+	chownCmd := flag.NewFlagSet("chown", flag.ExitOnError)
+
+	lnCmd := flag.NewFlagSet("ln", flag.ExitOnError)
+	lnSymbolic := lnCmd.Bool("s", false, "Create a symlink")
+	lnForce := lnCmd.Bool("f", false, "Replace a file already using the symlink's name")
+
+	whoamiCmd := flag.NewFlagSet("whoami", flag.ExitOnError)
+
+	delegateCmd := flag.NewFlagSet("delegate", flag.ExitOnError)
+	delegatePrefix := delegateCmd.String("prefix", "", "Only let the token read files under this prefix")
+	delegateTTL := delegateCmd.Duration("ttl", 0, "How long the token stays valid unless renewed, 0 for the master's default of 24h")
+	delegateMaxLifetime := delegateCmd.Duration("max-lifetime", 0, "How long renewals may keep the token valid, 0 for the master's default of 168h, at most 720h")
+
+	renewTokenCmd := flag.NewFlagSet("renew-token", flag.ExitOnError)
+	renewTokenFile := renewTokenCmd.String("delegation-token-file", "", "File holding the delegation token to renew")
+	renewTokenTTL := renewTokenCmd.Duration("ttl", 0, "How long the renewed token stays valid, 0 for the master's default of 24h")
+
+	revokeTokenCmd := flag.NewFlagSet("revoke-token", flag.ExitOnError)
+	revokeTokenFile := revokeTokenCmd.String("delegation-token-file", "", "File holding the delegation token to revoke")
+
+	versionCmd := flag.NewFlagSet("version", flag.ExitOnError)
+	versionServers := versionCmd.Bool("servers", false, "Also report the version of every live chunk server")
+
+	shellCmd := flag.NewFlagSet("shell", flag.ExitOnError)
+	shellVerbose := shellCmd.Bool("v", false, "Show client log output")
+
+	commands := []*flag.FlagSet{uploadCmd, downloadCmd, listCmd, searchCmd, tagCmd, catCmd, tailCmd, appendCmd, duCmd, cpCmd, cloneCmd, mvCmd, watchCmd, rmCmd, undeleteCmd, setattrCmd, versionsCmd, statCmd, presignCmd, chmodCmd, chownCmd, lnCmd, whoamiCmd, delegateCmd, renewTokenCmd, revokeTokenCmd, versionCmd, shellCmd}
+
+	// every subcommand accepts the grpc connection and timeout flags
+	connTuning := common.DefaultConnTuning()
+	timeouts := client.DefaultTimeouts()
+	var retries int
+	var priority string
+	var tokenFile string
+	blacklistWindow := time.Minute
+	masterAddress := common.MasterAddress
+	for _, cmd := range commands {
+		cmd.StringVar(&masterAddress, "master", masterAddress, "Master server address")
+		connTuning.RegisterFlags(cmd)
+		cmd.DurationVar(&timeouts.Metadata, "master-timeout", timeouts.Metadata, "Timeout of master requests such as list, stat and rm")
+		cmd.DurationVar(&timeouts.ChunkWrite, "chunk-write-timeout", timeouts.ChunkWrite, "Timeout of writing a chunk to one replica, raise it on slow links")
+		cmd.DurationVar(&timeouts.ChunkRead, "chunk-read-timeout", timeouts.ChunkRead, "Timeout of reading a chunk from one replica, raise it on slow links")
+		cmd.IntVar(&retries, "retries", 0, "Retry reads and idempotent metadata requests failing because a server is unreachable this many times")
+		cmd.StringVar(&priority, "priority", string(common.PriorityInteractive), "Priority of the requests: interactive, or batch for bulk jobs a busy master serves after interactive ones")
+		cmd.StringVar(&tokenFile, "token-file", "", "File holding the user token the master authenticates requests with (defaults to $"+common.TokenEnv+")")
+		cmd.DurationVar(&blacklistWindow, "replica-blacklist", blacklistWindow, "Read from a replica that failed a chunk read or write only after the others for this long, 0 disables it")
+	}
 
 	// Check for subcommand
 	if len(os.Args) < 2 {
@@ -28,53 +193,574 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Parsing the subcommand's flags before connecting, so the connection flags apply
+	for _, cmd := range commands {
+		if cmd.Name() == os.Args[1] {
+			cmd.Parse(os.Args[2:])
+		}
+	}
+
+	masterAddress, err := common.ParseAddress(masterAddress)
+	if err != nil {
+		log.Fatalf("Invalid -master flag: %v", err)
+	}
+
+	requestPriority, err := common.ParsePriority(priority)
+	if err != nil {
+		log.Fatalf("Invalid -priority flag: %v", err)
+	}
+
+	token, err := common.LoadKey(tokenFile, common.TokenEnv)
+	if err != nil {
+		log.Fatalf("Invalid -token-file flag: %v", err)
+	}
+
 	// Creating client
-	dfsClient := client.NewClient(common.MasterAddress)
+	clientOptions := []client.ClientOption{client.WithConnTuning(connTuning), client.WithTimeouts(timeouts), client.WithReplicaBlacklist(blacklistWindow), client.WithPriority(requestPriority)}
+	if len(token) > 0 {
+		clientOptions = append(clientOptions, client.WithToken(string(token)))
+	}
+	if os.Args[1] == "upload" {
+		clientOptions = append(clientOptions, client.WithUploadFlowControl(*uploadMaxInFlight<<20))
+	}
+	if os.Args[1] == "cat" {
+		clientOptions = append(clientOptions, client.WithReadAhead(*catReadAhead))
+	}
+	if retries > 0 {
+		clientOptions = append(clientOptions, client.WithUnaryInterceptors(client.RetryInterceptor(retries+1, 500*time.Millisecond)))
+	}
+	dfsClient := client.NewClient(masterAddress, clientOptions...)
+	defer dfsClient.Close()
 
 	// Parsing subcommands
 	switch os.Args[1] {
 	case "upload":
-		uploadCmd.Parse(os.Args[2:])
-		if *uploadFile == "" || *uploadName == "" {
+		fromStdin := uploadCmd.Arg(0) == "-"
+		if (*uploadFile == "") == !fromStdin || *uploadName == "" {
 			uploadCmd.PrintDefaults()
 			os.Exit(1)
 		}
+		if err := dfsClient.SetCompression(*uploadCompress); err != nil {
+			log.Fatalf("Upload failed: %v", err)
+		}
+
+		if !*uploadVerbose {
+			log.SetOutput(io.Discard)
+			dfsClient.SetProgressFunc(newProgressBar().update)
+		}
 
-		if err := dfsClient.UploadFile(*uploadFile, *uploadName); err != nil {
+		options := client.UploadOptions{}
+		if *uploadZone != "" || *uploadLocalHost != "" || *uploadAntiAffinity != "" {
+			options.Hints = &pb.PlacementHints{
+				PreferredZone:    *uploadZone,
+				LocalHost:        *uploadLocalHost,
+				AntiAffinityFile: *uploadAntiAffinity,
+			}
+		}
+		if *uploadIfGeneration >= 0 {
+			options.IfGenerationMatch = uploadIfGeneration
+		}
+		// -if-generation already says which file may be replaced
+		options.Exclusive = !*uploadOverwrite && *uploadIfGeneration < 0
+		options.Immutable = *uploadImmutable || *uploadRetention > 0
+		options.Retention = *uploadRetention
+		options.Tags = uploadTags
+		options.Tier = *uploadTier
+		options.StorageClass = *uploadStorageClass
+		if *uploadMode != "" {
+			mode, err := parseMode(*uploadMode)
+			if err != nil {
+				log.Fatalf("Invalid -mode flag: %v", err)
+			}
+			options.Mode = mode
+		}
+
+		var err error
+		if fromStdin {
+			err = dfsClient.UploadReaderWithOptions(os.Stdin, *uploadName, options)
+		} else {
+			err = dfsClient.UploadFileWithOptions(*uploadFile, *uploadName, options)
+		}
+		log.SetOutput(os.Stderr)
+		if err != nil {
 			log.Fatalf("Upload failed: %v", err)
 		}
 		fmt.Printf("Successfully uploaded: %s\n", *uploadName)
 	case "download":
-		downloadCmd.Parse(os.Args[2:])
-		if *downloadName == "" || *downloadOutput == "" {
+		if (*downloadName == "") == (*downloadPrefix == "") || *downloadOutput == "" {
 			downloadCmd.PrintDefaults()
 			os.Exit(1)
 		}
+		if err := dfsClient.SetCompression(*downloadCompress); err != nil {
+			log.Fatalf("Download failed: %v", err)
+		}
 
-		if err := dfsClient.DownloadFile(*downloadName, *downloadOutput); err != nil {
+		if !*downloadVerbose {
+			log.SetOutput(io.Discard)
+			// parallel prefix downloads would interleave on a single bar
+			if *downloadPrefix == "" {
+				dfsClient.SetProgressFunc(newProgressBar().update)
+			}
+		}
+
+		if *downloadPrefix != "" && *downloadGeneration != 0 {
+			downloadCmd.PrintDefaults()
+			os.Exit(1)
+		}
+
+		if *downloadPrefix != "" {
+			err := dfsClient.DownloadPrefix(*downloadPrefix, *downloadOutput)
+			log.SetOutput(os.Stderr)
+			if err != nil {
+				log.Fatalf("Download failed: %v", err)
+			}
+			fmt.Printf("Successfully downloaded %s to: %s\n", *downloadPrefix, *downloadOutput)
+			break
+		}
+
+		err := dfsClient.DownloadFileVersion(*downloadName, *downloadGeneration, *downloadOutput)
+		log.SetOutput(os.Stderr)
+		if err != nil {
 			log.Fatalf("Download failed: %v", err)
 		}
 		fmt.Printf("Successfully downloaded to: %s\n", *downloadOutput)
 	case "list":
-		listCmd.Parse(os.Args[2:])
+		listOptions, err := parseListOptions(listTags, *listSort, *listReverse, *listMinSize, *listMaxSize, *listCreatedAfter)
+		if err != nil {
+			log.Fatalf("List failed: %v", err)
+		}
 
-		files, err := dfsClient.ListFiles()
+		// printing files as they arrive, so huge namespaces start listing right away
+		count := 0
+		err = dfsClient.ListFilesStream(listOptions, func(file *pb.FileInfo) error {
+			if count == 0 {
+				fmt.Println("Files in DFS:")
+				fmt.Println("----------------------------------------")
+			}
+			count++
+			printFile(file)
+			return nil
+		})
 		if err != nil {
 			log.Fatalf("List failed: %v", err)
 		}
 
-		if len(files) == 0 {
+		if count == 0 {
 			fmt.Println("No files in the system")
 		} else {
-			fmt.Printf("Files in DFS (%d total):\n", len(files))
-			fmt.Println("----------------------------------------")
-			for _, file := range files {
-				fmt.Printf("Name: %s\n", file.Filename)
-				fmt.Printf("Size: %d bytes\n", file.Filesize)
-				fmt.Printf("Chunks: %d\n", file.NumChunks)
-				fmt.Println("----------------------------------------")
+			fmt.Printf("%d files total\n", count)
+		}
+	case "search":
+		query := client.SearchQuery{NameContains: *searchName, Tags: searchTags, Limit: int32(*searchLimit)}
+		if *searchMinSize >= 0 {
+			query.MinSize = searchMinSize
+		}
+		if *searchMaxSize >= 0 {
+			query.MaxSize = searchMaxSize
+		}
+
+		var err error
+		for _, bound := range []struct {
+			flag  string
+			value string
+			time  *time.Time
+		}{
+			{"created-after", *searchCreatedAfter, &query.CreatedAfter},
+			{"created-before", *searchCreatedBefore, &query.CreatedBefore},
+			{"modified-after", *searchModifiedAfter, &query.ModifiedAfter},
+			{"modified-before", *searchModifiedBefore, &query.ModifiedBefore},
+		} {
+			if *bound.time, err = parseTimeFlag(bound.flag, bound.value); err != nil {
+				log.Fatalf("Search failed: %v", err)
 			}
 		}
+
+		files, truncated, err := dfsClient.SearchFiles(query)
+		if err != nil {
+			log.Fatalf("Search failed: %v", err)
+		}
+
+		if len(files) == 0 {
+			fmt.Println("No matching files")
+			break
+		}
+		fmt.Printf("Matching files (%d):\n", len(files))
+		printFiles(files)
+		if truncated {
+			fmt.Println("More files matched, narrow the search or raise -limit")
+		}
+	case "tag":
+		if *tagName == "" || len(tagSet) == 0 && len(tagRemove) == 0 {
+			tagCmd.PrintDefaults()
+			os.Exit(1)
+		}
+
+		tags, err := dfsClient.UpdateFileTags(*tagName, tagSet, tagRemove)
+		if err != nil {
+			log.Fatalf("Tag failed: %v", err)
+		}
+		fmt.Printf("Tags of %s: %s\n", *tagName, formatTags(tags))
+	case "cat":
+		if *catName == "" {
+			catCmd.PrintDefaults()
+			os.Exit(1)
+		}
+
+		if !*catVerbose {
+			log.SetOutput(io.Discard)
+		}
+
+		err := catFile(dfsClient, *catName)
+		log.SetOutput(os.Stderr)
+		if err != nil {
+			log.Fatalf("Cat failed: %v", err)
+		}
+	case "append":
+		if *appendName == "" {
+			appendCmd.PrintDefaults()
+			os.Exit(1)
+		}
+
+		input := os.Stdin
+		if *appendFile != "" {
+			file, err := os.Open(*appendFile)
+			if err != nil {
+				log.Fatalf("Append failed: %v", err)
+			}
+			defer file.Close()
+			input = file
+		}
+
+		log.SetOutput(io.Discard)
+		records, err := appendLines(dfsClient, *appendName, input, *appendBuffer)
+		log.SetOutput(os.Stderr)
+		if err != nil {
+			log.Fatalf("Append failed after %d records: %v", records, err)
+		}
+		fmt.Printf("Appended %d records to %s\n", records, *appendName)
+	case "tail":
+		if *tailName == "" {
+			tailCmd.PrintDefaults()
+			os.Exit(1)
+		}
+
+		log.SetOutput(io.Discard)
+		data, offset, err := lastLines(dfsClient, *tailName, *tailLinesFlag)
+		if err != nil {
+			log.SetOutput(os.Stderr)
+			log.Fatalf("Tail failed: %v", err)
+		}
+		os.Stdout.Write(data)
+
+		if *tailFollow {
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+
+			err := dfsClient.Follow(ctx, *tailName, offset, *tailInterval, os.Stdout)
+			log.SetOutput(os.Stderr)
+			if err != nil {
+				log.Fatalf("Tail failed: %v", err)
+			}
+		}
+	case "du":
+		usage, err := dfsClient.DiskUsage(*duPrefix)
+		if err != nil {
+			log.Fatalf("Disk usage failed: %v", err)
+		}
+
+		fmt.Printf("%-12s %-12s %-8s %s\n", "LOGICAL", "PHYSICAL", "FILES", "PATH")
+		for _, entry := range usage.Entries {
+			fmt.Printf("%-12s %-12s %-8d %s\n", common.FormatBytes(float64(entry.LogicalBytes)), common.FormatBytes(float64(entry.PhysicalBytes)), entry.FileCount, entry.Path)
+		}
+		fmt.Printf("%-12s %-12s %-8d total\n", common.FormatBytes(float64(usage.Total.LogicalBytes)), common.FormatBytes(float64(usage.Total.PhysicalBytes)), usage.Total.FileCount)
+	case "cp":
+		if cpCmd.NArg() != 2 {
+			printUsage()
+			os.Exit(1)
+		}
+
+		var err error
+		if *cpIfGeneration >= 0 {
+			err = dfsClient.CopyFileIfGeneration(cpCmd.Arg(0), cpCmd.Arg(1), *cpIfGeneration)
+		} else {
+			err = dfsClient.CopyFile(cpCmd.Arg(0), cpCmd.Arg(1))
+		}
+		if err != nil {
+			log.Fatalf("Copy failed: %v", err)
+		}
+		fmt.Printf("Successfully copied %s to %s\n", cpCmd.Arg(0), cpCmd.Arg(1))
+	case "clone":
+		if cloneCmd.NArg() != 2 {
+			printUsage()
+			os.Exit(1)
+		}
+
+		var err error
+		if *cloneIfGeneration >= 0 {
+			err = dfsClient.CloneFileIfGeneration(cloneCmd.Arg(0), cloneCmd.Arg(1), *cloneIfGeneration)
+		} else {
+			err = dfsClient.CloneFile(cloneCmd.Arg(0), cloneCmd.Arg(1))
+		}
+		if err != nil {
+			log.Fatalf("Clone failed: %v", err)
+		}
+		fmt.Printf("Successfully cloned %s to %s\n", cloneCmd.Arg(0), cloneCmd.Arg(1))
+	case "mv":
+		if mvCmd.NArg() != 2 {
+			printUsage()
+			os.Exit(1)
+		}
+
+		var err error
+		if *mvIfGeneration >= 0 {
+			err = dfsClient.RenameFileIfGeneration(mvCmd.Arg(0), mvCmd.Arg(1), *mvIfGeneration)
+		} else {
+			err = dfsClient.RenameFile(mvCmd.Arg(0), mvCmd.Arg(1))
+		}
+		if err != nil {
+			log.Fatalf("Rename failed: %v", err)
+		}
+		fmt.Printf("Successfully renamed %s to %s\n", mvCmd.Arg(0), mvCmd.Arg(1))
+	case "watch":
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		err := dfsClient.Watch(ctx, *watchPrefix, func(event *pb.FileEvent) {
+			timestamp := time.Unix(0, event.Timestamp).Format(time.RFC3339)
+			if event.Type == pb.FileEventType_FILE_EVENT_RENAMED {
+				fmt.Printf("%s %s %s -> %s\n", timestamp, event.Type, event.OldFilename, event.Filename)
+			} else {
+				fmt.Printf("%s %s %s (%d bytes)\n", timestamp, event.Type, event.Filename, event.Filesize)
+			}
+		})
+		if err != nil {
+			log.Fatalf("Watch failed: %v", err)
+		}
+	case "rm":
+		if *rmName == "" {
+			rmCmd.PrintDefaults()
+			os.Exit(1)
+		}
+
+		var err error
+		if *rmIfGeneration >= 0 {
+			err = dfsClient.DeleteFileIfGeneration(*rmName, *rmIfGeneration)
+		} else {
+			err = dfsClient.DeleteFile(*rmName)
+		}
+		if err != nil {
+			log.Fatalf("Delete failed: %v", err)
+		}
+		fmt.Printf("Successfully deleted: %s\n", *rmName)
+	case "undelete":
+		if *undeleteName == "" {
+			undeleteCmd.PrintDefaults()
+			os.Exit(1)
+		}
+
+		generation, err := dfsClient.UndeleteFile(*undeleteName)
+		if err != nil {
+			log.Fatalf("Undelete failed: %v", err)
+		}
+		fmt.Printf("Successfully restored: %s, generation: %d\n", *undeleteName, generation)
+	case "setattr":
+		if *setattrName == "" || setattrCmd.NFlag() < 2 {
+			setattrCmd.PrintDefaults()
+			os.Exit(1)
+		}
+
+		update := client.AttributeUpdate{
+			SetTags:    setattrTags,
+			RemoveTags: setattrRemoveTags,
+		}
+		// only the flags given on the command line change, so -ttl 0 can remove an expiry
+		setattrCmd.Visit(func(f *flag.Flag) {
+			switch f.Name {
+			case "ttl":
+				update.TTL = setattrTTL
+			case "replication":
+				replicationFactor := int32(*setattrReplication)
+				update.ReplicationFactor = &replicationFactor
+			case "tier":
+				update.Tier = setattrTier
+			case "storage-class":
+				update.StorageClass = setattrStorageClass
+			}
+		})
+
+		attributes, err := dfsClient.SetFileAttributes(*setattrName, update)
+		if err != nil {
+			log.Fatalf("Set attributes failed: %v", err)
+		}
+		printFileAttributes(attributes)
+	case "versions":
+		if *versionsName == "" {
+			versionsCmd.PrintDefaults()
+			os.Exit(1)
+		}
+
+		versions, err := dfsClient.ListFileVersions(*versionsName)
+		if err != nil {
+			log.Fatalf("Versions failed: %v", err)
+		}
+
+		fmt.Printf("%-20s %-12s %-20s %s\n", "GENERATION", "SIZE", "CREATED", "REPLACED")
+		for _, version := range versions {
+			replaced := "current"
+			if !version.Current {
+				replaced = time.Unix(version.ReplacedAt, 0).Format(time.DateTime)
+			}
+			fmt.Printf("%-20d %-12s %-20s %s\n", version.Generation, common.FormatBytes(float64(version.Filesize)), time.Unix(version.CreatedAt, 0).Format(time.DateTime), replaced)
+		}
+	case "stat":
+		if *statName == "" {
+			statCmd.PrintDefaults()
+			os.Exit(1)
+		}
+
+		info, err := dfsClient.GetFileInfo(*statName)
+		if err != nil {
+			log.Fatalf("Stat failed: %v", err)
+		}
+		printFileInfo(info)
+	case "presign":
+		if *presignName == "" {
+			presignCmd.PrintDefaults()
+			os.Exit(1)
+		}
+
+		presigned, err := dfsClient.PresignDownload(*presignName, *presignTTL)
+		if err != nil {
+			log.Fatalf("Presign failed: %v", err)
+		}
+		// the url alone goes to stdout so scripts can capture it
+		fmt.Println(presigned.Url)
+		fmt.Fprintf(os.Stderr, "Expires: %s, generation %d\n", time.Unix(presigned.ExpiresAt, 0).Format(time.DateTime), presigned.Generation)
+	case "chmod":
+		if chmodCmd.NArg() != 2 {
+			fmt.Println("Usage: client chmod <mode> <remote_name>")
+			os.Exit(1)
+		}
+		mode, err := parseMode(chmodCmd.Arg(0))
+		if err != nil {
+			log.Fatalf("Chmod failed: %v", err)
+		}
+
+		file, err := dfsClient.SetFileMode(chmodCmd.Arg(1), mode)
+		if err != nil {
+			log.Fatalf("Chmod failed: %v", err)
+		}
+		fmt.Printf("%s: %s\n", file.Filename, formatMode(file.Mode))
+	case "chown":
+		if chownCmd.NArg() != 2 {
+			fmt.Println("Usage: client chown <owner>[:<group>] <remote_name>")
+			os.Exit(1)
+		}
+		owner, group, _ := strings.Cut(chownCmd.Arg(0), ":")
+
+		file, err := dfsClient.SetFileOwner(chownCmd.Arg(1), owner, group)
+		if err != nil {
+			log.Fatalf("Chown failed: %v", err)
+		}
+		fmt.Printf("%s: %s\n", file.Filename, formatOwner(file))
+	case "ln":
+		if lnCmd.NArg() != 2 {
+			fmt.Println("Usage: client ln [-s [-f]] <target_name> <link_name>")
+			os.Exit(1)
+		}
+		if *lnForce && !*lnSymbolic {
+			log.Fatalf("Ln failed: -f only replaces symlinks, remove the file first")
+		}
+
+		if !*lnSymbolic {
+			generation, err := dfsClient.Link(lnCmd.Arg(0), lnCmd.Arg(1))
+			if err != nil {
+				log.Fatalf("Ln failed: %v", err)
+			}
+			fmt.Printf("%s => %s, generation %d\n", lnCmd.Arg(1), lnCmd.Arg(0), generation)
+			break
+		}
+
+		generation, err := dfsClient.Symlink(lnCmd.Arg(0), lnCmd.Arg(1), *lnForce)
+		if err != nil {
+			log.Fatalf("Ln failed: %v", err)
+		}
+		fmt.Printf("%s -> %s, generation %d\n", lnCmd.Arg(1), lnCmd.Arg(0), generation)
+	case "whoami":
+		identity, err := dfsClient.WhoAmI()
+		if err != nil {
+			log.Fatalf("Whoami failed: %v", err)
+		}
+		fmt.Printf("User: %s\n", identity.User)
+		if len(identity.Groups) > 0 {
+			fmt.Printf("Groups: %s\n", strings.Join(identity.Groups, ", "))
+		}
+		if identity.Superuser {
+			fmt.Println("Superuser: file permissions don't apply")
+		}
+		if identity.DelegationId != "" {
+			fmt.Printf("Delegation token: %s, reading files under %q\n", identity.DelegationId, identity.DelegationPrefix)
+		}
+		if !identity.Authenticated {
+			fmt.Println("Not authenticated, the master serves this client anonymously")
+		}
+	case "delegate":
+		if *delegatePrefix == "" {
+			delegateCmd.PrintDefaults()
+			os.Exit(1)
+		}
+
+		delegation, err := dfsClient.GetDelegationToken(*delegatePrefix, *delegateTTL, *delegateMaxLifetime)
+		if err != nil {
+			log.Fatalf("Delegate failed: %v", err)
+		}
+		// the token alone goes to stdout so scripts can capture it
+		fmt.Println(delegation.Token)
+		fmt.Fprintf(os.Stderr, "Token %s expires: %s, renewable until %s\n", delegation.Id,
+			time.Unix(delegation.ExpiresAt, 0).Format(time.DateTime), time.Unix(delegation.MaxExpiresAt, 0).Format(time.DateTime))
+	case "renew-token":
+		if *renewTokenFile == "" {
+			renewTokenCmd.PrintDefaults()
+			os.Exit(1)
+		}
+		delegationToken, err := common.LoadKey(*renewTokenFile, "")
+		if err != nil {
+			log.Fatalf("Renew failed: %v", err)
+		}
+
+		renewed, err := dfsClient.RenewDelegationToken(string(delegationToken), *renewTokenTTL)
+		if err != nil {
+			log.Fatalf("Renew failed: %v", err)
+		}
+		fmt.Println(renewed.Token)
+		fmt.Fprintf(os.Stderr, "Expires: %s\n", time.Unix(renewed.ExpiresAt, 0).Format(time.DateTime))
+	case "revoke-token":
+		if *revokeTokenFile == "" {
+			revokeTokenCmd.PrintDefaults()
+			os.Exit(1)
+		}
+		delegationToken, err := common.LoadKey(*revokeTokenFile, "")
+		if err != nil {
+			log.Fatalf("Revoke failed: %v", err)
+		}
+
+		if err := dfsClient.RevokeDelegationToken(string(delegationToken), ""); err != nil {
+			log.Fatalf("Revoke failed: %v", err)
+		}
+		fmt.Println("Delegation token revoked")
+	case "version":
+		if err := printVersions(dfsClient, masterAddress, *versionServers); err != nil {
+			log.Fatalf("Version failed: %v", err)
+		}
+	case "shell":
+		if !*shellVerbose {
+			log.SetOutput(io.Discard)
+		}
+
+		if err := runShell(dfsClient); err != nil {
+			fmt.Fprintf(os.Stderr, "Shell failed: %v\n", err)
+			os.Exit(1)
+		}
 	default:
 		printUsage()
 		os.Exit(1)
@@ -85,10 +771,440 @@ func printUsage() {
 	fmt.Println("Distributed File System Client")
 	fmt.Println("\nUsage:")
 	fmt.Println("	client upload -file <local_path> -name <remote_name>")
+	fmt.Println("	client upload -name <remote_name> -")
+	fmt.Println("	client upload -file <local_path> -name <remote_name> -overwrite")
+	fmt.Println("	client upload -file <local_path> -name <remote_name> [-zone <zone>] [-local-host <host>] [-anti-affinity <remote_name>]")
+	fmt.Println("	client upload -file <local_path> -name <remote_name> -if-generation <generation>")
+	fmt.Println("	client upload -file <local_path> -name <remote_name> -immutable [-retention <duration>]")
+	fmt.Println("	client upload -file <local_path> -name <remote_name> -tag <key=value>...")
+	fmt.Println("	client upload -file <local_path> -name <remote_name> -mode <octal_mode>")
+	fmt.Println("	client upload -file <local_path> -name <remote_name> -tier <tier>")
+	fmt.Println("	client upload -file <local_path> -name <remote_name> -storage-class standard|reduced-redundancy|archive")
 	fmt.Println("	client download -name <remote_name> -output <local_path>")
-	fmt.Println("	client list")
+	fmt.Println("	client download -name <remote_name> -generation <generation> -output <local_path>")
+	fmt.Println("	client download -prefix <remote_prefix> -output <local_dir>")
+	fmt.Println("	client upload|download ... -compress gzip|zstd")
+	fmt.Println("	client list [-tag <key=value>]...")
+	fmt.Println("	client list [-sort name|size|mtime] [-reverse] [-min-size <bytes>] [-max-size <bytes>] [-created-after <time>]")
+	fmt.Println("	client search [-name <substring>] [-tag <key=value>]... [-min-size <bytes>] [-max-size <bytes>] [-limit <n>]")
+	fmt.Println("	client search [-created-after <time>] [-created-before <time>] [-modified-after <time>] [-modified-before <time>]")
+	fmt.Println("	client tag -name <remote_name> [-set <key=value>]... [-remove <key>]...")
+	fmt.Println("	client cat [-read-ahead <chunks>] -name <remote_name>")
+	fmt.Println("	client tail [-f] [-n <lines>] -name <remote_name>")
+	fmt.Println("	client append -name <remote_name> [-file <local_path>] [-buffer <bytes>]")
+	fmt.Println("	client du [-prefix <remote_prefix>]")
+	fmt.Println("	client cp [-if-generation <generation>] <source_name> <destination_name>")
+	fmt.Println("	client clone [-if-generation <generation>] <source_name> <destination_name>")
+	fmt.Println("	client mv [-if-generation <generation>] <source_name> <destination_name>")
+	fmt.Println("	client watch [-prefix <remote_prefix>]")
+	fmt.Println("	client rm -name <remote_name> [-if-generation <generation>]")
+	fmt.Println("	client undelete -name <remote_name>")
+	fmt.Println("	client setattr -name <remote_name> [-tag <key=value>]... [-remove-tag <key>]... [-ttl <duration>] [-replication <replicas>] [-tier <tier>] [-storage-class <class>]")
+	fmt.Println("	client versions -name <remote_name>")
+	fmt.Println("	client stat -name <remote_name>")
+	fmt.Println("	client presign -name <remote_name> [-ttl <duration>]")
+	fmt.Println("	client chmod <mode> <remote_name>")
+	fmt.Println("	client chown <owner>[:<group>] <remote_name>")
+	fmt.Println("	client ln [-s [-f]] <target_name> <link_name>")
+	fmt.Println("	client whoami")
+	fmt.Println("	client delegate -prefix <remote_prefix> [-ttl <duration>] [-max-lifetime <duration>]")
+	fmt.Println("	client renew-token -delegation-token-file <path> [-ttl <duration>]")
+	fmt.Println("	client revoke-token -delegation-token-file <path>")
+	fmt.Println("	client version [-servers]")
+	fmt.Println("	client shell [-v]")
 	fmt.Println("\nExamples:")
 	fmt.Println("	client upload -file ./test.txt -name myfile.txt")
+	fmt.Println("	pg_dump mydb | client upload -name backups/mydb.sql -")
 	fmt.Println("	client download -name myfile.txt -output ./downloaded.txt")
+	fmt.Println("	client download -prefix datasets/2024/ -output ./datasets")
 	fmt.Println("	client list")
+	fmt.Println("	client list -tag dataset=2024-06 -tag owner=etl")
+	fmt.Println("	client tag -name myfile.txt -set owner=etl -remove tmp")
+	fmt.Println("	client cat -name myfile.txt | grep error")
+	fmt.Println("	client tail -f -name logs/app.log")
+	fmt.Println("	./app 2>&1 | client append -name logs/app.log")
+	fmt.Println("	client du -prefix datasets/")
+	fmt.Println("	client cp myfile.txt myfile-copy.txt")
+	fmt.Println("	client clone datasets/train.csv experiments/train.csv")
+	fmt.Println("	client mv myfile.txt archive/myfile.txt")
+	fmt.Println("	client upload -file ./audit.log -name audit/2024-06.log -retention 8760h")
+	fmt.Println("	client upload -file ./config.json -name config.json -if-generation 1718000000000000000")
+	fmt.Println("	client watch -prefix logs/")
+	fmt.Println("	client rm -name myfile.txt")
+	fmt.Println("	client setattr -name logs/app.log -ttl 720h -replication 2")
+	fmt.Println("	client upload -file ./model.bin -name models/latest.bin -tier ssd")
+	fmt.Println("	client setattr -name logs/2023.tar -tier hdd")
+	fmt.Println("	client upload -file ./thumbnails.tar -name cache/thumbnails.tar -storage-class reduced-redundancy")
+	fmt.Println("	client setattr -name backups/2023.tar -storage-class archive")
+	fmt.Println("	client versions -name myfile.txt")
+	fmt.Println("	client stat -name myfile.txt")
+	fmt.Println("	client presign -name reports/q2.pdf -ttl 24h")
+	fmt.Println("	client chmod 0640 reports/q2.csv")
+	fmt.Println("	client chown :analysts reports/q2.csv")
+	fmt.Println("	client ln -s -f snapshots/2024-06-01 latest")
+	fmt.Println("	client ln datasets/2024/06/01/clicks.parquet datasets/by-id/7f3a/clicks.parquet")
+	fmt.Println("	DFS_TOKEN=$(cat ~/.dfs-token) client whoami")
+	fmt.Println("	client delegate -prefix datasets/2024/ -ttl 12h > job.token")
+	fmt.Println("	client renew-token -delegation-token-file job.token > job.token.new && mv job.token.new job.token")
+	fmt.Println("	DFS_TOKEN=$(cat job.token) client download -prefix datasets/2024/ -output ./datasets")
+	fmt.Println("	client revoke-token -delegation-token-file job.token")
+	fmt.Println("	client version -servers")
+	fmt.Println("	client shell")
+}
+
+// catFile writes a remote file to stdout, downloading the next chunks while earlier ones are written out
+func catFile(dfsClient *client.Client, remoteName string) error {
+	reader, err := dfsClient.Open(remoteName)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	_, err = io.Copy(os.Stdout, reader)
+	return err
+}
+
+// appendLines appends every line read from r to a remote file as its own record, or batched into records of up to
+// bufferSize bytes when set, and returns the number of lines appended
+func appendLines(dfsClient *client.Client, remoteName string, r io.Reader, bufferSize int) (int, error) {
+	appendLine := func(line []byte) error {
+		_, err := dfsClient.RecordAppend(remoteName, line)
+		return err
+	}
+	flush := func() error { return nil }
+	if bufferSize > 0 {
+		appender := dfsClient.NewBufferedAppender(remoteName, bufferSize)
+		appendLine = appender.Append
+		flush = appender.Flush
+	}
+
+	reader := bufio.NewReader(r)
+	records := 0
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			if appendErr := appendLine(line); appendErr != nil {
+				return records, appendErr
+			}
+			records++
+		}
+		if err == io.EOF {
+			return records, flush()
+		}
+		if err != nil {
+			return records, err
+		}
+	}
+}
+
+// tailReadSize is how far lastLines reads backwards at a time
+const tailReadSize = 64 * 1024
+
+// lastLines returns the last n lines of a remote file along with the file size,
+// reading backwards tailReadSize bytes at a time until enough lines are found
+func lastLines(dfsClient *client.Client, remoteName string, n int) ([]byte, int64, error) {
+	info, err := dfsClient.GetFileInfo(remoteName)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	filesize := info.File.Filesize
+	if filesize == 0 || n <= 0 {
+		return nil, filesize, nil
+	}
+
+	start := max(filesize-tailReadSize, 0)
+	data, err := dfsClient.ReadRange(remoteName, start, filesize-start)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// a trailing newline terminates the last line, it doesn't start a new one
+	for bytes.Count(bytes.TrimSuffix(data, []byte("\n")), []byte("\n")) < n && start > 0 {
+		end := start
+		start = max(start-tailReadSize, 0)
+		previous, err := dfsClient.ReadRange(remoteName, start, end-start)
+		if err != nil {
+			return nil, 0, err
+		}
+		data = append(previous, data...)
+	}
+
+	trimmed := bytes.TrimSuffix(data, []byte("\n"))
+	for i := len(trimmed) - 1; i >= 0; i-- {
+		if trimmed[i] == '\n' {
+			n--
+			if n == 0 {
+				return data[i+1:], filesize, nil
+			}
+		}
+	}
+
+	return data, filesize, nil
+}
+
+// printFiles prints the listing of each file
+func printFiles(files []*pb.FileInfo) {
+	fmt.Println("----------------------------------------")
+	for _, file := range files {
+		printFile(file)
+	}
+}
+
+// printFile prints one file of a listing followed by a separator
+func printFile(file *pb.FileInfo) {
+	if file.SymlinkTarget != "" {
+		fmt.Printf("Name: %s -> %s\n", file.Filename, file.SymlinkTarget)
+	} else {
+		fmt.Printf("Name: %s\n", file.Filename)
+	}
+	fmt.Printf("Size: %d bytes\n", file.Filesize)
+	fmt.Printf("Chunks: %d\n", file.NumChunks)
+	fmt.Printf("Created: %s, modified: %s\n", formatTime(file.CreatedAt), formatTime(file.ModifiedAt))
+	fmt.Printf("Replication: %s\n", formatReplication(file))
+	fmt.Printf("Generation: %d\n", file.Generation)
+	if file.Owner != "" {
+		fmt.Printf("Owner: %s, mode %s\n", formatOwner(file), formatMode(file.Mode))
+	}
+	if len(file.HardLinks) > 0 {
+		fmt.Printf("Hard links: %s\n", strings.Join(file.HardLinks, ", "))
+	}
+	if file.Immutable {
+		fmt.Printf("Immutable: %s\n", formatRetainUntil(file.RetainUntil))
+	}
+	if len(file.Tags) > 0 {
+		fmt.Printf("Tags: %s\n", formatTags(file.Tags))
+	}
+	fmt.Printf("Reads: %d, last accessed: %s\n", file.ReadCount, formatLastAccessed(file.LastAccessed))
+	fmt.Println("----------------------------------------")
+}
+
+// parseListOptions converts the list flags to list options, negative sizes and an empty time meaning no filter
+func parseListOptions(tags map[string]string, sortBy string, reverse bool, minSize, maxSize int64, createdAfter string) (client.ListOptions, error) {
+	options := client.ListOptions{Tags: tags, Descending: reverse}
+
+	switch sortBy {
+	case "name":
+		options.SortBy = pb.ListSortKey_LIST_SORT_NAME
+	case "size":
+		options.SortBy = pb.ListSortKey_LIST_SORT_SIZE
+	case "mtime":
+		options.SortBy = pb.ListSortKey_LIST_SORT_MODIFIED
+	default:
+		return options, fmt.Errorf("unknown sort key %q, expected name, size or mtime", sortBy)
+	}
+
+	if minSize >= 0 {
+		options.MinSize = &minSize
+	}
+	if maxSize >= 0 {
+		options.MaxSize = &maxSize
+	}
+
+	after, err := parseTimeFlag("created-after", createdAfter)
+	if err != nil {
+		return options, err
+	}
+	options.CreatedAfter = after
+
+	return options, nil
+}
+
+// parseTimeFlag parses an RFC 3339 time or a local date given to the named flag, an empty value as the zero time
+func parseTimeFlag(name, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t, err = time.ParseInLocation(time.DateOnly, value, time.Local)
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid -%s %q, expected RFC 3339 or YYYY-MM-DD", name, value)
+	}
+
+	return t, nil
+}
+
+// formatLastAccessed formats a last access time in unix seconds, 0 meaning the file was never read
+func formatLastAccessed(lastAccessed int64) string {
+	if lastAccessed == 0 {
+		return "never"
+	}
+
+	return time.Unix(lastAccessed, 0).Format(time.DateTime)
+}
+
+// formatTime formats a time in unix seconds
+func formatTime(unixSeconds int64) string {
+	return time.Unix(unixSeconds, 0).Format(time.DateTime)
+}
+
+// formatReplication describes the replication factor of a file and whether all its chunks have that many replicas
+func formatReplication(file *pb.FileInfo) string {
+	if file.Degraded {
+		return fmt.Sprintf("%d replicas, degraded (a chunk has %d)", file.ReplicationFactor, file.MinReplicas)
+	}
+
+	return fmt.Sprintf("%d replicas, healthy", file.ReplicationFactor)
+}
+
+// formatRetainUntil formats the end of an immutable file's retention in unix seconds, 0 meaning forever
+func formatRetainUntil(retainUntil int64) string {
+	if retainUntil == 0 {
+		return "forever"
+	}
+
+	return "until " + time.Unix(retainUntil, 0).Format(time.DateTime)
+}
+
+func printFileAttributes(attributes *pb.FileAttributes) {
+	fmt.Printf("Tags: %s\n", formatTags(attributes.Tags))
+	fmt.Printf("Replication factor: %d\n", attributes.ReplicationFactor)
+	if attributes.StorageTier != "" {
+		fmt.Printf("Tier: %s\n", attributes.StorageTier)
+	}
+	if attributes.StorageClass != "" {
+		fmt.Printf("Storage class: %s\n", attributes.StorageClass)
+	}
+	if attributes.ExpiresAt != 0 {
+		fmt.Printf("Expires: %s\n", time.Unix(attributes.ExpiresAt, 0).Format(time.DateTime))
+	} else {
+		fmt.Printf("Expires: never\n")
+	}
+	if attributes.Immutable {
+		fmt.Printf("Immutable: %s\n", formatRetainUntil(attributes.RetainUntil))
+	}
+}
+
+// printVersions prints the version of the client and the master, and of every live chunk server when servers is set.
+// A server that can't be reached is reported without failing, since diagnosing such a cluster is the point
+func printVersions(dfsClient *client.Client, masterAddress string, servers bool) error {
+	fmt.Printf("Client: %s\n", formatServerInfo(&pb.GetServerInfoResponse{
+		Version:   common.Version,
+		Commit:    common.BuildCommit(),
+		GoVersion: runtime.Version(),
+		Features:  common.ProtocolFeatures,
+	}))
+
+	info, err := dfsClient.GetServerInfo()
+	if err != nil {
+		fmt.Printf("Master %s: %v\n", masterAddress, err)
+	} else {
+		fmt.Printf("Master %s: %s\n", masterAddress, formatServerInfo(info))
+	}
+
+	if !servers {
+		return nil
+	}
+
+	distribution, err := dfsClient.GetChunkDistribution()
+	if err != nil {
+		return err
+	}
+
+	for _, server := range distribution.Servers {
+		if server.State == "DEAD" {
+			fmt.Printf("Chunk server %s: dead\n", server.Address)
+			continue
+		}
+
+		info, err := dfsClient.GetChunkServerInfo(server.Address)
+		if err != nil {
+			fmt.Printf("Chunk server %s: %v\n", server.Address, err)
+			continue
+		}
+		fmt.Printf("Chunk server %s: %s\n", server.Address, formatServerInfo(info))
+	}
+
+	return nil
+}
+
+// formatServerInfo formats a version for printing, listing the protocol features this client knows of that the server lacks
+func formatServerInfo(info *pb.GetServerInfoResponse) string {
+	commit := info.Commit
+	if commit == "" {
+		commit = "unknown"
+	}
+
+	formatted := fmt.Sprintf("%s (commit %s, %s)", info.Version, commit, info.GoVersion)
+
+	missing := make([]string, 0)
+	for _, feature := range common.ProtocolFeatures {
+		if !slices.Contains(info.Features, feature) {
+			missing = append(missing, feature)
+		}
+	}
+	if len(missing) > 0 {
+		formatted += fmt.Sprintf(", missing features: %s", strings.Join(missing, ", "))
+	}
+
+	return formatted
+}
+
+// formatOwner formats the owner of a file with its group, e.g. alice:etl
+func formatOwner(file *pb.FileInfo) string {
+	if file.Group == "" {
+		return file.Owner
+	}
+
+	return file.Owner + ":" + file.Group
+}
+
+// formatMode formats permission bits in octal and like ls -l, e.g. 0640 (-rw-r-----)
+func formatMode(mode uint32) string {
+	return fmt.Sprintf("%04o (%s)", mode, os.FileMode(mode))
+}
+
+// parseMode parses octal permission bits, e.g. 640 or 0640
+func parseMode(value string) (uint32, error) {
+	mode, err := strconv.ParseUint(value, 8, 32)
+	if err != nil || mode > 0o777 {
+		return 0, fmt.Errorf("invalid mode %q, expected octal permission bits like 0640", value)
+	}
+
+	return uint32(mode), nil
+}
+
+func printFileInfo(info *pb.GetFileInfoResponse) {
+	fmt.Printf("Name: %s\n", info.File.Filename)
+	fmt.Printf("Size: %d bytes\n", info.File.Filesize)
+	fmt.Printf("Generation: %d\n", info.File.Generation)
+	if info.File.Owner != "" {
+		fmt.Printf("Owner: %s, mode %s\n", formatOwner(info.File), formatMode(info.File.Mode))
+	}
+	if len(info.File.HardLinks) > 0 {
+		fmt.Printf("Hard links: %s\n", strings.Join(info.File.HardLinks, ", "))
+	}
+	if info.File.Immutable {
+		fmt.Printf("Immutable: %s\n", formatRetainUntil(info.File.RetainUntil))
+	}
+	if len(info.File.Tags) > 0 {
+		fmt.Printf("Tags: %s\n", formatTags(info.File.Tags))
+	}
+	fmt.Printf("Created: %s\n", formatTime(info.File.CreatedAt))
+	fmt.Printf("Modified: %s\n", formatTime(info.File.ModifiedAt))
+	fmt.Printf("Replication: %s\n", formatReplication(info.File))
+	if info.File.StorageTier != "" {
+		fmt.Printf("Tier: %s\n", info.File.StorageTier)
+	}
+	if info.File.StorageClass != "" {
+		fmt.Printf("Storage class: %s\n", info.File.StorageClass)
+	}
+	if info.File.ExpiresAt != 0 {
+		fmt.Printf("Expires: %s\n", time.Unix(info.File.ExpiresAt, 0).Format(time.DateTime))
+	}
+	fmt.Printf("Reads: %d\n", info.File.ReadCount)
+	fmt.Printf("Last accessed: %s\n", formatLastAccessed(info.File.LastAccessed))
+	if info.File.Inline {
+		fmt.Println("Chunks: none, stored inline in the master's metadata")
+	} else if info.File.ArchivedSize > 0 {
+		fmt.Printf("Chunks: %d, archived as %s compressed, recalled on the next read\n", info.File.NumChunks, common.FormatBytes(float64(info.File.ArchivedSize)))
+	} else {
+		fmt.Printf("Chunks: %d\n", info.File.NumChunks)
+	}
+	for _, chunkLoc := range info.ChunkLocations {
+		fmt.Printf("  [%d] %s -> %v\n", chunkLoc.ChunkIndex, chunkLoc.ChunkHandle, chunkLoc.ChunkServerAddresses)
+	}
 }
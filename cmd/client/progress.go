@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/harshvardha/distributed_file_system/client"
+	"github.com/harshvardha/distributed_file_system/common"
+)
+
+// progressBarWidth is the number of characters used by the bar itself
+const progressBarWidth = 30
+
+// progressBar renders transfer progress with the transfer rate on stderr
+type progressBar struct {
+	start time.Time
+}
+
+func newProgressBar() *progressBar {
+	return &progressBar{start: time.Now()}
+}
+
+// update redraws the bar for the latest progress
+func (p *progressBar) update(progress client.Progress) {
+	// a stream of unknown size has no bar until it ends
+	if progress.TotalChunks == 0 && progress.ChunksDone > 0 {
+		fmt.Fprintf(os.Stderr, "\r%s %s/s chunks %d", common.FormatBytes(float64(progress.BytesTransferred)), common.FormatBytes(p.rate(progress)), progress.ChunksDone)
+		return
+	}
+
+	fraction := 1.0
+	if progress.TotalBytes > 0 {
+		fraction = float64(progress.BytesTransferred) / float64(progress.TotalBytes)
+	}
+
+	filled := int(fraction * progressBarWidth)
+	bar := strings.Repeat("=", filled) + strings.Repeat(" ", progressBarWidth-filled)
+
+	rate := p.rate(progress)
+
+	fmt.Fprintf(os.Stderr, "\r[%s] %3.0f%% %s/%s %s/s chunks %d/%d",
+		bar, fraction*100, common.FormatBytes(float64(progress.BytesTransferred)), common.FormatBytes(float64(progress.TotalBytes)),
+		common.FormatBytes(rate), progress.ChunksDone, progress.TotalChunks)
+
+	if progress.ChunksDone == progress.TotalChunks {
+		fmt.Fprintln(os.Stderr)
+	}
+}
+
+// rate returns the bytes transferred per second so far
+func (p *progressBar) rate(progress client.Progress) float64 {
+	if elapsed := time.Since(p.start).Seconds(); elapsed > 0 {
+		return float64(progress.BytesTransferred) / elapsed
+	}
+
+	return 0
+}
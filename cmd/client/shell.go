@@ -0,0 +1,304 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/chzyer/readline"
+	"github.com/harshvardha/distributed_file_system/client"
+)
+
+// shellCommands are the commands understood by the interactive shell
+var shellCommands = []string{"cd", "exit", "get", "help", "ls", "put", "pwd", "quit", "rm", "stat"}
+
+// remoteArgCommands are the shell commands whose arguments are remote paths
+var remoteArgCommands = []string{"cd", "get", "ls", "rm", "stat"}
+
+// shell is an interactive session that reuses one client, and so its connections, for every command
+type shell struct {
+	client *client.Client
+	cwd    string // current remote directory, "" for root otherwise always ends with "/"
+}
+
+// dirEntry is a single entry of a remote directory listing
+type dirEntry struct {
+	name     string
+	isDir    bool
+	filesize int64
+}
+
+// runShell starts the read-eval-print loop and returns when the user exits
+func runShell(dfsClient *client.Client) error {
+	sh := &shell{client: dfsClient}
+
+	config := &readline.Config{
+		Prompt:       sh.prompt(),
+		AutoComplete: sh,
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		config.HistoryFile = filepath.Join(home, ".dfs_history")
+	}
+
+	rl, err := readline.NewEx(config)
+	if err != nil {
+		return fmt.Errorf("failed to start shell: %v", err)
+	}
+	defer rl.Close()
+
+	fmt.Println("Distributed File System Shell, type 'help' for commands")
+
+	for {
+		line, err := rl.Readline()
+		if errors.Is(err, readline.ErrInterrupt) {
+			continue
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		args := strings.Fields(line)
+		if len(args) == 0 {
+			continue
+		}
+
+		if args[0] == "exit" || args[0] == "quit" {
+			return nil
+		}
+
+		if err := sh.execute(args); err != nil {
+			fmt.Printf("Error: %v\n", err)
+		}
+		rl.SetPrompt(sh.prompt())
+	}
+}
+
+// prompt builds the shell prompt showing the current remote directory
+func (sh *shell) prompt() string {
+	return "dfs:/" + sh.cwd + "> "
+}
+
+// execute runs a single shell command
+func (sh *shell) execute(args []string) error {
+	switch args[0] {
+	case "help":
+		printShellHelp()
+	case "pwd":
+		fmt.Println("/" + sh.cwd)
+	case "cd":
+		if len(args) == 1 {
+			sh.cwd = ""
+			return nil
+		}
+
+		dir := sh.resolveDir(args[1])
+		if dir != "" {
+			entries, err := sh.list(dir)
+			if err != nil {
+				return err
+			}
+			if len(entries) == 0 {
+				return fmt.Errorf("no such directory: %s", args[1])
+			}
+		}
+		sh.cwd = dir
+	case "ls":
+		dir := sh.cwd
+		if len(args) > 1 {
+			dir = sh.resolveDir(args[1])
+		}
+
+		entries, err := sh.list(dir)
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			if entry.isDir {
+				fmt.Printf("%12s  %s\n", "-", entry.name)
+			} else {
+				fmt.Printf("%12d  %s\n", entry.filesize, entry.name)
+			}
+		}
+	case "get":
+		if len(args) < 2 {
+			return fmt.Errorf("usage: get <remote_name> [local_path]")
+		}
+
+		remoteName := sh.resolve(args[1])
+		localPath := path.Base(remoteName)
+		if len(args) > 2 {
+			localPath = args[2]
+		}
+
+		if err := sh.client.DownloadFile(remoteName, localPath); err != nil {
+			return err
+		}
+		fmt.Printf("Downloaded %s to %s\n", remoteName, localPath)
+	case "put":
+		if len(args) < 2 {
+			return fmt.Errorf("usage: put <local_path> [remote_name]")
+		}
+
+		remoteName := sh.resolve(filepath.Base(args[1]))
+		if len(args) > 2 {
+			remoteName = sh.resolve(args[2])
+		}
+
+		if err := sh.client.UploadFile(args[1], remoteName); err != nil {
+			return err
+		}
+		fmt.Printf("Uploaded %s to %s\n", args[1], remoteName)
+	case "rm":
+		if len(args) < 2 {
+			return fmt.Errorf("usage: rm <remote_name>")
+		}
+
+		if err := sh.client.DeleteFile(sh.resolve(args[1])); err != nil {
+			return err
+		}
+	case "stat":
+		if len(args) < 2 {
+			return fmt.Errorf("usage: stat <remote_name>")
+		}
+
+		info, err := sh.client.GetFileInfo(sh.resolve(args[1]))
+		if err != nil {
+			return err
+		}
+		printFileInfo(info)
+	default:
+		return fmt.Errorf("unknown command: %s, type 'help' for commands", args[0])
+	}
+
+	return nil
+}
+
+// resolve turns a path relative to the current directory into a remote file name
+func (sh *shell) resolve(name string) string {
+	if !strings.HasPrefix(name, "/") {
+		name = "/" + sh.cwd + name
+	}
+
+	return strings.TrimPrefix(path.Clean(name), "/")
+}
+
+// resolveDir turns a path relative to the current directory into a remote directory prefix
+func (sh *shell) resolveDir(name string) string {
+	dir := sh.resolve(name)
+	if dir == "" {
+		return ""
+	}
+
+	return dir + "/"
+}
+
+// list returns the files and sub directories directly under dir
+func (sh *shell) list(dir string) ([]dirEntry, error) {
+	files, err := sh.client.ListFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	seenDirs := make(map[string]bool)
+	entries := make([]dirEntry, 0)
+
+	for _, file := range files {
+		if !strings.HasPrefix(file.Filename, dir) {
+			continue
+		}
+
+		name := strings.TrimPrefix(file.Filename, dir)
+		if subDir, _, isNested := strings.Cut(name, "/"); isNested {
+			if !seenDirs[subDir] {
+				seenDirs[subDir] = true
+				entries = append(entries, dirEntry{name: subDir + "/", isDir: true})
+			}
+			continue
+		}
+
+		entries = append(entries, dirEntry{name: name, filesize: file.Filesize})
+	}
+
+	slices.SortFunc(entries, func(a, b dirEntry) int {
+		return strings.Compare(a.name, b.name)
+	})
+
+	return entries, nil
+}
+
+// Do implements readline.AutoCompleter, completing command names and remote paths
+func (sh *shell) Do(line []rune, pos int) ([][]rune, int) {
+	text := string(line[:pos])
+	args := strings.Fields(text)
+
+	word := ""
+	if len(args) > 0 && !strings.HasSuffix(text, " ") {
+		word = args[len(args)-1]
+		args = args[:len(args)-1]
+	}
+
+	// completing the command itself
+	if len(args) == 0 {
+		return completions(shellCommands, word), len([]rune(word))
+	}
+
+	if !slices.Contains(remoteArgCommands, args[0]) {
+		return nil, 0
+	}
+
+	// completing a remote path: list the directory part and match the base part
+	dirPart, basePart := "", word
+	if i := strings.LastIndex(word, "/"); i >= 0 {
+		dirPart, basePart = word[:i+1], word[i+1:]
+	}
+
+	dir := sh.cwd
+	if dirPart != "" {
+		dir = sh.resolveDir(dirPart)
+	}
+
+	entries, err := sh.list(dir)
+	if err != nil {
+		return nil, 0
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.name)
+	}
+
+	return completions(names, basePart), len([]rune(basePart))
+}
+
+// completions returns the remaining suffix of every candidate starting with word
+func completions(candidates []string, word string) [][]rune {
+	suffixes := make([][]rune, 0)
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, word) {
+			suffixes = append(suffixes, []rune(strings.TrimPrefix(candidate, word)))
+		}
+	}
+
+	return suffixes
+}
+
+func printShellHelp() {
+	fmt.Println("Commands:")
+	fmt.Println("	ls [dir]                         list the current or given directory")
+	fmt.Println("	cd [dir]                         change directory, no argument goes to /")
+	fmt.Println("	pwd                              print the current directory")
+	fmt.Println("	get <remote_name> [local_path]   download a file")
+	fmt.Println("	put <local_path> [remote_name]   upload a file")
+	fmt.Println("	rm <remote_name>                 delete a file")
+	fmt.Println("	stat <remote_name>               show file metadata and chunk locations")
+	fmt.Println("	exit                             leave the shell")
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// tagFlag collects repeated key=value flags into a tag map
+type tagFlag map[string]string
+
+func (t tagFlag) String() string {
+	return formatTags(t)
+}
+
+func (t tagFlag) Set(value string) error {
+	key, tagValue, found := strings.Cut(value, "=")
+	if !found || key == "" {
+		return fmt.Errorf("expected key=value, got %q", value)
+	}
+
+	t[key] = tagValue
+	return nil
+}
+
+// listFlag collects repeated flags into a list
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// formatTags formats tags as comma separated key=value pairs in key order
+func formatTags(tags map[string]string) string {
+	pairs := make([]string, 0, len(tags))
+	for _, key := range slices.Sorted(maps.Keys(tags)) {
+		pairs = append(pairs, key+"="+tags[key])
+	}
+
+	return strings.Join(pairs, ", ")
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/harshvardha/distributed_file_system/client"
+	"github.com/harshvardha/distributed_file_system/common"
+	pb "github.com/harshvardha/distributed_file_system/proto"
+)
+
+const (
+	// tarTagPrefix prefixes the PAX records holding a file's tags in tar archives, stored as
+	// extended attributes so tar extracts them with --xattrs instead of warning about them
+	tarTagPrefix = "SCHILY.xattr.user.dfs.tag."
+
+	// s3TagsMetadata is the S3 user metadata holding a file's tags, url query encoded
+	s3TagsMetadata = "dfs-tags"
+)
+
+// archiveSettings are the flags shared by export and import
+type archiveSettings struct {
+	prefixes   []string // only files under these prefixes, all files when empty
+	s3Endpoint string   // S3 compatible endpoint, AWS when empty
+	s3Region   string
+}
+
+// selected reports whether a file is under one of the selected prefixes
+func (s archiveSettings) selected(filename string) bool {
+	return len(s.prefixes) == 0 || slices.ContainsFunc(s.prefixes, func(prefix string) bool {
+		return strings.HasPrefix(filename, prefix)
+	})
+}
+
+// parseS3Location splits an s3://bucket/prefix location, ok is false for other locations
+func parseS3Location(location string) (bucket, keyPrefix string, ok bool) {
+	rest, ok := strings.CutPrefix(location, "s3://")
+	if !ok {
+		return "", "", false
+	}
+
+	bucket, keyPrefix, _ = strings.Cut(rest, "/")
+	return bucket, keyPrefix, true
+}
+
+// exportFiles streams the selected files one at a time into a tar archive, - for stdout, or an
+// s3://bucket/prefix location, where each file becomes the object prefix followed by its name
+func exportFiles(dfsClient *client.Client, output string, settings archiveSettings) error {
+	files, err := dfsClient.ListFiles()
+	if err != nil {
+		return err
+	}
+	files = slices.DeleteFunc(files, func(file *pb.FileInfo) bool {
+		return !settings.selected(file.Filename)
+	})
+
+	// the archive itself may be going to stdout
+	status := os.Stdout
+	if output == "-" {
+		status = os.Stderr
+	}
+
+	var total int64
+	if bucket, keyPrefix, ok := parseS3Location(output); ok {
+		s3, err := newS3Client(settings.s3Endpoint, settings.s3Region, bucket)
+		if err != nil {
+			return err
+		}
+
+		for _, file := range files {
+			if err := exportToS3(dfsClient, s3, keyPrefix+file.Filename, file); err != nil {
+				return fmt.Errorf("failed to export %s: %v", file.Filename, err)
+			}
+			fmt.Fprintf(status, "Exported %s (%s)\n", file.Filename, common.FormatBytes(float64(file.Filesize)))
+			total += file.Filesize
+		}
+	} else {
+		w := os.Stdout
+		if output != "-" {
+			w, err = os.Create(output)
+			if err != nil {
+				return fmt.Errorf("failed to create archive: %v", err)
+			}
+			defer w.Close()
+		}
+
+		tw := tar.NewWriter(w)
+		for _, file := range files {
+			if err := exportToTar(dfsClient, tw, file); err != nil {
+				return fmt.Errorf("failed to export %s: %v", file.Filename, err)
+			}
+			fmt.Fprintf(status, "Exported %s (%s)\n", file.Filename, common.FormatBytes(float64(file.Filesize)))
+			total += file.Filesize
+		}
+
+		if err := tw.Close(); err != nil {
+			return fmt.Errorf("failed to finish archive: %v", err)
+		}
+		if output != "-" {
+			if err := w.Close(); err != nil {
+				return fmt.Errorf("failed to write archive: %v", err)
+			}
+		}
+	}
+
+	fmt.Fprintf(status, "Exported %d files, %s\n", len(files), common.FormatBytes(float64(total)))
+	return nil
+}
+
+// exportToTar writes the listed generation of a file to the archive, keeping its tags in PAX records
+func exportToTar(dfsClient *client.Client, tw *tar.Writer, file *pb.FileInfo) error {
+	header := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     file.Filename,
+		Size:     file.Filesize,
+		Mode:     0644,
+		ModTime:  time.Unix(file.ModifiedAt, 0),
+		Format:   tar.FormatPAX,
+	}
+	if len(file.Tags) > 0 {
+		header.PAXRecords = make(map[string]string, len(file.Tags))
+		for key, value := range file.Tags {
+			header.PAXRecords[tarTagPrefix+key] = value
+		}
+	}
+
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+
+	// pinning the generation so the contents match the size written in the header
+	return dfsClient.DownloadVersion(file.Filename, file.Generation, tw)
+}
+
+// exportToS3 streams the listed generation of a file into the object key, keeping its tags as user metadata
+func exportToS3(dfsClient *client.Client, s3 *s3Client, key string, file *pb.FileInfo) error {
+	metadata := map[string]string{}
+	if len(file.Tags) > 0 {
+		tags := url.Values{}
+		for key, value := range file.Tags {
+			tags.Set(key, value)
+		}
+		metadata[s3TagsMetadata] = tags.Encode()
+	}
+
+	r, w := io.Pipe()
+	go func() {
+		w.CloseWithError(dfsClient.DownloadVersion(file.Filename, file.Generation, w))
+	}()
+	defer r.Close()
+
+	return s3.put(key, r, file.Filesize, metadata)
+}
+
+// importFiles uploads the selected files of a tar archive, - for stdin, or an s3://bucket/prefix location,
+// keeping their tags. Files that already exist are skipped unless overwrite is set
+func importFiles(dfsClient *client.Client, input string, overwrite bool, settings archiveSettings) error {
+	var imported, skipped int
+	var total int64
+	importFile := func(filename string, r io.Reader, size int64, tags map[string]string) error {
+		if !settings.selected(filename) {
+			return nil
+		}
+
+		err := dfsClient.UploadReaderWithOptions(r, filename, client.UploadOptions{Tags: tags, Exclusive: !overwrite})
+		if errors.Is(err, client.ErrFileExists) {
+			fmt.Printf("Skipped %s, it already exists\n", filename)
+			skipped++
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to import %s: %v", filename, err)
+		}
+
+		fmt.Printf("Imported %s (%s)\n", filename, common.FormatBytes(float64(size)))
+		imported++
+		total += size
+		return nil
+	}
+
+	if bucket, keyPrefix, ok := parseS3Location(input); ok {
+		s3, err := newS3Client(settings.s3Endpoint, settings.s3Region, bucket)
+		if err != nil {
+			return err
+		}
+
+		objects, err := s3.list(keyPrefix)
+		if err != nil {
+			return err
+		}
+
+		for _, object := range objects {
+			filename := strings.TrimPrefix(object.Key, keyPrefix)
+			if filename == "" || strings.HasSuffix(filename, "/") || !settings.selected(filename) {
+				continue
+			}
+
+			body, metadata, err := s3.get(object.Key)
+			if err != nil {
+				return err
+			}
+			tags, err := url.ParseQuery(metadata[s3TagsMetadata])
+			if err != nil {
+				body.Close()
+				return fmt.Errorf("invalid tags of %s: %v", object.Key, err)
+			}
+
+			err = importFile(filename, body, object.Size, firstValues(tags))
+			body.Close()
+			if err != nil {
+				return err
+			}
+		}
+	} else {
+		r := os.Stdin
+		if input != "-" {
+			var err error
+			r, err = os.Open(input)
+			if err != nil {
+				return fmt.Errorf("failed to open archive: %v", err)
+			}
+			defer r.Close()
+		}
+
+		tr := tar.NewReader(r)
+		for {
+			header, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return fmt.Errorf("failed to read archive: %v", err)
+			}
+			if header.Typeflag != tar.TypeReg {
+				continue
+			}
+
+			tags := make(map[string]string)
+			for key, value := range header.PAXRecords {
+				if key, ok := strings.CutPrefix(key, tarTagPrefix); ok {
+					tags[key] = value
+				}
+			}
+
+			if err := importFile(header.Name, tr, header.Size, tags); err != nil {
+				return err
+			}
+		}
+	}
+
+	fmt.Printf("Imported %d files, %s, skipped %d existing files\n", imported, common.FormatBytes(float64(total)), skipped)
+	return nil
+}
+
+// firstValues keeps the first value of each key
+func firstValues(values url.Values) map[string]string {
+	first := make(map[string]string, len(values))
+	for key := range values {
+		first[key] = values.Get(key)
+	}
+
+	return first
+}
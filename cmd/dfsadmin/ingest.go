@@ -0,0 +1,251 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/harshvardha/distributed_file_system/client"
+	"github.com/harshvardha/distributed_file_system/common"
+)
+
+// ingestObject is a file of the ingest source
+type ingestObject struct {
+	path     string // relative to the source location, the name it gets under the destination prefix
+	location string // object key or HDFS path
+	size     int64
+}
+
+// ingestSource lists and reads the files of an S3 bucket prefix or an HDFS directory
+type ingestSource interface {
+	list() ([]ingestObject, error)
+	open(object ingestObject) (io.ReadCloser, error)
+}
+
+// s3Source ingests the objects under a key prefix of a bucket
+type s3Source struct {
+	s3        *s3Client
+	keyPrefix string
+}
+
+func (s *s3Source) list() ([]ingestObject, error) {
+	objects, err := s.s3.list(s.keyPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]ingestObject, 0, len(objects))
+	for _, object := range objects {
+		relativePath := strings.TrimPrefix(strings.TrimPrefix(object.Key, s.keyPrefix), "/")
+		if relativePath == "" || strings.HasSuffix(relativePath, "/") {
+			continue // folder markers
+		}
+		files = append(files, ingestObject{path: relativePath, location: object.Key, size: object.Size})
+	}
+
+	return files, nil
+}
+
+func (s *s3Source) open(object ingestObject) (io.ReadCloser, error) {
+	body, _, err := s.s3.get(object.location)
+	return body, err
+}
+
+// hdfsSource ingests the files under an HDFS directory
+type hdfsSource struct {
+	hdfs *webHDFSClient
+	root string
+}
+
+func (s *hdfsSource) list() ([]ingestObject, error) {
+	var files []ingestObject
+	err := s.hdfs.walk(s.root, func(filePath string, length int64) {
+		relativePath := strings.TrimPrefix(strings.TrimPrefix(filePath, s.root), "/")
+		if relativePath == "" {
+			relativePath = path.Base(filePath) // the root is a file
+		}
+		files = append(files, ingestObject{path: relativePath, location: filePath, size: length})
+	})
+
+	return files, err
+}
+
+func (s *hdfsSource) open(object ingestObject) (io.ReadCloser, error) {
+	return s.hdfs.open(object.location)
+}
+
+// openIngestSource parses an s3://bucket/prefix or hdfs://namenode:port/path source location.
+// HDFS is read through WebHDFS, so the port is the namenode's http port, 9870 by default
+func openIngestSource(location string, settings archiveSettings, hdfsUser string) (ingestSource, error) {
+	if bucket, keyPrefix, ok := parseS3Location(location); ok {
+		s3, err := newS3Client(settings.s3Endpoint, settings.s3Region, bucket)
+		if err != nil {
+			return nil, err
+		}
+		return &s3Source{s3: s3, keyPrefix: keyPrefix}, nil
+	}
+
+	source, err := url.Parse(location)
+	if err != nil || (source.Scheme != "hdfs" && source.Scheme != "webhdfs") || source.Host == "" {
+		return nil, fmt.Errorf("invalid source %q, expected s3://bucket/prefix or hdfs://namenode:9870/path", location)
+	}
+	if source.Port() == "" {
+		source.Host += ":9870"
+	}
+
+	root := path.Clean("/" + source.Path)
+	return &hdfsSource{hdfs: newWebHDFSClient("http://"+source.Host, hdfsUser), root: root}, nil
+}
+
+// ingestCheckpoint records the source files already ingested, one quoted path per line,
+// so an interrupted ingest resumes where it stopped. A nil checkpoint records nothing
+type ingestCheckpoint struct {
+	mu   sync.Mutex
+	file *os.File
+	done map[string]bool
+}
+
+// openIngestCheckpoint loads the paths recorded in filename, creating it if needed
+func openIngestCheckpoint(filename string) (*ingestCheckpoint, error) {
+	file, err := os.OpenFile(filename, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open checkpoint: %v", err)
+	}
+
+	checkpoint := &ingestCheckpoint{file: file, done: make(map[string]bool)}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		// a line cut short by a crash is ingested again
+		if relativePath, err := strconv.Unquote(scanner.Text()); err == nil {
+			checkpoint.done[relativePath] = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to read checkpoint: %v", err)
+	}
+
+	return checkpoint, nil
+}
+
+func (c *ingestCheckpoint) contains(relativePath string) bool {
+	if c == nil {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.done[relativePath]
+}
+
+// record marks a source file ingested
+func (c *ingestCheckpoint) record(relativePath string) error {
+	if c == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.done[relativePath] = true
+	if _, err := c.file.WriteString(strconv.Quote(relativePath) + "\n"); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %v", err)
+	}
+
+	return nil
+}
+
+func (c *ingestCheckpoint) Close() error {
+	return c.file.Close()
+}
+
+// ingestFiles uploads every file of source into the DFS as destPrefix followed by its path relative to the
+// source, parallel files at a time. Files recorded in the checkpoint are skipped, and so are files already
+// in the DFS unless overwrite is set. Failed files are reported at the end and retried by running again
+func ingestFiles(dfsClient *client.Client, source ingestSource, destPrefix string, parallel int, checkpoint *ingestCheckpoint, overwrite bool) error {
+	objects, err := source.list()
+	if err != nil {
+		return fmt.Errorf("failed to list source: %v", err)
+	}
+
+	pending := make([]ingestObject, 0, len(objects))
+	for _, object := range objects {
+		if !checkpoint.contains(object.path) {
+			pending = append(pending, object)
+		}
+	}
+	fmt.Printf("Ingesting %d files, %d already ingested\n", len(pending), len(objects)-len(pending))
+
+	var (
+		wg                          sync.WaitGroup
+		mu                          sync.Mutex
+		ingested, skipped, failures int
+		total                       int64
+	)
+	work := make(chan ingestObject)
+	for range max(parallel, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for object := range work {
+				filename := destPrefix + object.path
+				existed, err := ingestFile(dfsClient, source, object, filename, overwrite)
+				if err == nil {
+					err = checkpoint.record(object.path)
+				}
+
+				mu.Lock()
+				switch {
+				case err != nil:
+					fmt.Printf("Failed to ingest %s: %v\n", object.path, err)
+					failures++
+				case existed:
+					fmt.Printf("Skipped %s, %s already exists\n", object.path, filename)
+					skipped++
+				default:
+					fmt.Printf("Ingested %s as %s (%s)\n", object.path, filename, common.FormatBytes(float64(object.size)))
+					ingested++
+					total += object.size
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, object := range pending {
+		work <- object
+	}
+	close(work)
+	wg.Wait()
+
+	fmt.Printf("Ingested %d files, %s, skipped %d existing files\n", ingested, common.FormatBytes(float64(total)), skipped)
+	if failures > 0 {
+		return fmt.Errorf("%d files failed, run the same command again to retry them", failures)
+	}
+
+	return nil
+}
+
+// ingestFile copies one source file into the DFS, existed reporting a file skipped because it already exists
+func ingestFile(dfsClient *client.Client, source ingestSource, object ingestObject, filename string, overwrite bool) (bool, error) {
+	body, err := source.open(object)
+	if err != nil {
+		return false, err
+	}
+	defer body.Close()
+
+	err = dfsClient.UploadReaderWithOptions(body, filename, client.UploadOptions{Exclusive: !overwrite})
+	if errors.Is(err, client.ErrFileExists) {
+		return true, nil
+	}
+
+	return false, err
+}
@@ -0,0 +1,456 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/harshvardha/distributed_file_system/client"
+	"github.com/harshvardha/distributed_file_system/common"
+	"github.com/harshvardha/distributed_file_system/mirroring"
+)
+
+func main() {
+	// Creating subcommands
+	reportCmd := flag.NewFlagSet("report", flag.ExitOnError)
+	reportMaster := reportCmd.String("master", common.MasterAddress, "Master server address")
+
+	unaccessedCmd := flag.NewFlagSet("unaccessed", flag.ExitOnError)
+	unaccessedMaster := unaccessedCmd.String("master", common.MasterAddress, "Master server address")
+	unaccessedDays := unaccessedCmd.Int("days", 30, "Only files not read for at least this many days")
+	unaccessedLimit := unaccessedCmd.Int("limit", 20, "Maximum number of files to show, 0 shows all")
+
+	geoStatusCmd := flag.NewFlagSet("geo-status", flag.ExitOnError)
+	geoStatusMaster := geoStatusCmd.String("master", common.MasterAddress, "Master server address")
+
+	reclaimCmd := flag.NewFlagSet("reclaim", flag.ExitOnError)
+	reclaimMaster := reclaimCmd.String("master", common.MasterAddress, "Master server address")
+
+	exportCmd := flag.NewFlagSet("export", flag.ExitOnError)
+	exportMaster := exportCmd.String("master", common.MasterAddress, "Master server address")
+	exportOutput := exportCmd.String("output", "", "Tar archive to write, - for stdout, or s3://bucket/prefix")
+	exportPrefixes := exportCmd.String("prefix", "", "Comma separated prefixes of the files to export, all files when empty")
+	exportS3Endpoint := exportCmd.String("s3-endpoint", "", "S3 compatible endpoint, e.g. http://localhost:9000 for MinIO (AWS when empty)")
+	exportS3Region := exportCmd.String("s3-region", cmp.Or(os.Getenv("AWS_REGION"), "us-east-1"), "S3 region")
+	exportVerbose := exportCmd.Bool("v", false, "Show client log output")
+
+	importCmd := flag.NewFlagSet("import", flag.ExitOnError)
+	importMaster := importCmd.String("master", common.MasterAddress, "Master server address")
+	importInput := importCmd.String("input", "", "Tar archive to read, - for stdin, or s3://bucket/prefix")
+	importPrefixes := importCmd.String("prefix", "", "Comma separated prefixes of the files to import, all files when empty")
+	importOverwrite := importCmd.Bool("overwrite", false, "Replace files that already exist instead of skipping them")
+	importS3Endpoint := importCmd.String("s3-endpoint", "", "S3 compatible endpoint, e.g. http://localhost:9000 for MinIO (AWS when empty)")
+	importS3Region := importCmd.String("s3-region", cmp.Or(os.Getenv("AWS_REGION"), "us-east-1"), "S3 region")
+	importVerbose := importCmd.Bool("v", false, "Show client log output")
+
+	ingestCmd := flag.NewFlagSet("ingest", flag.ExitOnError)
+	ingestMaster := ingestCmd.String("master", common.MasterAddress, "Master server address")
+	ingestSourceFlag := ingestCmd.String("source", "", "Files to ingest: s3://bucket/prefix or hdfs://namenode:9870/path")
+	ingestDestPrefix := ingestCmd.String("dest-prefix", "", "Prefix put before the path of every file relative to -source")
+	ingestParallel := ingestCmd.Int("parallel", 4, "Files copied at once, each is buffered in memory while uploaded")
+	ingestCheckpointFile := ingestCmd.String("checkpoint", "", "File recording the files already ingested, so a rerun resumes where an interrupted one stopped")
+	ingestOverwrite := ingestCmd.Bool("overwrite", false, "Replace files that already exist instead of skipping them")
+	ingestS3Endpoint := ingestCmd.String("s3-endpoint", "", "S3 compatible endpoint, e.g. http://localhost:9000 for MinIO (AWS when empty)")
+	ingestS3Region := ingestCmd.String("s3-region", cmp.Or(os.Getenv("AWS_REGION"), "us-east-1"), "S3 region")
+	ingestHDFSUser := ingestCmd.String("hdfs-user", os.Getenv("HADOOP_USER_NAME"), "HDFS user the files are read as")
+	ingestVerbose := ingestCmd.Bool("v", false, "Show client log output")
+
+	mirrorCmd := flag.NewFlagSet("mirror", flag.ExitOnError)
+	mirrorMaster := mirrorCmd.String("master", common.MasterAddress, "Master server address of the cluster mirrored")
+	mirrorTo := mirrorCmd.String("to", "", "Master server address of the cluster the files are copied to")
+	mirrorToTokenFile := mirrorCmd.String("to-token-file", "", "File holding the user token of the -to cluster (defaults to $"+common.TokenEnv+")")
+	mirrorPrefix := mirrorCmd.String("prefix", "", "Only mirror files whose name starts with this prefix")
+	mirrorParallel := mirrorCmd.Int("parallel", mirroring.DefaultWorkers, "Files copied at once")
+	mirrorConflicts := mirrorCmd.String("conflict-policy", "keep-remote", "What happens to files written on the -to cluster: keep-remote, source-wins or newer-wins")
+	mirrorScanInterval := mirrorCmd.Duration("scan-interval", mirroring.DefaultScanInterval, "How often all mirrored files are compared with the -to cluster to catch missed changes")
+	mirrorReportInterval := mirrorCmd.Duration("report-interval", 30*time.Second, "How often the mirroring lag is printed")
+	mirrorVerbose := mirrorCmd.Bool("v", false, "Show client log output")
+
+	newUserCmd := flag.NewFlagSet("new-user", flag.ExitOnError)
+	newUserName := newUserCmd.String("name", "", "Name of the user")
+	newUserGroups := newUserCmd.String("groups", "", "Comma separated groups of the user, the first owning the files the user creates")
+	newUserSuperuser := newUserCmd.Bool("superuser", false, "File permissions don't apply to the user, who may also give files to other users")
+
+	revokeTokenCmd := flag.NewFlagSet("revoke-token", flag.ExitOnError)
+	revokeTokenMaster := revokeTokenCmd.String("master", common.MasterAddress, "Master server address")
+	revokeTokenID := revokeTokenCmd.String("id", "", "Id of the delegation token to revoke, as printed when it was minted")
+
+	// Check for subcommand
+	if len(os.Args) < 2 {
+		printUsage()
+		os.Exit(1)
+	}
+
+	// Parsing subcommands
+	switch os.Args[1] {
+	case "report":
+		reportCmd.Parse(os.Args[2:])
+
+		dfsClient := newClient(*reportMaster)
+		defer dfsClient.Close()
+
+		if err := printReport(dfsClient); err != nil {
+			log.Fatalf("Report failed: %v", err)
+		}
+	case "unaccessed":
+		unaccessedCmd.Parse(os.Args[2:])
+
+		dfsClient := newClient(*unaccessedMaster)
+		defer dfsClient.Close()
+
+		if err := printUnaccessedFiles(dfsClient, *unaccessedDays, *unaccessedLimit); err != nil {
+			log.Fatalf("Listing unaccessed files failed: %v", err)
+		}
+	case "geo-status":
+		geoStatusCmd.Parse(os.Args[2:])
+
+		dfsClient := newClient(*geoStatusMaster)
+		defer dfsClient.Close()
+
+		if err := printGeoReplicationStatus(dfsClient); err != nil {
+			log.Fatalf("Geo-replication status failed: %v", err)
+		}
+	case "reclaim":
+		reclaimCmd.Parse(os.Args[2:])
+
+		dfsClient := newClient(*reclaimMaster)
+		defer dfsClient.Close()
+
+		reclaimed, err := dfsClient.ReclaimDeleted()
+		if err != nil {
+			log.Fatalf("Reclaim failed: %v", err)
+		}
+		fmt.Printf("Reclaimed %d deleted chunks\n", reclaimed)
+	case "export":
+		exportCmd.Parse(os.Args[2:])
+		if *exportOutput == "" {
+			log.Fatal("export requires -output")
+		}
+
+		dfsClient := newClient(*exportMaster, client.WithPriority(common.PriorityBatch))
+		defer dfsClient.Close()
+
+		if !*exportVerbose {
+			log.SetOutput(io.Discard)
+		}
+		err := exportFiles(dfsClient, *exportOutput, archiveSettings{
+			prefixes:   splitList(*exportPrefixes),
+			s3Endpoint: *exportS3Endpoint,
+			s3Region:   *exportS3Region,
+		})
+		log.SetOutput(os.Stderr)
+		if err != nil {
+			log.Fatalf("Export failed: %v", err)
+		}
+	case "import":
+		importCmd.Parse(os.Args[2:])
+		if *importInput == "" {
+			log.Fatal("import requires -input")
+		}
+
+		dfsClient := newClient(*importMaster, client.WithPriority(common.PriorityBatch))
+		defer dfsClient.Close()
+
+		if !*importVerbose {
+			log.SetOutput(io.Discard)
+		}
+		err := importFiles(dfsClient, *importInput, *importOverwrite, archiveSettings{
+			prefixes:   splitList(*importPrefixes),
+			s3Endpoint: *importS3Endpoint,
+			s3Region:   *importS3Region,
+		})
+		log.SetOutput(os.Stderr)
+		if err != nil {
+			log.Fatalf("Import failed: %v", err)
+		}
+	case "ingest":
+		ingestCmd.Parse(os.Args[2:])
+		if *ingestSourceFlag == "" {
+			log.Fatal("ingest requires -source")
+		}
+
+		source, err := openIngestSource(*ingestSourceFlag, archiveSettings{s3Endpoint: *ingestS3Endpoint, s3Region: *ingestS3Region}, *ingestHDFSUser)
+		if err != nil {
+			log.Fatalf("Invalid -source flag: %v", err)
+		}
+
+		var checkpoint *ingestCheckpoint
+		if *ingestCheckpointFile != "" {
+			checkpoint, err = openIngestCheckpoint(*ingestCheckpointFile)
+			if err != nil {
+				log.Fatalf("Ingest failed: %v", err)
+			}
+			defer checkpoint.Close()
+		}
+
+		dfsClient := newClient(*ingestMaster, client.WithPriority(common.PriorityBatch))
+		defer dfsClient.Close()
+
+		if !*ingestVerbose {
+			log.SetOutput(io.Discard)
+		}
+		err = ingestFiles(dfsClient, source, *ingestDestPrefix, *ingestParallel, checkpoint, *ingestOverwrite)
+		log.SetOutput(os.Stderr)
+		if err != nil {
+			log.Fatalf("Ingest failed: %v", err)
+		}
+	case "mirror":
+		mirrorCmd.Parse(os.Args[2:])
+		if *mirrorTo == "" {
+			log.Fatal("mirror requires -to")
+		}
+		conflictPolicy, err := mirroring.ParseConflictPolicy(*mirrorConflicts)
+		if err != nil {
+			log.Fatalf("Invalid -conflict-policy flag: %v", err)
+		}
+		var prefixes []string
+		if *mirrorPrefix != "" {
+			prefixes = []string{*mirrorPrefix}
+		}
+
+		source := newClient(*mirrorMaster, client.WithPriority(common.PriorityBatch))
+		defer source.Close()
+
+		var destinationOptions []client.ClientOption
+		if *mirrorToTokenFile != "" {
+			token, err := os.ReadFile(*mirrorToTokenFile)
+			if err != nil {
+				log.Fatalf("Invalid -to-token-file flag: %v", err)
+			}
+			destinationOptions = append(destinationOptions, client.WithToken(strings.TrimSpace(string(token))))
+		}
+		destination := newClient(*mirrorTo, append(destinationOptions, client.WithPriority(common.PriorityBatch))...)
+		defer destination.Close()
+
+		if !*mirrorVerbose {
+			log.SetOutput(io.Discard)
+		}
+		fmt.Printf("Mirroring %s to %s\n", masterAddress(*mirrorMaster), masterAddress(*mirrorTo))
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		runMirror(ctx, mirroring.New(source, destination, mirroring.Config{
+			Prefixes:       prefixes,
+			ConflictPolicy: conflictPolicy,
+			ScanInterval:   *mirrorScanInterval,
+			Workers:        *mirrorParallel,
+		}), *mirrorReportInterval)
+	case "new-user":
+		newUserCmd.Parse(os.Args[2:])
+		if *newUserName == "" {
+			log.Fatal("new-user requires -name")
+		}
+
+		if err := printNewUser(*newUserName, splitList(*newUserGroups), *newUserSuperuser); err != nil {
+			log.Fatalf("Creating user failed: %v", err)
+		}
+	case "revoke-token":
+		revokeTokenCmd.Parse(os.Args[2:])
+		if *revokeTokenID == "" {
+			log.Fatal("revoke-token requires -id")
+		}
+
+		dfsClient := newClient(*revokeTokenMaster)
+		defer dfsClient.Close()
+
+		if err := dfsClient.RevokeDelegationToken("", *revokeTokenID); err != nil {
+			log.Fatalf("Revoke failed: %v", err)
+		}
+		fmt.Printf("Delegation token %s revoked\n", *revokeTokenID)
+	default:
+		printUsage()
+		os.Exit(1)
+	}
+}
+
+// splitList splits a comma separated flag value, nil when empty
+func splitList(value string) []string {
+	if value == "" {
+		return nil
+	}
+
+	return strings.Split(value, ",")
+}
+
+// newClient creates a client of the master at the -master flag's address, authenticated with the token
+// in $DFS_TOKEN when set
+func newClient(address string, options ...client.ClientOption) *client.Client {
+	if token := strings.TrimSpace(os.Getenv(common.TokenEnv)); token != "" {
+		options = append(options, client.WithToken(token))
+	}
+
+	return client.NewClient(masterAddress(address), options...)
+}
+
+// printNewUser generates a token for a new user, printing the token to hand to the user and the entry
+// to add to the master's -identities-file, which only holds the token's hash
+func printNewUser(name string, groups []string, superuser bool) error {
+	if groups == nil {
+		groups = []string{}
+	}
+
+	token := rand.Text()
+	entry, err := json.Marshal(struct {
+		Name        string   `json:"name"`
+		Groups      []string `json:"groups"`
+		TokenSHA256 string   `json:"token_sha256"`
+		Superuser   bool     `json:"superuser,omitempty"`
+	}{name, groups, common.HashToken(token), superuser})
+	if err != nil {
+		return err
+	}
+
+	// the token alone goes to stdout so scripts can capture it
+	fmt.Fprintf(os.Stderr, "Token of %s, give it to the client with -token-file or $%s:\n", name, common.TokenEnv)
+	fmt.Println(token)
+	fmt.Fprintf(os.Stderr, "Add to the users of the master's -identities-file:\n%s\n", entry)
+
+	return nil
+}
+
+// masterAddress validates the -master flag
+func masterAddress(address string) string {
+	address, err := common.ParseAddress(address)
+	if err != nil {
+		log.Fatalf("Invalid -master flag: %v", err)
+	}
+
+	return address
+}
+
+// printReport prints the cluster summary, the chunk distribution across chunk servers and the replication histogram
+func printReport(dfsClient *client.Client) error {
+	stats, err := dfsClient.GetClusterStats()
+	if err != nil {
+		return err
+	}
+
+	distribution, err := dfsClient.GetChunkDistribution()
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Cluster:")
+	fmt.Println("----------------------------------------")
+	fmt.Printf("Capacity: %s, used %s, free %s\n", common.FormatBytes(float64(stats.CapacityBytes)),
+		common.FormatBytes(float64(stats.UsedBytes)), common.FormatBytes(float64(stats.FreeBytes)))
+	fmt.Printf("Files: %d, chunks: %d, deleted chunks awaiting reclamation: %d\n", stats.FileCount, stats.ChunkCount, stats.PendingReclamationChunks)
+	fmt.Printf("Chunk servers: %d live, %d dead\n\n", stats.LiveChunkServers, stats.DeadChunkServers)
+
+	fmt.Printf("Chunk servers (%d total):\n", len(distribution.Servers))
+	fmt.Println("----------------------------------------")
+	fmt.Printf("%-24s %-7s %-10s %-12s %-12s %-12s %s\n", "ADDRESS", "STATE", "CHUNKS", "BYTES", "CAPACITY", "FREE", "VERSION")
+	for _, server := range distribution.Servers {
+		state := server.State
+		if state == "" {
+			state = "-"
+		}
+		version := server.Version
+		if version == "" {
+			version = "-"
+		}
+		fmt.Printf("%-24s %-7s %-10d %-12s %-12s %-12s %s\n", server.Address, state, server.ChunkCount, common.FormatBytes(float64(server.Bytes)),
+			common.FormatBytes(float64(server.CapacityBytes)), common.FormatBytes(float64(server.FreeBytes)), version)
+	}
+
+	fmt.Printf("\nReplication (target %d replicas):\n", distribution.ReplicationFactor)
+	fmt.Println("----------------------------------------")
+	for _, bucket := range distribution.ReplicationHistogram {
+		fmt.Printf("%d replicas: %d chunks\n", bucket.Replicas, bucket.ChunkCount)
+	}
+	fmt.Printf("Under-replicated chunks: %d\n", stats.UnderReplicatedChunks)
+
+	return nil
+}
+
+// printUnaccessedFiles prints the largest files not read for at least days, as candidates for cleanup
+func printUnaccessedFiles(dfsClient *client.Client, days, limit int) error {
+	files, err := dfsClient.ListUnaccessedFiles(time.Duration(days)*24*time.Hour, limit)
+	if err != nil {
+		return err
+	}
+
+	if len(files) == 0 {
+		fmt.Printf("No files unaccessed for %d days\n", days)
+		return nil
+	}
+
+	var total int64
+	fmt.Printf("Largest files unaccessed for %d days:\n", days)
+	fmt.Println("----------------------------------------")
+	fmt.Printf("%-12s %-8s %-20s %s\n", "SIZE", "READS", "LAST ACCESSED", "NAME")
+	for _, file := range files {
+		lastAccessed := "never"
+		if file.LastAccessed != 0 {
+			lastAccessed = time.Unix(file.LastAccessed, 0).Format(time.DateTime)
+		}
+		fmt.Printf("%-12s %-8d %-20s %s\n", common.FormatBytes(float64(file.Filesize)), file.ReadCount, lastAccessed, file.Filename)
+		total += file.Filesize
+	}
+	fmt.Printf("Total: %s in %d files\n", common.FormatBytes(float64(total)), len(files))
+
+	return nil
+}
+
+// printGeoReplicationStatus prints the lag and counters of mirroring to the remote cluster
+func printGeoReplicationStatus(dfsClient *client.Client) error {
+	status, err := dfsClient.GetGeoReplicationStatus()
+	if err != nil {
+		return err
+	}
+
+	if !status.Enabled {
+		fmt.Println("Geo-replication is disabled, start the master with -geo-replicate-to to enable it")
+		return nil
+	}
+
+	prefixes := "all files"
+	if len(status.Prefixes) > 0 {
+		prefixes = strings.Join(status.Prefixes, ", ")
+	}
+	lastReplicated := "never"
+	if status.LastReplicatedAt != 0 {
+		lastReplicated = time.Unix(status.LastReplicatedAt, 0).Format(time.DateTime)
+	}
+
+	fmt.Println("Geo-replication:")
+	fmt.Println("----------------------------------------")
+	fmt.Printf("Remote master: %s\n", status.RemoteMaster)
+	fmt.Printf("Prefixes: %s\n", prefixes)
+	fmt.Printf("Conflict policy: %s\n", status.ConflictPolicy)
+	fmt.Printf("Lag: %s, %d files pending\n", time.Duration(status.LagSeconds)*time.Second, status.PendingFiles)
+	fmt.Printf("Last replicated: %s\n", lastReplicated)
+	fmt.Printf("Replicated: %d files, %s\n", status.ReplicatedFiles, common.FormatBytes(float64(status.ReplicatedBytes)))
+	fmt.Printf("Deleted: %d files\n", status.DeletedFiles)
+	fmt.Printf("Conflicts: %d, failures: %d\n", status.Conflicts, status.Failures)
+	if status.LastError != "" {
+		fmt.Printf("Last error: %s\n", status.LastError)
+	}
+
+	return nil
+}
+
+func printUsage() {
+	fmt.Println("Distributed File System Admin")
+	fmt.Println("\nUsage:")
+	fmt.Println("	dfsadmin report [-master <address>]")
+	fmt.Println("	dfsadmin unaccessed [-master <address>] [-days <days>] [-limit <count>]")
+	fmt.Println("	dfsadmin geo-status [-master <address>]")
+	fmt.Println("	dfsadmin reclaim [-master <address>]")
+	fmt.Println("	dfsadmin export -output <file.tar|-|s3://bucket/prefix> [-master <address>] [-prefix <prefixes>] [-s3-endpoint <url>] [-s3-region <region>]")
+	fmt.Println("	dfsadmin import -input <file.tar|-|s3://bucket/prefix> [-master <address>] [-prefix <prefixes>] [-overwrite] [-s3-endpoint <url>] [-s3-region <region>]")
+	fmt.Println("	dfsadmin new-user -name <user> [-groups <groups>] [-superuser]")
+	fmt.Println("	dfsadmin revoke-token -id <token_id> [-master <address>]")
+	fmt.Println("	dfsadmin mirror -to <address> [-master <address>] [-to-token-file <file>] [-prefix <prefix>] [-parallel <n>] [-conflict-policy <policy>] [-scan-interval <duration>] [-report-interval <duration>]")
+	fmt.Println("	dfsadmin ingest -source <s3://bucket/prefix|hdfs://namenode:port/path> [-master <address>] [-dest-prefix <prefix>] [-parallel <n>] [-checkpoint <file>] [-overwrite]")
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/harshvardha/distributed_file_system/common"
+	"github.com/harshvardha/distributed_file_system/mirroring"
+)
+
+// runMirror mirrors changes until ctx is cancelled, printing the lag every reportInterval
+func runMirror(ctx context.Context, mirror *mirroring.Mirror, reportInterval time.Duration) {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		mirror.Run(ctx)
+	}()
+
+	ticker := time.NewTicker(reportInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		case <-ticker.C:
+			fmt.Println(mirrorStatusLine(mirror.Status()))
+		}
+	}
+}
+
+// mirrorStatusLine summarizes the lag and counters of the mirror
+func mirrorStatusLine(status mirroring.Status) string {
+	lastMirrored := "never"
+	if !status.LastMirrored.IsZero() {
+		lastMirrored = status.LastMirrored.Format(time.DateTime)
+	}
+	line := fmt.Sprintf("%s lag %s, %d files pending, last mirrored %s, mirrored %d files (%s), deleted %d, conflicts %d, failures %d",
+		time.Now().Format(time.DateTime), status.Lag.Round(time.Second), status.PendingFiles, lastMirrored, status.MirroredFiles,
+		common.FormatBytes(float64(status.MirroredBytes)), status.DeletedFiles, status.Conflicts, status.Failures)
+	if status.LastError != "" {
+		line += ", last error: " + status.LastError
+	}
+
+	return line
+}
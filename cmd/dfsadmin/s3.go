@@ -0,0 +1,270 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// s3MaxObjectSize is the largest object a single PUT may upload
+const s3MaxObjectSize = 5 << 30
+
+// s3Client talks to an S3 compatible object store with path style urls and AWS signature version 4,
+// reading credentials from the standard AWS_* environment variables
+type s3Client struct {
+	endpoint     string // e.g. https://s3.us-east-1.amazonaws.com
+	region       string
+	bucket       string
+	accessKey    string
+	secretKey    string
+	sessionToken string
+	httpClient   *http.Client
+}
+
+// s3Object is an object in a bucket listing
+type s3Object struct {
+	Key  string `xml:"Key"`
+	Size int64  `xml:"Size"`
+}
+
+type s3ListResult struct {
+	Contents              []s3Object `xml:"Contents"`
+	IsTruncated           bool       `xml:"IsTruncated"`
+	NextContinuationToken string     `xml:"NextContinuationToken"`
+}
+
+// s3Error is the body of failed requests
+type s3Error struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+// newS3Client returns a client of bucket, endpoint defaulting to AWS in region
+func newS3Client(endpoint, region, bucket string) (*s3Client, error) {
+	accessKey, secretKey := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY")
+	if accessKey == "" || secretKey == "" {
+		return nil, fmt.Errorf("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set")
+	}
+
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", region)
+	}
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "https://" + endpoint
+	}
+
+	return &s3Client{
+		endpoint:     strings.TrimSuffix(endpoint, "/"),
+		region:       region,
+		bucket:       bucket,
+		accessKey:    accessKey,
+		secretKey:    secretKey,
+		sessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+		httpClient:   &http.Client{},
+	}, nil
+}
+
+// put uploads size bytes read from body as the object key, with user metadata
+func (c *s3Client) put(key string, body io.Reader, size int64, metadata map[string]string) error {
+	if size > s3MaxObjectSize {
+		return fmt.Errorf("%s is %d bytes, larger than the 5GB objects a single S3 upload may create", key, size)
+	}
+
+	// a non-nil body with a zero length would be sent chunked
+	if size == 0 {
+		body = http.NoBody
+	}
+
+	headers := make(http.Header)
+	for name, value := range metadata {
+		headers.Set("X-Amz-Meta-"+name, value)
+	}
+
+	resp, err := c.do(http.MethodPut, key, nil, headers, body, size)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+// get opens the object key, returning its contents and user metadata
+func (c *s3Client) get(key string) (io.ReadCloser, map[string]string, error) {
+	resp, err := c.do(http.MethodGet, key, nil, nil, nil, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	metadata := make(map[string]string)
+	for name, values := range resp.Header {
+		if name, ok := strings.CutPrefix(strings.ToLower(name), "x-amz-meta-"); ok && len(values) > 0 {
+			metadata[name] = values[0]
+		}
+	}
+
+	return resp.Body, metadata, nil
+}
+
+// list returns every object whose key starts with prefix
+func (c *s3Client) list(prefix string) ([]s3Object, error) {
+	var objects []s3Object
+	query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+	for {
+		resp, err := c.do(http.MethodGet, "", query, nil, nil, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		result := s3ListResult{}
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode bucket listing: %v", err)
+		}
+
+		objects = append(objects, result.Contents...)
+		if !result.IsTruncated {
+			return objects, nil
+		}
+		query.Set("continuation-token", result.NextContinuationToken)
+	}
+}
+
+// do sends a signed request for key, or the bucket itself when key is empty
+func (c *s3Client) do(method, key string, query url.Values, headers http.Header, body io.Reader, size int64) (*http.Response, error) {
+	path := "/" + c.bucket
+	if key != "" {
+		path += "/" + key
+	}
+
+	req, err := http.NewRequest(method, c.endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+	req.URL.Path = path
+	req.URL.RawPath = s3Escape(path, false)
+	req.URL.RawQuery = s3CanonicalQuery(query)
+	req.ContentLength = size
+	for name, values := range headers {
+		req.Header[name] = values
+	}
+	c.sign(req, time.Now().UTC())
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		failure := s3Error{}
+		data, _ := io.ReadAll(resp.Body)
+		if xml.Unmarshal(data, &failure) != nil || failure.Message == "" {
+			failure.Message = strings.TrimSpace(string(data))
+		}
+		return nil, fmt.Errorf("s3 %s %s: %s %s", method, path, resp.Status, failure.Message)
+	}
+
+	return resp, nil
+}
+
+// sign adds the AWS signature version 4 headers to req, leaving the payload unsigned so it can be streamed
+func (c *s3Client) sign(req *http.Request, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	req.Header.Set("Host", req.URL.Host)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", "UNSIGNED-PAYLOAD")
+	if c.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", c.sessionToken)
+	}
+
+	names := make([]string, 0, len(req.Header))
+	canonicalHeaders := make(map[string]string, len(req.Header))
+	for name, values := range req.Header {
+		name = strings.ToLower(name)
+		names = append(names, name)
+		canonicalHeaders[name] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	slices.Sort(names)
+
+	var headerLines strings.Builder
+	for _, name := range names {
+		headerLines.WriteString(name + ":" + canonicalHeaders[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.RawPath,
+		req.URL.RawQuery,
+		headerLines.String(),
+		signedHeaders,
+		"UNSIGNED-PAYLOAD",
+	}, "\n")
+
+	scope := date + "/" + c.region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hexSHA256(canonicalRequest)
+
+	key := hmacSHA256([]byte("AWS4"+c.secretKey), date)
+	for _, part := range []string{c.region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		c.accessKey, scope, signedHeaders, signature))
+	req.Header.Del("Host") // net/http sends req.Host
+}
+
+// s3Escape percent-encodes everything but unreserved characters, and slashes unless escapeSlash is set
+func s3Escape(value string, escapeSlash bool) string {
+	var escaped strings.Builder
+	for _, b := range []byte(value) {
+		switch {
+		case 'a' <= b && b <= 'z', 'A' <= b && b <= 'Z', '0' <= b && b <= '9', b == '-', b == '_', b == '.', b == '~':
+			escaped.WriteByte(b)
+		case b == '/' && !escapeSlash:
+			escaped.WriteByte(b)
+		default:
+			fmt.Fprintf(&escaped, "%%%02X", b)
+		}
+	}
+
+	return escaped.String()
+}
+
+// s3CanonicalQuery encodes query parameters sorted by name, as signature version 4 expects
+func s3CanonicalQuery(query url.Values) string {
+	pairs := make([]string, 0, len(query))
+	for _, name := range slices.Sorted(maps.Keys(query)) {
+		for _, value := range query[name] {
+			pairs = append(pairs, s3Escape(name, true)+"="+s3Escape(value, true))
+		}
+	}
+
+	return strings.Join(pairs, "&")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func hexSHA256(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// webHDFSClient reads an HDFS cluster through the WebHDFS REST api of its namenode
+type webHDFSClient struct {
+	endpoint   string // namenode http address, e.g. http://namenode:9870
+	user       string // user.name of requests, empty for the namenode's default
+	httpClient *http.Client
+}
+
+// webHDFSFileStatus is an entry of a directory listing
+type webHDFSFileStatus struct {
+	PathSuffix string `json:"pathSuffix"`
+	Type       string `json:"type"` // FILE, DIRECTORY or SYMLINK
+	Length     int64  `json:"length"`
+}
+
+type webHDFSListing struct {
+	FileStatuses struct {
+		FileStatus []webHDFSFileStatus `json:"FileStatus"`
+	} `json:"FileStatuses"`
+}
+
+// webHDFSError is the body of failed requests
+type webHDFSError struct {
+	RemoteException struct {
+		Exception string `json:"exception"`
+		Message   string `json:"message"`
+	} `json:"RemoteException"`
+}
+
+func newWebHDFSClient(endpoint, user string) *webHDFSClient {
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "http://" + endpoint
+	}
+
+	return &webHDFSClient{
+		endpoint:   strings.TrimSuffix(endpoint, "/"),
+		user:       user,
+		httpClient: &http.Client{},
+	}
+}
+
+// walk calls fn with the path and length of every file under root, descending into subdirectories
+func (c *webHDFSClient) walk(root string, fn func(filePath string, length int64)) error {
+	resp, err := c.do(root, "LISTSTATUS")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	listing := webHDFSListing{}
+	if err := json.NewDecoder(resp.Body).Decode(&listing); err != nil {
+		return fmt.Errorf("failed to decode listing of %s: %v", root, err)
+	}
+
+	for _, status := range listing.FileStatuses.FileStatus {
+		// listing a file returns the file itself with an empty suffix
+		entryPath := path.Join(root, status.PathSuffix)
+
+		switch status.Type {
+		case "FILE":
+			fn(entryPath, status.Length)
+		case "DIRECTORY":
+			if err := c.walk(entryPath, fn); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// open reads a file, following the namenode's redirect to a datanode holding it
+func (c *webHDFSClient) open(filePath string) (io.ReadCloser, error) {
+	resp, err := c.do(filePath, "OPEN")
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Body, nil
+}
+
+// do sends a GET request for op on filePath
+func (c *webHDFSClient) do(filePath, op string) (*http.Response, error) {
+	query := url.Values{"op": {op}}
+	if c.user != "" {
+		query.Set("user.name", c.user)
+	}
+	target := c.endpoint + (&url.URL{Path: "/webhdfs/v1" + filePath}).EscapedPath() + "?" + query.Encode()
+
+	resp, err := c.httpClient.Get(target)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		failure := webHDFSError{}
+		data, _ := io.ReadAll(resp.Body)
+		message := strings.TrimSpace(string(data))
+		if json.Unmarshal(data, &failure) == nil && failure.RemoteException.Message != "" {
+			message = failure.RemoteException.Message
+		}
+		return nil, fmt.Errorf("webhdfs %s %s: %s %s", op, filePath, resp.Status, message)
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/harshvardha/distributed_file_system/chunkserver"
+)
+
+// devCluster is the chunk servers a -dev master runs in its own process
+type devCluster struct {
+	servers       []*chunkserver.Server
+	storageDir    string
+	removeStorage bool // the storage is a temporary directory
+}
+
+// startDevCluster starts count chunk servers with config on ephemeral localhost ports, storing their chunks
+// under storageDir, or a temporary directory removed by stop when storageDir is empty
+func startDevCluster(masterAddress string, count int, storageDir string, config chunkserver.Config) (*devCluster, error) {
+	dev := &devCluster{storageDir: storageDir}
+	if storageDir == "" {
+		dir, err := os.MkdirTemp("", "dfs-dev-")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create storage directory: %v", err)
+		}
+		dev.storageDir = dir
+		dev.removeStorage = true
+	}
+
+	for i := range count {
+		listener, err := net.Listen("tcp", "localhost:0")
+		if err != nil {
+			dev.stop()
+			return nil, fmt.Errorf("failed to listen: %v", err)
+		}
+		address := net.JoinHostPort("localhost", strconv.Itoa(listener.Addr().(*net.TCPAddr).Port))
+
+		storagePath := filepath.Join(dev.storageDir, fmt.Sprintf("chunkserver%d", i+1))
+		server, err := chunkserver.NewServer(address, []string{storagePath}, masterAddress, config)
+		if err != nil {
+			listener.Close()
+			dev.stop()
+			return nil, fmt.Errorf("failed to create chunk server: %v", err)
+		}
+		dev.servers = append(dev.servers, server)
+
+		go func() {
+			if err := server.Serve(listener); err != nil {
+				log.Printf("Warning: dev chunk server %s failed: %v", address, err)
+			}
+		}()
+	}
+
+	return dev, nil
+}
+
+// stop stops the chunk servers and removes their temporary storage
+func (d *devCluster) stop() {
+	for _, server := range d.servers {
+		server.Stop()
+	}
+
+	if d.removeStorage {
+		if err := os.RemoveAll(d.storageDir); err != nil {
+			log.Printf("Warning: failed to remove dev storage %s: %v", d.storageDir, err)
+		}
+	}
+}
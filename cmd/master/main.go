@@ -1,17 +1,331 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"log"
+	"net"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
+	"github.com/harshvardha/distributed_file_system/chunkserver"
 	"github.com/harshvardha/distributed_file_system/common"
 	"github.com/harshvardha/distributed_file_system/master"
+	"github.com/harshvardha/distributed_file_system/mirroring"
 )
 
 func main() {
+	port := flag.String("port", "8000", "Port to listen on")
+	bind := flag.String("bind", "", "Address to listen on, e.g. 0.0.0.0:8000 (defaults to localhost:<port>)")
+	httpAddress := flag.String("http", "", "Address for the /healthz, /readyz and /metrics http endpoints, e.g. :8080 (disabled when empty)")
+	metadataBackend := flag.String("metadata-backend", "memory", "Where the namespace is stored: memory, bolt to keep it on disk across restarts, or etcd")
+	metadataPath := flag.String("metadata-path", "master.db", "Metadata file when -metadata-backend=bolt")
+	etcdEndpoints := flag.String("etcd-endpoints", "http://localhost:2379", "Comma separated etcd client urls when -metadata-backend=etcd")
+	etcdPrefix := flag.String("etcd-prefix", "/dfs/", "Prefix of the etcd keys holding the namespace when -metadata-backend=etcd")
+	etcdUsername := flag.String("etcd-username", "", "User the master authenticates to etcd as, with the password in -etcd-password-file (no authentication when empty)")
+	etcdPasswordFile := flag.String("etcd-password-file", "", "File holding the password of -etcd-username")
+	etcdCAFile := flag.String("etcd-ca-file", "", "CA certificate etcd's server certificates are verified with, enabling tls")
+	etcdCertFile := flag.String("etcd-cert-file", "", "Client certificate presented to etcd, enabling tls")
+	etcdKeyFile := flag.String("etcd-key-file", "", "Key of -etcd-cert-file")
+	inlineThreshold := flag.Int64("inline-threshold", 4096, "Files of at most this many bytes are stored in the master's metadata instead of chunks, at most 65536, 0 disables it")
+	archiveAfter := flag.Duration("archive-after", 0, "Files not read for this long are compressed onto -archive-tier chunk servers and recalled when read again, e.g. 2160h (0 disables archival)")
+	archivePrefixes := flag.String("archive-prefixes", "", "Comma separated prefixes of the files -archive-after applies to, all files when empty")
+	archiveTier := flag.String("archive-tier", master.DefaultArchiveTier, "Tier of the chunk servers holding archived files, see the chunk servers' -tier")
+	keepVersions := flag.Int("keep-versions", 0, "Previous versions kept when a file is overwritten, listable and downloadable by generation")
+	versionMaxAge := flag.Duration("version-max-age", 7*24*time.Hour, "Previous versions are dropped this long after being replaced, 0 keeps them until pushed out by -keep-versions")
+	reclaimDelay := flag.Duration("reclaim-delay", 24*time.Hour, "How long replicas of deleted files and versions are kept before being deleted, 0 deletes them right away")
+	geoRemote := flag.String("geo-replicate-to", "", "Master of a remote cluster to mirror files to asynchronously (disabled when empty)")
+	geoPrefixes := flag.String("geo-replicate-prefixes", "", "Comma separated prefixes of the files mirrored to -geo-replicate-to, all files when empty")
+	geoConflicts := flag.String("geo-conflict-policy", "keep-remote", "What happens to remote files written on the remote cluster: keep-remote, source-wins or newer-wins")
+	maxConcurrentRequests := flag.Int("max-concurrent-requests", 256, "Client requests served at once, more wait with interactive requests served before batch ones (0 for no limit)")
+	debugServices := flag.Bool("grpc-debug", false, "Serve grpc reflection and channelz, for inspecting the master with tools like grpcurl")
+	slowRequests := flag.Duration("slow-request-threshold", common.DefaultSlowRequestThreshold, "Log requests taking longer than this with their parameters and caller (0 disables it)")
+	logRequests := flag.Bool("log-requests", false, "Log every request with its caller, duration and status")
+	heartbeatTimeout := flag.Duration("heartbeat-timeout", master.DefaultDeadServerTimeout, "How long a chunk server may go without a heartbeat before it is considered dead and its chunks repaired")
+	tunablesFile := flag.String("tunables-file", "", "File of flag=value lines overriding the flags that can change at runtime, read again on SIGHUP: "+strings.Join(masterTunables, ", "))
+	chunkTokenKeyFile := flag.String("chunk-token-key-file", "", "File holding the key chunk access tokens are signed with, shared with the chunk servers, which authenticate to the master with it (defaults to $DFS_CHUNK_TOKEN_KEY, no tokens without a key)")
+	chunkTokenTTL := flag.Duration("chunk-token-ttl", common.DefaultChunkTokenTTL, "How long chunk access tokens handed out with chunk locations stay valid")
+	presignKeyFile := flag.String("presign-key-file", "", "File holding the key presigned download urls are signed with (defaults to $DFS_PRESIGN_KEY, presigning is disabled without a key)")
+	presignBaseURL := flag.String("presign-base-url", "", "Scheme and host presigned urls point at, e.g. https://dfs.example.com (defaults to http://<-http address>)")
+	identitiesFile := flag.String("identities-file", "", "JSON file of the users and groups allowed to use the cluster, see dfsadmin new-user (every caller is anonymous when empty)")
+	allowAnonymous := flag.Bool("allow-anonymous", false, "With -identities-file, serve requests without a token as user anonymous instead of rejecting them")
+	geoRemoteTokenFile := flag.String("geo-remote-token-file", "", "File holding the user token -geo-replicate-to authenticates the mirroring with, for remote clusters with -identities-file")
+	faultSpec := flag.String("faults", os.Getenv(common.FaultsEnv), "Failures to inject for testing recovery, e.g. delay=200ms,error:Heartbeat=0.1,drop-report=0.5 (defaults to $DFS_FAULTS)")
+	dev := flag.Bool("dev", false, "Also run chunk servers in this process with temporary storage, a whole cluster in one command for trying the DFS out")
+	devChunkServers := flag.Int("dev-chunkservers", common.ReplicationFactor, "Chunk servers started by -dev")
+	devStorage := flag.String("dev-storage", "", "Directory holding the chunks of -dev chunk servers, a temporary directory removed on exit when empty")
+	webhookURLs := flag.String("webhook-urls", "", "Comma separated urls file events are posted to as JSON, e.g. for ETL schedulers or alerting (disabled when empty)")
+	webhookPrefixes := flag.String("webhook-prefixes", "", "Comma separated prefixes of the files whose events are posted to -webhook-urls, all files when empty")
+	webhookSecretFile := flag.String("webhook-secret-file", "", "File holding the key webhook request bodies are signed with, the signature is sent in the "+master.WebhookSignatureHeader+" header (unsigned when empty)")
+	geoScanInterval := flag.Duration("geo-scan-interval", 5*time.Minute, "How often all mirrored files are compared with the remote cluster to catch missed changes")
+	connTuning := common.DefaultConnTuning()
+	connTuning.RegisterFlags(flag.CommandLine)
+	flag.Parse()
+
+	address := *bind
+	if address == "" {
+		address = net.JoinHostPort("localhost", *port)
+	}
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		log.Fatalf("Invalid -bind flag %q: %v", address, err)
+	}
+
 	log.Println("Starting Distributed File System Master Server...")
+	log.Printf("Listening on: %s", address)
+	log.Printf("Metadata backend: %s", *metadataBackend)
+	log.Printf("Version: %s (commit %s)", common.Version, common.BuildCommit())
+
+	backend, err := master.ParseMetadataBackend(*metadataBackend)
+	if err != nil {
+		log.Fatalf("Invalid -metadata-backend flag: %v", err)
+	}
+
+	etcd := master.EtcdConfig{
+		Endpoints: strings.Split(*etcdEndpoints, ","),
+		Prefix:    *etcdPrefix,
+		Username:  *etcdUsername,
+		CAFile:    *etcdCAFile,
+		CertFile:  *etcdCertFile,
+		KeyFile:   *etcdKeyFile,
+	}
+	if *etcdPasswordFile != "" {
+		password, err := os.ReadFile(*etcdPasswordFile)
+		if err != nil {
+			log.Fatalf("Invalid -etcd-password-file flag: %v", err)
+		}
+		etcd.Password = strings.TrimSpace(string(password))
+	}
+
+	geoReplication := master.GeoReplicationConfig{ScanInterval: *geoScanInterval}
+	if *geoRemote != "" {
+		geoReplication.RemoteMaster, err = common.ParseAddress(*geoRemote)
+		if err != nil {
+			log.Fatalf("Invalid -geo-replicate-to flag: %v", err)
+		}
+		geoReplication.ConflictPolicy, err = mirroring.ParseConflictPolicy(*geoConflicts)
+		if err != nil {
+			log.Fatalf("Invalid -geo-conflict-policy flag: %v", err)
+		}
+		if *geoPrefixes != "" {
+			geoReplication.Prefixes = strings.Split(*geoPrefixes, ",")
+		}
+		if *geoRemoteTokenFile != "" {
+			token, err := os.ReadFile(*geoRemoteTokenFile)
+			if err != nil {
+				log.Fatalf("Invalid -geo-remote-token-file flag: %v", err)
+			}
+			geoReplication.RemoteToken = strings.TrimSpace(string(token))
+		}
+	}
+
+	var webhooks master.WebhookConfig
+	if *webhookURLs != "" {
+		for _, rawURL := range strings.Split(*webhookURLs, ",") {
+			parsed, err := url.Parse(rawURL)
+			if err != nil || parsed.Scheme != "http" && parsed.Scheme != "https" || parsed.Host == "" {
+				log.Fatalf("Invalid -webhook-urls flag: %q is not an http or https url", rawURL)
+			}
+			webhooks.URLs = append(webhooks.URLs, rawURL)
+		}
+		if *webhookPrefixes != "" {
+			webhooks.Prefixes = strings.Split(*webhookPrefixes, ",")
+		}
+		if *webhookSecretFile != "" {
+			webhooks.Secret, err = common.LoadKey(*webhookSecretFile, "")
+			if err != nil {
+				log.Fatalf("Invalid -webhook-secret-file flag: %v", err)
+			}
+		}
+	}
 
-	server := master.NewServer(common.MasterAddress)
-	if err := server.Start(); err != nil {
+	archival := master.ArchivalPolicy{After: *archiveAfter, Tier: *archiveTier}
+	if *archivePrefixes != "" {
+		archival.Prefixes = strings.Split(*archivePrefixes, ",")
+	}
+
+	faults, err := common.ParseFaults(*faultSpec)
+	if err != nil {
+		log.Fatalf("Invalid -faults flag: %v", err)
+	}
+	if faults != nil {
+		log.Printf("Warning: fault injection enabled: %s", faults)
+	}
+
+	chunkTokenKey, err := common.LoadKey(*chunkTokenKeyFile, common.ChunkTokenKeyEnv)
+	if err != nil {
+		log.Fatalf("Invalid -chunk-token-key-file flag: %v", err)
+	}
+	chunkTokens, err := common.NewChunkTokens(chunkTokenKey, *chunkTokenTTL)
+	if err != nil {
+		log.Fatalf("Invalid chunk token key: %v", err)
+	}
+	if chunkTokens != nil {
+		log.Printf("Chunk access tokens: valid for %s", *chunkTokenTTL)
+	}
+
+	presignKey, err := common.LoadKey(*presignKeyFile, master.PresignKeyEnv)
+	if err != nil {
+		log.Fatalf("Invalid -presign-key-file flag: %v", err)
+	}
+	if len(presignKey) > 0 {
+		if *httpAddress == "" {
+			log.Fatalf("Presigned urls are served over http, set -http")
+		}
+		if *presignBaseURL == "" {
+			*presignBaseURL = "http://" + localHTTPAddress(*httpAddress)
+		}
+		log.Printf("Presigned urls: %s", *presignBaseURL)
+	}
+
+	var identities master.IdentityProvider
+	if *identitiesFile != "" {
+		static, err := master.LoadStaticIdentities(*identitiesFile)
+		if err != nil {
+			log.Fatalf("Invalid -identities-file flag: %v", err)
+		}
+		// without a key anyone could join as a chunk server, or read and overwrite chunks bypassing file permissions
+		if chunkTokens == nil {
+			log.Fatalf("-identities-file requires a chunk token key, see -chunk-token-key-file")
+		}
+		identities = static
+		log.Printf("Identities: %s, anonymous requests allowed: %t", *identitiesFile, *allowAnonymous)
+	} else if *allowAnonymous {
+		log.Printf("Warning: -allow-anonymous has no effect without -identities-file")
+	}
+
+	server, err := master.NewServer(address, master.Config{
+		MetadataBackend: backend,
+		MetadataPath:    *metadataPath,
+		Etcd:            etcd,
+		KeepVersions:    *keepVersions,
+		InlineThreshold: *inlineThreshold,
+		Archival:        archival,
+		VersionMaxAge:   *versionMaxAge,
+		ReclaimDelay:    *reclaimDelay,
+		Conn:            connTuning,
+		GeoReplication:  geoReplication,
+		Webhooks:        webhooks,
+		Faults:          faults,
+
+		MaxConcurrentRequests: *maxConcurrentRequests,
+		DebugServices:         *debugServices,
+		SlowRequestThreshold:  *slowRequests,
+		LogRequests:           *logRequests,
+		HeartbeatTimeout:      *heartbeatTimeout,
+		ChunkTokens:           chunkTokens,
+		PresignKey:            presignKey,
+		PresignBaseURL:        *presignBaseURL,
+		Identities:            identities,
+		AllowAnonymous:        *allowAnonymous,
+	})
+	if err != nil {
+		log.Fatalf("Failed to create master server: %v", err)
+	}
+
+	if *tunablesFile != "" {
+		// the file overrides the command line, so a line removed from it restores the flag's value on reload
+		flags := server.Tunables()
+		reload := func() error {
+			tunables, err := loadMasterTunables(*tunablesFile, flags)
+			if err != nil {
+				return err
+			}
+			return server.SetTunables(tunables)
+		}
+		if err := reload(); err != nil {
+			log.Fatalf("Invalid -tunables-file flag: %v", err)
+		}
+
+		common.OnHangup(func() {
+			if err := reload(); err != nil {
+				log.Printf("Warning: failed to reload tunables, keeping the current ones: %v", err)
+				return
+			}
+			log.Printf("Reloaded tunables from %s", *tunablesFile)
+		})
+	}
+
+	if *httpAddress != "" {
+		go func() {
+			if err := server.StartHTTP(*httpAddress); err != nil {
+				log.Fatalf("Master http server failed: %v", err)
+			}
+		}()
+	}
+
+	if !*dev {
+		if err := server.Start(); err != nil {
+			log.Fatalf("Master server failed: %v", err)
+		}
+		return
+	}
+
+	// listening before the chunk servers start so they can register right away
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		log.Fatalf("Failed to listen: %v", err)
+	}
+
+	devCluster, err := startDevCluster(address, *devChunkServers, *devStorage, chunkserver.Config{
+		SyncMode:      chunkserver.SyncNone,
+		Conn:          connTuning,
+		Faults:        faults,
+		DebugServices: *debugServices,
+		ChunkTokens:   chunkTokens,
+
+		SlowRequestThreshold: *slowRequests,
+		LogRequests:          *logRequests,
+	})
+	if err != nil {
+		log.Fatalf("Failed to start dev chunk servers: %v", err)
+	}
+	log.Printf("Dev mode: %d chunk servers storing chunks in %s, try: go run cmd/client/main.go upload -file <file> -name <name> -master %s",
+		*devChunkServers, devCluster.storageDir, address)
+
+	// stopping on Ctrl-C so the temporary storage is removed
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		log.Println("Stopping dev cluster...")
+		devCluster.stop()
+		server.Stop()
+	}()
+
+	if err := server.Serve(listener); err != nil {
+		devCluster.stop()
 		log.Fatalf("Master server failed: %v", err)
 	}
 }
+
+// localHTTPAddress returns the address local clients reach an http listen address at, e.g. localhost:8080 for :8080
+func localHTTPAddress(address string) string {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil || host != "" && host != "0.0.0.0" && host != "::" {
+		return address
+	}
+
+	return net.JoinHostPort("localhost", port)
+}
+
+// masterTunables are the flags a -tunables-file may set
+var masterTunables = []string{"heartbeat-timeout", "max-concurrent-requests", "slow-request-threshold", "log-requests", "keep-versions", "version-max-age", "reclaim-delay"}
+
+// loadMasterTunables returns tunables with the flags set in a tunables file changed
+func loadMasterTunables(path string, tunables master.Tunables) (master.Tunables, error) {
+	set := flag.NewFlagSet("tunables", flag.ContinueOnError)
+	set.DurationVar(&tunables.HeartbeatTimeout, "heartbeat-timeout", tunables.HeartbeatTimeout, "")
+	set.IntVar(&tunables.MaxConcurrentRequests, "max-concurrent-requests", tunables.MaxConcurrentRequests, "")
+	set.DurationVar(&tunables.SlowRequestThreshold, "slow-request-threshold", tunables.SlowRequestThreshold, "")
+	set.BoolVar(&tunables.LogRequests, "log-requests", tunables.LogRequests, "")
+	set.IntVar(&tunables.KeepVersions, "keep-versions", tunables.KeepVersions, "")
+	set.DurationVar(&tunables.VersionMaxAge, "version-max-age", tunables.VersionMaxAge, "")
+	set.DurationVar(&tunables.ReclaimDelay, "reclaim-delay", tunables.ReclaimDelay, "")
+
+	err := common.ParseFlagFile(path, set)
+	return tunables, err
+}
@@ -0,0 +1,86 @@
+package common
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+// ParseAddress validates a host:port address of a master or chunk server and returns it in canonical
+// form, so the same server is always recorded under the same address. Hosts are DNS names, which are
+// lowercased, IPv4 literals or bracketed IPv6 literals, which are compressed, e.g. [2001:db8::1]:9001
+func ParseAddress(address string) (string, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		if strings.Count(address, ":") > 1 && !strings.HasPrefix(address, "[") {
+			return "", fmt.Errorf("invalid address %q: IPv6 addresses must be bracketed, e.g. [::1]:9001", address)
+		}
+		return "", fmt.Errorf("invalid address %q: %v", address, err)
+	}
+
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		return "", fmt.Errorf("invalid address %q: port must be a number from 1 to 65535", address)
+	}
+
+	host, err = canonicalHost(host)
+	if err != nil {
+		return "", fmt.Errorf("invalid address %q: %v", address, err)
+	}
+
+	return net.JoinHostPort(host, port), nil
+}
+
+// CanonicalHost returns a host name or IP literal in the form ParseAddress records it, brackets
+// around IPv6 literals are optional. It returns host unchanged if it isn't a valid host
+func CanonicalHost(host string) string {
+	canonical, err := canonicalHost(strings.TrimSuffix(strings.TrimPrefix(host, "["), "]"))
+	if err != nil {
+		return host
+	}
+
+	return canonical
+}
+
+// canonicalHost validates an unbracketed host
+func canonicalHost(host string) (string, error) {
+	if host == "" {
+		return "", fmt.Errorf("missing host")
+	}
+
+	if ip, err := netip.ParseAddr(host); err == nil {
+		if ip.Zone() != "" {
+			return "", fmt.Errorf("IPv6 zones are not supported, other servers can't reach a link-local address")
+		}
+		return ip.Unmap().String(), nil
+	}
+
+	if !isDNSName(host) {
+		return "", fmt.Errorf("%q is neither an IP address nor a valid DNS name", host)
+	}
+
+	return strings.ToLower(strings.TrimSuffix(host, ".")), nil
+}
+
+// isDNSName reports whether name is a syntactically valid DNS name. Underscores are accepted
+// since container platforms use them in service names
+func isDNSName(name string) bool {
+	name = strings.TrimSuffix(name, ".")
+	if name == "" || len(name) > 253 {
+		return false
+	}
+
+	for _, label := range strings.Split(name, ".") {
+		if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, c := range label {
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+				return false
+			}
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,66 @@
+package common
+
+import (
+	"runtime/debug"
+	"sync"
+)
+
+// Commit is the git commit the dfs binaries were built from, set at build time with
+// -ldflags "-X github.com/harshvardha/distributed_file_system/common.Commit=$(git rev-parse HEAD)".
+// When unset, BuildCommit falls back to the version control information go build records
+var Commit = ""
+
+// ProtocolFeatures lists the optional protocol features the servers of this build support, so clients
+// and operators of a cluster running mixed versions can tell which servers lack one. A feature is only
+// ever added, never renamed
+var ProtocolFeatures = []string{
+	"checksums",         // chunk reads and writes carry CRC-32C checksums
+	"read-stream",       // ReadChunkStream
+	"record-append",     // PrepareAppend, RecordAppend and CompleteAppend
+	"conditional-write", // if_generation_match on uploads, copies, renames and deletes
+	"file-versions",     // ListFileVersions and downloading previous generations
+	"clone",             // CloneFile sharing chunks copy-on-write
+	"list-stream",       // ListFilesStream
+	"request-priority",  // the dfs-priority metadata key
+	"chunk-tokens",      // chunk requests carry access tokens signed by the master
+	"symlinks",          // SymlinkFile and symlink_target in file info
+	"hard-links",        // LinkFile and hard_links in file info
+	"inline-files",      // data of small uploads and downloads sent to and from the master
+	"storage-tiers",     // storage_tier on uploads and file attributes, tier in heartbeats
+	"archival",          // archived_size in file info, archived files recalled by RecallChunks on download
+	"storage-classes",   // storage_class on uploads and file attributes
+	"streaming-upload",  // AllocateChunk and the size of streaming uploads sent with CompleteUpload
+	"zstd",              // chunk data compressed with zstd on the wire, see ZstdCompressor
+}
+
+// buildCommit caches the commit read from the build information
+var buildCommit = sync.OnceValue(func() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+
+	revision, modified := "", false
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			revision = setting.Value
+		case "vcs.modified":
+			modified = setting.Value == "true"
+		}
+	}
+	if revision != "" && modified {
+		revision += "-dirty"
+	}
+
+	return revision
+})
+
+// BuildCommit returns the git commit the running binary was built from, empty if unknown
+func BuildCommit() string {
+	if Commit != "" {
+		return Commit
+	}
+
+	return buildCommit()
+}
@@ -0,0 +1,88 @@
+package common
+
+import (
+	"io"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	"google.golang.org/grpc/encoding"
+)
+
+// ZstdCompressor is the grpc name of the zstd compressor registered by this package, so the clients and
+// chunk servers importing it can compress chunk data with zstd on the wire
+const ZstdCompressor = "zstd"
+
+func init() {
+	encoding.RegisterCompressor(&zstdCompressor{})
+}
+
+// zstdCompressor compresses grpc messages with zstd, reusing encoders and decoders across messages
+type zstdCompressor struct {
+	encoders sync.Pool
+	decoders sync.Pool
+}
+
+func (c *zstdCompressor) Name() string {
+	return ZstdCompressor
+}
+
+func (c *zstdCompressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	if encoder, ok := c.encoders.Get().(*zstd.Encoder); ok {
+		encoder.Reset(w)
+		return &zstdWriter{Encoder: encoder, pool: &c.encoders}, nil
+	}
+
+	// chunk data is compressed on the request's goroutine, a message at a time
+	encoder, err := zstd.NewWriter(w, zstd.WithEncoderConcurrency(1), zstd.WithEncoderLevel(zstd.SpeedFastest))
+	if err != nil {
+		return nil, err
+	}
+	return &zstdWriter{Encoder: encoder, pool: &c.encoders}, nil
+}
+
+func (c *zstdCompressor) Decompress(r io.Reader) (io.Reader, error) {
+	if decoder, ok := c.decoders.Get().(*zstd.Decoder); ok {
+		if err := decoder.Reset(r); err != nil {
+			c.decoders.Put(decoder)
+			return nil, err
+		}
+		return &zstdReader{Decoder: decoder, pool: &c.decoders}, nil
+	}
+
+	decoder, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1), zstd.WithDecoderMaxMemory(uint64(ChunkSize)*2))
+	if err != nil {
+		return nil, err
+	}
+	return &zstdReader{Decoder: decoder, pool: &c.decoders}, nil
+}
+
+// zstdWriter returns its encoder to the pool once the message is compressed
+type zstdWriter struct {
+	*zstd.Encoder
+	pool *sync.Pool
+}
+
+func (w *zstdWriter) Close() error {
+	err := w.Encoder.Close()
+	w.pool.Put(w.Encoder)
+	return err
+}
+
+// zstdReader returns its decoder to the pool once the message is read to the end
+type zstdReader struct {
+	*zstd.Decoder
+	pool *sync.Pool
+}
+
+func (r *zstdReader) Read(p []byte) (int, error) {
+	if r.Decoder == nil {
+		return 0, io.EOF
+	}
+
+	n, err := r.Decoder.Read(p)
+	if err == io.EOF {
+		r.pool.Put(r.Decoder)
+		r.Decoder = nil
+	}
+	return n, err
+}
@@ -0,0 +1,94 @@
+package common
+
+import (
+	"flag"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
+)
+
+// minKeepaliveTime is the most often grpc lets clients ping, servers accept pings at this rate
+// so clients with any valid keepalive setting aren't disconnected for pinging too much
+const minKeepaliveTime = 10 * time.Second
+
+// ConnTuning holds the grpc keepalive, flow control and message size settings of a process.
+// Zero durations and window sizes keep grpc's defaults
+type ConnTuning struct {
+	KeepaliveTime         time.Duration // ping connections idle for this long so NATs and firewalls keep them open
+	KeepaliveTimeout      time.Duration // close connections whose ping isn't answered within this
+	MaxConnectionAge      time.Duration // servers ask clients to reconnect after this long, 0 never
+	InitialWindowSize     int           // flow control window per stream in bytes, 0 sizes it dynamically
+	InitialConnWindowSize int           // flow control window per connection in bytes, 0 sizes it dynamically
+	MaxMessageSize        int           // largest message sent or received in bytes
+}
+
+// DefaultConnTuning returns the connection settings used unless configured otherwise. Messages
+// fit a whole chunk plus the rest of the request
+func DefaultConnTuning() ConnTuning {
+	return ConnTuning{
+		KeepaliveTime:    30 * time.Second,
+		KeepaliveTimeout: 10 * time.Second,
+		MaxMessageSize:   ChunkSize + 1024*1024,
+	}
+}
+
+// RegisterFlags adds flags setting the connection tuning to fs, defaulting to its current values
+func (t *ConnTuning) RegisterFlags(fs *flag.FlagSet) {
+	fs.DurationVar(&t.KeepaliveTime, "keepalive-time", t.KeepaliveTime, "Ping idle grpc connections this often, 0 disables pings (at least 10s)")
+	fs.DurationVar(&t.KeepaliveTimeout, "keepalive-timeout", t.KeepaliveTimeout, "Close grpc connections whose ping isn't answered within this")
+	fs.DurationVar(&t.MaxConnectionAge, "max-connection-age", t.MaxConnectionAge, "Ask clients to reconnect after this long, 0 never (servers only)")
+	fs.IntVar(&t.InitialWindowSize, "window-size", t.InitialWindowSize, "grpc flow control window per stream in bytes, 0 sizes it dynamically")
+	fs.IntVar(&t.InitialConnWindowSize, "conn-window-size", t.InitialConnWindowSize, "grpc flow control window per connection in bytes, 0 sizes it dynamically")
+	fs.IntVar(&t.MaxMessageSize, "max-message-size", t.MaxMessageSize, "Largest grpc message sent or received in bytes")
+}
+
+// ServerOptions returns the grpc server options applying the tuning
+func (t ConnTuning) ServerOptions() []grpc.ServerOption {
+	options := []grpc.ServerOption{
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			Time:             t.KeepaliveTime,
+			Timeout:          t.KeepaliveTimeout,
+			MaxConnectionAge: t.MaxConnectionAge,
+		}),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             minKeepaliveTime,
+			PermitWithoutStream: true,
+		}),
+	}
+	if t.InitialWindowSize > 0 {
+		options = append(options, grpc.InitialWindowSize(int32(t.InitialWindowSize)))
+	}
+	if t.InitialConnWindowSize > 0 {
+		options = append(options, grpc.InitialConnWindowSize(int32(t.InitialConnWindowSize)))
+	}
+	if t.MaxMessageSize > 0 {
+		options = append(options, grpc.MaxRecvMsgSize(t.MaxMessageSize), grpc.MaxSendMsgSize(t.MaxMessageSize))
+	}
+
+	return options
+}
+
+// DialOptions returns the grpc dial options applying the tuning to connections without transport security
+func (t ConnTuning) DialOptions() []grpc.DialOption {
+	options := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	if t.KeepaliveTime > 0 {
+		options = append(options, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                max(t.KeepaliveTime, minKeepaliveTime),
+			Timeout:             t.KeepaliveTimeout,
+			PermitWithoutStream: true,
+		}))
+	}
+	if t.InitialWindowSize > 0 {
+		options = append(options, grpc.WithInitialWindowSize(int32(t.InitialWindowSize)))
+	}
+	if t.InitialConnWindowSize > 0 {
+		options = append(options, grpc.WithInitialConnWindowSize(int32(t.InitialConnWindowSize)))
+	}
+	if t.MaxMessageSize > 0 {
+		options = append(options, grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(t.MaxMessageSize), grpc.MaxCallSendMsgSize(t.MaxMessageSize)))
+	}
+
+	return options
+}
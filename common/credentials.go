@@ -0,0 +1,77 @@
+package common
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// TokenEnv is the environment variable holding the user token of client commands when no -token-file flag is given
+const TokenEnv = "DFS_TOKEN"
+
+// AuthorizationMetadataKey is the grpc metadata key carrying a client's token, as "Bearer <token>"
+const AuthorizationMetadataKey = "authorization"
+
+// ServerTokenMetadataKey is the grpc metadata key carrying the token a chunk server authenticates its
+// requests to the master with, see ChunkTokens.SignServer
+const ServerTokenMetadataKey = "dfs-server-token"
+
+// bearerPrefix precedes the token in the authorization metadata
+const bearerPrefix = "Bearer "
+
+// WithToken authenticates the outgoing requests made with the returned context with a user token
+func WithToken(ctx context.Context, token string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, AuthorizationMetadataKey, bearerPrefix+token)
+}
+
+// TokenDialOptions returns the interceptors authenticating every call on a connection with a user token
+func TokenDialOptions(token string) []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			return invoker(WithToken(ctx, token), method, req, reply, cc, opts...)
+		}),
+		grpc.WithChainStreamInterceptor(func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			return streamer(WithToken(ctx, token), desc, cc, method, opts...)
+		}),
+	}
+}
+
+// TokenFromContext returns the user token an incoming request carries, false when it carries none
+func TokenFromContext(ctx context.Context) (string, bool) {
+	values := metadata.ValueFromIncomingContext(ctx, AuthorizationMetadataKey)
+	if len(values) == 0 {
+		return "", false
+	}
+
+	token, found := strings.CutPrefix(values[0], bearerPrefix)
+	if !found || token == "" {
+		return "", false
+	}
+
+	return token, true
+}
+
+// WithServerToken authenticates the outgoing requests made with the returned context as a chunk server's
+func WithServerToken(ctx context.Context, token string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, ServerTokenMetadataKey, token)
+}
+
+// ServerTokenFromContext returns the chunk server token an incoming request carries, false when it carries none
+func ServerTokenFromContext(ctx context.Context) (string, bool) {
+	values := metadata.ValueFromIncomingContext(ctx, ServerTokenMetadataKey)
+	if len(values) == 0 || values[0] == "" {
+		return "", false
+	}
+
+	return values[0], true
+}
+
+// HashToken returns the hex encoded SHA-256 of a user token, as listed in the master's identities file
+func HashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
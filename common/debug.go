@@ -0,0 +1,15 @@
+package common
+
+import (
+	"google.golang.org/grpc"
+	channelzservice "google.golang.org/grpc/channelz/service"
+	"google.golang.org/grpc/reflection"
+)
+
+// RegisterDebugServices registers grpc reflection, so tools like grpcurl can list and call a server's rpcs
+// without its proto files, and channelz, which reports the state of the server's connections and calls.
+// Both expose the server's internals to anyone who can reach it, so they are only registered when asked for
+func RegisterDebugServices(server *grpc.Server) {
+	reflection.Register(server)
+	channelzservice.RegisterChannelzServiceToServer(server)
+}
@@ -0,0 +1,182 @@
+package common
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// FaultsEnv is the environment variable holding the fault injection spec when no -faults flag is given
+const FaultsEnv = "DFS_FAULTS"
+
+// Faults injects failures into a master or chunk server so recovery can be exercised in integration tests
+// and game days. A nil *Faults injects nothing. Faults are parsed from a comma separated spec of rules:
+//
+//	delay=200ms             delay every rpc the server handles
+//	delay:WriteChunk=2s     delay one rpc, named as in the proto service
+//	error=0.1               fail this fraction of rpcs with Unavailable, error:<rpc>=<fraction> for one rpc
+//	drop-report=0.5         the master ignores this fraction of ReportChunk calls
+//	partial-write=0.1       chunk servers store only part of this fraction of chunk writes, acknowledging them
+//	corrupt-read=0.01       chunk servers flip a bit of this fraction of verified chunk reads
+type Faults struct {
+	spec   string
+	delays map[string]time.Duration // key: rpc name, "*" for every rpc
+	errors map[string]float64       // key: rpc name, "*" for every rpc
+
+	dropReports   float64
+	partialWrites float64
+	corruptReads  float64
+}
+
+// ParseFaults parses a fault injection spec, returning nil for an empty spec
+func ParseFaults(spec string) (*Faults, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, nil
+	}
+
+	f := &Faults{
+		spec:   spec,
+		delays: make(map[string]time.Duration),
+		errors: make(map[string]float64),
+	}
+	for _, rule := range strings.Split(spec, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(rule), "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid fault %q, expected kind=value", rule)
+		}
+		kind, method, _ := strings.Cut(key, ":")
+		method = cmp.Or(method, "*")
+
+		var err error
+		switch kind {
+		case "delay":
+			f.delays[method], err = time.ParseDuration(value)
+		case "error":
+			f.errors[method], err = parseFraction(value)
+		case "drop-report":
+			f.dropReports, err = parseFraction(value)
+		case "partial-write":
+			f.partialWrites, err = parseFraction(value)
+		case "corrupt-read":
+			f.corruptReads, err = parseFraction(value)
+		default:
+			err = fmt.Errorf("unknown fault, expected delay, error, drop-report, partial-write or corrupt-read")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid fault %q: %v", rule, err)
+		}
+	}
+
+	return f, nil
+}
+
+// parseFraction parses a probability between 0 and 1
+func parseFraction(value string) (float64, error) {
+	fraction, err := strconv.ParseFloat(value, 64)
+	if err != nil || fraction < 0 || fraction > 1 {
+		return 0, fmt.Errorf("%q is not a fraction between 0 and 1", value)
+	}
+
+	return fraction, nil
+}
+
+// String returns the spec the faults were parsed from
+func (f *Faults) String() string {
+	if f == nil {
+		return ""
+	}
+
+	return f.spec
+}
+
+// inject reports whether a fault happening to fraction of the operations hits this one
+func (f *Faults) inject(fraction float64, description string) bool {
+	if f == nil || fraction <= 0 || rand.Float64() >= fraction {
+		return false
+	}
+
+	log.Printf("Fault injection: %s", description)
+	return true
+}
+
+// DropReport reports whether to ignore a ReportChunk call
+func (f *Faults) DropReport(chunkHandle string) bool {
+	return f != nil && f.inject(f.dropReports, "dropping report of chunk "+chunkHandle)
+}
+
+// PartialWrite reports whether to store only part of a chunk write
+func (f *Faults) PartialWrite(chunkHandle string) bool {
+	return f != nil && f.inject(f.partialWrites, "storing a partial write of chunk "+chunkHandle)
+}
+
+// CorruptRead reports whether to corrupt a chunk read
+func (f *Faults) CorruptRead(chunkHandle string) bool {
+	return f != nil && f.inject(f.corruptReads, "corrupting a read of chunk "+chunkHandle)
+}
+
+// FlipBit flips a random bit of data
+func FlipBit(data []byte) {
+	if len(data) > 0 {
+		data[rand.IntN(len(data))] ^= 1 << rand.IntN(8)
+	}
+}
+
+// ServerOptions returns the grpc interceptors delaying and failing rpcs, nil when no rpc faults are set
+func (f *Faults) ServerOptions() []grpc.ServerOption {
+	if f == nil || len(f.delays) == 0 && len(f.errors) == 0 {
+		return nil
+	}
+
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			if err := f.beforeRPC(ctx, info.FullMethod); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.ChainStreamInterceptor(func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := f.beforeRPC(stream.Context(), info.FullMethod); err != nil {
+				return err
+			}
+			return handler(srv, stream)
+		}),
+	}
+}
+
+// beforeRPC applies the delay and error faults of an rpc
+func (f *Faults) beforeRPC(ctx context.Context, fullMethod string) error {
+	method := path.Base(fullMethod)
+
+	delay, exists := f.delays[method]
+	if !exists {
+		delay = f.delays["*"]
+	}
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	fraction, exists := f.errors[method]
+	if !exists {
+		fraction = f.errors["*"]
+	}
+	if f.inject(fraction, "failing "+method) {
+		return status.Errorf(codes.Unavailable, "injected fault: %s failed", method)
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package common
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+)
+
+// ParseFlagFile sets the flags of set listed in a file of name=value lines, e.g. max-io=16, skipping blank
+// lines and # comments. Names may start with a dash like on the command line. Flags set doesn't define are rejected
+func ParseFlagFile(path string, set *flag.FlagSet) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %v", path, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		name, value, ok := strings.Cut(line, "=")
+		name = strings.TrimLeft(strings.TrimSpace(name), "-")
+		if !ok {
+			return fmt.Errorf("%s:%d: expected name=value", path, lineNumber)
+		}
+		if set.Lookup(name) == nil {
+			return fmt.Errorf("%s:%d: %s can't be changed at runtime", path, lineNumber, name)
+		}
+		if err := set.Set(name, strings.TrimSpace(value)); err != nil {
+			return fmt.Errorf("%s:%d: invalid %s: %v", path, lineNumber, name, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read %s: %v", path, err)
+	}
+
+	return nil
+}
+
+// OnHangup calls reload every time the process receives SIGHUP, the conventional signal for reloading configuration
+func OnHangup(reload func()) {
+	hangups := make(chan os.Signal, 1)
+	signal.Notify(hangups, syscall.SIGHUP)
+
+	go func() {
+		for range hangups {
+			reload()
+		}
+	}()
+}
@@ -0,0 +1,190 @@
+package common
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"runtime/debug"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// ServerInterceptors is the interceptor chain the master and chunk servers run every rpc through. It recovers
+// panics of handlers, failing the rpc with Internal instead of crashing the server, counts rpcs and their
+// durations for metrics, logs every rpc when request logging is on and logs slow rpcs. Panics of goroutines
+// a handler starts aren't recovered
+type ServerInterceptors struct {
+	slowRequests *SlowRequestLog
+	logRequests  atomic.Bool
+
+	mu       sync.Mutex
+	requests map[rpcKey]*rpcStats
+	inFlight atomic.Int64
+	panics   atomic.Int64
+}
+
+// rpcKey identifies the rpcs counted together
+type rpcKey struct {
+	method string
+	code   codes.Code
+}
+
+// rpcStats counts the rpcs of a method that ended with a status code
+type rpcStats struct {
+	count    int64
+	duration time.Duration
+}
+
+// NewServerInterceptors creates the interceptor chain of a server, also logging the slow requests of
+// slowRequests, nil logging none
+func NewServerInterceptors(slowRequests *SlowRequestLog, logRequests bool) *ServerInterceptors {
+	i := &ServerInterceptors{
+		slowRequests: slowRequests,
+		requests:     make(map[rpcKey]*rpcStats),
+	}
+	i.SetLogRequests(logRequests)
+
+	return i
+}
+
+// LogRequests reports whether every rpc is logged
+func (i *ServerInterceptors) LogRequests() bool {
+	return i.logRequests.Load()
+}
+
+// SetLogRequests turns logging every rpc with its caller, duration and status on or off
+func (i *ServerInterceptors) SetLogRequests(logRequests bool) {
+	i.logRequests.Store(logRequests)
+}
+
+// ServerOptions returns the grpc interceptors, the outermost of a server besides connection settings so
+// they see every rpc, including those rejected or panicking in the interceptors added after them
+func (i *ServerInterceptors) ServerOptions() []grpc.ServerOption {
+	options := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			start := i.begin()
+			response, err := recoverUnary(ctx, req, info, handler, &i.panics)
+			i.end(ctx, info.FullMethod, start, err)
+			return response, err
+		}),
+		grpc.ChainStreamInterceptor(func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			start := i.begin()
+			err := recoverStream(srv, stream, info, handler, &i.panics)
+			i.end(stream.Context(), info.FullMethod, start, err)
+			return err
+		}),
+	}
+	if i.slowRequests != nil {
+		options = append(options, i.slowRequests.ServerOptions()...)
+	}
+
+	return options
+}
+
+// begin counts an rpc as in flight, returning when it started
+func (i *ServerInterceptors) begin() time.Time {
+	i.inFlight.Add(1)
+	return time.Now()
+}
+
+// end records an rpc that finished with err
+func (i *ServerInterceptors) end(ctx context.Context, fullMethod string, start time.Time, err error) {
+	elapsed := time.Since(start)
+	i.inFlight.Add(-1)
+
+	method := path.Base(fullMethod)
+	code := status.Code(err)
+	i.mu.Lock()
+	stats, ok := i.requests[rpcKey{method, code}]
+	if !ok {
+		stats = &rpcStats{}
+		i.requests[rpcKey{method, code}] = stats
+	}
+	stats.count++
+	stats.duration += elapsed
+	i.mu.Unlock()
+
+	if i.LogRequests() {
+		caller := "unknown"
+		if p, ok := peer.FromContext(ctx); ok {
+			caller = p.Addr.String()
+		}
+		log.Printf("Request: %s from %s took %s (%s)", method, caller, elapsed.Round(time.Microsecond), code)
+	}
+}
+
+// recoverUnary calls handler, turning a panic into an Internal error
+func recoverUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler, panics *atomic.Int64) (response any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = recovered(info.FullMethod, r, panics)
+		}
+	}()
+
+	return handler(ctx, req)
+}
+
+// recoverStream calls handler, turning a panic into an Internal error
+func recoverStream(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler, panics *atomic.Int64) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = recovered(info.FullMethod, r, panics)
+		}
+	}()
+
+	return handler(srv, stream)
+}
+
+// recovered logs a panic of an rpc handler with its stack, returning the error the rpc fails with
+func recovered(fullMethod string, r any, panics *atomic.Int64) error {
+	panics.Add(1)
+	log.Printf("Warning: recovered panic serving %s: %v\n%s", path.Base(fullMethod), r, debug.Stack())
+
+	return status.Errorf(codes.Internal, "internal error serving %s", path.Base(fullMethod))
+}
+
+// WriteMetrics writes the rpc counters in the prometheus text format, named after prefix, e.g.
+// dfs_master_grpc_requests_total for the prefix dfs_master
+func (i *ServerInterceptors) WriteMetrics(w io.Writer, prefix string) {
+	i.mu.Lock()
+	keys := make([]rpcKey, 0, len(i.requests))
+	stats := make(map[rpcKey]rpcStats, len(i.requests))
+	for key, s := range i.requests {
+		keys = append(keys, key)
+		stats[key] = *s
+	}
+	i.mu.Unlock()
+
+	slices.SortFunc(keys, func(a, b rpcKey) int {
+		return cmp.Or(cmp.Compare(a.method, b.method), cmp.Compare(a.code, b.code))
+	})
+
+	name := prefix + "_grpc_requests_total"
+	fmt.Fprintf(w, "# HELP %s Rpcs served by method and status code.\n# TYPE %s counter\n", name, name)
+	for _, key := range keys {
+		fmt.Fprintf(w, "%s{method=%q,code=%q} %d\n", name, key.method, key.code, stats[key].count)
+	}
+
+	name = prefix + "_grpc_request_duration_seconds"
+	fmt.Fprintf(w, "# HELP %s Time spent serving rpcs by method and status code.\n# TYPE %s summary\n", name, name)
+	for _, key := range keys {
+		fmt.Fprintf(w, "%s_sum{method=%q,code=%q} %g\n", name, key.method, key.code, stats[key].duration.Seconds())
+		fmt.Fprintf(w, "%s_count{method=%q,code=%q} %d\n", name, key.method, key.code, stats[key].count)
+	}
+
+	name = prefix + "_grpc_requests_in_flight"
+	fmt.Fprintf(w, "# HELP %s Rpcs being served.\n# TYPE %s gauge\n%s %d\n", name, name, name, i.inFlight.Load())
+
+	name = prefix + "_grpc_panics_total"
+	fmt.Fprintf(w, "# HELP %s Panics of rpc handlers recovered.\n# TYPE %s counter\n%s %d\n", name, name, name, i.panics.Load())
+}
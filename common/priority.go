@@ -0,0 +1,55 @@
+package common
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// Priority is the class of service a request asks for, the master serving interactive requests first when saturated
+type Priority string
+
+const (
+	// PriorityInteractive requests are waited on by a user, e.g. list, stat and download. Untagged requests are interactive
+	PriorityInteractive Priority = "interactive"
+
+	// PriorityBatch requests belong to bulk jobs like migrations and imports, which can wait when the master is busy
+	PriorityBatch Priority = "batch"
+)
+
+// PriorityMetadataKey is the grpc metadata key carrying a request's priority
+const PriorityMetadataKey = "dfs-priority"
+
+// ParsePriority parses a priority name, the empty name being PriorityInteractive
+func ParsePriority(name string) (Priority, error) {
+	switch Priority(name) {
+	case "", PriorityInteractive:
+		return PriorityInteractive, nil
+	case PriorityBatch:
+		return PriorityBatch, nil
+	default:
+		return "", fmt.Errorf("unknown priority %q, expected %s or %s", name, PriorityInteractive, PriorityBatch)
+	}
+}
+
+// WithPriority tags the outgoing requests made with the returned context with priority
+func WithPriority(ctx context.Context, priority Priority) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, PriorityMetadataKey, string(priority))
+}
+
+// PriorityFromContext returns the priority an incoming request is tagged with, PriorityInteractive when
+// it isn't tagged or the tag is unknown
+func PriorityFromContext(ctx context.Context) Priority {
+	values := metadata.ValueFromIncomingContext(ctx, PriorityMetadataKey)
+	if len(values) == 0 {
+		return PriorityInteractive
+	}
+
+	priority, err := ParsePriority(values[0])
+	if err != nil {
+		return PriorityInteractive
+	}
+
+	return priority
+}
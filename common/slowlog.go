@@ -0,0 +1,179 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const (
+	// DefaultSlowRequestThreshold is how long a request may take before it's logged as slow unless configured otherwise
+	DefaultSlowRequestThreshold = 5 * time.Second
+
+	// slowRequestMaxValue bounds how much of a string field of a slow request is logged
+	slowRequestMaxValue = 128
+
+	// slowRequestMaxItems bounds how many items of a list field of a slow request are logged
+	slowRequestMaxItems = 8
+)
+
+// SlowRequestLog logs every rpc a server handles taking longer than a threshold with its parameters, caller and
+// duration, so slow disks or networks show up in the logs. The threshold can be changed while the server runs
+type SlowRequestLog struct {
+	threshold atomic.Int64 // nanoseconds, 0 logs nothing
+	exempt    []string     // rpcs never logged
+}
+
+// NewSlowRequestLog creates a log of the rpcs taking longer than threshold, 0 logging none. Long lived
+// streams, e.g. Watch, are named in exempt and never logged
+func NewSlowRequestLog(threshold time.Duration, exempt ...string) *SlowRequestLog {
+	l := &SlowRequestLog{exempt: exempt}
+	l.SetThreshold(threshold)
+
+	return l
+}
+
+// Threshold returns the duration above which rpcs are logged, 0 when none are
+func (l *SlowRequestLog) Threshold() time.Duration {
+	return time.Duration(l.threshold.Load())
+}
+
+// SetThreshold changes the duration above which rpcs are logged, 0 logging none
+func (l *SlowRequestLog) SetThreshold(threshold time.Duration) {
+	l.threshold.Store(int64(max(threshold, 0)))
+}
+
+// ServerOptions returns the grpc interceptors timing every rpc
+func (l *SlowRequestLog) ServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			start := time.Now()
+			response, err := handler(ctx, req)
+			if elapsed := time.Since(start); l.slow(elapsed) {
+				logSlowRequest(ctx, info.FullMethod, req, elapsed, err)
+			}
+			return response, err
+		}),
+		grpc.ChainStreamInterceptor(func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if slices.Contains(l.exempt, path.Base(info.FullMethod)) {
+				return handler(srv, stream)
+			}
+
+			start := time.Now()
+			recorded := &firstMessageStream{ServerStream: stream}
+			err := handler(srv, recorded)
+			if elapsed := time.Since(start); l.slow(elapsed) {
+				logSlowRequest(stream.Context(), info.FullMethod, recorded.first, elapsed, err)
+			}
+			return err
+		}),
+	}
+}
+
+// slow reports whether an rpc taking elapsed is logged
+func (l *SlowRequestLog) slow(elapsed time.Duration) bool {
+	threshold := l.Threshold()
+	return threshold > 0 && elapsed > threshold
+}
+
+// firstMessageStream keeps the first message received on a stream, the request of a server stream
+type firstMessageStream struct {
+	grpc.ServerStream
+	first any
+}
+
+func (s *firstMessageStream) RecvMsg(m any) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil && s.first == nil {
+		s.first = m
+	}
+
+	return err
+}
+
+// logSlowRequest logs an rpc that took longer than the slow request threshold
+func logSlowRequest(ctx context.Context, fullMethod string, req any, elapsed time.Duration, err error) {
+	caller := "unknown"
+	if p, ok := peer.FromContext(ctx); ok {
+		caller = p.Addr.String()
+	}
+
+	log.Printf("Slow request: %s from %s took %s (%s) %s", path.Base(fullMethod), caller, elapsed.Round(time.Millisecond), status.Code(err), describeRequest(req))
+}
+
+// describeRequest formats the fields set in a request, leaving out chunk data and access tokens
+func describeRequest(req any) string {
+	message, ok := req.(proto.Message)
+	if !ok || message == nil {
+		return "{}"
+	}
+
+	return describeMessage(message.ProtoReflect())
+}
+
+// describeMessage formats the fields set in a message
+func describeMessage(message protoreflect.Message) string {
+	var fields []string
+	message.Range(func(field protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+		fields = append(fields, string(field.Name())+"="+describeField(field, value))
+		return true
+	})
+
+	return "{" + strings.Join(fields, " ") + "}"
+}
+
+// describeField formats the value of a field of a request
+func describeField(field protoreflect.FieldDescriptor, value protoreflect.Value) string {
+	switch {
+	case strings.HasSuffix(string(field.Name()), "token"):
+		return "<redacted>"
+	case field.IsMap():
+		return fmt.Sprintf("<%d entries>", value.Map().Len())
+	case field.IsList():
+		list := value.List()
+		items := make([]string, 0, min(list.Len(), slowRequestMaxItems))
+		for i := range min(list.Len(), slowRequestMaxItems) {
+			items = append(items, describeValue(field, list.Get(i)))
+		}
+		if list.Len() > slowRequestMaxItems {
+			items = append(items, fmt.Sprintf("... %d more", list.Len()-slowRequestMaxItems))
+		}
+		return "[" + strings.Join(items, " ") + "]"
+	default:
+		return describeValue(field, value)
+	}
+}
+
+// describeValue formats a single value of a field
+func describeValue(field protoreflect.FieldDescriptor, value protoreflect.Value) string {
+	switch field.Kind() {
+	case protoreflect.BytesKind:
+		return fmt.Sprintf("<%d bytes>", len(value.Bytes()))
+	case protoreflect.StringKind:
+		s := value.String()
+		if len(s) > slowRequestMaxValue {
+			s = s[:slowRequestMaxValue] + "..."
+		}
+		return fmt.Sprintf("%q", s)
+	case protoreflect.EnumKind:
+		if enum := field.Enum().Values().ByNumber(value.Enum()); enum != nil {
+			return string(enum.Name())
+		}
+		return value.String()
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return describeMessage(value.Message())
+	default:
+		return value.String()
+	}
+}
@@ -0,0 +1,202 @@
+package common
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ChunkTokenKeyEnv is the environment variable holding the chunk token key when no key file is given
+const ChunkTokenKeyEnv = "DFS_CHUNK_TOKEN_KEY"
+
+// DefaultChunkTokenTTL is how long chunk access tokens stay valid, long enough for the slowest upload
+// of a large file since its tokens are all handed out when it starts
+const DefaultChunkTokenTTL = time.Hour
+
+// MinKeySize is the shortest signing key accepted, shorter keys being guessable
+const MinKeySize = 16
+
+// ChunkOperation is what a chunk access token allows its holder to do with a chunk
+type ChunkOperation string
+
+const (
+	ChunkRead   ChunkOperation = "read"
+	ChunkWrite  ChunkOperation = "write" // also covers appending records and copying into the chunk
+	ChunkDelete ChunkOperation = "delete"
+	// ChunkForward marks writes a primary forwards to its secondaries and writes the master directs to a
+	// replica, the only writes taken without a lease. Clients never get these tokens
+	ChunkForward ChunkOperation = "forward"
+)
+
+// chunkServerOperation is what the tokens chunk servers authenticate their requests to the master with are
+// signed for, with the server's address in place of a chunk handle
+const chunkServerOperation ChunkOperation = "chunk-server"
+
+// leaseOperation is what lease tokens are signed for, with the chunk handle, primary and chunk version in place
+// of a chunk handle
+const leaseOperation ChunkOperation = "lease"
+
+// delegationOperation is what delegation tokens are signed for, with the token's encoded claims in place of a
+// chunk handle
+const delegationOperation ChunkOperation = "delegation"
+
+// ErrInvalidChunkToken is returned for chunk access tokens that are missing, expired, or not signed
+// for the chunk and operation they are used for
+var ErrInvalidChunkToken = errors.New("invalid chunk access token")
+
+// ChunkTokens signs and verifies the short-lived tokens the master hands out with chunk locations, so only
+// clients the master let access a file can read or overwrite its chunks on the chunk servers. The master and
+// the chunk servers share the key. A nil *ChunkTokens signs empty tokens and accepts any token, for clusters
+// without a key
+type ChunkTokens struct {
+	key []byte
+	ttl time.Duration
+}
+
+// NewChunkTokens creates the signer of tokens valid for ttl, DefaultChunkTokenTTL when 0. It returns nil
+// for an empty key, which disables tokens
+func NewChunkTokens(key []byte, ttl time.Duration) (*ChunkTokens, error) {
+	if len(key) == 0 {
+		return nil, nil
+	}
+	if len(key) < MinKeySize {
+		return nil, fmt.Errorf("chunk token key must be at least %d bytes", MinKeySize)
+	}
+	if ttl <= 0 {
+		ttl = DefaultChunkTokenTTL
+	}
+
+	return &ChunkTokens{key: key, ttl: ttl}, nil
+}
+
+// LoadKey reads a signing key from path, or from the environment variable env when path is empty.
+// Surrounding whitespace is dropped so keys can be written with a trailing newline
+func LoadKey(path, env string) ([]byte, error) {
+	if path == "" {
+		return []byte(strings.TrimSpace(os.Getenv(env))), nil
+	}
+
+	key, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key: %v", err)
+	}
+
+	return bytes.TrimSpace(key), nil
+}
+
+// Sign returns a token allowing operation on the chunk until the token's time to live runs out
+func (t *ChunkTokens) Sign(chunkHandle string, operation ChunkOperation) string {
+	if t == nil {
+		return ""
+	}
+
+	expires := strconv.FormatInt(time.Now().Add(t.ttl).Unix(), 10)
+	return expires + "." + base64.RawURLEncoding.EncodeToString(t.mac(chunkHandle, operation, expires))
+}
+
+// Verify checks token allows operation on the chunk now
+func (t *ChunkTokens) Verify(token, chunkHandle string, operation ChunkOperation) error {
+	return t.verify(token, chunkHandle, operation, fmt.Sprintf("%s of chunk %s", operation, chunkHandle))
+}
+
+// SignServer returns a token authenticating the requests of the chunk server at address to the master, so
+// only servers holding the cluster's key can join it and report chunks
+func (t *ChunkTokens) SignServer(address string) string {
+	return t.Sign(address, chunkServerOperation)
+}
+
+// VerifyServer checks token authenticates requests of the chunk server at address now
+func (t *ChunkTokens) VerifyServer(token, address string) error {
+	return t.verify(token, address, chunkServerOperation, "chunk server "+address)
+}
+
+// SignLease returns a token proving the master granted primary the lease on a version of the chunk until expires,
+// so the primary doesn't have to take a client's word for it
+func (t *ChunkTokens) SignLease(chunkHandle, primary string, chunkVersion int32, expires time.Time) string {
+	if t == nil {
+		return ""
+	}
+
+	// rounding down, the token never outlives the lease
+	expiresAt := strconv.FormatInt(expires.Unix(), 10)
+	return expiresAt + "." + base64.RawURLEncoding.EncodeToString(t.mac(leaseSubject(chunkHandle, primary, chunkVersion), leaseOperation, expiresAt))
+}
+
+// VerifyLease checks token proves primary holds the lease on a version of the chunk now
+func (t *ChunkTokens) VerifyLease(token, chunkHandle, primary string, chunkVersion int32) error {
+	return t.verify(token, leaseSubject(chunkHandle, primary, chunkVersion), leaseOperation,
+		fmt.Sprintf("lease on version %d of chunk %s held by %s", chunkVersion, chunkHandle, primary))
+}
+
+// SignDelegation returns the signature of a delegation token carrying claims, valid until expires. The master
+// keeps the claims' format to itself, they are only signed here
+func (t *ChunkTokens) SignDelegation(claims string, expires time.Time) string {
+	if t == nil {
+		return ""
+	}
+
+	expiresAt := strconv.FormatInt(expires.Unix(), 10)
+	return expiresAt + "." + base64.RawURLEncoding.EncodeToString(t.mac(claims, delegationOperation, expiresAt))
+}
+
+// VerifyDelegation checks signature was returned by SignDelegation for claims and hasn't expired, returning when it expires
+func (t *ChunkTokens) VerifyDelegation(signature, claims string) (time.Time, error) {
+	if err := t.verify(signature, claims, delegationOperation, "delegation token"); err != nil {
+		return time.Time{}, err
+	}
+
+	expires, _, _ := strings.Cut(signature, ".")
+	expiresAt, _ := strconv.ParseInt(expires, 10, 64)
+	return time.Unix(expiresAt, 0), nil
+}
+
+// leaseSubject is what a lease token is signed for
+func leaseSubject(chunkHandle, primary string, chunkVersion int32) string {
+	return chunkHandle + "\x00" + primary + "\x00" + strconv.Itoa(int(chunkVersion))
+}
+
+// verify checks token is signed for operation on subject and hasn't expired, naming the request what in errors
+func (t *ChunkTokens) verify(token, subject string, operation ChunkOperation, what string) error {
+	if t == nil {
+		return nil
+	}
+	if token == "" {
+		return fmt.Errorf("%w: %s carries no token", ErrInvalidChunkToken, what)
+	}
+
+	expires, signature, found := strings.Cut(token, ".")
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if !found || err != nil {
+		return fmt.Errorf("%w: malformed token", ErrInvalidChunkToken)
+	}
+
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, t.mac(subject, operation, expires)) {
+		return fmt.Errorf("%w: token not signed for %s", ErrInvalidChunkToken, what)
+	}
+
+	if !time.Now().Before(time.Unix(expiresAt, 0)) {
+		return fmt.Errorf("%w: token expired at %s", ErrInvalidChunkToken, time.Unix(expiresAt, 0).Format(time.DateTime))
+	}
+
+	return nil
+}
+
+// mac signs the chunk handle, operation and expiry of a token
+func (t *ChunkTokens) mac(chunkHandle string, operation ChunkOperation, expires string) []byte {
+	h := hmac.New(sha256.New, t.key)
+	h.Write([]byte(chunkHandle))
+	h.Write([]byte{0})
+	h.Write([]byte(operation))
+	h.Write([]byte{0})
+	h.Write([]byte(expires))
+
+	return h.Sum(nil)
+}
@@ -3,6 +3,7 @@ package common
 import (
 	"crypto/sha256"
 	"fmt"
+	"hash/crc32"
 )
 
 const (
@@ -14,11 +15,37 @@ const (
 
 	// MasterAddress is the default master server address
 	MasterAddress = "localhost:8000"
+
+	// MaxRecordSize is the largest record that can be appended, so padding a chunk a record doesn't fit wastes at most a quarter of it
+	MaxRecordSize = ChunkSize / 4
+
+	// MaxInlineSize is the largest file clients send along with its upload request, so the master can store it in
+	// its metadata instead of chunks
+	MaxInlineSize = 64 * 1024
 )
 
-// GenerateChunkHandle generates a unique chunk handle based on filename and chunk index
-func GenerateChunkHandle(filename string, chunkIndex int) string {
-	data := fmt.Sprintf("%s-%d", filename, chunkIndex)
+// Version is the software version of the dfs binaries, set at build time with
+// -ldflags "-X github.com/harshvardha/distributed_file_system/common.Version=v1.2.0"
+var Version = "dev"
+
+// castagnoliTable is used for chunk payload checksums
+var castagnoliTable = crc32.MakeTable(crc32.Castagnoli)
+
+// Checksum computes the CRC-32C checksum clients send with chunk writes, the one chunk servers store with chunks
+func Checksum(data []byte) uint32 {
+	return crc32.Checksum(data, castagnoliTable)
+}
+
+// UpdateChecksum extends a Checksum with more data, for checksumming data that arrives in pieces
+func UpdateChecksum(crc uint32, data []byte) uint32 {
+	return crc32.Update(crc, castagnoliTable, data)
+}
+
+// GenerateChunkHandle generates a unique chunk handle based on filename, file generation and chunk index.
+// Each generation of a file gets its own handles, so a renamed file never shares chunks with a new file
+// written under its old name
+func GenerateChunkHandle(filename string, generation int64, chunkIndex int) string {
+	data := fmt.Sprintf("%s-%d-%d", filename, generation, chunkIndex)
 	hash := sha256.Sum256([]byte(data))
 	return fmt.Sprintf("%x", hash[:16])
 }
@@ -32,3 +59,20 @@ func CalculateNumChunks(filesize int64) int {
 
 	return int(numChunks)
 }
+
+// ChunkLength returns the number of bytes held by the chunk at chunkIndex of a file of the given size
+func ChunkLength(filesize int64, chunkIndex int) int64 {
+	return max(0, min(int64(ChunkSize), filesize-int64(chunkIndex)*ChunkSize))
+}
+
+// FormatBytes formats a byte count using binary units
+func FormatBytes(bytes float64) string {
+	units := []string{"B", "KB", "MB", "GB", "TB"}
+	unit := 0
+	for bytes >= 1024 && unit < len(units)-1 {
+		bytes /= 1024
+		unit++
+	}
+
+	return fmt.Sprintf("%.1f %s", bytes, units[unit])
+}
@@ -0,0 +1,227 @@
+package master
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/harshvardha/distributed_file_system/common"
+	pb "github.com/harshvardha/distributed_file_system/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// AddAppendChunk adds a chunk placed on servers to the end of a file records are appended to and returns it.
+// The servers are recorded as the chunk's locations right away, the first record appended creates the chunk on them
+func (m *Metadata) AddAppendChunk(filename string, servers []string) (*ChunkMetadata, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var chunk *ChunkMetadata
+	exists := false
+	err := m.update(func(tx *metadataTx) error {
+		var file *FileMetadata
+		var err error
+		file, exists, err = tx.GetFile(filename)
+		if err != nil || !exists {
+			return err
+		}
+
+		chunkIndex := len(file.Chunks)
+		chunk, err = tx.addChunk(common.GenerateChunkHandle(filename, file.Generation, chunkIndex), filename, int32(chunkIndex))
+		if err != nil {
+			return err
+		}
+
+		chunk.Locations = servers
+		chunk.ReplicationFactor = file.ReplicationFactor
+		chunk.Tier = file.Tier
+		if err := tx.PutChunk(chunk); err != nil {
+			return err
+		}
+
+		file.Chunks = append(file.Chunks, chunk.ChunkHandle)
+		file.ChunkCount = len(file.Chunks)
+		return tx.PutFile(file)
+	})
+	if err != nil || !exists {
+		return nil, exists, err
+	}
+
+	return chunk, true, nil
+}
+
+// ExtendFile grows a file to filesize, keeping its generation so readers see the file grow, and returns it.
+// Files already as large are left alone. It returns false if the file doesn't exist
+func (m *Metadata) ExtendFile(filename string, filesize int64) (*FileMetadata, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var file *FileMetadata
+	exists := false
+	err := m.update(func(tx *metadataTx) error {
+		var err error
+		file, exists, err = tx.GetFile(filename)
+		if err != nil || !exists || filesize <= file.Filesize {
+			return err
+		}
+
+		file.Filesize = filesize
+		file.ModifiedAt = time.Now()
+		return tx.PutFile(file)
+	})
+	if err != nil {
+		return nil, exists, err
+	}
+
+	return file, exists, nil
+}
+
+// PrepareAppend handles requests for the chunk records are appended to. The first record creates the file,
+// a new chunk is added when the file has none or the primary reported the last one full
+func (s *Server) PrepareAppend(ctx context.Context, req *pb.PrepareAppendRequest) (*pb.PrepareAppendResponse, error) {
+	log.Printf("Append request for file: %s", req.Filename)
+
+	unlock := s.locks.Lock(req.Filename)
+	defer unlock()
+
+	// an upload replaces the chunks records are appended to
+	if s.uploads.InProgress(req.Filename) {
+		return nil, status.Errorf(codes.Aborted, "failed to append to %s: %v", req.Filename, ErrUploadInProgress)
+	}
+
+	file, exists, err := s.metadata.GetFile(req.Filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up file %s: %v", req.Filename, err)
+	}
+	if err := checkMutable(req.Filename, file, exists); err != nil {
+		return nil, err
+	}
+	if exists && file.isSymlink() {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to append to %s: it is a symlink to %s", req.Filename, file.SymlinkTarget)
+	}
+	// the records would only show up under one of the names sharing the chunks
+	if exists && len(file.Links) > 0 {
+		return nil, status.Errorf(codes.FailedPrecondition, "failed to append to %s: it has hard links %v", req.Filename, file.Links)
+	}
+	identity := identityFromContext(ctx)
+	if err := checkAccess(req.Filename, file, exists, identity, AccessWrite); err != nil {
+		return nil, err
+	}
+	if !exists {
+		if _, _, err := s.metadata.AddFile(req.Filename, 0, 0, s.tunables.Load().KeepVersions, newOwnership(identity, DefaultFileMode)); err != nil {
+			return nil, fmt.Errorf("failed to add file %s: %v", req.Filename, err)
+		}
+		if file, _, err = s.metadata.GetFile(req.Filename); err != nil {
+			return nil, fmt.Errorf("failed to look up file %s: %v", req.Filename, err)
+		}
+		s.events.Publish(pb.FileEventType_FILE_EVENT_CREATED, req.Filename, "", 0)
+	}
+
+	// records go to chunks, so the contents of a file stored inline become its first chunk
+	if file.Data != nil {
+		if file, err = s.spillInlineData(file); err != nil {
+			return nil, err
+		}
+	}
+	// and are appended to the contents of an archived file, not to the archive
+	if err := s.checkRecalled(file, file.Generation); err != nil {
+		return nil, err
+	}
+
+	// the primary padded the last chunk on every replica, so the file now covers all of it
+	full := req.FullChunkIndex != nil && int(*req.FullChunkIndex) == len(file.Chunks)-1
+	if full {
+		if file, _, err = s.metadata.ExtendFile(req.Filename, int64(len(file.Chunks))*common.ChunkSize); err != nil {
+			return nil, fmt.Errorf("failed to extend file %s: %v", req.Filename, err)
+		}
+	}
+
+	var chunk *ChunkMetadata
+	if len(file.Chunks) == 0 || full {
+		servers, err := s.metadata.PlaceChunk(file.replicationFactor(), PlacementHints{Tier: file.Tier})
+		if err != nil {
+			return nil, fmt.Errorf("failed to place chunk %d of %s: %v", len(file.Chunks), req.Filename, err)
+		}
+		if len(servers) == 0 {
+			return nil, status.Errorf(codes.ResourceExhausted, "failed to place chunk %d of %s: no chunk servers available", len(file.Chunks), req.Filename)
+		}
+
+		if chunk, _, err = s.metadata.AddAppendChunk(req.Filename, servers); err != nil {
+			return nil, fmt.Errorf("failed to add chunk %d to %s: %v", len(file.Chunks), req.Filename, err)
+		}
+		log.Printf("Chunk %d (%s) of %s assigned to servers: %v", chunk.ChunkIndex, chunk.ChunkHandle, req.Filename, servers)
+	} else {
+		chunkHandle := file.Chunks[len(file.Chunks)-1]
+		if chunk, exists, err = s.metadata.GetChunk(chunkHandle); err != nil || !exists {
+			return nil, fmt.Errorf("failed to look up chunk %s: %v", chunkHandle, err)
+		}
+
+		// records appended to a chunk shared with a clone must not show up in the other file
+		if chunk.shared() {
+			if chunk, err = s.copyOnWrite(req.Filename, chunk); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if len(chunk.Locations) == 0 {
+		return nil, status.Errorf(codes.DataLoss, "chunk %d of %s has no replicas left", chunk.ChunkIndex, req.Filename)
+	}
+
+	// every client sends records of a chunk to the lease holder, which orders them
+	lease, err := s.metadata.GrantLease(chunk.ChunkHandle, chunk.Locations)
+	if err != nil {
+		return nil, status.Errorf(codes.Aborted, "failed to append to chunk %d of %s: %v", chunk.ChunkIndex, req.Filename, err)
+	}
+
+	return &pb.PrepareAppendResponse{
+		ChunkLocation: &pb.ChunkLocation{
+			ChunkHandle:          chunk.ChunkHandle,
+			ChunkServerAddresses: chunk.Locations,
+			ChunkIndex:           chunk.ChunkIndex,
+			ChunkVersion:         lease.version,
+			PrimaryAddress:       lease.primary,
+			LeaseExpiresAt:       lease.expires.UnixNano(),
+			AccessToken:          s.tokens.Sign(chunk.ChunkHandle, common.ChunkWrite),
+			LeaseToken:           s.tokens.SignLease(chunk.ChunkHandle, lease.primary, lease.version, lease.expires),
+		},
+		Generation: file.Generation,
+	}, nil
+}
+
+// CompleteAppend handles reports of records written to every replica, growing the file to cover them
+func (s *Server) CompleteAppend(ctx context.Context, req *pb.CompleteAppendRequest) (*pb.CompleteAppendResponse, error) {
+	if req.EndOffset < 0 || req.EndOffset > common.ChunkSize {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to complete append to %s: offset %d out of bounds", req.Filename, req.EndOffset)
+	}
+
+	unlock := s.locks.Lock(req.Filename)
+	defer unlock()
+
+	file, exists, err := s.metadata.GetFile(req.Filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up file %s: %v", req.Filename, err)
+	}
+	if !exists {
+		return nil, status.Errorf(codes.NotFound, "file not found: %s", req.Filename)
+	}
+
+	// the record went to a file that has since been overwritten, renamed or deleted
+	if file.Generation != req.Generation {
+		return nil, status.Errorf(codes.FailedPrecondition, "%s: %v, appended to generation %d, found %d", req.Filename, ErrGenerationMismatch, req.Generation, file.Generation)
+	}
+	if req.ChunkIndex < 0 || int(req.ChunkIndex) >= len(file.Chunks) {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to complete append to %s: chunk %d out of bounds", req.Filename, req.ChunkIndex)
+	}
+
+	file, _, err = s.metadata.ExtendFile(req.Filename, int64(req.ChunkIndex)*common.ChunkSize+req.EndOffset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extend file %s: %v", req.Filename, err)
+	}
+
+	return &pb.CompleteAppendResponse{
+		Filesize: file.Filesize,
+	}, nil
+}
@@ -0,0 +1,624 @@
+package master
+
+import (
+	"bytes"
+	"cmp"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/harshvardha/distributed_file_system/common"
+	pb "github.com/harshvardha/distributed_file_system/proto"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+const (
+	// archivalInterval is how often the master looks for files to archive
+	archivalInterval = 10 * time.Minute
+
+	// maxArchivalsPerRound is the number of files archived per interval, so a policy matching many files
+	// doesn't swamp the chunk servers
+	maxArchivalsPerRound = 8
+
+	// DefaultArchiveTier is the tier archived chunks are placed on unless the policy names another
+	DefaultArchiveTier = "archive"
+
+	// recallRetryDelay is how long readers of an archived file are told to wait before trying again
+	recallRetryDelay = 2 * time.Second
+
+	// recallTimeout bounds how long a chunk server may take to recall an archived file
+	recallTimeout = 30 * time.Minute
+)
+
+// ArchivalPolicy selects the files the master archives: their contents are compressed into new chunks placed
+// on the archive tier, and recalled to their own tier when read again
+type ArchivalPolicy struct {
+	After    time.Duration // files not read for this long are archived, 0 only archives files of the archive storage class
+	Prefixes []string      // only files under these prefixes are archived, all files when empty
+	Tier     string        // tier of the chunk servers holding archived chunks, DefaultArchiveTier when empty
+}
+
+// selects reports whether the policy archives the file once it has been idle long enough
+func (p ArchivalPolicy) selects(file *FileMetadata) bool {
+	if len(p.Prefixes) == 0 {
+		return true
+	}
+
+	return slices.ContainsFunc(p.Prefixes, func(prefix string) bool {
+		return strings.HasPrefix(file.Filename, prefix)
+	})
+}
+
+// archived reports whether the current contents of the file are archived
+func (f *FileMetadata) archived() bool {
+	return f.ArchivedSize > 0
+}
+
+// archivable reports whether the current contents of the file can be archived. Inline files and symlinks
+// have no chunks to move, hard linked files share theirs with their other names
+func (f *FileMetadata) archivable() bool {
+	return !f.archived() && !f.isSymlink() && f.Data == nil && len(f.Chunks) > 0 && f.Inode == ""
+}
+
+// archivedChunks returns the chunk handles of the archived versions of the file, current or previous
+func (f *FileMetadata) archivedChunks() []string {
+	var handles []string
+	if f.archived() {
+		handles = append(handles, f.Chunks...)
+	}
+	for _, version := range f.Versions {
+		if version.ArchivedSize > 0 {
+			handles = append(handles, version.Chunks...)
+		}
+	}
+
+	return handles
+}
+
+// RewriteChunks swaps the chunks of the version of filename with the given generation for chunks holding the
+// same contents in another form, archived as archivedSize compressed bytes or not archived when 0. It is only
+// done if the version still has the previous chunks and filesize, returning false otherwise, and returns the
+// replaced chunks, whose replicas the caller is responsible for deleting
+func (m *Metadata) RewriteChunks(filename string, generation, filesize int64, previous, chunks []string, archivedSize int64) ([]*ChunkMetadata, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var replaced []*ChunkMetadata
+	rewritten := false
+	err := m.update(func(tx *metadataTx) error {
+		file, exists, err := tx.GetFile(filename)
+		if err != nil || !exists {
+			return err
+		}
+
+		if file.Generation == generation {
+			if file.Filesize != filesize || !slices.Equal(file.Chunks, previous) {
+				return nil
+			}
+			file.Chunks = slices.Clone(chunks)
+			file.ChunkCount = len(chunks)
+			file.ArchivedSize = archivedSize
+		} else {
+			i := slices.IndexFunc(file.Versions, func(version FileVersion) bool { return version.Generation == generation })
+			if i < 0 || file.Versions[i].Filesize != filesize || !slices.Equal(file.Versions[i].Chunks, previous) {
+				return nil
+			}
+			file.Versions[i].Chunks = slices.Clone(chunks)
+			file.Versions[i].ChunkCount = len(chunks)
+			file.Versions[i].ArchivedSize = archivedSize
+		}
+
+		if err := tx.PutFile(file); err != nil {
+			return err
+		}
+
+		rewritten = true
+		replaced, err = tx.removeChunks(previous)
+		return err
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	return replaced, rewritten, nil
+}
+
+// DiscardChunks removes chunks written for a rewrite that didn't happen, returning them so the caller
+// deletes their replicas
+func (m *Metadata) DiscardChunks(chunkHandles []string) ([]*ChunkMetadata, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var chunks []*ChunkMetadata
+	err := m.update(func(tx *metadataTx) error {
+		var err error
+		chunks, err = tx.removeChunks(chunkHandles)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return chunks, nil
+}
+
+// startArchival periodically archives the files of the archive storage class and the files the archival
+// policy selects that weren't read for long enough
+func (s *Server) startArchival() {
+	ticker := time.NewTicker(archivalInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+		}
+
+		s.archiveIdleFiles()
+	}
+}
+
+// archiveIdleFiles archives up to maxArchivalsPerRound idle files, largest first
+func (s *Server) archiveIdleFiles() {
+	// files of the archive class are archived once they weren't read for a round, rather than recalled
+	// and archived again while they are being used
+	idle := archivalInterval
+	if s.archival.After > 0 {
+		idle = min(idle, s.archival.After)
+	}
+	files, err := s.metadata.UnaccessedFiles(idle, 0)
+	if err != nil {
+		log.Printf("Warning: failed to look for files to archive: %v", err)
+		return
+	}
+
+	now := time.Now()
+	archived := 0
+	for _, file := range files {
+		if archived >= maxArchivalsPerRound || s.stopped() {
+			return
+		}
+		if !s.dueForArchival(file, now) || s.uploads.InProgress(file.Filename) {
+			continue
+		}
+
+		done, err := s.archiveFile(file)
+		if err != nil {
+			log.Printf("Warning: failed to archive %s: %v", file.Filename, err)
+			continue
+		}
+		if done {
+			archived++
+		}
+	}
+}
+
+// dueForArchival reports whether an idle file is to be archived: files of the archive class always, others
+// once the policy selects them and they weren't read for the policy's idle time
+func (s *Server) dueForArchival(file *FileMetadata, now time.Time) bool {
+	if !file.archivable() {
+		return false
+	}
+	if file.storageClass() == StorageClassArchive {
+		return true
+	}
+
+	return s.archival.After > 0 && now.Sub(file.lastActivity()) >= s.archival.After && s.archival.selects(file)
+}
+
+// archiveFile compresses the current contents of the file into new chunks on the archive tier and swaps them
+// for its chunks, returning false if the file changed meanwhile or shares chunks with a clone
+func (s *Server) archiveFile(file *FileMetadata) (bool, error) {
+	for _, chunkHandle := range file.Chunks {
+		chunk, exists, err := s.metadata.GetChunk(chunkHandle)
+		if err != nil {
+			return false, fmt.Errorf("failed to look up chunk %s: %v", chunkHandle, err)
+		}
+		if !exists || chunk.shared() {
+			return false, nil
+		}
+	}
+
+	// the chunks are read and written without holding the file's lock, so readers aren't held up, the swap
+	// only happens if the file didn't change meanwhile
+	sink := &chunkSink{server: s, file: file, tier: s.archival.Tier}
+	compressor, err := gzip.NewWriterLevel(sink, gzip.BestCompression)
+	if err != nil {
+		return false, err
+	}
+	if _, err = io.Copy(compressor, &chunkSource{server: s, chunks: file.Chunks, size: file.Filesize}); err == nil {
+		if err = compressor.Close(); err == nil {
+			err = sink.flush()
+		}
+	}
+	if err != nil {
+		s.discardChunks(sink.chunks)
+		return false, err
+	}
+
+	// a storage class changed meanwhile is converted by the request that changed it
+	unlock := s.locks.Lock(file.Filename)
+	current, exists, err := s.metadata.GetFile(file.Filename)
+	applied := false
+	var replaced []*ChunkMetadata
+	if err == nil && exists && current.storageClass() == file.storageClass() {
+		replaced, applied, err = s.metadata.RewriteChunks(file.Filename, file.Generation, file.Filesize, file.Chunks, sink.chunks, sink.written)
+	}
+	unlock()
+	if err != nil || !applied {
+		s.discardChunks(sink.chunks)
+		return false, err
+	}
+	s.deleteChunks(replaced)
+
+	log.Printf("Archived %s: %d bytes compressed to %d in %d chunks on tier %s", file.Filename, file.Filesize, sink.written, len(sink.chunks), s.archival.Tier)
+	return true, nil
+}
+
+// checkRecalled returns nil unless the version of the file with the given generation is archived. An archived
+// version is recalled by a chunk server in the background, meanwhile a retryable Unavailable error tells the
+// caller when to try again
+func (s *Server) checkRecalled(file *FileMetadata, generation int64) error {
+	version, exists := file.version(generation)
+	if !exists || version.ArchivedSize == 0 {
+		return nil
+	}
+
+	s.startRecall(file.Filename, version)
+
+	recalling := status.Newf(codes.Unavailable, "%s is archived and being recalled, try again shortly", file.Filename)
+	if detailed, err := recalling.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(recallRetryDelay)}); err == nil {
+		recalling = detailed
+	}
+	return recalling.Err()
+}
+
+// startRecall recalls an archived version of a file in the background, unless it is being recalled already
+func (s *Server) startRecall(filename string, version FileVersion) {
+	key := fmt.Sprintf("%s@%d", filename, version.Generation)
+
+	s.recallMu.Lock()
+	defer s.recallMu.Unlock()
+	if s.recalling[key] {
+		return
+	}
+	s.recalling[key] = true
+
+	s.goBackground(func() {
+		defer func() {
+			s.recallMu.Lock()
+			delete(s.recalling, key)
+			s.recallMu.Unlock()
+		}()
+
+		if err := s.recallVersion(filename, version); err != nil && !s.stopped() {
+			log.Printf("Warning: failed to recall archived %s generation %d: %v", filename, version.Generation, err)
+		}
+	})
+}
+
+// recallVersion has a chunk server decompress an archived version of the file into new chunks on the file's
+// tier, and swaps them for the archived chunks if the version didn't change meanwhile
+func (s *Server) recallVersion(filename string, version FileVersion) error {
+	file, exists, err := s.metadata.GetFile(filename)
+	if err != nil {
+		return fmt.Errorf("failed to look up file %s: %v", filename, err)
+	}
+	if !exists {
+		return nil
+	}
+
+	started := time.Now()
+	request := &pb.RecallChunksRequest{
+		ArchivedSize: version.ArchivedSize,
+		Filesize:     version.Filesize,
+	}
+	for _, chunkHandle := range version.Chunks {
+		chunk, exists, err := s.metadata.GetChunk(chunkHandle)
+		if err != nil {
+			return fmt.Errorf("failed to look up chunk %s: %v", chunkHandle, err)
+		}
+		if !exists {
+			return fmt.Errorf("chunk not found: %s", chunkHandle)
+		}
+		request.ArchivedChunks = append(request.ArchivedChunks, &pb.ArchivedChunk{
+			ChunkHandle: chunkHandle,
+			Addresses:   s.metadata.ReadReplicas(chunk.Locations),
+			AccessToken: s.tokens.Sign(chunkHandle, common.ChunkRead),
+		})
+	}
+
+	// the chunks are added to the metadata before they are written, so a failed recall can discard them
+	var chunks []string
+	recaller := ""
+	for i := range common.CalculateNumChunks(version.Filesize) {
+		servers, err := s.metadata.PlaceChunk(file.replicationFactor(), PlacementHints{Tier: file.Tier})
+		if err != nil || len(servers) == 0 {
+			s.discardChunks(chunks)
+			return fmt.Errorf("failed to place chunk %d: %v", i, cmp.Or(err, errors.New("no chunk servers available")))
+		}
+		chunk, err := s.metadata.AddChunkCopy(filename, &ChunkMetadata{
+			ChunkIndex:        int32(i),
+			ReplicationFactor: file.ReplicationFactor,
+			Tier:              file.Tier,
+		})
+		if err != nil {
+			s.discardChunks(chunks)
+			return fmt.Errorf("failed to add chunk %d: %v", i, err)
+		}
+		chunks = append(chunks, chunk.ChunkHandle)
+
+		request.Chunks = append(request.Chunks, &pb.RecalledChunk{
+			ChunkHandle:  chunk.ChunkHandle,
+			ChunkVersion: chunk.Version,
+			Addresses:    servers,
+			AccessToken:  s.tokens.Sign(chunk.ChunkHandle, common.ChunkWrite),
+		})
+		if recaller == "" {
+			recaller = servers[0]
+		}
+	}
+
+	if recaller != "" {
+		response, err := s.recallOnServer(recaller, request)
+		if err == nil {
+			err = s.addRecalledLocations(response)
+		}
+		if err != nil {
+			s.discardChunks(chunks)
+			return err
+		}
+	}
+
+	unlock := s.locks.Lock(filename)
+	replaced, applied, err := s.metadata.RewriteChunks(filename, version.Generation, version.Filesize, version.Chunks, chunks, 0)
+	unlock()
+	if err != nil || !applied {
+		s.discardChunks(chunks)
+		if err == nil {
+			err = fmt.Errorf("version %d changed while being recalled", version.Generation)
+		}
+		return err
+	}
+	s.deleteChunks(replaced)
+
+	log.Printf("Recalled archived %s generation %d, %d bytes on %s in %v", filename, version.Generation, version.Filesize, recaller, time.Since(started).Round(time.Millisecond))
+	return nil
+}
+
+// recallOnServer sends a recall to the chunk server decompressing the archive
+func (s *Server) recallOnServer(serverAddr string, request *pb.RecallChunksRequest) (*pb.RecallChunksResponse, error) {
+	conn, err := grpc.NewClient(serverAddr, s.conn.DialOptions()...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to chunk server %s: %v", serverAddr, err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(s.ctx, recallTimeout)
+	defer cancel()
+
+	response, err := pb.NewChunkServerClient(conn).RecallChunks(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("chunk server %s failed to recall: %v", serverAddr, err)
+	}
+
+	return response, nil
+}
+
+// addRecalledLocations records the servers the recalled chunks were written to
+func (s *Server) addRecalledLocations(response *pb.RecallChunksResponse) error {
+	for _, chunk := range response.Chunks {
+		for _, serverAddr := range chunk.Addresses {
+			if err := s.metadata.AddChunkLocation(chunk.ChunkHandle, serverAddr); err != nil {
+				return fmt.Errorf("failed to add location of chunk %s: %v", chunk.ChunkHandle, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// discardChunks removes chunks written for an archival or recall that didn't happen, along with their replicas
+func (s *Server) discardChunks(chunkHandles []string) {
+	chunks, err := s.metadata.DiscardChunks(chunkHandles)
+	if err != nil {
+		log.Printf("Warning: failed to discard %d chunks: %v", len(chunkHandles), err)
+	}
+	s.reclaimChunks(chunks)
+}
+
+// chunkSource reads the chunks of a file version one after the other, cut to the version's size
+type chunkSource struct {
+	server  *Server
+	chunks  []string
+	size    int64 // bytes stored in the chunks
+	index   int   // next chunk to read
+	pending []byte
+}
+
+func (r *chunkSource) Read(p []byte) (int, error) {
+	for len(r.pending) == 0 {
+		if r.index >= len(r.chunks) {
+			return 0, io.EOF
+		}
+
+		data, err := r.server.readChunk(r.chunks[r.index])
+		if err != nil {
+			return 0, err
+		}
+		length := common.ChunkLength(r.size, r.index)
+		if int64(len(data)) < length {
+			return 0, fmt.Errorf("chunk %s holds %d bytes, expected %d", r.chunks[r.index], len(data), length)
+		}
+
+		r.pending = data[:length]
+		r.index++
+	}
+
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+// chunkSink writes the data written to it into new chunks of a file on a tier, a chunk each time
+// common.ChunkSize bytes were written. flush writes the last, partial chunk
+type chunkSink struct {
+	server  *Server
+	file    *FileMetadata
+	tier    string
+	buffer  []byte
+	chunks  []string // handles of the chunks added, including one that failed to be written
+	written int64
+}
+
+func (w *chunkSink) Write(p []byte) (int, error) {
+	n := 0
+	for len(p) > 0 {
+		take := min(len(p), common.ChunkSize-len(w.buffer))
+		w.buffer = append(w.buffer, p[:take]...)
+		p = p[take:]
+		n += take
+
+		if len(w.buffer) == common.ChunkSize {
+			if err := w.flush(); err != nil {
+				return n, err
+			}
+		}
+	}
+
+	return n, nil
+}
+
+func (w *chunkSink) flush() error {
+	if len(w.buffer) == 0 {
+		return nil
+	}
+
+	err := w.server.writeNewChunk(w.file, len(w.chunks), w.buffer, w.tier, func(chunkHandle string) {
+		w.chunks = append(w.chunks, chunkHandle)
+	})
+	if err != nil {
+		return err
+	}
+
+	w.written += int64(len(w.buffer))
+	w.buffer = w.buffer[:0]
+	return nil
+}
+
+// writeNewChunk writes data as chunk index of the file on servers of the tier, under a handle never used before
+// that is passed to added as soon as the chunk is in the metadata
+func (s *Server) writeNewChunk(file *FileMetadata, index int, data []byte, tier string, added func(string)) error {
+	servers, err := s.metadata.PlaceChunk(file.replicationFactor(), PlacementHints{Tier: tier})
+	if err != nil {
+		return fmt.Errorf("failed to place chunk %d of %s: %v", index, file.Filename, err)
+	}
+	if len(servers) == 0 {
+		return status.Errorf(codes.ResourceExhausted, "failed to place chunk %d of %s: no chunk servers available", index, file.Filename)
+	}
+
+	chunk, err := s.metadata.AddChunkCopy(file.Filename, &ChunkMetadata{
+		ChunkIndex:        int32(index),
+		ReplicationFactor: file.ReplicationFactor,
+		Tier:              tier,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add chunk %d of %s: %v", index, file.Filename, err)
+	}
+	added(chunk.ChunkHandle)
+
+	written := 0
+	for _, serverAddr := range servers {
+		if err := s.writeChunkOnServer(serverAddr, chunk.ChunkHandle, chunk.Version, int32(index), data); err != nil {
+			log.Printf("Warning: failed to write chunk %d of %s to %s: %v", index, file.Filename, serverAddr, err)
+			continue
+		}
+		if err := s.metadata.AddChunkLocation(chunk.ChunkHandle, serverAddr); err != nil {
+			return fmt.Errorf("failed to add location of chunk %s: %v", chunk.ChunkHandle, err)
+		}
+		written++
+	}
+	if written == 0 {
+		return fmt.Errorf("failed to write chunk %d of %s on any server", index, file.Filename)
+	}
+
+	return nil
+}
+
+// readChunk reads a whole chunk from the first of its replicas that returns it intact
+func (s *Server) readChunk(chunkHandle string) ([]byte, error) {
+	chunk, exists, err := s.metadata.GetChunk(chunkHandle)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up chunk %s: %v", chunkHandle, err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("chunk not found: %s", chunkHandle)
+	}
+
+	lastErr := fmt.Errorf("chunk %s has no replicas", chunkHandle)
+	for _, serverAddr := range s.metadata.ReadReplicas(chunk.Locations) {
+		data, err := s.readChunkFromServer(serverAddr, chunkHandle)
+		if err != nil {
+			log.Printf("Warning: failed to read chunk %s from %s: %v", chunkHandle, serverAddr, err)
+			lastErr = err
+			continue
+		}
+		return data, nil
+	}
+
+	return nil, lastErr
+}
+
+// readChunkFromServer reads a whole chunk from a single chunk server, checking the checksum it sends
+func (s *Server) readChunkFromServer(serverAddr, chunkHandle string) ([]byte, error) {
+	conn, err := grpc.NewClient(serverAddr, s.conn.DialOptions()...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to chunk server %s: %v", serverAddr, err)
+	}
+	defer conn.Close()
+
+	chunkClient := pb.NewChunkServerClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	stream, err := chunkClient.ReadChunkStream(ctx, &pb.ReadChunkRequest{
+		ChunkHandle: chunkHandle,
+		AccessToken: s.tokens.Sign(chunkHandle, common.ChunkRead),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		data bytes.Buffer
+		sum  uint32
+	)
+	for {
+		response, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		data.Write(response.Data)
+		sum = common.UpdateChecksum(sum, response.Data)
+
+		if response.Checksum != nil && *response.Checksum != sum {
+			return nil, fmt.Errorf("data read from %s doesn't match the checksum it sent", serverAddr)
+		}
+	}
+
+	return data.Bytes(), nil
+}
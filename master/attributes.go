@@ -0,0 +1,261 @@
+package master
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/harshvardha/distributed_file_system/common"
+	pb "github.com/harshvardha/distributed_file_system/proto"
+)
+
+// maxReplicationFactor is the highest replication factor a file may ask for
+const maxReplicationFactor = 10
+
+// fileExpiryInterval is how often the master deletes files whose time to live ran out
+const fileExpiryInterval = time.Minute
+
+// AttributeUpdate lists changes to the mutable attributes of a file, nil and empty fields are left unchanged
+type AttributeUpdate struct {
+	SetTags           map[string]string
+	RemoveTags        []string
+	ExpiresAt         *time.Time // zero time removes the expiry
+	ReplicationFactor *int       // 0 restores common.ReplicationFactor
+	Tier              *string    // empty places the file's chunks on any tier
+	StorageClass      *string    // converted in background, empty restores the standard class
+}
+
+// validate checks the update before it is applied
+func (u AttributeUpdate) validate() error {
+	if err := validateTags(u.SetTags); err != nil {
+		return err
+	}
+
+	if u.ReplicationFactor != nil && (*u.ReplicationFactor < 0 || *u.ReplicationFactor > maxReplicationFactor) {
+		return fmt.Errorf("replication factor must be between 1 and %d, or 0 for the default", maxReplicationFactor)
+	}
+	if u.Tier != nil {
+		if err := validateTier(*u.Tier); err != nil {
+			return err
+		}
+	}
+	if u.StorageClass != nil {
+		if err := validateStorageClass(*u.StorageClass); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// UpdateAttributes applies the update to a file and returns the updated file, false if it doesn't exist.
+// A new replication factor or tier applies to the chunks of every version of the file, the caller is
+// responsible for adding or removing replicas to match the replication factor, replicas on another tier
+// are moved by the tier migration
+func (m *Metadata) UpdateAttributes(filename string, update AttributeUpdate) (*FileMetadata, bool, error) {
+	if err := update.validate(); err != nil {
+		return nil, false, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var file *FileMetadata
+	exists := false
+	err := m.update(func(tx *metadataTx) error {
+		var err error
+		file, exists, err = tx.GetFile(filename)
+		if err != nil || !exists {
+			return err
+		}
+
+		return tx.updateAttributes(file, update)
+	})
+	if err != nil || !exists {
+		return nil, exists, err
+	}
+
+	return file, true, nil
+}
+
+// updateAttributes applies the update to file and writes it and its chunks
+func (tx *metadataTx) updateAttributes(file *FileMetadata, update AttributeUpdate) error {
+	if err := file.applyAttributes(update); err != nil {
+		return err
+	}
+
+	if err := tx.PutFile(file); err != nil {
+		return err
+	}
+
+	if update.Tier != nil {
+		// archived chunks stay on the archive tier, the chunks a recall writes go to the file's tier
+		archived := file.archivedChunks()
+		for _, chunkHandle := range file.chunkHandles() {
+			chunk, exists, err := tx.GetChunk(chunkHandle)
+			if err != nil {
+				return err
+			}
+			if !exists || chunk.Tier == file.Tier || slices.Contains(archived, chunkHandle) {
+				continue
+			}
+
+			// a chunk shared with a clone follows the file that changed its tier last
+			chunk.Tier = file.Tier
+			if err := tx.PutChunk(chunk); err != nil {
+				return err
+			}
+		}
+	}
+
+	if update.ReplicationFactor != nil {
+		for _, chunkHandle := range file.chunkHandles() {
+			chunk, exists, err := tx.GetChunk(chunkHandle)
+			if err != nil {
+				return err
+			}
+			if !exists || chunk.ReplicationFactor == file.ReplicationFactor {
+				continue
+			}
+			// a chunk shared with a clone keeps the most replicas any of its files asks for
+			if chunk.shared() && file.replicationFactor() < chunk.replicationFactor() {
+				continue
+			}
+
+			chunk.ReplicationFactor = file.ReplicationFactor
+			if err := tx.PutChunk(chunk); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// applyAttributes applies the update to the fields of file without writing it
+func (f *FileMetadata) applyAttributes(update AttributeUpdate) error {
+	if len(update.SetTags) > 0 || len(update.RemoveTags) > 0 {
+		if err := f.updateTags(update.SetTags, update.RemoveTags); err != nil {
+			return err
+		}
+	}
+	if update.ExpiresAt != nil {
+		f.ExpiresAt = *update.ExpiresAt
+	}
+	if update.ReplicationFactor != nil {
+		f.ReplicationFactor = *update.ReplicationFactor
+	}
+	if update.Tier != nil {
+		f.Tier = *update.Tier
+	}
+	if update.StorageClass != nil {
+		f.StorageClass = *update.StorageClass
+	}
+
+	return nil
+}
+
+// ExpiredFiles returns the names of the files whose time to live ran out by now
+func (m *Metadata) ExpiredFiles(now time.Time) ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	expired := make([]string, 0)
+	err := m.store.ForEachFile("", func(file *FileMetadata) error {
+		if file.expired(now) {
+			expired = append(expired, file.Filename)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return expired, nil
+}
+
+// expired reports whether the file's time to live ran out by now
+func (f *FileMetadata) expired(now time.Time) bool {
+	return !f.ExpiresAt.IsZero() && !now.Before(f.ExpiresAt)
+}
+
+// startFileExpiry periodically deletes files whose time to live ran out. Files still under
+// retention are kept until their retention ends
+func (s *Server) startFileExpiry() {
+	ticker := time.NewTicker(fileExpiryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+		}
+
+		expired, err := s.metadata.ExpiredFiles(time.Now())
+		if err != nil {
+			log.Printf("Warning: failed to look for expired files: %v", err)
+			continue
+		}
+
+		for _, filename := range expired {
+			s.expireFile(filename)
+		}
+	}
+}
+
+// expireFile deletes a file whose time to live ran out, unless it changed since it was found expired
+func (s *Server) expireFile(filename string) {
+	unlock := s.locks.Lock(filename)
+	defer unlock()
+
+	now := time.Now()
+	file, exists, err := s.metadata.GetFile(filename)
+	if err != nil {
+		log.Printf("Warning: failed to look up expired file %s: %v", filename, err)
+		return
+	}
+	if !exists || !file.expired(now) || file.retained(now) {
+		return
+	}
+
+	log.Printf("File %s expired at %s, deleting it", filename, file.ExpiresAt.Format(time.DateTime))
+
+	deleted, chunks, _, err := s.metadata.DeleteFile(filename)
+	if err != nil {
+		log.Printf("Warning: failed to delete expired file %s: %v", filename, err)
+		return
+	}
+	s.goBackground(func() { s.deleteFileChunks(deleted, chunks) })
+
+	s.events.Publish(pb.FileEventType_FILE_EVENT_DELETED, filename, "", 0)
+}
+
+// toFileAttributes converts the mutable attributes of a file to their protobuf representation
+func toFileAttributes(file *FileMetadata) *pb.FileAttributes {
+	attributes := &pb.FileAttributes{
+		Tags:              file.Tags,
+		ReplicationFactor: int32(file.replicationFactor()),
+		Immutable:         file.Immutable,
+		StorageTier:       file.Tier,
+		StorageClass:      file.storageClass(),
+	}
+	if !file.ExpiresAt.IsZero() {
+		attributes.ExpiresAt = file.ExpiresAt.Unix()
+	}
+	if !file.RetainUntil.IsZero() {
+		attributes.RetainUntil = file.RetainUntil.Unix()
+	}
+
+	return attributes
+}
+
+// replicationFactor returns the number of replicas the file's chunks should have
+func (f *FileMetadata) replicationFactor() int {
+	if f.ReplicationFactor > 0 {
+		return f.ReplicationFactor
+	}
+
+	return common.ReplicationFactor
+}
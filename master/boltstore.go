@@ -0,0 +1,328 @@
+package master
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// buckets of the bolt metadata file, records are stored as json
+var (
+	filesBucket    = []byte("files")    // key: filename, value: file metadata
+	inodesBucket   = []byte("inodes")   // key: inode id, value: names, owner and mode shared by hard links
+	chunksBucket   = []byte("chunks")   // key: chunk handle, value: chunk metadata
+	versionsBucket = []byte("versions") // key: chunk handle, value: latest version handed out as a big endian uint32
+
+	tombstonesBucket = []byte("tombstones")     // key: tombstone id, value: deleted chunks waiting to be reclaimed
+	revokedBucket    = []byte("revoked-tokens") // key: delegation token id, value: revocation of the token
+)
+
+// BoltStore is a MetadataStore backed by a BoltDB file. Records are read from the memory mapped file
+// on demand, so the namespace may be larger than memory and opening it doesn't load anything
+type BoltStore struct {
+	db *bolt.DB
+}
+
+// OpenBoltStore opens the bolt metadata file at path, creating it if it doesn't exist
+func OpenBoltStore(path string) (*BoltStore, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open metadata file %s: %v", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range [][]byte{filesBucket, inodesBucket, chunksBucket, versionsBucket, tombstonesBucket, revokedBucket} {
+			if _, err := tx.CreateBucketIfNotExists(bucket); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create metadata buckets: %v", err)
+	}
+
+	return &BoltStore{db: db}, nil
+}
+
+// get decodes the record stored under key in bucket into value, returning false if there is none
+func get(tx *bolt.Tx, bucket []byte, key string, value any) (bool, error) {
+	data := tx.Bucket(bucket).Get([]byte(key))
+	if data == nil {
+		return false, nil
+	}
+
+	return true, json.Unmarshal(data, value)
+}
+
+// put encodes value and stores it under key in bucket
+func put(tx *bolt.Tx, bucket []byte, key string, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return tx.Bucket(bucket).Put([]byte(key), data)
+}
+
+// view runs fn in a read only transaction
+func (s *BoltStore) view(fn func(tx *boltTx) error) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		return fn(&boltTx{tx: tx})
+	})
+}
+
+// GetFile implements MetadataStore
+func (s *BoltStore) GetFile(filename string) (file *FileMetadata, exists bool, err error) {
+	err = s.view(func(tx *boltTx) error {
+		file, exists, err = tx.GetFile(filename)
+		return err
+	})
+
+	return file, exists, err
+}
+
+// ForEachFile implements MetadataStore
+func (s *BoltStore) ForEachFile(prefix string, fn func(file *FileMetadata) error) error {
+	return s.ForEachFileAfter(prefix, "", fn)
+}
+
+// ForEachFileAfter implements MetadataStore
+func (s *BoltStore) ForEachFileAfter(prefix, after string, fn func(file *FileMetadata) error) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(filesBucket).Cursor()
+		for key, data := cursor.Seek([]byte(max(prefix, after))); key != nil && bytes.HasPrefix(key, []byte(prefix)); key, data = cursor.Next() {
+			if string(key) == after {
+				continue
+			}
+
+			file := &FileMetadata{}
+			if err := json.Unmarshal(data, file); err != nil {
+				return fmt.Errorf("failed to decode file %s: %v", key, err)
+			}
+
+			if err := fn(file); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetInode implements MetadataStore
+func (s *BoltStore) GetInode(id string) (inode *Inode, exists bool, err error) {
+	err = s.view(func(tx *boltTx) error {
+		inode, exists, err = tx.GetInode(id)
+		return err
+	})
+
+	return inode, exists, err
+}
+
+// GetChunk implements MetadataStore
+func (s *BoltStore) GetChunk(chunkHandle string) (chunk *ChunkMetadata, exists bool, err error) {
+	err = s.view(func(tx *boltTx) error {
+		chunk, exists, err = tx.GetChunk(chunkHandle)
+		return err
+	})
+
+	return chunk, exists, err
+}
+
+// ForEachChunk implements MetadataStore
+func (s *BoltStore) ForEachChunk(fn func(chunk *ChunkMetadata) error) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(chunksBucket).ForEach(func(key, data []byte) error {
+			chunk := &ChunkMetadata{}
+			if err := json.Unmarshal(data, chunk); err != nil {
+				return fmt.Errorf("failed to decode chunk %s: %v", key, err)
+			}
+
+			return fn(chunk)
+		})
+	})
+}
+
+// GetChunkVersion implements MetadataStore
+func (s *BoltStore) GetChunkVersion(chunkHandle string) (version int32, err error) {
+	err = s.view(func(tx *boltTx) error {
+		version, err = tx.GetChunkVersion(chunkHandle)
+		return err
+	})
+
+	return version, err
+}
+
+// ForEachTombstone implements MetadataStore
+func (s *BoltStore) ForEachTombstone(fn func(tombstone *Tombstone) error) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(tombstonesBucket).ForEach(func(key, data []byte) error {
+			tombstone := &Tombstone{}
+			if err := json.Unmarshal(data, tombstone); err != nil {
+				return fmt.Errorf("failed to decode tombstone %s: %v", key, err)
+			}
+
+			return fn(tombstone)
+		})
+	})
+}
+
+// GetRevokedToken implements MetadataStore
+func (s *BoltStore) GetRevokedToken(id string) (*RevokedToken, bool, error) {
+	revoked := &RevokedToken{}
+	exists := false
+	err := s.db.View(func(tx *bolt.Tx) error {
+		var err error
+		exists, err = get(tx, revokedBucket, id, revoked)
+		return err
+	})
+	if err != nil || !exists {
+		return nil, false, err
+	}
+
+	return revoked, true, nil
+}
+
+// ForEachRevokedToken implements MetadataStore
+func (s *BoltStore) ForEachRevokedToken(fn func(revoked *RevokedToken) error) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(revokedBucket).ForEach(func(key, data []byte) error {
+			revoked := &RevokedToken{}
+			if err := json.Unmarshal(data, revoked); err != nil {
+				return fmt.Errorf("failed to decode revoked token %s: %v", key, err)
+			}
+
+			return fn(revoked)
+		})
+	})
+}
+
+// Update implements MetadataStore, running fn in a single bolt transaction
+func (s *BoltStore) Update(fn func(tx MetadataTx) error) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return fn(&boltTx{tx: tx})
+	})
+}
+
+// boltTx is a transaction of a BoltStore
+type boltTx struct {
+	tx *bolt.Tx
+}
+
+// GetFile implements MetadataTx
+func (tx *boltTx) GetFile(filename string) (*FileMetadata, bool, error) {
+	file := &FileMetadata{}
+	exists, err := get(tx.tx, filesBucket, filename, file)
+	if err != nil || !exists {
+		return nil, false, err
+	}
+
+	return file, true, nil
+}
+
+// PutFile implements MetadataTx
+func (tx *boltTx) PutFile(file *FileMetadata) error {
+	return put(tx.tx, filesBucket, file.Filename, file)
+}
+
+// DeleteFile implements MetadataTx
+func (tx *boltTx) DeleteFile(filename string) error {
+	return tx.tx.Bucket(filesBucket).Delete([]byte(filename))
+}
+
+// GetInode implements MetadataTx
+func (tx *boltTx) GetInode(id string) (*Inode, bool, error) {
+	inode := &Inode{}
+	exists, err := get(tx.tx, inodesBucket, id, inode)
+	if err != nil || !exists {
+		return nil, false, err
+	}
+
+	return inode, true, nil
+}
+
+// PutInode implements MetadataTx
+func (tx *boltTx) PutInode(inode *Inode) error {
+	return put(tx.tx, inodesBucket, inode.ID, inode)
+}
+
+// DeleteInode implements MetadataTx
+func (tx *boltTx) DeleteInode(id string) error {
+	return tx.tx.Bucket(inodesBucket).Delete([]byte(id))
+}
+
+// GetChunk implements MetadataTx
+func (tx *boltTx) GetChunk(chunkHandle string) (*ChunkMetadata, bool, error) {
+	chunk := &ChunkMetadata{}
+	exists, err := get(tx.tx, chunksBucket, chunkHandle, chunk)
+	if err != nil || !exists {
+		return nil, false, err
+	}
+
+	return chunk, true, nil
+}
+
+// PutChunk implements MetadataTx
+func (tx *boltTx) PutChunk(chunk *ChunkMetadata) error {
+	return put(tx.tx, chunksBucket, chunk.ChunkHandle, chunk)
+}
+
+// DeleteChunk implements MetadataTx
+func (tx *boltTx) DeleteChunk(chunkHandle string) error {
+	return tx.tx.Bucket(chunksBucket).Delete([]byte(chunkHandle))
+}
+
+// GetChunkVersion implements MetadataTx
+func (tx *boltTx) GetChunkVersion(chunkHandle string) (int32, error) {
+	if data := tx.tx.Bucket(versionsBucket).Get([]byte(chunkHandle)); len(data) == 4 {
+		return int32(binary.BigEndian.Uint32(data)), nil
+	}
+
+	return 0, nil
+}
+
+// PutChunkVersion implements MetadataTx
+func (tx *boltTx) PutChunkVersion(chunkHandle string, version int32) error {
+	return tx.tx.Bucket(versionsBucket).Put([]byte(chunkHandle), binary.BigEndian.AppendUint32(nil, uint32(version)))
+}
+
+// GetTombstone implements MetadataTx
+func (tx *boltTx) GetTombstone(id string) (*Tombstone, bool, error) {
+	tombstone := &Tombstone{}
+	exists, err := get(tx.tx, tombstonesBucket, id, tombstone)
+	if err != nil || !exists {
+		return nil, false, err
+	}
+
+	return tombstone, true, nil
+}
+
+// PutTombstone implements MetadataTx
+func (tx *boltTx) PutTombstone(tombstone *Tombstone) error {
+	return put(tx.tx, tombstonesBucket, tombstone.ID, tombstone)
+}
+
+// DeleteTombstone implements MetadataTx
+func (tx *boltTx) DeleteTombstone(id string) error {
+	return tx.tx.Bucket(tombstonesBucket).Delete([]byte(id))
+}
+
+// PutRevokedToken implements MetadataTx
+func (tx *boltTx) PutRevokedToken(revoked *RevokedToken) error {
+	return put(tx.tx, revokedBucket, revoked.ID, revoked)
+}
+
+// DeleteRevokedToken implements MetadataTx
+func (tx *boltTx) DeleteRevokedToken(id string) error {
+	return tx.tx.Bucket(revokedBucket).Delete([]byte(id))
+}
+
+// Close implements MetadataStore
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,251 @@
+package master
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/harshvardha/distributed_file_system/common"
+	pb "github.com/harshvardha/distributed_file_system/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// shared reports whether more than one file version uses the chunk, so it must be copied before it is modified
+func (c *ChunkMetadata) shared() bool {
+	return c.References > 1
+}
+
+// CloneFile adds a file named destination sharing the current chunks of source, replacing a file already using
+// the name like AddFile does. It returns the clone, false if the source doesn't exist, and the chunks of the
+// replaced versions that weren't kept, whose replicas the caller is responsible for deleting. A new clone gets the
+// ownership like the files AddFile adds
+func (m *Metadata) CloneFile(source, destination string, keepVersions int, ownership FileOwnership) (*FileMetadata, bool, []*ChunkMetadata, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var clone *FileMetadata
+	var dropped []*ChunkMetadata
+	exists := false
+	err := m.update(func(tx *metadataTx) error {
+		var file *FileMetadata
+		var err error
+		file, exists, err = tx.GetFile(source)
+		if err != nil || !exists {
+			return err
+		}
+
+		if err := tx.shareChunks(file.Chunks); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		clone = &FileMetadata{
+			Filename:          destination,
+			Filesize:          file.Filesize,
+			ChunkCount:        file.ChunkCount,
+			Chunks:            slices.Clone(file.Chunks),
+			Data:              file.Data,
+			CreatedAt:         now,
+			ModifiedAt:        now,
+			Generation:        m.nextGeneration(),
+			Tags:              maps.Clone(file.Tags),
+			ReplicationFactor: file.ReplicationFactor,
+			Tier:              file.Tier,
+			StorageClass:      file.StorageClass,
+		}
+		clone.setOwnership(ownership)
+
+		dropped, err = tx.replaceFile(clone, keepVersions)
+		return err
+	})
+	if err != nil || !exists {
+		return nil, exists, nil, err
+	}
+
+	return clone, true, dropped, nil
+}
+
+// shareChunks counts one more file using each of the chunks
+func (tx *metadataTx) shareChunks(chunkHandles []string) error {
+	for _, chunkHandle := range chunkHandles {
+		chunk, exists, err := tx.GetChunk(chunkHandle)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("chunk not found: %s", chunkHandle)
+		}
+
+		chunk.References = max(chunk.References, 1) + 1
+		if err := tx.PutChunk(chunk); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// AddChunkCopy adds a chunk taking the place of chunk in filename once its replicas are copied, with a
+// handle never used before and no locations yet
+func (m *Metadata) AddChunkCopy(filename string, chunk *ChunkMetadata) (*ChunkMetadata, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// the file's generation doesn't change on appends, so a fresh one keeps the handle from colliding with
+	// the shared chunk or an earlier copy of it
+	var chunkCopy *ChunkMetadata
+	err := m.update(func(tx *metadataTx) error {
+		var err error
+		chunkCopy, err = tx.addChunk(common.GenerateChunkHandle(filename, m.nextGeneration(), int(chunk.ChunkIndex)), filename, chunk.ChunkIndex)
+		if err != nil {
+			return err
+		}
+
+		chunkCopy.ReplicationFactor = chunk.ReplicationFactor
+		chunkCopy.Tier = chunk.Tier
+		return tx.PutChunk(chunkCopy)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return chunkCopy, nil
+}
+
+// ReplaceChunk swaps the chunk handle shared by filename for the handle of its private copy, dropping the file's
+// reference to the shared chunk. It returns the shared chunk when no file uses it anymore, whose replicas the
+// caller is responsible for deleting
+func (m *Metadata) ReplaceChunk(filename, sharedHandle, copyHandle string) ([]*ChunkMetadata, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var chunks []*ChunkMetadata
+	err := m.update(func(tx *metadataTx) error {
+		file, exists, err := tx.GetFile(filename)
+		if err != nil || !exists {
+			return err
+		}
+
+		i := slices.Index(file.Chunks, sharedHandle)
+		if i < 0 {
+			return nil
+		}
+
+		file.Chunks[i] = copyHandle
+		if err := tx.PutFile(file); err != nil {
+			return err
+		}
+
+		chunks, err = tx.removeChunks([]string{sharedHandle})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return chunks, nil
+}
+
+// CloneFile handles requests to clone a file. The clone shares the source's chunks instead of copying them,
+// so it is cheap whatever the file's size, and a shared chunk is only copied once either file appends to it
+func (s *Server) CloneFile(ctx context.Context, req *pb.CloneFileRequest) (*pb.CloneFileResponse, error) {
+	log.Printf("Clone request: %s -> %s", req.SourceFilename, req.DestinationFilename)
+
+	if req.SourceFilename == req.DestinationFilename {
+		return nil, status.Errorf(codes.InvalidArgument, "source and destination are the same file: %s", req.SourceFilename)
+	}
+
+	unlock := s.locks.LockCopy(req.SourceFilename, req.DestinationFilename)
+	defer unlock()
+
+	if s.uploads.InProgress(req.DestinationFilename) {
+		return nil, status.Errorf(codes.Aborted, "failed to clone to %s: %v", req.DestinationFilename, ErrUploadInProgress)
+	}
+
+	// cloning a symlink clones the file it leads to
+	source, exists, err := s.resolveFile(req.SourceFilename)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, status.Errorf(codes.NotFound, "file not found: %s", req.SourceFilename)
+	}
+	identity := identityFromContext(ctx)
+	if err := checkAccess(source.Filename, source, exists, identity, AccessRead); err != nil {
+		return nil, err
+	}
+	// the clone shares the contents, not the archive
+	if err := s.checkRecalled(source, source.Generation); err != nil {
+		return nil, err
+	}
+
+	destination, exists, err := s.metadata.GetFile(req.DestinationFilename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up file %s: %v", req.DestinationFilename, err)
+	}
+	if err := checkGeneration(req.DestinationFilename, destination, exists, req.IfGenerationMatch); err != nil {
+		return nil, err
+	}
+	if err := checkMutable(req.DestinationFilename, destination, exists); err != nil {
+		return nil, err
+	}
+	if err := checkAccess(req.DestinationFilename, destination, exists, identity, AccessWrite); err != nil {
+		return nil, err
+	}
+
+	// replicas of replaced versions that aren't kept are removed in background
+	clone, exists, dropped, err := s.metadata.CloneFile(source.Filename, req.DestinationFilename, s.tunables.Load().KeepVersions, newOwnership(identity, source.mode()))
+	s.goBackground(func() { s.deleteChunks(dropped) })
+	if err != nil {
+		return nil, fmt.Errorf("failed to clone file %s: %v", req.SourceFilename, err)
+	}
+	if !exists {
+		return nil, status.Errorf(codes.NotFound, "file not found: %s", req.SourceFilename)
+	}
+
+	log.Printf("Cloned %s to %s sharing %d chunks", source.Filename, req.DestinationFilename, len(clone.Chunks))
+	s.events.Publish(pb.FileEventType_FILE_EVENT_CREATED, req.DestinationFilename, "", clone.Filesize)
+
+	return &pb.CloneFileResponse{
+		Success:    true,
+		Generation: clone.Generation,
+	}, nil
+}
+
+// copyOnWrite gives filename a private copy of a chunk it shares with a clone, copied by every server holding
+// the shared chunk, and returns the copy. The caller must hold the file's lock
+func (s *Server) copyOnWrite(filename string, chunk *ChunkMetadata) (*ChunkMetadata, error) {
+	chunkCopy, err := s.metadata.AddChunkCopy(filename, chunk)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add copy of chunk %s: %v", chunk.ChunkHandle, err)
+	}
+
+	for _, serverAddr := range chunk.Locations {
+		if err := s.copyChunkOnServer(serverAddr, chunk.ChunkHandle, chunkCopy.ChunkHandle, chunkCopy.Version); err != nil {
+			log.Printf("Warning: failed to copy chunk %s on %s: %v", chunk.ChunkHandle, serverAddr, err)
+			continue
+		}
+
+		if err := s.metadata.AddChunkLocation(chunkCopy.ChunkHandle, serverAddr); err != nil {
+			return nil, fmt.Errorf("failed to add location of chunk %s: %v", chunkCopy.ChunkHandle, err)
+		}
+		chunkCopy.Locations = append(chunkCopy.Locations, serverAddr)
+	}
+
+	if len(chunkCopy.Locations) == 0 {
+		return nil, fmt.Errorf("failed to copy chunk %d of %s on any server", chunk.ChunkIndex, filename)
+	}
+
+	unused, err := s.metadata.ReplaceChunk(filename, chunk.ChunkHandle, chunkCopy.ChunkHandle)
+	s.goBackground(func() { s.deleteChunks(unused) })
+	if err != nil {
+		return nil, fmt.Errorf("failed to replace chunk %s of %s: %v", chunk.ChunkHandle, filename, err)
+	}
+
+	log.Printf("Chunk %d of %s copied on write to %s on %d servers", chunk.ChunkIndex, filename, chunkCopy.ChunkHandle, len(chunkCopy.Locations))
+	return chunkCopy, nil
+}
@@ -0,0 +1,373 @@
+package master
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/harshvardha/distributed_file_system/common"
+	pb "github.com/harshvardha/distributed_file_system/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DelegationTokenPrefix starts every delegation token, telling them apart from user tokens
+const DelegationTokenPrefix = "dfsdt."
+
+const (
+	defaultDelegationTTL      = 24 * time.Hour
+	defaultDelegationLifetime = 7 * 24 * time.Hour
+	maxDelegationLifetime     = 30 * 24 * time.Hour // bounds how long a leaked token stays usable, and revocations are kept
+)
+
+// ErrInvalidDelegationToken is returned for delegation tokens that are malformed, expired, revoked or not signed by the cluster
+var ErrInvalidDelegationToken = errors.New("invalid delegation token")
+
+// delegationMethods are the rpcs requests authenticated with a delegation token may call. Listing and searching
+// aren't limited to a prefix, presigned urls would outlive a revocation, and corrupt chunk reports name no file,
+// so a token only reads the files it names
+var delegationMethods = map[string]bool{
+	pb.Master_DownloadFile_FullMethodName:     true,
+	pb.Master_GetFileInfo_FullMethodName:      true,
+	pb.Master_ListFileVersions_FullMethodName: true,
+	pb.Master_WhoAmI_FullMethodName:           true,
+}
+
+// Delegation is what a delegation token narrows its owner's access to: reading the files under a prefix
+type Delegation struct {
+	ID     string
+	Prefix string // empty for every file the owner may read
+}
+
+// allows reports whether the delegation lets its holder access filename
+func (d *Delegation) allows(filename string, access Access) bool {
+	return access == AccessRead && strings.HasPrefix(filename, d.Prefix)
+}
+
+// RevokedToken records a delegation token the master no longer accepts. It is kept until the token
+// couldn't have been renewed any longer anyway
+type RevokedToken struct {
+	ID      string
+	Expires time.Time
+}
+
+// delegationClaims is what a delegation token says about its holder, signed by the master
+type delegationClaims struct {
+	ID         string   `json:"id"`
+	User       string   `json:"user"`
+	Groups     []string `json:"groups,omitempty"`
+	Superuser  bool     `json:"superuser,omitempty"`
+	Prefix     string   `json:"prefix"`
+	MaxExpires int64    `json:"max_expires"` // unix time in seconds past which the token can't be renewed
+}
+
+// identity returns the identity of a token's holder, its owner limited to the token's delegation
+func (c *delegationClaims) identity() *Identity {
+	return &Identity{
+		User:       c.User,
+		Groups:     c.Groups,
+		Superuser:  c.Superuser,
+		Delegation: &Delegation{ID: c.ID, Prefix: c.Prefix},
+	}
+}
+
+// delegationTokens mints and checks the delegation tokens of a master. Tokens are signed with the cluster's
+// chunk token key, so any master of the cluster accepts them, and revocations are kept in the metadata store
+type delegationTokens struct {
+	tokens   *common.ChunkTokens
+	metadata *Metadata
+}
+
+// newDelegationTokens creates the delegation tokens of a master, nil without a chunk token key to sign them with
+func newDelegationTokens(tokens *common.ChunkTokens, metadata *Metadata) *delegationTokens {
+	if tokens == nil {
+		return nil
+	}
+
+	return &delegationTokens{tokens: tokens, metadata: metadata}
+}
+
+// sign returns the token carrying claims, valid until expires
+func (d *delegationTokens) sign(claims *delegationClaims, expires time.Time) (string, error) {
+	data, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(data)
+	return DelegationTokenPrefix + encoded + "." + d.tokens.SignDelegation(encoded, expires), nil
+}
+
+// parse checks a token was signed by the cluster and hasn't expired, returning its claims
+func (d *delegationTokens) parse(token string) (*delegationClaims, error) {
+	encoded, signature, found := strings.Cut(strings.TrimPrefix(token, DelegationTokenPrefix), ".")
+	if !found {
+		return nil, fmt.Errorf("%w: malformed token", ErrInvalidDelegationToken)
+	}
+	if _, err := d.tokens.VerifyDelegation(signature, encoded); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDelegationToken, err)
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed claims", ErrInvalidDelegationToken)
+	}
+	claims := &delegationClaims{}
+	if err := json.Unmarshal(data, claims); err != nil {
+		return nil, fmt.Errorf("%w: malformed claims", ErrInvalidDelegationToken)
+	}
+
+	return claims, nil
+}
+
+// parseUnrevoked is parse also failing for revoked tokens
+func (d *delegationTokens) parseUnrevoked(token string) (*delegationClaims, error) {
+	claims, err := d.parse(token)
+	if err != nil {
+		return nil, err
+	}
+
+	revoked, err := d.metadata.DelegationTokenRevoked(claims.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up revocation of delegation token %s: %v", claims.ID, err)
+	}
+	if revoked {
+		return nil, fmt.Errorf("%w: token %s was revoked", ErrInvalidDelegationToken, claims.ID)
+	}
+
+	return claims, nil
+}
+
+// authenticate returns the identity of a delegation token's holder
+func (d *delegationTokens) authenticate(token string) (*Identity, error) {
+	claims, err := d.parseUnrevoked(token)
+	if err != nil {
+		return nil, err
+	}
+
+	return claims.identity(), nil
+}
+
+// fileRequest is a request naming the file it is about
+type fileRequest interface {
+	GetFilename() string
+}
+
+// checkDelegated rejects requests authenticated with a delegation token for rpcs other than delegationMethods,
+// and for files outside the token's prefix. checkAccess checks the files requests resolve to, e.g. symlink targets
+func checkDelegated(ctx context.Context, fullMethod string, req any) error {
+	identity := identityFromContext(ctx)
+	if identity == nil || identity.Delegation == nil {
+		return nil
+	}
+
+	if !delegationMethods[fullMethod] {
+		return status.Errorf(codes.PermissionDenied, "%v, delegation token %s only reads files", ErrPermissionDenied, identity.Delegation.ID)
+	}
+	if request, ok := req.(fileRequest); ok && !identity.Delegation.allows(request.GetFilename(), AccessRead) {
+		return status.Errorf(codes.PermissionDenied, "%s: %v, delegation token %s only reads files under %q",
+			request.GetFilename(), ErrPermissionDenied, identity.Delegation.ID, identity.Delegation.Prefix)
+	}
+
+	return nil
+}
+
+// RevokeDelegationToken records a delegation token as revoked until expires, dropping the revocations of tokens that can't
+// be renewed any longer
+func (m *Metadata) RevokeDelegationToken(id string, expires time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	var expired []string
+	err := m.store.ForEachRevokedToken(func(revoked *RevokedToken) error {
+		if !now.Before(revoked.Expires) {
+			expired = append(expired, revoked.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return m.update(func(tx *metadataTx) error {
+		for _, expiredID := range expired {
+			if err := tx.DeleteRevokedToken(expiredID); err != nil {
+				return err
+			}
+		}
+		return tx.PutRevokedToken(&RevokedToken{ID: id, Expires: expires})
+	})
+}
+
+// DelegationTokenRevoked reports whether a delegation token was revoked
+func (m *Metadata) DelegationTokenRevoked(id string) (bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	_, revoked, err := m.store.GetRevokedToken(id)
+	return revoked, err
+}
+
+// delegator returns the delegation tokens of the master and the identity of a caller allowed to mint, renew and
+// revoke them: a user authenticated with the user's own token
+func (s *Server) delegator(ctx context.Context) (*delegationTokens, *Identity, error) {
+	if s.auth == nil {
+		return nil, nil, status.Errorf(codes.FailedPrecondition, "delegation tokens need a master authenticating its users")
+	}
+	if s.auth.delegations == nil {
+		return nil, nil, status.Errorf(codes.FailedPrecondition, "delegation tokens need a chunk token key to be signed with")
+	}
+
+	identity := identityFromContext(ctx)
+	switch {
+	case identity == nil || identity.User == AnonymousUser:
+		return nil, nil, status.Errorf(codes.Unauthenticated, "delegation tokens are only handed to authenticated users")
+	case identity.Delegation != nil:
+		return nil, nil, status.Errorf(codes.PermissionDenied, "%v, delegation tokens can't manage delegation tokens", ErrPermissionDenied)
+	}
+
+	return s.auth.delegations, identity, nil
+}
+
+// delegationTTL converts a requested time to live in seconds, 0 for fallback, to a duration
+func delegationTTL(seconds int64, fallback time.Duration) (time.Duration, error) {
+	if seconds < 0 {
+		return 0, fmt.Errorf("time to live must not be negative")
+	}
+	if seconds == 0 {
+		return fallback, nil
+	}
+
+	return time.Duration(seconds) * time.Second, nil
+}
+
+// GetDelegationToken handles requests for a delegation token reading the caller's files under a prefix, for
+// batch jobs to use instead of the caller's own token
+func (s *Server) GetDelegationToken(ctx context.Context, req *pb.GetDelegationTokenRequest) (*pb.GetDelegationTokenResponse, error) {
+	delegations, identity, err := s.delegator(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl, err := delegationTTL(req.TtlSeconds, defaultDelegationTTL)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to mint delegation token: %v", err)
+	}
+	lifetime, err := delegationTTL(req.MaxLifetimeSeconds, defaultDelegationLifetime)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to mint delegation token: %v", err)
+	}
+	if lifetime > maxDelegationLifetime {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to mint delegation token: maximum lifetime is above %s", maxDelegationLifetime)
+	}
+
+	now := time.Now()
+	maxExpires := now.Add(lifetime)
+	expires := now.Add(min(ttl, lifetime))
+	claims := &delegationClaims{
+		ID:         rand.Text(),
+		User:       identity.User,
+		Groups:     identity.Groups,
+		Superuser:  identity.Superuser,
+		Prefix:     req.Prefix,
+		MaxExpires: maxExpires.Unix(),
+	}
+	token, err := delegations.sign(claims, expires)
+	if err != nil {
+		return nil, fmt.Errorf("failed to mint delegation token: %v", err)
+	}
+
+	log.Printf("Delegation token %s minted for %s, reading %q until %s", claims.ID, identity.User, req.Prefix, expires.Format(time.DateTime))
+	return &pb.GetDelegationTokenResponse{
+		Token:        token,
+		Id:           claims.ID,
+		ExpiresAt:    expires.Unix(),
+		MaxExpiresAt: claims.MaxExpires,
+	}, nil
+}
+
+// RenewDelegationToken handles requests extending a delegation token, allowed for its owner and superusers. An
+// expired token can't be renewed, and renewals never extend it past the maximum lifetime it was minted with
+func (s *Server) RenewDelegationToken(ctx context.Context, req *pb.RenewDelegationTokenRequest) (*pb.RenewDelegationTokenResponse, error) {
+	delegations, identity, err := s.delegator(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := delegations.parseUnrevoked(req.Token)
+	if errors.Is(err, ErrInvalidDelegationToken) {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to renew delegation token: %v", err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !identity.Superuser && identity.User != claims.User {
+		return nil, status.Errorf(codes.PermissionDenied, "%v, delegation token %s belongs to %s", ErrPermissionDenied, claims.ID, claims.User)
+	}
+
+	ttl, err := delegationTTL(req.TtlSeconds, defaultDelegationTTL)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to renew delegation token: %v", err)
+	}
+	maxExpires := time.Unix(claims.MaxExpires, 0)
+	if !time.Now().Before(maxExpires) {
+		return nil, status.Errorf(codes.FailedPrecondition, "delegation token %s reached its maximum lifetime at %s", claims.ID, maxExpires.Format(time.DateTime))
+	}
+
+	expires := time.Now().Add(ttl)
+	if expires.After(maxExpires) {
+		expires = maxExpires
+	}
+	token, err := delegations.sign(claims, expires)
+	if err != nil {
+		return nil, fmt.Errorf("failed to renew delegation token: %v", err)
+	}
+
+	log.Printf("Delegation token %s of %s renewed until %s", claims.ID, claims.User, expires.Format(time.DateTime))
+	return &pb.RenewDelegationTokenResponse{Token: token, ExpiresAt: expires.Unix()}, nil
+}
+
+// RevokeDelegationToken handles requests to stop accepting a delegation token and its renewals. Owners revoke their
+// tokens by handing in the token, superusers may also revoke any token by its id
+func (s *Server) RevokeDelegationToken(ctx context.Context, req *pb.RevokeDelegationTokenRequest) (*pb.RevokeDelegationTokenResponse, error) {
+	delegations, identity, err := s.delegator(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var id string
+	var expires time.Time
+	switch {
+	case req.Token != "":
+		claims, err := delegations.parse(req.Token)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "failed to revoke delegation token: %v", err)
+		}
+		if !identity.Superuser && identity.User != claims.User {
+			return nil, status.Errorf(codes.PermissionDenied, "%v, delegation token %s belongs to %s", ErrPermissionDenied, claims.ID, claims.User)
+		}
+		id, expires = claims.ID, time.Unix(claims.MaxExpires, 0)
+	case req.Id != "":
+		if !identity.Superuser {
+			return nil, status.Errorf(codes.PermissionDenied, "%v, only superusers revoke delegation tokens by id", ErrPermissionDenied)
+		}
+		// no token minted now lives longer
+		id, expires = req.Id, time.Now().Add(maxDelegationLifetime)
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "failed to revoke delegation token: no token or id given")
+	}
+
+	if err := s.metadata.RevokeDelegationToken(id, expires); err != nil {
+		return nil, fmt.Errorf("failed to revoke delegation token %s: %v", id, err)
+	}
+
+	log.Printf("Delegation token %s revoked by %s", id, identity.User)
+	return &pb.RevokeDelegationTokenResponse{}, nil
+}
@@ -0,0 +1,574 @@
+package master
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"go.etcd.io/etcd/client/pkg/v3/transport"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// etcdPageSize is the number of keys fetched per range request while iterating
+const etcdPageSize = 1000
+
+// etcdRequestTimeout bounds every request to etcd
+const etcdRequestTimeout = 10 * time.Second
+
+// etcdTxnAttempts is how many times a transaction is run before giving up when the records it read keep
+// changing under it, e.g. while another master writes the same files
+const etcdTxnAttempts = 10
+
+// errEtcdConflict is returned when a transaction kept conflicting with other writers
+var errEtcdConflict = errors.New("records changed by another writer")
+
+// EtcdStore is a MetadataStore keeping the namespace in etcd. Records are stored as json under prefix followed
+// by files/, inodes/, chunks/, versions/, tombstones/ or revoked-tokens/. Since the namespace lives outside the master, several masters
+// can share it: transactions only commit if none of the records they read changed since, and are run again otherwise,
+// and WatchFiles reports the files other masters write
+type EtcdStore struct {
+	client *clientv3.Client
+	prefix string
+}
+
+// EtcdConfig configures the connection of an EtcdStore
+type EtcdConfig struct {
+	Endpoints []string // client urls, e.g. https://etcd1:2379
+	Prefix    string   // prefix of every key written
+	Username  string   // user of etcd authentication, none when empty
+	Password  string
+	// CAFile verifies the etcd servers' certificates, and CertFile and KeyFile are the client certificate
+	// presented to them. Connections are plain when all are empty
+	CAFile   string
+	CertFile string
+	KeyFile  string
+}
+
+// OpenEtcdStore connects to the etcd cluster of config
+func OpenEtcdStore(config EtcdConfig) (*EtcdStore, error) {
+	if len(config.Endpoints) == 0 {
+		return nil, fmt.Errorf("at least one etcd endpoint is required")
+	}
+
+	clientConfig := clientv3.Config{
+		Endpoints:   config.Endpoints,
+		DialTimeout: etcdRequestTimeout,
+		Username:    config.Username,
+		Password:    config.Password,
+	}
+	if config.CAFile != "" || config.CertFile != "" || config.KeyFile != "" {
+		tlsInfo := transport.TLSInfo{
+			TrustedCAFile: config.CAFile,
+			CertFile:      config.CertFile,
+			KeyFile:       config.KeyFile,
+		}
+		tlsConfig, err := tlsInfo.ClientConfig()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load etcd tls settings: %v", err)
+		}
+		clientConfig.TLS = tlsConfig
+	}
+
+	client, err := clientv3.New(clientConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to etcd: %v", err)
+	}
+	store := &EtcdStore{client: client, prefix: config.Prefix}
+
+	// checking the cluster is reachable before the master starts serving
+	ctx, cancel := context.WithTimeout(context.Background(), etcdRequestTimeout)
+	defer cancel()
+	if _, err := client.Get(ctx, config.Prefix, clientv3.WithCountOnly()); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to etcd: %v", err)
+	}
+
+	return store, nil
+}
+
+// get decodes the record stored under key into value, returning false if there is none, and the
+// revision the record was last modified at, 0 if there is none
+func (s *EtcdStore) get(key string, value any) (bool, int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), etcdRequestTimeout)
+	defer cancel()
+
+	response, err := s.client.Get(ctx, key)
+	if err != nil {
+		return false, 0, err
+	}
+
+	if len(response.Kvs) == 0 {
+		return false, 0, nil
+	}
+
+	return true, response.Kvs[0].ModRevision, json.Unmarshal(response.Kvs[0].Value, value)
+}
+
+// forEach calls fn with the value of every key starting with keyPrefix in key order, one page at a time
+func (s *EtcdStore) forEach(keyPrefix string, fn func(value []byte) error) error {
+	return s.forEachFrom(keyPrefix, keyPrefix, fn)
+}
+
+// forEachFrom is forEach starting at the key start instead of the first key with the prefix. Every page is
+// read at the revision of the first, so the iteration sees the records as they were when it started
+func (s *EtcdStore) forEachFrom(keyPrefix, start string, fn func(value []byte) error) error {
+	key := start
+	rangeEnd := clientv3.GetPrefixRangeEnd(keyPrefix)
+	revision := int64(0)
+
+	for {
+		options := []clientv3.OpOption{clientv3.WithRange(rangeEnd), clientv3.WithLimit(etcdPageSize)}
+		if revision > 0 {
+			options = append(options, clientv3.WithRev(revision))
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), etcdRequestTimeout)
+		response, err := s.client.Get(ctx, key, options...)
+		cancel()
+		if err != nil {
+			return err
+		}
+		revision = response.Header.Revision
+
+		for _, kv := range response.Kvs {
+			if err := fn(kv.Value); err != nil {
+				return err
+			}
+		}
+
+		if !response.More || len(response.Kvs) == 0 {
+			return nil
+		}
+
+		// continuing right after the last key of this page
+		key = string(response.Kvs[len(response.Kvs)-1].Key) + "\x00"
+	}
+}
+
+func (s *EtcdStore) fileKey(filename string) string {
+	return s.prefix + "files/" + filename
+}
+
+func (s *EtcdStore) inodeKey(id string) string {
+	return s.prefix + "inodes/" + id
+}
+
+func (s *EtcdStore) chunkKey(chunkHandle string) string {
+	return s.prefix + "chunks/" + chunkHandle
+}
+
+func (s *EtcdStore) versionKey(chunkHandle string) string {
+	return s.prefix + "versions/" + chunkHandle
+}
+
+func (s *EtcdStore) tombstoneKey(id string) string {
+	return s.prefix + "tombstones/" + id
+}
+
+func (s *EtcdStore) revokedKey(id string) string {
+	return s.prefix + "revoked-tokens/" + id
+}
+
+// GetFile implements MetadataStore
+func (s *EtcdStore) GetFile(filename string) (*FileMetadata, bool, error) {
+	file := &FileMetadata{}
+	exists, _, err := s.get(s.fileKey(filename), file)
+	if err != nil || !exists {
+		return nil, false, err
+	}
+
+	return file, true, nil
+}
+
+// ForEachFile implements MetadataStore
+func (s *EtcdStore) ForEachFile(prefix string, fn func(file *FileMetadata) error) error {
+	return s.ForEachFileAfter(prefix, "", fn)
+}
+
+// ForEachFileAfter implements MetadataStore
+func (s *EtcdStore) ForEachFileAfter(prefix, after string, fn func(file *FileMetadata) error) error {
+	// the range starts right after the key of after
+	start := s.fileKey(prefix)
+	if after >= prefix {
+		start = s.fileKey(after) + "\x00"
+	}
+
+	return s.forEachFrom(s.fileKey(prefix), start, func(value []byte) error {
+		file := &FileMetadata{}
+		if err := json.Unmarshal(value, file); err != nil {
+			return fmt.Errorf("failed to decode file: %v", err)
+		}
+
+		return fn(file)
+	})
+}
+
+// WatchFiles implements FileWatcher
+func (s *EtcdStore) WatchFiles(changed func(filename string, file *FileMetadata), missed func()) error {
+	revision, err := s.revision()
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		for {
+			s.watchFiles(revision, changed)
+			if s.client.Ctx().Err() != nil {
+				return
+			}
+
+			// starting over from the current revision once the files were read again
+			if revision, err = s.revision(); err != nil {
+				log.Printf("Warning: failed to resume watching files: %v", err)
+				time.Sleep(etcdRequestTimeout)
+				continue
+			}
+			missed()
+		}
+	}()
+
+	return nil
+}
+
+// watchFiles calls changed for every file changed after revision, until the watch fails or the store is closed
+func (s *EtcdStore) watchFiles(revision int64, changed func(filename string, file *FileMetadata)) {
+	filesPrefix := s.fileKey("")
+	watch := s.client.Watch(s.client.Ctx(), filesPrefix, clientv3.WithPrefix(), clientv3.WithRev(revision+1))
+	for response := range watch {
+		if err := response.Err(); err != nil {
+			log.Printf("Warning: watching files failed: %v", err)
+			return
+		}
+
+		for _, event := range response.Events {
+			filename := strings.TrimPrefix(string(event.Kv.Key), filesPrefix)
+			if event.Type == clientv3.EventTypeDelete {
+				changed(filename, nil)
+				continue
+			}
+
+			file := &FileMetadata{}
+			if err := json.Unmarshal(event.Kv.Value, file); err != nil {
+				log.Printf("Warning: failed to decode changed file %s: %v", filename, err)
+				continue
+			}
+			changed(filename, file)
+		}
+	}
+}
+
+// revision returns the current revision of the etcd cluster
+func (s *EtcdStore) revision() (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), etcdRequestTimeout)
+	defer cancel()
+
+	response, err := s.client.Get(ctx, s.prefix, clientv3.WithCountOnly())
+	if err != nil {
+		return 0, err
+	}
+
+	return response.Header.Revision, nil
+}
+
+// GetInode implements MetadataStore
+func (s *EtcdStore) GetInode(id string) (*Inode, bool, error) {
+	inode := &Inode{}
+	exists, _, err := s.get(s.inodeKey(id), inode)
+	if err != nil || !exists {
+		return nil, false, err
+	}
+
+	return inode, true, nil
+}
+
+// GetChunk implements MetadataStore
+func (s *EtcdStore) GetChunk(chunkHandle string) (*ChunkMetadata, bool, error) {
+	chunk := &ChunkMetadata{}
+	exists, _, err := s.get(s.chunkKey(chunkHandle), chunk)
+	if err != nil || !exists {
+		return nil, false, err
+	}
+
+	return chunk, true, nil
+}
+
+// ForEachChunk implements MetadataStore
+func (s *EtcdStore) ForEachChunk(fn func(chunk *ChunkMetadata) error) error {
+	return s.forEach(s.chunkKey(""), func(value []byte) error {
+		chunk := &ChunkMetadata{}
+		if err := json.Unmarshal(value, chunk); err != nil {
+			return fmt.Errorf("failed to decode chunk: %v", err)
+		}
+
+		return fn(chunk)
+	})
+}
+
+// GetChunkVersion implements MetadataStore
+func (s *EtcdStore) GetChunkVersion(chunkHandle string) (int32, error) {
+	var version int32
+	if _, _, err := s.get(s.versionKey(chunkHandle), &version); err != nil {
+		return 0, err
+	}
+
+	return version, nil
+}
+
+// ForEachTombstone implements MetadataStore
+func (s *EtcdStore) ForEachTombstone(fn func(tombstone *Tombstone) error) error {
+	return s.forEach(s.tombstoneKey(""), func(value []byte) error {
+		tombstone := &Tombstone{}
+		if err := json.Unmarshal(value, tombstone); err != nil {
+			return fmt.Errorf("failed to decode tombstone: %v", err)
+		}
+
+		return fn(tombstone)
+	})
+}
+
+// GetRevokedToken implements MetadataStore
+func (s *EtcdStore) GetRevokedToken(id string) (*RevokedToken, bool, error) {
+	revoked := &RevokedToken{}
+	exists, _, err := s.get(s.revokedKey(id), revoked)
+	if err != nil || !exists {
+		return nil, false, err
+	}
+
+	return revoked, true, nil
+}
+
+// ForEachRevokedToken implements MetadataStore
+func (s *EtcdStore) ForEachRevokedToken(fn func(revoked *RevokedToken) error) error {
+	return s.forEach(s.revokedKey(""), func(value []byte) error {
+		revoked := &RevokedToken{}
+		if err := json.Unmarshal(value, revoked); err != nil {
+			return fmt.Errorf("failed to decode revoked token: %v", err)
+		}
+
+		return fn(revoked)
+	})
+}
+
+// Update implements MetadataStore. Changes are buffered and committed in a single etcd transaction when fn
+// returns, on the condition that every record fn read is unchanged. fn is run again when one did change
+func (s *EtcdStore) Update(fn func(tx MetadataTx) error) error {
+	for range etcdTxnAttempts {
+		tx := &etcdTx{
+			store:  s,
+			reads:  make(map[string]int64),
+			writes: make(map[string][]byte),
+		}
+		if err := fn(tx); err != nil {
+			return err
+		}
+
+		if len(tx.keys) == 0 {
+			return nil
+		}
+
+		committed, err := tx.commit()
+		if err != nil || committed {
+			return err
+		}
+	}
+
+	return fmt.Errorf("failed to commit transaction after %d attempts: %w", etcdTxnAttempts, errEtcdConflict)
+}
+
+// etcdTx is a transaction of an EtcdStore, buffering its writes until Update commits them
+type etcdTx struct {
+	store  *EtcdStore
+	reads  map[string]int64  // key: key read, value: revision it was last modified at, 0 for missing keys
+	writes map[string][]byte // key: key written, value: encoded record, nil for deleted records
+	keys   []string          // keys of writes in the order they were first written
+}
+
+// commit writes the buffered changes if none of the records read changed, returning false if one did
+func (tx *etcdTx) commit() (bool, error) {
+	conditions := make([]clientv3.Cmp, 0, len(tx.reads))
+	for key, revision := range tx.reads {
+		conditions = append(conditions, clientv3.Compare(clientv3.ModRevision(key), "=", revision))
+	}
+
+	operations := make([]clientv3.Op, 0, len(tx.keys))
+	for _, key := range tx.keys {
+		if value := tx.writes[key]; value != nil {
+			operations = append(operations, clientv3.OpPut(key, string(value)))
+		} else {
+			operations = append(operations, clientv3.OpDelete(key))
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), etcdRequestTimeout)
+	defer cancel()
+
+	response, err := tx.store.client.Txn(ctx).If(conditions...).Then(operations...).Commit()
+	if err != nil {
+		return false, err
+	}
+
+	return response.Succeeded, nil
+}
+
+// get decodes the record stored under key into value, seeing the writes of the transaction and
+// remembering the revision of records read from etcd
+func (tx *etcdTx) get(key string, value any) (bool, error) {
+	if data, written := tx.writes[key]; written {
+		if data == nil {
+			return false, nil
+		}
+		return true, json.Unmarshal(data, value)
+	}
+
+	exists, revision, err := tx.store.get(key, value)
+	if err != nil {
+		return false, err
+	}
+	if _, read := tx.reads[key]; !read {
+		tx.reads[key] = revision
+	}
+
+	return exists, nil
+}
+
+// put encodes value and buffers storing it under key
+func (tx *etcdTx) put(key string, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	tx.write(key, data)
+	return nil
+}
+
+// write buffers storing data under key, nil deleting it
+func (tx *etcdTx) write(key string, data []byte) {
+	if _, written := tx.writes[key]; !written {
+		tx.keys = append(tx.keys, key)
+	}
+	tx.writes[key] = data
+}
+
+// GetFile implements MetadataTx
+func (tx *etcdTx) GetFile(filename string) (*FileMetadata, bool, error) {
+	file := &FileMetadata{}
+	exists, err := tx.get(tx.store.fileKey(filename), file)
+	if err != nil || !exists {
+		return nil, false, err
+	}
+
+	return file, true, nil
+}
+
+// PutFile implements MetadataTx
+func (tx *etcdTx) PutFile(file *FileMetadata) error {
+	return tx.put(tx.store.fileKey(file.Filename), file)
+}
+
+// DeleteFile implements MetadataTx
+func (tx *etcdTx) DeleteFile(filename string) error {
+	tx.write(tx.store.fileKey(filename), nil)
+	return nil
+}
+
+// GetInode implements MetadataTx
+func (tx *etcdTx) GetInode(id string) (*Inode, bool, error) {
+	inode := &Inode{}
+	exists, err := tx.get(tx.store.inodeKey(id), inode)
+	if err != nil || !exists {
+		return nil, false, err
+	}
+
+	return inode, true, nil
+}
+
+// PutInode implements MetadataTx
+func (tx *etcdTx) PutInode(inode *Inode) error {
+	return tx.put(tx.store.inodeKey(inode.ID), inode)
+}
+
+// DeleteInode implements MetadataTx
+func (tx *etcdTx) DeleteInode(id string) error {
+	tx.write(tx.store.inodeKey(id), nil)
+	return nil
+}
+
+// GetChunk implements MetadataTx
+func (tx *etcdTx) GetChunk(chunkHandle string) (*ChunkMetadata, bool, error) {
+	chunk := &ChunkMetadata{}
+	exists, err := tx.get(tx.store.chunkKey(chunkHandle), chunk)
+	if err != nil || !exists {
+		return nil, false, err
+	}
+
+	return chunk, true, nil
+}
+
+// PutChunk implements MetadataTx
+func (tx *etcdTx) PutChunk(chunk *ChunkMetadata) error {
+	return tx.put(tx.store.chunkKey(chunk.ChunkHandle), chunk)
+}
+
+// DeleteChunk implements MetadataTx
+func (tx *etcdTx) DeleteChunk(chunkHandle string) error {
+	tx.write(tx.store.chunkKey(chunkHandle), nil)
+	return nil
+}
+
+// GetChunkVersion implements MetadataTx
+func (tx *etcdTx) GetChunkVersion(chunkHandle string) (int32, error) {
+	var version int32
+	if _, err := tx.get(tx.store.versionKey(chunkHandle), &version); err != nil {
+		return 0, err
+	}
+
+	return version, nil
+}
+
+// PutChunkVersion implements MetadataTx
+func (tx *etcdTx) PutChunkVersion(chunkHandle string, version int32) error {
+	return tx.put(tx.store.versionKey(chunkHandle), version)
+}
+
+// GetTombstone implements MetadataTx
+func (tx *etcdTx) GetTombstone(id string) (*Tombstone, bool, error) {
+	tombstone := &Tombstone{}
+	exists, err := tx.get(tx.store.tombstoneKey(id), tombstone)
+	if err != nil || !exists {
+		return nil, false, err
+	}
+
+	return tombstone, true, nil
+}
+
+// PutTombstone implements MetadataTx
+func (tx *etcdTx) PutTombstone(tombstone *Tombstone) error {
+	return tx.put(tx.store.tombstoneKey(tombstone.ID), tombstone)
+}
+
+// DeleteTombstone implements MetadataTx
+func (tx *etcdTx) DeleteTombstone(id string) error {
+	tx.write(tx.store.tombstoneKey(id), nil)
+	return nil
+}
+
+// PutRevokedToken implements MetadataTx
+func (tx *etcdTx) PutRevokedToken(revoked *RevokedToken) error {
+	return tx.put(tx.store.revokedKey(revoked.ID), revoked)
+}
+
+// DeleteRevokedToken implements MetadataTx
+func (tx *etcdTx) DeleteRevokedToken(id string) error {
+	tx.write(tx.store.revokedKey(id), nil)
+	return nil
+}
+
+// Close implements MetadataStore
+func (s *EtcdStore) Close() error {
+	return s.client.Close()
+}
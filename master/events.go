@@ -0,0 +1,87 @@
+package master
+
+import (
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/harshvardha/distributed_file_system/proto"
+)
+
+// watcherBufferSize is the number of events buffered per watcher before events get dropped
+const watcherBufferSize = 64
+
+// watcher represents a single Watch subscription
+type watcher struct {
+	prefix string
+	events chan *pb.FileEvent
+}
+
+// EventBroker fans out namespace events to all subscribed watchers
+type EventBroker struct {
+	mu       sync.Mutex
+	nextID   int
+	watchers map[int]*watcher // key: subscription id, value: watcher
+}
+
+// NewEventBroker creates a new event broker
+func NewEventBroker() *EventBroker {
+	return &EventBroker{
+		watchers: make(map[int]*watcher),
+	}
+}
+
+// Subscribe registers a watcher for events on files matching prefix
+func (b *EventBroker) Subscribe(prefix string) (int, <-chan *pb.FileEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	w := &watcher{
+		prefix: prefix,
+		events: make(chan *pb.FileEvent, watcherBufferSize),
+	}
+	b.watchers[b.nextID] = w
+
+	return b.nextID, w.events
+}
+
+// Unsubscribe removes a watcher and closes its event channel
+func (b *EventBroker) Unsubscribe(id int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if w, exists := b.watchers[id]; exists {
+		close(w.events)
+		delete(b.watchers, id)
+	}
+}
+
+// Publish delivers an event to every watcher whose prefix matches
+func (b *EventBroker) Publish(eventType pb.FileEventType, filename, oldFilename string, filesize int64) {
+	event := &pb.FileEvent{
+		Type:        eventType,
+		Filename:    filename,
+		OldFilename: oldFilename,
+		Filesize:    filesize,
+		Timestamp:   time.Now().UnixNano(),
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for id, w := range b.watchers {
+		// renames match when either the old or the new name is under the prefix
+		if !strings.HasPrefix(filename, w.prefix) && (oldFilename == "" || !strings.HasPrefix(oldFilename, w.prefix)) {
+			continue
+		}
+
+		// never block the master on a slow watcher
+		select {
+		case w.events <- event:
+		default:
+			log.Printf("Warning: dropping %s event for %s, watcher %d is too slow", eventType, filename, id)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package master
+
+import (
+	"slices"
+	"sync"
+)
+
+// fileLock is the lock of a single filename, shared by every operation currently using the name
+type fileLock struct {
+	mu   sync.RWMutex
+	refs int // operations holding or waiting for the lock
+}
+
+// FileLocks hands out per filename read/write locks so operations on the same file don't interleave,
+// while operations on different files run in parallel. Locks exist only while someone uses them
+type FileLocks struct {
+	mu    sync.Mutex
+	locks map[string]*fileLock // key: filename, value: lock of the filename
+}
+
+// NewFileLocks creates a new file lock manager
+func NewFileLocks() *FileLocks {
+	return &FileLocks{
+		locks: make(map[string]*fileLock),
+	}
+}
+
+// acquire returns the lock of filename, creating it if needed, and counts the caller as a user
+func (l *FileLocks) acquire(filename string) *fileLock {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	lock, exists := l.locks[filename]
+	if !exists {
+		lock = &fileLock{}
+		l.locks[filename] = lock
+	}
+	lock.refs++
+
+	return lock
+}
+
+// release stops counting the caller as a user of the lock of filename, dropping the lock once unused
+func (l *FileLocks) release(filename string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	lock := l.locks[filename]
+	lock.refs--
+	if lock.refs == 0 {
+		delete(l.locks, filename)
+	}
+}
+
+// Lock takes exclusive locks on filenames and returns the function releasing them. Locks are taken
+// in filename order so operations locking several files can't deadlock each other
+func (l *FileLocks) Lock(filenames ...string) func() {
+	filenames = slices.Compact(slices.Sorted(slices.Values(filenames)))
+
+	locks := make([]*fileLock, 0, len(filenames))
+	for _, filename := range filenames {
+		lock := l.acquire(filename)
+		lock.mu.Lock()
+		locks = append(locks, lock)
+	}
+
+	return func() {
+		for i := len(filenames) - 1; i >= 0; i-- {
+			locks[i].mu.Unlock()
+			l.release(filenames[i])
+		}
+	}
+}
+
+// RLock takes a shared lock on filename and returns the function releasing it
+func (l *FileLocks) RLock(filename string) func() {
+	lock := l.acquire(filename)
+	lock.mu.RLock()
+
+	return func() {
+		lock.mu.RUnlock()
+		l.release(filename)
+	}
+}
+
+// LockCopy takes a shared lock on source and an exclusive lock on destination, in filename order,
+// and returns the function releasing them. source and destination must differ
+func (l *FileLocks) LockCopy(source, destination string) func() {
+	if source > destination {
+		unlockDestination := l.Lock(destination)
+		unlockSource := l.RLock(source)
+		return func() {
+			unlockSource()
+			unlockDestination()
+		}
+	}
+
+	unlockSource := l.RLock(source)
+	unlockDestination := l.Lock(destination)
+	return func() {
+		unlockDestination()
+		unlockSource()
+	}
+}
@@ -0,0 +1,33 @@
+package master
+
+import (
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrGenerationMismatch is returned when a conditional operation finds a different generation of the file than expected
+var ErrGenerationMismatch = errors.New("generation mismatch")
+
+// ErrFileExists is returned when an exclusive create finds the file already there
+var ErrFileExists = errors.New("file already exists")
+
+// checkGeneration verifies a conditional operation's expected generation against the current file.
+// A nil expectation always matches, an expected generation of 0 matches only when the file doesn't exist
+func checkGeneration(filename string, file *FileMetadata, exists bool, expected *int64) error {
+	if expected == nil {
+		return nil
+	}
+
+	switch {
+	case *expected == 0 && exists:
+		return status.Errorf(codes.FailedPrecondition, "%s: %v, expected no file, found generation %d", filename, ErrGenerationMismatch, file.Generation)
+	case *expected != 0 && !exists:
+		return status.Errorf(codes.FailedPrecondition, "%s: %v, expected generation %d, found no file", filename, ErrGenerationMismatch, *expected)
+	case *expected != 0 && file.Generation != *expected:
+		return status.Errorf(codes.FailedPrecondition, "%s: %v, expected generation %d, found %d", filename, ErrGenerationMismatch, *expected, file.Generation)
+	}
+
+	return nil
+}
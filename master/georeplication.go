@@ -0,0 +1,110 @@
+package master
+
+import (
+	"context"
+	"log"
+	"net"
+	"net/netip"
+	"time"
+
+	"github.com/harshvardha/distributed_file_system/client"
+	"github.com/harshvardha/distributed_file_system/common"
+	"github.com/harshvardha/distributed_file_system/mirroring"
+	pb "github.com/harshvardha/distributed_file_system/proto"
+)
+
+// geoReplicator mirrors changes of files under the configured prefixes to the remote cluster
+type geoReplicator struct {
+	config GeoReplicationConfig
+	mirror *mirroring.Mirror
+	local  *client.Client // reads file contents from this cluster
+	remote *client.Client
+
+	server *Server
+}
+
+// GeoReplicationConfig holds the settings of asynchronous mirroring to a remote cluster
+type GeoReplicationConfig struct {
+	RemoteMaster   string                   // master of the cluster files are mirrored to, empty disables geo-replication
+	Prefixes       []string                 // only files under these prefixes are mirrored, every file when empty
+	ConflictPolicy mirroring.ConflictPolicy // mirroring.ConflictKeepRemote when empty
+	ScanInterval   time.Duration            // how often all files are compared to catch missed changes, 0 for 5 minutes
+	RemoteToken    string                   // user token the remote master authenticates the mirroring as, empty for anonymous
+}
+
+func newGeoReplicator(server *Server, config GeoReplicationConfig) *geoReplicator {
+	// mirroring is a bulk job, so the clusters serve their users first
+	options := []client.ClientOption{client.WithConnTuning(server.conn), client.WithPriority(common.PriorityBatch)}
+	local := client.NewClient(localDialAddress(server.address), append(options, client.WithToken(server.auth.token()))...)
+	remote := client.NewClient(config.RemoteMaster, append(options, client.WithToken(config.RemoteToken))...)
+
+	return &geoReplicator{
+		config: config,
+		mirror: mirroring.New(local, remote, mirroring.Config{
+			Prefixes:       config.Prefixes,
+			ConflictPolicy: config.ConflictPolicy,
+			ScanInterval:   config.ScanInterval,
+			Busy:           server.uploads.InProgress,
+		}),
+		local:  local,
+		remote: remote,
+		server: server,
+	}
+}
+
+// localDialAddress returns an address the master can reach itself at when listening on address,
+// which may leave the host empty or use the unspecified address
+func localDialAddress(address string) string {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return address
+	}
+	if ip, err := netip.ParseAddr(host); host == "" || err == nil && ip.IsUnspecified() {
+		return net.JoinHostPort("localhost", port)
+	}
+
+	return address
+}
+
+// run mirrors every change of the local files until the master stops
+func (r *geoReplicator) run() {
+	status := r.mirror.Status()
+	log.Printf("Geo-replicating %s to %s, conflict policy %s", r.mirror.DescribePrefixes(), r.config.RemoteMaster, status.ConflictPolicy)
+
+	r.mirror.Run(r.server.ctx)
+	r.local.Close()
+	r.remote.Close()
+}
+
+// statusReport returns the lag and counters of geo-replication
+func (r *geoReplicator) statusReport() *pb.GetGeoReplicationStatusResponse {
+	status := r.mirror.Status()
+	report := &pb.GetGeoReplicationStatusResponse{
+		Enabled:         true,
+		RemoteMaster:    r.config.RemoteMaster,
+		Prefixes:        status.Prefixes,
+		ConflictPolicy:  string(status.ConflictPolicy),
+		PendingFiles:    int64(status.PendingFiles),
+		LagSeconds:      int64(status.Lag / time.Second),
+		ReplicatedFiles: status.MirroredFiles,
+		ReplicatedBytes: status.MirroredBytes,
+		DeletedFiles:    status.DeletedFiles,
+		Conflicts:       status.Conflicts,
+		Failures:        status.Failures,
+		LastError:       status.LastError,
+	}
+	if !status.LastMirrored.IsZero() {
+		report.LastReplicatedAt = status.LastMirrored.Unix()
+	}
+
+	return report
+}
+
+// GetGeoReplicationStatus handles geo-replication status requests
+func (s *Server) GetGeoReplicationStatus(ctx context.Context, req *pb.GetGeoReplicationStatusRequest) (*pb.GetGeoReplicationStatusResponse, error) {
+	if s.geoReplicator == nil {
+		return &pb.GetGeoReplicationStatusResponse{}, nil
+	}
+
+	return s.geoReplicator.statusReport(), nil
+}
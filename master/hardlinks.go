@@ -0,0 +1,273 @@
+package master
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"strconv"
+	"time"
+
+	pb "github.com/harshvardha/distributed_file_system/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Inode is what the names of a hard linked file share besides its chunks: the names themselves and the owner
+// and mode. Every name refers to it, so linking, renaming or changing the owner or mode writes the inode rather
+// than every name. It is removed once a single name is left, which becomes a file without links again
+type Inode struct {
+	ID    string
+	Names []string
+	Owner string
+	Group string
+	Mode  uint32
+}
+
+// applyInode fills in what the file shares with its other names
+func (f *FileMetadata) applyInode(inode *Inode) {
+	f.Links = slices.DeleteFunc(slices.Clone(inode.Names), func(name string) bool { return name == f.Filename })
+	f.Owner, f.Group, f.Mode = inode.Owner, inode.Group, inode.Mode
+}
+
+// resolveInode fills in what a hard linked file shares with its other names from its inode. The caller must hold the lock
+func (m *Metadata) resolveInode(file *FileMetadata) error {
+	if file.Inode == "" {
+		return nil
+	}
+
+	inode, exists, err := m.store.GetInode(file.Inode)
+	if err != nil {
+		return fmt.Errorf("failed to read inode of %s: %v", file.Filename, err)
+	}
+	if exists {
+		file.applyInode(inode)
+	}
+	return nil
+}
+
+// LinkFile adds a hard link named destination to the current contents of source and returns it, false if the
+// source doesn't exist. Both names share the chunks, counted like the chunks of a clone so they are kept until
+// neither name uses them, and the inode holding the names, owner and mode. The destination must not exist
+func (m *Metadata) LinkFile(source, destination string) (*FileMetadata, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var link *FileMetadata
+	exists := false
+	err := m.update(func(tx *metadataTx) error {
+		var file *FileMetadata
+		var err error
+		file, exists, err = tx.GetFile(source)
+		if err != nil || !exists {
+			return err
+		}
+
+		if err := tx.shareChunks(file.Chunks); err != nil {
+			return err
+		}
+
+		// the first link turns the file's owner and mode into an inode
+		inode := &Inode{
+			ID:    strconv.FormatInt(m.nextGeneration(), 10),
+			Names: []string{source},
+			Owner: file.Owner,
+			Group: file.Group,
+			Mode:  file.Mode,
+		}
+		if file.Inode != "" {
+			var found bool
+			if inode, found, err = tx.GetInode(file.Inode); err != nil {
+				return err
+			} else if !found {
+				return fmt.Errorf("inode %s of %s not found", file.Inode, source)
+			}
+		} else {
+			file.Inode = inode.ID
+			if err := tx.PutFile(file); err != nil {
+				return err
+			}
+		}
+
+		inode.Names = append(inode.Names, destination)
+		if err := tx.PutInode(inode); err != nil {
+			return err
+		}
+
+		link = file.linkAs(destination, m.nextGeneration())
+		return tx.PutFile(link)
+	})
+	if err != nil || !exists {
+		return nil, exists, err
+	}
+
+	return link, true, nil
+}
+
+// linkAs returns another name of the file's current contents, sharing its chunks and inode
+func (f *FileMetadata) linkAs(destination string, generation int64) *FileMetadata {
+	return &FileMetadata{
+		Filename:          destination,
+		Filesize:          f.Filesize,
+		ChunkCount:        f.ChunkCount,
+		Chunks:            slices.Clone(f.Chunks),
+		Data:              f.Data,
+		CreatedAt:         time.Now(),
+		ModifiedAt:        f.ModifiedAt,
+		Generation:        generation,
+		Tags:              maps.Clone(f.Tags),
+		ReplicationFactor: f.ReplicationFactor,
+		Tier:              f.Tier,
+		StorageClass:      f.StorageClass,
+		Owner:             f.Owner,
+		Group:             f.Group,
+		Mode:              f.Mode,
+		Inode:             f.Inode,
+		Links:             append(slices.Clone(f.Links), f.Filename),
+	}
+}
+
+// updateInode writes the owner and mode of a hard linked file to the inode its other names read them from
+func (tx *metadataTx) updateInode(file *FileMetadata) error {
+	if file.Inode == "" {
+		return nil
+	}
+
+	inode, exists, err := tx.GetInode(file.Inode)
+	if err != nil || !exists {
+		return err
+	}
+
+	inode.Owner, inode.Group, inode.Mode = file.Owner, file.Group, file.Mode
+	return tx.PutInode(inode)
+}
+
+// renameLink replaces source with the new name of a renamed hard linked file in its inode
+func (tx *metadataTx) renameLink(file *FileMetadata, source string) error {
+	if file.Inode == "" {
+		return nil
+	}
+
+	inode, exists, err := tx.GetInode(file.Inode)
+	if err != nil || !exists {
+		return err
+	}
+
+	if i := slices.Index(inode.Names, source); i >= 0 {
+		inode.Names[i] = file.Filename
+	}
+	return tx.PutInode(inode)
+}
+
+// unlink removes a deleted or replaced file from its inode. When a single name is left the inode is
+// removed and that name becomes a file without links, keeping the owner and mode
+func (tx *metadataTx) unlink(file *FileMetadata) error {
+	if file.Inode == "" {
+		return nil
+	}
+
+	inode, exists, err := tx.GetInode(file.Inode)
+	if err != nil || !exists {
+		return err
+	}
+
+	inode.Names = slices.DeleteFunc(inode.Names, func(name string) bool { return name == file.Filename })
+	if len(inode.Names) > 1 {
+		return tx.PutInode(inode)
+	}
+
+	for _, name := range inode.Names {
+		last, exists, err := tx.GetFile(name)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			continue
+		}
+
+		last.Inode, last.Links = "", nil
+		if err := tx.PutFile(last); err != nil {
+			return err
+		}
+	}
+	return tx.DeleteInode(inode.ID)
+}
+
+// relink adds an undeleted file back to its inode when other names still share it, otherwise
+// it becomes a file without links
+func (tx *metadataTx) relink(file *FileMetadata) error {
+	if file.Inode == "" {
+		return nil
+	}
+
+	inode, exists, err := tx.GetInode(file.Inode)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		file.Inode, file.Links = "", nil
+		return nil
+	}
+
+	inode.Names = append(inode.Names, file.Filename)
+	file.applyInode(inode)
+	return tx.PutInode(inode)
+}
+
+// LinkFile handles requests to hard link a file. The link is another name of the file's current contents
+// rather than a copy, so a large dataset can appear under several names while its chunks are stored once
+func (s *Server) LinkFile(ctx context.Context, req *pb.LinkFileRequest) (*pb.LinkFileResponse, error) {
+	log.Printf("Link request: %s -> %s", req.DestinationFilename, req.SourceFilename)
+
+	if req.SourceFilename == req.DestinationFilename {
+		return nil, status.Errorf(codes.InvalidArgument, "source and destination are the same file: %s", req.SourceFilename)
+	}
+
+	unlock := s.locks.LockCopy(req.SourceFilename, req.DestinationFilename)
+	defer unlock()
+
+	if s.uploads.InProgress(req.DestinationFilename) {
+		return nil, status.Errorf(codes.Aborted, "failed to link to %s: %v", req.DestinationFilename, ErrUploadInProgress)
+	}
+
+	// linking a symlink links the file it leads to
+	source, exists, err := s.resolveFile(req.SourceFilename)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, status.Errorf(codes.NotFound, "file not found: %s", req.SourceFilename)
+	}
+	// the link keeps the file's owner and mode, so reading it through the link is no more than reading the file
+	if err := checkAccess(source.Filename, source, exists, identityFromContext(ctx), AccessRead); err != nil {
+		return nil, err
+	}
+	// the names share the contents, not the archive
+	if err := s.checkRecalled(source, source.Generation); err != nil {
+		return nil, err
+	}
+
+	_, exists, err = s.metadata.GetFile(req.DestinationFilename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up file %s: %v", req.DestinationFilename, err)
+	}
+	if exists {
+		return nil, status.Errorf(codes.AlreadyExists, "failed to link to %s: file already exists", req.DestinationFilename)
+	}
+
+	link, exists, err := s.metadata.LinkFile(source.Filename, req.DestinationFilename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to link file %s: %v", source.Filename, err)
+	}
+	if !exists {
+		return nil, status.Errorf(codes.NotFound, "file not found: %s", source.Filename)
+	}
+
+	log.Printf("Linked %s to %s, %d names share %d chunks", req.DestinationFilename, source.Filename, len(link.Links)+1, len(link.Chunks))
+	s.events.Publish(pb.FileEventType_FILE_EVENT_CREATED, req.DestinationFilename, "", link.Filesize)
+
+	return &pb.LinkFileResponse{
+		Generation: link.Generation,
+	}, nil
+}
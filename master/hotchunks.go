@@ -0,0 +1,136 @@
+package master
+
+import (
+	"log"
+	"time"
+
+	"github.com/harshvardha/distributed_file_system/common"
+)
+
+const (
+	// hotChunkCheckInterval is how often the master recomputes chunk read rates
+	hotChunkCheckInterval = 30 * time.Second
+
+	// hotChunkReadsPerSec is the smoothed read rate above which a chunk gets extra replicas
+	hotChunkReadsPerSec = 1.0
+
+	// coldChunkReadsPerSec is the read rate below which a hot chunk drops back to its replication factor,
+	// kept well under hotChunkReadsPerSec so chunks near the threshold don't flap
+	coldChunkReadsPerSec = 0.25
+
+	// hotReplicationFactor is the number of replicas a hot chunk gets, capped by the alive chunk servers
+	hotReplicationFactor = 2 * common.ReplicationFactor
+
+	// forgetChunkReadsPerSec is the read rate below which cold chunks stop being tracked
+	forgetChunkReadsPerSec = hotChunkReadsPerSec / 100
+
+	// readRateSmoothing is the weight of the latest interval in the smoothed read rate
+	readRateSmoothing = 0.5
+)
+
+// chunkReadStats tracks how often a chunk is read. It is rebuilt from heartbeats and never persisted
+type chunkReadStats struct {
+	recentReads int64   // reads reported since the read rate was last updated
+	readRate    float64 // reads per second, smoothed over time
+	hotReplicas int     // replicas wanted while the chunk is hot, 0 while it isn't
+}
+
+// targetReplicas returns the number of replicas a chunk should have, its replication factor
+// raised while the chunk is hot. The caller must hold the lock
+func (m *Metadata) targetReplicas(chunk *ChunkMetadata) int {
+	target := chunk.replicationFactor()
+	if stats, exists := m.readStats[chunk.ChunkHandle]; exists {
+		target = max(target, stats.hotReplicas)
+	}
+
+	return target
+}
+
+// RecordChunkReads adds the chunk reads reported by a chunk server in a heartbeat
+func (m *Metadata) RecordChunkReads(reads map[string]int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for chunkHandle, count := range reads {
+		stats, exists := m.readStats[chunkHandle]
+		if !exists {
+			stats = &chunkReadStats{}
+			m.readStats[chunkHandle] = stats
+		}
+		stats.recentReads += count
+	}
+}
+
+// UpdateReadRates folds the reads recorded over the last interval into the read rate of every recently
+// read chunk and adjusts the target replicas of chunks that became hot or cold. It returns the chunks
+// whose target was raised and the chunks whose target was lowered
+func (m *Metadata) UpdateReadRates(interval time.Duration) ([]string, []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	aliveServers := 0
+	now := time.Now()
+	for _, server := range m.chunkServers {
+		if server.State == ChunkServerAlive && now.Sub(server.LatestHeartbeat) < m.DeadServerTimeout() {
+			aliveServers++
+		}
+	}
+	hotTarget := max(min(hotReplicationFactor, aliveServers), common.ReplicationFactor)
+
+	raised := make([]string, 0)
+	lowered := make([]string, 0)
+	for chunkHandle, stats := range m.readStats {
+		rate := float64(stats.recentReads) / interval.Seconds()
+		stats.readRate = readRateSmoothing*rate + (1-readRateSmoothing)*stats.readRate
+		stats.recentReads = 0
+
+		hotReplicas := stats.hotReplicas
+		switch {
+		case stats.readRate >= hotChunkReadsPerSec:
+			hotReplicas = hotTarget
+		case stats.readRate < coldChunkReadsPerSec:
+			hotReplicas = 0
+		}
+
+		if hotReplicas > stats.hotReplicas {
+			raised = append(raised, chunkHandle)
+		} else if hotReplicas < stats.hotReplicas {
+			lowered = append(lowered, chunkHandle)
+		}
+		stats.hotReplicas = hotReplicas
+
+		// chunks nobody reads anymore are no longer tracked
+		if hotReplicas == 0 && stats.readRate < forgetChunkReadsPerSec {
+			delete(m.readStats, chunkHandle)
+		}
+	}
+
+	return raised, lowered
+}
+
+// startHotChunkMonitor periodically adds replicas to heavily read chunks, spreading their reads
+// over more chunk servers, and removes the extra replicas once the chunks cool down
+func (s *Server) startHotChunkMonitor() {
+	ticker := time.NewTicker(hotChunkCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+		}
+
+		raised, lowered := s.metadata.UpdateReadRates(hotChunkCheckInterval)
+		if len(raised) > 0 || len(lowered) > 0 {
+			log.Printf("Hot chunks: %d chunks need more replicas, %d chunks cooled down", len(raised), len(lowered))
+		}
+
+		for _, chunkHandle := range raised {
+			s.scheduleRepair(chunkHandle)
+		}
+		for _, chunkHandle := range lowered {
+			s.goBackground(func() { s.removeExcessReplicas(chunkHandle) })
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package master
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+)
+
+// StartHTTP serves the liveness and readiness endpoints for orchestrators that can't speak grpc health,
+// rpc metrics, and presigned downloads when they are enabled
+func (s *Server) StartHTTP(httpAddress string) error {
+	mux := http.NewServeMux()
+
+	// liveness: the process is up and able to answer
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+
+	// readiness: the grpc server is accepting requests
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		if !s.ready.Load() {
+			http.Error(w, "grpc server not started", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, "ready")
+	})
+
+	// rpc counters in the prometheus text format
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		s.interceptors.WriteMetrics(w, "dfs_master")
+	})
+
+	if s.presigner != nil {
+		mux.HandleFunc(presignedPath, s.servePresigned)
+	}
+
+	log.Printf("Master http endpoints starting on %s", httpAddress)
+
+	if err := http.ListenAndServe(httpAddress, mux); err != nil {
+		return fmt.Errorf("failed to serve http: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,351 @@
+package master
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/harshvardha/distributed_file_system/common"
+	pb "github.com/harshvardha/distributed_file_system/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// AnonymousUser is the user of requests carrying no token on masters allowing anonymous access
+	AnonymousUser = "anonymous"
+
+	// internalUser is the user of the master's own requests to itself, e.g. serving presigned downloads
+	internalUser = "master"
+)
+
+// ErrUnknownCredentials is returned by identity providers for tokens belonging to no user
+var ErrUnknownCredentials = errors.New("unknown credentials")
+
+// unauthenticatedMethods are served without credentials, operators must be able to check a master's version
+// before they have one
+var unauthenticatedMethods = map[string]bool{
+	pb.Master_GetServerInfo_FullMethodName: true,
+}
+
+// chunkServerMethods are the rpcs chunk servers call on the master. Chunk servers have no user token, they
+// authenticate with a token signed with the cluster's chunk token key instead, see common.ChunkTokens.SignServer.
+// Clients also report corrupt replicas, as users
+var chunkServerMethods = map[string]bool{
+	pb.Master_Heartbeat_FullMethodName:           true,
+	pb.Master_RegisterChunkServer_FullMethodName: true,
+	pb.Master_ReportChunk_FullMethodName:         true,
+	pb.Master_ReportLostChunks_FullMethodName:    true,
+	pb.Master_ReportCorruptChunk_FullMethodName:  true,
+}
+
+// Identity is the authenticated user behind a request
+type Identity struct {
+	User      string
+	Groups    []string // the first group is the user's primary group, owning the files the user creates
+	Superuser bool     // file permissions don't apply to the user, who may also give files to other users
+
+	Delegation *Delegation // set for requests authenticated with a delegation token, which only read under its prefix
+}
+
+// primaryGroup returns the group owning the files the user creates, empty when the user is in no group
+func (i *Identity) primaryGroup() string {
+	if len(i.Groups) == 0 {
+		return ""
+	}
+
+	return i.Groups[0]
+}
+
+// IdentityProvider authenticates the tokens clients send with their requests. StaticIdentities reads users
+// from a file, other providers, e.g. backed by LDAP or an OIDC issuer, are plugged in through Config.Identities
+type IdentityProvider interface {
+	// Authenticate returns the identity a token belongs to, ErrUnknownCredentials when it belongs to no user
+	Authenticate(ctx context.Context, token string) (*Identity, error)
+}
+
+// StaticIdentities is an IdentityProvider reading its users from a JSON file. The file holds the SHA-256
+// of each user's token rather than the token, so reading it doesn't reveal any credentials:
+//
+//	{"users": [{"name": "alice", "groups": ["etl", "analysts"], "token_sha256": "9f86d081..."},
+//	           {"name": "root", "superuser": true, "token_sha256": "60303ae2..."}]}
+type StaticIdentities struct {
+	users map[string]*Identity // key: hex encoded SHA-256 of the user's token
+}
+
+// staticIdentitiesFile is the layout of the file StaticIdentities are loaded from
+type staticIdentitiesFile struct {
+	Users []struct {
+		Name        string   `json:"name"`
+		Groups      []string `json:"groups"`
+		TokenSHA256 string   `json:"token_sha256"`
+		Superuser   bool     `json:"superuser"`
+	} `json:"users"`
+}
+
+// LoadStaticIdentities reads the users of a StaticIdentities from a JSON file
+func LoadStaticIdentities(path string) (*StaticIdentities, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read identities: %v", err)
+	}
+
+	var file staticIdentitiesFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse identities %s: %v", path, err)
+	}
+
+	identities := &StaticIdentities{users: make(map[string]*Identity)}
+	names := make(map[string]bool)
+	for _, user := range file.Users {
+		if user.Name == "" || user.Name == AnonymousUser || user.Name == internalUser {
+			return nil, fmt.Errorf("invalid user name %q in %s", user.Name, path)
+		}
+		if names[user.Name] {
+			return nil, fmt.Errorf("user %s is listed twice in %s", user.Name, path)
+		}
+		names[user.Name] = true
+
+		hash := strings.ToLower(user.TokenSHA256)
+		if decoded, err := hex.DecodeString(hash); err != nil || len(decoded) != sha256.Size {
+			return nil, fmt.Errorf("token_sha256 of user %s must be a hex encoded SHA-256", user.Name)
+		}
+		if _, exists := identities.users[hash]; exists {
+			return nil, fmt.Errorf("user %s shares its token with another user in %s", user.Name, path)
+		}
+
+		identities.users[hash] = &Identity{User: user.Name, Groups: user.Groups, Superuser: user.Superuser}
+	}
+
+	return identities, nil
+}
+
+// Authenticate returns the user whose token hashes to a listed hash
+func (s *StaticIdentities) Authenticate(ctx context.Context, token string) (*Identity, error) {
+	identity, exists := s.users[common.HashToken(token)]
+	if !exists {
+		return nil, ErrUnknownCredentials
+	}
+
+	return identity, nil
+}
+
+// authenticator resolves the identity behind every client request of a master with an identity provider
+type authenticator struct {
+	provider       IdentityProvider
+	allowAnonymous bool              // requests without a token are served as AnonymousUser instead of being rejected
+	internalToken  string            // token of the master's own clients, random for every run
+	delegations    *delegationTokens // nil when the master has no key to sign delegation tokens with
+}
+
+// newAuthenticator creates the authenticator of a master, nil when there is no provider and every caller is anonymous
+func newAuthenticator(provider IdentityProvider, allowAnonymous bool, delegations *delegationTokens) *authenticator {
+	if provider == nil {
+		return nil
+	}
+
+	return &authenticator{
+		provider:       provider,
+		allowAnonymous: allowAnonymous,
+		internalToken:  rand.Text(),
+		delegations:    delegations,
+	}
+}
+
+// identityKey is the context key of a request's Identity
+type identityKey struct{}
+
+// identityFromContext returns the identity authenticated for a request, nil when the master authenticates nobody
+func identityFromContext(ctx context.Context) *Identity {
+	identity, _ := ctx.Value(identityKey{}).(*Identity)
+	return identity
+}
+
+// authenticate returns ctx carrying the identity behind a request
+func (a *authenticator) authenticate(ctx context.Context) (context.Context, error) {
+	token, found := common.TokenFromContext(ctx)
+	if !found {
+		if !a.allowAnonymous {
+			return nil, status.Errorf(codes.Unauthenticated, "request carries no token")
+		}
+		return context.WithValue(ctx, identityKey{}, &Identity{User: AnonymousUser}), nil
+	}
+
+	if token == a.internalToken {
+		return context.WithValue(ctx, identityKey{}, &Identity{User: internalUser, Superuser: true}), nil
+	}
+
+	if a.delegations != nil && strings.HasPrefix(token, DelegationTokenPrefix) {
+		identity, err := a.delegations.authenticate(token)
+		if errors.Is(err, ErrInvalidDelegationToken) {
+			return nil, status.Errorf(codes.Unauthenticated, "%v", err)
+		}
+		if err != nil {
+			return nil, status.Errorf(codes.Unavailable, "failed to authenticate: %v", err)
+		}
+		return context.WithValue(ctx, identityKey{}, identity), nil
+	}
+
+	identity, err := a.provider.Authenticate(ctx, token)
+	if errors.Is(err, ErrUnknownCredentials) {
+		return nil, status.Errorf(codes.Unauthenticated, "%v", err)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "failed to authenticate: %v", err)
+	}
+
+	return context.WithValue(ctx, identityKey{}, identity), nil
+}
+
+// skip reports whether a request is served without a user token
+func (a *authenticator) skip(ctx context.Context, fullMethod string) bool {
+	// other services, e.g. health checks, need no token, and chunk servers authenticated with theirs
+	return !strings.HasPrefix(fullMethod, "/"+pb.Master_ServiceDesc.ServiceName+"/") || unauthenticatedMethods[fullMethod] || fromChunkServer(ctx)
+}
+
+// chunkServerKey is the context key marking requests authenticated as a chunk server's
+type chunkServerKey struct{}
+
+// fromChunkServer reports whether a request was authenticated as a chunk server's
+func fromChunkServer(ctx context.Context) bool {
+	authenticated, _ := ctx.Value(chunkServerKey{}).(bool)
+	return authenticated
+}
+
+// chunkServerRequest is a request of a chunk server, naming the server it comes from
+type chunkServerRequest interface {
+	GetChunkServerAddress() string
+}
+
+// authenticateChunkServer returns ctx marked as a chunk server's when the request carries a token signed for the
+// server it names. Without a chunk token key there is nothing to check tokens against, and chunk servers are trusted
+func authenticateChunkServer(ctx context.Context, tokens *common.ChunkTokens, fullMethod string, req any) (context.Context, error) {
+	if tokens == nil {
+		return context.WithValue(ctx, chunkServerKey{}, true), nil
+	}
+
+	token, found := common.ServerTokenFromContext(ctx)
+	if !found {
+		// clients report corrupt replicas too, authenticated as users
+		if fullMethod == pb.Master_ReportCorruptChunk_FullMethodName {
+			return ctx, nil
+		}
+		return nil, status.Errorf(codes.Unauthenticated, "chunk server request carries no server token")
+	}
+
+	request, ok := req.(chunkServerRequest)
+	if !ok {
+		return nil, status.Errorf(codes.Internal, "%s is not a chunk server request", fullMethod)
+	}
+	if err := tokens.VerifyServer(token, request.GetChunkServerAddress()); err != nil {
+		log.Printf("Warning: rejecting %s from chunk server %s: %v", fullMethod, request.GetChunkServerAddress(), err)
+		return nil, status.Errorf(codes.Unauthenticated, "%v", err)
+	}
+
+	return context.WithValue(ctx, chunkServerKey{}, true), nil
+}
+
+// chunkServerAuthOptions returns the grpc interceptor authenticating the requests of chunk servers with tokens.
+// It must come before the authenticator's, which lets requests authenticated as a chunk server's through
+func chunkServerAuthOptions(tokens *common.ChunkTokens) []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			if !chunkServerMethods[info.FullMethod] {
+				return handler(ctx, req)
+			}
+
+			ctx, err := authenticateChunkServer(ctx, tokens, info.FullMethod, req)
+			if err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+	}
+}
+
+// serverOptions returns the grpc interceptors authenticating the master's rpcs, nil for a nil authenticator.
+// They must come before the scheduler's, so rejected requests don't take a slot
+func (a *authenticator) serverOptions() []grpc.ServerOption {
+	if a == nil {
+		return nil
+	}
+
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			if a.skip(ctx, info.FullMethod) {
+				return handler(ctx, req)
+			}
+
+			ctx, err := a.authenticate(ctx)
+			if err != nil {
+				return nil, err
+			}
+			if err := checkDelegated(ctx, info.FullMethod, req); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.ChainStreamInterceptor(func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if a.skip(stream.Context(), info.FullMethod) {
+				return handler(srv, stream)
+			}
+
+			ctx, err := a.authenticate(stream.Context())
+			if err != nil {
+				return err
+			}
+			if err := checkDelegated(ctx, info.FullMethod, nil); err != nil {
+				return err
+			}
+			return handler(srv, &identityStream{ServerStream: stream, ctx: ctx})
+		}),
+	}
+}
+
+// identityStream is a server stream whose context carries the caller's identity
+type identityStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *identityStream) Context() context.Context {
+	return s.ctx
+}
+
+// token returns the token the master's own clients authenticate with, empty for a nil authenticator
+func (a *authenticator) token() string {
+	if a == nil {
+		return ""
+	}
+
+	return a.internalToken
+}
+
+// WhoAmI handles requests for the identity the master authenticated the caller as
+func (s *Server) WhoAmI(ctx context.Context, req *pb.WhoAmIRequest) (*pb.WhoAmIResponse, error) {
+	identity := identityFromContext(ctx)
+	if identity == nil {
+		return &pb.WhoAmIResponse{User: AnonymousUser, Authenticated: false}, nil
+	}
+
+	response := &pb.WhoAmIResponse{
+		User:          identity.User,
+		Groups:        identity.Groups,
+		Authenticated: identity.User != AnonymousUser,
+		Superuser:     identity.Superuser,
+	}
+	if identity.Delegation != nil {
+		response.DelegationId = identity.Delegation.ID
+		response.DelegationPrefix = identity.Delegation.Prefix
+	}
+
+	return response, nil
+}
@@ -0,0 +1,126 @@
+package master
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/harshvardha/distributed_file_system/common"
+	pb "github.com/harshvardha/distributed_file_system/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// storesInline reports whether an upload is small enough to be stored in the metadata. Clients that don't
+// send the data of small files get chunks like before
+func (s *Server) storesInline(req *pb.UploadFileRequest) bool {
+	return req.Filesize > 0 && req.Filesize <= s.inlineThreshold && int64(len(req.Data)) == req.Filesize
+}
+
+// SpillFileData moves the contents of a file stored inline to the chunk written with them on servers, which
+// becomes the file's first chunk, and returns the updated file, false if it doesn't exist
+func (m *Metadata) SpillFileData(filename, chunkHandle string, servers []string) (*FileMetadata, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var file *FileMetadata
+	exists := false
+	err := m.update(func(tx *metadataTx) error {
+		var err error
+		file, exists, err = tx.GetFile(filename)
+		if err != nil || !exists {
+			return err
+		}
+
+		chunk, found, err := tx.GetChunk(chunkHandle)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return fmt.Errorf("chunk not found: %s", chunkHandle)
+		}
+
+		chunk.Locations = servers
+		chunk.ReplicationFactor = file.ReplicationFactor
+		chunk.Tier = file.Tier
+		if err := tx.PutChunk(chunk); err != nil {
+			return err
+		}
+
+		file.Chunks = append(file.Chunks, chunkHandle)
+		file.ChunkCount = len(file.Chunks)
+		file.Data = nil
+		return tx.PutFile(file)
+	})
+	if err != nil || !exists {
+		return nil, exists, err
+	}
+
+	return file, true, nil
+}
+
+// spillInlineData writes the contents of a file stored inline to a new chunk on the chunk servers, so records
+// can be appended after them, and returns the updated file. The caller must hold the file's lock
+func (s *Server) spillInlineData(file *FileMetadata) (*FileMetadata, error) {
+	servers, err := s.metadata.PlaceChunk(file.replicationFactor(), PlacementHints{Tier: file.Tier})
+	if err != nil {
+		return nil, fmt.Errorf("failed to place chunk 0 of %s: %v", file.Filename, err)
+	}
+	if len(servers) == 0 {
+		return nil, status.Errorf(codes.ResourceExhausted, "failed to place chunk 0 of %s: no chunk servers available", file.Filename)
+	}
+
+	chunkHandle := common.GenerateChunkHandle(file.Filename, file.Generation, 0)
+	chunkVersion, err := s.metadata.AddChunk(chunkHandle, file.Filename, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add chunk 0 of %s: %v", file.Filename, err)
+	}
+
+	written := make([]string, 0, len(servers))
+	for _, serverAddr := range servers {
+		if err := s.writeChunkOnServer(serverAddr, chunkHandle, chunkVersion, 0, file.Data); err != nil {
+			log.Printf("Warning: failed to write inline data of %s to %s: %v", file.Filename, serverAddr, err)
+			continue
+		}
+		written = append(written, serverAddr)
+	}
+	if len(written) == 0 {
+		return nil, fmt.Errorf("failed to write inline data of %s on any server", file.Filename)
+	}
+
+	file, _, err = s.metadata.SpillFileData(file.Filename, chunkHandle, written)
+	if err != nil {
+		return nil, fmt.Errorf("failed to move inline data of %s to chunk %s: %v", file.Filename, chunkHandle, err)
+	}
+
+	log.Printf("Inline data of %s moved to chunk %s on %d servers", file.Filename, chunkHandle, len(written))
+	return file, nil
+}
+
+// writeChunkOnServer writes a whole new chunk to a single chunk server
+func (s *Server) writeChunkOnServer(serverAddr, chunkHandle string, chunkVersion, chunkIndex int32, data []byte) error {
+	conn, err := grpc.NewClient(serverAddr, s.conn.DialOptions()...)
+	if err != nil {
+		return fmt.Errorf("failed to connect to chunk server %s: %v", serverAddr, err)
+	}
+	defer conn.Close()
+
+	chunkClient := pb.NewChunkServerClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	checksum := common.Checksum(data)
+	_, err = chunkClient.WriteChunk(ctx, &pb.WriteChunkRequest{
+		ChunkHandle:  chunkHandle,
+		Data:         data,
+		ChunkIndex:   chunkIndex,
+		ChunkVersion: chunkVersion,
+		Checksum:     &checksum,
+		AccessToken:  s.tokens.Sign(chunkHandle, common.ChunkWrite),
+		ForwardToken: s.tokens.Sign(chunkHandle, common.ChunkForward),
+	})
+
+	return err
+}
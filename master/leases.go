@@ -0,0 +1,110 @@
+package master
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// leaseDuration is how long a primary may order writes to a chunk before the master must renew its lease
+const leaseDuration = 60 * time.Second
+
+// ErrLeaseHeld is returned when the primary of a chunk is gone but its lease hasn't expired yet,
+// so no other replica may become primary before then
+var ErrLeaseHeld = errors.New("lease held by an unavailable primary")
+
+// chunkLease designates the replica ordering writes to a version of a chunk until it expires
+type chunkLease struct {
+	primary string
+	version int32
+	expires time.Time
+}
+
+// GrantLease returns the lease of a chunk, extending it while its primary is one of the live replicas, or granting
+// a new one to the least loaded live replica once it expired. Every new lease comes with a new chunk version, so
+// replicas written under it turn away writes a former primary still sends with the old version. Leases live in
+// memory only: for a lease period after the master starts, chunks it didn't create get no new lease, since one an
+// earlier master granted may still be held
+func (m *Metadata) GrantLease(chunkHandle string, replicas []string) (chunkLease, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	live := slices.DeleteFunc(slices.Clone(replicas), func(address string) bool {
+		server, exists := m.chunkServers[address]
+		return !exists || server.State != ChunkServerAlive || now.Sub(server.LatestHeartbeat) >= m.DeadServerTimeout()
+	})
+
+	if lease, exists := m.leases[chunkHandle]; exists && now.Before(lease.expires) {
+		if !slices.Contains(live, lease.primary) {
+			return chunkLease{}, fmt.Errorf("%w: %s until %s", ErrLeaseHeld, lease.primary, lease.expires.Format(time.TimeOnly))
+		}
+
+		lease.expires = now.Add(leaseDuration)
+		return *lease, nil
+	}
+
+	if now.Before(m.leaseGrace) && !m.newChunks[chunkHandle] {
+		return chunkLease{}, fmt.Errorf("%w: a lease granted before the master started may be held until %s", ErrLeaseHeld, m.leaseGrace.Format(time.TimeOnly))
+	}
+	if len(live) == 0 {
+		return chunkLease{}, fmt.Errorf("no live replicas of chunk %s", chunkHandle)
+	}
+
+	var version int32
+	err := m.update(func(tx *metadataTx) error {
+		latest, err := tx.GetChunkVersion(chunkHandle)
+		if err != nil {
+			return err
+		}
+		version = max(latest+1, initialChunkVersion)
+		if err := tx.PutChunkVersion(chunkHandle, version); err != nil {
+			return err
+		}
+
+		chunk, exists, err := tx.GetChunk(chunkHandle)
+		if err != nil || !exists {
+			return err
+		}
+		chunk.Version = version
+		return tx.PutChunk(chunk)
+	})
+	if err != nil {
+		return chunkLease{}, fmt.Errorf("failed to advance version of chunk %s: %v", chunkHandle, err)
+	}
+
+	// dropping expired leases while granting, so leases of deleted chunks don't pile up
+	for handle, lease := range m.leases {
+		if !now.Before(lease.expires) {
+			delete(m.leases, handle)
+		}
+	}
+
+	slices.SortStableFunc(live, func(a, b string) int {
+		return cmp.Compare(m.chunkServerLoad(a), m.chunkServerLoad(b))
+	})
+	lease := &chunkLease{
+		primary: live[0],
+		version: version,
+		expires: now.Add(leaseDuration),
+	}
+	m.leases[chunkHandle] = lease
+
+	return *lease, nil
+}
+
+// trackNewChunk records a chunk created since the master started, which gets leases right away. Chunks stop
+// being tracked once the leases of an earlier master have expired. The caller must hold the lock
+func (m *Metadata) trackNewChunk(chunkHandle string) {
+	if m.newChunks == nil {
+		return
+	}
+	if !time.Now().Before(m.leaseGrace) {
+		m.newChunks = nil
+		return
+	}
+
+	m.newChunks[chunkHandle] = true
+}
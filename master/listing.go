@@ -0,0 +1,271 @@
+package master
+
+import (
+	"cmp"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	pb "github.com/harshvardha/distributed_file_system/proto"
+	"google.golang.org/grpc"
+)
+
+// listBatchSize is how many files a streamed listing reads from the store and sends at a time
+const listBatchSize = 1000
+
+// errBatchFull stops an iteration over the store once a batch of files is collected
+var errBatchFull = errors.New("batch full")
+
+// ListFilter selects the files a listing returns, zero fields match every file
+type ListFilter struct {
+	Tags         map[string]string
+	MinSize      *int64
+	MaxSize      *int64
+	CreatedAfter time.Time
+}
+
+// listFilter converts the filters of a list request
+func listFilter(req *pb.ListFilesRequest) ListFilter {
+	return ListFilter{
+		Tags:         req.Tags,
+		MinSize:      req.MinSize,
+		MaxSize:      req.MaxSize,
+		CreatedAfter: unixTime(req.CreatedAfter),
+	}
+}
+
+// matches reports whether the file passes every filter
+func (f *FileMetadata) matches(filter ListFilter) bool {
+	if filter.MinSize != nil && f.Filesize < *filter.MinSize {
+		return false
+	}
+	if filter.MaxSize != nil && f.Filesize > *filter.MaxSize {
+		return false
+	}
+	if !filter.CreatedAfter.IsZero() && !f.CreatedAt.After(filter.CreatedAfter) {
+		return false
+	}
+
+	return f.hasTags(filter.Tags)
+}
+
+// query converts the filter to a search query matching the same files
+func (filter ListFilter) query() SearchQuery {
+	return SearchQuery{
+		Tags:         filter.Tags,
+		MinSize:      filter.MinSize,
+		MaxSize:      filter.MaxSize,
+		CreatedAfter: filter.CreatedAfter,
+	}
+}
+
+// listCursor is where a page of a listing ends. It is encoded into the page token, so the next page starts
+// right after the last file sent even if files were added or removed in between
+type listCursor struct {
+	SortBy     pb.ListSortKey `json:"sort_by"`
+	Descending bool           `json:"descending"`
+	Filename   string         `json:"filename"`
+	Filesize   int64          `json:"filesize,omitempty"`
+	ModifiedAt int64          `json:"modified_at,omitempty"` // unix time in nanoseconds
+}
+
+// encode converts the cursor to an opaque page token
+func (c *listCursor) encode() string {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeListCursor parses a page token of a listing with the given order, nil for an empty token
+func decodeListCursor(token string, sortBy pb.ListSortKey, descending bool) (*listCursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("malformed page token: %v", err)
+	}
+	var cursor listCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, fmt.Errorf("malformed page token: %v", err)
+	}
+	if cursor.SortBy != sortBy || cursor.Descending != descending {
+		return nil, errors.New("page token is for a listing in a different order")
+	}
+
+	return &cursor, nil
+}
+
+// compareListed orders two indexed files by the sort key, breaking ties by name
+func compareListed(sortBy pb.ListSortKey, descending bool, nameA string, a indexedFile, nameB string, b indexedFile) int {
+	var order int
+	switch sortBy {
+	case pb.ListSortKey_LIST_SORT_SIZE:
+		order = cmp.Compare(a.filesize, b.filesize)
+	case pb.ListSortKey_LIST_SORT_MODIFIED:
+		order = a.modifiedAt.Compare(b.modifiedAt)
+	}
+	if order == 0 {
+		order = cmp.Compare(nameA, nameB)
+	}
+
+	if descending {
+		return -order
+	}
+	return order
+}
+
+// ListFileNames returns the names of the files matching filter ordered by the sort key, breaking ties by name.
+// They are filtered and sorted in the search index, so the store is only read for the files sent. A listing
+// given a cursor starts after it, and one given a positive limit returns at most limit names along with the
+// cursor the next page starts at, nil when no files are left
+func (m *Metadata) ListFileNames(filter ListFilter, sortBy pb.ListSortKey, descending bool, after *listCursor, limit int) ([]string, *listCursor) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var last indexedFile
+	if after != nil {
+		last = indexedFile{filesize: after.Filesize, modifiedAt: time.Unix(0, after.ModifiedAt)}
+	}
+
+	query := filter.query()
+	names := make([]string, 0, len(m.index.files))
+	for filename, file := range m.index.files {
+		if after != nil && compareListed(sortBy, descending, filename, file, after.Filename, last) <= 0 {
+			continue
+		}
+		if file.matches(query) {
+			names = append(names, filename)
+		}
+	}
+
+	slices.SortFunc(names, func(a, b string) int {
+		return compareListed(sortBy, descending, a, m.index.files[a], b, m.index.files[b])
+	})
+
+	if limit <= 0 || len(names) <= limit {
+		return names, nil
+	}
+
+	names = names[:limit]
+	lastName := names[len(names)-1]
+	lastFile := m.index.files[lastName]
+	return names, &listCursor{
+		SortBy:     sortBy,
+		Descending: descending,
+		Filename:   lastName,
+		Filesize:   lastFile.filesize,
+		ModifiedAt: lastFile.modifiedAt.UnixNano(),
+	}
+}
+
+// GetFiles fetches copies of the files with the given names in the same order, leaving out files that no longer exist
+func (m *Metadata) GetFiles(filenames []string) ([]*FileMetadata, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	files := make([]*FileMetadata, 0, len(filenames))
+	for _, filename := range filenames {
+		file, exists, err := m.store.GetFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			continue
+		}
+
+		if err := m.resolveInode(file); err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+
+	return files, nil
+}
+
+// ListFilesAfter returns up to limit files matching filter whose names sort after after, in filename order
+func (m *Metadata) ListFilesAfter(after string, limit int, filter ListFilter) ([]*FileMetadata, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	files := make([]*FileMetadata, 0, limit)
+	err := m.store.ForEachFileAfter("", after, func(file *FileMetadata) error {
+		if !file.matches(filter) {
+			return nil
+		}
+
+		if err := m.resolveInode(file); err != nil {
+			return err
+		}
+		files = append(files, file)
+		if len(files) == limit {
+			return errBatchFull
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errBatchFull) {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+// ListFilesStream handles list requests sending the files in batches. Listings in filename order are read from
+// the store a batch at a time, so the master never holds the whole namespace; other orders are sorted by name
+// in the search index first, then read a batch at a time
+func (s *Server) ListFilesStream(req *pb.ListFilesRequest, stream grpc.ServerStreamingServer[pb.ListFilesResponse]) error {
+	log.Printf("Streaming list files request, tags: %v, sort by: %s", req.Tags, req.SortBy)
+
+	filter := listFilter(req)
+	if req.SortBy != pb.ListSortKey_LIST_SORT_NAME || req.Descending {
+		names, _ := s.metadata.ListFileNames(filter, req.SortBy, req.Descending, nil, 0)
+		for batch := range slices.Chunk(names, listBatchSize) {
+			files, err := s.metadata.GetFiles(batch)
+			if err != nil {
+				return fmt.Errorf("failed to list files: %v", err)
+			}
+			if err := s.sendFiles(stream, files); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	after := ""
+	for {
+		files, err := s.metadata.ListFilesAfter(after, listBatchSize, filter)
+		if err != nil {
+			return fmt.Errorf("failed to list files after %q: %v", after, err)
+		}
+		if len(files) == 0 {
+			return nil
+		}
+
+		if err := s.sendFiles(stream, files); err != nil {
+			return err
+		}
+		if len(files) < listBatchSize {
+			return nil
+		}
+		after = files[len(files)-1].Filename
+	}
+}
+
+// sendFiles sends a batch of files of a streamed listing
+func (s *Server) sendFiles(stream grpc.ServerStreamingServer[pb.ListFilesResponse], files []*FileMetadata) error {
+	fileInfos := make([]*pb.FileInfo, 0, len(files))
+	for _, file := range files {
+		fileInfos = append(fileInfos, s.fileInfoWithHealth(file))
+	}
+
+	return stream.Send(&pb.ListFilesResponse{
+		Files: fileInfos,
+	})
+}
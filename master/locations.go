@@ -0,0 +1,140 @@
+package master
+
+import (
+	"slices"
+)
+
+// locationIndex keeps which chunk servers hold a replica of which chunk handle. Locations aren't persisted,
+// they are rebuilt from the chunks chunk servers report when they register and in every heartbeat, so a
+// restarted master learns them again within a heartbeat interval. It is guarded by the metadata lock
+type locationIndex struct {
+	byChunk  map[string][]string            // key: chunk handle, value: addresses of the servers holding it
+	byServer map[string]map[string]struct{} // key: chunk server address, value: chunk handles it holds
+}
+
+func newLocationIndex() *locationIndex {
+	return &locationIndex{
+		byChunk:  make(map[string][]string),
+		byServer: make(map[string]map[string]struct{}),
+	}
+}
+
+// get returns a copy of the locations of a chunk
+func (idx *locationIndex) get(chunkHandle string) []string {
+	return slices.Clone(idx.byChunk[chunkHandle])
+}
+
+// set replaces the locations of a chunk
+func (idx *locationIndex) set(chunkHandle string, locations []string) {
+	for _, address := range idx.byChunk[chunkHandle] {
+		if !slices.Contains(locations, address) {
+			idx.unindex(address, chunkHandle)
+		}
+	}
+
+	delete(idx.byChunk, chunkHandle)
+	for _, address := range locations {
+		idx.add(chunkHandle, address)
+	}
+}
+
+// add records a replica of a chunk on a chunk server
+func (idx *locationIndex) add(chunkHandle, address string) {
+	if slices.Contains(idx.byChunk[chunkHandle], address) {
+		return
+	}
+
+	idx.byChunk[chunkHandle] = append(idx.byChunk[chunkHandle], address)
+	idx.index(address, chunkHandle)
+}
+
+// remove forgets the replica of a chunk on a chunk server
+func (idx *locationIndex) remove(chunkHandle, address string) {
+	locations := slices.DeleteFunc(idx.byChunk[chunkHandle], func(location string) bool {
+		return location == address
+	})
+	if len(locations) == 0 {
+		delete(idx.byChunk, chunkHandle)
+	} else {
+		idx.byChunk[chunkHandle] = locations
+	}
+
+	idx.unindex(address, chunkHandle)
+}
+
+// removeServer forgets every replica on a chunk server, returning the chunk handles it held
+func (idx *locationIndex) removeServer(address string) []string {
+	chunkHandles := make([]string, 0, len(idx.byServer[address]))
+	for chunkHandle := range idx.byServer[address] {
+		chunkHandles = append(chunkHandles, chunkHandle)
+	}
+	for _, chunkHandle := range chunkHandles {
+		idx.remove(chunkHandle, address)
+	}
+
+	slices.Sort(chunkHandles)
+	return chunkHandles
+}
+
+// chunkCount returns the number of chunk handles a chunk server holds
+func (idx *locationIndex) chunkCount(address string) int {
+	return len(idx.byServer[address])
+}
+
+// report applies what a chunk server reported holding, given what it reported before: chunks
+// that appeared since the previous report are added and chunks that disappeared are removed.
+// Chunks reported by ReportChunk in between two reports are left alone, a report put together
+// before the chunk was written doesn't drop it
+func (idx *locationIndex) report(address string, previous, chunks []string) {
+	reported := make(map[string]struct{}, len(chunks))
+	for _, chunkHandle := range chunks {
+		reported[chunkHandle] = struct{}{}
+	}
+	before := make(map[string]struct{}, len(previous))
+	for _, chunkHandle := range previous {
+		before[chunkHandle] = struct{}{}
+	}
+
+	for chunkHandle := range reported {
+		if _, known := before[chunkHandle]; !known {
+			idx.add(chunkHandle, address)
+		}
+	}
+	for chunkHandle := range before {
+		if _, kept := reported[chunkHandle]; !kept {
+			idx.remove(chunkHandle, address)
+		}
+	}
+}
+
+func (idx *locationIndex) index(address, chunkHandle string) {
+	handles, exists := idx.byServer[address]
+	if !exists {
+		handles = make(map[string]struct{})
+		idx.byServer[address] = handles
+	}
+	handles[chunkHandle] = struct{}{}
+}
+
+func (idx *locationIndex) unindex(address, chunkHandle string) {
+	delete(idx.byServer[address], chunkHandle)
+	if len(idx.byServer[address]) == 0 {
+		delete(idx.byServer, address)
+	}
+}
+
+// ChunkLocations returns the servers known to hold a replica of a chunk, along with the given
+// locations it was last seen at, e.g. those recorded when the chunk was deleted
+func (m *Metadata) ChunkLocations(chunkHandle string, seen []string) []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	locations := slices.Clone(seen)
+	for _, address := range m.locations.byChunk[chunkHandle] {
+		if !slices.Contains(locations, address) {
+			locations = append(locations, address)
+		}
+	}
+
+	return locations
+}
@@ -0,0 +1,325 @@
+package master
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// MemoryStore is a MetadataStore keeping everything in maps, the namespace is lost when the master stops
+type MemoryStore struct {
+	mu       sync.RWMutex
+	files    map[string]*FileMetadata  // key: filename, value: file metadata
+	chunks   map[string]*ChunkMetadata // key: chunk handle, value: chunk metadata
+	versions map[string]int32          // key: chunk handle, value: latest version handed out, kept after deletes
+	inodes   map[string]*Inode         // key: inode id, value: names, owner and mode shared by hard links
+
+	tombstones map[string]*Tombstone    // key: tombstone id, value: deleted chunks waiting to be reclaimed
+	revoked    map[string]*RevokedToken // key: delegation token id, value: revocation of the token
+}
+
+// NewMemoryStore creates a new in-memory metadata store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		files:    make(map[string]*FileMetadata),
+		chunks:   make(map[string]*ChunkMetadata),
+		versions: make(map[string]int32),
+		inodes:   make(map[string]*Inode),
+
+		tombstones: make(map[string]*Tombstone),
+		revoked:    make(map[string]*RevokedToken),
+	}
+}
+
+// GetFile implements MetadataStore
+func (s *MemoryStore) GetFile(filename string) (*FileMetadata, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	file, exists := s.files[filename]
+	if !exists {
+		return nil, false, nil
+	}
+
+	return file.clone(), true, nil
+}
+
+// ForEachFile implements MetadataStore
+func (s *MemoryStore) ForEachFile(prefix string, fn func(file *FileMetadata) error) error {
+	return s.ForEachFileAfter(prefix, "", fn)
+}
+
+// ForEachFileAfter implements MetadataStore
+func (s *MemoryStore) ForEachFileAfter(prefix, after string, fn func(file *FileMetadata) error) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, filename := range slices.Sorted(maps.Keys(s.files)) {
+		if !strings.HasPrefix(filename, prefix) || filename <= after {
+			continue
+		}
+
+		if err := fn(s.files[filename].clone()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetInode implements MetadataStore
+func (s *MemoryStore) GetInode(id string) (*Inode, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	inode, exists := s.inodes[id]
+	if !exists {
+		return nil, false, nil
+	}
+
+	return inode.clone(), true, nil
+}
+
+// GetChunk implements MetadataStore
+func (s *MemoryStore) GetChunk(chunkHandle string) (*ChunkMetadata, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	chunk, exists := s.chunks[chunkHandle]
+	if !exists {
+		return nil, false, nil
+	}
+
+	return chunk.clone(), true, nil
+}
+
+// ForEachChunk implements MetadataStore
+func (s *MemoryStore) ForEachChunk(fn func(chunk *ChunkMetadata) error) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, chunk := range s.chunks {
+		if err := fn(chunk.clone()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetChunkVersion implements MetadataStore
+func (s *MemoryStore) GetChunkVersion(chunkHandle string) (int32, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.versions[chunkHandle], nil
+}
+
+// ForEachTombstone implements MetadataStore
+func (s *MemoryStore) ForEachTombstone(fn func(tombstone *Tombstone) error) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, id := range slices.Sorted(maps.Keys(s.tombstones)) {
+		if err := fn(s.tombstones[id].clone()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetRevokedToken implements MetadataStore
+func (s *MemoryStore) GetRevokedToken(id string) (*RevokedToken, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	revoked, exists := s.revoked[id]
+	if !exists {
+		return nil, false, nil
+	}
+
+	return revoked.clone(), true, nil
+}
+
+// ForEachRevokedToken implements MetadataStore
+func (s *MemoryStore) ForEachRevokedToken(fn func(revoked *RevokedToken) error) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, revoked := range s.revoked {
+		if err := fn(revoked.clone()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Update implements MetadataStore. The store is locked for the whole transaction, whose changes are kept
+// aside and only applied once fn succeeds
+func (s *MemoryStore) Update(fn func(tx MetadataTx) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx := &memoryTx{
+		store:      s,
+		files:      make(map[string]*FileMetadata),
+		inodes:     make(map[string]*Inode),
+		chunks:     make(map[string]*ChunkMetadata),
+		versions:   make(map[string]int32),
+		tombstones: make(map[string]*Tombstone),
+		revoked:    make(map[string]*RevokedToken),
+	}
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	apply(s.files, tx.files)
+	apply(s.inodes, tx.inodes)
+	apply(s.chunks, tx.chunks)
+	maps.Copy(s.versions, tx.versions)
+	apply(s.tombstones, tx.tombstones)
+	apply(s.revoked, tx.revoked)
+	return nil
+}
+
+// apply writes the records changed by a transaction, nil ones being deleted
+func apply[V any](records map[string]*V, changes map[string]*V) {
+	for key, record := range changes {
+		if record == nil {
+			delete(records, key)
+		} else {
+			records[key] = record
+		}
+	}
+}
+
+// memoryTx is a transaction of a MemoryStore, keeping the records it writes until it succeeds
+type memoryTx struct {
+	store      *MemoryStore
+	files      map[string]*FileMetadata  // key: filename, value: file written, nil for deleted
+	inodes     map[string]*Inode         // key: inode id, value: inode written, nil for deleted
+	chunks     map[string]*ChunkMetadata // key: chunk handle, value: chunk written, nil for deleted
+	versions   map[string]int32          // key: chunk handle, value: latest version handed out
+	tombstones map[string]*Tombstone     // key: tombstone id, value: tombstone written, nil for deleted
+	revoked    map[string]*RevokedToken  // key: delegation token id, value: revocation written, nil for deleted
+}
+
+// read returns a copy of the record under key, the one written by the transaction if there is one
+func read[V any](changes, records map[string]*V, key string, clone func(*V) *V) (*V, bool) {
+	record, changed := changes[key]
+	if !changed {
+		record = records[key]
+	}
+	if record == nil {
+		return nil, false
+	}
+
+	return clone(record), true
+}
+
+// GetFile implements MetadataTx
+func (tx *memoryTx) GetFile(filename string) (*FileMetadata, bool, error) {
+	file, exists := read(tx.files, tx.store.files, filename, (*FileMetadata).clone)
+	return file, exists, nil
+}
+
+// PutFile implements MetadataTx
+func (tx *memoryTx) PutFile(file *FileMetadata) error {
+	tx.files[file.Filename] = file.clone()
+	return nil
+}
+
+// DeleteFile implements MetadataTx
+func (tx *memoryTx) DeleteFile(filename string) error {
+	tx.files[filename] = nil
+	return nil
+}
+
+// GetInode implements MetadataTx
+func (tx *memoryTx) GetInode(id string) (*Inode, bool, error) {
+	inode, exists := read(tx.inodes, tx.store.inodes, id, (*Inode).clone)
+	return inode, exists, nil
+}
+
+// PutInode implements MetadataTx
+func (tx *memoryTx) PutInode(inode *Inode) error {
+	tx.inodes[inode.ID] = inode.clone()
+	return nil
+}
+
+// DeleteInode implements MetadataTx
+func (tx *memoryTx) DeleteInode(id string) error {
+	tx.inodes[id] = nil
+	return nil
+}
+
+// GetChunk implements MetadataTx
+func (tx *memoryTx) GetChunk(chunkHandle string) (*ChunkMetadata, bool, error) {
+	chunk, exists := read(tx.chunks, tx.store.chunks, chunkHandle, (*ChunkMetadata).clone)
+	return chunk, exists, nil
+}
+
+// PutChunk implements MetadataTx
+func (tx *memoryTx) PutChunk(chunk *ChunkMetadata) error {
+	tx.chunks[chunk.ChunkHandle] = chunk.clone()
+	return nil
+}
+
+// DeleteChunk implements MetadataTx
+func (tx *memoryTx) DeleteChunk(chunkHandle string) error {
+	tx.chunks[chunkHandle] = nil
+	return nil
+}
+
+// GetChunkVersion implements MetadataTx
+func (tx *memoryTx) GetChunkVersion(chunkHandle string) (int32, error) {
+	if version, changed := tx.versions[chunkHandle]; changed {
+		return version, nil
+	}
+
+	return tx.store.versions[chunkHandle], nil
+}
+
+// PutChunkVersion implements MetadataTx
+func (tx *memoryTx) PutChunkVersion(chunkHandle string, version int32) error {
+	tx.versions[chunkHandle] = version
+	return nil
+}
+
+// GetTombstone implements MetadataTx
+func (tx *memoryTx) GetTombstone(id string) (*Tombstone, bool, error) {
+	tombstone, exists := read(tx.tombstones, tx.store.tombstones, id, (*Tombstone).clone)
+	return tombstone, exists, nil
+}
+
+// PutTombstone implements MetadataTx
+func (tx *memoryTx) PutTombstone(tombstone *Tombstone) error {
+	tx.tombstones[tombstone.ID] = tombstone.clone()
+	return nil
+}
+
+// DeleteTombstone implements MetadataTx
+func (tx *memoryTx) DeleteTombstone(id string) error {
+	tx.tombstones[id] = nil
+	return nil
+}
+
+// PutRevokedToken implements MetadataTx
+func (tx *memoryTx) PutRevokedToken(revoked *RevokedToken) error {
+	tx.revoked[revoked.ID] = revoked.clone()
+	return nil
+}
+
+// DeleteRevokedToken implements MetadataTx
+func (tx *memoryTx) DeleteRevokedToken(id string) error {
+	tx.revoked[id] = nil
+	return nil
+}
+
+// Close implements MetadataStore
+func (s *MemoryStore) Close() error {
+	return nil
+}
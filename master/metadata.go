@@ -1,18 +1,51 @@
 package master
 
 import (
+	"cmp"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
 	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/harshvardha/distributed_file_system/common"
 )
 
 // FileMetadata represents metadata for a file
 type FileMetadata struct {
-	Filename   string
-	Filesize   int64
-	ChunkCount int
-	Chunks     []string // chunk handles
-	CreatedAt  time.Time
+	Filename     string
+	Filesize     int64
+	ChunkCount   int
+	Chunks       []string  // chunk handles
+	CreatedAt    time.Time // when the name was first used, kept across overwrites
+	ModifiedAt   time.Time // when the current contents were written
+	Generation   int64     // changes whenever the file is written, copied over or renamed
+	Immutable    bool      // the file can't be deleted, renamed or overwritten
+	RetainUntil  time.Time // when an immutable file becomes mutable again, zero never
+	ReadCount    int64
+	LastAccessed time.Time         // zero if the file was never read
+	Versions     []FileVersion     // previous versions kept after overwrites, newest first
+	Tags         map[string]string // user defined key value tags
+	// ReplicationFactor is the number of replicas of the file's chunks, 0 for common.ReplicationFactor
+	ReplicationFactor int
+	ExpiresAt         time.Time // when the file is deleted, zero never
+	Owner             string    // user that created the file, empty if created anonymously
+	Group             string    // group owning the file, the owner's primary group unless changed
+	Mode              uint32    // file type and permission bits, 0 for files created before modes were recorded
+	SymlinkTarget     string    // name of the file a symlink points at, empty for files with contents
+	Inode             string    // id of the inode a hard linked file shares with its other names, empty for files without links
+	Data              []byte    // contents of a file small enough to be stored inline, nil for files stored in chunks
+	Tier              string    // tier of the chunk servers the file's chunks are placed on, empty for any
+	ArchivedSize      int64     // compressed size of the contents of an archived file, 0 for files that aren't archived
+	StorageClass      string    // standard, reduced-redundancy or archive, empty for standard
+	// Upload is the file an overwriting upload replaces this one with once it completes, nil when none is staged
+	Upload *FileMetadata
+	// Links are the other names of a hard linked file, read from its inode along with the owner and mode they share
+	Links []string `json:"-"`
 }
 
 // ChunkMetadata represents metadata for a chunk
@@ -22,154 +55,1276 @@ type ChunkMetadata struct {
 	Version     int32
 	Filename    string
 	ChunkIndex  int32
+	// ReplicationFactor is the number of replicas the chunk's file asks for, 0 for common.ReplicationFactor
+	ReplicationFactor int
+	// References is the number of file versions using the chunk since it was cloned, 0 for chunks never shared
+	References int
+	Tier       string // tier of the chunk servers the chunk's file asks for, empty for any
+}
+
+// replicationFactor returns the number of replicas the chunk should have when it isn't hot
+func (c *ChunkMetadata) replicationFactor() int {
+	if c.ReplicationFactor > 0 {
+		return c.ReplicationFactor
+	}
+
+	return common.ReplicationFactor
 }
 
+// initialChunkVersion is the version assigned to newly allocated chunks
+const initialChunkVersion = 1
+
 // ChunkServerInfo represents a chunk server
 type ChunkServerInfo struct {
 	Address         string
+	Zone            string
+	Tier            string // storage media, e.g. ssd or hdd, empty if the server didn't announce one
+	State           ChunkServerState
 	LatestHeartbeat time.Time
 	Chunks          []string // chunk handles stored on this server
+	CapacityBytes   int64
+	FreeBytes       int64
+	Load            ChunkServerLoad
+
+	// ServerID identifies the chunk server's storage, so two processes claiming the same address
+	// or the same storage are told apart. Empty for servers predating server ids
+	ServerID string
+
+	// announced by RegisterChunkServer, empty for servers that only ever sent heartbeats
+	Version            string
+	Labels             map[string]string
+	StorageDirectories []StorageDirectory
+}
+
+// StorageDirectory is one of the directories a chunk server spreads its chunks across
+type StorageDirectory struct {
+	Path          string
+	CapacityBytes int64
+	FreeBytes     int64
+	ChunkCount    int64
+	Healthy       bool
+}
+
+// ChunkServerRegistration is what a starting chunk server announces about itself
+type ChunkServerRegistration struct {
+	Address            string
+	ServerID           string
+	Version            string
+	Labels             map[string]string // the zone label is the server's failure domain
+	Chunks             []string
+	CapacityBytes      int64
+	FreeBytes          int64
+	StorageDirectories []StorageDirectory
 }
 
-// Metadata manages all the metadata for the dfs
+// ChunkServerLoad is the load a chunk server reported in its latest heartbeat
+type ChunkServerLoad struct {
+	IOPS                  float64
+	QueueDepth            int
+	CPUUtilization        float64
+	NetworkInBytesPerSec  float64
+	NetworkOutBytesPerSec float64
+}
+
+// reference values for a busy chunk server, used to bring the load metrics to a common scale
+const (
+	busyIOPS               = 100
+	busyNetworkBytesPerSec = 100 * 1024 * 1024
+)
+
+// Score combines the load metrics into a single number where 0 is idle and roughly 1 per metric is busy.
+// Queued operations weigh the most since they directly delay every new request
+func (l ChunkServerLoad) Score() float64 {
+	return float64(l.QueueDepth) +
+		l.CPUUtilization +
+		l.IOPS/busyIOPS +
+		(l.NetworkInBytesPerSec+l.NetworkOutBytesPerSec)/busyNetworkBytesPerSec
+}
+
+// ChunkServerState is the liveness of a chunk server as seen by the master
+type ChunkServerState string
+
+const (
+	// ChunkServerAlive servers send heartbeats and receive new chunks
+	ChunkServerAlive ChunkServerState = "ALIVE"
+
+	// ChunkServerDead servers missed heartbeats for longer than the dead server timeout, their replicas are not used
+	ChunkServerDead ChunkServerState = "DEAD"
+)
+
+// ErrChunkServerConflict is returned when a chunk server claims the address or the identity of another live chunk server
+var ErrChunkServerConflict = errors.New("conflicting chunk server")
+
+// heartbeatInterval is how often chunk servers are told to send heartbeats when they register
+const heartbeatInterval = 10 * time.Second
+
+// DefaultDeadServerTimeout is how long a chunk server may go without a heartbeat before it is considered dead
+// unless configured otherwise
+const DefaultDeadServerTimeout = 30 * time.Second
+
+// staleServerTimeout is how long a chunk server may go without a heartbeat before its replicas are
+// read last, it missed at least one heartbeat and may be on its way to being declared dead
+const staleServerTimeout = 15 * time.Second
+
+// DiskUsage summarizes the space consumed by a group of files
+type DiskUsage struct {
+	Path          string
+	LogicalBytes  int64
+	PhysicalBytes int64
+	FileCount     int64
+}
+
+// ChunkServerUsage describes how much chunk data a chunk server holds
+type ChunkServerUsage struct {
+	Address       string
+	ChunkCount    int64
+	Bytes         int64
+	CapacityBytes int64
+	FreeBytes     int64
+	State         ChunkServerState
+	LastHeartbeat time.Time
+	Version       string
+	Labels        map[string]string
+}
+
+// ClusterStats aggregates capacity, namespace and chunk server statistics of the whole cluster
+type ClusterStats struct {
+	CapacityBytes         int64
+	UsedBytes             int64
+	FreeBytes             int64
+	FileCount             int64
+	ChunkCount            int64
+	LiveChunkServers      int
+	DeadChunkServers      int
+	UnderReplicatedChunks int64
+
+	PendingReclamationChunks int64 // chunks of tombstones not reclaimed yet
+}
+
+// Metadata manages all the metadata for the dfs. The namespace lives in a MetadataStore, chunk servers,
+// chunk locations and read statistics are rebuilt from heartbeats and kept in memory
 type Metadata struct {
 	mu           sync.RWMutex
-	files        map[string]*FileMetadata    // key: filename, value: file metadata
-	chunks       map[string]*ChunkMetadata   // key: chunk handle, value: chunk metadata
+	store        MetadataStore
 	chunkServers map[string]*ChunkServerInfo // key: address, value: chunk server info
+	locations    *locationIndex
+	readStats    map[string]*chunkReadStats // key: chunk handle, value: read statistics of recently read chunks
+	leases       map[string]*chunkLease     // key: chunk handle, value: lease of the replica ordering writes to it
+	// leaseGrace is when leases an earlier master granted before this one started have all expired. Until then
+	// only chunks created since the start, kept in newChunks, get leases
+	leaseGrace time.Time
+	newChunks  map[string]bool
+
+	index             *searchIndex
+	readsMu           sync.Mutex
+	fileReads         map[string]*fileRead // key: filename, value: reads not yet written to the store
+	lastGeneration    int64                // latest generation handed out
+	deadServerTimeout atomic.Int64         // nanoseconds a chunk server may go without a heartbeat before it is dead
 }
 
-// NewMetadata creates a new metadata manager
-func NewMetadata() *Metadata {
-	return &Metadata{
-		files:        make(map[string]*FileMetadata),
-		chunks:       make(map[string]*ChunkMetadata),
+// NewMetadata creates a new metadata manager keeping the namespace in store, indexing the files already in it for search
+func NewMetadata(store MetadataStore) (*Metadata, error) {
+	m := &Metadata{
+		store:        store,
 		chunkServers: make(map[string]*ChunkServerInfo),
+		locations:    newLocationIndex(),
+		readStats:    make(map[string]*chunkReadStats),
+		leases:       make(map[string]*chunkLease),
+		leaseGrace:   time.Now().Add(leaseDuration),
+		newChunks:    make(map[string]bool),
+		fileReads:    make(map[string]*fileRead),
+		index:        newSearchIndex(),
 	}
+	m.SetDeadServerTimeout(DefaultDeadServerTimeout)
+
+	// watching before indexing, changes made meanwhile are applied again once the index is built
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if watcher, ok := store.(FileWatcher); ok {
+		if err := watcher.WatchFiles(m.fileChanged, m.filesMissed); err != nil {
+			return nil, fmt.Errorf("failed to watch files: %v", err)
+		}
+	}
+	if err := m.buildIndex(); err != nil {
+		return nil, fmt.Errorf("failed to index files: %v", err)
+	}
+
+	return m, nil
 }
 
-// AddFile adds a new File to the metadata
-func (m *Metadata) AddFile(filename string, filesize int64, chunkCount int) {
+// DeadServerTimeout returns how long a chunk server may go without a heartbeat before it is considered dead
+func (m *Metadata) DeadServerTimeout() time.Duration {
+	return time.Duration(m.deadServerTimeout.Load())
+}
+
+// SetDeadServerTimeout changes how long a chunk server may go without a heartbeat before it is considered dead
+func (m *Metadata) SetDeadServerTimeout(timeout time.Duration) {
+	m.deadServerTimeout.Store(int64(timeout))
+}
+
+// Close closes the metadata store, after which the namespace can no longer be read or changed
+func (m *Metadata) Close() error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	m.files[filename] = &FileMetadata{
+	return m.store.Close()
+}
+
+// AddFile adds a new File to the metadata and returns its generation. A file already using the name is
+// replaced, keeping up to keepVersions of its versions as previous versions of the new file. It also
+// returns the chunks of the replaced versions that weren't kept, whose replicas the caller is responsible for deleting.
+// A new file gets the ownership, a replaced file keeps its owner and mode
+func (m *Metadata) AddFile(filename string, filesize int64, chunkCount int, keepVersions int, ownership FileOwnership) (int64, []*ChunkMetadata, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	file := &FileMetadata{
 		Filename:   filename,
 		Filesize:   filesize,
 		ChunkCount: chunkCount,
 		Chunks:     make([]string, 0, chunkCount),
-		CreatedAt:  time.Now(),
+		CreatedAt:  now,
+		ModifiedAt: now,
+		Generation: m.nextGeneration(),
+	}
+	file.setOwnership(ownership)
+
+	var chunks []*ChunkMetadata
+	err := m.update(func(tx *metadataTx) error {
+		var err error
+		chunks, err = tx.replaceFile(file, keepVersions)
+		return err
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return file.Generation, chunks, nil
+}
+
+// FileAllocation describes a file AllocateFile adds along with its chunks
+type FileAllocation struct {
+	Filename     string
+	Filesize     int64
+	ChunkCount   int // chunks added for the file, none for files stored inline
+	KeepVersions int
+	Ownership    FileOwnership
+	Attributes   AttributeUpdate // tags, tier and storage class the file starts with
+	Data         []byte          // contents of a file stored inline, nil for files stored in chunks
+}
+
+// AllocateFile adds a new file like AddFile together with its attributes, inline contents and chunks in a
+// single transaction, so an upload whose allocation fails leaves no trace in the namespace. A file already
+// using the name isn't replaced yet, the new file is staged beside it until CommitUpload swaps them, so an
+// overwrite that never completes leaves the file as it was. It returns the new file's generation, its chunks
+// in order and the chunks of an earlier staged upload it replaced, whose replicas the caller is responsible for deleting
+func (m *Metadata) AllocateFile(allocation FileAllocation) (int64, []*ChunkMetadata, []*ChunkMetadata, error) {
+	if err := allocation.Attributes.validate(); err != nil {
+		return 0, nil, nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	generation := m.nextGeneration()
+	var chunks, dropped []*ChunkMetadata
+	err := m.update(func(tx *metadataTx) error {
+		file := &FileMetadata{
+			Filename:   allocation.Filename,
+			Filesize:   allocation.Filesize,
+			ChunkCount: allocation.ChunkCount,
+			Chunks:     make([]string, 0, allocation.ChunkCount),
+			CreatedAt:  now,
+			ModifiedAt: now,
+			Generation: generation,
+			Data:       allocation.Data,
+		}
+		file.setOwnership(allocation.Ownership)
+		if err := file.applyAttributes(allocation.Attributes); err != nil {
+			return err
+		}
+
+		chunks = make([]*ChunkMetadata, 0, allocation.ChunkCount)
+		for i := range allocation.ChunkCount {
+			chunk, err := tx.addChunk(common.GenerateChunkHandle(file.Filename, generation, i), file.Filename, int32(i))
+			if err != nil {
+				return err
+			}
+
+			chunk.ReplicationFactor = file.ReplicationFactor
+			chunk.Tier = file.Tier
+			if err := tx.PutChunk(chunk); err != nil {
+				return err
+			}
+			chunks = append(chunks, chunk)
+			file.Chunks = append(file.Chunks, chunk.ChunkHandle)
+		}
+
+		existing, exists, err := tx.GetFile(file.Filename)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return tx.PutFile(file)
+		}
+
+		// an upload staged before the master restarted can no longer complete
+		if existing.Upload != nil {
+			if dropped, err = tx.removeChunks(existing.Upload.Chunks); err != nil {
+				return err
+			}
+		}
+		existing.Upload = file
+		return tx.PutFile(existing)
+	})
+	if err != nil {
+		return 0, nil, nil, err
+	}
+
+	return generation, chunks, dropped, nil
+}
+
+// CommitUpload makes the file staged by the upload with the given generation the current one, keeping up to
+// keepVersions of the versions it replaces. It returns the committed file, whether it replaced a staged
+// overwrite's file, and the chunks of the versions that weren't kept, whose replicas the caller is responsible
+// for deleting. A file the upload created is current already, an upload whose generation is neither staged
+// nor current fails with ErrUploadSuperseded
+func (m *Metadata) CommitUpload(filename string, generation int64, keepVersions int) (*FileMetadata, bool, []*ChunkMetadata, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var committed *FileMetadata
+	var dropped []*ChunkMetadata
+	replaced := false
+	err := m.update(func(tx *metadataTx) error {
+		file, exists, err := tx.GetFile(filename)
+		if err != nil {
+			return err
+		}
+		if exists && file.Generation == generation {
+			committed, replaced = file, false
+			return nil
+		}
+		if !exists || file.Upload == nil || file.Upload.Generation != generation {
+			return ErrUploadSuperseded
+		}
+
+		committed, replaced = file.Upload, true
+		committed.ModifiedAt = time.Now()
+		dropped, err = tx.replaceFile(committed, keepVersions)
+		return err
+	})
+	if err != nil {
+		return nil, false, nil, err
+	}
+
+	return committed, replaced, dropped, nil
+}
+
+// GetUpload returns the file being written by the upload with the given generation, staged beside
+// the file it replaces or the file itself when the upload created it. It returns false if the
+// upload was superseded
+func (m *Metadata) GetUpload(filename string, generation int64) (*FileMetadata, bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	file, exists, err := m.store.GetFile(filename)
+	if err != nil || !exists {
+		return nil, false, err
+	}
+
+	upload, exists := file.upload(generation)
+	return upload, exists, nil
+}
+
+// upload returns the file the upload with the given generation writes, false if it is neither staged nor current
+func (f *FileMetadata) upload(generation int64) (*FileMetadata, bool) {
+	if f.Upload != nil && f.Upload.Generation == generation {
+		return f.Upload, true
+	}
+	if f.Generation == generation {
+		return f, true
 	}
+
+	return nil, false
 }
 
-// AddChunkToFile adds a chunk handle to a file's chunk list
-func (m *Metadata) AddChunkToFile(filename string, chunkHandle string) {
+// AddCopiedFile adds a file whose chunks were already written, e.g. copied from another file, together with its
+// attributes, inline contents and chunks in a single transaction. It replaces a file already using the name
+// like AddFile does and returns the chunks of the replaced versions that weren't kept, whose replicas the
+// caller is responsible for deleting. The chunks must use handles of generation, the file's new generation
+func (m *Metadata) AddCopiedFile(allocation FileAllocation, generation int64, chunks []*ChunkMetadata) ([]*ChunkMetadata, error) {
+	if err := allocation.Attributes.validate(); err != nil {
+		return nil, err
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	if file, exists := m.files[filename]; exists {
-		file.Chunks = append(file.Chunks, chunkHandle)
+	now := time.Now()
+	var dropped []*ChunkMetadata
+	err := m.update(func(tx *metadataTx) error {
+		file := &FileMetadata{
+			Filename:   allocation.Filename,
+			Filesize:   allocation.Filesize,
+			ChunkCount: allocation.ChunkCount,
+			Chunks:     make([]string, 0, len(chunks)),
+			CreatedAt:  now,
+			ModifiedAt: now,
+			Generation: generation,
+			Data:       allocation.Data,
+		}
+		file.setOwnership(allocation.Ownership)
+		if err := file.applyAttributes(allocation.Attributes); err != nil {
+			return err
+		}
+
+		for _, chunk := range chunks {
+			// the replicas were written with the chunk's version, which must be newer than any earlier use of the handle
+			latest, err := tx.GetChunkVersion(chunk.ChunkHandle)
+			if err != nil {
+				return err
+			}
+			if latest >= chunk.Version {
+				return fmt.Errorf("chunk %s was written with version %d, version %d is in use", chunk.ChunkHandle, chunk.Version, latest)
+			}
+			if err := tx.PutChunkVersion(chunk.ChunkHandle, chunk.Version); err != nil {
+				return err
+			}
+			tx.m.trackNewChunk(chunk.ChunkHandle)
+
+			chunk.ReplicationFactor = file.ReplicationFactor
+			chunk.Tier = file.Tier
+			if err := tx.PutChunk(chunk); err != nil {
+				return err
+			}
+			file.Chunks = append(file.Chunks, chunk.ChunkHandle)
+		}
+
+		var err error
+		dropped, err = tx.replaceFile(file, allocation.KeepVersions)
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
+
+	return dropped, nil
 }
 
-// AddChunk adds chunk metadata
-func (m *Metadata) AddChunk(chunkHandle string, filename string, chunkIndex int32) {
+// NewGeneration returns a new file generation, for chunks written before their file is added
+func (m *Metadata) NewGeneration() int64 {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	m.chunks[chunkHandle] = &ChunkMetadata{
+	return m.nextGeneration()
+}
+
+// replaceFile stores file in place of the file using its name, keeping up to keepVersions of the replaced file's
+// versions as previous versions. It returns the chunks of the versions that weren't kept
+func (tx *metadataTx) replaceFile(file *FileMetadata, keepVersions int) ([]*ChunkMetadata, error) {
+	existing, exists, err := tx.GetFile(file.Filename)
+	if err != nil {
+		return nil, err
+	}
+
+	dropped := make([]string, 0)
+	if exists {
+		file.CreatedAt = existing.CreatedAt
+		if existing.Owner != "" {
+			file.Owner, file.Group, file.Mode = existing.Owner, existing.Group, existing.Mode
+		}
+		// the new contents are only the name's, like writing a new file over a hard link
+		if err := tx.unlink(existing); err != nil {
+			return nil, err
+		}
+		file.Versions = append([]FileVersion{existing.asVersion(file.ModifiedAt)}, existing.Versions...)
+		for _, version := range file.Versions[min(keepVersions, len(file.Versions)):] {
+			dropped = append(dropped, version.Chunks...)
+		}
+		file.Versions = file.Versions[:min(keepVersions, len(file.Versions))]
+	}
+
+	if err := tx.PutFile(file); err != nil {
+		return nil, err
+	}
+
+	return tx.removeChunks(dropped)
+}
+
+// nextGeneration returns a new file generation. Generations are the current time in nanoseconds so
+// they keep growing across master restarts, bumped when the clock hasn't moved. The caller must hold the lock
+func (m *Metadata) nextGeneration() int64 {
+	m.lastGeneration = max(time.Now().UnixNano(), m.lastGeneration+1)
+	return m.lastGeneration
+}
+
+// RenameFile moves a file to a new name with a new generation, keeping its chunks and previous versions. It returns
+// the new generation, false if the source doesn't exist. The destination must not exist
+func (m *Metadata) RenameFile(source, destination string) (int64, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var generation int64
+	exists := false
+	err := m.update(func(tx *metadataTx) error {
+		var file *FileMetadata
+		var err error
+		file, exists, err = tx.GetFile(source)
+		if err != nil || !exists {
+			return err
+		}
+
+		file.Filename = destination
+		file.Generation = m.nextGeneration()
+		generation = file.Generation
+		if err := tx.PutFile(file); err != nil {
+			return err
+		}
+
+		for _, chunkHandle := range file.chunkHandles() {
+			chunk, exists, err := tx.GetChunk(chunkHandle)
+			if err != nil {
+				return err
+			}
+			if !exists {
+				continue
+			}
+
+			chunk.Filename = destination
+			if err := tx.PutChunk(chunk); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.DeleteFile(source); err != nil {
+			return err
+		}
+
+		return tx.renameLink(file, source)
+	})
+	if err != nil || !exists {
+		return 0, exists, err
+	}
+
+	return generation, true, nil
+}
+
+// AddChunk adds chunk metadata and returns the chunk version to write it with. Should a chunk handle
+// be reused, the reuse gets a newer version than any replica of the handle that may still be on a chunk server
+func (m *Metadata) AddChunk(chunkHandle string, filename string, chunkIndex int32) (int32, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var chunk *ChunkMetadata
+	err := m.update(func(tx *metadataTx) error {
+		var err error
+		chunk, err = tx.addChunk(chunkHandle, filename, chunkIndex)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return chunk.Version, nil
+}
+
+// addChunk adds chunk metadata with a version newer than any earlier use of the handle
+func (tx *metadataTx) addChunk(chunkHandle string, filename string, chunkIndex int32) (*ChunkMetadata, error) {
+	latest, err := tx.GetChunkVersion(chunkHandle)
+	if err != nil {
+		return nil, err
+	}
+
+	version := max(latest+1, initialChunkVersion)
+	if err := tx.PutChunkVersion(chunkHandle, version); err != nil {
+		return nil, err
+	}
+	tx.m.trackNewChunk(chunkHandle)
+
+	chunk := &ChunkMetadata{
 		ChunkHandle: chunkHandle,
 		Locations:   make([]string, 0),
-		Version:     1,
+		Version:     version,
 		Filename:    filename,
 		ChunkIndex:  chunkIndex,
 	}
+	if err := tx.PutChunk(chunk); err != nil {
+		return nil, err
+	}
+
+	return chunk, nil
 }
 
 // AddChunkLocation adds a chunk server location for a chunk
-func (m *Metadata) AddChunkLocation(chunkHandle string, serverAddress string) {
+func (m *Metadata) AddChunkLocation(chunkHandle string, serverAddress string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.locations.add(chunkHandle, serverAddress)
+	return nil
+}
+
+// RemoveChunkLocation removes a chunk server location from a chunk
+func (m *Metadata) RemoveChunkLocation(chunkHandle string, serverAddress string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.locations.remove(chunkHandle, serverAddress)
+	return nil
+}
+
+// ErrLastReplica is returned when removing a replica would leave a chunk without any known location
+var ErrLastReplica = errors.New("last known replica of the chunk")
+
+// RemoveCorruptReplica removes a corrupt replica from the locations of a chunk. The chunk's last known replica
+// is kept, failing with ErrLastReplica, so the chunk is never forgotten by the master. It reports whether the
+// server was a location of the chunk
+func (m *Metadata) RemoveCorruptReplica(chunkHandle string, serverAddress string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	locations := m.locations.get(chunkHandle)
+	if !slices.Contains(locations, serverAddress) {
+		return false, nil
+	}
+	if len(locations) == 1 {
+		return true, ErrLastReplica
+	}
+
+	m.locations.remove(chunkHandle, serverAddress)
+	return true, nil
+}
+
+// ChunkReplication returns the number of known replicas of a chunk and the number of replicas it should have
+func (m *Metadata) ChunkReplication(chunkHandle string) (int, int, bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	chunk, exists, err := m.getChunk(chunkHandle)
+	if err != nil || !exists {
+		return 0, 0, false, err
+	}
+
+	return len(chunk.Locations), m.targetReplicas(chunk), true, nil
+}
+
+// FileReplication returns the fewest replicas of any chunk of the current version of a file and whether
+// some chunk has fewer replicas than the file's replication factor. Chunks without known replicas count as having none
+func (m *Metadata) FileReplication(file *FileMetadata) (int, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if len(file.Chunks) == 0 {
+		return file.replicationFactor(), false
+	}
+
+	// replica counts come from the chunk locations in memory, listing files reads no chunk records
+	minReplicas := math.MaxInt
+	degraded := false
+	for _, chunkHandle := range file.Chunks {
+		replicas := len(m.locations.byChunk[chunkHandle])
+		minReplicas = min(minReplicas, replicas)
+		degraded = degraded || replicas < file.replicationFactor()
+	}
+
+	return minReplicas, degraded
+}
+
+// TrimChunkLocations removes locations of a chunk beyond its target replicas, picking the least loaded
+// holders so reclaiming space disturbs busy servers the least. It returns the removed locations, whose
+// replicas the caller is responsible for deleting
+func (m *Metadata) TrimChunkLocations(chunkHandle string) ([]string, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	if chunk, exists := m.chunks[chunkHandle]; exists {
-		// if the location already exist then return to avoid duplicates
-		if slices.Contains(chunk.Locations, serverAddress) {
-			return
+	chunk, exists, err := m.getChunk(chunkHandle)
+	if err != nil || !exists {
+		return nil, err
+	}
+
+	target := m.targetReplicas(chunk)
+	removed := make([]string, 0)
+	for len(chunk.Locations) > target {
+		// replicas on another tier than the chunk asks for go first
+		leastLoaded := 0
+		for i, location := range chunk.Locations {
+			onTier, leastOnTier := m.onTier(location, chunk.Tier), m.onTier(chunk.Locations[leastLoaded], chunk.Tier)
+			if onTier != leastOnTier {
+				if !onTier {
+					leastLoaded = i
+				}
+				continue
+			}
+			if m.chunkServerLoad(location) < m.chunkServerLoad(chunk.Locations[leastLoaded]) {
+				leastLoaded = i
+			}
 		}
 
-		chunk.Locations = append(chunk.Locations, serverAddress)
+		removed = append(removed, chunk.Locations[leastLoaded])
+		chunk.Locations = slices.Delete(chunk.Locations, leastLoaded, leastLoaded+1)
+	}
+
+	for _, address := range removed {
+		m.locations.remove(chunkHandle, address)
 	}
+
+	return removed, nil
+}
+
+// chunkServerLoad returns the load score of a chunk server, servers
+// that never sent a heartbeat have no load. The caller must hold the lock
+func (m *Metadata) chunkServerLoad(address string) float64 {
+	server, exists := m.chunkServers[address]
+	if !exists {
+		return 0
+	}
+
+	return server.Load.Score()
 }
 
-// GetFile fetches the file metadata
-func (m *Metadata) GetFile(filename string) (*FileMetadata, bool) {
+// ReadReplicas returns the chunk server addresses a client should read a chunk from, least loaded first.
+// Servers that missed a heartbeat come after the others and servers past the dead server timeout are left out,
+// so clients don't wait out timeouts on dead servers. Servers the master hasn't heard from since it
+// started are kept last, they may just not have sent their first heartbeat yet
+func (m *Metadata) ReadReplicas(addresses []string) []string {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	file, exists := m.files[filename]
-	return file, exists
+	now := time.Now()
+	staleness := func(address string) int {
+		if server, exists := m.chunkServers[address]; exists && now.Sub(server.LatestHeartbeat) < staleServerTimeout {
+			return 0
+		}
+		return 1
+	}
+
+	replicas := slices.DeleteFunc(slices.Clone(addresses), func(address string) bool {
+		server, exists := m.chunkServers[address]
+		return exists && (server.State != ChunkServerAlive || now.Sub(server.LatestHeartbeat) >= m.DeadServerTimeout())
+	})
+	slices.SortStableFunc(replicas, func(a, b string) int {
+		if c := cmp.Compare(staleness(a), staleness(b)); c != 0 {
+			return c
+		}
+		return cmp.Compare(m.chunkServerLoad(a), m.chunkServerLoad(b))
+	})
+
+	return replicas
 }
 
-// GetChunk fetches the chunk metadata
-func (m *Metadata) GetChunk(chunkHandle string) (*ChunkMetadata, bool) {
+// GetFile fetches a copy of the file metadata
+func (m *Metadata) GetFile(filename string) (*FileMetadata, bool, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	chunk, exists := m.chunks[chunkHandle]
-	return chunk, exists
+	file, exists, err := m.store.GetFile(filename)
+	if err != nil || !exists {
+		return nil, exists, err
+	}
+	if err := m.resolveInode(file); err != nil {
+		return nil, false, err
+	}
+
+	return file, true, nil
+}
+
+// fileReadFlushInterval is how often the reads counted in memory are written to the metadata store
+const fileReadFlushInterval = 30 * time.Second
+
+// fileReadFlushBatch is the number of files whose reads are written to the store in one transaction
+const fileReadFlushBatch = 32
+
+// fileRead is the reads of a file counted since they were last written to the store
+type fileRead struct {
+	count int64
+	last  time.Time
+}
+
+// RecordFileRead counts a read of a file and updates its last access time. Reads are counted in memory and
+// written to the store by FlushFileReads, so downloads don't each run a store transaction
+func (m *Metadata) RecordFileRead(filename string) {
+	m.readsMu.Lock()
+	defer m.readsMu.Unlock()
+
+	read, exists := m.fileReads[filename]
+	if !exists {
+		read = &fileRead{}
+		m.fileReads[filename] = read
+	}
+	read.count++
+	read.last = time.Now()
+}
+
+// FlushFileReads writes the reads counted since the last flush to the store. Reads that failed to be written
+// are kept for the next flush
+func (m *Metadata) FlushFileReads() error {
+	m.readsMu.Lock()
+	reads := m.fileReads
+	m.fileReads = make(map[string]*fileRead)
+	m.readsMu.Unlock()
+
+	filenames := slices.Sorted(maps.Keys(reads))
+	for batch := range slices.Chunk(filenames, fileReadFlushBatch) {
+		if err := m.writeFileReads(batch, reads); err != nil {
+			m.restoreFileReads(filenames, reads)
+			return err
+		}
+		for _, filename := range batch {
+			delete(reads, filename)
+		}
+	}
+
+	return nil
+}
+
+// writeFileReads adds the reads of the given files to their metadata in one transaction
+func (m *Metadata) writeFileReads(filenames []string, reads map[string]*fileRead) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.update(func(tx *metadataTx) error {
+		for _, filename := range filenames {
+			file, exists, err := tx.GetFile(filename)
+			if err != nil {
+				return err
+			}
+			if !exists {
+				continue
+			}
+
+			read := reads[filename]
+			file.ReadCount += read.count
+			if read.last.After(file.LastAccessed) {
+				file.LastAccessed = read.last
+			}
+			if err := tx.PutFile(file); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// restoreFileReads puts back the reads of a failed flush still in reads, merging them with reads counted since
+func (m *Metadata) restoreFileReads(filenames []string, reads map[string]*fileRead) {
+	m.readsMu.Lock()
+	defer m.readsMu.Unlock()
+
+	for _, filename := range filenames {
+		unwritten, exists := reads[filename]
+		if !exists {
+			continue
+		}
+		if read, exists := m.fileReads[filename]; exists {
+			read.count += unwritten.count
+			continue
+		}
+		m.fileReads[filename] = unwritten
+	}
+}
+
+// lastActivity returns when the file was last read, or written if it was never read
+func (f *FileMetadata) lastActivity() time.Time {
+	if f.LastAccessed.IsZero() {
+		return f.modifiedAt()
+	}
+
+	return f.LastAccessed
+}
+
+// UnaccessedFiles returns the files not read for at least idle, largest first. Files that were
+// never read count from their last modification. A limit of 0 returns all of them
+func (m *Metadata) UnaccessedFiles(idle time.Duration, limit int) ([]*FileMetadata, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	cutoff := time.Now().Add(-idle)
+	files := make([]*FileMetadata, 0)
+	err := m.store.ForEachFile("", func(file *FileMetadata) error {
+		if !file.lastActivity().After(cutoff) {
+			files = append(files, file)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(files, func(a, b *FileMetadata) int {
+		return cmp.Or(cmp.Compare(b.Filesize, a.Filesize), strings.Compare(a.Filename, b.Filename))
+	})
+
+	if limit > 0 && len(files) > limit {
+		files = files[:limit]
+	}
+
+	return files, nil
+}
+
+// GetChunk fetches a copy of the chunk metadata
+func (m *Metadata) GetChunk(chunkHandle string) (*ChunkMetadata, bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.getChunk(chunkHandle)
+}
+
+// DeleteFile removes a file, its previous versions and their chunks from the metadata, returning the deleted file
+// and the removed chunks
+func (m *Metadata) DeleteFile(filename string) (*FileMetadata, []*ChunkMetadata, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var file *FileMetadata
+	var chunks []*ChunkMetadata
+	exists := false
+	err := m.update(func(tx *metadataTx) error {
+		var err error
+		file, exists, err = tx.GetFile(filename)
+		if err != nil || !exists {
+			return err
+		}
+
+		if err := tx.DeleteFile(filename); err != nil {
+			return err
+		}
+		if err := tx.unlink(file); err != nil {
+			return err
+		}
+
+		// an upload still writing to the file can no longer complete
+		chunkHandles := file.chunkHandles()
+		if file.Upload != nil {
+			chunkHandles = append(chunkHandles, file.Upload.Chunks...)
+			file.Upload = nil
+		}
+
+		chunks, err = tx.removeChunks(chunkHandles)
+		return err
+	})
+	if err != nil {
+		return nil, nil, exists, err
+	}
+
+	return file, chunks, exists, nil
 }
 
-// ListFiles returns all the files
-func (m *Metadata) ListFiles() []*FileMetadata {
+// DiskUsage computes the space consumed by all files under prefix, in total and
+// for each file or directory directly under the prefix
+func (m *Metadata) DiskUsage(prefix string) (*DiskUsage, []*DiskUsage, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	files := make([]*FileMetadata, 0, len(m.files))
-	for _, file := range m.files {
+	files := make([]*FileMetadata, 0)
+	err := m.store.ForEachFile(prefix, func(file *FileMetadata) error {
 		files = append(files, file)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	total := &DiskUsage{Path: prefix}
+	children := make(map[string]*DiskUsage)
+
+	for _, file := range files {
+		filename := file.Filename
+
+		// physical size counts every replica that is actually stored, previous versions included
+		var physicalBytes int64
+		for _, chunkHandle := range file.chunkHandles() {
+			chunk, exists, err := m.store.GetChunk(chunkHandle)
+			if err != nil {
+				return nil, nil, err
+			}
+			if exists {
+				physicalBytes += common.ChunkLength(file.chunkFilesize(chunkHandle), int(chunk.ChunkIndex)) * int64(len(m.locations.byChunk[chunkHandle]))
+			}
+		}
+
+		// grouping by the first path component after the prefix
+		childPath := filename
+		if dir, _, isNested := strings.Cut(strings.TrimPrefix(filename, prefix), "/"); isNested {
+			childPath = prefix + dir + "/"
+		}
+
+		child, exists := children[childPath]
+		if !exists {
+			child = &DiskUsage{Path: childPath}
+			children[childPath] = child
+		}
+
+		for _, usage := range []*DiskUsage{total, child} {
+			usage.LogicalBytes += file.Filesize
+			usage.PhysicalBytes += physicalBytes
+			usage.FileCount++
+		}
 	}
 
-	return files
+	entries := make([]*DiskUsage, 0, len(children))
+	for _, child := range children {
+		entries = append(entries, child)
+	}
+	slices.SortFunc(entries, func(a, b *DiskUsage) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	return total, entries, nil
 }
 
-// RegisterChunkServer registers/update a chunk server
-func (m *Metadata) RegisterChunkServer(address string, chunks []string) {
+// ChunkDistribution reports the chunks and bytes held by every chunk server, and a histogram
+// of replica counts (key: number of replicas, value: number of chunks with that many replicas)
+func (m *Metadata) ChunkDistribution() ([]*ChunkServerUsage, map[int]int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	usages := make(map[string]*ChunkServerUsage, len(m.chunkServers))
+	for address, server := range m.chunkServers {
+		usages[address] = &ChunkServerUsage{
+			Address:       address,
+			State:         server.State,
+			LastHeartbeat: server.LatestHeartbeat,
+			CapacityBytes: server.CapacityBytes,
+			FreeBytes:     server.FreeBytes,
+			Version:       server.Version,
+			Labels:        maps.Clone(server.Labels),
+		}
+	}
+
+	// files are only read for chunks that have replicas, to size them
+	histogram := make(map[int]int64)
+	files := make(map[string]*FileMetadata) // key: filename, value: file, nil for missing files
+	err := m.store.ForEachChunk(func(chunk *ChunkMetadata) error {
+		locations := m.locations.byChunk[chunk.ChunkHandle]
+		histogram[len(locations)]++
+		if len(locations) == 0 {
+			return nil
+		}
+
+		file, known := files[chunk.Filename]
+		if !known {
+			var exists bool
+			var err error
+			file, exists, err = m.store.GetFile(chunk.Filename)
+			if err != nil {
+				return err
+			}
+			if !exists {
+				file = nil
+			}
+			files[chunk.Filename] = file
+		}
+
+		var chunkLength int64
+		if file != nil {
+			if filesize := file.chunkFilesize(chunk.ChunkHandle); filesize >= 0 {
+				chunkLength = common.ChunkLength(filesize, int(chunk.ChunkIndex))
+			}
+		}
+
+		for _, address := range locations {
+			usage, exists := usages[address]
+			if !exists {
+				usage = &ChunkServerUsage{Address: address}
+				usages[address] = usage
+			}
+			usage.ChunkCount++
+			usage.Bytes += chunkLength
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	servers := make([]*ChunkServerUsage, 0, len(usages))
+	for _, usage := range usages {
+		servers = append(servers, usage)
+	}
+	slices.SortFunc(servers, func(a, b *ChunkServerUsage) int {
+		return strings.Compare(a.Address, b.Address)
+	})
+
+	return servers, histogram, nil
+}
+
+// ClusterStats computes the aggregate statistics of the cluster, capacity is counted over live chunk servers only
+func (m *Metadata) ClusterStats() (*ClusterStats, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	stats := &ClusterStats{}
+
+	now := time.Now()
+	for _, server := range m.chunkServers {
+		if server.State != ChunkServerAlive || now.Sub(server.LatestHeartbeat) >= m.DeadServerTimeout() {
+			stats.DeadChunkServers++
+			continue
+		}
+
+		stats.LiveChunkServers++
+		stats.CapacityBytes += server.CapacityBytes
+		stats.FreeBytes += server.FreeBytes
+	}
+	stats.UsedBytes = stats.CapacityBytes - stats.FreeBytes
+
+	stats.FileCount = int64(len(m.index.files))
+
+	err := m.store.ForEachChunk(func(chunk *ChunkMetadata) error {
+		stats.ChunkCount++
+		if len(m.locations.byChunk[chunk.ChunkHandle]) < chunk.replicationFactor() {
+			stats.UnderReplicatedChunks++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = m.store.ForEachTombstone(func(tombstone *Tombstone) error {
+		stats.PendingReclamationChunks += int64(len(tombstone.Chunks))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// RecordHeartbeat registers/update a chunk server, returning whether a dead server came back.
+// Heartbeats conflicting with another live chunk server fail with ErrChunkServerConflict, see checkIdentity
+func (m *Metadata) RecordHeartbeat(address, serverID, zone, tier string, chunks []string, capacityBytes, freeBytes int64, load ChunkServerLoad) (bool, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	if err := m.checkIdentity(address, serverID); err != nil {
+		return false, err
+	}
+
+	revived := false
 	if server, exists := m.chunkServers[address]; exists {
 		// update chunk server if server with given address exists
+		if server.State == ChunkServerDead {
+			server.State = ChunkServerAlive
+			revived = true
+		}
 		server.LatestHeartbeat = time.Now()
+		m.locations.report(address, server.Chunks, chunks)
 		server.Chunks = chunks
+		server.CapacityBytes = capacityBytes
+		server.FreeBytes = freeBytes
+		server.Load = load
+		server.Zone = zone
+		server.Tier = tier
+		if serverID != "" {
+			server.ServerID = serverID
+		}
 	} else {
 		// registers a new chunk server
+		m.locations.report(address, nil, chunks)
 		m.chunkServers[address] = &ChunkServerInfo{
 			Address:         address,
+			ServerID:        serverID,
+			Zone:            zone,
+			Tier:            tier,
+			State:           ChunkServerAlive,
 			LatestHeartbeat: time.Now(),
 			Chunks:          chunks,
+			CapacityBytes:   capacityBytes,
+			FreeBytes:       freeBytes,
+			Load:            load,
 		}
 	}
+
+	return revived, nil
 }
 
-// GetAvailableChunkServers returns the list of available chunk servers whose heartbeats had been updated recently within 30 secs
-func (m *Metadata) GetAvailableChunkServers(replicationFactor int) []string {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+// RegisterChunkServer records what a starting chunk server announced about itself and marks it alive,
+// returning whether a dead server came back. Servers that register again replace their previous registration,
+// a server conflicting with another live chunk server fails with ErrChunkServerConflict, see checkIdentity
+func (m *Metadata) RegisterChunkServer(registration ChunkServerRegistration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.checkIdentity(registration.Address, registration.ServerID); err != nil {
+		return false, err
+	}
+
+	server, exists := m.chunkServers[registration.Address]
+	if !exists {
+		server = &ChunkServerInfo{Address: registration.Address}
+		m.chunkServers[registration.Address] = server
+	}
+	revived := exists && server.State == ChunkServerDead
+
+	server.ServerID = registration.ServerID
+	server.State = ChunkServerAlive
+	server.LatestHeartbeat = time.Now()
+	server.Zone = registration.Labels["zone"]
+	server.Tier = registration.Labels["tier"]
+	m.locations.report(registration.Address, server.Chunks, registration.Chunks)
+	server.Chunks = registration.Chunks
+	server.CapacityBytes = registration.CapacityBytes
+	server.FreeBytes = registration.FreeBytes
+	server.Version = registration.Version
+	server.Labels = registration.Labels
+	server.StorageDirectories = registration.StorageDirectories
+
+	return revived, nil
+}
+
+// checkIdentity rejects a chunk server whose address is held by a live server with another identity,
+// e.g. a second process started with the same address, or whose identity is held by a live server at
+// another address, e.g. a copy of its storage. Until the other server stops sending heartbeats and is
+// declared dead the newcomer is kept out. The caller must hold the lock
+func (m *Metadata) checkIdentity(address, serverID string) error {
+	if serverID == "" {
+		return nil
+	}
 
-	servers := make([]string, 0, replicationFactor)
 	now := time.Now()
+	for otherAddress, server := range m.chunkServers {
+		if server.ServerID == "" || server.State != ChunkServerAlive || now.Sub(server.LatestHeartbeat) >= m.DeadServerTimeout() {
+			continue
+		}
 
+		switch {
+		case otherAddress == address && server.ServerID != serverID:
+			return fmt.Errorf("%w: %s is in use by chunk server %s", ErrChunkServerConflict, address, server.ServerID)
+		case otherAddress != address && server.ServerID == serverID:
+			return fmt.Errorf("%w: chunk server %s is already running at %s", ErrChunkServerConflict, serverID, otherAddress)
+		}
+	}
+
+	return nil
+}
+
+// MarkDeadChunkServers marks alive chunk servers without a heartbeat for longer than timeout as dead
+// and removes them from the locations of every chunk. It returns the affected chunk handles per dead server
+func (m *Metadata) MarkDeadChunkServers(timeout time.Duration) (map[string][]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	dead := make(map[string][]string)
+	now := time.Now()
 	for address, server := range m.chunkServers {
-		// only considers servers available if the heartbeat was updated within last 30 seconds
-		if now.Sub(server.LatestHeartbeat) < 30*time.Second {
-			servers = append(servers, address)
-			if len(servers) >= replicationFactor {
-				break
-			}
+		if server.State == ChunkServerAlive && now.Sub(server.LatestHeartbeat) > timeout {
+			server.State = ChunkServerDead
+			dead[address] = make([]string, 0)
 		}
 	}
 
-	return servers
+	for address := range dead {
+		dead[address] = m.locations.removeServer(address)
+		// a server coming back reports all of its chunks again
+		m.chunkServers[address].Chunks = nil
+	}
+
+	return dead, nil
 }
 
 // GetAllChunkServers returns all registered chunk servers
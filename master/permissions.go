@@ -0,0 +1,250 @@
+package master
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+
+	pb "github.com/harshvardha/distributed_file_system/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultFileMode is the permission bits of new files created without a mode, read and write for
+// the owner and read for everybody else
+const DefaultFileMode = 0o644
+
+// modeRegular is the file type bits of a regular file, S_IFREG, stored with the permission bits so a
+// mode of 0 can be told from files created before modes were recorded
+const modeRegular = 0o100000
+
+// ErrPermissionDenied is returned when the mode of a file doesn't let the caller access it
+var ErrPermissionDenied = errors.New("permission denied")
+
+// Access is what a caller does with a file, checked against the file's permission bits
+type Access uint32
+
+const (
+	AccessRead  Access = 0o4 // downloading, listing versions, copying from and presigning the file
+	AccessWrite Access = 0o2 // overwriting, appending to, renaming, deleting and tagging the file
+)
+
+func (a Access) String() string {
+	if a == AccessWrite {
+		return "write"
+	}
+
+	return "read"
+}
+
+// FileOwnership is who owns a new file and what its permission bits let others do
+type FileOwnership struct {
+	Owner string // empty for files nobody owns, which every caller may access
+	Group string
+	Mode  uint32 // permission bits
+}
+
+// newOwnership returns the ownership of a file created by identity, nil when the master authenticates
+// nobody, with the mode, DefaultFileMode when 0
+func newOwnership(identity *Identity, mode uint32) FileOwnership {
+	ownership := FileOwnership{Mode: cmp.Or(mode, DefaultFileMode)}
+	if identity != nil && identity.User != AnonymousUser {
+		ownership.Owner, ownership.Group = identity.User, identity.primaryGroup()
+	}
+
+	return ownership
+}
+
+// setOwnership records the owner and permission bits of a new file
+func (f *FileMetadata) setOwnership(ownership FileOwnership) {
+	f.Owner, f.Group, f.Mode = ownership.Owner, ownership.Group, modeRegular|ownership.Mode
+}
+
+// mode returns the permission bits of the file
+func (f *FileMetadata) mode() uint32 {
+	if f.Mode == 0 {
+		return DefaultFileMode
+	}
+
+	return f.Mode & 0o777
+}
+
+// validateMode checks mode only has permission bits set
+func validateMode(mode uint32) error {
+	if mode&^0o777 != 0 {
+		return fmt.Errorf("mode %o has bits other than the permission bits 0777", mode)
+	}
+
+	return nil
+}
+
+// checkAccess returns PermissionDenied unless the file's permission bits give identity the access, using
+// the owner bits for its owner, the group bits for members of its group and the other bits for everybody
+// else. Superusers, files nobody owns, and masters that authenticate nobody skip the check. Requests
+// authenticated with a delegation token only read files under the token's prefix, whoever the owner is
+func checkAccess(filename string, file *FileMetadata, exists bool, identity *Identity, access Access) error {
+	if identity != nil && identity.Delegation != nil && !identity.Delegation.allows(filename, access) {
+		return status.Errorf(codes.PermissionDenied, "%s: %v, delegation token %s only reads files under %q",
+			filename, ErrPermissionDenied, identity.Delegation.ID, identity.Delegation.Prefix)
+	}
+	if !exists || file.Owner == "" || identity == nil || identity.Superuser {
+		return nil
+	}
+
+	bits := file.mode()
+	switch {
+	case identity.User == file.Owner:
+		bits >>= 6
+	case file.Group != "" && slices.Contains(identity.Groups, file.Group):
+		bits >>= 3
+	}
+	if Access(bits)&access != 0 {
+		return nil
+	}
+
+	return status.Errorf(codes.PermissionDenied, "%s: %v, %s may not %s it", filename, ErrPermissionDenied, identity.User, access)
+}
+
+// checkFileAccess looks up a file and checks the caller may access it. A missing file passes, for the caller
+// to report. The caller must hold the file's lock
+func (s *Server) checkFileAccess(ctx context.Context, filename string, access Access) error {
+	file, exists, err := s.metadata.GetFile(filename)
+	if err != nil {
+		return fmt.Errorf("failed to look up file %s: %v", filename, err)
+	}
+
+	return checkAccess(filename, file, exists, identityFromContext(ctx), access)
+}
+
+// SetFileMode changes the permission bits of a file and its hard links and returns the updated file, false if it doesn't exist
+func (m *Metadata) SetFileMode(filename string, mode uint32) (*FileMetadata, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var file *FileMetadata
+	exists := false
+	err := m.update(func(tx *metadataTx) error {
+		var err error
+		file, exists, err = tx.GetFile(filename)
+		if err != nil || !exists {
+			return err
+		}
+
+		// hard links read the mode from the inode they share
+		file.Mode = modeRegular | mode
+		if file.Inode != "" {
+			return tx.updateInode(file)
+		}
+		return tx.PutFile(file)
+	})
+	if err != nil || !exists {
+		return nil, exists, err
+	}
+
+	return file, true, nil
+}
+
+// SetFileOwner changes the owner and group of a file and its hard links, keeping either when empty, and returns the updated
+// file, false if it doesn't exist
+func (m *Metadata) SetFileOwner(filename, owner, group string) (*FileMetadata, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var file *FileMetadata
+	exists := false
+	err := m.update(func(tx *metadataTx) error {
+		var err error
+		file, exists, err = tx.GetFile(filename)
+		if err != nil || !exists {
+			return err
+		}
+
+		if owner != "" {
+			file.Owner = owner
+		}
+		if group != "" {
+			file.Group = group
+		}
+		if file.Inode != "" {
+			return tx.updateInode(file)
+		}
+		return tx.PutFile(file)
+	})
+	if err != nil || !exists {
+		return nil, exists, err
+	}
+
+	return file, true, nil
+}
+
+// SetFileMode handles chmod requests, allowed for the file's owner and superusers
+func (s *Server) SetFileMode(ctx context.Context, req *pb.SetFileModeRequest) (*pb.SetFileModeResponse, error) {
+	log.Printf("Set mode request for file: %s, mode: %o", req.Filename, req.Mode)
+
+	if err := validateMode(req.Mode); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to set mode of %s: %v", req.Filename, err)
+	}
+
+	unlock := s.locks.Lock(req.Filename)
+	defer unlock()
+
+	file, exists, err := s.metadata.GetFile(req.Filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up file %s: %v", req.Filename, err)
+	}
+	if !exists {
+		return nil, status.Errorf(codes.NotFound, "file not found: %s", req.Filename)
+	}
+	if identity := identityFromContext(ctx); identity != nil && !identity.Superuser && identity.User != file.Owner {
+		return nil, status.Errorf(codes.PermissionDenied, "%s: %v, only its owner may change its mode", req.Filename, ErrPermissionDenied)
+	}
+
+	if file, _, err = s.metadata.SetFileMode(req.Filename, req.Mode); err != nil {
+		return nil, fmt.Errorf("failed to set mode of %s: %v", req.Filename, err)
+	}
+
+	return &pb.SetFileModeResponse{File: s.fileInfoWithHealth(file)}, nil
+}
+
+// SetFileOwner handles chown requests. Superusers may give a file to anyone, its owner may only change
+// its group to one of the owner's own groups
+func (s *Server) SetFileOwner(ctx context.Context, req *pb.SetFileOwnerRequest) (*pb.SetFileOwnerResponse, error) {
+	log.Printf("Set owner request for file: %s, owner: %q, group: %q", req.Filename, req.Owner, req.Group)
+
+	if req.Owner == "" && req.Group == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to set owner of %s: no owner or group given", req.Filename)
+	}
+	if req.Owner == AnonymousUser || req.Owner == internalUser {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to set owner of %s: %s can't own files", req.Filename, req.Owner)
+	}
+
+	unlock := s.locks.Lock(req.Filename)
+	defer unlock()
+
+	file, exists, err := s.metadata.GetFile(req.Filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up file %s: %v", req.Filename, err)
+	}
+	if !exists {
+		return nil, status.Errorf(codes.NotFound, "file not found: %s", req.Filename)
+	}
+	if identity := identityFromContext(ctx); identity != nil && !identity.Superuser {
+		switch {
+		case identity.User != file.Owner:
+			return nil, status.Errorf(codes.PermissionDenied, "%s: %v, only its owner may change its group", req.Filename, ErrPermissionDenied)
+		case req.Owner != "" && req.Owner != file.Owner:
+			return nil, status.Errorf(codes.PermissionDenied, "%s: %v, only superusers may give files away", req.Filename, ErrPermissionDenied)
+		case req.Group != "" && !slices.Contains(identity.Groups, req.Group):
+			return nil, status.Errorf(codes.PermissionDenied, "%s: %v, %s isn't in group %s", req.Filename, ErrPermissionDenied, identity.User, req.Group)
+		}
+	}
+
+	if file, _, err = s.metadata.SetFileOwner(req.Filename, req.Owner, req.Group); err != nil {
+		return nil, fmt.Errorf("failed to set owner of %s: %v", req.Filename, err)
+	}
+
+	return &pb.SetFileOwnerResponse{File: s.fileInfoWithHealth(file)}, nil
+}
@@ -0,0 +1,93 @@
+package master
+
+import (
+	"cmp"
+	"net"
+	"slices"
+	"time"
+
+	"github.com/harshvardha/distributed_file_system/common"
+)
+
+// PlacementHints are client preferences for where the replicas of new chunks go
+type PlacementHints struct {
+	PreferredZone    string // chunk servers in this zone are used first
+	LocalHost        string // a chunk server on this host gets the first replica
+	AntiAffinityFile string // chunk servers holding chunks of this file are used last
+	Tier             string // chunk servers of another storage tier are only used when too few of this one are available
+}
+
+// PlaceChunk picks up to replicationFactor available chunk servers for a new chunk. Servers are
+// ranked by load, then servers matching the hints are moved ahead of the others. Hints never
+// reduce the number of replicas, servers not matching them fill in the remaining slots
+func (m *Metadata) PlaceChunk(replicationFactor int, hints PlacementHints) ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	servers := make([]*ChunkServerInfo, 0, len(m.chunkServers))
+	now := time.Now()
+	for _, server := range m.chunkServers {
+		if server.State == ChunkServerAlive && now.Sub(server.LatestHeartbeat) < m.DeadServerTimeout() {
+			servers = append(servers, server)
+		}
+	}
+
+	// servers holding chunks of the anti-affinity file
+	avoided := make(map[string]bool)
+	if hints.AntiAffinityFile != "" {
+		file, exists, err := m.store.GetFile(hints.AntiAffinityFile)
+		if err != nil {
+			return nil, err
+		}
+
+		if exists {
+			for _, chunkHandle := range file.Chunks {
+				for _, location := range m.locations.byChunk[chunkHandle] {
+					avoided[location] = true
+				}
+			}
+		}
+	}
+
+	// rank returns 0 for a server matching every hint and grows with each hint it misses,
+	// the tier outweighing anti-affinity, which outweighs zone preference
+	rank := func(server *ChunkServerInfo) int {
+		r := 0
+		if hints.Tier != "" && server.Tier != hints.Tier {
+			r += 4
+		}
+		if avoided[server.Address] {
+			r += 2
+		}
+		if hints.PreferredZone != "" && server.Zone != hints.PreferredZone {
+			r++
+		}
+		return r
+	}
+
+	slices.SortStableFunc(servers, func(a, b *ChunkServerInfo) int {
+		if c := cmp.Compare(rank(a), rank(b)); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Load.Score(), b.Load.Score())
+	})
+
+	// moving the least loaded server on the client's host to the front
+	if hints.LocalHost != "" {
+		if i := slices.IndexFunc(servers, func(server *ChunkServerInfo) bool {
+			host, _, err := net.SplitHostPort(server.Address)
+			return err == nil && common.CanonicalHost(host) == common.CanonicalHost(hints.LocalHost)
+		}); i > 0 {
+			local := servers[i]
+			copy(servers[1:i+1], servers[:i])
+			servers[0] = local
+		}
+	}
+
+	placement := make([]string, 0, replicationFactor)
+	for _, server := range servers[:min(len(servers), replicationFactor)] {
+		placement = append(placement, server.Address)
+	}
+
+	return placement, nil
+}
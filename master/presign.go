@@ -0,0 +1,187 @@
+package master
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/harshvardha/distributed_file_system/client"
+	"github.com/harshvardha/distributed_file_system/common"
+	pb "github.com/harshvardha/distributed_file_system/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// PresignKeyEnv is the environment variable holding the presigned url key when no key file is given
+const PresignKeyEnv = "DFS_PRESIGN_KEY"
+
+// presignedPath is the http path presigned downloads are served under
+const presignedPath = "/presigned"
+
+const (
+	defaultPresignTTL = time.Hour
+	maxPresignTTL     = 7 * 24 * time.Hour // bounds how long a leaked url stays usable
+)
+
+// presigner signs and serves presigned download urls, letting anyone holding a url download one version
+// of a file over plain http until the url expires
+type presigner struct {
+	key     []byte
+	baseURL string         // scheme and host the urls point at, e.g. http://localhost:8080
+	local   *client.Client // downloads the files through this master
+}
+
+// newPresigner creates the presigner of a master, nil when no key is set
+func newPresigner(server *Server, key []byte, baseURL string) (*presigner, error) {
+	if len(key) == 0 {
+		return nil, nil
+	}
+	if len(key) < common.MinKeySize {
+		return nil, fmt.Errorf("presign key must be at least %d bytes", common.MinKeySize)
+	}
+	if baseURL == "" {
+		return nil, fmt.Errorf("presigned urls need the base url they are served at")
+	}
+
+	return &presigner{
+		key:     key,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		local:   client.NewClient(localDialAddress(server.address), client.WithToken(server.auth.token()), client.WithConnTuning(server.conn)),
+	}, nil
+}
+
+// sign returns the signature of a url downloading a version of a file until expires
+func (p *presigner) sign(filename string, generation int64, expires int64) string {
+	h := hmac.New(sha256.New, p.key)
+	h.Write([]byte(filename))
+	h.Write([]byte{0})
+	h.Write([]byte(strconv.FormatInt(generation, 10)))
+	h.Write([]byte{0})
+	h.Write([]byte(strconv.FormatInt(expires, 10)))
+
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil))
+}
+
+// presignedURL returns the presigned url of a version of a file, valid until expires
+func (p *presigner) presignedURL(filename string, generation int64, expires int64) string {
+	query := url.Values{}
+	query.Set("file", filename)
+	query.Set("generation", strconv.FormatInt(generation, 10))
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	query.Set("signature", p.sign(filename, generation, expires))
+
+	return p.baseURL + presignedPath + "?" + query.Encode()
+}
+
+// verify checks the query of a presigned url was signed by this master and hasn't expired, returning
+// the file and generation it downloads
+func (p *presigner) verify(query url.Values) (string, int64, error) {
+	filename := query.Get("file")
+	generation, err := strconv.ParseInt(query.Get("generation"), 10, 64)
+	if err != nil {
+		return "", 0, fmt.Errorf("malformed generation")
+	}
+	expires, err := strconv.ParseInt(query.Get("expires"), 10, 64)
+	if err != nil {
+		return "", 0, fmt.Errorf("malformed expiry")
+	}
+
+	if !hmac.Equal([]byte(query.Get("signature")), []byte(p.sign(filename, generation, expires))) {
+		return "", 0, fmt.Errorf("signature doesn't match")
+	}
+	if !time.Now().Before(time.Unix(expires, 0)) {
+		return "", 0, fmt.Errorf("url expired at %s", time.Unix(expires, 0).Format(time.DateTime))
+	}
+
+	return filename, generation, nil
+}
+
+// PresignDownload handles requests for a presigned url of a file. The url pins the file's current version,
+// so overwriting the file doesn't change what it downloads, and it stops working once that version is dropped
+func (s *Server) PresignDownload(ctx context.Context, req *pb.PresignDownloadRequest) (*pb.PresignDownloadResponse, error) {
+	log.Printf("Presign request for file: %s, ttl: %ds", req.Filename, req.TtlSeconds)
+
+	if s.presigner == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "presigned urls are disabled, the master has no presign key")
+	}
+
+	ttl := time.Duration(req.TtlSeconds) * time.Second
+	if ttl == 0 {
+		ttl = defaultPresignTTL
+	}
+	if ttl < 0 || ttl > maxPresignTTL {
+		return nil, status.Errorf(codes.InvalidArgument, "ttl must be between 1s and %s", maxPresignTTL)
+	}
+
+	// a url of a symlink downloads the file the link leads to now, even once the link changes
+	file, exists, err := s.resolveFile(req.Filename)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, status.Errorf(codes.NotFound, "file not found: %s", req.Filename)
+	}
+	if err := checkAccess(file.Filename, file, exists, identityFromContext(ctx), AccessRead); err != nil {
+		return nil, err
+	}
+
+	expires := time.Now().Add(ttl).Unix()
+	return &pb.PresignDownloadResponse{
+		Url:        s.presigner.presignedURL(file.Filename, file.Generation, expires),
+		ExpiresAt:  expires,
+		Generation: file.Generation,
+	}, nil
+}
+
+// servePresigned streams the file version a presigned url points at
+func (s *Server) servePresigned(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	filename, generation, err := s.presigner.verify(r.URL.Query())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid presigned url: %v", err), http.StatusForbidden)
+		return
+	}
+
+	file, exists, err := s.metadata.GetFile(filename)
+	if err != nil {
+		log.Printf("Warning: failed to look up presigned file %s: %v", filename, err)
+		http.Error(w, "failed to look up file", http.StatusInternalServerError)
+		return
+	}
+	if !exists {
+		http.Error(w, "file not found", http.StatusNotFound)
+		return
+	}
+	version, exists := file.version(generation)
+	if !exists {
+		http.Error(w, "version of the file no longer exists", http.StatusGone)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Length", strconv.FormatInt(version.Filesize, 10))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", path.Base(filename)))
+	if r.Method == http.MethodHead {
+		return
+	}
+
+	log.Printf("Presigned download of %s, generation %d, by %s", filename, generation, r.RemoteAddr)
+	if err := s.presigner.local.DownloadVersion(filename, generation, w); err != nil {
+		// the status is sent already, cutting the response short tells the downloader it is incomplete
+		log.Printf("Warning: presigned download of %s failed: %v", filename, err)
+		panic(http.ErrAbortHandler)
+	}
+}
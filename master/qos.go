@@ -0,0 +1,144 @@
+package master
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/harshvardha/distributed_file_system/common"
+	pb "github.com/harshvardha/distributed_file_system/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// batchTurn is how often a freed slot goes to a waiting batch request while interactive requests wait too,
+// so a steady stream of interactive requests slows bulk jobs down without starving them
+const batchTurn = 4
+
+// unscheduledMethods are never queued: chunk servers must keep reporting in and upload sessions must
+// be renewed in time however busy the master is, and operators diagnosing a busy master must get its version
+var unscheduledMethods = map[string]bool{
+	pb.Master_Heartbeat_FullMethodName:           true,
+	pb.Master_RegisterChunkServer_FullMethodName: true,
+	pb.Master_ReportChunk_FullMethodName:         true,
+	pb.Master_ReportLostChunks_FullMethodName:    true,
+	pb.Master_ReportCorruptChunk_FullMethodName:  true,
+	pb.Master_RenewUpload_FullMethodName:         true,
+	pb.Master_GetServerInfo_FullMethodName:       true,
+}
+
+// requestScheduler bounds the client requests the master serves at once. Once saturated, requests queue by
+// priority and a finished request hands its slot to the oldest interactive request before any batch request
+type requestScheduler struct {
+	mu      sync.Mutex
+	limit   int                                 // requests served at once, 0 for no limit
+	running int                                 // requests holding a slot
+	queues  map[common.Priority][]chan struct{} // key: priority, value: requests waiting for a slot, oldest first
+	handoff int                                 // slots handed to waiting requests, for taking batch turns
+}
+
+// newRequestScheduler creates a scheduler serving up to maxConcurrent requests at once, 0 serving every request right away
+func newRequestScheduler(maxConcurrent int) *requestScheduler {
+	return &requestScheduler{
+		limit:  max(maxConcurrent, 0),
+		queues: make(map[common.Priority][]chan struct{}),
+	}
+}
+
+// setLimit changes how many requests are served at once, 0 for no limit. Requests running beyond a lowered
+// limit finish, the queued ones wait until enough of them did
+func (r *requestScheduler) setLimit(maxConcurrent int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.limit = max(maxConcurrent, 0)
+	r.handOffLocked()
+}
+
+// saturatedLocked reports whether every slot is taken. The caller must hold the lock
+func (r *requestScheduler) saturatedLocked() bool {
+	return r.limit > 0 && r.running >= r.limit
+}
+
+// admit waits for a slot to serve a request of priority, failing if the request's context ends first
+func (r *requestScheduler) admit(ctx context.Context, priority common.Priority) error {
+	r.mu.Lock()
+	if !r.saturatedLocked() {
+		r.running++
+		r.mu.Unlock()
+		return nil
+	}
+
+	ready := make(chan struct{})
+	r.queues[priority] = append(r.queues[priority], ready)
+	r.mu.Unlock()
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		if i := slices.Index(r.queues[priority], ready); i >= 0 {
+			r.queues[priority] = slices.Delete(r.queues[priority], i, i+1)
+		} else {
+			// the slot was handed over while giving up, passing it on
+			r.running--
+			r.handOffLocked()
+		}
+		return status.FromContextError(ctx.Err()).Err()
+	}
+}
+
+// release frees the slot of a finished request
+func (r *requestScheduler) release() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.running--
+	r.handOffLocked()
+}
+
+// handOffLocked hands the free slots to the next waiting requests. The caller must hold the lock
+func (r *requestScheduler) handOffLocked() {
+	for !r.saturatedLocked() {
+		interactive, batch := r.queues[common.PriorityInteractive], r.queues[common.PriorityBatch]
+
+		next := common.PriorityInteractive
+		if len(interactive) == 0 || len(batch) > 0 && r.handoff%batchTurn == batchTurn-1 {
+			next = common.PriorityBatch
+		}
+
+		queue := r.queues[next]
+		if len(queue) == 0 {
+			return
+		}
+
+		close(queue[0])
+		r.queues[next] = queue[1:]
+		r.running++
+		r.handoff++
+	}
+}
+
+// serverOptions returns the grpc interceptor scheduling the master's unary rpcs.
+// Streams aren't scheduled since watches stay open for as long as their clients run
+func (r *requestScheduler) serverOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			// other services, e.g. health checks, and chunk server reports skip the queue
+			if !strings.HasPrefix(info.FullMethod, "/"+pb.Master_ServiceDesc.ServiceName+"/") || unscheduledMethods[info.FullMethod] {
+				return handler(ctx, req)
+			}
+
+			if err := r.admit(ctx, common.PriorityFromContext(ctx)); err != nil {
+				return nil, err
+			}
+			defer r.release()
+
+			return handler(ctx, req)
+		}),
+	}
+}
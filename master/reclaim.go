@@ -0,0 +1,352 @@
+package master
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	pb "github.com/harshvardha/distributed_file_system/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// reclaimCheckInterval is how often tombstones are checked for chunks due for reclamation
+const reclaimCheckInterval = time.Minute
+
+// Tombstone records chunks removed from the namespace whose replicas are kept on the chunk servers until
+// ReclaimAt, so an accidental delete doesn't destroy the data right away and reads that already looked up
+// the chunks complete
+type Tombstone struct {
+	ID        string
+	DeletedAt time.Time
+	ReclaimAt time.Time
+	Chunks    []*ChunkMetadata // chunk handles and the servers holding their replicas when they were deleted
+	File      *FileMetadata    // the deleted file, restored by UndeleteFile, nil for chunks of replaced versions
+}
+
+// ErrChunkReclaimed is returned when undeleting a file some of whose chunks were reclaimed already
+var ErrChunkReclaimed = errors.New("chunk was reclaimed")
+
+// AddTombstone records chunks removed from the namespace, and the file they were deleted with if any, to be reclaimed at reclaimAt
+func (m *Metadata) AddTombstone(file *FileMetadata, chunks []*ChunkMetadata, reclaimAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// a chunk is only deleted once and a file generation only used once, so either makes the id unique
+	now := time.Now()
+	var id string
+	if file != nil {
+		id = fmt.Sprintf("%020d-%s-%d", now.UnixNano(), file.Filename, file.Generation)
+	} else {
+		id = fmt.Sprintf("%020d-%s", now.UnixNano(), chunks[0].ChunkHandle)
+	}
+
+	return m.update(func(tx *metadataTx) error {
+		return tx.PutTombstone(&Tombstone{
+			ID:        id,
+			DeletedAt: now,
+			ReclaimAt: reclaimAt,
+			Chunks:    chunks,
+			File:      file,
+		})
+	})
+}
+
+// UndeleteFile restores the most recently deleted file named filename from its tombstone with a new generation,
+// returning false if no tombstone holds it. It fails with ErrFileExists if the name was used again and with
+// ErrChunkReclaimed if one of its chunks is gone. Chunks of the tombstone the file doesn't use stay to be reclaimed
+func (m *Metadata) UndeleteFile(filename string) (*FileMetadata, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	latest, err := m.latestTombstone(filename)
+	if err != nil || latest == nil {
+		return nil, false, err
+	}
+
+	var file *FileMetadata
+	exists := false
+	err = m.update(func(tx *metadataTx) error {
+		tombstone, found, err := tx.GetTombstone(latest.ID)
+		if err != nil || !found {
+			return err
+		}
+		exists = true
+
+		if _, taken, err := tx.GetFile(filename); err != nil {
+			return err
+		} else if taken {
+			return ErrFileExists
+		}
+
+		file = tombstone.File
+		file.Generation = m.nextGeneration()
+		// a file deleted because it expired would be deleted again right away
+		if file.expired(time.Now()) {
+			file.ExpiresAt = time.Time{}
+		}
+		remaining := make([]*ChunkMetadata, 0)
+		handles := file.chunkHandles()
+		for _, chunk := range tombstone.Chunks {
+			if slices.Contains(handles, chunk.ChunkHandle) {
+				// servers may have reported replicas since the chunk was deleted
+				for _, address := range m.locations.byChunk[chunk.ChunkHandle] {
+					if !slices.Contains(chunk.Locations, address) {
+						chunk.Locations = append(chunk.Locations, address)
+					}
+				}
+				if err := tx.PutChunk(chunk); err != nil {
+					return err
+				}
+			} else {
+				remaining = append(remaining, chunk)
+			}
+		}
+
+		// chunks shared with a clone or link were kept for the files still using them and get the reference back
+		for _, chunkHandle := range handles {
+			if slices.ContainsFunc(tombstone.Chunks, func(chunk *ChunkMetadata) bool { return chunk.ChunkHandle == chunkHandle }) {
+				continue
+			}
+			chunk, exists, err := tx.GetChunk(chunkHandle)
+			if err != nil {
+				return err
+			}
+			if !exists {
+				return fmt.Errorf("%w: %s", ErrChunkReclaimed, chunkHandle)
+			}
+
+			chunk.References++
+			if err := tx.PutChunk(chunk); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.relink(file); err != nil {
+			return err
+		}
+		if err := tx.PutFile(file); err != nil {
+			return err
+		}
+
+		if len(remaining) == 0 {
+			return tx.DeleteTombstone(tombstone.ID)
+		}
+		tombstone.Chunks, tombstone.File = remaining, nil
+		return tx.PutTombstone(tombstone)
+	})
+	if err != nil || !exists {
+		return nil, exists, err
+	}
+
+	return file, true, nil
+}
+
+// TakeTombstones removes and returns the tombstones due for reclamation by now, or every tombstone when force is set.
+// Tombstones another master sharing the store took first are left out
+func (m *Metadata) TakeTombstones(now time.Time, force bool) ([]*Tombstone, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	due := make([]string, 0)
+	err := m.store.ForEachTombstone(func(tombstone *Tombstone) error {
+		if force || !now.Before(tombstone.ReclaimAt) {
+			due = append(due, tombstone.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var taken []*Tombstone
+	err = m.update(func(tx *metadataTx) error {
+		taken = make([]*Tombstone, 0, len(due))
+		for _, id := range due {
+			tombstone, exists, err := tx.GetTombstone(id)
+			if err != nil {
+				return err
+			}
+			if !exists {
+				continue
+			}
+
+			if err := tx.DeleteTombstone(id); err != nil {
+				return err
+			}
+			taken = append(taken, tombstone)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return taken, nil
+}
+
+// DeletedFile returns the most recently deleted file named filename that can still be undeleted, false if there is none
+func (m *Metadata) DeletedFile(filename string) (*FileMetadata, bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	latest, err := m.latestTombstone(filename)
+	if err != nil || latest == nil {
+		return nil, false, err
+	}
+
+	return latest.File, true, nil
+}
+
+// latestTombstone returns the tombstone of the most recently deleted file named filename, nil if there is none.
+// The caller must hold the lock
+func (m *Metadata) latestTombstone(filename string) (*Tombstone, error) {
+	var latest *Tombstone
+	err := m.store.ForEachTombstone(func(tombstone *Tombstone) error {
+		if tombstone.File != nil && tombstone.File.Filename == filename && (latest == nil || tombstone.DeletedAt.After(latest.DeletedAt)) {
+			latest = tombstone
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return latest, nil
+}
+
+// deleteChunks deletes the replicas of chunks removed from the namespace once the reclaim delay has passed
+func (s *Server) deleteChunks(chunks []*ChunkMetadata) {
+	s.deleteFileChunks(nil, chunks)
+}
+
+// deleteFileChunks deletes the replicas of the chunks of a deleted file once the reclaim delay has passed,
+// until then the file can be undeleted
+func (s *Server) deleteFileChunks(file *FileMetadata, chunks []*ChunkMetadata) {
+	if len(chunks) == 0 && file == nil {
+		return
+	}
+
+	reclaimDelay := s.tunables.Load().ReclaimDelay
+	if reclaimDelay <= 0 {
+		s.reclaimChunks(chunks)
+		return
+	}
+
+	if err := s.metadata.AddTombstone(file, chunks, time.Now().Add(reclaimDelay)); err != nil {
+		log.Printf("Warning: failed to record tombstone, deleting %d chunks now: %v", len(chunks), err)
+		s.reclaimChunks(chunks)
+	}
+}
+
+// reclaimChunks deletes the replicas of chunks removed from the namespace from the chunk servers
+func (s *Server) reclaimChunks(chunks []*ChunkMetadata) {
+	for _, chunk := range chunks {
+		// servers may have reported replicas since the chunk was deleted
+		for _, serverAddr := range s.metadata.ChunkLocations(chunk.ChunkHandle, chunk.Locations) {
+			if err := s.deleteChunkOnServer(serverAddr, chunk.ChunkHandle); err != nil {
+				log.Printf("Warning: failed to delete chunk %s on %s: %v", chunk.ChunkHandle, serverAddr, err)
+			}
+		}
+	}
+}
+
+// reclaimTombstones deletes the replicas of the chunks of tombstones due for reclamation, or of every
+// tombstone when force is set, and returns the number of chunks reclaimed
+func (s *Server) reclaimTombstones(force bool) (int, error) {
+	tombstones, err := s.metadata.TakeTombstones(time.Now(), force)
+
+	reclaimed := 0
+	for _, tombstone := range tombstones {
+		s.reclaimChunks(tombstone.Chunks)
+		reclaimed += len(tombstone.Chunks)
+	}
+	if reclaimed > 0 {
+		log.Printf("Reclaimed %d deleted chunks", reclaimed)
+	}
+
+	return reclaimed, err
+}
+
+// startReclamation periodically deletes the replicas of chunks deleted longer than the reclaim delay ago
+func (s *Server) startReclamation() {
+	ticker := time.NewTicker(reclaimCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+		}
+
+		if _, err := s.reclaimTombstones(false); err != nil {
+			log.Printf("Warning: failed to reclaim deleted chunks: %v", err)
+		}
+	}
+}
+
+// ReclaimDeleted handles requests to reclaim every deleted chunk now instead of after the reclaim delay.
+// It drops every user's deleted files for good, so only superusers may ask for it
+func (s *Server) ReclaimDeleted(ctx context.Context, req *pb.ReclaimDeletedRequest) (*pb.ReclaimDeletedResponse, error) {
+	if identity := identityFromContext(ctx); identity != nil && !identity.Superuser {
+		return nil, status.Errorf(codes.PermissionDenied, "%v, only superusers may reclaim deleted chunks", ErrPermissionDenied)
+	}
+
+	log.Printf("Reclaim request for all deleted chunks")
+
+	reclaimed, err := s.reclaimTombstones(true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reclaim deleted chunks: %v", err)
+	}
+
+	return &pb.ReclaimDeletedResponse{
+		ReclaimedChunks: int64(reclaimed),
+	}, nil
+}
+
+// UndeleteFile handles requests to restore a deleted file before the reclaim delay passes and its chunks are reclaimed
+func (s *Server) UndeleteFile(ctx context.Context, req *pb.UndeleteFileRequest) (*pb.UndeleteFileResponse, error) {
+	log.Printf("Undelete request for file: %s", req.Filename)
+
+	unlock := s.locks.Lock(req.Filename)
+	defer unlock()
+
+	if s.uploads.InProgress(req.Filename) {
+		return nil, status.Errorf(codes.Aborted, "failed to undelete %s: %v", req.Filename, ErrUploadInProgress)
+	}
+
+	// restoring a file is writing it, which its owner and mode when it was deleted decide
+	deleted, exists, err := s.metadata.DeletedFile(req.Filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up deleted file %s: %v", req.Filename, err)
+	}
+	if !exists {
+		return nil, status.Errorf(codes.NotFound, "no deleted file to restore: %s", req.Filename)
+	}
+	if err := checkAccess(req.Filename, deleted, true, identityFromContext(ctx), AccessWrite); err != nil {
+		return nil, err
+	}
+
+	file, exists, err := s.metadata.UndeleteFile(req.Filename)
+	switch {
+	case errors.Is(err, ErrFileExists):
+		return nil, status.Errorf(codes.AlreadyExists, "failed to undelete %s: %v", req.Filename, err)
+	case errors.Is(err, ErrChunkReclaimed):
+		return nil, status.Errorf(codes.FailedPrecondition, "failed to undelete %s: %v", req.Filename, err)
+	case err != nil:
+		return nil, fmt.Errorf("failed to undelete %s: %v", req.Filename, err)
+	case !exists:
+		return nil, status.Errorf(codes.NotFound, "no deleted file to restore: %s", req.Filename)
+	}
+
+	log.Printf("File %s undeleted with generation %d", req.Filename, file.Generation)
+	s.events.Publish(pb.FileEventType_FILE_EVENT_CREATED, req.Filename, "", file.Filesize)
+
+	return &pb.UndeleteFileResponse{
+		Generation: file.Generation,
+	}, nil
+}
@@ -0,0 +1,187 @@
+package master
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/harshvardha/distributed_file_system/common"
+	pb "github.com/harshvardha/distributed_file_system/proto"
+	"google.golang.org/grpc"
+)
+
+// maxConcurrentRepairs is the number of chunks repaired at once
+const maxConcurrentRepairs = 4
+
+// scheduleRepair queues a chunk for repair if it has fewer replicas than it should
+func (s *Server) scheduleRepair(chunkHandle string) {
+	replicas, target, exists, err := s.metadata.ChunkReplication(chunkHandle)
+	if err != nil {
+		log.Printf("Warning: failed to check replication of chunk %s: %v", chunkHandle, err)
+		return
+	}
+	if !exists || replicas >= target {
+		return
+	}
+
+	queued := s.repairs.Push(chunkHandle, replicas)
+	log.Printf("Chunk %s queued for repair with %d of %d replicas, %d chunks waiting", chunkHandle, replicas, target, s.repairs.Len())
+
+	// posting the degradation once, not again for every replica lost while the chunk waits
+	if queued && s.webhooks != nil {
+		if chunk, exists, err := s.metadata.GetChunk(chunkHandle); err == nil && exists {
+			s.webhooks.replicationDegraded(chunk.Filename, chunkHandle, replicas, target)
+		}
+	}
+}
+
+// removeExcessReplicas deletes replicas of a chunk beyond its target, left over after repairs,
+// when servers holding copies come back or when a hot chunk cools down
+func (s *Server) removeExcessReplicas(chunkHandle string) {
+	removed, err := s.metadata.TrimChunkLocations(chunkHandle)
+	if err != nil {
+		log.Printf("Warning: failed to trim locations of chunk %s: %v", chunkHandle, err)
+		return
+	}
+
+	for _, serverAddr := range removed {
+		log.Printf("Chunk %s is over-replicated, deleting its replica on %s", chunkHandle, serverAddr)
+
+		if err := s.deleteChunkOnServer(serverAddr, chunkHandle); err != nil {
+			log.Printf("Warning: failed to delete excess replica of chunk %s on %s: %v", chunkHandle, serverAddr, err)
+		}
+	}
+}
+
+// deadServerCheckInterval is how often the master looks for dead chunk servers
+const deadServerCheckInterval = 5 * time.Second
+
+// startDeadServerMonitor periodically marks chunk servers that stopped sending heartbeats as dead
+// and queues the chunks that lost a replica with them for repair
+func (s *Server) startDeadServerMonitor() {
+	ticker := time.NewTicker(deadServerCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+		}
+
+		timeout := s.metadata.DeadServerTimeout()
+		dead, err := s.metadata.MarkDeadChunkServers(timeout)
+		if err != nil {
+			log.Printf("Warning: failed to remove dead chunk servers from chunk locations: %v", err)
+		}
+
+		for address, chunkHandles := range dead {
+			log.Printf("Chunk server %s is DEAD, no heartbeat for over %s, %d chunks lost a replica", address, timeout, len(chunkHandles))
+
+			for _, chunkHandle := range chunkHandles {
+				s.scheduleRepair(chunkHandle)
+			}
+		}
+	}
+}
+
+// startRepairWorkers runs maxConcurrentRepairs workers repairing queued chunks, most urgent first, until the
+// master stops
+func (s *Server) startRepairWorkers() {
+	for range maxConcurrentRepairs {
+		s.goBackground(func() {
+			for {
+				chunkHandle, ok := s.repairs.Pop()
+				if !ok {
+					return
+				}
+				s.repairChunk(chunkHandle)
+			}
+		})
+	}
+}
+
+// repairChunk adds one replica to a chunk by copying a good replica to a server not holding it,
+// queueing the chunk again if it still needs more
+func (s *Server) repairChunk(chunkHandle string) {
+	chunk, exists, err := s.metadata.GetChunk(chunkHandle)
+	if err != nil {
+		log.Printf("Warning: failed to look up chunk %s for repair: %v", chunkHandle, err)
+		return
+	}
+	if !exists {
+		return
+	}
+
+	sources := chunk.Locations
+	if len(sources) == 0 {
+		log.Printf("Chunk %s has no good replicas left, it can't be repaired", chunkHandle)
+		return
+	}
+
+	if _, wanted, _, err := s.metadata.ChunkReplication(chunkHandle); err != nil || len(sources) >= wanted {
+		return
+	}
+
+	// Picking a live server that doesn't hold the chunk yet, on the chunk's tier if possible
+	candidates, err := s.metadata.PlaceChunk(len(s.metadata.GetAllChunkServers()), PlacementHints{Tier: chunk.Tier})
+	if err != nil {
+		log.Printf("Warning: failed to pick a server to repair chunk %s on: %v", chunkHandle, err)
+		return
+	}
+	target := ""
+	for _, address := range candidates {
+		if !slices.Contains(sources, address) {
+			target = address
+			break
+		}
+	}
+	if target == "" {
+		log.Printf("No chunk server available to repair chunk %s, %d replicas left", chunkHandle, len(sources))
+		return
+	}
+
+	for _, source := range sources {
+		if err := s.replicateChunkOnServer(chunkHandle, chunk.Version, source, target); err != nil {
+			log.Printf("Warning: failed to repair chunk %s from %s to %s: %v", chunkHandle, source, target, err)
+			continue
+		}
+
+		log.Printf("Repaired chunk %s by copying it from %s to %s", chunkHandle, source, target)
+
+		// recording the replica right away instead of waiting for the target's report,
+		// so the next repair of the chunk doesn't pick the same target
+		if err := s.metadata.AddChunkLocation(chunkHandle, target); err != nil {
+			log.Printf("Warning: failed to record repaired replica of chunk %s on %s: %v", chunkHandle, target, err)
+		}
+		s.scheduleRepair(chunkHandle)
+		return
+	}
+}
+
+// replicateChunkOnServer asks target to pull a chunk from source. The target reports
+// the new replica to the master once it is stored
+func (s *Server) replicateChunkOnServer(chunkHandle string, chunkVersion int32, source, target string) error {
+	conn, err := grpc.NewClient(target, s.conn.DialOptions()...)
+	if err != nil {
+		return fmt.Errorf("failed to connect to chunk server %s: %v", target, err)
+	}
+	defer conn.Close()
+
+	chunkClient := pb.NewChunkServerClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	_, err = chunkClient.ReplicateChunk(ctx, &pb.ReplicateChunkRequest{
+		ChunkHandle:   chunkHandle,
+		SourceAddress: source,
+		ChunkVersion:  chunkVersion,
+		AccessToken:   s.tokens.Sign(chunkHandle, common.ChunkWrite),
+
+		SourceAccessToken: s.tokens.Sign(chunkHandle, common.ChunkRead),
+	})
+
+	return err
+}
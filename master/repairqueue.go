@@ -0,0 +1,122 @@
+package master
+
+import (
+	"container/heap"
+	"sync"
+)
+
+// repairItem is an under-replicated chunk waiting for repair
+type repairItem struct {
+	chunkHandle string
+	replicas    int    // good replicas left when the chunk was queued
+	seq         uint64 // queueing order, breaks ties between chunks with as many replicas
+	index       int    // position in the heap
+}
+
+// repairHeap orders repair items by fewest replicas first, then by queueing order
+type repairHeap []*repairItem
+
+func (h repairHeap) Len() int { return len(h) }
+
+func (h repairHeap) Less(i, j int) bool {
+	if h[i].replicas != h[j].replicas {
+		return h[i].replicas < h[j].replicas
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h repairHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *repairHeap) Push(x any) {
+	item := x.(*repairItem)
+	item.index = len(*h)
+	*h = append(*h, item)
+}
+
+func (h *repairHeap) Pop() any {
+	old := *h
+	item := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return item
+}
+
+// RepairQueue is a priority queue of under-replicated chunks, chunks with the fewest
+// surviving replicas come out first so they are repaired before the others
+type RepairQueue struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	items   repairHeap
+	queued  map[string]*repairItem // key: chunk handle, value: its queue entry
+	nextSeq uint64
+	closed  bool // set by Close, Pop no longer waits
+}
+
+// NewRepairQueue creates a new repair queue
+func NewRepairQueue() *RepairQueue {
+	q := &RepairQueue{
+		queued: make(map[string]*repairItem),
+	}
+	q.cond = sync.NewCond(&q.mu)
+
+	return q
+}
+
+// Push queues a chunk for repair, or updates its priority if it is already queued, reporting whether it was newly queued
+func (q *RepairQueue) Push(chunkHandle string, replicas int) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if item, exists := q.queued[chunkHandle]; exists {
+		item.replicas = replicas
+		heap.Fix(&q.items, item.index)
+		return false
+	}
+
+	q.nextSeq++
+	item := &repairItem{chunkHandle: chunkHandle, replicas: replicas, seq: q.nextSeq}
+	heap.Push(&q.items, item)
+	q.queued[chunkHandle] = item
+	q.cond.Signal()
+
+	return true
+}
+
+// Pop blocks until a chunk is queued and returns the most urgent one, returning false once the queue is closed
+func (q *RepairQueue) Pop() (string, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for q.items.Len() == 0 && !q.closed {
+		q.cond.Wait()
+	}
+	if q.closed {
+		return "", false
+	}
+
+	item := heap.Pop(&q.items).(*repairItem)
+	delete(q.queued, item.chunkHandle)
+
+	return item.chunkHandle, true
+}
+
+// Close wakes every blocked Pop and makes further calls return right away, for the repair workers to end
+func (q *RepairQueue) Close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.closed = true
+	q.cond.Broadcast()
+}
+
+// Len returns the number of chunks waiting for repair
+func (q *RepairQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.items.Len()
+}
@@ -0,0 +1,60 @@
+package master
+
+import (
+	"errors"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrFileImmutable is returned when deleting, renaming or overwriting a file under retention
+var ErrFileImmutable = errors.New("file is immutable")
+
+// UploadProtection is the write protection an upload asks for, applied once the upload completes
+// so a failed upload never leaves behind an incomplete file nobody can remove
+type UploadProtection struct {
+	Immutable bool
+	Retention time.Duration // how long the file stays immutable, 0 forever
+	Exclusive bool          // the upload creates the file, so a failed upload removes it again
+}
+
+// retained reports whether the file is immutable at now
+func (f *FileMetadata) retained(now time.Time) bool {
+	return f.Immutable && (f.RetainUntil.IsZero() || now.Before(f.RetainUntil))
+}
+
+// checkMutable rejects changes to a file that is still under retention, a missing file is always mutable
+func checkMutable(filename string, file *FileMetadata, exists bool) error {
+	if !exists || !file.retained(time.Now()) {
+		return nil
+	}
+
+	if file.RetainUntil.IsZero() {
+		return status.Errorf(codes.PermissionDenied, "%s: %v", filename, ErrFileImmutable)
+	}
+	return status.Errorf(codes.PermissionDenied, "%s: %v until %s", filename, ErrFileImmutable, file.RetainUntil.Format(time.DateTime))
+}
+
+// MakeImmutable protects a file from being deleted, renamed or overwritten until retainUntil, forever
+// when it is zero. It returns false if the file doesn't exist
+func (m *Metadata) MakeImmutable(filename string, retainUntil time.Time) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	exists := false
+	err := m.update(func(tx *metadataTx) error {
+		var file *FileMetadata
+		var err error
+		file, exists, err = tx.GetFile(filename)
+		if err != nil || !exists {
+			return err
+		}
+
+		file.Immutable = true
+		file.RetainUntil = retainUntil
+		return tx.PutFile(file)
+	})
+
+	return exists, err
+}
@@ -0,0 +1,295 @@
+package master
+
+import (
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	pb "github.com/harshvardha/distributed_file_system/proto"
+)
+
+// search result limits, keeping responses to a size a client can take in one message
+const (
+	defaultSearchLimit = 1000
+	maxSearchLimit     = 10000
+)
+
+// trigramLen is the length of the name substrings the search index is keyed by
+const trigramLen = 3
+
+// SearchQuery selects files by name, tags, size and time, zero fields match every file
+type SearchQuery struct {
+	NameContains   string
+	Tags           map[string]string
+	MinSize        *int64
+	MaxSize        *int64
+	CreatedAfter   time.Time
+	CreatedBefore  time.Time
+	ModifiedAfter  time.Time
+	ModifiedBefore time.Time
+	Limit          int // 0 for defaultSearchLimit
+}
+
+// searchQuery converts a search request
+func searchQuery(req *pb.SearchFilesRequest) SearchQuery {
+	return SearchQuery{
+		NameContains:   req.NameContains,
+		Tags:           req.Tags,
+		MinSize:        req.MinSize,
+		MaxSize:        req.MaxSize,
+		CreatedAfter:   unixTime(req.CreatedAfter),
+		CreatedBefore:  unixTime(req.CreatedBefore),
+		ModifiedAfter:  unixTime(req.ModifiedAfter),
+		ModifiedBefore: unixTime(req.ModifiedBefore),
+		Limit:          int(req.Limit),
+	}
+}
+
+// unixTime converts unix seconds to a time, 0 to the zero time
+func unixTime(seconds int64) time.Time {
+	if seconds == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(seconds, 0)
+}
+
+// validate checks the query and fills in the default limit
+func (q *SearchQuery) validate() error {
+	if q.Limit < 0 || q.Limit > maxSearchLimit {
+		return fmt.Errorf("limit must be between 1 and %d, or 0 for the default", maxSearchLimit)
+	}
+	if q.Limit == 0 {
+		q.Limit = defaultSearchLimit
+	}
+
+	return validateTags(q.Tags)
+}
+
+// indexedFile is what the search index keeps about a file
+type indexedFile struct {
+	filesize   int64
+	createdAt  time.Time
+	modifiedAt time.Time
+	tags       map[string]string
+}
+
+// matches reports whether the file passes every condition of the query but the name
+func (f indexedFile) matches(query SearchQuery) bool {
+	switch {
+	case query.MinSize != nil && f.filesize < *query.MinSize,
+		query.MaxSize != nil && f.filesize > *query.MaxSize,
+		!query.CreatedAfter.IsZero() && !f.createdAt.After(query.CreatedAfter),
+		!query.CreatedBefore.IsZero() && !f.createdAt.Before(query.CreatedBefore),
+		!query.ModifiedAfter.IsZero() && !f.modifiedAt.After(query.ModifiedAfter),
+		!query.ModifiedBefore.IsZero() && !f.modifiedAt.Before(query.ModifiedBefore):
+		return false
+	}
+
+	for key, value := range query.Tags {
+		if fileValue, exists := f.tags[key]; !exists || fileValue != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+// searchIndex indexes the namespace in memory by name trigrams and tags, so searches only look
+// at files that can match instead of scanning the store. It is guarded by the metadata lock
+type searchIndex struct {
+	files    map[string]indexedFile         // key: filename
+	trigrams map[string]map[string]struct{} // key: name substring of trigramLen bytes, value: filenames
+	tags     map[string]map[string]struct{} // key: key=value tag, value: filenames
+}
+
+func newSearchIndex() *searchIndex {
+	return &searchIndex{
+		files:    make(map[string]indexedFile),
+		trigrams: make(map[string]map[string]struct{}),
+		tags:     make(map[string]map[string]struct{}),
+	}
+}
+
+// put indexes a file, replacing what was indexed under its name
+func (idx *searchIndex) put(file *FileMetadata) {
+	entry := indexedFile{
+		filesize:   file.Filesize,
+		createdAt:  file.CreatedAt,
+		modifiedAt: file.modifiedAt(),
+		tags:       maps.Clone(file.Tags),
+	}
+
+	// most writes, such as counting reads, don't change anything searchable
+	if existing, exists := idx.files[file.Filename]; exists {
+		if existing.filesize == entry.filesize && existing.createdAt.Equal(entry.createdAt) &&
+			existing.modifiedAt.Equal(entry.modifiedAt) && maps.Equal(existing.tags, entry.tags) {
+			return
+		}
+		idx.remove(file.Filename)
+	}
+
+	idx.files[file.Filename] = entry
+	for _, trigram := range trigrams(file.Filename) {
+		addPosting(idx.trigrams, trigram, file.Filename)
+	}
+	for key, value := range entry.tags {
+		addPosting(idx.tags, key+"="+value, file.Filename)
+	}
+}
+
+// remove drops a file from the index
+func (idx *searchIndex) remove(filename string) {
+	entry, exists := idx.files[filename]
+	if !exists {
+		return
+	}
+
+	delete(idx.files, filename)
+	for _, trigram := range trigrams(filename) {
+		removePosting(idx.trigrams, trigram, filename)
+	}
+	for key, value := range entry.tags {
+		removePosting(idx.tags, key+"="+value, filename)
+	}
+}
+
+// search returns the names of up to query.Limit matching files in filename order and
+// whether more files matched
+func (idx *searchIndex) search(query SearchQuery) ([]string, bool) {
+	// narrowing the candidates to the smallest posting list of the query's trigrams and tags
+	var candidates map[string]struct{}
+	narrowed := false
+	narrow := func(postings map[string]struct{}) {
+		if !narrowed || len(postings) < len(candidates) {
+			candidates = postings
+			narrowed = true
+		}
+	}
+	for _, trigram := range trigrams(query.NameContains) {
+		narrow(idx.trigrams[trigram])
+	}
+	for key, value := range query.Tags {
+		narrow(idx.tags[key+"="+value])
+	}
+
+	names := make([]string, 0)
+	check := func(filename string) {
+		if strings.Contains(filename, query.NameContains) && idx.files[filename].matches(query) {
+			names = append(names, filename)
+		}
+	}
+	if narrowed {
+		for filename := range candidates {
+			check(filename)
+		}
+	} else {
+		for filename := range idx.files {
+			check(filename)
+		}
+	}
+
+	slices.Sort(names)
+	if len(names) > query.Limit {
+		return names[:query.Limit], true
+	}
+	return names, false
+}
+
+// trigrams returns the distinct substrings of trigramLen bytes of s
+func trigrams(s string) []string {
+	if len(s) < trigramLen {
+		return nil
+	}
+
+	grams := make([]string, 0, len(s)-trigramLen+1)
+	for i := 0; i+trigramLen <= len(s); i++ {
+		grams = append(grams, s[i:i+trigramLen])
+	}
+	slices.Sort(grams)
+	return slices.Compact(grams)
+}
+
+func addPosting(postings map[string]map[string]struct{}, key, filename string) {
+	if postings[key] == nil {
+		postings[key] = make(map[string]struct{})
+	}
+	postings[key][filename] = struct{}{}
+}
+
+func removePosting(postings map[string]map[string]struct{}, key, filename string) {
+	delete(postings[key], filename)
+	if len(postings[key]) == 0 {
+		delete(postings, key)
+	}
+}
+
+// SearchFiles returns copies of up to query.Limit files matching the query in filename order
+// and whether more files matched
+func (m *Metadata) SearchFiles(query SearchQuery) ([]*FileMetadata, bool, error) {
+	if err := query.validate(); err != nil {
+		return nil, false, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	names, truncated := m.index.search(query)
+	files := make([]*FileMetadata, 0, len(names))
+	for _, filename := range names {
+		file, exists, err := m.store.GetFile(filename)
+		if err != nil {
+			return nil, false, err
+		}
+		if !exists {
+			continue
+		}
+
+		if err := m.resolveInode(file); err != nil {
+			return nil, false, err
+		}
+		files = append(files, file)
+	}
+
+	return files, truncated, nil
+}
+
+// buildIndex indexes every file in the store. The caller must hold the lock
+func (m *Metadata) buildIndex() error {
+	index := newSearchIndex()
+	err := m.store.ForEachFile("", func(file *FileMetadata) error {
+		index.put(file)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	m.index = index
+	return nil
+}
+
+// fileChanged indexes a file another master sharing the store wrote, or drops it from the index when file is nil
+func (m *Metadata) fileChanged(filename string, file *FileMetadata) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if file == nil {
+		m.index.remove(filename)
+	} else {
+		m.index.put(file)
+	}
+}
+
+// filesMissed indexes the files again after changes other masters made to them were missed
+func (m *Metadata) filesMissed() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.buildIndex(); err != nil {
+		log.Printf("Warning: failed to index files again: %v", err)
+	}
+}
@@ -1,150 +1,1443 @@
 package master
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net"
+	"runtime"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/harshvardha/distributed_file_system/common"
 	pb "github.com/harshvardha/distributed_file_system/proto"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 // Server represents the master server
 type Server struct {
 	pb.UnimplementedMasterServer
 	metadata *Metadata
+	locks    *FileLocks      // serializes operations on the same file
+	uploads  *UploadRegistry // uploads whose chunks are still being written
+	events   *EventBroker
+	repairs  *RepairQueue // under-replicated chunks waiting for repair
+	health   *health.Server
+	ready    atomic.Bool // set once the grpc server is listening
 	address  string
+	serving  atomic.Pointer[grpc.Server]
+	ctx      context.Context // cancelled by Stop to end the background work
+	cancel   context.CancelFunc
+	done     <-chan struct{} // ctx.Done()
+	stopOnce sync.Once
+	conn     common.ConnTuning
+	faults   *common.Faults      // injected failures, nil outside of tests and game days
+	tokens   *common.ChunkTokens // signs the chunk access tokens handed out with chunk locations, nil for none
+	auth     *authenticator      // identifies the user behind client requests, nil when every caller is anonymous
+
+	backgroundMu sync.Mutex     // orders starting background goroutines before Stop waits for them
+	background   sync.WaitGroup // background goroutines, joined by Stop
+
+	scheduler     *requestScheduler      // queues client requests by priority once saturated
+	debugServices bool                   // grpc reflection and channelz are served
+	slowRequests  *common.SlowRequestLog // logs requests taking longer than the threshold
+	interceptors  *common.ServerInterceptors
+
+	tunables        atomic.Pointer[Tunables] // settings changed at runtime by SetTunables
+	inlineThreshold int64                    // files of at most this many bytes are stored in the metadata, 0 never
+	archival        ArchivalPolicy
+	recallMu        sync.Mutex
+	recalling       map[string]bool // key: filename@generation, archived versions being recalled
+
+	geoReplicator *geoReplicator   // nil unless files are mirrored to a remote cluster
+	webhooks      *webhookNotifier // nil unless events are posted to webhook urls
+	presigner     *presigner       // nil unless presigned download urls are enabled
+}
+
+// Config holds the master settings
+type Config struct {
+	MetadataBackend MetadataBackend   // where the namespace is stored
+	MetadataPath    string            // metadata file of BackendBolt
+	Etcd            EtcdConfig        // etcd cluster of BackendEtcd
+	KeepVersions    int               // previous versions kept when a file is overwritten, 0 keeps none
+	VersionMaxAge   time.Duration     // previous versions are dropped this long after being replaced, 0 keeps them
+	ReclaimDelay    time.Duration     // replicas of deleted chunks are kept this long before being deleted, 0 deletes them right away
+	Conn            common.ConnTuning // grpc connection settings, zero for common.DefaultConnTuning
+	GeoReplication  GeoReplicationConfig
+	Faults          *common.Faults // failures to inject, nil for none
+	// MaxConcurrentRequests bounds the client requests served at once, interactive requests being served
+	// first when more are waiting. 0 serves every request right away
+	MaxConcurrentRequests int
+	DebugServices         bool // serve grpc reflection and channelz, see common.RegisterDebugServices
+	// SlowRequestThreshold logs requests taking longer with their parameters and caller, see
+	// common.SlowRequestLog. 0 logs none
+	SlowRequestThreshold time.Duration
+	LogRequests          bool // log every request with its caller, duration and status
+	// HeartbeatTimeout is how long a chunk server may go without a heartbeat before it is considered dead,
+	// 0 for DefaultDeadServerTimeout
+	HeartbeatTimeout time.Duration
+	// ChunkTokens signs the access tokens chunk servers holding the same key require for every chunk request.
+	// nil hands out no tokens
+	ChunkTokens *common.ChunkTokens
+	// PresignKey signs presigned download urls, served by StartHTTP at PresignBaseURL, e.g.
+	// https://dfs.example.com. Empty disables them
+	PresignKey     []byte
+	PresignBaseURL string
+	// Identities authenticates the token client requests carry, recording the user as the owner of the files
+	// it creates and checking file permissions. nil serves every caller anonymously
+	Identities     IdentityProvider
+	AllowAnonymous bool // with Identities, requests without a token are served as AnonymousUser instead of rejected
+	// InlineThreshold stores files of at most this many bytes in the metadata instead of chunks, so they are read
+	// without contacting chunk servers. At most common.MaxInlineSize, 0 stores every file in chunks
+	InlineThreshold int64
+	Archival        ArchivalPolicy // which idle files are compressed onto the archive tier, see ArchivalPolicy
+	Webhooks        WebhookConfig  // urls file events are posted to, see WebhookConfig
 }
 
 // NewServer creates a new master server
-func NewServer(address string) *Server {
-	return &Server{
-		metadata: NewMetadata(),
+func NewServer(address string, config Config) (*Server, error) {
+	if config.InlineThreshold > common.MaxInlineSize {
+		return nil, fmt.Errorf("inline threshold of %d bytes is above the %d bytes clients send inline", config.InlineThreshold, common.MaxInlineSize)
+	}
+	if config.Archival.Tier == "" {
+		config.Archival.Tier = DefaultArchiveTier
+	}
+	if err := validateTier(config.Archival.Tier); err != nil {
+		return nil, fmt.Errorf("invalid archive tier: %v", err)
+	}
+
+	store, err := OpenMetadataStore(config)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Conn == (common.ConnTuning{}) {
+		config.Conn = common.DefaultConnTuning()
+	}
+
+	metadata, err := NewMetadata(store)
+	if err != nil {
+		store.Close()
+		return nil, err
+	}
+
+	s := &Server{
+		metadata: metadata,
+		locks:    NewFileLocks(),
+		uploads:  NewUploadRegistry(),
+		events:   NewEventBroker(),
+		repairs:  NewRepairQueue(),
+		health:   health.NewServer(),
 		address:  address,
+		conn:     config.Conn,
+		faults:   config.Faults,
+		tokens:   config.ChunkTokens,
+		auth:     newAuthenticator(config.Identities, config.AllowAnonymous, newDelegationTokens(config.ChunkTokens, metadata)),
+
+		scheduler:     newRequestScheduler(config.MaxConcurrentRequests),
+		debugServices: config.DebugServices,
+		slowRequests:  common.NewSlowRequestLog(config.SlowRequestThreshold, "Watch", "ListFilesStream"),
+
+		inlineThreshold: config.InlineThreshold,
+		archival:        config.Archival,
+		recalling:       make(map[string]bool),
+	}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	s.done = s.ctx.Done()
+	s.interceptors = common.NewServerInterceptors(s.slowRequests, config.LogRequests)
+	if err := s.SetTunables(Tunables{
+		HeartbeatTimeout:      cmp.Or(config.HeartbeatTimeout, DefaultDeadServerTimeout),
+		MaxConcurrentRequests: config.MaxConcurrentRequests,
+		SlowRequestThreshold:  config.SlowRequestThreshold,
+		LogRequests:           config.LogRequests,
+		KeepVersions:          config.KeepVersions,
+		VersionMaxAge:         config.VersionMaxAge,
+		ReclaimDelay:          config.ReclaimDelay,
+	}); err != nil {
+		metadata.Close()
+		return nil, err
+	}
+	if config.GeoReplication.RemoteMaster != "" {
+		s.geoReplicator = newGeoReplicator(s, config.GeoReplication)
+	}
+	s.webhooks = newWebhookNotifier(s, config.Webhooks)
+
+	s.presigner, err = newPresigner(s, config.PresignKey, config.PresignBaseURL)
+	if err != nil {
+		metadata.Close()
+		return nil, err
 	}
+
+	return s, nil
 }
 
 // UploadFile handles file upload requests
 func (s *Server) UploadFile(ctx context.Context, req *pb.UploadFileRequest) (*pb.UploadFileResponse, error) {
 	log.Printf("Upload request for file: %s, size: %d bytes", req.Filename, req.Filesize)
 
-	// Calculating number of chunks needed for storing the file
-	numChunks := common.CalculateNumChunks(req.Filesize)
+	unlock := s.locks.Lock(req.Filename)
+	defer unlock()
 
-	// Adding file metadata
-	s.metadata.AddFile(req.Filename, req.Filesize, numChunks)
+	// cleaning up after a client that went away before its session was swept
+	if upload := s.uploads.TakeExpired(req.Filename); upload != nil {
+		if err := s.abandonUpload(req.Filename, upload, "session expired"); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+	}
 
-	// Allocating chunks and assigning chunk servers
-	chunkLocations := make([]*pb.ChunkLocation, 0, numChunks)
+	// checked before claiming the upload so the loser of a create race learns the file exists
+	if req.Exclusive {
+		_, exists, err := s.metadata.GetFile(req.Filename)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up file %s: %v", req.Filename, err)
+		}
+		if exists || s.uploads.InProgress(req.Filename) {
+			return nil, status.Errorf(codes.AlreadyExists, "%s: %v", req.Filename, ErrFileExists)
+		}
+	}
+
+	// a second writer would interleave its chunks with the first one's
+	protection := UploadProtection{
+		Immutable: req.Immutable,
+		Retention: time.Duration(req.RetentionSeconds) * time.Second,
+		Exclusive: req.Exclusive,
+	}
+	uploadID, expires, err := s.uploads.Begin(req.Filename, protection)
+	if err != nil {
+		return nil, status.Errorf(codes.Aborted, "failed to upload %s: %v", req.Filename, err)
+	}
+
+	response, err := s.allocateFile(req, identityFromContext(ctx))
+	if err != nil {
+		s.uploads.Complete(req.Filename, uploadID)
+		// a file whose chunks couldn't all be placed can't be read, and an overwrite left staged never completes
+		if response != nil {
+			if err := s.abandonUpload(req.Filename, &pendingUpload{generation: response.Generation}, "placement failed"); err != nil {
+				log.Printf("Warning: %v", err)
+			}
+		}
+		return nil, err
+	}
+	s.uploads.SetGeneration(req.Filename, uploadID, response.Generation)
+	if req.Streaming {
+		s.uploads.SetStreaming(req.Filename, uploadID, uploadHints(req))
+	}
+	response.UploadId = uploadID
+	response.ExpiresAt = expires.UnixNano()
+
+	return response, nil
+}
 
-	for i := 0; i < numChunks; i++ {
-		// Generating chunk handle for each chunk
-		chunkHandle := common.GenerateChunkHandle(req.Filename, i)
+// allocateFile adds the metadata of a file uploaded by identity and assigns chunk servers to each of its chunks.
+// When placing a chunk fails after the file was added, it returns the error with a response holding only the
+// generation of the upload, for the caller to abandon
+func (s *Server) allocateFile(req *pb.UploadFileRequest, identity *Identity) (*pb.UploadFileResponse, error) {
+	if err := validateTags(req.Tags); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to upload %s: %v", req.Filename, err)
+	}
+	if err := validateMode(req.Mode); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to upload %s: %v", req.Filename, err)
+	}
+	if err := validateTier(req.StorageTier); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to upload %s: %v", req.Filename, err)
+	}
+	if err := validateStorageClass(req.StorageClass); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to upload %s: %v", req.Filename, err)
+	}
+	if req.Streaming && (req.Filesize != 0 || len(req.Data) > 0) {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to upload %s: streaming uploads set their size when they complete", req.Filename)
+	}
+	hints := uploadHints(req)
 
-		// Adding chunk metadata
-		s.metadata.AddChunk(chunkHandle, req.Filename, int32(i))
-		s.metadata.AddChunkToFile(req.Filename, chunkHandle)
+	existing, exists, err := s.metadata.GetFile(req.Filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up file %s: %v", req.Filename, err)
+	}
+	if err := checkGeneration(req.Filename, existing, exists, req.IfGenerationMatch); err != nil {
+		return nil, err
+	}
+	if err := checkMutable(req.Filename, existing, exists); err != nil {
+		return nil, err
+	}
+	if err := checkAccess(req.Filename, existing, exists, identity, AccessWrite); err != nil {
+		return nil, err
+	}
 
-		// fetching available chunk servers for replication
-		servers := s.metadata.GetAvailableChunkServers(common.ReplicationFactor)
+	// Calculating number of chunks needed for storing the file, none when it is stored inline
+	numChunks := common.CalculateNumChunks(req.Filesize)
+	inline := s.storesInline(req)
+	if inline {
+		numChunks = 0
+	}
 
-		if len(servers) < common.ReplicationFactor {
-			log.Printf("Warning: Only %d chunk servers available, need %d for replication", len(servers), common.ReplicationFactor)
+	// files of the archive class are written like standard ones and archived in background
+	replicas := common.ReplicationFactor
+	attributes := AttributeUpdate{SetTags: req.Tags}
+	if req.StorageTier != "" {
+		attributes.Tier = &req.StorageTier
+	}
+	if req.StorageClass != "" {
+		replicationFactor := classReplicationFactor(req.StorageClass)
+		attributes.StorageClass, attributes.ReplicationFactor = &req.StorageClass, &replicationFactor
+		if replicationFactor > 0 {
+			replicas = replicationFactor
 		}
+	}
+	allocation := FileAllocation{
+		Filename:     req.Filename,
+		Filesize:     req.Filesize,
+		ChunkCount:   numChunks,
+		KeepVersions: s.tunables.Load().KeepVersions,
+		Ownership:    newOwnership(identity, req.Mode),
+		Attributes:   attributes,
+	}
+	if inline {
+		allocation.Data = req.Data
+	}
 
-		// Adding chunk location info
-		chunkLocations = append(chunkLocations, &pb.ChunkLocation{
-			ChunkHandle:          chunkHandle,
-			ChunkServerAddresses: servers,
-			ChunkIndex:           int32(i),
-		})
+	// Adding file and chunk metadata, replicas of replaced versions that aren't kept are removed in background
+	generation, chunks, dropped, err := s.metadata.AllocateFile(allocation)
+	s.goBackground(func() { s.deleteChunks(dropped) })
+	if err != nil {
+		return nil, fmt.Errorf("failed to add file %s: %v", req.Filename, err)
+	}
+	if inline {
+		log.Printf("File %s of %d bytes stored inline", req.Filename, req.Filesize)
+	}
+
+	// Assigning chunk servers
+	chunkLocations := make([]*pb.ChunkLocation, 0, numChunks)
+	for _, chunk := range chunks {
+		chunkLocation, err := s.placeNewChunk(req.Filename, chunk.ChunkHandle, chunk.ChunkIndex, chunk.Version, replicas, hints)
+		if err != nil {
+			return &pb.UploadFileResponse{Generation: generation}, err
+		}
+		chunkLocations = append(chunkLocations, chunkLocation)
+	}
 
-		log.Printf("Chunk %d (%s) assigned to servers: %v", i, chunkHandle, servers)
+	// overwrites announce the file once they complete, streaming uploads once its size is known
+	if !req.Streaming && !exists {
+		s.events.Publish(pb.FileEventType_FILE_EVENT_CREATED, req.Filename, "", req.Filesize)
 	}
 
 	return &pb.UploadFileResponse{
 		ChunkLocations: chunkLocations,
+		Generation:     generation,
+		Inline:         inline,
+	}, nil
+}
+
+// placeNewChunk assigns chunk servers to a chunk being uploaded, honoring the client's placement hints, and
+// grants one of them the lease, returning the location the client writes the chunk to
+func (s *Server) placeNewChunk(filename, chunkHandle string, chunkIndex, chunkVersion int32, replicas int, hints PlacementHints) (*pb.ChunkLocation, error) {
+	// fetching available chunk servers for replication
+	servers, err := s.metadata.PlaceChunk(replicas, hints)
+	if err != nil {
+		return nil, fmt.Errorf("failed to place chunk %d of %s: %v", chunkIndex, filename, err)
+	}
+
+	if len(servers) < replicas {
+		log.Printf("Warning: Only %d chunk servers available, need %d for replication", len(servers), replicas)
+	}
+
+	// Adding chunk location info
+	chunkLocation := &pb.ChunkLocation{
+		ChunkHandle:          chunkHandle,
+		ChunkServerAddresses: servers,
+		ChunkIndex:           chunkIndex,
+		ChunkVersion:         chunkVersion,
+		AccessToken:          s.tokens.Sign(chunkHandle, common.ChunkWrite),
+	}
+
+	// the client writes the chunk to the lease holder, which forwards it to the other replicas
+	if len(servers) > 0 {
+		lease, err := s.metadata.GrantLease(chunkHandle, servers)
+		if err != nil {
+			return nil, fmt.Errorf("failed to grant lease on chunk %d of %s: %v", chunkIndex, filename, err)
+		}
+		chunkLocation.ChunkVersion = lease.version
+		chunkLocation.PrimaryAddress = lease.primary
+		chunkLocation.LeaseExpiresAt = lease.expires.UnixNano()
+		chunkLocation.LeaseToken = s.tokens.SignLease(chunkHandle, lease.primary, lease.version, lease.expires)
+	}
+
+	log.Printf("Chunk %d (%s) assigned to servers: %v, primary: %s", chunkIndex, chunkHandle, servers, chunkLocation.PrimaryAddress)
+	return chunkLocation, nil
+}
+
+// CompleteUpload handles upload completion requests, releasing the file for other uploads
+func (s *Server) CompleteUpload(ctx context.Context, req *pb.CompleteUploadRequest) (*pb.CompleteUploadResponse, error) {
+	log.Printf("Upload of %s completed, failed: %t", req.Filename, req.Failed)
+
+	unlock := s.locks.Lock(req.Filename)
+	defer unlock()
+
+	// a streaming upload only learns its size now, an upload with a size that doesn't fit its chunks stays open
+	if !req.Failed {
+		if err := s.finishStreamingUpload(req); err != nil {
+			return nil, err
+		}
+	}
+
+	upload, err := s.uploads.Complete(req.Filename, req.UploadId)
+	if err != nil {
+		return nil, status.Errorf(codes.Aborted, "failed to complete upload of %s: %v", req.Filename, err)
+	}
+	protection := upload.protection
+
+	// a half written file left behind would be read as garbage and make every retry of a create fail
+	if req.Failed {
+		if err := s.abandonUpload(req.Filename, upload, "client gave up"); err != nil {
+			return nil, err
+		}
+	}
+
+	// an overwrite replaces the file only now, readers were served the previous contents until the upload completed
+	if !req.Failed && upload.generation != 0 {
+		file, replaced, dropped, err := s.metadata.CommitUpload(req.Filename, upload.generation, s.tunables.Load().KeepVersions)
+		s.goBackground(func() { s.deleteChunks(dropped) })
+		if errors.Is(err, ErrUploadSuperseded) {
+			return nil, status.Errorf(codes.Aborted, "failed to complete upload of %s: %v", req.Filename, err)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to complete upload of %s: %v", req.Filename, err)
+		}
+
+		// uploads creating the file announced it when they started, unless they were streaming and didn't know its size
+		if replaced || upload.streaming {
+			s.events.Publish(pb.FileEventType_FILE_EVENT_CREATED, req.Filename, "", file.Filesize)
+		}
+	}
+
+	if protection.Immutable && !req.Failed {
+		var retainUntil time.Time
+		if protection.Retention > 0 {
+			retainUntil = time.Now().Add(protection.Retention)
+		}
+
+		if _, err := s.metadata.MakeImmutable(req.Filename, retainUntil); err != nil {
+			return nil, fmt.Errorf("failed to make %s immutable: %v", req.Filename, err)
+		}
+	}
+
+	return &pb.CompleteUploadResponse{
+		Success: true,
+	}, nil
+}
+
+// RenewUpload extends the session of an upload whose client is still writing chunks
+func (s *Server) RenewUpload(ctx context.Context, req *pb.RenewUploadRequest) (*pb.RenewUploadResponse, error) {
+	expires, err := s.uploads.Renew(req.Filename, req.UploadId)
+	if err != nil {
+		return nil, status.Errorf(codes.Aborted, "failed to renew upload of %s: %v", req.Filename, err)
+	}
+
+	return &pb.RenewUploadResponse{
+		ExpiresAt: expires.UnixNano(),
 	}, nil
 }
 
 // DownloadFile handles file download requests
 func (s *Server) DownloadFile(ctx context.Context, req *pb.DownloadFileRequest) (*pb.DownloadFileResponse, error) {
-	log.Printf("Download request for file: %s", req.Filename)
+	log.Printf("Download request for file: %s, generation: %d", req.Filename, req.Generation)
 
-	// Get file metadata
-	file, exists := s.metadata.GetFile(req.Filename)
+	unlock := s.locks.RLock(req.Filename)
+	defer unlock()
+
+	// Get file metadata, the generation is one of the file a symlink leads to
+	file, exists, err := s.resolveFile(req.Filename)
+	if err != nil {
+		return nil, err
+	}
 	if !exists {
-		return nil, fmt.Errorf("file not found: %s", req.Filename)
+		return nil, status.Errorf(codes.NotFound, "file not found: %s", req.Filename)
+	}
+	if err := checkAccess(file.Filename, file, exists, identityFromContext(ctx), AccessRead); err != nil {
+		return nil, err
+	}
+
+	version, exists := file.version(req.Generation)
+	if !exists {
+		return nil, status.Errorf(codes.NotFound, "version %d of %s not found", req.Generation, file.Filename)
+	}
+	// archived contents are decompressed back into chunks before they are handed out
+	if err := s.checkRecalled(file, version.Generation); err != nil {
+		return nil, err
 	}
 
 	// Fetching chunk locations
-	chunkLocations := make([]*pb.ChunkLocation, 0, len(file.Chunks))
+	chunkLocations := make([]*pb.ChunkLocation, 0, len(version.Chunks))
 
-	for _, chunkHandle := range file.Chunks {
-		chunk, exists := s.metadata.GetChunk(chunkHandle)
+	for _, chunkHandle := range version.Chunks {
+		chunk, exists, err := s.metadata.GetChunk(chunkHandle)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up chunk %s: %v", chunkHandle, err)
+		}
 		if !exists {
 			return nil, fmt.Errorf("chunk not found: %s", chunkHandle)
 		}
 
 		chunkLocations = append(chunkLocations, &pb.ChunkLocation{
 			ChunkHandle:          chunkHandle,
-			ChunkServerAddresses: chunk.Locations,
+			ChunkServerAddresses: s.metadata.ReadReplicas(chunk.Locations),
 			ChunkIndex:           chunk.ChunkIndex,
+			ChunkVersion:         chunk.Version,
+			AccessToken:          s.tokens.Sign(chunkHandle, common.ChunkRead),
 		})
 	}
 
+	s.metadata.RecordFileRead(file.Filename)
+
 	return &pb.DownloadFileResponse{
-		Filesize:      file.Filesize,
+		Data:          version.Data,
+		Filesize:      version.Filesize,
 		ChunkLocation: chunkLocations,
 	}, nil
 }
 
 // ListFiles handles list files request
 func (s *Server) ListFiles(ctx context.Context, req *pb.ListFilesRequest) (*pb.ListFilesResponse, error) {
-	log.Printf("List files request")
+	log.Printf("List files request, tags: %v, sort by: %s, page size: %d", req.Tags, req.SortBy, req.PageSize)
 
-	files := s.metadata.ListFiles()
-	fileInfos := make([]*pb.FileInfo, 0, len(files))
+	if req.PageSize < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to list files: page size must not be negative")
+	}
+	after, err := decodeListCursor(req.PageToken, req.SortBy, req.Descending)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to list files: %v", err)
+	}
 
-	for _, file := range files {
-		fileInfos = append(fileInfos, &pb.FileInfo{
-			Filename:  file.Filename,
-			Filesize:  file.Filesize,
-			NumChunks: int32(file.ChunkCount),
-		})
+	names, next := s.metadata.ListFileNames(listFilter(req), req.SortBy, req.Descending, after, int(req.PageSize))
+	fileInfos := make([]*pb.FileInfo, 0, len(names))
+	for batch := range slices.Chunk(names, listBatchSize) {
+		files, err := s.metadata.GetFiles(batch)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list files: %v", err)
+		}
+
+		for _, file := range files {
+			fileInfos = append(fileInfos, s.fileInfoWithHealth(file))
+		}
 	}
 
-	return &pb.ListFilesResponse{
+	response := &pb.ListFilesResponse{
 		Files: fileInfos,
+	}
+	if next != nil {
+		response.NextPageToken = next.encode()
+	}
+	return response, nil
+}
+
+// SearchFiles handles file search requests
+func (s *Server) SearchFiles(ctx context.Context, req *pb.SearchFilesRequest) (*pb.SearchFilesResponse, error) {
+	log.Printf("Search files request, name contains: %q, tags: %v", req.NameContains, req.Tags)
+
+	query := searchQuery(req)
+	if err := query.validate(); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to search files: %v", err)
+	}
+
+	files, truncated, err := s.metadata.SearchFiles(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search files: %v", err)
+	}
+
+	fileInfos := make([]*pb.FileInfo, 0, len(files))
+	for _, file := range files {
+		fileInfos = append(fileInfos, s.fileInfoWithHealth(file))
+	}
+
+	return &pb.SearchFilesResponse{
+		Files:     fileInfos,
+		Truncated: truncated,
 	}, nil
 }
 
 // Heartbeat handles chunk server heartbeat
 func (s *Server) Heartbeat(ctx context.Context, req *pb.HeartbeatRequest) (*pb.HeartbeatResponse, error) {
-	log.Printf("Heartbeat from chunk server: %s with %d chunks", req.ChunkServerAddress, len(req.ChunkHandles))
+	log.Printf("Heartbeat from chunk server: %s with %d chunks, %.1f iops, queue depth %d, cpu %.0f%%", req.ChunkServerAddress, len(req.ChunkHandles),
+		req.Load.GetIops(), req.Load.GetQueueDepth(), req.Load.GetCpuUtilization()*100)
+
+	// the server is recorded under the canonical form of its address, which registration recorded too
+	address, err := common.ParseAddress(req.ChunkServerAddress)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "heartbeat rejected: %v", err)
+	}
 
 	// registering/updating chunk server
-	s.metadata.RegisterChunkServer(req.ChunkServerAddress, req.ChunkHandles)
+	load := ChunkServerLoad{
+		IOPS:                  req.Load.GetIops(),
+		QueueDepth:            int(req.Load.GetQueueDepth()),
+		CPUUtilization:        req.Load.GetCpuUtilization(),
+		NetworkInBytesPerSec:  req.Load.GetNetworkInBytesPerSec(),
+		NetworkOutBytesPerSec: req.Load.GetNetworkOutBytesPerSec(),
+	}
+	revived, err := s.metadata.RecordHeartbeat(address, req.ServerId, req.Zone, req.Tier, req.ChunkHandles, req.CapacityBytes, req.FreeBytes, load)
+	if err != nil {
+		// fencing the server rather than mixing its inventory with the other server's
+		log.Printf("Rejecting heartbeat from %s: %v", address, err)
+		return nil, status.Errorf(codes.FailedPrecondition, "heartbeat rejected: %v", err)
+	}
+	if revived {
+		log.Printf("Chunk server %s is ALIVE again", address)
+	}
+	s.metadata.RecordChunkReads(req.ChunkReads)
 
 	return &pb.HeartbeatResponse{
 		Success: true,
 	}, nil
 }
 
+// RegisterChunkServer handles chunk servers announcing themselves as they start
+func (s *Server) RegisterChunkServer(ctx context.Context, req *pb.RegisterChunkServerRequest) (*pb.RegisterChunkServerResponse, error) {
+	log.Printf("Chunk server %s (%s) registering, version %s, labels %v, %d storage directories, %d chunks", req.ChunkServerAddress, req.ServerId, req.Version,
+		req.Labels, len(req.StorageDirectories), len(req.ChunkHandles))
+
+	// clients must be able to dial whatever address the server is recorded under
+	// and the same server is recorded under one address however it was spelled, e.g. [::1] and [0:0:0:0:0:0:0:1]
+	address, err := common.ParseAddress(req.ChunkServerAddress)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to register chunk server: %v", err)
+	}
+
+	directories := make([]StorageDirectory, 0, len(req.StorageDirectories))
+	for _, directory := range req.StorageDirectories {
+		directories = append(directories, StorageDirectory{
+			Path:          directory.Path,
+			CapacityBytes: directory.CapacityBytes,
+			FreeBytes:     directory.FreeBytes,
+			ChunkCount:    directory.ChunkCount,
+			Healthy:       directory.Healthy,
+		})
+	}
+
+	revived, err := s.metadata.RegisterChunkServer(ChunkServerRegistration{
+		Address:            address,
+		ServerID:           req.ServerId,
+		Version:            req.Version,
+		Labels:             req.Labels,
+		Chunks:             req.ChunkHandles,
+		CapacityBytes:      req.CapacityBytes,
+		FreeBytes:          req.FreeBytes,
+		StorageDirectories: directories,
+	})
+	if err != nil {
+		log.Printf("Rejecting registration of %s: %v", address, err)
+		return nil, status.Errorf(codes.AlreadyExists, "registration rejected: %v", err)
+	}
+	if revived {
+		log.Printf("Chunk server %s is ALIVE again", address)
+	}
+
+	return &pb.RegisterChunkServerResponse{
+		ChunkSize:           common.ChunkSize,
+		HeartbeatIntervalMs: heartbeatInterval.Milliseconds(),
+		ReplicationFactor:   common.ReplicationFactor,
+	}, nil
+}
+
 // ReportChunk handles chunk storage completion reports
 func (s *Server) ReportChunk(ctx context.Context, req *pb.ReportChunkRequest) (*pb.ReportChunkResponse, error) {
 	log.Printf("Chunk report: %s stored on %s", req.ChunkHandle, req.ChunkServerAddress)
 
+	address, err := common.ParseAddress(req.ChunkServerAddress)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "report of chunk %s rejected: %v", req.ChunkHandle, err)
+	}
+
+	// the chunk server believes the report went through, leaving the replica for the next full report to find
+	if s.faults.DropReport(req.ChunkHandle) {
+		return &pb.ReportChunkResponse{Success: true}, nil
+	}
+
 	// Adding chunk location
-	s.metadata.AddChunkLocation(req.ChunkHandle, req.ChunkServerAddress)
+	if err := s.metadata.AddChunkLocation(req.ChunkHandle, address); err != nil {
+		return nil, fmt.Errorf("failed to add location of chunk %s: %v", req.ChunkHandle, err)
+	}
+
+	// a repaired chunk may now have one replica too many
+	if replicas, target, exists, err := s.metadata.ChunkReplication(req.ChunkHandle); err == nil && exists && replicas > target {
+		s.goBackground(func() { s.removeExcessReplicas(req.ChunkHandle) })
+	}
 
 	return &pb.ReportChunkResponse{
 		Success: true,
 	}, nil
 }
 
+// ReportLostChunks handles reports of chunks a chunk server no longer holds
+func (s *Server) ReportLostChunks(ctx context.Context, req *pb.ReportLostChunksRequest) (*pb.ReportLostChunksResponse, error) {
+	log.Printf("Chunk server %s lost %d chunks: %s", req.ChunkServerAddress, len(req.ChunkHandles), req.Reason)
+
+	address, err := common.ParseAddress(req.ChunkServerAddress)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "lost chunks report rejected: %v", err)
+	}
+
+	for _, chunkHandle := range req.ChunkHandles {
+		if err := s.metadata.RemoveChunkLocation(chunkHandle, address); err != nil {
+			return nil, fmt.Errorf("failed to remove location of chunk %s: %v", chunkHandle, err)
+		}
+		s.scheduleRepair(chunkHandle)
+	}
+
+	return &pb.ReportLostChunksResponse{
+		Success: true,
+	}, nil
+}
+
+// ReportCorruptChunk handles reports of replicas that failed checksum verification, from the chunk server holding
+// the replica or from a client that read it. Data may also be corrupted on the way to a client, and reports
+// may be wrong, so the replica is only dropped once its server confirms it failed verification or no longer has it
+func (s *Server) ReportCorruptChunk(ctx context.Context, req *pb.ReportCorruptChunkRequest) (*pb.ReportCorruptChunkResponse, error) {
+	log.Printf("Chunk server %s has a corrupt replica of chunk %s: %s", req.ChunkServerAddress, req.ChunkHandle, req.Reason)
+
+	address, err := common.ParseAddress(req.ChunkServerAddress)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "corrupt chunk report rejected: %v", err)
+	}
+
+	chunk, exists, err := s.metadata.GetChunk(req.ChunkHandle)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up chunk %s: %v", req.ChunkHandle, err)
+	}
+	if !exists {
+		return nil, status.Errorf(codes.NotFound, "chunk not found: %s", req.ChunkHandle)
+	}
+
+	// clients only report chunks of files they may read
+	if !fromChunkServer(ctx) {
+		file, exists, err := s.metadata.GetFile(chunk.Filename)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up file %s: %v", chunk.Filename, err)
+		}
+		if err := checkAccess(chunk.Filename, file, exists, identityFromContext(ctx), AccessRead); err != nil {
+			return nil, err
+		}
+	}
+	if !slices.Contains(chunk.Locations, address) {
+		return &pb.ReportCorruptChunkResponse{Success: false}, nil
+	}
+
+	corrupt, err := s.verifyChunkOnServer(address, req.ChunkHandle)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "failed to verify chunk %s on %s: %v", req.ChunkHandle, address, err)
+	}
+	if !corrupt {
+		log.Printf("Replica of chunk %s on %s passed verification, keeping it", req.ChunkHandle, address)
+		return &pb.ReportCorruptChunkResponse{Success: false}, nil
+	}
+
+	// clients are no longer sent to the corrupt replica
+	if _, err := s.metadata.RemoveCorruptReplica(req.ChunkHandle, address); errors.Is(err, ErrLastReplica) {
+		log.Printf("Warning: keeping the location of corrupt chunk %s on %s, it is the chunk's last replica", req.ChunkHandle, address)
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to remove location of chunk %s: %v", req.ChunkHandle, err)
+	}
+
+	s.scheduleRepair(req.ChunkHandle)
+
+	return &pb.ReportCorruptChunkResponse{
+		Success: true,
+	}, nil
+}
+
+// verifyChunkOnServer asks a chunk server to check its replica of a chunk, which it drops if it is corrupt.
+// It reports whether the replica is corrupt or already gone
+func (s *Server) verifyChunkOnServer(serverAddr, chunkHandle string) (bool, error) {
+	conn, err := grpc.NewClient(serverAddr, s.conn.DialOptions()...)
+	if err != nil {
+		return false, fmt.Errorf("failed to connect to chunk server %s: %v", serverAddr, err)
+	}
+	defer conn.Close()
+
+	chunkClient := pb.NewChunkServerClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	response, err := chunkClient.VerifyChunk(ctx, &pb.VerifyChunkRequest{
+		ChunkHandle: chunkHandle,
+		AccessToken: s.tokens.Sign(chunkHandle, common.ChunkRead),
+	})
+	if status.Code(err) == codes.NotFound {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return response.Corrupt, nil
+}
+
+// CopyFile handles server side file copy requests
+func (s *Server) CopyFile(ctx context.Context, req *pb.CopyFileRequest) (*pb.CopyFileResponse, error) {
+	log.Printf("Copy request: %s -> %s", req.SourceFilename, req.DestinationFilename)
+
+	if req.SourceFilename == req.DestinationFilename {
+		return nil, status.Errorf(codes.InvalidArgument, "source and destination are the same file: %s", req.SourceFilename)
+	}
+
+	unlock := s.locks.LockCopy(req.SourceFilename, req.DestinationFilename)
+	defer unlock()
+
+	if s.uploads.InProgress(req.DestinationFilename) {
+		return nil, status.Errorf(codes.Aborted, "failed to copy to %s: %v", req.DestinationFilename, ErrUploadInProgress)
+	}
+
+	// Get source file metadata, copying a symlink copies the file it leads to
+	file, exists, err := s.resolveFile(req.SourceFilename)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, status.Errorf(codes.NotFound, "file not found: %s", req.SourceFilename)
+	}
+	identity := identityFromContext(ctx)
+	if err := checkAccess(file.Filename, file, exists, identity, AccessRead); err != nil {
+		return nil, err
+	}
+	// the copy gets the contents, not the archive
+	if err := s.checkRecalled(file, file.Generation); err != nil {
+		return nil, err
+	}
+
+	destination, exists, err := s.metadata.GetFile(req.DestinationFilename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up file %s: %v", req.DestinationFilename, err)
+	}
+	if err := checkGeneration(req.DestinationFilename, destination, exists, req.IfGenerationMatch); err != nil {
+		return nil, err
+	}
+	if err := checkMutable(req.DestinationFilename, destination, exists); err != nil {
+		return nil, err
+	}
+	if err := checkAccess(req.DestinationFilename, destination, exists, identity, AccessWrite); err != nil {
+		return nil, err
+	}
+
+	// Copying the chunks before the destination is added, so a copy that fails part way leaves the namespace as it was
+	generation := s.metadata.NewGeneration()
+	chunks := make([]*ChunkMetadata, 0, len(file.Chunks))
+	for i, sourceHandle := range file.Chunks {
+		chunk, err := s.copyChunk(sourceHandle, common.GenerateChunkHandle(req.DestinationFilename, generation, i), req.DestinationFilename)
+		if chunk != nil {
+			chunks = append(chunks, chunk)
+		}
+		if err != nil {
+			// removing the replicas copied so far
+			s.goBackground(func() { s.deleteChunks(chunks) })
+			return nil, fmt.Errorf("failed to copy chunk %d of %s: %v", i, req.SourceFilename, err)
+		}
+	}
+
+	// Adding destination file metadata, replicas of replaced versions that aren't kept are removed in background
+	allocation := FileAllocation{
+		Filename:     req.DestinationFilename,
+		Filesize:     file.Filesize,
+		ChunkCount:   file.ChunkCount,
+		KeepVersions: s.tunables.Load().KeepVersions,
+		Ownership:    newOwnership(identity, file.mode()),
+		Attributes:   AttributeUpdate{SetTags: file.Tags},
+		Data:         file.Data,
+	}
+	dropped, err := s.metadata.AddCopiedFile(allocation, generation, chunks)
+	if err != nil {
+		s.goBackground(func() { s.deleteChunks(chunks) })
+		return nil, fmt.Errorf("failed to add file %s: %v", req.DestinationFilename, err)
+	}
+	s.goBackground(func() { s.deleteChunks(dropped) })
+
+	s.events.Publish(pb.FileEventType_FILE_EVENT_CREATED, req.DestinationFilename, "", file.Filesize)
+
+	return &pb.CopyFileResponse{
+		Success:    true,
+		Generation: generation,
+	}, nil
+}
+
+// RenameFile handles file rename requests, the file keeps its chunks under the new name
+func (s *Server) RenameFile(ctx context.Context, req *pb.RenameFileRequest) (*pb.RenameFileResponse, error) {
+	log.Printf("Rename request: %s -> %s", req.SourceFilename, req.DestinationFilename)
+
+	if req.SourceFilename == req.DestinationFilename {
+		return nil, status.Errorf(codes.InvalidArgument, "source and destination are the same file: %s", req.SourceFilename)
+	}
+
+	unlock := s.locks.Lock(req.SourceFilename, req.DestinationFilename)
+	defer unlock()
+
+	for _, filename := range []string{req.SourceFilename, req.DestinationFilename} {
+		if s.uploads.InProgress(filename) {
+			return nil, status.Errorf(codes.Aborted, "failed to rename %s: %v", filename, ErrUploadInProgress)
+		}
+	}
+
+	file, exists, err := s.metadata.GetFile(req.SourceFilename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up file %s: %v", req.SourceFilename, err)
+	}
+	if !exists {
+		return nil, status.Errorf(codes.NotFound, "file not found: %s", req.SourceFilename)
+	}
+	if err := checkGeneration(req.SourceFilename, file, exists, req.IfGenerationMatch); err != nil {
+		return nil, err
+	}
+	if err := checkMutable(req.SourceFilename, file, exists); err != nil {
+		return nil, err
+	}
+	if err := checkAccess(req.SourceFilename, file, exists, identityFromContext(ctx), AccessWrite); err != nil {
+		return nil, err
+	}
+
+	_, exists, err = s.metadata.GetFile(req.DestinationFilename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up file %s: %v", req.DestinationFilename, err)
+	}
+	if exists {
+		return nil, status.Errorf(codes.AlreadyExists, "failed to rename to %s: file already exists", req.DestinationFilename)
+	}
+
+	generation, _, err := s.metadata.RenameFile(req.SourceFilename, req.DestinationFilename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rename file %s: %v", req.SourceFilename, err)
+	}
+
+	s.events.Publish(pb.FileEventType_FILE_EVENT_RENAMED, req.DestinationFilename, req.SourceFilename, file.Filesize)
+
+	return &pb.RenameFileResponse{
+		Success:    true,
+		Generation: generation,
+	}, nil
+}
+
+// Watch streams namespace events for files matching the requested prefix until the client disconnects
+func (s *Server) Watch(req *pb.WatchRequest, stream grpc.ServerStreamingServer[pb.FileEvent]) error {
+	log.Printf("Watch request for prefix: %q", req.Prefix)
+
+	id, events := s.events.Subscribe(req.Prefix)
+	defer s.events.Unsubscribe(id)
+
+	// the headers tell the client it is subscribed, before any event is due
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return fmt.Errorf("failed to send headers: %v", err)
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			log.Printf("Watcher for prefix %q disconnected", req.Prefix)
+			return nil
+		case event := <-events:
+			if err := stream.Send(event); err != nil {
+				return fmt.Errorf("failed to send event: %v", err)
+			}
+		}
+	}
+}
+
+// DeleteFile handles file deletion requests
+func (s *Server) DeleteFile(ctx context.Context, req *pb.DeleteFileRequest) (*pb.DeleteFileResponse, error) {
+	log.Printf("Delete request for file: %s", req.Filename)
+
+	unlock := s.locks.Lock(req.Filename)
+	defer unlock()
+
+	file, exists, err := s.metadata.GetFile(req.Filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up file %s: %v", req.Filename, err)
+	}
+	if err := checkGeneration(req.Filename, file, exists, req.IfGenerationMatch); err != nil {
+		return nil, err
+	}
+	if err := checkMutable(req.Filename, file, exists); err != nil {
+		return nil, err
+	}
+	if err := checkAccess(req.Filename, file, exists, identityFromContext(ctx), AccessWrite); err != nil {
+		return nil, err
+	}
+
+	deleted, chunks, exists, err := s.metadata.DeleteFile(req.Filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete file %s: %v", req.Filename, err)
+	}
+	if !exists {
+		return nil, status.Errorf(codes.NotFound, "file not found: %s", req.Filename)
+	}
+
+	s.events.Publish(pb.FileEventType_FILE_EVENT_DELETED, req.Filename, "", 0)
+
+	// Removing chunk replicas from chunk servers once the reclaim delay passes, the file can be undeleted until then
+	s.goBackground(func() { s.deleteFileChunks(deleted, chunks) })
+
+	return &pb.DeleteFileResponse{
+		Success: true,
+	}, nil
+}
+
+// GetFileInfo handles file stat requests
+func (s *Server) GetFileInfo(ctx context.Context, req *pb.GetFileInfoRequest) (*pb.GetFileInfoResponse, error) {
+	log.Printf("File info request for file: %s", req.Filename)
+
+	unlock := s.locks.RLock(req.Filename)
+	defer unlock()
+
+	// like stat, the info of a symlink is the info of the file it leads to
+	file, exists, err := s.resolveFile(req.Filename)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, status.Errorf(codes.NotFound, "file not found: %s", req.Filename)
+	}
+
+	// anyone may stat a file, but the chunk locations and their read tokens are only for callers allowed to read it
+	chunkHandles := file.Chunks
+	if checkAccess(file.Filename, file, exists, identityFromContext(ctx), AccessRead) != nil {
+		chunkHandles = nil
+	}
+
+	chunkLocations := make([]*pb.ChunkLocation, 0, len(chunkHandles))
+	for _, chunkHandle := range chunkHandles {
+		chunk, exists, err := s.metadata.GetChunk(chunkHandle)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up chunk %s: %v", chunkHandle, err)
+		}
+		if !exists {
+			return nil, fmt.Errorf("chunk not found: %s", chunkHandle)
+		}
+
+		chunkLocations = append(chunkLocations, &pb.ChunkLocation{
+			ChunkHandle:          chunkHandle,
+			ChunkServerAddresses: s.metadata.ReadReplicas(chunk.Locations), // like downloads, live replicas only and least loaded first
+			ChunkIndex:           chunk.ChunkIndex,
+			ChunkVersion:         chunk.Version,
+			AccessToken:          s.tokens.Sign(chunkHandle, common.ChunkRead),
+		})
+	}
+
+	return &pb.GetFileInfoResponse{
+		File:           s.fileInfoWithHealth(file),
+		ChunkLocations: chunkLocations,
+	}, nil
+}
+
+// UpdateFileTags handles requests changing the tags of a file
+func (s *Server) UpdateFileTags(ctx context.Context, req *pb.UpdateFileTagsRequest) (*pb.UpdateFileTagsResponse, error) {
+	log.Printf("Update tags request for file: %s, set: %v, remove: %v", req.Filename, req.Set, req.Remove)
+
+	if err := validateTags(req.Set); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to update tags of %s: %v", req.Filename, err)
+	}
+
+	unlock := s.locks.Lock(req.Filename)
+	defer unlock()
+
+	if err := s.checkFileAccess(ctx, req.Filename, AccessWrite); err != nil {
+		return nil, err
+	}
+
+	tags, exists, err := s.metadata.UpdateFileTags(req.Filename, req.Set, req.Remove)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update tags of %s: %v", req.Filename, err)
+	}
+	if !exists {
+		return nil, status.Errorf(codes.NotFound, "file not found: %s", req.Filename)
+	}
+
+	return &pb.UpdateFileTagsResponse{
+		Tags: tags,
+	}, nil
+}
+
+// GetFileAttributes handles requests for the mutable attributes of a file
+func (s *Server) GetFileAttributes(ctx context.Context, req *pb.GetFileAttributesRequest) (*pb.GetFileAttributesResponse, error) {
+	log.Printf("Get attributes request for file: %s", req.Filename)
+
+	file, exists, err := s.metadata.GetFile(req.Filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up file %s: %v", req.Filename, err)
+	}
+	if !exists {
+		return nil, status.Errorf(codes.NotFound, "file not found: %s", req.Filename)
+	}
+
+	return &pb.GetFileAttributesResponse{
+		Attributes: toFileAttributes(file),
+	}, nil
+}
+
+// SetFileAttributes handles requests changing the mutable attributes of a file. Replicas are added
+// or removed in background to match a new replication factor, and the file archived or recalled to
+// match a new storage class
+func (s *Server) SetFileAttributes(ctx context.Context, req *pb.SetFileAttributesRequest) (*pb.SetFileAttributesResponse, error) {
+	log.Printf("Set attributes request for file: %s", req.Filename)
+
+	update := AttributeUpdate{
+		SetTags:    req.SetTags,
+		RemoveTags: req.RemoveTags,
+	}
+	if req.TtlSeconds != nil {
+		var expiresAt time.Time
+		if *req.TtlSeconds > 0 {
+			expiresAt = time.Now().Add(time.Duration(*req.TtlSeconds) * time.Second)
+		}
+		update.ExpiresAt = &expiresAt
+	}
+	if req.ReplicationFactor != nil {
+		replicationFactor := int(*req.ReplicationFactor)
+		update.ReplicationFactor = &replicationFactor
+	}
+	update.Tier = req.StorageTier
+	update.StorageClass = req.StorageClass
+	// a storage class comes with its replication factor, unless the request sets one too
+	if update.StorageClass != nil && update.ReplicationFactor == nil {
+		replicationFactor := classReplicationFactor(*update.StorageClass)
+		update.ReplicationFactor = &replicationFactor
+	}
+	if err := update.validate(); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to set attributes of %s: %v", req.Filename, err)
+	}
+
+	unlock := s.locks.Lock(req.Filename)
+	defer unlock()
+
+	if err := s.checkFileAccess(ctx, req.Filename, AccessWrite); err != nil {
+		return nil, err
+	}
+
+	previous, exists, err := s.metadata.GetFile(req.Filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up file %s: %v", req.Filename, err)
+	}
+	if !exists {
+		return nil, status.Errorf(codes.NotFound, "file not found: %s", req.Filename)
+	}
+
+	file, exists, err := s.metadata.UpdateAttributes(req.Filename, update)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set attributes of %s: %v", req.Filename, err)
+	}
+	if !exists {
+		return nil, status.Errorf(codes.NotFound, "file not found: %s", req.Filename)
+	}
+
+	if update.ReplicationFactor != nil {
+		for _, chunkHandle := range file.chunkHandles() {
+			s.scheduleRepair(chunkHandle)
+			s.goBackground(func() { s.removeExcessReplicas(chunkHandle) })
+		}
+	}
+	if file.storageClass() != previous.storageClass() {
+		log.Printf("Storage class of %s changed from %s to %s", req.Filename, previous.storageClass(), file.storageClass())
+		s.goBackground(func() { s.convertStorageClass(req.Filename) })
+	}
+
+	return &pb.SetFileAttributesResponse{
+		Attributes: toFileAttributes(file),
+	}, nil
+}
+
+// ListFileVersions handles requests for the versions of a file
+func (s *Server) ListFileVersions(ctx context.Context, req *pb.ListFileVersionsRequest) (*pb.ListFileVersionsResponse, error) {
+	log.Printf("List versions request for file: %s", req.Filename)
+
+	file, exists, err := s.resolveFile(req.Filename)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, status.Errorf(codes.NotFound, "file not found: %s", req.Filename)
+	}
+	if err := checkAccess(file.Filename, file, exists, identityFromContext(ctx), AccessRead); err != nil {
+		return nil, err
+	}
+
+	versions := make([]*pb.FileVersion, 0, len(file.Versions)+1)
+	versions = append(versions, &pb.FileVersion{
+		Generation: file.Generation,
+		Filesize:   file.Filesize,
+		NumChunks:  int32(file.ChunkCount),
+		CreatedAt:  file.modifiedAt().Unix(),
+		Current:    true,
+	})
+	for _, version := range file.Versions {
+		versions = append(versions, &pb.FileVersion{
+			Generation: version.Generation,
+			Filesize:   version.Filesize,
+			NumChunks:  int32(version.ChunkCount),
+			CreatedAt:  version.CreatedAt.Unix(),
+			ReplacedAt: version.ReplacedAt.Unix(),
+		})
+	}
+
+	return &pb.ListFileVersionsResponse{
+		Versions: versions,
+	}, nil
+}
+
+// DiskUsage handles disk usage requests
+func (s *Server) DiskUsage(ctx context.Context, req *pb.DiskUsageRequest) (*pb.DiskUsageResponse, error) {
+	log.Printf("Disk usage request for prefix: %q", req.Prefix)
+
+	total, children, err := s.metadata.DiskUsage(req.Prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute disk usage: %v", err)
+	}
+
+	entries := make([]*pb.DiskUsageEntry, 0, len(children))
+	for _, child := range children {
+		entries = append(entries, diskUsageToProto(child))
+	}
+
+	return &pb.DiskUsageResponse{
+		Total:   diskUsageToProto(total),
+		Entries: entries,
+	}, nil
+}
+
+// ListUnaccessedFiles handles requests for the largest files not read for a while
+func (s *Server) ListUnaccessedFiles(ctx context.Context, req *pb.ListUnaccessedFilesRequest) (*pb.ListUnaccessedFilesResponse, error) {
+	idle := time.Duration(req.IdleSeconds) * time.Second
+	log.Printf("Unaccessed files request, idle for %s, limit %d", idle, req.Limit)
+
+	files, err := s.metadata.UnaccessedFiles(idle, int(req.Limit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list unaccessed files: %v", err)
+	}
+
+	fileInfos := make([]*pb.FileInfo, 0, len(files))
+	for _, file := range files {
+		fileInfos = append(fileInfos, toFileInfo(file))
+	}
+
+	return &pb.ListUnaccessedFilesResponse{
+		Files: fileInfos,
+	}, nil
+}
+
+// GetChunkDistribution handles chunk distribution report requests
+func (s *Server) GetChunkDistribution(ctx context.Context, req *pb.GetChunkDistributionRequest) (*pb.GetChunkDistributionResponse, error) {
+	log.Printf("Chunk distribution request")
+
+	usages, histogram, err := s.metadata.ChunkDistribution()
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute chunk distribution: %v", err)
+	}
+
+	servers := make([]*pb.ChunkServerUsage, 0, len(usages))
+	for _, usage := range usages {
+		servers = append(servers, &pb.ChunkServerUsage{
+			Address:       usage.Address,
+			ChunkCount:    usage.ChunkCount,
+			Bytes:         usage.Bytes,
+			CapacityBytes: usage.CapacityBytes,
+			FreeBytes:     usage.FreeBytes,
+			State:         string(usage.State),
+			LastHeartbeat: usage.LastHeartbeat.Unix(),
+			Version:       usage.Version,
+			Labels:        usage.Labels,
+		})
+	}
+
+	// always reporting every bucket from 0 up to the replication factor so gaps are visible
+	maxReplicas := common.ReplicationFactor
+	for replicas := range histogram {
+		maxReplicas = max(maxReplicas, replicas)
+	}
+
+	buckets := make([]*pb.ReplicationBucket, 0, maxReplicas+1)
+	for replicas := 0; replicas <= maxReplicas; replicas++ {
+		buckets = append(buckets, &pb.ReplicationBucket{
+			Replicas:   int32(replicas),
+			ChunkCount: histogram[replicas],
+		})
+	}
+
+	return &pb.GetChunkDistributionResponse{
+		Servers:              servers,
+		ReplicationHistogram: buckets,
+		ReplicationFactor:    common.ReplicationFactor,
+	}, nil
+}
+
+// GetClusterStats handles cluster statistics requests
+func (s *Server) GetClusterStats(ctx context.Context, req *pb.GetClusterStatsRequest) (*pb.GetClusterStatsResponse, error) {
+	log.Printf("Cluster stats request")
+
+	stats, err := s.metadata.ClusterStats()
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute cluster stats: %v", err)
+	}
+
+	return &pb.GetClusterStatsResponse{
+		CapacityBytes:         stats.CapacityBytes,
+		UsedBytes:             stats.UsedBytes,
+		FreeBytes:             stats.FreeBytes,
+		FileCount:             stats.FileCount,
+		ChunkCount:            stats.ChunkCount,
+		LiveChunkServers:      int32(stats.LiveChunkServers),
+		DeadChunkServers:      int32(stats.DeadChunkServers),
+		UnderReplicatedChunks: stats.UnderReplicatedChunks,
+
+		PendingReclamationChunks: stats.PendingReclamationChunks,
+	}, nil
+}
+
+// GetServerInfo handles requests for the master's version, so mixed-version clusters can be diagnosed
+func (s *Server) GetServerInfo(ctx context.Context, req *pb.GetServerInfoRequest) (*pb.GetServerInfoResponse, error) {
+	return &pb.GetServerInfoResponse{
+		Version:   common.Version,
+		Commit:    common.BuildCommit(),
+		GoVersion: runtime.Version(),
+		Features:  common.ProtocolFeatures,
+	}, nil
+}
+
+// diskUsageToProto converts disk usage metadata to its protobuf representation
+func diskUsageToProto(usage *DiskUsage) *pb.DiskUsageEntry {
+	return &pb.DiskUsageEntry{
+		Path:          usage.Path,
+		LogicalBytes:  usage.LogicalBytes,
+		PhysicalBytes: usage.PhysicalBytes,
+		FileCount:     usage.FileCount,
+	}
+}
+
+// fileInfoWithHealth converts file metadata to its protobuf representation including the replication health of its chunks
+func (s *Server) fileInfoWithHealth(file *FileMetadata) *pb.FileInfo {
+	minReplicas, degraded := s.metadata.FileReplication(file)
+
+	info := toFileInfo(file)
+	info.MinReplicas = int32(minReplicas)
+	info.Degraded = degraded
+	return info
+}
+
+// toFileInfo converts file metadata to its protobuf representation
+func toFileInfo(file *FileMetadata) *pb.FileInfo {
+	info := &pb.FileInfo{
+		Filename:   file.Filename,
+		Filesize:   file.Filesize,
+		NumChunks:  int32(file.ChunkCount),
+		ReadCount:  file.ReadCount,
+		Generation: file.Generation,
+		Immutable:  file.Immutable,
+		Tags:       file.Tags,
+
+		ReplicationFactor: int32(file.replicationFactor()),
+		CreatedAt:         file.CreatedAt.Unix(),
+		ModifiedAt:        file.modifiedAt().Unix(),
+		Owner:             file.Owner,
+		Group:             file.Group,
+		Mode:              file.mode(),
+		SymlinkTarget:     file.SymlinkTarget,
+		HardLinks:         file.Links,
+		Inline:            file.Data != nil,
+		StorageTier:       file.Tier,
+		ArchivedSize:      file.ArchivedSize,
+		StorageClass:      file.storageClass(),
+	}
+	if !file.LastAccessed.IsZero() {
+		info.LastAccessed = file.LastAccessed.Unix()
+	}
+	if !file.RetainUntil.IsZero() {
+		info.RetainUntil = file.RetainUntil.Unix()
+	}
+	if !file.ExpiresAt.IsZero() {
+		info.ExpiresAt = file.ExpiresAt.Unix()
+	}
+
+	return info
+}
+
+// deleteChunkOnServer asks a chunk server to delete a chunk
+func (s *Server) deleteChunkOnServer(serverAddr, chunkHandle string) error {
+	conn, err := grpc.NewClient(serverAddr, s.conn.DialOptions()...)
+	if err != nil {
+		return fmt.Errorf("failed to connect to chunk server %s: %v", serverAddr, err)
+	}
+	defer conn.Close()
+
+	chunkClient := pb.NewChunkServerClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err = chunkClient.DeleteChunk(ctx, &pb.DeleteChunkRequest{
+		ChunkHandle: chunkHandle,
+		AccessToken: s.tokens.Sign(chunkHandle, common.ChunkDelete),
+	})
+
+	return err
+}
+
+// copyChunk copies a chunk to destinationHandle on every server holding it, so no chunk data crosses the network.
+// It returns the copy with the locations it was written to, nil if the source chunk couldn't be looked up
+func (s *Server) copyChunk(sourceHandle, destinationHandle, destinationFilename string) (*ChunkMetadata, error) {
+	chunk, exists, err := s.metadata.GetChunk(sourceHandle)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up chunk %s: %v", sourceHandle, err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("chunk not found: %s", sourceHandle)
+	}
+
+	// the handle is new, so the copy gets the version of a newly allocated chunk
+	chunkCopy := &ChunkMetadata{
+		ChunkHandle: destinationHandle,
+		Locations:   make([]string, 0, len(chunk.Locations)),
+		Version:     initialChunkVersion,
+		Filename:    destinationFilename,
+		ChunkIndex:  chunk.ChunkIndex,
+	}
+	for _, serverAddr := range chunk.Locations {
+		if err := s.copyChunkOnServer(serverAddr, sourceHandle, destinationHandle, chunkCopy.Version); err != nil {
+			log.Printf("Warning: failed to copy chunk %s on %s: %v", sourceHandle, serverAddr, err)
+			continue
+		}
+		chunkCopy.Locations = append(chunkCopy.Locations, serverAddr)
+	}
+
+	if len(chunkCopy.Locations) == 0 {
+		return chunkCopy, fmt.Errorf("no server holding chunk %s copied it", sourceHandle)
+	}
+
+	log.Printf("Chunk %d copied to %s on %d servers", chunk.ChunkIndex, destinationHandle, len(chunkCopy.Locations))
+	return chunkCopy, nil
+}
+
+// copyChunkOnServer asks a chunk server to duplicate a chunk it stores under a new chunk handle
+func (s *Server) copyChunkOnServer(serverAddr, sourceHandle, destinationHandle string, chunkVersion int32) error {
+	conn, err := grpc.NewClient(serverAddr, s.conn.DialOptions()...)
+	if err != nil {
+		return fmt.Errorf("failed to connect to chunk server %s: %v", serverAddr, err)
+	}
+	defer conn.Close()
+
+	chunkClient := pb.NewChunkServerClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err = chunkClient.CopyChunk(ctx, &pb.CopyChunkRequest{
+		SourceChunkHandle:      sourceHandle,
+		DestinationChunkHandle: destinationHandle,
+		ChunkVersion:           chunkVersion,
+		AccessToken:            s.tokens.Sign(destinationHandle, common.ChunkWrite),
+		SourceAccessToken:      s.tokens.Sign(sourceHandle, common.ChunkRead),
+	})
+
+	return err
+}
+
 // Start starts the master server
 func (s *Server) Start() error {
 	listen, err := net.Listen("tcp", s.address)
@@ -152,10 +1445,48 @@ func (s *Server) Start() error {
 		return fmt.Errorf("failed to listen: %v", err)
 	}
 
-	grpcServer := grpc.NewServer()
+	return s.Serve(listen)
+}
+
+// Serve runs the master on an existing listener, e.g. one bound to an ephemeral port, until Stop is called
+func (s *Server) Serve(listen net.Listener) error {
+	// slow requests are timed first, so their duration includes waiting for the scheduler
+	serverOptions := append(s.conn.ServerOptions(), s.interceptors.ServerOptions()...)
+	serverOptions = append(serverOptions, chunkServerAuthOptions(s.tokens)...)
+	serverOptions = append(serverOptions, s.auth.serverOptions()...)
+	serverOptions = append(serverOptions, s.scheduler.serverOptions()...)
+	grpcServer := grpc.NewServer(append(serverOptions, s.faults.ServerOptions()...)...)
 	pb.RegisterMasterServer(grpcServer, s)
+	if s.debugServices {
+		common.RegisterDebugServices(grpcServer)
+	}
+
+	// Registering standard grpc health checking service for load balancers and probes
+	healthpb.RegisterHealthServer(grpcServer, s.health)
+	s.health.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	s.health.SetServingStatus(pb.Master_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+
+	s.startRepairWorkers()
+	s.goBackground(s.startDeadServerMonitor)
+	s.goBackground(s.startHotChunkMonitor)
+	s.goBackground(s.startTierMigration)
+	s.goBackground(s.startArchival)
+	s.goBackground(s.startFileExpiry)
+	s.goBackground(s.startUploadExpiry)
+	s.goBackground(s.startReclamation)
+	s.goBackground(s.startVersionExpiry)
+	s.goBackground(s.startFileReadFlush)
+	if s.geoReplicator != nil {
+		s.goBackground(s.geoReplicator.run)
+	}
+	s.webhooks.start()
 
 	log.Printf("Master server starting on %s", s.address)
+	s.serving.Store(grpcServer)
+	if s.stopped() {
+		grpcServer.Stop() // Stop ran before there was a grpc server to stop
+	}
+	s.ready.Store(true)
 
 	if err := grpcServer.Serve(listen); err != nil {
 		return fmt.Errorf("failed to serve: %v", err)
@@ -163,3 +1494,70 @@ func (s *Server) Start() error {
 
 	return nil
 }
+
+// Stop closes the connections and listener of the grpc server, ends the periodic background
+// monitors and closes the metadata store
+func (s *Server) Stop() {
+	s.stopOnce.Do(func() {
+		s.ready.Store(false)
+		s.backgroundMu.Lock()
+		s.cancel()
+		s.backgroundMu.Unlock()
+		s.repairs.Close()
+		if grpcServer := s.serving.Load(); grpcServer != nil {
+			grpcServer.Stop()
+		}
+		s.background.Wait()
+		// the reads counted since the last periodic flush
+		if err := s.metadata.FlushFileReads(); err != nil {
+			log.Printf("Warning: failed to record file reads: %v", err)
+		}
+		if err := s.metadata.Close(); err != nil {
+			log.Printf("Warning: failed to close metadata store: %v", err)
+		}
+	})
+}
+
+// startFileReadFlush periodically writes the file reads counted in memory to the metadata store
+func (s *Server) startFileReadFlush() {
+	ticker := time.NewTicker(fileReadFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+		}
+
+		if err := s.metadata.FlushFileReads(); err != nil {
+			log.Printf("Warning: failed to record file reads: %v", err)
+		}
+	}
+}
+
+// goBackground runs fn in a goroutine Stop waits for, fn returns once s.done is closed. Nothing is started
+// once Stop was called
+func (s *Server) goBackground(fn func()) {
+	s.backgroundMu.Lock()
+	defer s.backgroundMu.Unlock()
+
+	if s.stopped() {
+		return
+	}
+	s.background.Add(1)
+	go func() {
+		defer s.background.Done()
+		fn()
+	}()
+}
+
+// stopped reports whether Stop was called
+func (s *Server) stopped() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
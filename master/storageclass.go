@@ -0,0 +1,71 @@
+package master
+
+import (
+	"fmt"
+	"log"
+)
+
+// Storage classes a file can be stored in, each standing for a replication factor and a tier
+const (
+	StorageClassStandard          = "standard"           // common.ReplicationFactor replicas on the file's tier
+	StorageClassReducedRedundancy = "reduced-redundancy" // fewer replicas, for data that can be regenerated
+	StorageClassArchive           = "archive"            // compressed onto the archive tier, recalled when read
+)
+
+// reducedRedundancyReplicas is the number of replicas of the chunks of reduced redundancy files
+const reducedRedundancyReplicas = 2
+
+// validateStorageClass checks the storage class is one of the known ones, empty meaning standard
+func validateStorageClass(class string) error {
+	switch class {
+	case "", StorageClassStandard, StorageClassReducedRedundancy, StorageClassArchive:
+		return nil
+	default:
+		return fmt.Errorf("unknown storage class %q, expected %s, %s or %s", class, StorageClassStandard, StorageClassReducedRedundancy, StorageClassArchive)
+	}
+}
+
+// classReplicationFactor returns the replication factor files of the storage class get, 0 for common.ReplicationFactor
+func classReplicationFactor(class string) int {
+	if class == StorageClassReducedRedundancy {
+		return reducedRedundancyReplicas
+	}
+
+	return 0
+}
+
+// storageClass returns the storage class of the file, standard for files that never had one set
+func (f *FileMetadata) storageClass() string {
+	if f.StorageClass == "" {
+		return StorageClassStandard
+	}
+
+	return f.StorageClass
+}
+
+// convertStorageClass archives a file moved to the archive class, or recalls an archived file moved to
+// another class, so the change doesn't wait for the next archival round or read
+func (s *Server) convertStorageClass(filename string) {
+	file, exists, err := s.metadata.GetFile(filename)
+	if err != nil {
+		log.Printf("Warning: failed to look up %s to convert its storage class: %v", filename, err)
+		return
+	}
+	if !exists {
+		return
+	}
+
+	if file.storageClass() == StorageClassArchive {
+		if !file.archivable() || s.uploads.InProgress(filename) {
+			return
+		}
+		if _, err := s.archiveFile(file); err != nil {
+			log.Printf("Warning: failed to archive %s: %v", filename, err)
+		}
+		return
+	}
+
+	if version, _ := file.version(file.Generation); version.ArchivedSize > 0 {
+		s.startRecall(filename, version)
+	}
+}
@@ -0,0 +1,170 @@
+package master
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// MetadataStore persists the namespace: files, the inodes hard linked files share, chunks, the latest version
+// handed out per chunk handle and tombstones of deleted chunks waiting to be reclaimed. Records are changed in transactions of Update,
+// so an operation changing several records leaves all of its changes or none should the master crash part way.
+// Records passed in and returned are copies, changes only take effect once written back with Put
+type MetadataStore interface {
+	// GetFile returns the file, false if it doesn't exist
+	GetFile(filename string) (*FileMetadata, bool, error)
+	// ForEachFile calls fn for every file whose name starts with prefix in filename order, stopping
+	// at the first error. fn must not modify the store
+	ForEachFile(prefix string, fn func(file *FileMetadata) error) error
+	// ForEachFileAfter is ForEachFile skipping the files up to and including after, for resuming an iteration
+	ForEachFileAfter(prefix, after string, fn func(file *FileMetadata) error) error
+
+	// GetInode returns the inode, false if it doesn't exist
+	GetInode(id string) (*Inode, bool, error)
+
+	// GetChunk returns the chunk, false if it doesn't exist
+	GetChunk(chunkHandle string) (*ChunkMetadata, bool, error)
+	// ForEachChunk calls fn for every chunk, stopping at the first error. fn must not modify the store
+	ForEachChunk(fn func(chunk *ChunkMetadata) error) error
+
+	// GetChunkVersion returns the latest version handed out for a chunk handle, 0 if none was
+	GetChunkVersion(chunkHandle string) (int32, error)
+
+	// ForEachTombstone calls fn for every tombstone in id order, stopping at the first error. fn must not modify the store
+	ForEachTombstone(fn func(tombstone *Tombstone) error) error
+
+	// GetRevokedToken returns the revocation of a delegation token, false if the token isn't revoked
+	GetRevokedToken(id string) (*RevokedToken, bool, error)
+	// ForEachRevokedToken calls fn for every revoked delegation token, stopping at the first error. fn must not modify the store
+	ForEachRevokedToken(fn func(revoked *RevokedToken) error) error
+
+	// Update runs fn in a transaction, applying every change fn made through tx at once when it returns nil and
+	// none of them when it returns an error. Reads through tx see the changes fn made. fn must not use the store
+	// itself, and may be called again when a store shared by several masters finds the records it read changed
+	Update(fn func(tx MetadataTx) error) error
+
+	Close() error
+}
+
+// FileWatcher is implemented by stores several masters can share, so each master's search index follows the
+// files the others write
+type FileWatcher interface {
+	// WatchFiles calls changed with every file written from now on, nil for files deleted, in the order they
+	// changed. Should changes be missed, e.g. once etcd compacted them away, missed is called and the files must
+	// be read again. Watching ends when the store is closed
+	WatchFiles(changed func(filename string, file *FileMetadata), missed func()) error
+}
+
+// MetadataTx reads and changes the records of a MetadataStore within a transaction of Update
+type MetadataTx interface {
+	// GetFile returns the file, false if it doesn't exist
+	GetFile(filename string) (*FileMetadata, bool, error)
+	PutFile(file *FileMetadata) error
+	DeleteFile(filename string) error
+
+	// GetInode returns the inode, false if it doesn't exist
+	GetInode(id string) (*Inode, bool, error)
+	PutInode(inode *Inode) error
+	DeleteInode(id string) error
+
+	// GetChunk returns the chunk, false if it doesn't exist
+	GetChunk(chunkHandle string) (*ChunkMetadata, bool, error)
+	PutChunk(chunk *ChunkMetadata) error
+	DeleteChunk(chunkHandle string) error
+
+	// GetChunkVersion returns the latest version handed out for a chunk handle, 0 if none was
+	GetChunkVersion(chunkHandle string) (int32, error)
+	PutChunkVersion(chunkHandle string, version int32) error
+
+	// GetTombstone returns the tombstone, false if it doesn't exist
+	GetTombstone(id string) (*Tombstone, bool, error)
+	PutTombstone(tombstone *Tombstone) error
+	DeleteTombstone(id string) error
+
+	PutRevokedToken(revoked *RevokedToken) error
+	DeleteRevokedToken(id string) error
+}
+
+// MetadataBackend selects the MetadataStore implementation used by the master
+type MetadataBackend string
+
+const (
+	// BackendMemory keeps the namespace in memory, it is lost when the master stops
+	BackendMemory MetadataBackend = "memory"
+
+	// BackendBolt keeps the namespace in a BoltDB file, so it survives restarts and may outgrow memory
+	BackendBolt MetadataBackend = "bolt"
+
+	// BackendEtcd keeps the namespace in an etcd cluster shared by every master pointed at it
+	BackendEtcd MetadataBackend = "etcd"
+)
+
+// ParseMetadataBackend converts a flag value to a MetadataBackend
+func ParseMetadataBackend(value string) (MetadataBackend, error) {
+	switch backend := MetadataBackend(value); backend {
+	case BackendMemory, BackendBolt, BackendEtcd:
+		return backend, nil
+	default:
+		return "", fmt.Errorf("unknown metadata backend %q, expected memory, bolt or etcd", value)
+	}
+}
+
+// OpenMetadataStore opens the metadata store of the configured backend
+func OpenMetadataStore(config Config) (MetadataStore, error) {
+	switch config.MetadataBackend {
+	case BackendMemory, "":
+		return NewMemoryStore(), nil
+	case BackendBolt:
+		return OpenBoltStore(config.MetadataPath)
+	case BackendEtcd:
+		return OpenEtcdStore(config.Etcd)
+	default:
+		return nil, fmt.Errorf("unknown metadata backend %q", config.MetadataBackend)
+	}
+}
+
+// clone returns a deep copy of the file metadata
+func (f *FileMetadata) clone() *FileMetadata {
+	fileCopy := *f
+	fileCopy.Chunks = slices.Clone(f.Chunks)
+	fileCopy.Tags = maps.Clone(f.Tags)
+	fileCopy.Versions = slices.Clone(f.Versions)
+	for i := range fileCopy.Versions {
+		fileCopy.Versions[i].Chunks = slices.Clone(f.Versions[i].Chunks)
+	}
+	if f.Upload != nil {
+		fileCopy.Upload = f.Upload.clone()
+	}
+	return &fileCopy
+}
+
+// clone returns a deep copy of the inode
+func (i *Inode) clone() *Inode {
+	inodeCopy := *i
+	inodeCopy.Names = slices.Clone(i.Names)
+	return &inodeCopy
+}
+
+// clone returns a deep copy of the chunk metadata
+func (c *ChunkMetadata) clone() *ChunkMetadata {
+	chunkCopy := *c
+	chunkCopy.Locations = slices.Clone(c.Locations)
+	return &chunkCopy
+}
+
+// clone returns a copy of the revoked token
+func (r *RevokedToken) clone() *RevokedToken {
+	revokedCopy := *r
+	return &revokedCopy
+}
+
+// clone returns a deep copy of the tombstone
+func (t *Tombstone) clone() *Tombstone {
+	tombstoneCopy := *t
+	tombstoneCopy.Chunks = make([]*ChunkMetadata, len(t.Chunks))
+	for i, chunk := range t.Chunks {
+		tombstoneCopy.Chunks[i] = chunk.clone()
+	}
+
+	return &tombstoneCopy
+}
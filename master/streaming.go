@@ -0,0 +1,177 @@
+package master
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/harshvardha/distributed_file_system/common"
+	pb "github.com/harshvardha/distributed_file_system/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// uploadHints returns the placement hints of the chunks of an upload
+func uploadHints(req *pb.UploadFileRequest) PlacementHints {
+	return PlacementHints{
+		PreferredZone:    req.Hints.GetPreferredZone(),
+		LocalHost:        req.Hints.GetLocalHost(),
+		AntiAffinityFile: req.Hints.GetAntiAffinityFile(),
+		Tier:             req.StorageTier,
+	}
+}
+
+// AllocateChunk handles requests for the next chunk of a streaming upload, which writes data of unknown size,
+// e.g. from a pipe, one chunk at a time. Asking for a chunk already allocated places it again, so a client
+// whose request failed part way can retry it
+func (s *Server) AllocateChunk(ctx context.Context, req *pb.AllocateChunkRequest) (*pb.AllocateChunkResponse, error) {
+	log.Printf("Chunk allocation request for file: %s, chunk %d", req.Filename, req.ChunkIndex)
+
+	unlock := s.locks.Lock(req.Filename)
+	defer unlock()
+
+	upload, err := s.uploads.Get(req.Filename, req.UploadId)
+	if err != nil {
+		return nil, status.Errorf(codes.Aborted, "failed to allocate chunk of %s: %v", req.Filename, err)
+	}
+	if !upload.streaming {
+		return nil, status.Errorf(codes.FailedPrecondition, "failed to allocate chunk of %s: the upload allocated its chunks when it started", req.Filename)
+	}
+
+	file, exists, err := s.metadata.GetUpload(req.Filename, upload.generation)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up file %s: %v", req.Filename, err)
+	}
+	if !exists {
+		return nil, status.Errorf(codes.Aborted, "failed to allocate chunk of %s: %v", req.Filename, ErrUploadSuperseded)
+	}
+
+	chunkIndex := int(req.ChunkIndex)
+	if chunkIndex < 0 || chunkIndex > len(file.Chunks) {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to allocate chunk %d of %s: the next chunk is %d", chunkIndex, req.Filename, len(file.Chunks))
+	}
+
+	var chunk *ChunkMetadata
+	if chunkIndex < len(file.Chunks) {
+		chunk, exists, err = s.metadata.GetChunk(file.Chunks[chunkIndex])
+		if err != nil || !exists {
+			return nil, fmt.Errorf("failed to look up chunk %d of %s: %v", chunkIndex, req.Filename, err)
+		}
+	} else {
+		chunk, err = s.metadata.AddUploadChunk(req.Filename, upload.generation, common.GenerateChunkHandle(req.Filename, file.Generation, chunkIndex))
+		if err != nil {
+			return nil, fmt.Errorf("failed to add chunk %d to %s: %v", chunkIndex, req.Filename, err)
+		}
+	}
+
+	chunkLocation, err := s.placeNewChunk(req.Filename, chunk.ChunkHandle, chunk.ChunkIndex, chunk.Version, file.replicationFactor(), upload.hints)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.AllocateChunkResponse{
+		ChunkLocation: chunkLocation,
+	}, nil
+}
+
+// finishStreamingUpload sets the size of the file of a streaming upload being completed, which must end in the last
+// chunk the upload allocated. Other uploads are left alone. The caller must hold the file lock
+func (s *Server) finishStreamingUpload(req *pb.CompleteUploadRequest) error {
+	upload, err := s.uploads.Get(req.Filename, req.UploadId)
+	if err != nil {
+		return status.Errorf(codes.Aborted, "failed to complete upload of %s: %v", req.Filename, err)
+	}
+	if !upload.streaming {
+		return nil
+	}
+	if req.Filesize == nil {
+		return status.Errorf(codes.InvalidArgument, "failed to complete upload of %s: streaming uploads must send their size", req.Filename)
+	}
+
+	file, exists, err := s.metadata.GetUpload(req.Filename, upload.generation)
+	if err != nil {
+		return fmt.Errorf("failed to look up file %s: %v", req.Filename, err)
+	}
+	if !exists {
+		return status.Errorf(codes.Aborted, "failed to complete upload of %s: %v", req.Filename, ErrUploadSuperseded)
+	}
+
+	filesize := *req.Filesize
+	if filesize < 0 || common.CalculateNumChunks(filesize) != len(file.Chunks) {
+		return status.Errorf(codes.InvalidArgument, "failed to complete upload of %s: %d bytes don't fit the %d chunks allocated", req.Filename, filesize, len(file.Chunks))
+	}
+
+	if err := s.metadata.SetUploadSize(req.Filename, upload.generation, filesize); err != nil {
+		return fmt.Errorf("failed to set size of %s: %v", req.Filename, err)
+	}
+
+	log.Printf("Streaming upload of %s completed with %d bytes in %d chunks", req.Filename, filesize, len(file.Chunks))
+	return nil
+}
+
+// AddUploadChunk adds a chunk to the end of the file written by the upload with the given generation
+// and returns it, failing with ErrUploadSuperseded if the upload is neither staged nor current
+func (m *Metadata) AddUploadChunk(filename string, generation int64, chunkHandle string) (*ChunkMetadata, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var chunk *ChunkMetadata
+	err := m.update(func(tx *metadataTx) error {
+		file, exists, err := tx.GetFile(filename)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrUploadSuperseded
+		}
+		upload, exists := file.upload(generation)
+		if !exists {
+			return ErrUploadSuperseded
+		}
+
+		chunk, err = tx.addChunk(chunkHandle, filename, int32(len(upload.Chunks)))
+		if err != nil {
+			return err
+		}
+		chunk.ReplicationFactor = upload.ReplicationFactor
+		chunk.Tier = upload.Tier
+		if err := tx.PutChunk(chunk); err != nil {
+			return err
+		}
+
+		upload.Chunks = append(upload.Chunks, chunkHandle)
+		upload.ChunkCount = max(upload.ChunkCount, len(upload.Chunks))
+		return tx.PutFile(file)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return chunk, nil
+}
+
+// SetUploadSize sets the size of the file written by the streaming upload with the given generation,
+// failing with ErrUploadSuperseded if the upload is neither staged nor current
+func (m *Metadata) SetUploadSize(filename string, generation int64, filesize int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.update(func(tx *metadataTx) error {
+		file, exists, err := tx.GetFile(filename)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrUploadSuperseded
+		}
+		upload, exists := file.upload(generation)
+		if !exists {
+			return ErrUploadSuperseded
+		}
+
+		upload.Filesize = filesize
+		upload.ModifiedAt = time.Now()
+		return tx.PutFile(file)
+	})
+}
@@ -0,0 +1,122 @@
+package master
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	pb "github.com/harshvardha/distributed_file_system/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxSymlinkHops bounds how many symlinks are followed to reach a file, so links pointing at each other fail
+const maxSymlinkHops = 8
+
+// ErrTooManySymlinks is returned when resolving a name follows more than maxSymlinkHops symlinks
+var ErrTooManySymlinks = errors.New("too many levels of symbolic links")
+
+// isSymlink reports whether the entry is a symlink rather than a file with contents
+func (f *FileMetadata) isSymlink() bool {
+	return f.SymlinkTarget != ""
+}
+
+// AddSymlink adds a symlink named linkName pointing at target, which doesn't have to exist, and returns its
+// generation. An entry already using the name is replaced like AddFile does, keeping up to keepVersions of
+// its versions and returning the chunks of the ones that weren't kept
+func (m *Metadata) AddSymlink(linkName, target string, keepVersions int, ownership FileOwnership) (int64, []*ChunkMetadata, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	link := &FileMetadata{
+		Filename:      linkName,
+		Chunks:        make([]string, 0),
+		CreatedAt:     now,
+		ModifiedAt:    now,
+		Generation:    m.nextGeneration(),
+		SymlinkTarget: target,
+	}
+	link.setOwnership(ownership)
+
+	var chunks []*ChunkMetadata
+	err := m.update(func(tx *metadataTx) error {
+		var err error
+		chunks, err = tx.replaceFile(link, keepVersions)
+		return err
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return link.Generation, chunks, nil
+}
+
+// resolveFile looks up a file following symlinks, returning the file they lead to, whose Filename is the
+// resolved name, and false if a link dangles or the file doesn't exist
+func (s *Server) resolveFile(filename string) (*FileMetadata, bool, error) {
+	name := filename
+	for range maxSymlinkHops + 1 {
+		file, exists, err := s.metadata.GetFile(name)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to look up file %s: %v", name, err)
+		}
+		if !exists || !file.isSymlink() {
+			return file, exists, nil
+		}
+		name = file.SymlinkTarget
+	}
+
+	return nil, false, status.Errorf(codes.FailedPrecondition, "failed to resolve %s: %v", filename, ErrTooManySymlinks)
+}
+
+// SymlinkFile handles requests to create a symlink. Downloads, stat, version listings, copies, clones and
+// presigned urls of the link follow it to its target, while renaming, deleting, tagging or overwriting
+// the link acts on the link itself
+func (s *Server) SymlinkFile(ctx context.Context, req *pb.SymlinkFileRequest) (*pb.SymlinkFileResponse, error) {
+	log.Printf("Symlink request: %s -> %s", req.LinkName, req.Target)
+
+	if req.LinkName == "" || req.Target == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "symlinks need a name and a target")
+	}
+	if req.LinkName == req.Target {
+		return nil, status.Errorf(codes.InvalidArgument, "symlink %s can't point at itself", req.LinkName)
+	}
+
+	unlock := s.locks.Lock(req.LinkName)
+	defer unlock()
+
+	if s.uploads.InProgress(req.LinkName) {
+		return nil, status.Errorf(codes.Aborted, "failed to create symlink %s: %v", req.LinkName, ErrUploadInProgress)
+	}
+
+	existing, exists, err := s.metadata.GetFile(req.LinkName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up file %s: %v", req.LinkName, err)
+	}
+	if exists && !req.Overwrite {
+		return nil, status.Errorf(codes.AlreadyExists, "failed to create symlink %s: file already exists", req.LinkName)
+	}
+	if err := checkMutable(req.LinkName, existing, exists); err != nil {
+		return nil, err
+	}
+	identity := identityFromContext(ctx)
+	if err := checkAccess(req.LinkName, existing, exists, identity, AccessWrite); err != nil {
+		return nil, err
+	}
+
+	// replicas of replaced versions that aren't kept are removed in background
+	generation, dropped, err := s.metadata.AddSymlink(req.LinkName, req.Target, s.tunables.Load().KeepVersions, newOwnership(identity, DefaultFileMode))
+	s.goBackground(func() { s.deleteChunks(dropped) })
+	if err != nil {
+		return nil, fmt.Errorf("failed to create symlink %s: %v", req.LinkName, err)
+	}
+
+	s.events.Publish(pb.FileEventType_FILE_EVENT_CREATED, req.LinkName, "", 0)
+
+	return &pb.SymlinkFileResponse{
+		Generation: generation,
+	}, nil
+}
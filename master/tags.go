@@ -0,0 +1,79 @@
+package master
+
+import (
+	"fmt"
+	"maps"
+)
+
+// limits keeping tags small, they are stored in every file record
+const (
+	maxTagsPerFile = 64
+	maxTagKeyLen   = 128
+	maxTagValueLen = 256
+)
+
+// validateTags checks tag keys and values against the tag limits
+func validateTags(tags map[string]string) error {
+	if len(tags) > maxTagsPerFile {
+		return fmt.Errorf("files can't have more than %d tags", maxTagsPerFile)
+	}
+
+	for key, value := range tags {
+		if key == "" {
+			return fmt.Errorf("tag keys can't be empty")
+		}
+		if len(key) > maxTagKeyLen {
+			return fmt.Errorf("tag key %q is longer than %d bytes", key, maxTagKeyLen)
+		}
+		if len(value) > maxTagValueLen {
+			return fmt.Errorf("value of tag %q is longer than %d bytes", key, maxTagValueLen)
+		}
+	}
+
+	return nil
+}
+
+// hasTags reports whether the file has every tag in tags with the same value
+func (f *FileMetadata) hasTags(tags map[string]string) bool {
+	for key, value := range tags {
+		if fileValue, exists := f.Tags[key]; !exists || fileValue != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+// UpdateFileTags sets the tags in set and removes the tags keyed by remove, then returns the resulting
+// tags of the file, false if the file doesn't exist
+func (m *Metadata) UpdateFileTags(filename string, set map[string]string, remove []string) (map[string]string, bool, error) {
+	file, exists, err := m.UpdateAttributes(filename, AttributeUpdate{SetTags: set, RemoveTags: remove})
+	if err != nil || !exists {
+		return nil, exists, err
+	}
+
+	return file.Tags, true, nil
+}
+
+// updateTags sets the tags in set and removes the tags keyed by remove on file
+func (f *FileMetadata) updateTags(set map[string]string, remove []string) error {
+	if err := validateTags(set); err != nil {
+		return err
+	}
+
+	tags := maps.Clone(f.Tags)
+	if tags == nil {
+		tags = make(map[string]string, len(set))
+	}
+	maps.Copy(tags, set)
+	for _, key := range remove {
+		delete(tags, key)
+	}
+
+	if len(tags) > maxTagsPerFile {
+		return fmt.Errorf("files can't have more than %d tags", maxTagsPerFile)
+	}
+
+	f.Tags = tags
+	return nil
+}
@@ -0,0 +1,144 @@
+package master
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"time"
+)
+
+const (
+	// tierMigrationInterval is how often the master looks for chunks stored on another tier than their file asks for
+	tierMigrationInterval = time.Minute
+
+	// maxTierMigrations is the number of chunks moved to their tier per interval, so changing the tier of a large
+	// file doesn't swamp the chunk servers
+	maxTierMigrations = 16
+
+	// maxTierLength is the longest storage tier name accepted
+	maxTierLength = 32
+)
+
+// validateTier checks a storage tier name is a short lowercase word like ssd or hdd, empty meaning any tier
+func validateTier(tier string) error {
+	if len(tier) > maxTierLength {
+		return fmt.Errorf("tier %q is longer than %d characters", tier, maxTierLength)
+	}
+	for _, c := range tier {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '-' {
+			return fmt.Errorf("tier %q may only contain lowercase letters, digits and dashes", tier)
+		}
+	}
+
+	return nil
+}
+
+// onTier reports whether the chunk server at address is of the tier, always true for any tier. The caller must hold the lock
+func (m *Metadata) onTier(address, tier string) bool {
+	if tier == "" {
+		return true
+	}
+
+	server, exists := m.chunkServers[address]
+	return exists && server.Tier == tier
+}
+
+// MisplacedChunks returns up to limit chunks with a replica on another tier than the chunk asks for, each with
+// the least loaded live server of its tier not holding it yet. Chunks whose tier has no such server are skipped
+func (m *Metadata) MisplacedChunks(limit int) (map[string]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := time.Now()
+	servers := make(map[string][]*ChunkServerInfo)
+	for _, server := range m.chunkServers {
+		if server.Tier != "" && server.State == ChunkServerAlive && now.Sub(server.LatestHeartbeat) < m.DeadServerTimeout() {
+			servers[server.Tier] = append(servers[server.Tier], server)
+		}
+	}
+
+	misplaced := make(map[string]string)
+	err := m.forEachChunk(func(chunk *ChunkMetadata) error {
+		if len(misplaced) >= limit || chunk.Tier == "" || len(chunk.Locations) == 0 {
+			return nil
+		}
+		if !slices.ContainsFunc(chunk.Locations, func(location string) bool { return !m.onTier(location, chunk.Tier) }) {
+			return nil
+		}
+
+		var target *ChunkServerInfo
+		for _, server := range servers[chunk.Tier] {
+			if slices.Contains(chunk.Locations, server.Address) {
+				continue
+			}
+			if target == nil || server.Load.Score() < target.Load.Score() {
+				target = server
+			}
+		}
+		if target != nil {
+			misplaced[chunk.ChunkHandle] = target.Address
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return misplaced, nil
+}
+
+// startTierMigration periodically moves replicas stored on another tier than their file asks for, e.g. after
+// the file's tier changed or after placement fell back to another tier, to servers of the right tier
+func (s *Server) startTierMigration() {
+	ticker := time.NewTicker(tierMigrationInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+		}
+
+		misplaced, err := s.metadata.MisplacedChunks(maxTierMigrations)
+		if err != nil {
+			log.Printf("Warning: failed to look for chunks on the wrong tier: %v", err)
+			continue
+		}
+		if len(misplaced) > 0 {
+			log.Printf("Tier migration: moving %d chunks to their tier", len(misplaced))
+		}
+
+		for chunkHandle, target := range misplaced {
+			s.migrateChunk(chunkHandle, target)
+		}
+	}
+}
+
+// migrateChunk copies a chunk to target, a server of the chunk's tier, then deletes the replica that became excess,
+// a replica on another tier going first
+func (s *Server) migrateChunk(chunkHandle, target string) {
+	chunk, exists, err := s.metadata.GetChunk(chunkHandle)
+	if err != nil {
+		log.Printf("Warning: failed to look up chunk %s for tier migration: %v", chunkHandle, err)
+		return
+	}
+	if !exists {
+		return
+	}
+
+	for _, source := range chunk.Locations {
+		if err := s.replicateChunkOnServer(chunkHandle, chunk.Version, source, target); err != nil {
+			log.Printf("Warning: failed to move chunk %s from %s to %s: %v", chunkHandle, source, target, err)
+			continue
+		}
+
+		log.Printf("Chunk %s copied from %s to %s on tier %s", chunkHandle, source, target, chunk.Tier)
+		if err := s.metadata.AddChunkLocation(chunkHandle, target); err != nil {
+			log.Printf("Warning: failed to record migrated replica of chunk %s on %s: %v", chunkHandle, target, err)
+			return
+		}
+		s.removeExcessReplicas(chunkHandle)
+		return
+	}
+}
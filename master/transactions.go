@@ -0,0 +1,146 @@
+package master
+
+import (
+	"slices"
+)
+
+// metadataTx is a transaction of the metadata store that keeps what the master holds in memory beside the
+// store, the search index, chunk locations and read statistics, in step with the records it changes. Those
+// are only updated once the transaction commits
+type metadataTx struct {
+	MetadataTx
+	m *Metadata
+
+	files     map[string]*FileMetadata // key: filename, value: file written, nil for deleted files
+	locations map[string][]string      // key: chunk handle, value: locations of the chunk written
+	deleted   []string                 // chunk handles deleted
+}
+
+// update runs fn in a store transaction, applying its changes to the search index and chunk locations
+// once it commits. The caller must hold the lock
+func (m *Metadata) update(fn func(tx *metadataTx) error) error {
+	var committed *metadataTx
+	err := m.store.Update(func(storeTx MetadataTx) error {
+		// starting over should the store run fn again
+		committed = &metadataTx{
+			MetadataTx: storeTx,
+			m:          m,
+			files:      make(map[string]*FileMetadata),
+			locations:  make(map[string][]string),
+		}
+		return fn(committed)
+	})
+	if err != nil {
+		return err
+	}
+
+	for filename, file := range committed.files {
+		if file == nil {
+			m.index.remove(filename)
+		} else {
+			m.index.put(file)
+		}
+	}
+	for chunkHandle, locations := range committed.locations {
+		m.locations.set(chunkHandle, locations)
+	}
+	for _, chunkHandle := range committed.deleted {
+		delete(m.readStats, chunkHandle)
+	}
+
+	return nil
+}
+
+// getChunk returns the chunk with its locations. The caller must hold the lock
+func (m *Metadata) getChunk(chunkHandle string) (*ChunkMetadata, bool, error) {
+	chunk, exists, err := m.store.GetChunk(chunkHandle)
+	if err != nil || !exists {
+		return nil, false, err
+	}
+
+	chunk.Locations = m.locations.get(chunkHandle)
+	return chunk, true, nil
+}
+
+// forEachChunk calls fn for every chunk with its locations. The caller must hold the lock
+func (m *Metadata) forEachChunk(fn func(chunk *ChunkMetadata) error) error {
+	return m.store.ForEachChunk(func(chunk *ChunkMetadata) error {
+		chunk.Locations = m.locations.get(chunk.ChunkHandle)
+		return fn(chunk)
+	})
+}
+
+// GetFile returns the file with what a hard linked file shares with its other names read from its inode
+func (tx *metadataTx) GetFile(filename string) (*FileMetadata, bool, error) {
+	file, exists, err := tx.MetadataTx.GetFile(filename)
+	if err != nil || !exists || file.Inode == "" {
+		return file, exists, err
+	}
+
+	inode, found, err := tx.GetInode(file.Inode)
+	if err != nil {
+		return nil, false, err
+	}
+	if found {
+		file.applyInode(inode)
+	}
+	return file, true, nil
+}
+
+// PutFile writes a file, indexing it for search once committed
+func (tx *metadataTx) PutFile(file *FileMetadata) error {
+	if err := tx.MetadataTx.PutFile(file); err != nil {
+		return err
+	}
+
+	tx.files[file.Filename] = file.clone()
+	return nil
+}
+
+// DeleteFile removes a file, dropping it from the search index once committed
+func (tx *metadataTx) DeleteFile(filename string) error {
+	if err := tx.MetadataTx.DeleteFile(filename); err != nil {
+		return err
+	}
+
+	tx.files[filename] = nil
+	return nil
+}
+
+// GetChunk returns the chunk with its locations
+func (tx *metadataTx) GetChunk(chunkHandle string) (*ChunkMetadata, bool, error) {
+	chunk, exists, err := tx.MetadataTx.GetChunk(chunkHandle)
+	if err != nil || !exists {
+		return nil, false, err
+	}
+
+	if locations, written := tx.locations[chunkHandle]; written {
+		chunk.Locations = slices.Clone(locations)
+	} else {
+		chunk.Locations = tx.m.locations.get(chunkHandle)
+	}
+	return chunk, true, nil
+}
+
+// PutChunk writes a chunk without its locations, which replace the chunk's known locations once committed
+func (tx *metadataTx) PutChunk(chunk *ChunkMetadata) error {
+	record := *chunk
+	record.Locations = nil
+	if err := tx.MetadataTx.PutChunk(&record); err != nil {
+		return err
+	}
+
+	tx.locations[chunk.ChunkHandle] = slices.Clone(chunk.Locations)
+	return nil
+}
+
+// DeleteChunk removes a chunk. Its locations stay known until its replicas are deleted, its read statistics go
+func (tx *metadataTx) DeleteChunk(chunkHandle string) error {
+	if err := tx.MetadataTx.DeleteChunk(chunkHandle); err != nil {
+		return err
+	}
+
+	delete(tx.locations, chunkHandle)
+	tx.deleted = append(tx.deleted, chunkHandle)
+	return nil
+}
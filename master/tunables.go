@@ -0,0 +1,59 @@
+package master
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+// Tunables are the settings of a master that SetTunables changes while it runs, without dropping the
+// connections of clients and chunk servers or the requests in flight
+type Tunables struct {
+	// HeartbeatTimeout is how long a chunk server may go without a heartbeat before it is considered dead,
+	// longer than the interval chunk servers send heartbeats at
+	HeartbeatTimeout      time.Duration
+	MaxConcurrentRequests int           // client requests served at once, 0 serves every request right away
+	SlowRequestThreshold  time.Duration // requests taking longer are logged, 0 logs none
+	LogRequests           bool          // every request is logged with its caller, duration and status
+	KeepVersions          int           // previous versions kept when a file is overwritten, 0 keeps none
+	VersionMaxAge         time.Duration // previous versions are dropped this long after being replaced, 0 keeps them
+	ReclaimDelay          time.Duration // replicas of deleted chunks are kept this long before being deleted
+}
+
+// validate checks the tunables are usable
+func (t Tunables) validate() error {
+	if t.HeartbeatTimeout <= heartbeatInterval {
+		return fmt.Errorf("heartbeat timeout of %s must be longer than the %s heartbeat interval", t.HeartbeatTimeout, heartbeatInterval)
+	}
+	if t.MaxConcurrentRequests < 0 || t.KeepVersions < 0 || t.SlowRequestThreshold < 0 || t.VersionMaxAge < 0 || t.ReclaimDelay < 0 {
+		return fmt.Errorf("tunables must not be negative")
+	}
+
+	return nil
+}
+
+// Tunables returns the settings the master currently runs with
+func (s *Server) Tunables() Tunables {
+	return *s.tunables.Load()
+}
+
+// SetTunables changes the settings of the running master, e.g. when its configuration is reloaded. Requests
+// running beyond a lowered MaxConcurrentRequests finish, chunk servers already dead aren't revived by a
+// longer HeartbeatTimeout until their next heartbeat
+func (s *Server) SetTunables(tunables Tunables) error {
+	if err := tunables.validate(); err != nil {
+		return fmt.Errorf("invalid tunables: %v", err)
+	}
+
+	previous := s.tunables.Swap(&tunables)
+	s.metadata.SetDeadServerTimeout(tunables.HeartbeatTimeout)
+	s.scheduler.setLimit(tunables.MaxConcurrentRequests)
+	s.slowRequests.SetThreshold(tunables.SlowRequestThreshold)
+	s.interceptors.SetLogRequests(tunables.LogRequests)
+
+	if previous != nil && *previous != tunables {
+		log.Printf("Tunables changed from %+v to %+v", *previous, tunables)
+	}
+
+	return nil
+}
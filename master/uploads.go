@@ -0,0 +1,267 @@
+package master
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	pb "github.com/harshvardha/distributed_file_system/proto"
+)
+
+const (
+	// uploadSessionTimeout is how long an upload session lasts unless the client renews it. A session that
+	// runs out belongs to a client that went away, its upload is abandoned and the half written file removed
+	uploadSessionTimeout = 5 * time.Minute
+
+	// uploadExpiryInterval is how often expired upload sessions are cleaned up
+	uploadExpiryInterval = 30 * time.Second
+)
+
+var (
+	// ErrUploadInProgress is returned when another client is still uploading the same file
+	ErrUploadInProgress = errors.New("upload already in progress")
+
+	// ErrUploadSuperseded is returned when completing an upload that was replaced by a newer upload of the same file
+	ErrUploadSuperseded = errors.New("upload was superseded by a newer upload")
+
+	// ErrUploadExpired is returned when renewing an upload session that already ran out
+	ErrUploadExpired = errors.New("upload session expired")
+)
+
+// pendingUpload is an upload session whose client hasn't finished writing the chunks yet
+type pendingUpload struct {
+	id         string
+	expires    time.Time
+	generation int64            // generation allocated to the upload, 0 until its chunks are allocated
+	protection UploadProtection // applied to the file once the upload completes
+	streaming  bool             // chunks are allocated one at a time, see AllocateChunk
+	hints      PlacementHints   // placement of the chunks of a streaming upload
+}
+
+// UploadRegistry tracks uploads in progress so two clients can't write the same file at once
+type UploadRegistry struct {
+	mu      sync.Mutex
+	uploads map[string]*pendingUpload // key: filename, value: upload in progress
+}
+
+// NewUploadRegistry creates a new upload registry
+func NewUploadRegistry() *UploadRegistry {
+	return &UploadRegistry{
+		uploads: make(map[string]*pendingUpload),
+	}
+}
+
+// Begin opens an upload session for filename and returns its id and expiry. It fails with ErrUploadInProgress
+// while another session of the file is open, a session that ran out is replaced and can no longer complete
+func (r *UploadRegistry) Begin(filename string, protection UploadProtection) (string, time.Time, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if upload, exists := r.uploads[filename]; exists && time.Now().Before(upload.expires) {
+		return "", time.Time{}, ErrUploadInProgress
+	}
+
+	id := newUploadID()
+	expires := time.Now().Add(uploadSessionTimeout)
+	r.uploads[filename] = &pendingUpload{
+		id:         id,
+		expires:    expires,
+		protection: protection,
+	}
+
+	return id, expires, nil
+}
+
+// SetGeneration records the generation allocated to the upload of filename with the given id
+func (r *UploadRegistry) SetGeneration(filename, id string, generation int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if upload, exists := r.uploads[filename]; exists && upload.id == id {
+		upload.generation = generation
+	}
+}
+
+// SetStreaming marks the upload of filename with the given id as allocating its chunks one at a time, placed using hints
+func (r *UploadRegistry) SetStreaming(filename, id string, hints PlacementHints) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if upload, exists := r.uploads[filename]; exists && upload.id == id {
+		upload.streaming = true
+		upload.hints = hints
+	}
+}
+
+// Get returns the upload of filename with the given id, failing with ErrUploadSuperseded if a newer
+// upload of the file replaced it and with ErrUploadExpired if its session ran out
+func (r *UploadRegistry) Get(filename, id string) (pendingUpload, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	upload, exists := r.uploads[filename]
+	if !exists || upload.id != id {
+		return pendingUpload{}, ErrUploadSuperseded
+	}
+	if !time.Now().Before(upload.expires) {
+		return pendingUpload{}, ErrUploadExpired
+	}
+
+	return *upload, nil
+}
+
+// Renew extends the session of the upload of filename with the given id and returns its new expiry
+func (r *UploadRegistry) Renew(filename, id string) (time.Time, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	upload, exists := r.uploads[filename]
+	if !exists || upload.id != id {
+		return time.Time{}, ErrUploadSuperseded
+	}
+	if !time.Now().Before(upload.expires) {
+		return time.Time{}, ErrUploadExpired
+	}
+
+	upload.expires = time.Now().Add(uploadSessionTimeout)
+	return upload.expires, nil
+}
+
+// Expired returns the files whose upload session ran out
+func (r *UploadRegistry) Expired() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	filenames := make([]string, 0)
+	for filename, upload := range r.uploads {
+		if !now.Before(upload.expires) {
+			filenames = append(filenames, filename)
+		}
+	}
+
+	return filenames
+}
+
+// TakeExpired removes and returns the upload session of filename if it ran out, nil otherwise
+func (r *UploadRegistry) TakeExpired(filename string) *pendingUpload {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	upload, exists := r.uploads[filename]
+	if !exists || time.Now().Before(upload.expires) {
+		return nil
+	}
+
+	delete(r.uploads, filename)
+	return upload
+}
+
+// InProgress reports whether an upload session of filename is open
+func (r *UploadRegistry) InProgress(filename string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	upload, exists := r.uploads[filename]
+	return exists && time.Now().Before(upload.expires)
+}
+
+// Complete ends the upload of filename with the given id and returns it, failing with
+// ErrUploadSuperseded if a newer upload of the file replaced it or its session expired
+func (r *UploadRegistry) Complete(filename, id string) (*pendingUpload, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	upload, exists := r.uploads[filename]
+	if !exists || upload.id != id {
+		return nil, ErrUploadSuperseded
+	}
+
+	delete(r.uploads, filename)
+	return upload, nil
+}
+
+// abandonUpload removes what an upload that never completed allocated: an overwrite leaves the file as it was,
+// a file the upload created is removed, and the chunks of the upload are deleted. The caller must hold the file lock
+func (s *Server) abandonUpload(filename string, upload *pendingUpload, reason string) error {
+	if upload.generation == 0 {
+		return nil
+	}
+
+	chunks, removed, err := s.metadata.AbandonUpload(filename, upload.generation)
+	s.goBackground(func() { s.deleteChunks(chunks) })
+	if err != nil {
+		return fmt.Errorf("failed to clean up abandoned upload of %s: %v", filename, err)
+	}
+	if len(chunks) == 0 && !removed {
+		return nil // a later upload replaced the generation already
+	}
+
+	log.Printf("Abandoned upload of %s, generation %d (%s): deleting %d chunks", filename, upload.generation, reason, len(chunks))
+	if removed {
+		s.events.Publish(pb.FileEventType_FILE_EVENT_DELETED, filename, "", 0)
+	}
+
+	return nil
+}
+
+// abandonStagedUploads abandons the overwrites staged before the master started, upload sessions only live
+// in memory so those uploads can never complete
+func (s *Server) abandonStagedUploads() {
+	staged, err := s.metadata.StagedUploads()
+	if err != nil {
+		log.Printf("Warning: failed to look up staged uploads: %v", err)
+		return
+	}
+
+	// uploads begun since have a newer generation and are left alone
+	for filename, generation := range staged {
+		unlock := s.locks.Lock(filename)
+		if err := s.abandonUpload(filename, &pendingUpload{generation: generation}, "master restarted"); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+		unlock()
+	}
+}
+
+// startUploadExpiry periodically abandons uploads whose session ran out because the client went away
+func (s *Server) startUploadExpiry() {
+	ticker := time.NewTicker(uploadExpiryInterval)
+	defer ticker.Stop()
+
+	// by the first tick chunk servers reported their replicas, so the replicas of staged uploads are found
+	sweptStaged := false
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+		}
+
+		if !sweptStaged {
+			s.abandonStagedUploads()
+			sweptStaged = true
+		}
+
+		for _, filename := range s.uploads.Expired() {
+			unlock := s.locks.Lock(filename)
+			if upload := s.uploads.TakeExpired(filename); upload != nil {
+				if err := s.abandonUpload(filename, upload, "session expired"); err != nil {
+					log.Printf("Warning: %v", err)
+				}
+			}
+			unlock()
+		}
+	}
+}
+
+// newUploadID generates a random upload id
+func newUploadID() string {
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
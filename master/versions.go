@@ -0,0 +1,263 @@
+package master
+
+import (
+	"cmp"
+	"log"
+	"slices"
+	"time"
+)
+
+// versionExpiryInterval is how often previous file versions are checked for expiry
+const versionExpiryInterval = 10 * time.Minute
+
+// FileVersion is a previous version of a file, kept after the file was overwritten
+type FileVersion struct {
+	Generation int64
+	Filesize   int64
+	ChunkCount int
+	Chunks     []string  // chunk handles
+	Data       []byte    // contents of a version stored inline
+	CreatedAt  time.Time // when the version was written
+	ReplacedAt time.Time // when a newer version replaced this one, the version ages from here
+	// ArchivedSize is the compressed size of the contents of an archived version, 0 for versions that aren't archived
+	ArchivedSize int64
+}
+
+// asVersion returns the current contents of the file as a previous version replaced at replacedAt
+func (f *FileMetadata) asVersion(replacedAt time.Time) FileVersion {
+	return FileVersion{
+		Generation: f.Generation,
+		Filesize:   f.Filesize,
+		ChunkCount: f.ChunkCount,
+		Chunks:     slices.Clone(f.Chunks),
+		Data:       f.Data,
+		CreatedAt:  f.modifiedAt(),
+		ReplacedAt: replacedAt,
+
+		ArchivedSize: f.ArchivedSize,
+	}
+}
+
+// modifiedAt returns when the current contents of the file were written. Files
+// stored before modification times were recorded fall back to their creation time
+func (f *FileMetadata) modifiedAt() time.Time {
+	if f.ModifiedAt.IsZero() {
+		return f.CreatedAt
+	}
+
+	return f.ModifiedAt
+}
+
+// version returns the version of the file with the given generation, 0 meaning the current one
+func (f *FileMetadata) version(generation int64) (FileVersion, bool) {
+	if generation == 0 || generation == f.Generation {
+		return f.asVersion(time.Time{}), true
+	}
+
+	for _, version := range f.Versions {
+		if version.Generation == generation {
+			return version, true
+		}
+	}
+
+	return FileVersion{}, false
+}
+
+// chunkHandles returns the chunk handles of the current and every previous version of the file
+func (f *FileMetadata) chunkHandles() []string {
+	handles := slices.Clone(f.Chunks)
+	for _, version := range f.Versions {
+		handles = append(handles, version.Chunks...)
+	}
+
+	return handles
+}
+
+// chunkFilesize returns the size of the version of the file holding chunkHandle, the compressed size for
+// archived versions, -1 if no version holds it
+func (f *FileMetadata) chunkFilesize(chunkHandle string) int64 {
+	if slices.Contains(f.Chunks, chunkHandle) {
+		return cmp.Or(f.ArchivedSize, f.Filesize)
+	}
+
+	for _, version := range f.Versions {
+		if slices.Contains(version.Chunks, chunkHandle) {
+			return cmp.Or(version.ArchivedSize, version.Filesize)
+		}
+	}
+
+	return -1
+}
+
+// removeChunks removes the chunks from the store, returning the removed chunks. Chunks shared with a clone
+// only lose a reference and are kept for the files still using them
+func (tx *metadataTx) removeChunks(chunkHandles []string) ([]*ChunkMetadata, error) {
+	chunks := make([]*ChunkMetadata, 0, len(chunkHandles))
+	for _, chunkHandle := range chunkHandles {
+		chunk, exists, err := tx.GetChunk(chunkHandle)
+		if err != nil {
+			return chunks, err
+		}
+		if !exists {
+			continue
+		}
+
+		if chunk.shared() {
+			chunk.References--
+			if err := tx.PutChunk(chunk); err != nil {
+				return chunks, err
+			}
+			continue
+		}
+
+		if err := tx.DeleteChunk(chunkHandle); err != nil {
+			return chunks, err
+		}
+		chunks = append(chunks, chunk)
+	}
+
+	return chunks, nil
+}
+
+// AbandonUpload undoes the allocation of an upload that never completed. An overwrite's staged file is dropped,
+// leaving the file it was to replace as it was, and a file the upload created is removed. It returns the chunks
+// of the abandoned upload, whose replicas the caller is responsible for deleting, and whether the file was removed
+func (m *Metadata) AbandonUpload(filename string, generation int64) ([]*ChunkMetadata, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var chunks []*ChunkMetadata
+	removed := false
+	err := m.update(func(tx *metadataTx) error {
+		file, exists, err := tx.GetFile(filename)
+		if err != nil || !exists {
+			return err
+		}
+
+		var abandoned []string
+		switch {
+		case file.Upload != nil && file.Upload.Generation == generation:
+			abandoned = file.Upload.Chunks
+			file.Upload = nil
+			err = tx.PutFile(file)
+		case file.Generation == generation:
+			// overwrites are staged, so the current generation is the upload's only when the upload created the file
+			abandoned = file.Chunks
+			removed = true
+			err = tx.DeleteFile(filename)
+		default:
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		chunks, err = tx.removeChunks(abandoned)
+		return err
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	return chunks, removed, nil
+}
+
+// StagedUploads returns the files with an overwrite staged, mapped to the generation of the upload
+func (m *Metadata) StagedUploads() (map[string]int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	staged := make(map[string]int64)
+	err := m.store.ForEachFile("", func(file *FileMetadata) error {
+		if file.Upload != nil {
+			staged[file.Filename] = file.Upload.Generation
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return staged, nil
+}
+
+// ExpireVersions drops previous file versions replaced more than maxAge ago and returns their
+// chunks, whose replicas the caller is responsible for deleting
+func (m *Metadata) ExpireVersions(maxAge time.Duration) ([]*ChunkMetadata, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cutoff := time.Now().Add(-maxAge)
+	isExpired := func(version FileVersion) bool {
+		return version.ReplacedAt.Before(cutoff)
+	}
+
+	expiring := make([]*FileMetadata, 0)
+	err := m.store.ForEachFile("", func(file *FileMetadata) error {
+		if slices.ContainsFunc(file.Versions, isExpired) {
+			expiring = append(expiring, file)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	removed := make([]*ChunkMetadata, 0)
+	for _, file := range expiring {
+		expiredHandles := make([]string, 0)
+		for _, version := range file.Versions {
+			if isExpired(version) {
+				expiredHandles = append(expiredHandles, version.Chunks...)
+			}
+		}
+
+		file.Versions = slices.DeleteFunc(file.Versions, isExpired)
+		err := m.update(func(tx *metadataTx) error {
+			if err := tx.PutFile(file); err != nil {
+				return err
+			}
+
+			chunks, err := tx.removeChunks(expiredHandles)
+			if err != nil {
+				return err
+			}
+			removed = append(removed, chunks...)
+			return nil
+		})
+		if err != nil {
+			return removed, err
+		}
+	}
+
+	return removed, nil
+}
+
+// startVersionExpiry periodically drops previous file versions older than the configured maximum age, if any
+func (s *Server) startVersionExpiry() {
+	ticker := time.NewTicker(versionExpiryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+		}
+
+		maxAge := s.tunables.Load().VersionMaxAge
+		if maxAge <= 0 {
+			continue
+		}
+
+		chunks, err := s.metadata.ExpireVersions(maxAge)
+		if err != nil {
+			log.Printf("Warning: failed to expire file versions: %v", err)
+		}
+		if len(chunks) > 0 {
+			log.Printf("Expired previous file versions, deleting %d chunks", len(chunks))
+		}
+
+		s.deleteChunks(chunks)
+	}
+}
@@ -0,0 +1,242 @@
+package master
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	pb "github.com/harshvardha/distributed_file_system/proto"
+)
+
+const (
+	// webhookQueueSize is the number of events waiting for delivery to one url before events get dropped
+	webhookQueueSize = 1024
+
+	// webhookTimeout bounds one delivery attempt
+	webhookTimeout = 10 * time.Second
+
+	// webhookMaxAttempts bounds the deliveries of one event, retried after webhookRetryBackoff doubled every attempt
+	webhookMaxAttempts  = 5
+	webhookRetryBackoff = time.Second
+
+	// WebhookSignatureHeader carries the hex HMAC-SHA256 of the request body keyed with WebhookConfig.Secret
+	WebhookSignatureHeader = "X-DFS-Signature"
+)
+
+// Webhook event types, the type field of the JSON posted
+const (
+	WebhookFileCreated         = "file_created"
+	WebhookFileDeleted         = "file_deleted"
+	WebhookFileRenamed         = "file_renamed"
+	WebhookReplicationDegraded = "replication_degraded"
+)
+
+// WebhookConfig configures the urls namespace events are posted to, so external systems, e.g. ETL schedulers
+// or alerting, can react to them without running a Watch stream
+type WebhookConfig struct {
+	URLs     []string // urls every event is posted to as JSON, empty disables webhooks
+	Prefixes []string // only events of files under these prefixes are posted, every file's when empty
+	// Secret signs every request body, the signature is sent in WebhookSignatureHeader. Empty sends no signature
+	Secret []byte
+}
+
+// WebhookEvent is the JSON body posted to webhook urls
+type WebhookEvent struct {
+	Type           string    `json:"type"`
+	Filename       string    `json:"filename"`
+	OldFilename    string    `json:"old_filename,omitempty"` // renamed files
+	Filesize       int64     `json:"filesize,omitempty"`
+	ChunkHandle    string    `json:"chunk_handle,omitempty"`    // chunks with degraded replication
+	Replicas       *int      `json:"replicas,omitempty"`        // replicas left of a chunk with degraded replication, possibly 0
+	TargetReplicas int       `json:"target_replicas,omitempty"` // replicas the chunk's file asks for
+	Timestamp      time.Time `json:"timestamp"`
+}
+
+// webhookNotifier posts events to the configured urls. Each url has its own queue, so a slow or
+// unreachable url delays neither the master nor the other urls
+type webhookNotifier struct {
+	server *Server
+	config WebhookConfig
+	http   *http.Client
+	queues map[string]chan *WebhookEvent // key: url
+}
+
+// newWebhookNotifier creates a notifier posting events to the urls of config, nil when there are none
+func newWebhookNotifier(s *Server, config WebhookConfig) *webhookNotifier {
+	if len(config.URLs) == 0 {
+		return nil
+	}
+
+	n := &webhookNotifier{
+		server: s,
+		config: config,
+		http:   &http.Client{Timeout: webhookTimeout},
+		queues: make(map[string]chan *WebhookEvent),
+	}
+	for _, url := range config.URLs {
+		n.queues[url] = make(chan *WebhookEvent, webhookQueueSize)
+	}
+
+	return n
+}
+
+// start follows namespace events and delivers them until the master stops
+func (n *webhookNotifier) start() {
+	if n == nil {
+		return
+	}
+
+	log.Printf("Posting file events to %d webhook urls", len(n.queues))
+
+	id, events := n.server.events.Subscribe("")
+	n.server.goBackground(func() {
+		<-n.server.done
+		n.server.events.Unsubscribe(id)
+	})
+	n.server.goBackground(func() {
+		for event := range events {
+			n.notify(fileWebhookEvent(event))
+		}
+	})
+
+	for url, queue := range n.queues {
+		n.server.goBackground(func() { n.deliver(url, queue) })
+	}
+}
+
+// fileWebhookEvent converts a namespace event to its webhook event
+func fileWebhookEvent(event *pb.FileEvent) *WebhookEvent {
+	eventType := WebhookFileCreated
+	switch event.Type {
+	case pb.FileEventType_FILE_EVENT_DELETED:
+		eventType = WebhookFileDeleted
+	case pb.FileEventType_FILE_EVENT_RENAMED:
+		eventType = WebhookFileRenamed
+	}
+
+	return &WebhookEvent{
+		Type:        eventType,
+		Filename:    event.Filename,
+		OldFilename: event.OldFilename,
+		Filesize:    event.Filesize,
+		Timestamp:   time.Unix(0, event.Timestamp).UTC(),
+	}
+}
+
+// replicationDegraded posts that a chunk of filename has fewer replicas than its file asks for
+func (n *webhookNotifier) replicationDegraded(filename, chunkHandle string, replicas, target int) {
+	if n == nil {
+		return
+	}
+
+	n.notify(&WebhookEvent{
+		Type:           WebhookReplicationDegraded,
+		Filename:       filename,
+		ChunkHandle:    chunkHandle,
+		Replicas:       &replicas,
+		TargetReplicas: target,
+		Timestamp:      time.Now().UTC(),
+	})
+}
+
+// notify queues an event for every url, dropping it for urls whose queue is full
+func (n *webhookNotifier) notify(event *WebhookEvent) {
+	if !n.selected(event.Filename) && (event.OldFilename == "" || !n.selected(event.OldFilename)) {
+		return
+	}
+
+	for url, queue := range n.queues {
+		select {
+		case queue <- event:
+		default:
+			log.Printf("Warning: dropping %s webhook event for %s, %s is too slow", event.Type, event.Filename, url)
+		}
+	}
+}
+
+// selected reports whether events of a file are posted
+func (n *webhookNotifier) selected(filename string) bool {
+	if len(n.config.Prefixes) == 0 {
+		return true
+	}
+
+	return slices.ContainsFunc(n.config.Prefixes, func(prefix string) bool {
+		return strings.HasPrefix(filename, prefix)
+	})
+}
+
+// deliver posts the events queued for url in order until the master stops, retrying failed posts with backoff
+func (n *webhookNotifier) deliver(url string, queue <-chan *WebhookEvent) {
+	for {
+		var event *WebhookEvent
+		select {
+		case <-n.server.done:
+			return
+		case event = <-queue:
+		}
+
+		body, err := json.Marshal(event)
+		if err != nil {
+			log.Printf("Warning: failed to encode %s webhook event for %s: %v", event.Type, event.Filename, err)
+			continue
+		}
+
+		backoff := webhookRetryBackoff
+		for attempt := 1; ; attempt++ {
+			retry, err := n.post(url, body)
+			if err == nil {
+				break
+			}
+			if !retry || attempt == webhookMaxAttempts {
+				log.Printf("Warning: giving up posting %s webhook event for %s to %s after %d attempts: %v", event.Type, event.Filename, url, attempt, err)
+				break
+			}
+
+			log.Printf("Warning: failed to post %s webhook event for %s to %s, retrying in %s: %v", event.Type, event.Filename, url, backoff, err)
+			select {
+			case <-n.server.done:
+				return
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+	}
+}
+
+// post sends one event to url, reporting whether a failed post is worth retrying
+func (n *webhookNotifier) post(url string, body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(n.config.Secret) > 0 {
+		mac := hmac.New(sha256.New, n.config.Secret)
+		mac.Write(body)
+		req.Header.Set(WebhookSignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	response, err := n.http.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, response.Body)
+
+	if response.StatusCode/100 == 2 {
+		return false, nil
+	}
+
+	// the receiver rejecting the event won't change its mind, unless it is overloaded or failing
+	retry := response.StatusCode >= 500 || response.StatusCode == http.StatusTooManyRequests
+	return retry, fmt.Errorf("%s answered %s", url, response.Status)
+}
@@ -0,0 +1,594 @@
+// Package mirroring copies files from one cluster to another as they change, for the master's
+// geo-replication and the dfsadmin mirror agent alike
+package mirroring
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/harshvardha/distributed_file_system/client"
+	pb "github.com/harshvardha/distributed_file_system/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// SourceGenerationTag is set on every copy written to the destination cluster, holding the generation of
+// the file it was copied from. It tells mirrored copies apart from files written on the destination, and
+// files carrying it are never mirrored onward, so two clusters can mirror each other
+const SourceGenerationTag = "dfs.geo-replication.source-generation"
+
+const (
+	// DefaultScanInterval is how often every mirrored file is compared with the destination when
+	// Config.ScanInterval is 0, to pick up changes whose events were missed
+	DefaultScanInterval = 5 * time.Minute
+
+	// DefaultWorkers is the number of files mirrored at once when Config.Workers is 0
+	DefaultWorkers = 2
+
+	// retryBackoff is the wait before mirroring a file again after a failure, doubled up to maxRetryBackoff
+	retryBackoff    = 5 * time.Second
+	maxRetryBackoff = 5 * time.Minute
+
+	// resubscribeDelay is the wait before watching the source again after the event stream broke
+	resubscribeDelay = 5 * time.Second
+
+	// pollInterval bounds how long idle workers wait before looking for due retries
+	pollInterval = time.Second
+)
+
+// errBusy defers mirroring a file the source is still writing, completing an upload publishes no event
+var errBusy = errors.New("file is being written")
+
+// ConflictPolicy decides what happens to a destination file that was written on the destination cluster
+// instead of by mirroring, when the source file with the same name changes or is deleted
+type ConflictPolicy string
+
+const (
+	// ConflictKeepRemote leaves files written on the destination cluster alone
+	ConflictKeepRemote ConflictPolicy = "keep-remote"
+
+	// ConflictSourceWins replaces or deletes them to match the source cluster
+	ConflictSourceWins ConflictPolicy = "source-wins"
+
+	// ConflictNewerWins replaces or deletes them when the source change is newer than the destination file
+	ConflictNewerWins ConflictPolicy = "newer-wins"
+)
+
+// ParseConflictPolicy converts a flag value to a ConflictPolicy
+func ParseConflictPolicy(value string) (ConflictPolicy, error) {
+	switch policy := ConflictPolicy(value); policy {
+	case ConflictKeepRemote, ConflictSourceWins, ConflictNewerWins:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown conflict policy %q, expected keep-remote, source-wins or newer-wins", value)
+	}
+}
+
+// Config holds the settings of mirroring one cluster to another
+type Config struct {
+	Prefixes       []string       // only files under these prefixes are mirrored, every file when empty
+	ConflictPolicy ConflictPolicy // ConflictKeepRemote when empty
+	ScanInterval   time.Duration  // how often all files are compared to catch missed changes, 0 for DefaultScanInterval
+	Workers        int            // files mirrored at once, 0 for DefaultWorkers
+
+	// Busy reports whether the source is still writing a file, which is then retried shortly. Nil when unknown
+	Busy func(filename string) bool
+}
+
+// Status holds the lag and counters of mirroring since Run started
+type Status struct {
+	Prefixes       []string
+	ConflictPolicy ConflictPolicy
+	PendingFiles   int
+	Lag            time.Duration // age of the oldest change not mirrored yet, 0 when the destination is up to date
+	MirroredFiles  int64
+	MirroredBytes  int64
+	DeletedFiles   int64
+	Conflicts      int64
+	Failures       int64
+	LastMirrored   time.Time // zero if nothing was mirrored yet
+	LastError      string
+}
+
+// change is a file changed on the source and not mirrored yet
+type change struct {
+	since    time.Time // oldest change not mirrored yet
+	attempts int       // failed attempts so far
+	retryAt  time.Time // when the file is tried again after a failure
+	running  bool      // a worker is mirroring the file
+	dirty    bool      // the file changed again while it was being mirrored
+}
+
+// Mirror copies files changed on a source cluster to a destination cluster as the source's namespace
+// events report them. Whenever it subscribes to the events, and every scan interval, it compares both
+// clusters in full, so changes made while it wasn't watching are caught up. Failed files are retried
+// with backoff
+type Mirror struct {
+	source      *client.Client
+	destination *client.Client
+	config      Config
+
+	mu       sync.Mutex
+	wake     chan struct{}
+	rescan   chan struct{}
+	pending  map[string]*change // key: filename
+	mirrored map[string]int64   // key: filename, value: source generation mirrored last
+	status   Status
+}
+
+func New(source, destination *client.Client, config Config) *Mirror {
+	if config.ConflictPolicy == "" {
+		config.ConflictPolicy = ConflictKeepRemote
+	}
+	if config.ScanInterval <= 0 {
+		config.ScanInterval = DefaultScanInterval
+	}
+	if config.Workers <= 0 {
+		config.Workers = DefaultWorkers
+	}
+
+	return &Mirror{
+		source:      source,
+		destination: destination,
+		config:      config,
+		wake:        make(chan struct{}, 1),
+		rescan:      make(chan struct{}, 1),
+		pending:     make(map[string]*change),
+		mirrored:    make(map[string]int64),
+		status:      Status{Prefixes: config.Prefixes, ConflictPolicy: config.ConflictPolicy},
+	}
+}
+
+// Run mirrors changes until ctx is cancelled, returning once every copy in flight was abandoned
+func (m *Mirror) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for range m.config.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.work(ctx)
+		}()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		m.scanPeriodically(ctx)
+	}()
+
+	m.watch(ctx)
+	wg.Wait()
+}
+
+// DescribePrefixes returns the mirrored prefixes for log messages
+func (m *Mirror) DescribePrefixes() string {
+	if len(m.config.Prefixes) == 0 {
+		return "all files"
+	}
+
+	return strings.Join(m.config.Prefixes, ", ")
+}
+
+// selected reports whether a file is under one of the mirrored prefixes
+func (m *Mirror) selected(filename string) bool {
+	if len(m.config.Prefixes) == 0 {
+		return true
+	}
+
+	return slices.ContainsFunc(m.config.Prefixes, func(prefix string) bool {
+		return strings.HasPrefix(filename, prefix)
+	})
+}
+
+// watch subscribes to the source's namespace events, subscribing again whenever the stream breaks
+func (m *Mirror) watch(ctx context.Context) {
+	prefix := ""
+	if len(m.config.Prefixes) == 1 {
+		prefix = m.config.Prefixes[0]
+	}
+
+	for ctx.Err() == nil {
+		subscribed := time.Now()
+
+		// scanning once the subscription is up, so no change falls between the scan and the first event
+		err := m.source.WatchSubscribed(ctx, prefix, m.requestScan, func(event *pb.FileEvent) {
+			changedAt := time.Unix(0, event.Timestamp)
+			if event.OldFilename != "" {
+				m.markPending(event.OldFilename, changedAt)
+			}
+			m.markPending(event.Filename, changedAt)
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			err = errors.New("event stream ended")
+		}
+		m.recordError(fmt.Errorf("watching the source failed after %s: %v", time.Since(subscribed).Round(time.Second), err))
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(resubscribeDelay):
+		}
+	}
+}
+
+// requestScan has the scanner compare both clusters as soon as it can
+func (m *Mirror) requestScan() {
+	select {
+	case m.rescan <- struct{}{}:
+	default:
+	}
+}
+
+// scanPeriodically compares both clusters whenever a subscription comes up and every scan interval
+func (m *Mirror) scanPeriodically(ctx context.Context) {
+	ticker := time.NewTicker(m.config.ScanInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-m.rescan:
+		case <-ticker.C:
+		}
+
+		if err := m.scan(ctx); err != nil && ctx.Err() == nil {
+			m.recordError(fmt.Errorf("failed to compare clusters: %v", err))
+		}
+	}
+}
+
+// scan queues every source file whose generation differs from the one mirrored last, and every
+// destination copy whose source is gone, catching up on events missed while the mirror wasn't watching
+func (m *Mirror) scan(ctx context.Context) error {
+	now := time.Now()
+	onSource := make(map[string]bool)
+	err := m.source.ListFilesStream(client.ListOptions{}, func(file *pb.FileInfo) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		onSource[file.Filename] = true
+		if !m.selected(file.Filename) || file.SymlinkTarget != "" || file.Tags[SourceGenerationTag] != "" {
+			return nil
+		}
+
+		m.mu.Lock()
+		mirrored := m.mirrored[file.Filename] == file.Generation
+		m.mu.Unlock()
+		if !mirrored {
+			m.markPending(file.Filename, now)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return m.destination.ListFilesStream(client.ListOptions{}, func(file *pb.FileInfo) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if file.Tags[SourceGenerationTag] != "" && !onSource[file.Filename] {
+			m.markPending(file.Filename, now)
+		}
+		return nil
+	})
+}
+
+// markPending queues a file changed at changedAt for mirroring
+func (m *Mirror) markPending(filename string, changedAt time.Time) {
+	if filename == "" || !m.selected(filename) {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	pending, exists := m.pending[filename]
+	switch {
+	case !exists:
+		m.pending[filename] = &change{since: changedAt}
+	case pending.running:
+		pending.dirty = true
+	default:
+		// a new change is tried right away, even if the last attempt failed
+		pending.retryAt = time.Time{}
+	}
+
+	select {
+	case m.wake <- struct{}{}:
+	default:
+	}
+}
+
+// work mirrors queued files until ctx is cancelled
+func (m *Mirror) work(ctx context.Context) {
+	for {
+		filename, pending := m.next()
+		if filename == "" {
+			select {
+			case <-ctx.Done():
+				return
+			case <-m.wake:
+			case <-time.After(pollInterval):
+			}
+			continue
+		}
+
+		err := m.mirror(ctx, filename, pending)
+		if ctx.Err() != nil {
+			// an interrupted copy isn't a failure, the file is still pending
+			m.mu.Lock()
+			pending.running = false
+			m.mu.Unlock()
+			return
+		}
+		m.finish(filename, pending, err)
+	}
+}
+
+// next claims the queued file with the oldest change that is due, returning an empty name if there is none
+func (m *Mirror) next() (string, *change) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	var next string
+	var oldest *change
+	for filename, pending := range m.pending {
+		if pending.running || now.Before(pending.retryAt) {
+			continue
+		}
+		if oldest == nil || pending.since.Before(oldest.since) {
+			next, oldest = filename, pending
+		}
+	}
+	if oldest != nil {
+		oldest.running = true
+	}
+
+	return next, oldest
+}
+
+// finish records the outcome of mirroring a file, keeping it queued when it failed or changed meanwhile
+func (m *Mirror) finish(filename string, pending *change, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	pending.running = false
+	if errors.Is(err, errBusy) {
+		pending.retryAt = time.Now().Add(pollInterval)
+		return
+	}
+	if err != nil {
+		pending.attempts++
+		backoff := min(retryBackoff<<min(pending.attempts-1, 16), maxRetryBackoff)
+		pending.retryAt = time.Now().Add(backoff)
+		log.Printf("Warning: failed to mirror %s, retrying in %s: %v", filename, backoff, err)
+
+		m.status.Failures++
+		m.status.LastError = fmt.Sprintf("%s: %v", filename, err)
+		return
+	}
+
+	m.status.LastMirrored = time.Now()
+	if pending.dirty {
+		pending.dirty = false
+		pending.attempts = 0
+		return
+	}
+	delete(m.pending, filename)
+}
+
+// recordMirrored remembers the source generation mirrored last, -1 for a deleted file
+func (m *Mirror) recordMirrored(filename string, generation int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if generation < 0 {
+		delete(m.mirrored, filename)
+		return
+	}
+	m.mirrored[filename] = generation
+}
+
+// recordConflict counts a destination file left alone because it was written on the destination cluster
+func (m *Mirror) recordConflict(filename, reason string) {
+	log.Printf("Mirroring conflict on %s: %s, keeping the destination's file", filename, reason)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.status.Conflicts++
+}
+
+// recordError keeps err as the last error of the status
+func (m *Mirror) recordError(err error) {
+	log.Printf("Warning: %v", err)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.status.LastError = err.Error()
+}
+
+// mirror brings the destination's copy of a file in line with the source
+func (m *Mirror) mirror(ctx context.Context, filename string, pending *change) error {
+	if m.config.Busy != nil && m.config.Busy(filename) {
+		return errBusy
+	}
+
+	sourceInfo, err := m.source.GetFileInfo(filename)
+	if err != nil && !errors.Is(err, client.ErrFileNotFound) {
+		return err
+	}
+	source := sourceInfo.GetFile()
+	if source != nil && source.Filename != filename {
+		// symlinks aren't mirrored, the files they point at are
+		return nil
+	}
+	if source != nil && source.Tags[SourceGenerationTag] != "" {
+		// the file is a copy received from another cluster
+		return nil
+	}
+
+	destinationInfo, err := m.destination.GetFileInfo(filename)
+	if err != nil && !errors.Is(err, client.ErrFileNotFound) {
+		return err
+	}
+	destination := destinationInfo.GetFile()
+
+	if source == nil {
+		return m.mirrorDelete(filename, destination, pending.since)
+	}
+
+	if destination != nil && destination.Tags[SourceGenerationTag] == strconv.FormatInt(source.Generation, 10) {
+		m.recordMirrored(filename, source.Generation)
+		return nil
+	}
+	if destination != nil && destination.Tags[SourceGenerationTag] == "" && !m.overrides(destination, time.Unix(source.ModifiedAt, 0)) {
+		m.recordConflict(filename, "written on the destination cluster")
+		m.recordMirrored(filename, source.Generation)
+		return nil
+	}
+
+	return m.mirrorFile(ctx, source, destination)
+}
+
+// overrides reports whether a source change made at changedAt replaces a file written on the destination
+func (m *Mirror) overrides(destination *pb.FileInfo, changedAt time.Time) bool {
+	switch m.config.ConflictPolicy {
+	case ConflictSourceWins:
+		return true
+	case ConflictNewerWins:
+		return changedAt.Unix() > destination.ModifiedAt
+	default:
+		return false
+	}
+}
+
+// mirrorFile copies the generation of the source file over its destination copy, conditional on the copy
+// being unchanged since it was looked at. The file is streamed from one cluster to the other without being
+// buffered, and the copy is abandoned when ctx is cancelled
+func (m *Mirror) mirrorFile(ctx context.Context, source, destination *pb.FileInfo) error {
+	tags := make(map[string]string, len(source.Tags)+1)
+	for key, value := range source.Tags {
+		tags[key] = value
+	}
+	tags[SourceGenerationTag] = strconv.FormatInt(source.Generation, 10)
+
+	options := client.UploadOptions{
+		IfGenerationMatch: new(int64),
+		Immutable:         source.Immutable,
+		Tags:              tags,
+		Mode:              source.Mode,
+	}
+	if destination != nil {
+		*options.IfGenerationMatch = destination.Generation
+	}
+	if source.RetainUntil != 0 {
+		options.Retention = time.Until(time.Unix(source.RetainUntil, 0))
+		if options.Retention <= 0 {
+			options.Immutable = false
+		}
+	}
+
+	reader, writer := io.Pipe()
+	downloaded := make(chan struct{})
+	go func() {
+		defer close(downloaded)
+		writer.CloseWithError(m.source.DownloadVersion(source.Filename, source.Generation, writer))
+	}()
+	stop := context.AfterFunc(ctx, func() {
+		reader.CloseWithError(ctx.Err())
+	})
+
+	err := m.destination.UploadReaderWithOptions(reader, source.Filename, options)
+	stop()
+	reader.CloseWithError(err)
+	<-downloaded
+
+	if status.Code(err) == codes.PermissionDenied {
+		m.recordConflict(source.Filename, "the destination's file is immutable")
+		m.recordMirrored(source.Filename, source.Generation)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Mirrored %s generation %d, %d bytes", source.Filename, source.Generation, source.Filesize)
+	m.recordMirrored(source.Filename, source.Generation)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.status.MirroredFiles++
+	m.status.MirroredBytes += source.Filesize
+
+	return nil
+}
+
+// mirrorDelete deletes the destination copy of a file deleted on the source, conditional on it being unchanged
+func (m *Mirror) mirrorDelete(filename string, destination *pb.FileInfo, deletedAt time.Time) error {
+	if destination == nil {
+		m.recordMirrored(filename, -1)
+		return nil
+	}
+	if destination.Tags[SourceGenerationTag] == "" && !m.overrides(destination, deletedAt) {
+		m.recordConflict(filename, "deleted on the source but written on the destination cluster")
+		m.recordMirrored(filename, -1)
+		return nil
+	}
+
+	err := m.destination.DeleteFileIfGeneration(filename, destination.Generation)
+	if errors.Is(err, client.ErrFileNotFound) {
+		err = nil
+	}
+	if status.Code(err) == codes.PermissionDenied {
+		m.recordConflict(filename, "the destination's file is immutable")
+		err = nil
+	}
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Mirrored deletion of %s", filename)
+	m.recordMirrored(filename, -1)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.status.DeletedFiles++
+
+	return nil
+}
+
+// Status returns the lag and counters of mirroring
+func (m *Mirror) Status() Status {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	report := m.status
+	report.PendingFiles = len(m.pending)
+
+	var oldest time.Time
+	for _, pending := range m.pending {
+		if oldest.IsZero() || pending.since.Before(oldest) {
+			oldest = pending.since
+		}
+	}
+	if !oldest.IsZero() {
+		report.Lag = time.Since(oldest)
+	}
+
+	return report
+}
@@ -21,30 +21,6143 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type ListSortKey int32
+
+const (
+	ListSortKey_LIST_SORT_NAME     ListSortKey = 0
+	ListSortKey_LIST_SORT_SIZE     ListSortKey = 1
+	ListSortKey_LIST_SORT_MODIFIED ListSortKey = 2
+)
+
+// Enum value maps for ListSortKey.
+var (
+	ListSortKey_name = map[int32]string{
+		0: "LIST_SORT_NAME",
+		1: "LIST_SORT_SIZE",
+		2: "LIST_SORT_MODIFIED",
+	}
+	ListSortKey_value = map[string]int32{
+		"LIST_SORT_NAME":     0,
+		"LIST_SORT_SIZE":     1,
+		"LIST_SORT_MODIFIED": 2,
+	}
+)
+
+func (x ListSortKey) Enum() *ListSortKey {
+	p := new(ListSortKey)
+	*p = x
+	return p
+}
+
+func (x ListSortKey) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ListSortKey) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_dfs_proto_enumTypes[0].Descriptor()
+}
+
+func (ListSortKey) Type() protoreflect.EnumType {
+	return &file_proto_dfs_proto_enumTypes[0]
+}
+
+func (x ListSortKey) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ListSortKey.Descriptor instead.
+func (ListSortKey) EnumDescriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{0}
+}
+
+type FileEventType int32
+
+const (
+	FileEventType_FILE_EVENT_UNSPECIFIED FileEventType = 0
+	FileEventType_FILE_EVENT_CREATED     FileEventType = 1
+	FileEventType_FILE_EVENT_DELETED     FileEventType = 2
+	FileEventType_FILE_EVENT_RENAMED     FileEventType = 3
+)
+
+// Enum value maps for FileEventType.
+var (
+	FileEventType_name = map[int32]string{
+		0: "FILE_EVENT_UNSPECIFIED",
+		1: "FILE_EVENT_CREATED",
+		2: "FILE_EVENT_DELETED",
+		3: "FILE_EVENT_RENAMED",
+	}
+	FileEventType_value = map[string]int32{
+		"FILE_EVENT_UNSPECIFIED": 0,
+		"FILE_EVENT_CREATED":     1,
+		"FILE_EVENT_DELETED":     2,
+		"FILE_EVENT_RENAMED":     3,
+	}
+)
+
+func (x FileEventType) Enum() *FileEventType {
+	p := new(FileEventType)
+	*p = x
+	return p
+}
+
+func (x FileEventType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (FileEventType) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_dfs_proto_enumTypes[1].Descriptor()
+}
+
+func (FileEventType) Type() protoreflect.EnumType {
+	return &file_proto_dfs_proto_enumTypes[1]
+}
+
+func (x FileEventType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use FileEventType.Descriptor instead.
+func (FileEventType) EnumDescriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{1}
+}
+
 // Messages for Master Service
 type UploadFileRequest struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Filename          string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	Filesize          int64                  `protobuf:"varint,2,opt,name=filesize,proto3" json:"filesize,omitempty"`
+	Hints             *PlacementHints        `protobuf:"bytes,3,opt,name=hints,proto3" json:"hints,omitempty"`
+	IfGenerationMatch *int64                 `protobuf:"varint,4,opt,name=if_generation_match,json=ifGenerationMatch,proto3,oneof" json:"if_generation_match,omitempty"`               // only overwrite this generation of the file, 0 if the file must not exist
+	Immutable         bool                   `protobuf:"varint,5,opt,name=immutable,proto3" json:"immutable,omitempty"`                                                                // once the upload completes, reject deleting, renaming or overwriting the file
+	RetentionSeconds  int64                  `protobuf:"varint,6,opt,name=retention_seconds,json=retentionSeconds,proto3" json:"retention_seconds,omitempty"`                          // how long an immutable file stays immutable, 0 forever
+	Tags              map[string]string      `protobuf:"bytes,7,rep,name=tags,proto3" json:"tags,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // user defined key value tags, e.g. dataset=2024-06
+	Exclusive         bool                   `protobuf:"varint,8,opt,name=exclusive,proto3" json:"exclusive,omitempty"`                                                                // fail with AlreadyExists instead of overwriting an existing file
+	Mode              uint32                 `protobuf:"varint,9,opt,name=mode,proto3" json:"mode,omitempty"`                                                                          // permission bits of a new file, 0 for 0644, overwritten files keep theirs
+	Data              []byte                 `protobuf:"bytes,10,opt,name=data,proto3" json:"data,omitempty"`                                                                          // contents of files of at most 64KiB, which the master may store inline instead of in chunks
+	StorageTier       string                 `protobuf:"bytes,11,opt,name=storage_tier,json=storageTier,proto3" json:"storage_tier,omitempty"`                                         // place the file's chunks on chunk servers of this tier, e.g. ssd, empty for any
+	StorageClass      string                 `protobuf:"bytes,12,opt,name=storage_class,json=storageClass,proto3" json:"storage_class,omitempty"`                                      // standard, reduced-redundancy or archive, empty for standard
+	Streaming         bool                   `protobuf:"varint,13,opt,name=streaming,proto3" json:"streaming,omitempty"`                                                               // the size isn't known up front, chunks are added with AllocateChunk and the size set by CompleteUpload
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *UploadFileRequest) Reset() {
+	*x = UploadFileRequest{}
+	mi := &file_proto_dfs_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadFileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadFileRequest) ProtoMessage() {}
+
+func (x *UploadFileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadFileRequest.ProtoReflect.Descriptor instead.
+func (*UploadFileRequest) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *UploadFileRequest) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *UploadFileRequest) GetFilesize() int64 {
+	if x != nil {
+		return x.Filesize
+	}
+	return 0
+}
+
+func (x *UploadFileRequest) GetHints() *PlacementHints {
+	if x != nil {
+		return x.Hints
+	}
+	return nil
+}
+
+func (x *UploadFileRequest) GetIfGenerationMatch() int64 {
+	if x != nil && x.IfGenerationMatch != nil {
+		return *x.IfGenerationMatch
+	}
+	return 0
+}
+
+func (x *UploadFileRequest) GetImmutable() bool {
+	if x != nil {
+		return x.Immutable
+	}
+	return false
+}
+
+func (x *UploadFileRequest) GetRetentionSeconds() int64 {
+	if x != nil {
+		return x.RetentionSeconds
+	}
+	return 0
+}
+
+func (x *UploadFileRequest) GetTags() map[string]string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *UploadFileRequest) GetExclusive() bool {
+	if x != nil {
+		return x.Exclusive
+	}
+	return false
+}
+
+func (x *UploadFileRequest) GetMode() uint32 {
+	if x != nil {
+		return x.Mode
+	}
+	return 0
+}
+
+func (x *UploadFileRequest) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *UploadFileRequest) GetStorageTier() string {
+	if x != nil {
+		return x.StorageTier
+	}
+	return ""
+}
+
+func (x *UploadFileRequest) GetStorageClass() string {
+	if x != nil {
+		return x.StorageClass
+	}
+	return ""
+}
+
+func (x *UploadFileRequest) GetStreaming() bool {
+	if x != nil {
+		return x.Streaming
+	}
+	return false
+}
+
+// PlacementHints are preferences for where the replicas of a new file go. They are best effort,
+// placement falls back to other servers when no server satisfies them
+type PlacementHints struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	PreferredZone    string                 `protobuf:"bytes,1,opt,name=preferred_zone,json=preferredZone,proto3" json:"preferred_zone,omitempty"`            // place replicas on chunk servers in this zone first
+	LocalHost        string                 `protobuf:"bytes,2,opt,name=local_host,json=localHost,proto3" json:"local_host,omitempty"`                        // put the first replica on a chunk server running on this host
+	AntiAffinityFile string                 `protobuf:"bytes,3,opt,name=anti_affinity_file,json=antiAffinityFile,proto3" json:"anti_affinity_file,omitempty"` // avoid chunk servers holding chunks of this file
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *PlacementHints) Reset() {
+	*x = PlacementHints{}
+	mi := &file_proto_dfs_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlacementHints) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlacementHints) ProtoMessage() {}
+
+func (x *PlacementHints) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlacementHints.ProtoReflect.Descriptor instead.
+func (*PlacementHints) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *PlacementHints) GetPreferredZone() string {
+	if x != nil {
+		return x.PreferredZone
+	}
+	return ""
+}
+
+func (x *PlacementHints) GetLocalHost() string {
+	if x != nil {
+		return x.LocalHost
+	}
+	return ""
+}
+
+func (x *PlacementHints) GetAntiAffinityFile() string {
+	if x != nil {
+		return x.AntiAffinityFile
+	}
+	return ""
+}
+
+type ChunkLocation struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	ChunkHandle          string                 `protobuf:"bytes,1,opt,name=chunk_handle,json=chunkHandle,proto3" json:"chunk_handle,omitempty"`
+	ChunkServerAddresses []string               `protobuf:"bytes,2,rep,name=chunk_server_addresses,json=chunkServerAddresses,proto3" json:"chunk_server_addresses,omitempty"`
+	ChunkIndex           int32                  `protobuf:"varint,3,opt,name=chunk_index,json=chunkIndex,proto3" json:"chunk_index,omitempty"`
+	ChunkVersion         int32                  `protobuf:"varint,4,opt,name=chunk_version,json=chunkVersion,proto3" json:"chunk_version,omitempty"`
+	PrimaryAddress       string                 `protobuf:"bytes,5,opt,name=primary_address,json=primaryAddress,proto3" json:"primary_address,omitempty"`    // replica holding the lease to order writes to the chunk, the other addresses are secondaries
+	LeaseExpiresAt       int64                  `protobuf:"varint,6,opt,name=lease_expires_at,json=leaseExpiresAt,proto3" json:"lease_expires_at,omitempty"` // unix time in nanoseconds the primary's lease expires
+	AccessToken          string                 `protobuf:"bytes,7,opt,name=access_token,json=accessToken,proto3" json:"access_token,omitempty"`             // signed by the master, lets the holder write the chunk for uploads and appends, read it otherwise, until it expires
+	LeaseToken           string                 `protobuf:"bytes,8,opt,name=lease_token,json=leaseToken,proto3" json:"lease_token,omitempty"`                // signed by the master, proves to the primary it holds the lease on this chunk version
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *ChunkLocation) Reset() {
+	*x = ChunkLocation{}
+	mi := &file_proto_dfs_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChunkLocation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChunkLocation) ProtoMessage() {}
+
+func (x *ChunkLocation) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChunkLocation.ProtoReflect.Descriptor instead.
+func (*ChunkLocation) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ChunkLocation) GetChunkHandle() string {
+	if x != nil {
+		return x.ChunkHandle
+	}
+	return ""
+}
+
+func (x *ChunkLocation) GetChunkServerAddresses() []string {
+	if x != nil {
+		return x.ChunkServerAddresses
+	}
+	return nil
+}
+
+func (x *ChunkLocation) GetChunkIndex() int32 {
+	if x != nil {
+		return x.ChunkIndex
+	}
+	return 0
+}
+
+func (x *ChunkLocation) GetChunkVersion() int32 {
+	if x != nil {
+		return x.ChunkVersion
+	}
+	return 0
+}
+
+func (x *ChunkLocation) GetPrimaryAddress() string {
+	if x != nil {
+		return x.PrimaryAddress
+	}
+	return ""
+}
+
+func (x *ChunkLocation) GetLeaseExpiresAt() int64 {
+	if x != nil {
+		return x.LeaseExpiresAt
+	}
+	return 0
+}
+
+func (x *ChunkLocation) GetAccessToken() string {
+	if x != nil {
+		return x.AccessToken
+	}
+	return ""
+}
+
+func (x *ChunkLocation) GetLeaseToken() string {
+	if x != nil {
+		return x.LeaseToken
+	}
+	return ""
+}
+
+type UploadFileResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ChunkLocations []*ChunkLocation       `protobuf:"bytes,1,rep,name=chunk_locations,json=chunkLocations,proto3" json:"chunk_locations,omitempty"`
+	UploadId       string                 `protobuf:"bytes,2,opt,name=upload_id,json=uploadId,proto3" json:"upload_id,omitempty"`     // passed to CompleteUpload
+	Generation     int64                  `protobuf:"varint,3,opt,name=generation,proto3" json:"generation,omitempty"`                // generation of the uploaded file
+	ExpiresAt      int64                  `protobuf:"varint,4,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"` // unix time in nanoseconds the upload session expires and the file is removed, unless renewed
+	Inline         bool                   `protobuf:"varint,5,opt,name=inline,proto3" json:"inline,omitempty"`                        // the data sent with the request was stored in the metadata, there are no chunks to write
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *UploadFileResponse) Reset() {
+	*x = UploadFileResponse{}
+	mi := &file_proto_dfs_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadFileResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadFileResponse) ProtoMessage() {}
+
+func (x *UploadFileResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadFileResponse.ProtoReflect.Descriptor instead.
+func (*UploadFileResponse) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *UploadFileResponse) GetChunkLocations() []*ChunkLocation {
+	if x != nil {
+		return x.ChunkLocations
+	}
+	return nil
+}
+
+func (x *UploadFileResponse) GetUploadId() string {
+	if x != nil {
+		return x.UploadId
+	}
+	return ""
+}
+
+func (x *UploadFileResponse) GetGeneration() int64 {
+	if x != nil {
+		return x.Generation
+	}
+	return 0
+}
+
+func (x *UploadFileResponse) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+func (x *UploadFileResponse) GetInline() bool {
+	if x != nil {
+		return x.Inline
+	}
+	return false
+}
+
+type CompleteUploadRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Filename      string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	UploadId      string                 `protobuf:"bytes,2,opt,name=upload_id,json=uploadId,proto3" json:"upload_id,omitempty"`
+	Failed        bool                   `protobuf:"varint,3,opt,name=failed,proto3" json:"failed,omitempty"`           // the client gave up on the upload
+	Filesize      *int64                 `protobuf:"varint,4,opt,name=filesize,proto3,oneof" json:"filesize,omitempty"` // final size of a streaming upload, required for those
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CompleteUploadRequest) Reset() {
+	*x = CompleteUploadRequest{}
+	mi := &file_proto_dfs_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompleteUploadRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompleteUploadRequest) ProtoMessage() {}
+
+func (x *CompleteUploadRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompleteUploadRequest.ProtoReflect.Descriptor instead.
+func (*CompleteUploadRequest) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CompleteUploadRequest) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *CompleteUploadRequest) GetUploadId() string {
+	if x != nil {
+		return x.UploadId
+	}
+	return ""
+}
+
+func (x *CompleteUploadRequest) GetFailed() bool {
+	if x != nil {
+		return x.Failed
+	}
+	return false
+}
+
+func (x *CompleteUploadRequest) GetFilesize() int64 {
+	if x != nil && x.Filesize != nil {
+		return *x.Filesize
+	}
+	return 0
+}
+
+type CompleteUploadResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CompleteUploadResponse) Reset() {
+	*x = CompleteUploadResponse{}
+	mi := &file_proto_dfs_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompleteUploadResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompleteUploadResponse) ProtoMessage() {}
+
+func (x *CompleteUploadResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompleteUploadResponse.ProtoReflect.Descriptor instead.
+func (*CompleteUploadResponse) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *CompleteUploadResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+type RenewUploadRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Filename      string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	UploadId      string                 `protobuf:"bytes,2,opt,name=upload_id,json=uploadId,proto3" json:"upload_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RenewUploadRequest) Reset() {
+	*x = RenewUploadRequest{}
+	mi := &file_proto_dfs_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RenewUploadRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenewUploadRequest) ProtoMessage() {}
+
+func (x *RenewUploadRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenewUploadRequest.ProtoReflect.Descriptor instead.
+func (*RenewUploadRequest) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *RenewUploadRequest) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *RenewUploadRequest) GetUploadId() string {
+	if x != nil {
+		return x.UploadId
+	}
+	return ""
+}
+
+type RenewUploadResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ExpiresAt     int64                  `protobuf:"varint,1,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"` // unix time in nanoseconds the renewed session expires
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RenewUploadResponse) Reset() {
+	*x = RenewUploadResponse{}
+	mi := &file_proto_dfs_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RenewUploadResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenewUploadResponse) ProtoMessage() {}
+
+func (x *RenewUploadResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenewUploadResponse.ProtoReflect.Descriptor instead.
+func (*RenewUploadResponse) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *RenewUploadResponse) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+type AllocateChunkRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Filename      string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	UploadId      string                 `protobuf:"bytes,2,opt,name=upload_id,json=uploadId,proto3" json:"upload_id,omitempty"`
+	ChunkIndex    int32                  `protobuf:"varint,3,opt,name=chunk_index,json=chunkIndex,proto3" json:"chunk_index,omitempty"` // the next chunk of the file, or an already allocated one to place again after a failed attempt
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AllocateChunkRequest) Reset() {
+	*x = AllocateChunkRequest{}
+	mi := &file_proto_dfs_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AllocateChunkRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AllocateChunkRequest) ProtoMessage() {}
+
+func (x *AllocateChunkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AllocateChunkRequest.ProtoReflect.Descriptor instead.
+func (*AllocateChunkRequest) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *AllocateChunkRequest) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *AllocateChunkRequest) GetUploadId() string {
+	if x != nil {
+		return x.UploadId
+	}
+	return ""
+}
+
+func (x *AllocateChunkRequest) GetChunkIndex() int32 {
+	if x != nil {
+		return x.ChunkIndex
+	}
+	return 0
+}
+
+type AllocateChunkResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ChunkLocation *ChunkLocation         `protobuf:"bytes,1,opt,name=chunk_location,json=chunkLocation,proto3" json:"chunk_location,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AllocateChunkResponse) Reset() {
+	*x = AllocateChunkResponse{}
+	mi := &file_proto_dfs_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AllocateChunkResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AllocateChunkResponse) ProtoMessage() {}
+
+func (x *AllocateChunkResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AllocateChunkResponse.ProtoReflect.Descriptor instead.
+func (*AllocateChunkResponse) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *AllocateChunkResponse) GetChunkLocation() *ChunkLocation {
+	if x != nil {
+		return x.ChunkLocation
+	}
+	return nil
+}
+
+type PrepareAppendRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Filename       string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	FullChunkIndex *int32                 `protobuf:"varint,2,opt,name=full_chunk_index,json=fullChunkIndex,proto3,oneof" json:"full_chunk_index,omitempty"` // the primary reported this chunk full, append to the next one
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *PrepareAppendRequest) Reset() {
+	*x = PrepareAppendRequest{}
+	mi := &file_proto_dfs_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PrepareAppendRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PrepareAppendRequest) ProtoMessage() {}
+
+func (x *PrepareAppendRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PrepareAppendRequest.ProtoReflect.Descriptor instead.
+func (*PrepareAppendRequest) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *PrepareAppendRequest) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *PrepareAppendRequest) GetFullChunkIndex() int32 {
+	if x != nil && x.FullChunkIndex != nil {
+		return *x.FullChunkIndex
+	}
+	return 0
+}
+
+type PrepareAppendResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ChunkLocation *ChunkLocation         `protobuf:"bytes,1,opt,name=chunk_location,json=chunkLocation,proto3" json:"chunk_location,omitempty"` // its primary chooses record offsets
+	Generation    int64                  `protobuf:"varint,3,opt,name=generation,proto3" json:"generation,omitempty"`                           // appends keep the generation of the file, passed to CompleteAppend
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PrepareAppendResponse) Reset() {
+	*x = PrepareAppendResponse{}
+	mi := &file_proto_dfs_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PrepareAppendResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PrepareAppendResponse) ProtoMessage() {}
+
+func (x *PrepareAppendResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PrepareAppendResponse.ProtoReflect.Descriptor instead.
+func (*PrepareAppendResponse) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *PrepareAppendResponse) GetChunkLocation() *ChunkLocation {
+	if x != nil {
+		return x.ChunkLocation
+	}
+	return nil
+}
+
+func (x *PrepareAppendResponse) GetGeneration() int64 {
+	if x != nil {
+		return x.Generation
+	}
+	return 0
+}
+
+type CompleteAppendRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Filename      string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	Generation    int64                  `protobuf:"varint,2,opt,name=generation,proto3" json:"generation,omitempty"`
+	ChunkIndex    int32                  `protobuf:"varint,3,opt,name=chunk_index,json=chunkIndex,proto3" json:"chunk_index,omitempty"`
+	EndOffset     int64                  `protobuf:"varint,4,opt,name=end_offset,json=endOffset,proto3" json:"end_offset,omitempty"` // end of the record inside the chunk
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CompleteAppendRequest) Reset() {
+	*x = CompleteAppendRequest{}
+	mi := &file_proto_dfs_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompleteAppendRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompleteAppendRequest) ProtoMessage() {}
+
+func (x *CompleteAppendRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompleteAppendRequest.ProtoReflect.Descriptor instead.
+func (*CompleteAppendRequest) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *CompleteAppendRequest) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *CompleteAppendRequest) GetGeneration() int64 {
+	if x != nil {
+		return x.Generation
+	}
+	return 0
+}
+
+func (x *CompleteAppendRequest) GetChunkIndex() int32 {
+	if x != nil {
+		return x.ChunkIndex
+	}
+	return 0
+}
+
+func (x *CompleteAppendRequest) GetEndOffset() int64 {
+	if x != nil {
+		return x.EndOffset
+	}
+	return 0
+}
+
+type CompleteAppendResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Filesize      int64                  `protobuf:"varint,1,opt,name=filesize,proto3" json:"filesize,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CompleteAppendResponse) Reset() {
+	*x = CompleteAppendResponse{}
+	mi := &file_proto_dfs_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompleteAppendResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompleteAppendResponse) ProtoMessage() {}
+
+func (x *CompleteAppendResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompleteAppendResponse.ProtoReflect.Descriptor instead.
+func (*CompleteAppendResponse) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *CompleteAppendResponse) GetFilesize() int64 {
+	if x != nil {
+		return x.Filesize
+	}
+	return 0
+}
+
+type DownloadFileRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Filename      string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	Generation    int64                  `protobuf:"varint,2,opt,name=generation,proto3" json:"generation,omitempty"` // version to download, 0 for the current one
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DownloadFileRequest) Reset() {
+	*x = DownloadFileRequest{}
+	mi := &file_proto_dfs_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DownloadFileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DownloadFileRequest) ProtoMessage() {}
+
+func (x *DownloadFileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DownloadFileRequest.ProtoReflect.Descriptor instead.
+func (*DownloadFileRequest) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *DownloadFileRequest) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *DownloadFileRequest) GetGeneration() int64 {
+	if x != nil {
+		return x.Generation
+	}
+	return 0
+}
+
+type DownloadFileResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Filesize      int64                  `protobuf:"varint,1,opt,name=filesize,proto3" json:"filesize,omitempty"`
+	ChunkLocation []*ChunkLocation       `protobuf:"bytes,2,rep,name=chunk_location,json=chunkLocation,proto3" json:"chunk_location,omitempty"`
+	Data          []byte                 `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"` // contents of a file stored inline, which has no chunks
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DownloadFileResponse) Reset() {
+	*x = DownloadFileResponse{}
+	mi := &file_proto_dfs_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DownloadFileResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DownloadFileResponse) ProtoMessage() {}
+
+func (x *DownloadFileResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DownloadFileResponse.ProtoReflect.Descriptor instead.
+func (*DownloadFileResponse) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *DownloadFileResponse) GetFilesize() int64 {
+	if x != nil {
+		return x.Filesize
+	}
+	return 0
+}
+
+func (x *DownloadFileResponse) GetChunkLocation() []*ChunkLocation {
+	if x != nil {
+		return x.ChunkLocation
+	}
+	return nil
+}
+
+func (x *DownloadFileResponse) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type ListFilesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tags          map[string]string      `protobuf:"bytes,1,rep,name=tags,proto3" json:"tags,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // only list files having all of these tags
+	SortBy        ListSortKey            `protobuf:"varint,2,opt,name=sort_by,json=sortBy,proto3,enum=dfs.ListSortKey" json:"sort_by,omitempty"`
+	Descending    bool                   `protobuf:"varint,3,opt,name=descending,proto3" json:"descending,omitempty"`
+	MinSize       *int64                 `protobuf:"varint,4,opt,name=min_size,json=minSize,proto3,oneof" json:"min_size,omitempty"`          // only list files of at least this many bytes
+	MaxSize       *int64                 `protobuf:"varint,5,opt,name=max_size,json=maxSize,proto3,oneof" json:"max_size,omitempty"`          // only list files of at most this many bytes
+	CreatedAfter  int64                  `protobuf:"varint,6,opt,name=created_after,json=createdAfter,proto3" json:"created_after,omitempty"` // unix time in seconds, only list files created after it, 0 for any
+	PageSize      int32                  `protobuf:"varint,7,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`             // most files ListFiles returns, 0 for all of them; streamed listings send every file
+	PageToken     string                 `protobuf:"bytes,8,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`           // next_page_token of the previous page, empty for the first page
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListFilesRequest) Reset() {
+	*x = ListFilesRequest{}
+	mi := &file_proto_dfs_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFilesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFilesRequest) ProtoMessage() {}
+
+func (x *ListFilesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFilesRequest.ProtoReflect.Descriptor instead.
+func (*ListFilesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ListFilesRequest) GetTags() map[string]string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *ListFilesRequest) GetSortBy() ListSortKey {
+	if x != nil {
+		return x.SortBy
+	}
+	return ListSortKey_LIST_SORT_NAME
+}
+
+func (x *ListFilesRequest) GetDescending() bool {
+	if x != nil {
+		return x.Descending
+	}
+	return false
+}
+
+func (x *ListFilesRequest) GetMinSize() int64 {
+	if x != nil && x.MinSize != nil {
+		return *x.MinSize
+	}
+	return 0
+}
+
+func (x *ListFilesRequest) GetMaxSize() int64 {
+	if x != nil && x.MaxSize != nil {
+		return *x.MaxSize
+	}
+	return 0
+}
+
+func (x *ListFilesRequest) GetCreatedAfter() int64 {
+	if x != nil {
+		return x.CreatedAfter
+	}
+	return 0
+}
+
+func (x *ListFilesRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListFilesRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type FileInfo struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Filename          string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	Filesize          int64                  `protobuf:"varint,2,opt,name=filesize,proto3" json:"filesize,omitempty"`
+	NumChunks         int32                  `protobuf:"varint,3,opt,name=num_chunks,json=numChunks,proto3" json:"num_chunks,omitempty"`
+	ReadCount         int64                  `protobuf:"varint,4,opt,name=read_count,json=readCount,proto3" json:"read_count,omitempty"`          // downloads and reads of the file
+	LastAccessed      int64                  `protobuf:"varint,5,opt,name=last_accessed,json=lastAccessed,proto3" json:"last_accessed,omitempty"` // unix time in seconds of the latest read, 0 if never read
+	Generation        int64                  `protobuf:"varint,6,opt,name=generation,proto3" json:"generation,omitempty"`                         // changes whenever the file is written, copied over or renamed
+	Immutable         bool                   `protobuf:"varint,7,opt,name=immutable,proto3" json:"immutable,omitempty"`                           // the file can't be deleted, renamed or overwritten
+	RetainUntil       int64                  `protobuf:"varint,8,opt,name=retain_until,json=retainUntil,proto3" json:"retain_until,omitempty"`    // unix time in seconds an immutable file becomes mutable again, 0 never
+	Tags              map[string]string      `protobuf:"bytes,9,rep,name=tags,proto3" json:"tags,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	ExpiresAt         int64                  `protobuf:"varint,10,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"` // unix time in seconds the file is deleted, 0 never
+	ReplicationFactor int32                  `protobuf:"varint,11,opt,name=replication_factor,json=replicationFactor,proto3" json:"replication_factor,omitempty"`
+	CreatedAt         int64                  `protobuf:"varint,12,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`            // unix time in seconds the file was first created
+	ModifiedAt        int64                  `protobuf:"varint,13,opt,name=modified_at,json=modifiedAt,proto3" json:"modified_at,omitempty"`         // unix time in seconds the current contents were written
+	MinReplicas       int32                  `protobuf:"varint,14,opt,name=min_replicas,json=minReplicas,proto3" json:"min_replicas,omitempty"`      // fewest replicas of any chunk of the file
+	Degraded          bool                   `protobuf:"varint,15,opt,name=degraded,proto3" json:"degraded,omitempty"`                               // some chunk has fewer replicas than the replication factor
+	Owner             string                 `protobuf:"bytes,16,opt,name=owner,proto3" json:"owner,omitempty"`                                      // user that created the file, empty if created anonymously
+	Group             string                 `protobuf:"bytes,17,opt,name=group,proto3" json:"group,omitempty"`                                      // group owning the file, the owner's primary group unless changed
+	Mode              uint32                 `protobuf:"varint,18,opt,name=mode,proto3" json:"mode,omitempty"`                                       // permission bits, e.g. 0640
+	SymlinkTarget     string                 `protobuf:"bytes,19,opt,name=symlink_target,json=symlinkTarget,proto3" json:"symlink_target,omitempty"` // file a symlink points at, empty for files with contents
+	HardLinks         []string               `protobuf:"bytes,20,rep,name=hard_links,json=hardLinks,proto3" json:"hard_links,omitempty"`             // other names of a hard linked file
+	Inline            bool                   `protobuf:"varint,21,opt,name=inline,proto3" json:"inline,omitempty"`                                   // the contents are stored in the master's metadata instead of chunks
+	StorageTier       string                 `protobuf:"bytes,22,opt,name=storage_tier,json=storageTier,proto3" json:"storage_tier,omitempty"`       // tier of the chunk servers holding the file's chunks, empty for any
+	ArchivedSize      int64                  `protobuf:"varint,23,opt,name=archived_size,json=archivedSize,proto3" json:"archived_size,omitempty"`   // compressed size of an archived file, recalled when read, 0 when not archived
+	StorageClass      string                 `protobuf:"bytes,24,opt,name=storage_class,json=storageClass,proto3" json:"storage_class,omitempty"`    // standard, reduced-redundancy or archive
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *FileInfo) Reset() {
+	*x = FileInfo{}
+	mi := &file_proto_dfs_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileInfo) ProtoMessage() {}
+
+func (x *FileInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileInfo.ProtoReflect.Descriptor instead.
+func (*FileInfo) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *FileInfo) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *FileInfo) GetFilesize() int64 {
+	if x != nil {
+		return x.Filesize
+	}
+	return 0
+}
+
+func (x *FileInfo) GetNumChunks() int32 {
+	if x != nil {
+		return x.NumChunks
+	}
+	return 0
+}
+
+func (x *FileInfo) GetReadCount() int64 {
+	if x != nil {
+		return x.ReadCount
+	}
+	return 0
+}
+
+func (x *FileInfo) GetLastAccessed() int64 {
+	if x != nil {
+		return x.LastAccessed
+	}
+	return 0
+}
+
+func (x *FileInfo) GetGeneration() int64 {
+	if x != nil {
+		return x.Generation
+	}
+	return 0
+}
+
+func (x *FileInfo) GetImmutable() bool {
+	if x != nil {
+		return x.Immutable
+	}
+	return false
+}
+
+func (x *FileInfo) GetRetainUntil() int64 {
+	if x != nil {
+		return x.RetainUntil
+	}
+	return 0
+}
+
+func (x *FileInfo) GetTags() map[string]string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *FileInfo) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+func (x *FileInfo) GetReplicationFactor() int32 {
+	if x != nil {
+		return x.ReplicationFactor
+	}
+	return 0
+}
+
+func (x *FileInfo) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *FileInfo) GetModifiedAt() int64 {
+	if x != nil {
+		return x.ModifiedAt
+	}
+	return 0
+}
+
+func (x *FileInfo) GetMinReplicas() int32 {
+	if x != nil {
+		return x.MinReplicas
+	}
+	return 0
+}
+
+func (x *FileInfo) GetDegraded() bool {
+	if x != nil {
+		return x.Degraded
+	}
+	return false
+}
+
+func (x *FileInfo) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *FileInfo) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+func (x *FileInfo) GetMode() uint32 {
+	if x != nil {
+		return x.Mode
+	}
+	return 0
+}
+
+func (x *FileInfo) GetSymlinkTarget() string {
+	if x != nil {
+		return x.SymlinkTarget
+	}
+	return ""
+}
+
+func (x *FileInfo) GetHardLinks() []string {
+	if x != nil {
+		return x.HardLinks
+	}
+	return nil
+}
+
+func (x *FileInfo) GetInline() bool {
+	if x != nil {
+		return x.Inline
+	}
+	return false
+}
+
+func (x *FileInfo) GetStorageTier() string {
+	if x != nil {
+		return x.StorageTier
+	}
+	return ""
+}
+
+func (x *FileInfo) GetArchivedSize() int64 {
+	if x != nil {
+		return x.ArchivedSize
+	}
+	return 0
+}
+
+func (x *FileInfo) GetStorageClass() string {
+	if x != nil {
+		return x.StorageClass
+	}
+	return ""
+}
+
+type ListFilesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Files         []*FileInfo            `protobuf:"bytes,1,rep,name=files,proto3" json:"files,omitempty"`
+	NextPageToken string                 `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"` // passed back to ListFiles for the next page, empty on the last page
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListFilesResponse) Reset() {
+	*x = ListFilesResponse{}
+	mi := &file_proto_dfs_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFilesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFilesResponse) ProtoMessage() {}
+
+func (x *ListFilesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFilesResponse.ProtoReflect.Descriptor instead.
+func (*ListFilesResponse) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ListFilesResponse) GetFiles() []*FileInfo {
+	if x != nil {
+		return x.Files
+	}
+	return nil
+}
+
+func (x *ListFilesResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type SearchFilesRequest struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	NameContains string                 `protobuf:"bytes,1,opt,name=name_contains,json=nameContains,proto3" json:"name_contains,omitempty"`
+	Tags         map[string]string      `protobuf:"bytes,2,rep,name=tags,proto3" json:"tags,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // only match files having all of these tags
+	MinSize      *int64                 `protobuf:"varint,3,opt,name=min_size,json=minSize,proto3,oneof" json:"min_size,omitempty"`
+	MaxSize      *int64                 `protobuf:"varint,4,opt,name=max_size,json=maxSize,proto3,oneof" json:"max_size,omitempty"`
+	// unix times in seconds bounding when files were created and last modified, 0 for no bound
+	CreatedAfter   int64 `protobuf:"varint,5,opt,name=created_after,json=createdAfter,proto3" json:"created_after,omitempty"`
+	CreatedBefore  int64 `protobuf:"varint,6,opt,name=created_before,json=createdBefore,proto3" json:"created_before,omitempty"`
+	ModifiedAfter  int64 `protobuf:"varint,7,opt,name=modified_after,json=modifiedAfter,proto3" json:"modified_after,omitempty"`
+	ModifiedBefore int64 `protobuf:"varint,8,opt,name=modified_before,json=modifiedBefore,proto3" json:"modified_before,omitempty"`
+	Limit          int32 `protobuf:"varint,9,opt,name=limit,proto3" json:"limit,omitempty"` // most files to return, 0 for the default of 1000
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *SearchFilesRequest) Reset() {
+	*x = SearchFilesRequest{}
+	mi := &file_proto_dfs_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchFilesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchFilesRequest) ProtoMessage() {}
+
+func (x *SearchFilesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchFilesRequest.ProtoReflect.Descriptor instead.
+func (*SearchFilesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *SearchFilesRequest) GetNameContains() string {
+	if x != nil {
+		return x.NameContains
+	}
+	return ""
+}
+
+func (x *SearchFilesRequest) GetTags() map[string]string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *SearchFilesRequest) GetMinSize() int64 {
+	if x != nil && x.MinSize != nil {
+		return *x.MinSize
+	}
+	return 0
+}
+
+func (x *SearchFilesRequest) GetMaxSize() int64 {
+	if x != nil && x.MaxSize != nil {
+		return *x.MaxSize
+	}
+	return 0
+}
+
+func (x *SearchFilesRequest) GetCreatedAfter() int64 {
+	if x != nil {
+		return x.CreatedAfter
+	}
+	return 0
+}
+
+func (x *SearchFilesRequest) GetCreatedBefore() int64 {
+	if x != nil {
+		return x.CreatedBefore
+	}
+	return 0
+}
+
+func (x *SearchFilesRequest) GetModifiedAfter() int64 {
+	if x != nil {
+		return x.ModifiedAfter
+	}
+	return 0
+}
+
+func (x *SearchFilesRequest) GetModifiedBefore() int64 {
+	if x != nil {
+		return x.ModifiedBefore
+	}
+	return 0
+}
+
+func (x *SearchFilesRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type SearchFilesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Files         []*FileInfo            `protobuf:"bytes,1,rep,name=files,proto3" json:"files,omitempty"`          // in filename order
+	Truncated     bool                   `protobuf:"varint,2,opt,name=truncated,proto3" json:"truncated,omitempty"` // more files matched than the limit
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchFilesResponse) Reset() {
+	*x = SearchFilesResponse{}
+	mi := &file_proto_dfs_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchFilesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchFilesResponse) ProtoMessage() {}
+
+func (x *SearchFilesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchFilesResponse.ProtoReflect.Descriptor instead.
+func (*SearchFilesResponse) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *SearchFilesResponse) GetFiles() []*FileInfo {
+	if x != nil {
+		return x.Files
+	}
+	return nil
+}
+
+func (x *SearchFilesResponse) GetTruncated() bool {
+	if x != nil {
+		return x.Truncated
+	}
+	return false
+}
+
+type HeartbeatRequest struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	ChunkServerAddress string                 `protobuf:"bytes,1,opt,name=chunk_server_address,json=chunkServerAddress,proto3" json:"chunk_server_address,omitempty"`
+	ChunkHandles       []string               `protobuf:"bytes,2,rep,name=chunk_handles,json=chunkHandles,proto3" json:"chunk_handles,omitempty"`
+	CapacityBytes      int64                  `protobuf:"varint,3,opt,name=capacity_bytes,json=capacityBytes,proto3" json:"capacity_bytes,omitempty"` // aggregated over all storage directories
+	FreeBytes          int64                  `protobuf:"varint,4,opt,name=free_bytes,json=freeBytes,proto3" json:"free_bytes,omitempty"`
+	Load               *LoadMetrics           `protobuf:"bytes,5,opt,name=load,proto3" json:"load,omitempty"`
+	Zone               string                 `protobuf:"bytes,6,opt,name=zone,proto3" json:"zone,omitempty"`                                                                                                          // failure domain of the chunk server, e.g. rack or availability zone
+	ChunkReads         map[string]int64       `protobuf:"bytes,7,rep,name=chunk_reads,json=chunkReads,proto3" json:"chunk_reads,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"` // key: chunk handle, value: reads since the previous heartbeat
+	ServerId           string                 `protobuf:"bytes,8,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`                                                                                  // identity kept in the chunk server's storage directories, see RegisterChunkServerRequest
+	Tier               string                 `protobuf:"bytes,9,opt,name=tier,proto3" json:"tier,omitempty"`                                                                                                          // storage media of the chunk server, e.g. ssd or hdd
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *HeartbeatRequest) Reset() {
+	*x = HeartbeatRequest{}
+	mi := &file_proto_dfs_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HeartbeatRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeartbeatRequest) ProtoMessage() {}
+
+func (x *HeartbeatRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HeartbeatRequest.ProtoReflect.Descriptor instead.
+func (*HeartbeatRequest) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *HeartbeatRequest) GetChunkServerAddress() string {
+	if x != nil {
+		return x.ChunkServerAddress
+	}
+	return ""
+}
+
+func (x *HeartbeatRequest) GetChunkHandles() []string {
+	if x != nil {
+		return x.ChunkHandles
+	}
+	return nil
+}
+
+func (x *HeartbeatRequest) GetCapacityBytes() int64 {
+	if x != nil {
+		return x.CapacityBytes
+	}
+	return 0
+}
+
+func (x *HeartbeatRequest) GetFreeBytes() int64 {
+	if x != nil {
+		return x.FreeBytes
+	}
+	return 0
+}
+
+func (x *HeartbeatRequest) GetLoad() *LoadMetrics {
+	if x != nil {
+		return x.Load
+	}
+	return nil
+}
+
+func (x *HeartbeatRequest) GetZone() string {
+	if x != nil {
+		return x.Zone
+	}
+	return ""
+}
+
+func (x *HeartbeatRequest) GetChunkReads() map[string]int64 {
+	if x != nil {
+		return x.ChunkReads
+	}
+	return nil
+}
+
+func (x *HeartbeatRequest) GetServerId() string {
+	if x != nil {
+		return x.ServerId
+	}
+	return ""
+}
+
+func (x *HeartbeatRequest) GetTier() string {
+	if x != nil {
+		return x.Tier
+	}
+	return ""
+}
+
+// LoadMetrics describes how busy a chunk server was since its previous heartbeat
+type LoadMetrics struct {
+	state                 protoimpl.MessageState `protogen:"open.v1"`
+	Iops                  float64                `protobuf:"fixed64,1,opt,name=iops,proto3" json:"iops,omitempty"`                                           // chunk reads and writes per second
+	QueueDepth            int32                  `protobuf:"varint,2,opt,name=queue_depth,json=queueDepth,proto3" json:"queue_depth,omitempty"`              // chunk reads and writes running or waiting when the heartbeat was sent
+	CpuUtilization        float64                `protobuf:"fixed64,3,opt,name=cpu_utilization,json=cpuUtilization,proto3" json:"cpu_utilization,omitempty"` // share of all cores used by the chunk server process, 0 to 1
+	NetworkInBytesPerSec  float64                `protobuf:"fixed64,4,opt,name=network_in_bytes_per_sec,json=networkInBytesPerSec,proto3" json:"network_in_bytes_per_sec,omitempty"`
+	NetworkOutBytesPerSec float64                `protobuf:"fixed64,5,opt,name=network_out_bytes_per_sec,json=networkOutBytesPerSec,proto3" json:"network_out_bytes_per_sec,omitempty"`
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *LoadMetrics) Reset() {
+	*x = LoadMetrics{}
+	mi := &file_proto_dfs_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LoadMetrics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LoadMetrics) ProtoMessage() {}
+
+func (x *LoadMetrics) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LoadMetrics.ProtoReflect.Descriptor instead.
+func (*LoadMetrics) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *LoadMetrics) GetIops() float64 {
+	if x != nil {
+		return x.Iops
+	}
+	return 0
+}
+
+func (x *LoadMetrics) GetQueueDepth() int32 {
+	if x != nil {
+		return x.QueueDepth
+	}
+	return 0
+}
+
+func (x *LoadMetrics) GetCpuUtilization() float64 {
+	if x != nil {
+		return x.CpuUtilization
+	}
+	return 0
+}
+
+func (x *LoadMetrics) GetNetworkInBytesPerSec() float64 {
+	if x != nil {
+		return x.NetworkInBytesPerSec
+	}
+	return 0
+}
+
+func (x *LoadMetrics) GetNetworkOutBytesPerSec() float64 {
+	if x != nil {
+		return x.NetworkOutBytesPerSec
+	}
+	return 0
+}
+
+type HeartbeatResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HeartbeatResponse) Reset() {
+	*x = HeartbeatResponse{}
+	mi := &file_proto_dfs_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HeartbeatResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeartbeatResponse) ProtoMessage() {}
+
+func (x *HeartbeatResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HeartbeatResponse.ProtoReflect.Descriptor instead.
+func (*HeartbeatResponse) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *HeartbeatResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+type RegisterChunkServerRequest struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	ChunkServerAddress string                 `protobuf:"bytes,1,opt,name=chunk_server_address,json=chunkServerAddress,proto3" json:"chunk_server_address,omitempty"`
+	CapacityBytes      int64                  `protobuf:"varint,2,opt,name=capacity_bytes,json=capacityBytes,proto3" json:"capacity_bytes,omitempty"` // aggregated over all storage directories
+	FreeBytes          int64                  `protobuf:"varint,3,opt,name=free_bytes,json=freeBytes,proto3" json:"free_bytes,omitempty"`
+	Labels             map[string]string      `protobuf:"bytes,4,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // e.g. zone=us-east-1a, rack=r12, the zone and tier labels are used for placement
+	Version            string                 `protobuf:"bytes,5,opt,name=version,proto3" json:"version,omitempty"`                                                                         // software version of the chunk server
+	StorageDirectories []*StorageDirectory    `protobuf:"bytes,6,rep,name=storage_directories,json=storageDirectories,proto3" json:"storage_directories,omitempty"`
+	ChunkHandles       []string               `protobuf:"bytes,7,rep,name=chunk_handles,json=chunkHandles,proto3" json:"chunk_handles,omitempty"` // chunks already stored, e.g. after a restart
+	ServerId           string                 `protobuf:"bytes,8,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`             // identity kept in the storage directories, so two servers sharing an address or storage are told apart
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *RegisterChunkServerRequest) Reset() {
+	*x = RegisterChunkServerRequest{}
+	mi := &file_proto_dfs_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegisterChunkServerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterChunkServerRequest) ProtoMessage() {}
+
+func (x *RegisterChunkServerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterChunkServerRequest.ProtoReflect.Descriptor instead.
+func (*RegisterChunkServerRequest) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *RegisterChunkServerRequest) GetChunkServerAddress() string {
+	if x != nil {
+		return x.ChunkServerAddress
+	}
+	return ""
+}
+
+func (x *RegisterChunkServerRequest) GetCapacityBytes() int64 {
+	if x != nil {
+		return x.CapacityBytes
+	}
+	return 0
+}
+
+func (x *RegisterChunkServerRequest) GetFreeBytes() int64 {
+	if x != nil {
+		return x.FreeBytes
+	}
+	return 0
+}
+
+func (x *RegisterChunkServerRequest) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *RegisterChunkServerRequest) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *RegisterChunkServerRequest) GetStorageDirectories() []*StorageDirectory {
+	if x != nil {
+		return x.StorageDirectories
+	}
+	return nil
+}
+
+func (x *RegisterChunkServerRequest) GetChunkHandles() []string {
+	if x != nil {
+		return x.ChunkHandles
+	}
+	return nil
+}
+
+func (x *RegisterChunkServerRequest) GetServerId() string {
+	if x != nil {
+		return x.ServerId
+	}
+	return ""
+}
+
+// StorageDirectory is one of the directories a chunk server spreads its chunks across
+type StorageDirectory struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	CapacityBytes int64                  `protobuf:"varint,2,opt,name=capacity_bytes,json=capacityBytes,proto3" json:"capacity_bytes,omitempty"`
+	FreeBytes     int64                  `protobuf:"varint,3,opt,name=free_bytes,json=freeBytes,proto3" json:"free_bytes,omitempty"`
+	ChunkCount    int64                  `protobuf:"varint,4,opt,name=chunk_count,json=chunkCount,proto3" json:"chunk_count,omitempty"`
+	Healthy       bool                   `protobuf:"varint,5,opt,name=healthy,proto3" json:"healthy,omitempty"` // false once isolated after a disk failure
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StorageDirectory) Reset() {
+	*x = StorageDirectory{}
+	mi := &file_proto_dfs_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StorageDirectory) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StorageDirectory) ProtoMessage() {}
+
+func (x *StorageDirectory) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StorageDirectory.ProtoReflect.Descriptor instead.
+func (*StorageDirectory) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *StorageDirectory) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *StorageDirectory) GetCapacityBytes() int64 {
+	if x != nil {
+		return x.CapacityBytes
+	}
+	return 0
+}
+
+func (x *StorageDirectory) GetFreeBytes() int64 {
+	if x != nil {
+		return x.FreeBytes
+	}
+	return 0
+}
+
+func (x *StorageDirectory) GetChunkCount() int64 {
+	if x != nil {
+		return x.ChunkCount
+	}
+	return 0
+}
+
+func (x *StorageDirectory) GetHealthy() bool {
+	if x != nil {
+		return x.Healthy
+	}
+	return false
+}
+
+type RegisterChunkServerResponse struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	ChunkSize           int64                  `protobuf:"varint,1,opt,name=chunk_size,json=chunkSize,proto3" json:"chunk_size,omitempty"` // chunk servers built with another chunk size must not join
+	HeartbeatIntervalMs int64                  `protobuf:"varint,2,opt,name=heartbeat_interval_ms,json=heartbeatIntervalMs,proto3" json:"heartbeat_interval_ms,omitempty"`
+	ReplicationFactor   int32                  `protobuf:"varint,3,opt,name=replication_factor,json=replicationFactor,proto3" json:"replication_factor,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *RegisterChunkServerResponse) Reset() {
+	*x = RegisterChunkServerResponse{}
+	mi := &file_proto_dfs_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegisterChunkServerResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterChunkServerResponse) ProtoMessage() {}
+
+func (x *RegisterChunkServerResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterChunkServerResponse.ProtoReflect.Descriptor instead.
+func (*RegisterChunkServerResponse) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *RegisterChunkServerResponse) GetChunkSize() int64 {
+	if x != nil {
+		return x.ChunkSize
+	}
+	return 0
+}
+
+func (x *RegisterChunkServerResponse) GetHeartbeatIntervalMs() int64 {
+	if x != nil {
+		return x.HeartbeatIntervalMs
+	}
+	return 0
+}
+
+func (x *RegisterChunkServerResponse) GetReplicationFactor() int32 {
+	if x != nil {
+		return x.ReplicationFactor
+	}
+	return 0
+}
+
+type ReportChunkRequest struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	ChunkHandle        string                 `protobuf:"bytes,1,opt,name=chunk_handle,json=chunkHandle,proto3" json:"chunk_handle,omitempty"`
+	ChunkServerAddress string                 `protobuf:"bytes,2,opt,name=chunk_server_address,json=chunkServerAddress,proto3" json:"chunk_server_address,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *ReportChunkRequest) Reset() {
+	*x = ReportChunkRequest{}
+	mi := &file_proto_dfs_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReportChunkRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReportChunkRequest) ProtoMessage() {}
+
+func (x *ReportChunkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReportChunkRequest.ProtoReflect.Descriptor instead.
+func (*ReportChunkRequest) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *ReportChunkRequest) GetChunkHandle() string {
+	if x != nil {
+		return x.ChunkHandle
+	}
+	return ""
+}
+
+func (x *ReportChunkRequest) GetChunkServerAddress() string {
+	if x != nil {
+		return x.ChunkServerAddress
+	}
+	return ""
+}
+
+type ReportChunkResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReportChunkResponse) Reset() {
+	*x = ReportChunkResponse{}
+	mi := &file_proto_dfs_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReportChunkResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReportChunkResponse) ProtoMessage() {}
+
+func (x *ReportChunkResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReportChunkResponse.ProtoReflect.Descriptor instead.
+func (*ReportChunkResponse) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *ReportChunkResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+type ReportLostChunksRequest struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	ChunkServerAddress string                 `protobuf:"bytes,1,opt,name=chunk_server_address,json=chunkServerAddress,proto3" json:"chunk_server_address,omitempty"`
+	ChunkHandles       []string               `protobuf:"bytes,2,rep,name=chunk_handles,json=chunkHandles,proto3" json:"chunk_handles,omitempty"`
+	Reason             string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *ReportLostChunksRequest) Reset() {
+	*x = ReportLostChunksRequest{}
+	mi := &file_proto_dfs_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReportLostChunksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReportLostChunksRequest) ProtoMessage() {}
+
+func (x *ReportLostChunksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReportLostChunksRequest.ProtoReflect.Descriptor instead.
+func (*ReportLostChunksRequest) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *ReportLostChunksRequest) GetChunkServerAddress() string {
+	if x != nil {
+		return x.ChunkServerAddress
+	}
+	return ""
+}
+
+func (x *ReportLostChunksRequest) GetChunkHandles() []string {
+	if x != nil {
+		return x.ChunkHandles
+	}
+	return nil
+}
+
+func (x *ReportLostChunksRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type ReportLostChunksResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReportLostChunksResponse) Reset() {
+	*x = ReportLostChunksResponse{}
+	mi := &file_proto_dfs_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReportLostChunksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReportLostChunksResponse) ProtoMessage() {}
+
+func (x *ReportLostChunksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReportLostChunksResponse.ProtoReflect.Descriptor instead.
+func (*ReportLostChunksResponse) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *ReportLostChunksResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+type ReportCorruptChunkRequest struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	ChunkServerAddress string                 `protobuf:"bytes,1,opt,name=chunk_server_address,json=chunkServerAddress,proto3" json:"chunk_server_address,omitempty"`
+	ChunkHandle        string                 `protobuf:"bytes,2,opt,name=chunk_handle,json=chunkHandle,proto3" json:"chunk_handle,omitempty"`
+	Reason             string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *ReportCorruptChunkRequest) Reset() {
+	*x = ReportCorruptChunkRequest{}
+	mi := &file_proto_dfs_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReportCorruptChunkRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReportCorruptChunkRequest) ProtoMessage() {}
+
+func (x *ReportCorruptChunkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReportCorruptChunkRequest.ProtoReflect.Descriptor instead.
+func (*ReportCorruptChunkRequest) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *ReportCorruptChunkRequest) GetChunkServerAddress() string {
+	if x != nil {
+		return x.ChunkServerAddress
+	}
+	return ""
+}
+
+func (x *ReportCorruptChunkRequest) GetChunkHandle() string {
+	if x != nil {
+		return x.ChunkHandle
+	}
+	return ""
+}
+
+func (x *ReportCorruptChunkRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type ReportCorruptChunkResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReportCorruptChunkResponse) Reset() {
+	*x = ReportCorruptChunkResponse{}
+	mi := &file_proto_dfs_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReportCorruptChunkResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReportCorruptChunkResponse) ProtoMessage() {}
+
+func (x *ReportCorruptChunkResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReportCorruptChunkResponse.ProtoReflect.Descriptor instead.
+func (*ReportCorruptChunkResponse) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *ReportCorruptChunkResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+type CopyFileRequest struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	SourceFilename      string                 `protobuf:"bytes,1,opt,name=source_filename,json=sourceFilename,proto3" json:"source_filename,omitempty"`
+	DestinationFilename string                 `protobuf:"bytes,2,opt,name=destination_filename,json=destinationFilename,proto3" json:"destination_filename,omitempty"`
+	IfGenerationMatch   *int64                 `protobuf:"varint,3,opt,name=if_generation_match,json=ifGenerationMatch,proto3,oneof" json:"if_generation_match,omitempty"` // only overwrite this generation of the destination, 0 if it must not exist
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *CopyFileRequest) Reset() {
+	*x = CopyFileRequest{}
+	mi := &file_proto_dfs_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CopyFileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CopyFileRequest) ProtoMessage() {}
+
+func (x *CopyFileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CopyFileRequest.ProtoReflect.Descriptor instead.
+func (*CopyFileRequest) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *CopyFileRequest) GetSourceFilename() string {
+	if x != nil {
+		return x.SourceFilename
+	}
+	return ""
+}
+
+func (x *CopyFileRequest) GetDestinationFilename() string {
+	if x != nil {
+		return x.DestinationFilename
+	}
+	return ""
+}
+
+func (x *CopyFileRequest) GetIfGenerationMatch() int64 {
+	if x != nil && x.IfGenerationMatch != nil {
+		return *x.IfGenerationMatch
+	}
+	return 0
+}
+
+type CopyFileResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Generation    int64                  `protobuf:"varint,2,opt,name=generation,proto3" json:"generation,omitempty"` // generation of the destination
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CopyFileResponse) Reset() {
+	*x = CopyFileResponse{}
+	mi := &file_proto_dfs_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CopyFileResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CopyFileResponse) ProtoMessage() {}
+
+func (x *CopyFileResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CopyFileResponse.ProtoReflect.Descriptor instead.
+func (*CopyFileResponse) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *CopyFileResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *CopyFileResponse) GetGeneration() int64 {
+	if x != nil {
+		return x.Generation
+	}
+	return 0
+}
+
+type CloneFileRequest struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	SourceFilename      string                 `protobuf:"bytes,1,opt,name=source_filename,json=sourceFilename,proto3" json:"source_filename,omitempty"`
+	DestinationFilename string                 `protobuf:"bytes,2,opt,name=destination_filename,json=destinationFilename,proto3" json:"destination_filename,omitempty"`
+	IfGenerationMatch   *int64                 `protobuf:"varint,3,opt,name=if_generation_match,json=ifGenerationMatch,proto3,oneof" json:"if_generation_match,omitempty"` // only overwrite this generation of the destination, 0 if it must not exist
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *CloneFileRequest) Reset() {
+	*x = CloneFileRequest{}
+	mi := &file_proto_dfs_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CloneFileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CloneFileRequest) ProtoMessage() {}
+
+func (x *CloneFileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CloneFileRequest.ProtoReflect.Descriptor instead.
+func (*CloneFileRequest) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *CloneFileRequest) GetSourceFilename() string {
+	if x != nil {
+		return x.SourceFilename
+	}
+	return ""
+}
+
+func (x *CloneFileRequest) GetDestinationFilename() string {
+	if x != nil {
+		return x.DestinationFilename
+	}
+	return ""
+}
+
+func (x *CloneFileRequest) GetIfGenerationMatch() int64 {
+	if x != nil && x.IfGenerationMatch != nil {
+		return *x.IfGenerationMatch
+	}
+	return 0
+}
+
+type CloneFileResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Generation    int64                  `protobuf:"varint,2,opt,name=generation,proto3" json:"generation,omitempty"` // generation of the destination
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CloneFileResponse) Reset() {
+	*x = CloneFileResponse{}
+	mi := &file_proto_dfs_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CloneFileResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CloneFileResponse) ProtoMessage() {}
+
+func (x *CloneFileResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CloneFileResponse.ProtoReflect.Descriptor instead.
+func (*CloneFileResponse) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *CloneFileResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *CloneFileResponse) GetGeneration() int64 {
+	if x != nil {
+		return x.Generation
+	}
+	return 0
+}
+
+type RenameFileRequest struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	SourceFilename      string                 `protobuf:"bytes,1,opt,name=source_filename,json=sourceFilename,proto3" json:"source_filename,omitempty"`
+	DestinationFilename string                 `protobuf:"bytes,2,opt,name=destination_filename,json=destinationFilename,proto3" json:"destination_filename,omitempty"`    // must not exist
+	IfGenerationMatch   *int64                 `protobuf:"varint,3,opt,name=if_generation_match,json=ifGenerationMatch,proto3,oneof" json:"if_generation_match,omitempty"` // only rename this generation of the source
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *RenameFileRequest) Reset() {
+	*x = RenameFileRequest{}
+	mi := &file_proto_dfs_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RenameFileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenameFileRequest) ProtoMessage() {}
+
+func (x *RenameFileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenameFileRequest.ProtoReflect.Descriptor instead.
+func (*RenameFileRequest) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *RenameFileRequest) GetSourceFilename() string {
+	if x != nil {
+		return x.SourceFilename
+	}
+	return ""
+}
+
+func (x *RenameFileRequest) GetDestinationFilename() string {
+	if x != nil {
+		return x.DestinationFilename
+	}
+	return ""
+}
+
+func (x *RenameFileRequest) GetIfGenerationMatch() int64 {
+	if x != nil && x.IfGenerationMatch != nil {
+		return *x.IfGenerationMatch
+	}
+	return 0
+}
+
+type RenameFileResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Generation    int64                  `protobuf:"varint,2,opt,name=generation,proto3" json:"generation,omitempty"` // generation of the renamed file
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RenameFileResponse) Reset() {
+	*x = RenameFileResponse{}
+	mi := &file_proto_dfs_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RenameFileResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenameFileResponse) ProtoMessage() {}
+
+func (x *RenameFileResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenameFileResponse.ProtoReflect.Descriptor instead.
+func (*RenameFileResponse) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *RenameFileResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *RenameFileResponse) GetGeneration() int64 {
+	if x != nil {
+		return x.Generation
+	}
+	return 0
+}
+
+type WatchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Prefix        string                 `protobuf:"bytes,1,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchRequest) Reset() {
+	*x = WatchRequest{}
+	mi := &file_proto_dfs_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchRequest) ProtoMessage() {}
+
+func (x *WatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchRequest.ProtoReflect.Descriptor instead.
+func (*WatchRequest) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *WatchRequest) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+type FileEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          FileEventType          `protobuf:"varint,1,opt,name=type,proto3,enum=dfs.FileEventType" json:"type,omitempty"`
+	Filename      string                 `protobuf:"bytes,2,opt,name=filename,proto3" json:"filename,omitempty"`
+	OldFilename   string                 `protobuf:"bytes,3,opt,name=old_filename,json=oldFilename,proto3" json:"old_filename,omitempty"` // set for renames
+	Filesize      int64                  `protobuf:"varint,4,opt,name=filesize,proto3" json:"filesize,omitempty"`
+	Timestamp     int64                  `protobuf:"varint,5,opt,name=timestamp,proto3" json:"timestamp,omitempty"` // unix time in nanoseconds
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FileEvent) Reset() {
+	*x = FileEvent{}
+	mi := &file_proto_dfs_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileEvent) ProtoMessage() {}
+
+func (x *FileEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileEvent.ProtoReflect.Descriptor instead.
+func (*FileEvent) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *FileEvent) GetType() FileEventType {
+	if x != nil {
+		return x.Type
+	}
+	return FileEventType_FILE_EVENT_UNSPECIFIED
+}
+
+func (x *FileEvent) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *FileEvent) GetOldFilename() string {
+	if x != nil {
+		return x.OldFilename
+	}
+	return ""
+}
+
+func (x *FileEvent) GetFilesize() int64 {
+	if x != nil {
+		return x.Filesize
+	}
+	return 0
+}
+
+func (x *FileEvent) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+type DeleteFileRequest struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Filename          string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	IfGenerationMatch *int64                 `protobuf:"varint,2,opt,name=if_generation_match,json=ifGenerationMatch,proto3,oneof" json:"if_generation_match,omitempty"` // only delete this generation of the file
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *DeleteFileRequest) Reset() {
+	*x = DeleteFileRequest{}
+	mi := &file_proto_dfs_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteFileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteFileRequest) ProtoMessage() {}
+
+func (x *DeleteFileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteFileRequest.ProtoReflect.Descriptor instead.
+func (*DeleteFileRequest) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *DeleteFileRequest) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *DeleteFileRequest) GetIfGenerationMatch() int64 {
+	if x != nil && x.IfGenerationMatch != nil {
+		return *x.IfGenerationMatch
+	}
+	return 0
+}
+
+type DeleteFileResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteFileResponse) Reset() {
+	*x = DeleteFileResponse{}
+	mi := &file_proto_dfs_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteFileResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteFileResponse) ProtoMessage() {}
+
+func (x *DeleteFileResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteFileResponse.ProtoReflect.Descriptor instead.
+func (*DeleteFileResponse) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *DeleteFileResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+type UndeleteFileRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Filename      string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UndeleteFileRequest) Reset() {
+	*x = UndeleteFileRequest{}
+	mi := &file_proto_dfs_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UndeleteFileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UndeleteFileRequest) ProtoMessage() {}
+
+func (x *UndeleteFileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UndeleteFileRequest.ProtoReflect.Descriptor instead.
+func (*UndeleteFileRequest) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *UndeleteFileRequest) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+type UndeleteFileResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Generation    int64                  `protobuf:"varint,1,opt,name=generation,proto3" json:"generation,omitempty"` // generation of the restored file
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UndeleteFileResponse) Reset() {
+	*x = UndeleteFileResponse{}
+	mi := &file_proto_dfs_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UndeleteFileResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UndeleteFileResponse) ProtoMessage() {}
+
+func (x *UndeleteFileResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UndeleteFileResponse.ProtoReflect.Descriptor instead.
+func (*UndeleteFileResponse) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *UndeleteFileResponse) GetGeneration() int64 {
+	if x != nil {
+		return x.Generation
+	}
+	return 0
+}
+
+type GetFileInfoRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Filename      string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetFileInfoRequest) Reset() {
+	*x = GetFileInfoRequest{}
+	mi := &file_proto_dfs_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetFileInfoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetFileInfoRequest) ProtoMessage() {}
+
+func (x *GetFileInfoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetFileInfoRequest.ProtoReflect.Descriptor instead.
+func (*GetFileInfoRequest) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *GetFileInfoRequest) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+type GetFileInfoResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	File           *FileInfo              `protobuf:"bytes,1,opt,name=file,proto3" json:"file,omitempty"`
+	ChunkLocations []*ChunkLocation       `protobuf:"bytes,2,rep,name=chunk_locations,json=chunkLocations,proto3" json:"chunk_locations,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *GetFileInfoResponse) Reset() {
+	*x = GetFileInfoResponse{}
+	mi := &file_proto_dfs_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetFileInfoResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetFileInfoResponse) ProtoMessage() {}
+
+func (x *GetFileInfoResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetFileInfoResponse.ProtoReflect.Descriptor instead.
+func (*GetFileInfoResponse) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *GetFileInfoResponse) GetFile() *FileInfo {
+	if x != nil {
+		return x.File
+	}
+	return nil
+}
+
+func (x *GetFileInfoResponse) GetChunkLocations() []*ChunkLocation {
+	if x != nil {
+		return x.ChunkLocations
+	}
+	return nil
+}
+
+type ListFileVersionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Filename      string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListFileVersionsRequest) Reset() {
+	*x = ListFileVersionsRequest{}
+	mi := &file_proto_dfs_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFileVersionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFileVersionsRequest) ProtoMessage() {}
+
+func (x *ListFileVersionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFileVersionsRequest.ProtoReflect.Descriptor instead.
+func (*ListFileVersionsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *ListFileVersionsRequest) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+type FileVersion struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Generation    int64                  `protobuf:"varint,1,opt,name=generation,proto3" json:"generation,omitempty"` // identifies the version in downloads
+	Filesize      int64                  `protobuf:"varint,2,opt,name=filesize,proto3" json:"filesize,omitempty"`
+	NumChunks     int32                  `protobuf:"varint,3,opt,name=num_chunks,json=numChunks,proto3" json:"num_chunks,omitempty"`
+	CreatedAt     int64                  `protobuf:"varint,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`    // unix time in seconds the version was written
+	ReplacedAt    int64                  `protobuf:"varint,5,opt,name=replaced_at,json=replacedAt,proto3" json:"replaced_at,omitempty"` // unix time in seconds a newer version replaced it, 0 for the current version
+	Current       bool                   `protobuf:"varint,6,opt,name=current,proto3" json:"current,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FileVersion) Reset() {
+	*x = FileVersion{}
+	mi := &file_proto_dfs_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileVersion) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileVersion) ProtoMessage() {}
+
+func (x *FileVersion) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileVersion.ProtoReflect.Descriptor instead.
+func (*FileVersion) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *FileVersion) GetGeneration() int64 {
+	if x != nil {
+		return x.Generation
+	}
+	return 0
+}
+
+func (x *FileVersion) GetFilesize() int64 {
+	if x != nil {
+		return x.Filesize
+	}
+	return 0
+}
+
+func (x *FileVersion) GetNumChunks() int32 {
+	if x != nil {
+		return x.NumChunks
+	}
+	return 0
+}
+
+func (x *FileVersion) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *FileVersion) GetReplacedAt() int64 {
+	if x != nil {
+		return x.ReplacedAt
+	}
+	return 0
+}
+
+func (x *FileVersion) GetCurrent() bool {
+	if x != nil {
+		return x.Current
+	}
+	return false
+}
+
+type ListFileVersionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Versions      []*FileVersion         `protobuf:"bytes,1,rep,name=versions,proto3" json:"versions,omitempty"` // newest first
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListFileVersionsResponse) Reset() {
+	*x = ListFileVersionsResponse{}
+	mi := &file_proto_dfs_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFileVersionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFileVersionsResponse) ProtoMessage() {}
+
+func (x *ListFileVersionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFileVersionsResponse.ProtoReflect.Descriptor instead.
+func (*ListFileVersionsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *ListFileVersionsResponse) GetVersions() []*FileVersion {
+	if x != nil {
+		return x.Versions
+	}
+	return nil
+}
+
+type UpdateFileTagsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Filename      string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	Set           map[string]string      `protobuf:"bytes,2,rep,name=set,proto3" json:"set,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // tags to add or change
+	Remove        []string               `protobuf:"bytes,3,rep,name=remove,proto3" json:"remove,omitempty"`                                                                     // keys of tags to remove
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateFileTagsRequest) Reset() {
+	*x = UpdateFileTagsRequest{}
+	mi := &file_proto_dfs_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateFileTagsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateFileTagsRequest) ProtoMessage() {}
+
+func (x *UpdateFileTagsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[50]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateFileTagsRequest.ProtoReflect.Descriptor instead.
+func (*UpdateFileTagsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *UpdateFileTagsRequest) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *UpdateFileTagsRequest) GetSet() map[string]string {
+	if x != nil {
+		return x.Set
+	}
+	return nil
+}
+
+func (x *UpdateFileTagsRequest) GetRemove() []string {
+	if x != nil {
+		return x.Remove
+	}
+	return nil
+}
+
+type UpdateFileTagsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tags          map[string]string      `protobuf:"bytes,1,rep,name=tags,proto3" json:"tags,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // tags of the file after the update
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateFileTagsResponse) Reset() {
+	*x = UpdateFileTagsResponse{}
+	mi := &file_proto_dfs_proto_msgTypes[51]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateFileTagsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateFileTagsResponse) ProtoMessage() {}
+
+func (x *UpdateFileTagsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[51]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateFileTagsResponse.ProtoReflect.Descriptor instead.
+func (*UpdateFileTagsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{51}
+}
+
+func (x *UpdateFileTagsResponse) GetTags() map[string]string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+// FileAttributes are the attributes of a file that can change without uploading it again
+type FileAttributes struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Tags              map[string]string      `protobuf:"bytes,1,rep,name=tags,proto3" json:"tags,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	ExpiresAt         int64                  `protobuf:"varint,2,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"` // unix time in seconds the file is deleted, 0 never
+	ReplicationFactor int32                  `protobuf:"varint,3,opt,name=replication_factor,json=replicationFactor,proto3" json:"replication_factor,omitempty"`
+	Immutable         bool                   `protobuf:"varint,4,opt,name=immutable,proto3" json:"immutable,omitempty"`                          // set at upload, read only
+	RetainUntil       int64                  `protobuf:"varint,5,opt,name=retain_until,json=retainUntil,proto3" json:"retain_until,omitempty"`   // set at upload, read only
+	StorageTier       string                 `protobuf:"bytes,6,opt,name=storage_tier,json=storageTier,proto3" json:"storage_tier,omitempty"`    // tier of the chunk servers holding the file's chunks, empty for any
+	StorageClass      string                 `protobuf:"bytes,7,opt,name=storage_class,json=storageClass,proto3" json:"storage_class,omitempty"` // standard, reduced-redundancy or archive
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *FileAttributes) Reset() {
+	*x = FileAttributes{}
+	mi := &file_proto_dfs_proto_msgTypes[52]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileAttributes) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileAttributes) ProtoMessage() {}
+
+func (x *FileAttributes) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[52]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileAttributes.ProtoReflect.Descriptor instead.
+func (*FileAttributes) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{52}
+}
+
+func (x *FileAttributes) GetTags() map[string]string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *FileAttributes) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+func (x *FileAttributes) GetReplicationFactor() int32 {
+	if x != nil {
+		return x.ReplicationFactor
+	}
+	return 0
+}
+
+func (x *FileAttributes) GetImmutable() bool {
+	if x != nil {
+		return x.Immutable
+	}
+	return false
+}
+
+func (x *FileAttributes) GetRetainUntil() int64 {
+	if x != nil {
+		return x.RetainUntil
+	}
+	return 0
+}
+
+func (x *FileAttributes) GetStorageTier() string {
+	if x != nil {
+		return x.StorageTier
+	}
+	return ""
+}
+
+func (x *FileAttributes) GetStorageClass() string {
+	if x != nil {
+		return x.StorageClass
+	}
+	return ""
+}
+
+type GetFileAttributesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Filename      string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetFileAttributesRequest) Reset() {
+	*x = GetFileAttributesRequest{}
+	mi := &file_proto_dfs_proto_msgTypes[53]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetFileAttributesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetFileAttributesRequest) ProtoMessage() {}
+
+func (x *GetFileAttributesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[53]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetFileAttributesRequest.ProtoReflect.Descriptor instead.
+func (*GetFileAttributesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{53}
+}
+
+func (x *GetFileAttributesRequest) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+type GetFileAttributesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Attributes    *FileAttributes        `protobuf:"bytes,1,opt,name=attributes,proto3" json:"attributes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetFileAttributesResponse) Reset() {
+	*x = GetFileAttributesResponse{}
+	mi := &file_proto_dfs_proto_msgTypes[54]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetFileAttributesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetFileAttributesResponse) ProtoMessage() {}
+
+func (x *GetFileAttributesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[54]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetFileAttributesResponse.ProtoReflect.Descriptor instead.
+func (*GetFileAttributesResponse) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{54}
+}
+
+func (x *GetFileAttributesResponse) GetAttributes() *FileAttributes {
+	if x != nil {
+		return x.Attributes
+	}
+	return nil
+}
+
+// SetFileAttributesRequest changes the attributes that are set, leaving the others unchanged
+type SetFileAttributesRequest struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Filename          string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	SetTags           map[string]string      `protobuf:"bytes,2,rep,name=set_tags,json=setTags,proto3" json:"set_tags,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	RemoveTags        []string               `protobuf:"bytes,3,rep,name=remove_tags,json=removeTags,proto3" json:"remove_tags,omitempty"`
+	TtlSeconds        *int64                 `protobuf:"varint,4,opt,name=ttl_seconds,json=ttlSeconds,proto3,oneof" json:"ttl_seconds,omitempty"`                      // delete the file this long from now, 0 removes the expiry
+	ReplicationFactor *int32                 `protobuf:"varint,5,opt,name=replication_factor,json=replicationFactor,proto3,oneof" json:"replication_factor,omitempty"` // 0 restores the default
+	StorageTier       *string                `protobuf:"bytes,6,opt,name=storage_tier,json=storageTier,proto3,oneof" json:"storage_tier,omitempty"`                    // chunks are moved to the new tier in background, empty allows any
+	StorageClass      *string                `protobuf:"bytes,7,opt,name=storage_class,json=storageClass,proto3,oneof" json:"storage_class,omitempty"`                 // sets the class's replication factor unless replication_factor is set too, the file is converted in background
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *SetFileAttributesRequest) Reset() {
+	*x = SetFileAttributesRequest{}
+	mi := &file_proto_dfs_proto_msgTypes[55]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetFileAttributesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetFileAttributesRequest) ProtoMessage() {}
+
+func (x *SetFileAttributesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[55]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetFileAttributesRequest.ProtoReflect.Descriptor instead.
+func (*SetFileAttributesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{55}
+}
+
+func (x *SetFileAttributesRequest) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *SetFileAttributesRequest) GetSetTags() map[string]string {
+	if x != nil {
+		return x.SetTags
+	}
+	return nil
+}
+
+func (x *SetFileAttributesRequest) GetRemoveTags() []string {
+	if x != nil {
+		return x.RemoveTags
+	}
+	return nil
+}
+
+func (x *SetFileAttributesRequest) GetTtlSeconds() int64 {
+	if x != nil && x.TtlSeconds != nil {
+		return *x.TtlSeconds
+	}
+	return 0
+}
+
+func (x *SetFileAttributesRequest) GetReplicationFactor() int32 {
+	if x != nil && x.ReplicationFactor != nil {
+		return *x.ReplicationFactor
+	}
+	return 0
+}
+
+func (x *SetFileAttributesRequest) GetStorageTier() string {
+	if x != nil && x.StorageTier != nil {
+		return *x.StorageTier
+	}
+	return ""
+}
+
+func (x *SetFileAttributesRequest) GetStorageClass() string {
+	if x != nil && x.StorageClass != nil {
+		return *x.StorageClass
+	}
+	return ""
+}
+
+type SetFileAttributesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Attributes    *FileAttributes        `protobuf:"bytes,1,opt,name=attributes,proto3" json:"attributes,omitempty"` // attributes after the change
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetFileAttributesResponse) Reset() {
+	*x = SetFileAttributesResponse{}
+	mi := &file_proto_dfs_proto_msgTypes[56]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetFileAttributesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetFileAttributesResponse) ProtoMessage() {}
+
+func (x *SetFileAttributesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[56]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetFileAttributesResponse.ProtoReflect.Descriptor instead.
+func (*SetFileAttributesResponse) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{56}
+}
+
+func (x *SetFileAttributesResponse) GetAttributes() *FileAttributes {
+	if x != nil {
+		return x.Attributes
+	}
+	return nil
+}
+
+type DiskUsageRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Prefix        string                 `protobuf:"bytes,1,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DiskUsageRequest) Reset() {
+	*x = DiskUsageRequest{}
+	mi := &file_proto_dfs_proto_msgTypes[57]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DiskUsageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiskUsageRequest) ProtoMessage() {}
+
+func (x *DiskUsageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[57]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiskUsageRequest.ProtoReflect.Descriptor instead.
+func (*DiskUsageRequest) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{57}
+}
+
+func (x *DiskUsageRequest) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+type DiskUsageEntry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	LogicalBytes  int64                  `protobuf:"varint,2,opt,name=logical_bytes,json=logicalBytes,proto3" json:"logical_bytes,omitempty"`
+	PhysicalBytes int64                  `protobuf:"varint,3,opt,name=physical_bytes,json=physicalBytes,proto3" json:"physical_bytes,omitempty"` // logical bytes multiplied by the replicas actually stored
+	FileCount     int64                  `protobuf:"varint,4,opt,name=file_count,json=fileCount,proto3" json:"file_count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DiskUsageEntry) Reset() {
+	*x = DiskUsageEntry{}
+	mi := &file_proto_dfs_proto_msgTypes[58]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DiskUsageEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiskUsageEntry) ProtoMessage() {}
+
+func (x *DiskUsageEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[58]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiskUsageEntry.ProtoReflect.Descriptor instead.
+func (*DiskUsageEntry) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{58}
+}
+
+func (x *DiskUsageEntry) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *DiskUsageEntry) GetLogicalBytes() int64 {
+	if x != nil {
+		return x.LogicalBytes
+	}
+	return 0
+}
+
+func (x *DiskUsageEntry) GetPhysicalBytes() int64 {
+	if x != nil {
+		return x.PhysicalBytes
+	}
+	return 0
+}
+
+func (x *DiskUsageEntry) GetFileCount() int64 {
+	if x != nil {
+		return x.FileCount
+	}
+	return 0
+}
+
+type DiskUsageResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Total         *DiskUsageEntry        `protobuf:"bytes,1,opt,name=total,proto3" json:"total,omitempty"`
+	Entries       []*DiskUsageEntry      `protobuf:"bytes,2,rep,name=entries,proto3" json:"entries,omitempty"` // one entry per file or directory directly under the prefix
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DiskUsageResponse) Reset() {
+	*x = DiskUsageResponse{}
+	mi := &file_proto_dfs_proto_msgTypes[59]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DiskUsageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiskUsageResponse) ProtoMessage() {}
+
+func (x *DiskUsageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[59]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiskUsageResponse.ProtoReflect.Descriptor instead.
+func (*DiskUsageResponse) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{59}
+}
+
+func (x *DiskUsageResponse) GetTotal() *DiskUsageEntry {
+	if x != nil {
+		return x.Total
+	}
+	return nil
+}
+
+func (x *DiskUsageResponse) GetEntries() []*DiskUsageEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+type ListUnaccessedFilesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	IdleSeconds   int64                  `protobuf:"varint,1,opt,name=idle_seconds,json=idleSeconds,proto3" json:"idle_seconds,omitempty"` // only files not read, or created when never read, for at least this long
+	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`                                // maximum number of files returned, 0 returns all
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListUnaccessedFilesRequest) Reset() {
+	*x = ListUnaccessedFilesRequest{}
+	mi := &file_proto_dfs_proto_msgTypes[60]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListUnaccessedFilesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListUnaccessedFilesRequest) ProtoMessage() {}
+
+func (x *ListUnaccessedFilesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[60]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListUnaccessedFilesRequest.ProtoReflect.Descriptor instead.
+func (*ListUnaccessedFilesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{60}
+}
+
+func (x *ListUnaccessedFilesRequest) GetIdleSeconds() int64 {
+	if x != nil {
+		return x.IdleSeconds
+	}
+	return 0
+}
+
+func (x *ListUnaccessedFilesRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ListUnaccessedFilesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Files         []*FileInfo            `protobuf:"bytes,1,rep,name=files,proto3" json:"files,omitempty"` // largest first
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListUnaccessedFilesResponse) Reset() {
+	*x = ListUnaccessedFilesResponse{}
+	mi := &file_proto_dfs_proto_msgTypes[61]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListUnaccessedFilesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListUnaccessedFilesResponse) ProtoMessage() {}
+
+func (x *ListUnaccessedFilesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[61]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListUnaccessedFilesResponse.ProtoReflect.Descriptor instead.
+func (*ListUnaccessedFilesResponse) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{61}
+}
+
+func (x *ListUnaccessedFilesResponse) GetFiles() []*FileInfo {
+	if x != nil {
+		return x.Files
+	}
+	return nil
+}
+
+type GetChunkDistributionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetChunkDistributionRequest) Reset() {
+	*x = GetChunkDistributionRequest{}
+	mi := &file_proto_dfs_proto_msgTypes[62]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetChunkDistributionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetChunkDistributionRequest) ProtoMessage() {}
+
+func (x *GetChunkDistributionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[62]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetChunkDistributionRequest.ProtoReflect.Descriptor instead.
+func (*GetChunkDistributionRequest) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{62}
+}
+
+type ChunkServerUsage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Address       string                 `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	ChunkCount    int64                  `protobuf:"varint,2,opt,name=chunk_count,json=chunkCount,proto3" json:"chunk_count,omitempty"`
+	Bytes         int64                  `protobuf:"varint,3,opt,name=bytes,proto3" json:"bytes,omitempty"`
+	CapacityBytes int64                  `protobuf:"varint,4,opt,name=capacity_bytes,json=capacityBytes,proto3" json:"capacity_bytes,omitempty"`
+	FreeBytes     int64                  `protobuf:"varint,5,opt,name=free_bytes,json=freeBytes,proto3" json:"free_bytes,omitempty"`
+	State         string                 `protobuf:"bytes,6,opt,name=state,proto3" json:"state,omitempty"`                                       // ALIVE or DEAD
+	LastHeartbeat int64                  `protobuf:"varint,7,opt,name=last_heartbeat,json=lastHeartbeat,proto3" json:"last_heartbeat,omitempty"` // unix time in seconds
+	Version       string                 `protobuf:"bytes,8,opt,name=version,proto3" json:"version,omitempty"`                                   // software version the chunk server registered with, empty if it never registered
+	Labels        map[string]string      `protobuf:"bytes,9,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChunkServerUsage) Reset() {
+	*x = ChunkServerUsage{}
+	mi := &file_proto_dfs_proto_msgTypes[63]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChunkServerUsage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChunkServerUsage) ProtoMessage() {}
+
+func (x *ChunkServerUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[63]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChunkServerUsage.ProtoReflect.Descriptor instead.
+func (*ChunkServerUsage) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{63}
+}
+
+func (x *ChunkServerUsage) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *ChunkServerUsage) GetChunkCount() int64 {
+	if x != nil {
+		return x.ChunkCount
+	}
+	return 0
+}
+
+func (x *ChunkServerUsage) GetBytes() int64 {
+	if x != nil {
+		return x.Bytes
+	}
+	return 0
+}
+
+func (x *ChunkServerUsage) GetCapacityBytes() int64 {
+	if x != nil {
+		return x.CapacityBytes
+	}
+	return 0
+}
+
+func (x *ChunkServerUsage) GetFreeBytes() int64 {
+	if x != nil {
+		return x.FreeBytes
+	}
+	return 0
+}
+
+func (x *ChunkServerUsage) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *ChunkServerUsage) GetLastHeartbeat() int64 {
+	if x != nil {
+		return x.LastHeartbeat
+	}
+	return 0
+}
+
+func (x *ChunkServerUsage) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *ChunkServerUsage) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+type ReplicationBucket struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Replicas      int32                  `protobuf:"varint,1,opt,name=replicas,proto3" json:"replicas,omitempty"`
+	ChunkCount    int64                  `protobuf:"varint,2,opt,name=chunk_count,json=chunkCount,proto3" json:"chunk_count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReplicationBucket) Reset() {
+	*x = ReplicationBucket{}
+	mi := &file_proto_dfs_proto_msgTypes[64]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplicationBucket) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplicationBucket) ProtoMessage() {}
+
+func (x *ReplicationBucket) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[64]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplicationBucket.ProtoReflect.Descriptor instead.
+func (*ReplicationBucket) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{64}
+}
+
+func (x *ReplicationBucket) GetReplicas() int32 {
+	if x != nil {
+		return x.Replicas
+	}
+	return 0
+}
+
+func (x *ReplicationBucket) GetChunkCount() int64 {
+	if x != nil {
+		return x.ChunkCount
+	}
+	return 0
+}
+
+type GetChunkDistributionResponse struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	Servers              []*ChunkServerUsage    `protobuf:"bytes,1,rep,name=servers,proto3" json:"servers,omitempty"`
+	ReplicationHistogram []*ReplicationBucket   `protobuf:"bytes,2,rep,name=replication_histogram,json=replicationHistogram,proto3" json:"replication_histogram,omitempty"` // ordered by number of replicas
+	ReplicationFactor    int32                  `protobuf:"varint,3,opt,name=replication_factor,json=replicationFactor,proto3" json:"replication_factor,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *GetChunkDistributionResponse) Reset() {
+	*x = GetChunkDistributionResponse{}
+	mi := &file_proto_dfs_proto_msgTypes[65]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetChunkDistributionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetChunkDistributionResponse) ProtoMessage() {}
+
+func (x *GetChunkDistributionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[65]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetChunkDistributionResponse.ProtoReflect.Descriptor instead.
+func (*GetChunkDistributionResponse) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{65}
+}
+
+func (x *GetChunkDistributionResponse) GetServers() []*ChunkServerUsage {
+	if x != nil {
+		return x.Servers
+	}
+	return nil
+}
+
+func (x *GetChunkDistributionResponse) GetReplicationHistogram() []*ReplicationBucket {
+	if x != nil {
+		return x.ReplicationHistogram
+	}
+	return nil
+}
+
+func (x *GetChunkDistributionResponse) GetReplicationFactor() int32 {
+	if x != nil {
+		return x.ReplicationFactor
+	}
+	return 0
+}
+
+type GetClusterStatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetClusterStatsRequest) Reset() {
+	*x = GetClusterStatsRequest{}
+	mi := &file_proto_dfs_proto_msgTypes[66]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetClusterStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetClusterStatsRequest) ProtoMessage() {}
+
+func (x *GetClusterStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[66]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetClusterStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetClusterStatsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{66}
+}
+
+type GetClusterStatsResponse struct {
+	state                    protoimpl.MessageState `protogen:"open.v1"`
+	CapacityBytes            int64                  `protobuf:"varint,1,opt,name=capacity_bytes,json=capacityBytes,proto3" json:"capacity_bytes,omitempty"` // summed over live chunk servers
+	UsedBytes                int64                  `protobuf:"varint,2,opt,name=used_bytes,json=usedBytes,proto3" json:"used_bytes,omitempty"`
+	FreeBytes                int64                  `protobuf:"varint,3,opt,name=free_bytes,json=freeBytes,proto3" json:"free_bytes,omitempty"`
+	FileCount                int64                  `protobuf:"varint,4,opt,name=file_count,json=fileCount,proto3" json:"file_count,omitempty"`
+	ChunkCount               int64                  `protobuf:"varint,5,opt,name=chunk_count,json=chunkCount,proto3" json:"chunk_count,omitempty"`
+	LiveChunkServers         int32                  `protobuf:"varint,6,opt,name=live_chunk_servers,json=liveChunkServers,proto3" json:"live_chunk_servers,omitempty"`
+	DeadChunkServers         int32                  `protobuf:"varint,7,opt,name=dead_chunk_servers,json=deadChunkServers,proto3" json:"dead_chunk_servers,omitempty"`
+	UnderReplicatedChunks    int64                  `protobuf:"varint,8,opt,name=under_replicated_chunks,json=underReplicatedChunks,proto3" json:"under_replicated_chunks,omitempty"`          // chunks with fewer replicas than the replication factor
+	PendingReclamationChunks int64                  `protobuf:"varint,9,opt,name=pending_reclamation_chunks,json=pendingReclamationChunks,proto3" json:"pending_reclamation_chunks,omitempty"` // deleted chunks whose replicas are kept until the reclaim delay passes
+	unknownFields            protoimpl.UnknownFields
+	sizeCache                protoimpl.SizeCache
+}
+
+func (x *GetClusterStatsResponse) Reset() {
+	*x = GetClusterStatsResponse{}
+	mi := &file_proto_dfs_proto_msgTypes[67]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetClusterStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetClusterStatsResponse) ProtoMessage() {}
+
+func (x *GetClusterStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[67]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetClusterStatsResponse.ProtoReflect.Descriptor instead.
+func (*GetClusterStatsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{67}
+}
+
+func (x *GetClusterStatsResponse) GetCapacityBytes() int64 {
+	if x != nil {
+		return x.CapacityBytes
+	}
+	return 0
+}
+
+func (x *GetClusterStatsResponse) GetUsedBytes() int64 {
+	if x != nil {
+		return x.UsedBytes
+	}
+	return 0
+}
+
+func (x *GetClusterStatsResponse) GetFreeBytes() int64 {
+	if x != nil {
+		return x.FreeBytes
+	}
+	return 0
+}
+
+func (x *GetClusterStatsResponse) GetFileCount() int64 {
+	if x != nil {
+		return x.FileCount
+	}
+	return 0
+}
+
+func (x *GetClusterStatsResponse) GetChunkCount() int64 {
+	if x != nil {
+		return x.ChunkCount
+	}
+	return 0
+}
+
+func (x *GetClusterStatsResponse) GetLiveChunkServers() int32 {
+	if x != nil {
+		return x.LiveChunkServers
+	}
+	return 0
+}
+
+func (x *GetClusterStatsResponse) GetDeadChunkServers() int32 {
+	if x != nil {
+		return x.DeadChunkServers
+	}
+	return 0
+}
+
+func (x *GetClusterStatsResponse) GetUnderReplicatedChunks() int64 {
+	if x != nil {
+		return x.UnderReplicatedChunks
+	}
+	return 0
+}
+
+func (x *GetClusterStatsResponse) GetPendingReclamationChunks() int64 {
+	if x != nil {
+		return x.PendingReclamationChunks
+	}
+	return 0
+}
+
+type ReclaimDeletedRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReclaimDeletedRequest) Reset() {
+	*x = ReclaimDeletedRequest{}
+	mi := &file_proto_dfs_proto_msgTypes[68]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReclaimDeletedRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReclaimDeletedRequest) ProtoMessage() {}
+
+func (x *ReclaimDeletedRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[68]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReclaimDeletedRequest.ProtoReflect.Descriptor instead.
+func (*ReclaimDeletedRequest) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{68}
+}
+
+type ReclaimDeletedResponse struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	ReclaimedChunks int64                  `protobuf:"varint,1,opt,name=reclaimed_chunks,json=reclaimedChunks,proto3" json:"reclaimed_chunks,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ReclaimDeletedResponse) Reset() {
+	*x = ReclaimDeletedResponse{}
+	mi := &file_proto_dfs_proto_msgTypes[69]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReclaimDeletedResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReclaimDeletedResponse) ProtoMessage() {}
+
+func (x *ReclaimDeletedResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[69]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReclaimDeletedResponse.ProtoReflect.Descriptor instead.
+func (*ReclaimDeletedResponse) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{69}
+}
+
+func (x *ReclaimDeletedResponse) GetReclaimedChunks() int64 {
+	if x != nil {
+		return x.ReclaimedChunks
+	}
+	return 0
+}
+
+type GetGeoReplicationStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetGeoReplicationStatusRequest) Reset() {
+	*x = GetGeoReplicationStatusRequest{}
+	mi := &file_proto_dfs_proto_msgTypes[70]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetGeoReplicationStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetGeoReplicationStatusRequest) ProtoMessage() {}
+
+func (x *GetGeoReplicationStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[70]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetGeoReplicationStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetGeoReplicationStatusRequest) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{70}
+}
+
+type GetGeoReplicationStatusResponse struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Enabled          bool                   `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	RemoteMaster     string                 `protobuf:"bytes,2,opt,name=remote_master,json=remoteMaster,proto3" json:"remote_master,omitempty"`
+	Prefixes         []string               `protobuf:"bytes,3,rep,name=prefixes,proto3" json:"prefixes,omitempty"`
+	ConflictPolicy   string                 `protobuf:"bytes,4,opt,name=conflict_policy,json=conflictPolicy,proto3" json:"conflict_policy,omitempty"`
+	PendingFiles     int64                  `protobuf:"varint,5,opt,name=pending_files,json=pendingFiles,proto3" json:"pending_files,omitempty"`          // files changed here and not mirrored yet
+	LagSeconds       int64                  `protobuf:"varint,6,opt,name=lag_seconds,json=lagSeconds,proto3" json:"lag_seconds,omitempty"`                // age of the oldest change not mirrored yet, 0 when caught up
+	ReplicatedFiles  int64                  `protobuf:"varint,7,opt,name=replicated_files,json=replicatedFiles,proto3" json:"replicated_files,omitempty"` // files copied since the master started
+	ReplicatedBytes  int64                  `protobuf:"varint,8,opt,name=replicated_bytes,json=replicatedBytes,proto3" json:"replicated_bytes,omitempty"`
+	DeletedFiles     int64                  `protobuf:"varint,9,opt,name=deleted_files,json=deletedFiles,proto3" json:"deleted_files,omitempty"`                // remote copies deleted since the master started
+	Conflicts        int64                  `protobuf:"varint,10,opt,name=conflicts,proto3" json:"conflicts,omitempty"`                                         // remote files left alone because they were changed on the remote cluster
+	Failures         int64                  `protobuf:"varint,11,opt,name=failures,proto3" json:"failures,omitempty"`                                           // mirroring attempts that failed and were retried
+	LastReplicatedAt int64                  `protobuf:"varint,12,opt,name=last_replicated_at,json=lastReplicatedAt,proto3" json:"last_replicated_at,omitempty"` // unix time in seconds of the latest mirrored change, 0 never
+	LastError        string                 `protobuf:"bytes,13,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *GetGeoReplicationStatusResponse) Reset() {
+	*x = GetGeoReplicationStatusResponse{}
+	mi := &file_proto_dfs_proto_msgTypes[71]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetGeoReplicationStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetGeoReplicationStatusResponse) ProtoMessage() {}
+
+func (x *GetGeoReplicationStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[71]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetGeoReplicationStatusResponse.ProtoReflect.Descriptor instead.
+func (*GetGeoReplicationStatusResponse) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{71}
+}
+
+func (x *GetGeoReplicationStatusResponse) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *GetGeoReplicationStatusResponse) GetRemoteMaster() string {
+	if x != nil {
+		return x.RemoteMaster
+	}
+	return ""
+}
+
+func (x *GetGeoReplicationStatusResponse) GetPrefixes() []string {
+	if x != nil {
+		return x.Prefixes
+	}
+	return nil
+}
+
+func (x *GetGeoReplicationStatusResponse) GetConflictPolicy() string {
+	if x != nil {
+		return x.ConflictPolicy
+	}
+	return ""
+}
+
+func (x *GetGeoReplicationStatusResponse) GetPendingFiles() int64 {
+	if x != nil {
+		return x.PendingFiles
+	}
+	return 0
+}
+
+func (x *GetGeoReplicationStatusResponse) GetLagSeconds() int64 {
+	if x != nil {
+		return x.LagSeconds
+	}
+	return 0
+}
+
+func (x *GetGeoReplicationStatusResponse) GetReplicatedFiles() int64 {
+	if x != nil {
+		return x.ReplicatedFiles
+	}
+	return 0
+}
+
+func (x *GetGeoReplicationStatusResponse) GetReplicatedBytes() int64 {
+	if x != nil {
+		return x.ReplicatedBytes
+	}
+	return 0
+}
+
+func (x *GetGeoReplicationStatusResponse) GetDeletedFiles() int64 {
+	if x != nil {
+		return x.DeletedFiles
+	}
+	return 0
+}
+
+func (x *GetGeoReplicationStatusResponse) GetConflicts() int64 {
+	if x != nil {
+		return x.Conflicts
+	}
+	return 0
+}
+
+func (x *GetGeoReplicationStatusResponse) GetFailures() int64 {
+	if x != nil {
+		return x.Failures
+	}
+	return 0
+}
+
+func (x *GetGeoReplicationStatusResponse) GetLastReplicatedAt() int64 {
+	if x != nil {
+		return x.LastReplicatedAt
+	}
+	return 0
+}
+
+func (x *GetGeoReplicationStatusResponse) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+type PresignDownloadRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Filename      string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	TtlSeconds    int64                  `protobuf:"varint,2,opt,name=ttl_seconds,json=ttlSeconds,proto3" json:"ttl_seconds,omitempty"` // how long the url stays valid, 0 for an hour
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PresignDownloadRequest) Reset() {
+	*x = PresignDownloadRequest{}
+	mi := &file_proto_dfs_proto_msgTypes[72]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PresignDownloadRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PresignDownloadRequest) ProtoMessage() {}
+
+func (x *PresignDownloadRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[72]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PresignDownloadRequest.ProtoReflect.Descriptor instead.
+func (*PresignDownloadRequest) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{72}
+}
+
+func (x *PresignDownloadRequest) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *PresignDownloadRequest) GetTtlSeconds() int64 {
+	if x != nil {
+		return x.TtlSeconds
+	}
+	return 0
+}
+
+type PresignDownloadResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Url           string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	ExpiresAt     int64                  `protobuf:"varint,2,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"` // unix time in seconds the url stops working
+	Generation    int64                  `protobuf:"varint,3,opt,name=generation,proto3" json:"generation,omitempty"`                // version of the file the url downloads, even once the file is overwritten
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PresignDownloadResponse) Reset() {
+	*x = PresignDownloadResponse{}
+	mi := &file_proto_dfs_proto_msgTypes[73]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PresignDownloadResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PresignDownloadResponse) ProtoMessage() {}
+
+func (x *PresignDownloadResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[73]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PresignDownloadResponse.ProtoReflect.Descriptor instead.
+func (*PresignDownloadResponse) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{73}
+}
+
+func (x *PresignDownloadResponse) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *PresignDownloadResponse) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+func (x *PresignDownloadResponse) GetGeneration() int64 {
+	if x != nil {
+		return x.Generation
+	}
+	return 0
+}
+
+type WhoAmIRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WhoAmIRequest) Reset() {
+	*x = WhoAmIRequest{}
+	mi := &file_proto_dfs_proto_msgTypes[74]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WhoAmIRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WhoAmIRequest) ProtoMessage() {}
+
+func (x *WhoAmIRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[74]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WhoAmIRequest.ProtoReflect.Descriptor instead.
+func (*WhoAmIRequest) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{74}
+}
+
+type WhoAmIResponse struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	User             string                 `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	Groups           []string               `protobuf:"bytes,2,rep,name=groups,proto3" json:"groups,omitempty"`
+	Authenticated    bool                   `protobuf:"varint,3,opt,name=authenticated,proto3" json:"authenticated,omitempty"`                              // false when the master serves the caller anonymously
+	Superuser        bool                   `protobuf:"varint,4,opt,name=superuser,proto3" json:"superuser,omitempty"`                                      // file permissions don't apply to the caller
+	DelegationId     string                 `protobuf:"bytes,5,opt,name=delegation_id,json=delegationId,proto3" json:"delegation_id,omitempty"`             // id of the delegation token the caller used, empty for the user's own token
+	DelegationPrefix string                 `protobuf:"bytes,6,opt,name=delegation_prefix,json=delegationPrefix,proto3" json:"delegation_prefix,omitempty"` // files the delegation token may read
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *WhoAmIResponse) Reset() {
+	*x = WhoAmIResponse{}
+	mi := &file_proto_dfs_proto_msgTypes[75]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WhoAmIResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WhoAmIResponse) ProtoMessage() {}
+
+func (x *WhoAmIResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[75]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WhoAmIResponse.ProtoReflect.Descriptor instead.
+func (*WhoAmIResponse) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{75}
+}
+
+func (x *WhoAmIResponse) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+func (x *WhoAmIResponse) GetGroups() []string {
+	if x != nil {
+		return x.Groups
+	}
+	return nil
+}
+
+func (x *WhoAmIResponse) GetAuthenticated() bool {
+	if x != nil {
+		return x.Authenticated
+	}
+	return false
+}
+
+func (x *WhoAmIResponse) GetSuperuser() bool {
+	if x != nil {
+		return x.Superuser
+	}
+	return false
+}
+
+func (x *WhoAmIResponse) GetDelegationId() string {
+	if x != nil {
+		return x.DelegationId
+	}
+	return ""
+}
+
+func (x *WhoAmIResponse) GetDelegationPrefix() string {
+	if x != nil {
+		return x.DelegationPrefix
+	}
+	return ""
+}
+
+type GetDelegationTokenRequest struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Prefix             string                 `protobuf:"bytes,1,opt,name=prefix,proto3" json:"prefix,omitempty"`                                                      // only files whose name starts with it may be read with the token, empty for any file
+	TtlSeconds         int64                  `protobuf:"varint,2,opt,name=ttl_seconds,json=ttlSeconds,proto3" json:"ttl_seconds,omitempty"`                           // how long the token is valid until renewed, 0 for a day
+	MaxLifetimeSeconds int64                  `protobuf:"varint,3,opt,name=max_lifetime_seconds,json=maxLifetimeSeconds,proto3" json:"max_lifetime_seconds,omitempty"` // how long renewals may keep the token valid, 0 for 7 days, at most 30 days
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *GetDelegationTokenRequest) Reset() {
+	*x = GetDelegationTokenRequest{}
+	mi := &file_proto_dfs_proto_msgTypes[76]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDelegationTokenRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDelegationTokenRequest) ProtoMessage() {}
+
+func (x *GetDelegationTokenRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[76]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDelegationTokenRequest.ProtoReflect.Descriptor instead.
+func (*GetDelegationTokenRequest) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{76}
+}
+
+func (x *GetDelegationTokenRequest) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+func (x *GetDelegationTokenRequest) GetTtlSeconds() int64 {
+	if x != nil {
+		return x.TtlSeconds
+	}
+	return 0
+}
+
+func (x *GetDelegationTokenRequest) GetMaxLifetimeSeconds() int64 {
+	if x != nil {
+		return x.MaxLifetimeSeconds
+	}
+	return 0
+}
+
+type GetDelegationTokenResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`                                            // names the token in logs and revocations
+	ExpiresAt     int64                  `protobuf:"varint,3,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`            // unix time in seconds the token must be renewed by
+	MaxExpiresAt  int64                  `protobuf:"varint,4,opt,name=max_expires_at,json=maxExpiresAt,proto3" json:"max_expires_at,omitempty"` // unix time in seconds past which the token can't be renewed
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDelegationTokenResponse) Reset() {
+	*x = GetDelegationTokenResponse{}
+	mi := &file_proto_dfs_proto_msgTypes[77]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDelegationTokenResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDelegationTokenResponse) ProtoMessage() {}
+
+func (x *GetDelegationTokenResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[77]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDelegationTokenResponse.ProtoReflect.Descriptor instead.
+func (*GetDelegationTokenResponse) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{77}
+}
+
+func (x *GetDelegationTokenResponse) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *GetDelegationTokenResponse) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *GetDelegationTokenResponse) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+func (x *GetDelegationTokenResponse) GetMaxExpiresAt() int64 {
+	if x != nil {
+		return x.MaxExpiresAt
+	}
+	return 0
+}
+
+type RenewDelegationTokenRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	TtlSeconds    int64                  `protobuf:"varint,2,opt,name=ttl_seconds,json=ttlSeconds,proto3" json:"ttl_seconds,omitempty"` // how long the renewed token is valid, 0 for a day
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RenewDelegationTokenRequest) Reset() {
+	*x = RenewDelegationTokenRequest{}
+	mi := &file_proto_dfs_proto_msgTypes[78]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RenewDelegationTokenRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenewDelegationTokenRequest) ProtoMessage() {}
+
+func (x *RenewDelegationTokenRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[78]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenewDelegationTokenRequest.ProtoReflect.Descriptor instead.
+func (*RenewDelegationTokenRequest) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{78}
+}
+
+func (x *RenewDelegationTokenRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *RenewDelegationTokenRequest) GetTtlSeconds() int64 {
+	if x != nil {
+		return x.TtlSeconds
+	}
+	return 0
+}
+
+type RenewDelegationTokenResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"` // replaces the renewed token, which stays valid until it expires
+	ExpiresAt     int64                  `protobuf:"varint,2,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RenewDelegationTokenResponse) Reset() {
+	*x = RenewDelegationTokenResponse{}
+	mi := &file_proto_dfs_proto_msgTypes[79]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RenewDelegationTokenResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenewDelegationTokenResponse) ProtoMessage() {}
+
+func (x *RenewDelegationTokenResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[79]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenewDelegationTokenResponse.ProtoReflect.Descriptor instead.
+func (*RenewDelegationTokenResponse) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{79}
+}
+
+func (x *RenewDelegationTokenResponse) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *RenewDelegationTokenResponse) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+type RevokeDelegationTokenRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"` // token to revoke, revoked by its owner or a superuser
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`       // id of the token to revoke when the token itself isn't at hand, superusers only
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeDelegationTokenRequest) Reset() {
+	*x = RevokeDelegationTokenRequest{}
+	mi := &file_proto_dfs_proto_msgTypes[80]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeDelegationTokenRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeDelegationTokenRequest) ProtoMessage() {}
+
+func (x *RevokeDelegationTokenRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[80]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeDelegationTokenRequest.ProtoReflect.Descriptor instead.
+func (*RevokeDelegationTokenRequest) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{80}
+}
+
+func (x *RevokeDelegationTokenRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *RevokeDelegationTokenRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type RevokeDelegationTokenResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeDelegationTokenResponse) Reset() {
+	*x = RevokeDelegationTokenResponse{}
+	mi := &file_proto_dfs_proto_msgTypes[81]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeDelegationTokenResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeDelegationTokenResponse) ProtoMessage() {}
+
+func (x *RevokeDelegationTokenResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[81]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeDelegationTokenResponse.ProtoReflect.Descriptor instead.
+func (*RevokeDelegationTokenResponse) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{81}
+}
+
+type SetFileModeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Filename      string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	Mode          uint32                 `protobuf:"varint,2,opt,name=mode,proto3" json:"mode,omitempty"` // permission bits, e.g. 0640
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetFileModeRequest) Reset() {
+	*x = SetFileModeRequest{}
+	mi := &file_proto_dfs_proto_msgTypes[82]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetFileModeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetFileModeRequest) ProtoMessage() {}
+
+func (x *SetFileModeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[82]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetFileModeRequest.ProtoReflect.Descriptor instead.
+func (*SetFileModeRequest) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{82}
+}
+
+func (x *SetFileModeRequest) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *SetFileModeRequest) GetMode() uint32 {
+	if x != nil {
+		return x.Mode
+	}
+	return 0
+}
+
+type SetFileModeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	File          *FileInfo              `protobuf:"bytes,1,opt,name=file,proto3" json:"file,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetFileModeResponse) Reset() {
+	*x = SetFileModeResponse{}
+	mi := &file_proto_dfs_proto_msgTypes[83]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetFileModeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetFileModeResponse) ProtoMessage() {}
+
+func (x *SetFileModeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[83]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetFileModeResponse.ProtoReflect.Descriptor instead.
+func (*SetFileModeResponse) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{83}
+}
+
+func (x *SetFileModeResponse) GetFile() *FileInfo {
+	if x != nil {
+		return x.File
+	}
+	return nil
+}
+
+type SetFileOwnerRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Filename      string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	Owner         string                 `protobuf:"bytes,2,opt,name=owner,proto3" json:"owner,omitempty"` // new owner, empty keeps the owner
+	Group         string                 `protobuf:"bytes,3,opt,name=group,proto3" json:"group,omitempty"` // new group, empty keeps the group
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetFileOwnerRequest) Reset() {
+	*x = SetFileOwnerRequest{}
+	mi := &file_proto_dfs_proto_msgTypes[84]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetFileOwnerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetFileOwnerRequest) ProtoMessage() {}
+
+func (x *SetFileOwnerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[84]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetFileOwnerRequest.ProtoReflect.Descriptor instead.
+func (*SetFileOwnerRequest) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{84}
+}
+
+func (x *SetFileOwnerRequest) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *SetFileOwnerRequest) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *SetFileOwnerRequest) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+type SetFileOwnerResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	File          *FileInfo              `protobuf:"bytes,1,opt,name=file,proto3" json:"file,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetFileOwnerResponse) Reset() {
+	*x = SetFileOwnerResponse{}
+	mi := &file_proto_dfs_proto_msgTypes[85]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetFileOwnerResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetFileOwnerResponse) ProtoMessage() {}
+
+func (x *SetFileOwnerResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[85]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetFileOwnerResponse.ProtoReflect.Descriptor instead.
+func (*SetFileOwnerResponse) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{85}
+}
+
+func (x *SetFileOwnerResponse) GetFile() *FileInfo {
+	if x != nil {
+		return x.File
+	}
+	return nil
+}
+
+type SymlinkFileRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	LinkName      string                 `protobuf:"bytes,1,opt,name=link_name,json=linkName,proto3" json:"link_name,omitempty"`
+	Target        string                 `protobuf:"bytes,2,opt,name=target,proto3" json:"target,omitempty"`        // full name of the file the link points at, which doesn't have to exist
+	Overwrite     bool                   `protobuf:"varint,3,opt,name=overwrite,proto3" json:"overwrite,omitempty"` // replace a file already using the link's name instead of failing with AlreadyExists
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SymlinkFileRequest) Reset() {
+	*x = SymlinkFileRequest{}
+	mi := &file_proto_dfs_proto_msgTypes[86]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SymlinkFileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SymlinkFileRequest) ProtoMessage() {}
+
+func (x *SymlinkFileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[86]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SymlinkFileRequest.ProtoReflect.Descriptor instead.
+func (*SymlinkFileRequest) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{86}
+}
+
+func (x *SymlinkFileRequest) GetLinkName() string {
+	if x != nil {
+		return x.LinkName
+	}
+	return ""
+}
+
+func (x *SymlinkFileRequest) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+func (x *SymlinkFileRequest) GetOverwrite() bool {
+	if x != nil {
+		return x.Overwrite
+	}
+	return false
+}
+
+type SymlinkFileResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Generation    int64                  `protobuf:"varint,1,opt,name=generation,proto3" json:"generation,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SymlinkFileResponse) Reset() {
+	*x = SymlinkFileResponse{}
+	mi := &file_proto_dfs_proto_msgTypes[87]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SymlinkFileResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SymlinkFileResponse) ProtoMessage() {}
+
+func (x *SymlinkFileResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[87]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SymlinkFileResponse.ProtoReflect.Descriptor instead.
+func (*SymlinkFileResponse) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{87}
+}
+
+func (x *SymlinkFileResponse) GetGeneration() int64 {
+	if x != nil {
+		return x.Generation
+	}
+	return 0
+}
+
+type LinkFileRequest struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	SourceFilename      string                 `protobuf:"bytes,1,opt,name=source_filename,json=sourceFilename,proto3" json:"source_filename,omitempty"`
+	DestinationFilename string                 `protobuf:"bytes,2,opt,name=destination_filename,json=destinationFilename,proto3" json:"destination_filename,omitempty"` // must not exist
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *LinkFileRequest) Reset() {
+	*x = LinkFileRequest{}
+	mi := &file_proto_dfs_proto_msgTypes[88]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LinkFileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LinkFileRequest) ProtoMessage() {}
+
+func (x *LinkFileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[88]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LinkFileRequest.ProtoReflect.Descriptor instead.
+func (*LinkFileRequest) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{88}
+}
+
+func (x *LinkFileRequest) GetSourceFilename() string {
+	if x != nil {
+		return x.SourceFilename
+	}
+	return ""
+}
+
+func (x *LinkFileRequest) GetDestinationFilename() string {
+	if x != nil {
+		return x.DestinationFilename
+	}
+	return ""
+}
+
+type LinkFileResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Generation    int64                  `protobuf:"varint,1,opt,name=generation,proto3" json:"generation,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LinkFileResponse) Reset() {
+	*x = LinkFileResponse{}
+	mi := &file_proto_dfs_proto_msgTypes[89]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LinkFileResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LinkFileResponse) ProtoMessage() {}
+
+func (x *LinkFileResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[89]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LinkFileResponse.ProtoReflect.Descriptor instead.
+func (*LinkFileResponse) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{89}
+}
+
+func (x *LinkFileResponse) GetGeneration() int64 {
+	if x != nil {
+		return x.Generation
+	}
+	return 0
+}
+
+// Messages shared by both services
+type GetServerInfoRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetServerInfoRequest) Reset() {
+	*x = GetServerInfoRequest{}
+	mi := &file_proto_dfs_proto_msgTypes[90]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetServerInfoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetServerInfoRequest) ProtoMessage() {}
+
+func (x *GetServerInfoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[90]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetServerInfoRequest.ProtoReflect.Descriptor instead.
+func (*GetServerInfoRequest) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{90}
+}
+
+type GetServerInfoResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Version       string                 `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"` // semantic software version, "dev" for builds without one
+	Commit        string                 `protobuf:"bytes,2,opt,name=commit,proto3" json:"commit,omitempty"`   // git commit the server was built from, empty if unknown
+	GoVersion     string                 `protobuf:"bytes,3,opt,name=go_version,json=goVersion,proto3" json:"go_version,omitempty"`
+	Features      []string               `protobuf:"bytes,4,rep,name=features,proto3" json:"features,omitempty"` // optional protocol features the server supports, see common.ProtocolFeatures
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetServerInfoResponse) Reset() {
+	*x = GetServerInfoResponse{}
+	mi := &file_proto_dfs_proto_msgTypes[91]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetServerInfoResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetServerInfoResponse) ProtoMessage() {}
+
+func (x *GetServerInfoResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[91]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetServerInfoResponse.ProtoReflect.Descriptor instead.
+func (*GetServerInfoResponse) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{91}
+}
+
+func (x *GetServerInfoResponse) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *GetServerInfoResponse) GetCommit() string {
+	if x != nil {
+		return x.Commit
+	}
+	return ""
+}
+
+func (x *GetServerInfoResponse) GetGoVersion() string {
+	if x != nil {
+		return x.GoVersion
+	}
+	return ""
+}
+
+func (x *GetServerInfoResponse) GetFeatures() []string {
+	if x != nil {
+		return x.Features
+	}
+	return nil
+}
+
+// Messages for ChunkServer Service
+type WriteChunkRequest struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	ChunkHandle        string                 `protobuf:"bytes,1,opt,name=chunk_handle,json=chunkHandle,proto3" json:"chunk_handle,omitempty"`
+	Data               []byte                 `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	ChunkIndex         int32                  `protobuf:"varint,3,opt,name=chunk_index,json=chunkIndex,proto3" json:"chunk_index,omitempty"`
+	ChunkVersion       int32                  `protobuf:"varint,4,opt,name=chunk_version,json=chunkVersion,proto3" json:"chunk_version,omitempty"`
+	Overwrite          bool                   `protobuf:"varint,5,opt,name=overwrite,proto3" json:"overwrite,omitempty"`                                            // replace an existing chunk even without a newer chunk version
+	Checksum           *uint32                `protobuf:"varint,6,opt,name=checksum,proto3,oneof" json:"checksum,omitempty"`                                        // CRC-32C of data, verified before the chunk is stored
+	SecondaryAddresses []string               `protobuf:"bytes,7,rep,name=secondary_addresses,json=secondaryAddresses,proto3" json:"secondary_addresses,omitempty"` // sent to the primary, which forwards the chunk to these
+	LeaseExpiresAt     int64                  `protobuf:"varint,8,opt,name=lease_expires_at,json=leaseExpiresAt,proto3" json:"lease_expires_at,omitempty"`          // unix time in nanoseconds the primary's lease expires, 0 for writes to a single replica
+	AccessToken        string                 `protobuf:"bytes,9,opt,name=access_token,json=accessToken,proto3" json:"access_token,omitempty"`                      // write token from the chunk's location, forwarded to the secondaries
+	LeaseToken         string                 `protobuf:"bytes,10,opt,name=lease_token,json=leaseToken,proto3" json:"lease_token,omitempty"`                        // lease token from the chunk's location
+	ForwardToken       string                 `protobuf:"bytes,11,opt,name=forward_token,json=forwardToken,proto3" json:"forward_token,omitempty"`                  // set by the primary forwarding the chunk and by the master, which write without a lease
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *WriteChunkRequest) Reset() {
+	*x = WriteChunkRequest{}
+	mi := &file_proto_dfs_proto_msgTypes[92]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WriteChunkRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WriteChunkRequest) ProtoMessage() {}
+
+func (x *WriteChunkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[92]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WriteChunkRequest.ProtoReflect.Descriptor instead.
+func (*WriteChunkRequest) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{92}
+}
+
+func (x *WriteChunkRequest) GetChunkHandle() string {
+	if x != nil {
+		return x.ChunkHandle
+	}
+	return ""
+}
+
+func (x *WriteChunkRequest) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *WriteChunkRequest) GetChunkIndex() int32 {
+	if x != nil {
+		return x.ChunkIndex
+	}
+	return 0
+}
+
+func (x *WriteChunkRequest) GetChunkVersion() int32 {
+	if x != nil {
+		return x.ChunkVersion
+	}
+	return 0
+}
+
+func (x *WriteChunkRequest) GetOverwrite() bool {
+	if x != nil {
+		return x.Overwrite
+	}
+	return false
+}
+
+func (x *WriteChunkRequest) GetChecksum() uint32 {
+	if x != nil && x.Checksum != nil {
+		return *x.Checksum
+	}
+	return 0
+}
+
+func (x *WriteChunkRequest) GetSecondaryAddresses() []string {
+	if x != nil {
+		return x.SecondaryAddresses
+	}
+	return nil
+}
+
+func (x *WriteChunkRequest) GetLeaseExpiresAt() int64 {
+	if x != nil {
+		return x.LeaseExpiresAt
+	}
+	return 0
+}
+
+func (x *WriteChunkRequest) GetAccessToken() string {
+	if x != nil {
+		return x.AccessToken
+	}
+	return ""
+}
+
+func (x *WriteChunkRequest) GetLeaseToken() string {
+	if x != nil {
+		return x.LeaseToken
+	}
+	return ""
+}
+
+func (x *WriteChunkRequest) GetForwardToken() string {
+	if x != nil {
+		return x.ForwardToken
+	}
+	return ""
+}
+
+type WriteChunkResponse struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Success           bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	FailedSecondaries []string               `protobuf:"bytes,2,rep,name=failed_secondaries,json=failedSecondaries,proto3" json:"failed_secondaries,omitempty"` // secondaries the primary failed to forward the chunk to
+	QueuePressure     float32                `protobuf:"fixed32,3,opt,name=queue_pressure,json=queuePressure,proto3" json:"queue_pressure,omitempty"`           // how full the io queues of the replicas written were, 0 idle to 1 rejecting requests
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *WriteChunkResponse) Reset() {
+	*x = WriteChunkResponse{}
+	mi := &file_proto_dfs_proto_msgTypes[93]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WriteChunkResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WriteChunkResponse) ProtoMessage() {}
+
+func (x *WriteChunkResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[93]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WriteChunkResponse.ProtoReflect.Descriptor instead.
+func (*WriteChunkResponse) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{93}
+}
+
+func (x *WriteChunkResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *WriteChunkResponse) GetFailedSecondaries() []string {
+	if x != nil {
+		return x.FailedSecondaries
+	}
+	return nil
+}
+
+func (x *WriteChunkResponse) GetQueuePressure() float32 {
+	if x != nil {
+		return x.QueuePressure
+	}
+	return 0
+}
+
+type ReadChunkRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ChunkHandle   string                 `protobuf:"bytes,1,opt,name=chunk_handle,json=chunkHandle,proto3" json:"chunk_handle,omitempty"`
+	Offset        int64                  `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`                             // byte offset inside the chunk
+	Length        int64                  `protobuf:"varint,3,opt,name=length,proto3" json:"length,omitempty"`                             // bytes to read, 0 reads to the end of the chunk
+	AccessToken   string                 `protobuf:"bytes,4,opt,name=access_token,json=accessToken,proto3" json:"access_token,omitempty"` // read token from the chunk's location
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReadChunkRequest) Reset() {
+	*x = ReadChunkRequest{}
+	mi := &file_proto_dfs_proto_msgTypes[94]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReadChunkRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadChunkRequest) ProtoMessage() {}
+
+func (x *ReadChunkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[94]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadChunkRequest.ProtoReflect.Descriptor instead.
+func (*ReadChunkRequest) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{94}
+}
+
+func (x *ReadChunkRequest) GetChunkHandle() string {
+	if x != nil {
+		return x.ChunkHandle
+	}
+	return ""
+}
+
+func (x *ReadChunkRequest) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *ReadChunkRequest) GetLength() int64 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+func (x *ReadChunkRequest) GetAccessToken() string {
+	if x != nil {
+		return x.AccessToken
+	}
+	return ""
+}
+
+type ReadChunkResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Data          []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	Checksum      *uint32                `protobuf:"varint,2,opt,name=checksum,proto3,oneof" json:"checksum,omitempty"` // CRC-32C of all the data read, streams send it alone in their last message
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReadChunkResponse) Reset() {
+	*x = ReadChunkResponse{}
+	mi := &file_proto_dfs_proto_msgTypes[95]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReadChunkResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadChunkResponse) ProtoMessage() {}
+
+func (x *ReadChunkResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[95]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadChunkResponse.ProtoReflect.Descriptor instead.
+func (*ReadChunkResponse) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{95}
+}
+
+func (x *ReadChunkResponse) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *ReadChunkResponse) GetChecksum() uint32 {
+	if x != nil && x.Checksum != nil {
+		return *x.Checksum
+	}
+	return 0
+}
+
+type CopyChunkRequest struct {
+	state                  protoimpl.MessageState `protogen:"open.v1"`
+	SourceChunkHandle      string                 `protobuf:"bytes,1,opt,name=source_chunk_handle,json=sourceChunkHandle,proto3" json:"source_chunk_handle,omitempty"`
+	DestinationChunkHandle string                 `protobuf:"bytes,2,opt,name=destination_chunk_handle,json=destinationChunkHandle,proto3" json:"destination_chunk_handle,omitempty"`
+	ChunkVersion           int32                  `protobuf:"varint,3,opt,name=chunk_version,json=chunkVersion,proto3" json:"chunk_version,omitempty"`                 // version of the copy, 0 keeps the source version
+	AccessToken            string                 `protobuf:"bytes,4,opt,name=access_token,json=accessToken,proto3" json:"access_token,omitempty"`                     // write token of the destination chunk
+	SourceAccessToken      string                 `protobuf:"bytes,5,opt,name=source_access_token,json=sourceAccessToken,proto3" json:"source_access_token,omitempty"` // read token of the source chunk
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *CopyChunkRequest) Reset() {
+	*x = CopyChunkRequest{}
+	mi := &file_proto_dfs_proto_msgTypes[96]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CopyChunkRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CopyChunkRequest) ProtoMessage() {}
+
+func (x *CopyChunkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[96]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CopyChunkRequest.ProtoReflect.Descriptor instead.
+func (*CopyChunkRequest) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{96}
+}
+
+func (x *CopyChunkRequest) GetSourceChunkHandle() string {
+	if x != nil {
+		return x.SourceChunkHandle
+	}
+	return ""
+}
+
+func (x *CopyChunkRequest) GetDestinationChunkHandle() string {
+	if x != nil {
+		return x.DestinationChunkHandle
+	}
+	return ""
+}
+
+func (x *CopyChunkRequest) GetChunkVersion() int32 {
+	if x != nil {
+		return x.ChunkVersion
+	}
+	return 0
+}
+
+func (x *CopyChunkRequest) GetAccessToken() string {
+	if x != nil {
+		return x.AccessToken
+	}
+	return ""
+}
+
+func (x *CopyChunkRequest) GetSourceAccessToken() string {
+	if x != nil {
+		return x.SourceAccessToken
+	}
+	return ""
+}
+
+type CopyChunkResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Filename      string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
-	Filesize      int64                  `protobuf:"varint,2,opt,name=filesize,proto3" json:"filesize,omitempty"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UploadFileRequest) Reset() {
-	*x = UploadFileRequest{}
-	mi := &file_proto_dfs_proto_msgTypes[0]
+func (x *CopyChunkResponse) Reset() {
+	*x = CopyChunkResponse{}
+	mi := &file_proto_dfs_proto_msgTypes[97]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UploadFileRequest) String() string {
+func (x *CopyChunkResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UploadFileRequest) ProtoMessage() {}
+func (*CopyChunkResponse) ProtoMessage() {}
 
-func (x *UploadFileRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_dfs_proto_msgTypes[0]
+func (x *CopyChunkResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[97]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -55,49 +6168,41 @@ func (x *UploadFileRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UploadFileRequest.ProtoReflect.Descriptor instead.
-func (*UploadFileRequest) Descriptor() ([]byte, []int) {
-	return file_proto_dfs_proto_rawDescGZIP(), []int{0}
-}
-
-func (x *UploadFileRequest) GetFilename() string {
-	if x != nil {
-		return x.Filename
-	}
-	return ""
+// Deprecated: Use CopyChunkResponse.ProtoReflect.Descriptor instead.
+func (*CopyChunkResponse) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{97}
 }
 
-func (x *UploadFileRequest) GetFilesize() int64 {
+func (x *CopyChunkResponse) GetSuccess() bool {
 	if x != nil {
-		return x.Filesize
+		return x.Success
 	}
-	return 0
+	return false
 }
 
-type ChunkLocation struct {
-	state                protoimpl.MessageState `protogen:"open.v1"`
-	ChunkHandle          string                 `protobuf:"bytes,1,opt,name=chunk_handle,json=chunkHandle,proto3" json:"chunk_handle,omitempty"`
-	ChunkServerAddresses []string               `protobuf:"bytes,2,rep,name=chunk_server_addresses,json=chunkServerAddresses,proto3" json:"chunk_server_addresses,omitempty"`
-	ChunkIndex           int32                  `protobuf:"varint,3,opt,name=chunk_index,json=chunkIndex,proto3" json:"chunk_index,omitempty"`
-	unknownFields        protoimpl.UnknownFields
-	sizeCache            protoimpl.SizeCache
+type DeleteChunkRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ChunkHandle   string                 `protobuf:"bytes,1,opt,name=chunk_handle,json=chunkHandle,proto3" json:"chunk_handle,omitempty"`
+	AccessToken   string                 `protobuf:"bytes,2,opt,name=access_token,json=accessToken,proto3" json:"access_token,omitempty"` // delete token, only the master hands these out
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ChunkLocation) Reset() {
-	*x = ChunkLocation{}
-	mi := &file_proto_dfs_proto_msgTypes[1]
+func (x *DeleteChunkRequest) Reset() {
+	*x = DeleteChunkRequest{}
+	mi := &file_proto_dfs_proto_msgTypes[98]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ChunkLocation) String() string {
+func (x *DeleteChunkRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ChunkLocation) ProtoMessage() {}
+func (*DeleteChunkRequest) ProtoMessage() {}
 
-func (x *ChunkLocation) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_dfs_proto_msgTypes[1]
+func (x *DeleteChunkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[98]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -108,54 +6213,47 @@ func (x *ChunkLocation) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ChunkLocation.ProtoReflect.Descriptor instead.
-func (*ChunkLocation) Descriptor() ([]byte, []int) {
-	return file_proto_dfs_proto_rawDescGZIP(), []int{1}
+// Deprecated: Use DeleteChunkRequest.ProtoReflect.Descriptor instead.
+func (*DeleteChunkRequest) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{98}
 }
 
-func (x *ChunkLocation) GetChunkHandle() string {
+func (x *DeleteChunkRequest) GetChunkHandle() string {
 	if x != nil {
 		return x.ChunkHandle
 	}
 	return ""
 }
 
-func (x *ChunkLocation) GetChunkServerAddresses() []string {
-	if x != nil {
-		return x.ChunkServerAddresses
-	}
-	return nil
-}
-
-func (x *ChunkLocation) GetChunkIndex() int32 {
+func (x *DeleteChunkRequest) GetAccessToken() string {
 	if x != nil {
-		return x.ChunkIndex
+		return x.AccessToken
 	}
-	return 0
+	return ""
 }
 
-type UploadFileResponse struct {
-	state          protoimpl.MessageState `protogen:"open.v1"`
-	ChunkLocations []*ChunkLocation       `protobuf:"bytes,1,rep,name=chunk_locations,json=chunkLocations,proto3" json:"chunk_locations,omitempty"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+type DeleteChunkResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UploadFileResponse) Reset() {
-	*x = UploadFileResponse{}
-	mi := &file_proto_dfs_proto_msgTypes[2]
+func (x *DeleteChunkResponse) Reset() {
+	*x = DeleteChunkResponse{}
+	mi := &file_proto_dfs_proto_msgTypes[99]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UploadFileResponse) String() string {
+func (x *DeleteChunkResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UploadFileResponse) ProtoMessage() {}
+func (*DeleteChunkResponse) ProtoMessage() {}
 
-func (x *UploadFileResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_dfs_proto_msgTypes[2]
+func (x *DeleteChunkResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[99]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -166,40 +6264,41 @@ func (x *UploadFileResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UploadFileResponse.ProtoReflect.Descriptor instead.
-func (*UploadFileResponse) Descriptor() ([]byte, []int) {
-	return file_proto_dfs_proto_rawDescGZIP(), []int{2}
+// Deprecated: Use DeleteChunkResponse.ProtoReflect.Descriptor instead.
+func (*DeleteChunkResponse) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{99}
 }
 
-func (x *UploadFileResponse) GetChunkLocations() []*ChunkLocation {
+func (x *DeleteChunkResponse) GetSuccess() bool {
 	if x != nil {
-		return x.ChunkLocations
+		return x.Success
 	}
-	return nil
+	return false
 }
 
-type DownloadFileRequest struct {
+type VerifyChunkRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Filename      string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	ChunkHandle   string                 `protobuf:"bytes,1,opt,name=chunk_handle,json=chunkHandle,proto3" json:"chunk_handle,omitempty"`
+	AccessToken   string                 `protobuf:"bytes,2,opt,name=access_token,json=accessToken,proto3" json:"access_token,omitempty"` // read token
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DownloadFileRequest) Reset() {
-	*x = DownloadFileRequest{}
-	mi := &file_proto_dfs_proto_msgTypes[3]
+func (x *VerifyChunkRequest) Reset() {
+	*x = VerifyChunkRequest{}
+	mi := &file_proto_dfs_proto_msgTypes[100]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DownloadFileRequest) String() string {
+func (x *VerifyChunkRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DownloadFileRequest) ProtoMessage() {}
+func (*VerifyChunkRequest) ProtoMessage() {}
 
-func (x *DownloadFileRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_dfs_proto_msgTypes[3]
+func (x *VerifyChunkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[100]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -210,41 +6309,47 @@ func (x *DownloadFileRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DownloadFileRequest.ProtoReflect.Descriptor instead.
-func (*DownloadFileRequest) Descriptor() ([]byte, []int) {
-	return file_proto_dfs_proto_rawDescGZIP(), []int{3}
+// Deprecated: Use VerifyChunkRequest.ProtoReflect.Descriptor instead.
+func (*VerifyChunkRequest) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{100}
 }
 
-func (x *DownloadFileRequest) GetFilename() string {
+func (x *VerifyChunkRequest) GetChunkHandle() string {
 	if x != nil {
-		return x.Filename
+		return x.ChunkHandle
 	}
 	return ""
 }
 
-type DownloadFileResponse struct {
+func (x *VerifyChunkRequest) GetAccessToken() string {
+	if x != nil {
+		return x.AccessToken
+	}
+	return ""
+}
+
+type VerifyChunkResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Filesize      int64                  `protobuf:"varint,1,opt,name=filesize,proto3" json:"filesize,omitempty"`
-	ChunkLocation []*ChunkLocation       `protobuf:"bytes,2,rep,name=chunk_location,json=chunkLocation,proto3" json:"chunk_location,omitempty"`
+	Corrupt       bool                   `protobuf:"varint,1,opt,name=corrupt,proto3" json:"corrupt,omitempty"` // the replica failed verification and was dropped
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DownloadFileResponse) Reset() {
-	*x = DownloadFileResponse{}
-	mi := &file_proto_dfs_proto_msgTypes[4]
+func (x *VerifyChunkResponse) Reset() {
+	*x = VerifyChunkResponse{}
+	mi := &file_proto_dfs_proto_msgTypes[101]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DownloadFileResponse) String() string {
+func (x *VerifyChunkResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DownloadFileResponse) ProtoMessage() {}
+func (*VerifyChunkResponse) ProtoMessage() {}
 
-func (x *DownloadFileResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_dfs_proto_msgTypes[4]
+func (x *VerifyChunkResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[101]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -255,46 +6360,116 @@ func (x *DownloadFileResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DownloadFileResponse.ProtoReflect.Descriptor instead.
-func (*DownloadFileResponse) Descriptor() ([]byte, []int) {
-	return file_proto_dfs_proto_rawDescGZIP(), []int{4}
+// Deprecated: Use VerifyChunkResponse.ProtoReflect.Descriptor instead.
+func (*VerifyChunkResponse) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{101}
 }
 
-func (x *DownloadFileResponse) GetFilesize() int64 {
+func (x *VerifyChunkResponse) GetCorrupt() bool {
 	if x != nil {
-		return x.Filesize
+		return x.Corrupt
+	}
+	return false
+}
+
+type ReplicateChunkRequest struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	ChunkHandle       string                 `protobuf:"bytes,1,opt,name=chunk_handle,json=chunkHandle,proto3" json:"chunk_handle,omitempty"`
+	SourceAddress     string                 `protobuf:"bytes,2,opt,name=source_address,json=sourceAddress,proto3" json:"source_address,omitempty"` // chunk server holding a good replica
+	ChunkVersion      int32                  `protobuf:"varint,3,opt,name=chunk_version,json=chunkVersion,proto3" json:"chunk_version,omitempty"`
+	AccessToken       string                 `protobuf:"bytes,4,opt,name=access_token,json=accessToken,proto3" json:"access_token,omitempty"`                     // write token of the chunk
+	SourceAccessToken string                 `protobuf:"bytes,5,opt,name=source_access_token,json=sourceAccessToken,proto3" json:"source_access_token,omitempty"` // read token of the chunk, sent on to the source
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *ReplicateChunkRequest) Reset() {
+	*x = ReplicateChunkRequest{}
+	mi := &file_proto_dfs_proto_msgTypes[102]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplicateChunkRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplicateChunkRequest) ProtoMessage() {}
+
+func (x *ReplicateChunkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[102]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplicateChunkRequest.ProtoReflect.Descriptor instead.
+func (*ReplicateChunkRequest) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{102}
+}
+
+func (x *ReplicateChunkRequest) GetChunkHandle() string {
+	if x != nil {
+		return x.ChunkHandle
+	}
+	return ""
+}
+
+func (x *ReplicateChunkRequest) GetSourceAddress() string {
+	if x != nil {
+		return x.SourceAddress
+	}
+	return ""
+}
+
+func (x *ReplicateChunkRequest) GetChunkVersion() int32 {
+	if x != nil {
+		return x.ChunkVersion
 	}
 	return 0
 }
 
-func (x *DownloadFileResponse) GetChunkLocation() []*ChunkLocation {
+func (x *ReplicateChunkRequest) GetAccessToken() string {
 	if x != nil {
-		return x.ChunkLocation
+		return x.AccessToken
 	}
-	return nil
+	return ""
 }
 
-type ListFilesRequest struct {
+func (x *ReplicateChunkRequest) GetSourceAccessToken() string {
+	if x != nil {
+		return x.SourceAccessToken
+	}
+	return ""
+}
+
+type ReplicateChunkResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListFilesRequest) Reset() {
-	*x = ListFilesRequest{}
-	mi := &file_proto_dfs_proto_msgTypes[5]
+func (x *ReplicateChunkResponse) Reset() {
+	*x = ReplicateChunkResponse{}
+	mi := &file_proto_dfs_proto_msgTypes[103]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListFilesRequest) String() string {
+func (x *ReplicateChunkResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListFilesRequest) ProtoMessage() {}
+func (*ReplicateChunkResponse) ProtoMessage() {}
 
-func (x *ListFilesRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_dfs_proto_msgTypes[5]
+func (x *ReplicateChunkResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[103]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -305,35 +6480,42 @@ func (x *ListFilesRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListFilesRequest.ProtoReflect.Descriptor instead.
-func (*ListFilesRequest) Descriptor() ([]byte, []int) {
-	return file_proto_dfs_proto_rawDescGZIP(), []int{5}
+// Deprecated: Use ReplicateChunkResponse.ProtoReflect.Descriptor instead.
+func (*ReplicateChunkResponse) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{103}
 }
 
-type FileInfo struct {
+func (x *ReplicateChunkResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+type ArchivedChunk struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Filename      string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
-	Filesize      int64                  `protobuf:"varint,2,opt,name=filesize,proto3" json:"filesize,omitempty"`
-	NumChunks     int32                  `protobuf:"varint,3,opt,name=num_chunks,json=numChunks,proto3" json:"num_chunks,omitempty"`
+	ChunkHandle   string                 `protobuf:"bytes,1,opt,name=chunk_handle,json=chunkHandle,proto3" json:"chunk_handle,omitempty"`
+	Addresses     []string               `protobuf:"bytes,2,rep,name=addresses,proto3" json:"addresses,omitempty"`                        // chunk servers holding a replica
+	AccessToken   string                 `protobuf:"bytes,3,opt,name=access_token,json=accessToken,proto3" json:"access_token,omitempty"` // read token of the chunk
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *FileInfo) Reset() {
-	*x = FileInfo{}
-	mi := &file_proto_dfs_proto_msgTypes[6]
+func (x *ArchivedChunk) Reset() {
+	*x = ArchivedChunk{}
+	mi := &file_proto_dfs_proto_msgTypes[104]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *FileInfo) String() string {
+func (x *ArchivedChunk) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*FileInfo) ProtoMessage() {}
+func (*ArchivedChunk) ProtoMessage() {}
 
-func (x *FileInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_dfs_proto_msgTypes[6]
+func (x *ArchivedChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[104]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -344,54 +6526,57 @@ func (x *FileInfo) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use FileInfo.ProtoReflect.Descriptor instead.
-func (*FileInfo) Descriptor() ([]byte, []int) {
-	return file_proto_dfs_proto_rawDescGZIP(), []int{6}
+// Deprecated: Use ArchivedChunk.ProtoReflect.Descriptor instead.
+func (*ArchivedChunk) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{104}
 }
 
-func (x *FileInfo) GetFilename() string {
+func (x *ArchivedChunk) GetChunkHandle() string {
 	if x != nil {
-		return x.Filename
+		return x.ChunkHandle
 	}
 	return ""
 }
 
-func (x *FileInfo) GetFilesize() int64 {
+func (x *ArchivedChunk) GetAddresses() []string {
 	if x != nil {
-		return x.Filesize
+		return x.Addresses
 	}
-	return 0
+	return nil
 }
 
-func (x *FileInfo) GetNumChunks() int32 {
+func (x *ArchivedChunk) GetAccessToken() string {
 	if x != nil {
-		return x.NumChunks
+		return x.AccessToken
 	}
-	return 0
+	return ""
 }
 
-type ListFilesResponse struct {
+type RecalledChunk struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Files         []*FileInfo            `protobuf:"bytes,1,rep,name=files,proto3" json:"files,omitempty"`
+	ChunkHandle   string                 `protobuf:"bytes,1,opt,name=chunk_handle,json=chunkHandle,proto3" json:"chunk_handle,omitempty"`
+	ChunkVersion  int32                  `protobuf:"varint,2,opt,name=chunk_version,json=chunkVersion,proto3" json:"chunk_version,omitempty"`
+	Addresses     []string               `protobuf:"bytes,3,rep,name=addresses,proto3" json:"addresses,omitempty"`                        // chunk servers the chunk is written to, in the response those it was written to
+	AccessToken   string                 `protobuf:"bytes,4,opt,name=access_token,json=accessToken,proto3" json:"access_token,omitempty"` // write token of the chunk
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListFilesResponse) Reset() {
-	*x = ListFilesResponse{}
-	mi := &file_proto_dfs_proto_msgTypes[7]
+func (x *RecalledChunk) Reset() {
+	*x = RecalledChunk{}
+	mi := &file_proto_dfs_proto_msgTypes[105]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListFilesResponse) String() string {
+func (x *RecalledChunk) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListFilesResponse) ProtoMessage() {}
+func (*RecalledChunk) ProtoMessage() {}
 
-func (x *ListFilesResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_dfs_proto_msgTypes[7]
+func (x *RecalledChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[105]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -402,41 +6587,64 @@ func (x *ListFilesResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListFilesResponse.ProtoReflect.Descriptor instead.
-func (*ListFilesResponse) Descriptor() ([]byte, []int) {
-	return file_proto_dfs_proto_rawDescGZIP(), []int{7}
+// Deprecated: Use RecalledChunk.ProtoReflect.Descriptor instead.
+func (*RecalledChunk) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{105}
 }
 
-func (x *ListFilesResponse) GetFiles() []*FileInfo {
+func (x *RecalledChunk) GetChunkHandle() string {
 	if x != nil {
-		return x.Files
+		return x.ChunkHandle
+	}
+	return ""
+}
+
+func (x *RecalledChunk) GetChunkVersion() int32 {
+	if x != nil {
+		return x.ChunkVersion
+	}
+	return 0
+}
+
+func (x *RecalledChunk) GetAddresses() []string {
+	if x != nil {
+		return x.Addresses
 	}
 	return nil
 }
 
-type HeartbeatRequest struct {
-	state              protoimpl.MessageState `protogen:"open.v1"`
-	ChunkServerAddress string                 `protobuf:"bytes,1,opt,name=chunk_server_address,json=chunkServerAddress,proto3" json:"chunk_server_address,omitempty"`
-	ChunkHandles       []string               `protobuf:"bytes,2,rep,name=chunk_handles,json=chunkHandles,proto3" json:"chunk_handles,omitempty"`
-	unknownFields      protoimpl.UnknownFields
-	sizeCache          protoimpl.SizeCache
+func (x *RecalledChunk) GetAccessToken() string {
+	if x != nil {
+		return x.AccessToken
+	}
+	return ""
 }
 
-func (x *HeartbeatRequest) Reset() {
-	*x = HeartbeatRequest{}
-	mi := &file_proto_dfs_proto_msgTypes[8]
+type RecallChunksRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ArchivedChunks []*ArchivedChunk       `protobuf:"bytes,1,rep,name=archived_chunks,json=archivedChunks,proto3" json:"archived_chunks,omitempty"` // the gzip compressed contents, in order
+	ArchivedSize   int64                  `protobuf:"varint,2,opt,name=archived_size,json=archivedSize,proto3" json:"archived_size,omitempty"`      // compressed bytes held by archived_chunks
+	Filesize       int64                  `protobuf:"varint,3,opt,name=filesize,proto3" json:"filesize,omitempty"`                                  // bytes the contents decompress to
+	Chunks         []*RecalledChunk       `protobuf:"bytes,4,rep,name=chunks,proto3" json:"chunks,omitempty"`                                       // one for every common.ChunkSize bytes of the contents
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *RecallChunksRequest) Reset() {
+	*x = RecallChunksRequest{}
+	mi := &file_proto_dfs_proto_msgTypes[106]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *HeartbeatRequest) String() string {
+func (x *RecallChunksRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HeartbeatRequest) ProtoMessage() {}
+func (*RecallChunksRequest) ProtoMessage() {}
 
-func (x *HeartbeatRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_dfs_proto_msgTypes[8]
+func (x *RecallChunksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[106]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -447,47 +6655,61 @@ func (x *HeartbeatRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use HeartbeatRequest.ProtoReflect.Descriptor instead.
-func (*HeartbeatRequest) Descriptor() ([]byte, []int) {
-	return file_proto_dfs_proto_rawDescGZIP(), []int{8}
+// Deprecated: Use RecallChunksRequest.ProtoReflect.Descriptor instead.
+func (*RecallChunksRequest) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{106}
 }
 
-func (x *HeartbeatRequest) GetChunkServerAddress() string {
+func (x *RecallChunksRequest) GetArchivedChunks() []*ArchivedChunk {
 	if x != nil {
-		return x.ChunkServerAddress
+		return x.ArchivedChunks
 	}
-	return ""
+	return nil
 }
 
-func (x *HeartbeatRequest) GetChunkHandles() []string {
+func (x *RecallChunksRequest) GetArchivedSize() int64 {
 	if x != nil {
-		return x.ChunkHandles
+		return x.ArchivedSize
+	}
+	return 0
+}
+
+func (x *RecallChunksRequest) GetFilesize() int64 {
+	if x != nil {
+		return x.Filesize
+	}
+	return 0
+}
+
+func (x *RecallChunksRequest) GetChunks() []*RecalledChunk {
+	if x != nil {
+		return x.Chunks
 	}
 	return nil
 }
 
-type HeartbeatResponse struct {
+type RecallChunksResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Chunks        []*RecalledChunk       `protobuf:"bytes,1,rep,name=chunks,proto3" json:"chunks,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *HeartbeatResponse) Reset() {
-	*x = HeartbeatResponse{}
-	mi := &file_proto_dfs_proto_msgTypes[9]
+func (x *RecallChunksResponse) Reset() {
+	*x = RecallChunksResponse{}
+	mi := &file_proto_dfs_proto_msgTypes[107]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *HeartbeatResponse) String() string {
+func (x *RecallChunksResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HeartbeatResponse) ProtoMessage() {}
+func (*RecallChunksResponse) ProtoMessage() {}
 
-func (x *HeartbeatResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_dfs_proto_msgTypes[9]
+func (x *RecallChunksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[107]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -498,41 +6720,47 @@ func (x *HeartbeatResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use HeartbeatResponse.ProtoReflect.Descriptor instead.
-func (*HeartbeatResponse) Descriptor() ([]byte, []int) {
-	return file_proto_dfs_proto_rawDescGZIP(), []int{9}
+// Deprecated: Use RecallChunksResponse.ProtoReflect.Descriptor instead.
+func (*RecallChunksResponse) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{107}
 }
 
-func (x *HeartbeatResponse) GetSuccess() bool {
+func (x *RecallChunksResponse) GetChunks() []*RecalledChunk {
 	if x != nil {
-		return x.Success
+		return x.Chunks
 	}
-	return false
+	return nil
 }
 
-type ReportChunkRequest struct {
+type RecordAppendRequest struct {
 	state              protoimpl.MessageState `protogen:"open.v1"`
 	ChunkHandle        string                 `protobuf:"bytes,1,opt,name=chunk_handle,json=chunkHandle,proto3" json:"chunk_handle,omitempty"`
-	ChunkServerAddress string                 `protobuf:"bytes,2,opt,name=chunk_server_address,json=chunkServerAddress,proto3" json:"chunk_server_address,omitempty"`
+	ChunkVersion       int32                  `protobuf:"varint,2,opt,name=chunk_version,json=chunkVersion,proto3" json:"chunk_version,omitempty"`
+	Data               []byte                 `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+	Checksum           uint32                 `protobuf:"varint,4,opt,name=checksum,proto3" json:"checksum,omitempty"` // CRC-32C of data
+	SecondaryAddresses []string               `protobuf:"bytes,5,rep,name=secondary_addresses,json=secondaryAddresses,proto3" json:"secondary_addresses,omitempty"`
+	LeaseExpiresAt     int64                  `protobuf:"varint,6,opt,name=lease_expires_at,json=leaseExpiresAt,proto3" json:"lease_expires_at,omitempty"` // unix time in nanoseconds the primary's lease expires
+	AccessToken        string                 `protobuf:"bytes,7,opt,name=access_token,json=accessToken,proto3" json:"access_token,omitempty"`             // write token from the chunk's location, forwarded to the secondaries
+	LeaseToken         string                 `protobuf:"bytes,8,opt,name=lease_token,json=leaseToken,proto3" json:"lease_token,omitempty"`                // lease token from the chunk's location
 	unknownFields      protoimpl.UnknownFields
 	sizeCache          protoimpl.SizeCache
 }
 
-func (x *ReportChunkRequest) Reset() {
-	*x = ReportChunkRequest{}
-	mi := &file_proto_dfs_proto_msgTypes[10]
+func (x *RecordAppendRequest) Reset() {
+	*x = RecordAppendRequest{}
+	mi := &file_proto_dfs_proto_msgTypes[108]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ReportChunkRequest) String() string {
+func (x *RecordAppendRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ReportChunkRequest) ProtoMessage() {}
+func (*RecordAppendRequest) ProtoMessage() {}
 
-func (x *ReportChunkRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_dfs_proto_msgTypes[10]
+func (x *RecordAppendRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[108]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -543,94 +6771,90 @@ func (x *ReportChunkRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ReportChunkRequest.ProtoReflect.Descriptor instead.
-func (*ReportChunkRequest) Descriptor() ([]byte, []int) {
-	return file_proto_dfs_proto_rawDescGZIP(), []int{10}
+// Deprecated: Use RecordAppendRequest.ProtoReflect.Descriptor instead.
+func (*RecordAppendRequest) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{108}
 }
 
-func (x *ReportChunkRequest) GetChunkHandle() string {
+func (x *RecordAppendRequest) GetChunkHandle() string {
 	if x != nil {
 		return x.ChunkHandle
 	}
 	return ""
 }
 
-func (x *ReportChunkRequest) GetChunkServerAddress() string {
+func (x *RecordAppendRequest) GetChunkVersion() int32 {
 	if x != nil {
-		return x.ChunkServerAddress
+		return x.ChunkVersion
 	}
-	return ""
+	return 0
 }
 
-type ReportChunkResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *RecordAppendRequest) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
 }
 
-func (x *ReportChunkResponse) Reset() {
-	*x = ReportChunkResponse{}
-	mi := &file_proto_dfs_proto_msgTypes[11]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *RecordAppendRequest) GetChecksum() uint32 {
+	if x != nil {
+		return x.Checksum
+	}
+	return 0
 }
 
-func (x *ReportChunkResponse) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *RecordAppendRequest) GetSecondaryAddresses() []string {
+	if x != nil {
+		return x.SecondaryAddresses
+	}
+	return nil
 }
 
-func (*ReportChunkResponse) ProtoMessage() {}
-
-func (x *ReportChunkResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_dfs_proto_msgTypes[11]
+func (x *RecordAppendRequest) GetLeaseExpiresAt() int64 {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.LeaseExpiresAt
 	}
-	return mi.MessageOf(x)
+	return 0
 }
 
-// Deprecated: Use ReportChunkResponse.ProtoReflect.Descriptor instead.
-func (*ReportChunkResponse) Descriptor() ([]byte, []int) {
-	return file_proto_dfs_proto_rawDescGZIP(), []int{11}
+func (x *RecordAppendRequest) GetAccessToken() string {
+	if x != nil {
+		return x.AccessToken
+	}
+	return ""
 }
 
-func (x *ReportChunkResponse) GetSuccess() bool {
+func (x *RecordAppendRequest) GetLeaseToken() string {
 	if x != nil {
-		return x.Success
+		return x.LeaseToken
 	}
-	return false
+	return ""
 }
 
-// Messages for ChunkServer Service
-type WriteChunkRequest struct {
+type RecordAppendResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	ChunkHandle   string                 `protobuf:"bytes,1,opt,name=chunk_handle,json=chunkHandle,proto3" json:"chunk_handle,omitempty"`
-	Data          []byte                 `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
-	ChunkIndex    int32                  `protobuf:"varint,3,opt,name=chunk_index,json=chunkIndex,proto3" json:"chunk_index,omitempty"`
+	Offset        int64                  `protobuf:"varint,1,opt,name=offset,proto3" json:"offset,omitempty"`                        // offset of the record inside the chunk
+	ChunkFull     bool                   `protobuf:"varint,2,opt,name=chunk_full,json=chunkFull,proto3" json:"chunk_full,omitempty"` // the record didn't fit, the chunk was padded and the record goes to the next chunk
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *WriteChunkRequest) Reset() {
-	*x = WriteChunkRequest{}
-	mi := &file_proto_dfs_proto_msgTypes[12]
+func (x *RecordAppendResponse) Reset() {
+	*x = RecordAppendResponse{}
+	mi := &file_proto_dfs_proto_msgTypes[109]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *WriteChunkRequest) String() string {
+func (x *RecordAppendResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*WriteChunkRequest) ProtoMessage() {}
+func (*RecordAppendResponse) ProtoMessage() {}
 
-func (x *WriteChunkRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_dfs_proto_msgTypes[12]
+func (x *RecordAppendResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[109]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -641,54 +6865,53 @@ func (x *WriteChunkRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use WriteChunkRequest.ProtoReflect.Descriptor instead.
-func (*WriteChunkRequest) Descriptor() ([]byte, []int) {
-	return file_proto_dfs_proto_rawDescGZIP(), []int{12}
-}
-
-func (x *WriteChunkRequest) GetChunkHandle() string {
-	if x != nil {
-		return x.ChunkHandle
-	}
-	return ""
+// Deprecated: Use RecordAppendResponse.ProtoReflect.Descriptor instead.
+func (*RecordAppendResponse) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{109}
 }
 
-func (x *WriteChunkRequest) GetData() []byte {
+func (x *RecordAppendResponse) GetOffset() int64 {
 	if x != nil {
-		return x.Data
+		return x.Offset
 	}
-	return nil
+	return 0
 }
 
-func (x *WriteChunkRequest) GetChunkIndex() int32 {
+func (x *RecordAppendResponse) GetChunkFull() bool {
 	if x != nil {
-		return x.ChunkIndex
+		return x.ChunkFull
 	}
-	return 0
+	return false
 }
 
-type WriteChunkResponse struct {
+type ApplyAppendRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	ChunkHandle   string                 `protobuf:"bytes,1,opt,name=chunk_handle,json=chunkHandle,proto3" json:"chunk_handle,omitempty"`
+	ChunkVersion  int32                  `protobuf:"varint,2,opt,name=chunk_version,json=chunkVersion,proto3" json:"chunk_version,omitempty"`
+	Offset        int64                  `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`
+	Data          []byte                 `protobuf:"bytes,4,opt,name=data,proto3" json:"data,omitempty"`                                     // empty to pad the chunk with zeros up to offset
+	Checksum      uint32                 `protobuf:"varint,5,opt,name=checksum,proto3" json:"checksum,omitempty"`                            // CRC-32C of data
+	AccessToken   string                 `protobuf:"bytes,6,opt,name=access_token,json=accessToken,proto3" json:"access_token,omitempty"`    // write token the client sent the primary
+	ForwardToken  string                 `protobuf:"bytes,7,opt,name=forward_token,json=forwardToken,proto3" json:"forward_token,omitempty"` // signed by the primary, secondaries only apply records it forwards
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *WriteChunkResponse) Reset() {
-	*x = WriteChunkResponse{}
-	mi := &file_proto_dfs_proto_msgTypes[13]
+func (x *ApplyAppendRequest) Reset() {
+	*x = ApplyAppendRequest{}
+	mi := &file_proto_dfs_proto_msgTypes[110]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *WriteChunkResponse) String() string {
+func (x *ApplyAppendRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*WriteChunkResponse) ProtoMessage() {}
+func (*ApplyAppendRequest) ProtoMessage() {}
 
-func (x *WriteChunkResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_dfs_proto_msgTypes[13]
+func (x *ApplyAppendRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[110]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -699,84 +6922,82 @@ func (x *WriteChunkResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use WriteChunkResponse.ProtoReflect.Descriptor instead.
-func (*WriteChunkResponse) Descriptor() ([]byte, []int) {
-	return file_proto_dfs_proto_rawDescGZIP(), []int{13}
+// Deprecated: Use ApplyAppendRequest.ProtoReflect.Descriptor instead.
+func (*ApplyAppendRequest) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{110}
 }
 
-func (x *WriteChunkResponse) GetSuccess() bool {
+func (x *ApplyAppendRequest) GetChunkHandle() string {
 	if x != nil {
-		return x.Success
+		return x.ChunkHandle
 	}
-	return false
+	return ""
 }
 
-type ReadChunkRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	ChunkHandle   string                 `protobuf:"bytes,1,opt,name=chunk_handle,json=chunkHandle,proto3" json:"chunk_handle,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *ApplyAppendRequest) GetChunkVersion() int32 {
+	if x != nil {
+		return x.ChunkVersion
+	}
+	return 0
 }
 
-func (x *ReadChunkRequest) Reset() {
-	*x = ReadChunkRequest{}
-	mi := &file_proto_dfs_proto_msgTypes[14]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *ApplyAppendRequest) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
 }
 
-func (x *ReadChunkRequest) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *ApplyAppendRequest) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
 }
 
-func (*ReadChunkRequest) ProtoMessage() {}
-
-func (x *ReadChunkRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_dfs_proto_msgTypes[14]
+func (x *ApplyAppendRequest) GetChecksum() uint32 {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.Checksum
 	}
-	return mi.MessageOf(x)
+	return 0
 }
 
-// Deprecated: Use ReadChunkRequest.ProtoReflect.Descriptor instead.
-func (*ReadChunkRequest) Descriptor() ([]byte, []int) {
-	return file_proto_dfs_proto_rawDescGZIP(), []int{14}
+func (x *ApplyAppendRequest) GetAccessToken() string {
+	if x != nil {
+		return x.AccessToken
+	}
+	return ""
 }
 
-func (x *ReadChunkRequest) GetChunkHandle() string {
+func (x *ApplyAppendRequest) GetForwardToken() string {
 	if x != nil {
-		return x.ChunkHandle
+		return x.ForwardToken
 	}
 	return ""
 }
 
-type ReadChunkResponse struct {
+type ApplyAppendResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Data          []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ReadChunkResponse) Reset() {
-	*x = ReadChunkResponse{}
-	mi := &file_proto_dfs_proto_msgTypes[15]
+func (x *ApplyAppendResponse) Reset() {
+	*x = ApplyAppendResponse{}
+	mi := &file_proto_dfs_proto_msgTypes[111]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ReadChunkResponse) String() string {
+func (x *ApplyAppendResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ReadChunkResponse) ProtoMessage() {}
+func (*ApplyAppendResponse) ProtoMessage() {}
 
-func (x *ReadChunkResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_dfs_proto_msgTypes[15]
+func (x *ApplyAppendResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dfs_proto_msgTypes[111]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -787,78 +7008,695 @@ func (x *ReadChunkResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ReadChunkResponse.ProtoReflect.Descriptor instead.
-func (*ReadChunkResponse) Descriptor() ([]byte, []int) {
-	return file_proto_dfs_proto_rawDescGZIP(), []int{15}
+// Deprecated: Use ApplyAppendResponse.ProtoReflect.Descriptor instead.
+func (*ApplyAppendResponse) Descriptor() ([]byte, []int) {
+	return file_proto_dfs_proto_rawDescGZIP(), []int{111}
 }
 
-func (x *ReadChunkResponse) GetData() []byte {
+func (x *ApplyAppendResponse) GetSuccess() bool {
 	if x != nil {
-		return x.Data
+		return x.Success
 	}
-	return nil
+	return false
 }
 
 var File_proto_dfs_proto protoreflect.FileDescriptor
 
 const file_proto_dfs_proto_rawDesc = "" +
 	"\n" +
-	"\x0fproto/dfs.proto\x12\x03dfs\"K\n" +
+	"\x0fproto/dfs.proto\x12\x03dfs\"\xa9\x04\n" +
 	"\x11UploadFileRequest\x12\x1a\n" +
 	"\bfilename\x18\x01 \x01(\tR\bfilename\x12\x1a\n" +
-	"\bfilesize\x18\x02 \x01(\x03R\bfilesize\"\x89\x01\n" +
+	"\bfilesize\x18\x02 \x01(\x03R\bfilesize\x12)\n" +
+	"\x05hints\x18\x03 \x01(\v2\x13.dfs.PlacementHintsR\x05hints\x123\n" +
+	"\x13if_generation_match\x18\x04 \x01(\x03H\x00R\x11ifGenerationMatch\x88\x01\x01\x12\x1c\n" +
+	"\timmutable\x18\x05 \x01(\bR\timmutable\x12+\n" +
+	"\x11retention_seconds\x18\x06 \x01(\x03R\x10retentionSeconds\x124\n" +
+	"\x04tags\x18\a \x03(\v2 .dfs.UploadFileRequest.TagsEntryR\x04tags\x12\x1c\n" +
+	"\texclusive\x18\b \x01(\bR\texclusive\x12\x12\n" +
+	"\x04mode\x18\t \x01(\rR\x04mode\x12\x12\n" +
+	"\x04data\x18\n" +
+	" \x01(\fR\x04data\x12!\n" +
+	"\fstorage_tier\x18\v \x01(\tR\vstorageTier\x12#\n" +
+	"\rstorage_class\x18\f \x01(\tR\fstorageClass\x12\x1c\n" +
+	"\tstreaming\x18\r \x01(\bR\tstreaming\x1a7\n" +
+	"\tTagsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B\x16\n" +
+	"\x14_if_generation_match\"\x84\x01\n" +
+	"\x0ePlacementHints\x12%\n" +
+	"\x0epreferred_zone\x18\x01 \x01(\tR\rpreferredZone\x12\x1d\n" +
+	"\n" +
+	"local_host\x18\x02 \x01(\tR\tlocalHost\x12,\n" +
+	"\x12anti_affinity_file\x18\x03 \x01(\tR\x10antiAffinityFile\"\xc5\x02\n" +
 	"\rChunkLocation\x12!\n" +
 	"\fchunk_handle\x18\x01 \x01(\tR\vchunkHandle\x124\n" +
 	"\x16chunk_server_addresses\x18\x02 \x03(\tR\x14chunkServerAddresses\x12\x1f\n" +
 	"\vchunk_index\x18\x03 \x01(\x05R\n" +
-	"chunkIndex\"Q\n" +
+	"chunkIndex\x12#\n" +
+	"\rchunk_version\x18\x04 \x01(\x05R\fchunkVersion\x12'\n" +
+	"\x0fprimary_address\x18\x05 \x01(\tR\x0eprimaryAddress\x12(\n" +
+	"\x10lease_expires_at\x18\x06 \x01(\x03R\x0eleaseExpiresAt\x12!\n" +
+	"\faccess_token\x18\a \x01(\tR\vaccessToken\x12\x1f\n" +
+	"\vlease_token\x18\b \x01(\tR\n" +
+	"leaseToken\"\xc5\x01\n" +
 	"\x12UploadFileResponse\x12;\n" +
-	"\x0fchunk_locations\x18\x01 \x03(\v2\x12.dfs.ChunkLocationR\x0echunkLocations\"1\n" +
+	"\x0fchunk_locations\x18\x01 \x03(\v2\x12.dfs.ChunkLocationR\x0echunkLocations\x12\x1b\n" +
+	"\tupload_id\x18\x02 \x01(\tR\buploadId\x12\x1e\n" +
+	"\n" +
+	"generation\x18\x03 \x01(\x03R\n" +
+	"generation\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x04 \x01(\x03R\texpiresAt\x12\x16\n" +
+	"\x06inline\x18\x05 \x01(\bR\x06inline\"\x96\x01\n" +
+	"\x15CompleteUploadRequest\x12\x1a\n" +
+	"\bfilename\x18\x01 \x01(\tR\bfilename\x12\x1b\n" +
+	"\tupload_id\x18\x02 \x01(\tR\buploadId\x12\x16\n" +
+	"\x06failed\x18\x03 \x01(\bR\x06failed\x12\x1f\n" +
+	"\bfilesize\x18\x04 \x01(\x03H\x00R\bfilesize\x88\x01\x01B\v\n" +
+	"\t_filesize\"2\n" +
+	"\x16CompleteUploadResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"M\n" +
+	"\x12RenewUploadRequest\x12\x1a\n" +
+	"\bfilename\x18\x01 \x01(\tR\bfilename\x12\x1b\n" +
+	"\tupload_id\x18\x02 \x01(\tR\buploadId\"4\n" +
+	"\x13RenewUploadResponse\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x01 \x01(\x03R\texpiresAt\"p\n" +
+	"\x14AllocateChunkRequest\x12\x1a\n" +
+	"\bfilename\x18\x01 \x01(\tR\bfilename\x12\x1b\n" +
+	"\tupload_id\x18\x02 \x01(\tR\buploadId\x12\x1f\n" +
+	"\vchunk_index\x18\x03 \x01(\x05R\n" +
+	"chunkIndex\"R\n" +
+	"\x15AllocateChunkResponse\x129\n" +
+	"\x0echunk_location\x18\x01 \x01(\v2\x12.dfs.ChunkLocationR\rchunkLocation\"v\n" +
+	"\x14PrepareAppendRequest\x12\x1a\n" +
+	"\bfilename\x18\x01 \x01(\tR\bfilename\x12-\n" +
+	"\x10full_chunk_index\x18\x02 \x01(\x05H\x00R\x0efullChunkIndex\x88\x01\x01B\x13\n" +
+	"\x11_full_chunk_index\"x\n" +
+	"\x15PrepareAppendResponse\x129\n" +
+	"\x0echunk_location\x18\x01 \x01(\v2\x12.dfs.ChunkLocationR\rchunkLocation\x12\x1e\n" +
+	"\n" +
+	"generation\x18\x03 \x01(\x03R\n" +
+	"generationJ\x04\b\x02\x10\x03\"\x93\x01\n" +
+	"\x15CompleteAppendRequest\x12\x1a\n" +
+	"\bfilename\x18\x01 \x01(\tR\bfilename\x12\x1e\n" +
+	"\n" +
+	"generation\x18\x02 \x01(\x03R\n" +
+	"generation\x12\x1f\n" +
+	"\vchunk_index\x18\x03 \x01(\x05R\n" +
+	"chunkIndex\x12\x1d\n" +
+	"\n" +
+	"end_offset\x18\x04 \x01(\x03R\tendOffset\"4\n" +
+	"\x16CompleteAppendResponse\x12\x1a\n" +
+	"\bfilesize\x18\x01 \x01(\x03R\bfilesize\"Q\n" +
 	"\x13DownloadFileRequest\x12\x1a\n" +
-	"\bfilename\x18\x01 \x01(\tR\bfilename\"m\n" +
+	"\bfilename\x18\x01 \x01(\tR\bfilename\x12\x1e\n" +
+	"\n" +
+	"generation\x18\x02 \x01(\x03R\n" +
+	"generation\"\x81\x01\n" +
 	"\x14DownloadFileResponse\x12\x1a\n" +
 	"\bfilesize\x18\x01 \x01(\x03R\bfilesize\x129\n" +
-	"\x0echunk_location\x18\x02 \x03(\v2\x12.dfs.ChunkLocationR\rchunkLocation\"\x12\n" +
-	"\x10ListFilesRequest\"a\n" +
+	"\x0echunk_location\x18\x02 \x03(\v2\x12.dfs.ChunkLocationR\rchunkLocation\x12\x12\n" +
+	"\x04data\x18\x03 \x01(\fR\x04data\"\x86\x03\n" +
+	"\x10ListFilesRequest\x123\n" +
+	"\x04tags\x18\x01 \x03(\v2\x1f.dfs.ListFilesRequest.TagsEntryR\x04tags\x12)\n" +
+	"\asort_by\x18\x02 \x01(\x0e2\x10.dfs.ListSortKeyR\x06sortBy\x12\x1e\n" +
+	"\n" +
+	"descending\x18\x03 \x01(\bR\n" +
+	"descending\x12\x1e\n" +
+	"\bmin_size\x18\x04 \x01(\x03H\x00R\aminSize\x88\x01\x01\x12\x1e\n" +
+	"\bmax_size\x18\x05 \x01(\x03H\x01R\amaxSize\x88\x01\x01\x12#\n" +
+	"\rcreated_after\x18\x06 \x01(\x03R\fcreatedAfter\x12\x1b\n" +
+	"\tpage_size\x18\a \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\b \x01(\tR\tpageToken\x1a7\n" +
+	"\tTagsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B\v\n" +
+	"\t_min_sizeB\v\n" +
+	"\t_max_size\"\xc4\x06\n" +
 	"\bFileInfo\x12\x1a\n" +
 	"\bfilename\x18\x01 \x01(\tR\bfilename\x12\x1a\n" +
 	"\bfilesize\x18\x02 \x01(\x03R\bfilesize\x12\x1d\n" +
 	"\n" +
-	"num_chunks\x18\x03 \x01(\x05R\tnumChunks\"8\n" +
+	"num_chunks\x18\x03 \x01(\x05R\tnumChunks\x12\x1d\n" +
+	"\n" +
+	"read_count\x18\x04 \x01(\x03R\treadCount\x12#\n" +
+	"\rlast_accessed\x18\x05 \x01(\x03R\flastAccessed\x12\x1e\n" +
+	"\n" +
+	"generation\x18\x06 \x01(\x03R\n" +
+	"generation\x12\x1c\n" +
+	"\timmutable\x18\a \x01(\bR\timmutable\x12!\n" +
+	"\fretain_until\x18\b \x01(\x03R\vretainUntil\x12+\n" +
+	"\x04tags\x18\t \x03(\v2\x17.dfs.FileInfo.TagsEntryR\x04tags\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\n" +
+	" \x01(\x03R\texpiresAt\x12-\n" +
+	"\x12replication_factor\x18\v \x01(\x05R\x11replicationFactor\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\f \x01(\x03R\tcreatedAt\x12\x1f\n" +
+	"\vmodified_at\x18\r \x01(\x03R\n" +
+	"modifiedAt\x12!\n" +
+	"\fmin_replicas\x18\x0e \x01(\x05R\vminReplicas\x12\x1a\n" +
+	"\bdegraded\x18\x0f \x01(\bR\bdegraded\x12\x14\n" +
+	"\x05owner\x18\x10 \x01(\tR\x05owner\x12\x14\n" +
+	"\x05group\x18\x11 \x01(\tR\x05group\x12\x12\n" +
+	"\x04mode\x18\x12 \x01(\rR\x04mode\x12%\n" +
+	"\x0esymlink_target\x18\x13 \x01(\tR\rsymlinkTarget\x12\x1d\n" +
+	"\n" +
+	"hard_links\x18\x14 \x03(\tR\thardLinks\x12\x16\n" +
+	"\x06inline\x18\x15 \x01(\bR\x06inline\x12!\n" +
+	"\fstorage_tier\x18\x16 \x01(\tR\vstorageTier\x12#\n" +
+	"\rarchived_size\x18\x17 \x01(\x03R\farchivedSize\x12#\n" +
+	"\rstorage_class\x18\x18 \x01(\tR\fstorageClass\x1a7\n" +
+	"\tTagsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"`\n" +
 	"\x11ListFilesResponse\x12#\n" +
-	"\x05files\x18\x01 \x03(\v2\r.dfs.FileInfoR\x05files\"i\n" +
+	"\x05files\x18\x01 \x03(\v2\r.dfs.FileInfoR\x05files\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"\xb5\x03\n" +
+	"\x12SearchFilesRequest\x12#\n" +
+	"\rname_contains\x18\x01 \x01(\tR\fnameContains\x125\n" +
+	"\x04tags\x18\x02 \x03(\v2!.dfs.SearchFilesRequest.TagsEntryR\x04tags\x12\x1e\n" +
+	"\bmin_size\x18\x03 \x01(\x03H\x00R\aminSize\x88\x01\x01\x12\x1e\n" +
+	"\bmax_size\x18\x04 \x01(\x03H\x01R\amaxSize\x88\x01\x01\x12#\n" +
+	"\rcreated_after\x18\x05 \x01(\x03R\fcreatedAfter\x12%\n" +
+	"\x0ecreated_before\x18\x06 \x01(\x03R\rcreatedBefore\x12%\n" +
+	"\x0emodified_after\x18\a \x01(\x03R\rmodifiedAfter\x12'\n" +
+	"\x0fmodified_before\x18\b \x01(\x03R\x0emodifiedBefore\x12\x14\n" +
+	"\x05limit\x18\t \x01(\x05R\x05limit\x1a7\n" +
+	"\tTagsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B\v\n" +
+	"\t_min_sizeB\v\n" +
+	"\t_max_size\"X\n" +
+	"\x13SearchFilesResponse\x12#\n" +
+	"\x05files\x18\x01 \x03(\v2\r.dfs.FileInfoR\x05files\x12\x1c\n" +
+	"\ttruncated\x18\x02 \x01(\bR\ttruncated\"\xa1\x03\n" +
 	"\x10HeartbeatRequest\x120\n" +
 	"\x14chunk_server_address\x18\x01 \x01(\tR\x12chunkServerAddress\x12#\n" +
-	"\rchunk_handles\x18\x02 \x03(\tR\fchunkHandles\"-\n" +
+	"\rchunk_handles\x18\x02 \x03(\tR\fchunkHandles\x12%\n" +
+	"\x0ecapacity_bytes\x18\x03 \x01(\x03R\rcapacityBytes\x12\x1d\n" +
+	"\n" +
+	"free_bytes\x18\x04 \x01(\x03R\tfreeBytes\x12$\n" +
+	"\x04load\x18\x05 \x01(\v2\x10.dfs.LoadMetricsR\x04load\x12\x12\n" +
+	"\x04zone\x18\x06 \x01(\tR\x04zone\x12F\n" +
+	"\vchunk_reads\x18\a \x03(\v2%.dfs.HeartbeatRequest.ChunkReadsEntryR\n" +
+	"chunkReads\x12\x1b\n" +
+	"\tserver_id\x18\b \x01(\tR\bserverId\x12\x12\n" +
+	"\x04tier\x18\t \x01(\tR\x04tier\x1a=\n" +
+	"\x0fChunkReadsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x01\"\xdd\x01\n" +
+	"\vLoadMetrics\x12\x12\n" +
+	"\x04iops\x18\x01 \x01(\x01R\x04iops\x12\x1f\n" +
+	"\vqueue_depth\x18\x02 \x01(\x05R\n" +
+	"queueDepth\x12'\n" +
+	"\x0fcpu_utilization\x18\x03 \x01(\x01R\x0ecpuUtilization\x126\n" +
+	"\x18network_in_bytes_per_sec\x18\x04 \x01(\x01R\x14networkInBytesPerSec\x128\n" +
+	"\x19network_out_bytes_per_sec\x18\x05 \x01(\x01R\x15networkOutBytesPerSec\"-\n" +
 	"\x11HeartbeatResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\"i\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"\xb8\x03\n" +
+	"\x1aRegisterChunkServerRequest\x120\n" +
+	"\x14chunk_server_address\x18\x01 \x01(\tR\x12chunkServerAddress\x12%\n" +
+	"\x0ecapacity_bytes\x18\x02 \x01(\x03R\rcapacityBytes\x12\x1d\n" +
+	"\n" +
+	"free_bytes\x18\x03 \x01(\x03R\tfreeBytes\x12C\n" +
+	"\x06labels\x18\x04 \x03(\v2+.dfs.RegisterChunkServerRequest.LabelsEntryR\x06labels\x12\x18\n" +
+	"\aversion\x18\x05 \x01(\tR\aversion\x12F\n" +
+	"\x13storage_directories\x18\x06 \x03(\v2\x15.dfs.StorageDirectoryR\x12storageDirectories\x12#\n" +
+	"\rchunk_handles\x18\a \x03(\tR\fchunkHandles\x12\x1b\n" +
+	"\tserver_id\x18\b \x01(\tR\bserverId\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xa7\x01\n" +
+	"\x10StorageDirectory\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12%\n" +
+	"\x0ecapacity_bytes\x18\x02 \x01(\x03R\rcapacityBytes\x12\x1d\n" +
+	"\n" +
+	"free_bytes\x18\x03 \x01(\x03R\tfreeBytes\x12\x1f\n" +
+	"\vchunk_count\x18\x04 \x01(\x03R\n" +
+	"chunkCount\x12\x18\n" +
+	"\ahealthy\x18\x05 \x01(\bR\ahealthy\"\x9f\x01\n" +
+	"\x1bRegisterChunkServerResponse\x12\x1d\n" +
+	"\n" +
+	"chunk_size\x18\x01 \x01(\x03R\tchunkSize\x122\n" +
+	"\x15heartbeat_interval_ms\x18\x02 \x01(\x03R\x13heartbeatIntervalMs\x12-\n" +
+	"\x12replication_factor\x18\x03 \x01(\x05R\x11replicationFactor\"i\n" +
 	"\x12ReportChunkRequest\x12!\n" +
 	"\fchunk_handle\x18\x01 \x01(\tR\vchunkHandle\x120\n" +
 	"\x14chunk_server_address\x18\x02 \x01(\tR\x12chunkServerAddress\"/\n" +
 	"\x13ReportChunkResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\"k\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"\x88\x01\n" +
+	"\x17ReportLostChunksRequest\x120\n" +
+	"\x14chunk_server_address\x18\x01 \x01(\tR\x12chunkServerAddress\x12#\n" +
+	"\rchunk_handles\x18\x02 \x03(\tR\fchunkHandles\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\"4\n" +
+	"\x18ReportLostChunksResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"\x88\x01\n" +
+	"\x19ReportCorruptChunkRequest\x120\n" +
+	"\x14chunk_server_address\x18\x01 \x01(\tR\x12chunkServerAddress\x12!\n" +
+	"\fchunk_handle\x18\x02 \x01(\tR\vchunkHandle\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\"6\n" +
+	"\x1aReportCorruptChunkResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"\xba\x01\n" +
+	"\x0fCopyFileRequest\x12'\n" +
+	"\x0fsource_filename\x18\x01 \x01(\tR\x0esourceFilename\x121\n" +
+	"\x14destination_filename\x18\x02 \x01(\tR\x13destinationFilename\x123\n" +
+	"\x13if_generation_match\x18\x03 \x01(\x03H\x00R\x11ifGenerationMatch\x88\x01\x01B\x16\n" +
+	"\x14_if_generation_match\"L\n" +
+	"\x10CopyFileResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x1e\n" +
+	"\n" +
+	"generation\x18\x02 \x01(\x03R\n" +
+	"generation\"\xbb\x01\n" +
+	"\x10CloneFileRequest\x12'\n" +
+	"\x0fsource_filename\x18\x01 \x01(\tR\x0esourceFilename\x121\n" +
+	"\x14destination_filename\x18\x02 \x01(\tR\x13destinationFilename\x123\n" +
+	"\x13if_generation_match\x18\x03 \x01(\x03H\x00R\x11ifGenerationMatch\x88\x01\x01B\x16\n" +
+	"\x14_if_generation_match\"M\n" +
+	"\x11CloneFileResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x1e\n" +
+	"\n" +
+	"generation\x18\x02 \x01(\x03R\n" +
+	"generation\"\xbc\x01\n" +
+	"\x11RenameFileRequest\x12'\n" +
+	"\x0fsource_filename\x18\x01 \x01(\tR\x0esourceFilename\x121\n" +
+	"\x14destination_filename\x18\x02 \x01(\tR\x13destinationFilename\x123\n" +
+	"\x13if_generation_match\x18\x03 \x01(\x03H\x00R\x11ifGenerationMatch\x88\x01\x01B\x16\n" +
+	"\x14_if_generation_match\"N\n" +
+	"\x12RenameFileResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x1e\n" +
+	"\n" +
+	"generation\x18\x02 \x01(\x03R\n" +
+	"generation\"&\n" +
+	"\fWatchRequest\x12\x16\n" +
+	"\x06prefix\x18\x01 \x01(\tR\x06prefix\"\xac\x01\n" +
+	"\tFileEvent\x12&\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x12.dfs.FileEventTypeR\x04type\x12\x1a\n" +
+	"\bfilename\x18\x02 \x01(\tR\bfilename\x12!\n" +
+	"\fold_filename\x18\x03 \x01(\tR\voldFilename\x12\x1a\n" +
+	"\bfilesize\x18\x04 \x01(\x03R\bfilesize\x12\x1c\n" +
+	"\ttimestamp\x18\x05 \x01(\x03R\ttimestamp\"|\n" +
+	"\x11DeleteFileRequest\x12\x1a\n" +
+	"\bfilename\x18\x01 \x01(\tR\bfilename\x123\n" +
+	"\x13if_generation_match\x18\x02 \x01(\x03H\x00R\x11ifGenerationMatch\x88\x01\x01B\x16\n" +
+	"\x14_if_generation_match\".\n" +
+	"\x12DeleteFileResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"1\n" +
+	"\x13UndeleteFileRequest\x12\x1a\n" +
+	"\bfilename\x18\x01 \x01(\tR\bfilename\"6\n" +
+	"\x14UndeleteFileResponse\x12\x1e\n" +
+	"\n" +
+	"generation\x18\x01 \x01(\x03R\n" +
+	"generation\"0\n" +
+	"\x12GetFileInfoRequest\x12\x1a\n" +
+	"\bfilename\x18\x01 \x01(\tR\bfilename\"u\n" +
+	"\x13GetFileInfoResponse\x12!\n" +
+	"\x04file\x18\x01 \x01(\v2\r.dfs.FileInfoR\x04file\x12;\n" +
+	"\x0fchunk_locations\x18\x02 \x03(\v2\x12.dfs.ChunkLocationR\x0echunkLocations\"5\n" +
+	"\x17ListFileVersionsRequest\x12\x1a\n" +
+	"\bfilename\x18\x01 \x01(\tR\bfilename\"\xc2\x01\n" +
+	"\vFileVersion\x12\x1e\n" +
+	"\n" +
+	"generation\x18\x01 \x01(\x03R\n" +
+	"generation\x12\x1a\n" +
+	"\bfilesize\x18\x02 \x01(\x03R\bfilesize\x12\x1d\n" +
+	"\n" +
+	"num_chunks\x18\x03 \x01(\x05R\tnumChunks\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x04 \x01(\x03R\tcreatedAt\x12\x1f\n" +
+	"\vreplaced_at\x18\x05 \x01(\x03R\n" +
+	"replacedAt\x12\x18\n" +
+	"\acurrent\x18\x06 \x01(\bR\acurrent\"H\n" +
+	"\x18ListFileVersionsResponse\x12,\n" +
+	"\bversions\x18\x01 \x03(\v2\x10.dfs.FileVersionR\bversions\"\xba\x01\n" +
+	"\x15UpdateFileTagsRequest\x12\x1a\n" +
+	"\bfilename\x18\x01 \x01(\tR\bfilename\x125\n" +
+	"\x03set\x18\x02 \x03(\v2#.dfs.UpdateFileTagsRequest.SetEntryR\x03set\x12\x16\n" +
+	"\x06remove\x18\x03 \x03(\tR\x06remove\x1a6\n" +
+	"\bSetEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x8c\x01\n" +
+	"\x16UpdateFileTagsResponse\x129\n" +
+	"\x04tags\x18\x01 \x03(\v2%.dfs.UpdateFileTagsResponse.TagsEntryR\x04tags\x1a7\n" +
+	"\tTagsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xd3\x02\n" +
+	"\x0eFileAttributes\x121\n" +
+	"\x04tags\x18\x01 \x03(\v2\x1d.dfs.FileAttributes.TagsEntryR\x04tags\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x02 \x01(\x03R\texpiresAt\x12-\n" +
+	"\x12replication_factor\x18\x03 \x01(\x05R\x11replicationFactor\x12\x1c\n" +
+	"\timmutable\x18\x04 \x01(\bR\timmutable\x12!\n" +
+	"\fretain_until\x18\x05 \x01(\x03R\vretainUntil\x12!\n" +
+	"\fstorage_tier\x18\x06 \x01(\tR\vstorageTier\x12#\n" +
+	"\rstorage_class\x18\a \x01(\tR\fstorageClass\x1a7\n" +
+	"\tTagsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"6\n" +
+	"\x18GetFileAttributesRequest\x12\x1a\n" +
+	"\bfilename\x18\x01 \x01(\tR\bfilename\"P\n" +
+	"\x19GetFileAttributesResponse\x123\n" +
+	"\n" +
+	"attributes\x18\x01 \x01(\v2\x13.dfs.FileAttributesR\n" +
+	"attributes\"\xd0\x03\n" +
+	"\x18SetFileAttributesRequest\x12\x1a\n" +
+	"\bfilename\x18\x01 \x01(\tR\bfilename\x12E\n" +
+	"\bset_tags\x18\x02 \x03(\v2*.dfs.SetFileAttributesRequest.SetTagsEntryR\asetTags\x12\x1f\n" +
+	"\vremove_tags\x18\x03 \x03(\tR\n" +
+	"removeTags\x12$\n" +
+	"\vttl_seconds\x18\x04 \x01(\x03H\x00R\n" +
+	"ttlSeconds\x88\x01\x01\x122\n" +
+	"\x12replication_factor\x18\x05 \x01(\x05H\x01R\x11replicationFactor\x88\x01\x01\x12&\n" +
+	"\fstorage_tier\x18\x06 \x01(\tH\x02R\vstorageTier\x88\x01\x01\x12(\n" +
+	"\rstorage_class\x18\a \x01(\tH\x03R\fstorageClass\x88\x01\x01\x1a:\n" +
+	"\fSetTagsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B\x0e\n" +
+	"\f_ttl_secondsB\x15\n" +
+	"\x13_replication_factorB\x0f\n" +
+	"\r_storage_tierB\x10\n" +
+	"\x0e_storage_class\"P\n" +
+	"\x19SetFileAttributesResponse\x123\n" +
+	"\n" +
+	"attributes\x18\x01 \x01(\v2\x13.dfs.FileAttributesR\n" +
+	"attributes\"*\n" +
+	"\x10DiskUsageRequest\x12\x16\n" +
+	"\x06prefix\x18\x01 \x01(\tR\x06prefix\"\x8f\x01\n" +
+	"\x0eDiskUsageEntry\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12#\n" +
+	"\rlogical_bytes\x18\x02 \x01(\x03R\flogicalBytes\x12%\n" +
+	"\x0ephysical_bytes\x18\x03 \x01(\x03R\rphysicalBytes\x12\x1d\n" +
+	"\n" +
+	"file_count\x18\x04 \x01(\x03R\tfileCount\"m\n" +
+	"\x11DiskUsageResponse\x12)\n" +
+	"\x05total\x18\x01 \x01(\v2\x13.dfs.DiskUsageEntryR\x05total\x12-\n" +
+	"\aentries\x18\x02 \x03(\v2\x13.dfs.DiskUsageEntryR\aentries\"U\n" +
+	"\x1aListUnaccessedFilesRequest\x12!\n" +
+	"\fidle_seconds\x18\x01 \x01(\x03R\vidleSeconds\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\"B\n" +
+	"\x1bListUnaccessedFilesResponse\x12#\n" +
+	"\x05files\x18\x01 \x03(\v2\r.dfs.FileInfoR\x05files\"\x1d\n" +
+	"\x1bGetChunkDistributionRequest\"\xf6\x02\n" +
+	"\x10ChunkServerUsage\x12\x18\n" +
+	"\aaddress\x18\x01 \x01(\tR\aaddress\x12\x1f\n" +
+	"\vchunk_count\x18\x02 \x01(\x03R\n" +
+	"chunkCount\x12\x14\n" +
+	"\x05bytes\x18\x03 \x01(\x03R\x05bytes\x12%\n" +
+	"\x0ecapacity_bytes\x18\x04 \x01(\x03R\rcapacityBytes\x12\x1d\n" +
+	"\n" +
+	"free_bytes\x18\x05 \x01(\x03R\tfreeBytes\x12\x14\n" +
+	"\x05state\x18\x06 \x01(\tR\x05state\x12%\n" +
+	"\x0elast_heartbeat\x18\a \x01(\x03R\rlastHeartbeat\x12\x18\n" +
+	"\aversion\x18\b \x01(\tR\aversion\x129\n" +
+	"\x06labels\x18\t \x03(\v2!.dfs.ChunkServerUsage.LabelsEntryR\x06labels\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"P\n" +
+	"\x11ReplicationBucket\x12\x1a\n" +
+	"\breplicas\x18\x01 \x01(\x05R\breplicas\x12\x1f\n" +
+	"\vchunk_count\x18\x02 \x01(\x03R\n" +
+	"chunkCount\"\xcb\x01\n" +
+	"\x1cGetChunkDistributionResponse\x12/\n" +
+	"\aservers\x18\x01 \x03(\v2\x15.dfs.ChunkServerUsageR\aservers\x12K\n" +
+	"\x15replication_histogram\x18\x02 \x03(\v2\x16.dfs.ReplicationBucketR\x14replicationHistogram\x12-\n" +
+	"\x12replication_factor\x18\x03 \x01(\x05R\x11replicationFactor\"\x18\n" +
+	"\x16GetClusterStatsRequest\"\x90\x03\n" +
+	"\x17GetClusterStatsResponse\x12%\n" +
+	"\x0ecapacity_bytes\x18\x01 \x01(\x03R\rcapacityBytes\x12\x1d\n" +
+	"\n" +
+	"used_bytes\x18\x02 \x01(\x03R\tusedBytes\x12\x1d\n" +
+	"\n" +
+	"free_bytes\x18\x03 \x01(\x03R\tfreeBytes\x12\x1d\n" +
+	"\n" +
+	"file_count\x18\x04 \x01(\x03R\tfileCount\x12\x1f\n" +
+	"\vchunk_count\x18\x05 \x01(\x03R\n" +
+	"chunkCount\x12,\n" +
+	"\x12live_chunk_servers\x18\x06 \x01(\x05R\x10liveChunkServers\x12,\n" +
+	"\x12dead_chunk_servers\x18\a \x01(\x05R\x10deadChunkServers\x126\n" +
+	"\x17under_replicated_chunks\x18\b \x01(\x03R\x15underReplicatedChunks\x12<\n" +
+	"\x1apending_reclamation_chunks\x18\t \x01(\x03R\x18pendingReclamationChunks\"\x17\n" +
+	"\x15ReclaimDeletedRequest\"C\n" +
+	"\x16ReclaimDeletedResponse\x12)\n" +
+	"\x10reclaimed_chunks\x18\x01 \x01(\x03R\x0freclaimedChunks\" \n" +
+	"\x1eGetGeoReplicationStatusRequest\"\xed\x03\n" +
+	"\x1fGetGeoReplicationStatusResponse\x12\x18\n" +
+	"\aenabled\x18\x01 \x01(\bR\aenabled\x12#\n" +
+	"\rremote_master\x18\x02 \x01(\tR\fremoteMaster\x12\x1a\n" +
+	"\bprefixes\x18\x03 \x03(\tR\bprefixes\x12'\n" +
+	"\x0fconflict_policy\x18\x04 \x01(\tR\x0econflictPolicy\x12#\n" +
+	"\rpending_files\x18\x05 \x01(\x03R\fpendingFiles\x12\x1f\n" +
+	"\vlag_seconds\x18\x06 \x01(\x03R\n" +
+	"lagSeconds\x12)\n" +
+	"\x10replicated_files\x18\a \x01(\x03R\x0freplicatedFiles\x12)\n" +
+	"\x10replicated_bytes\x18\b \x01(\x03R\x0freplicatedBytes\x12#\n" +
+	"\rdeleted_files\x18\t \x01(\x03R\fdeletedFiles\x12\x1c\n" +
+	"\tconflicts\x18\n" +
+	" \x01(\x03R\tconflicts\x12\x1a\n" +
+	"\bfailures\x18\v \x01(\x03R\bfailures\x12,\n" +
+	"\x12last_replicated_at\x18\f \x01(\x03R\x10lastReplicatedAt\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\r \x01(\tR\tlastError\"U\n" +
+	"\x16PresignDownloadRequest\x12\x1a\n" +
+	"\bfilename\x18\x01 \x01(\tR\bfilename\x12\x1f\n" +
+	"\vttl_seconds\x18\x02 \x01(\x03R\n" +
+	"ttlSeconds\"j\n" +
+	"\x17PresignDownloadResponse\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x02 \x01(\x03R\texpiresAt\x12\x1e\n" +
+	"\n" +
+	"generation\x18\x03 \x01(\x03R\n" +
+	"generation\"\x0f\n" +
+	"\rWhoAmIRequest\"\xd2\x01\n" +
+	"\x0eWhoAmIResponse\x12\x12\n" +
+	"\x04user\x18\x01 \x01(\tR\x04user\x12\x16\n" +
+	"\x06groups\x18\x02 \x03(\tR\x06groups\x12$\n" +
+	"\rauthenticated\x18\x03 \x01(\bR\rauthenticated\x12\x1c\n" +
+	"\tsuperuser\x18\x04 \x01(\bR\tsuperuser\x12#\n" +
+	"\rdelegation_id\x18\x05 \x01(\tR\fdelegationId\x12+\n" +
+	"\x11delegation_prefix\x18\x06 \x01(\tR\x10delegationPrefix\"\x86\x01\n" +
+	"\x19GetDelegationTokenRequest\x12\x16\n" +
+	"\x06prefix\x18\x01 \x01(\tR\x06prefix\x12\x1f\n" +
+	"\vttl_seconds\x18\x02 \x01(\x03R\n" +
+	"ttlSeconds\x120\n" +
+	"\x14max_lifetime_seconds\x18\x03 \x01(\x03R\x12maxLifetimeSeconds\"\x87\x01\n" +
+	"\x1aGetDelegationTokenResponse\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x03 \x01(\x03R\texpiresAt\x12$\n" +
+	"\x0emax_expires_at\x18\x04 \x01(\x03R\fmaxExpiresAt\"T\n" +
+	"\x1bRenewDelegationTokenRequest\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12\x1f\n" +
+	"\vttl_seconds\x18\x02 \x01(\x03R\n" +
+	"ttlSeconds\"S\n" +
+	"\x1cRenewDelegationTokenResponse\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x02 \x01(\x03R\texpiresAt\"D\n" +
+	"\x1cRevokeDelegationTokenRequest\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\"\x1f\n" +
+	"\x1dRevokeDelegationTokenResponse\"D\n" +
+	"\x12SetFileModeRequest\x12\x1a\n" +
+	"\bfilename\x18\x01 \x01(\tR\bfilename\x12\x12\n" +
+	"\x04mode\x18\x02 \x01(\rR\x04mode\"8\n" +
+	"\x13SetFileModeResponse\x12!\n" +
+	"\x04file\x18\x01 \x01(\v2\r.dfs.FileInfoR\x04file\"]\n" +
+	"\x13SetFileOwnerRequest\x12\x1a\n" +
+	"\bfilename\x18\x01 \x01(\tR\bfilename\x12\x14\n" +
+	"\x05owner\x18\x02 \x01(\tR\x05owner\x12\x14\n" +
+	"\x05group\x18\x03 \x01(\tR\x05group\"9\n" +
+	"\x14SetFileOwnerResponse\x12!\n" +
+	"\x04file\x18\x01 \x01(\v2\r.dfs.FileInfoR\x04file\"g\n" +
+	"\x12SymlinkFileRequest\x12\x1b\n" +
+	"\tlink_name\x18\x01 \x01(\tR\blinkName\x12\x16\n" +
+	"\x06target\x18\x02 \x01(\tR\x06target\x12\x1c\n" +
+	"\toverwrite\x18\x03 \x01(\bR\toverwrite\"5\n" +
+	"\x13SymlinkFileResponse\x12\x1e\n" +
+	"\n" +
+	"generation\x18\x01 \x01(\x03R\n" +
+	"generation\"m\n" +
+	"\x0fLinkFileRequest\x12'\n" +
+	"\x0fsource_filename\x18\x01 \x01(\tR\x0esourceFilename\x121\n" +
+	"\x14destination_filename\x18\x02 \x01(\tR\x13destinationFilename\"2\n" +
+	"\x10LinkFileResponse\x12\x1e\n" +
+	"\n" +
+	"generation\x18\x01 \x01(\x03R\n" +
+	"generation\"\x16\n" +
+	"\x14GetServerInfoRequest\"\x84\x01\n" +
+	"\x15GetServerInfoResponse\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\tR\aversion\x12\x16\n" +
+	"\x06commit\x18\x02 \x01(\tR\x06commit\x12\x1d\n" +
+	"\n" +
+	"go_version\x18\x03 \x01(\tR\tgoVersion\x12\x1a\n" +
+	"\bfeatures\x18\x04 \x03(\tR\bfeatures\"\xa0\x03\n" +
 	"\x11WriteChunkRequest\x12!\n" +
 	"\fchunk_handle\x18\x01 \x01(\tR\vchunkHandle\x12\x12\n" +
 	"\x04data\x18\x02 \x01(\fR\x04data\x12\x1f\n" +
 	"\vchunk_index\x18\x03 \x01(\x05R\n" +
-	"chunkIndex\".\n" +
+	"chunkIndex\x12#\n" +
+	"\rchunk_version\x18\x04 \x01(\x05R\fchunkVersion\x12\x1c\n" +
+	"\toverwrite\x18\x05 \x01(\bR\toverwrite\x12\x1f\n" +
+	"\bchecksum\x18\x06 \x01(\rH\x00R\bchecksum\x88\x01\x01\x12/\n" +
+	"\x13secondary_addresses\x18\a \x03(\tR\x12secondaryAddresses\x12(\n" +
+	"\x10lease_expires_at\x18\b \x01(\x03R\x0eleaseExpiresAt\x12!\n" +
+	"\faccess_token\x18\t \x01(\tR\vaccessToken\x12\x1f\n" +
+	"\vlease_token\x18\n" +
+	" \x01(\tR\n" +
+	"leaseToken\x12#\n" +
+	"\rforward_token\x18\v \x01(\tR\fforwardTokenB\v\n" +
+	"\t_checksum\"\x84\x01\n" +
 	"\x12WriteChunkResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\"5\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12-\n" +
+	"\x12failed_secondaries\x18\x02 \x03(\tR\x11failedSecondaries\x12%\n" +
+	"\x0equeue_pressure\x18\x03 \x01(\x02R\rqueuePressure\"\x88\x01\n" +
 	"\x10ReadChunkRequest\x12!\n" +
-	"\fchunk_handle\x18\x01 \x01(\tR\vchunkHandle\"'\n" +
+	"\fchunk_handle\x18\x01 \x01(\tR\vchunkHandle\x12\x16\n" +
+	"\x06offset\x18\x02 \x01(\x03R\x06offset\x12\x16\n" +
+	"\x06length\x18\x03 \x01(\x03R\x06length\x12!\n" +
+	"\faccess_token\x18\x04 \x01(\tR\vaccessToken\"U\n" +
 	"\x11ReadChunkResponse\x12\x12\n" +
-	"\x04data\x18\x01 \x01(\fR\x04data2\xc6\x02\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\x12\x1f\n" +
+	"\bchecksum\x18\x02 \x01(\rH\x00R\bchecksum\x88\x01\x01B\v\n" +
+	"\t_checksum\"\xf4\x01\n" +
+	"\x10CopyChunkRequest\x12.\n" +
+	"\x13source_chunk_handle\x18\x01 \x01(\tR\x11sourceChunkHandle\x128\n" +
+	"\x18destination_chunk_handle\x18\x02 \x01(\tR\x16destinationChunkHandle\x12#\n" +
+	"\rchunk_version\x18\x03 \x01(\x05R\fchunkVersion\x12!\n" +
+	"\faccess_token\x18\x04 \x01(\tR\vaccessToken\x12.\n" +
+	"\x13source_access_token\x18\x05 \x01(\tR\x11sourceAccessToken\"-\n" +
+	"\x11CopyChunkResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"Z\n" +
+	"\x12DeleteChunkRequest\x12!\n" +
+	"\fchunk_handle\x18\x01 \x01(\tR\vchunkHandle\x12!\n" +
+	"\faccess_token\x18\x02 \x01(\tR\vaccessToken\"/\n" +
+	"\x13DeleteChunkResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"Z\n" +
+	"\x12VerifyChunkRequest\x12!\n" +
+	"\fchunk_handle\x18\x01 \x01(\tR\vchunkHandle\x12!\n" +
+	"\faccess_token\x18\x02 \x01(\tR\vaccessToken\"/\n" +
+	"\x13VerifyChunkResponse\x12\x18\n" +
+	"\acorrupt\x18\x01 \x01(\bR\acorrupt\"\xd9\x01\n" +
+	"\x15ReplicateChunkRequest\x12!\n" +
+	"\fchunk_handle\x18\x01 \x01(\tR\vchunkHandle\x12%\n" +
+	"\x0esource_address\x18\x02 \x01(\tR\rsourceAddress\x12#\n" +
+	"\rchunk_version\x18\x03 \x01(\x05R\fchunkVersion\x12!\n" +
+	"\faccess_token\x18\x04 \x01(\tR\vaccessToken\x12.\n" +
+	"\x13source_access_token\x18\x05 \x01(\tR\x11sourceAccessToken\"2\n" +
+	"\x16ReplicateChunkResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"s\n" +
+	"\rArchivedChunk\x12!\n" +
+	"\fchunk_handle\x18\x01 \x01(\tR\vchunkHandle\x12\x1c\n" +
+	"\taddresses\x18\x02 \x03(\tR\taddresses\x12!\n" +
+	"\faccess_token\x18\x03 \x01(\tR\vaccessToken\"\x98\x01\n" +
+	"\rRecalledChunk\x12!\n" +
+	"\fchunk_handle\x18\x01 \x01(\tR\vchunkHandle\x12#\n" +
+	"\rchunk_version\x18\x02 \x01(\x05R\fchunkVersion\x12\x1c\n" +
+	"\taddresses\x18\x03 \x03(\tR\taddresses\x12!\n" +
+	"\faccess_token\x18\x04 \x01(\tR\vaccessToken\"\xbf\x01\n" +
+	"\x13RecallChunksRequest\x12;\n" +
+	"\x0farchived_chunks\x18\x01 \x03(\v2\x12.dfs.ArchivedChunkR\x0earchivedChunks\x12#\n" +
+	"\rarchived_size\x18\x02 \x01(\x03R\farchivedSize\x12\x1a\n" +
+	"\bfilesize\x18\x03 \x01(\x03R\bfilesize\x12*\n" +
+	"\x06chunks\x18\x04 \x03(\v2\x12.dfs.RecalledChunkR\x06chunks\"B\n" +
+	"\x14RecallChunksResponse\x12*\n" +
+	"\x06chunks\x18\x01 \x03(\v2\x12.dfs.RecalledChunkR\x06chunks\"\xac\x02\n" +
+	"\x13RecordAppendRequest\x12!\n" +
+	"\fchunk_handle\x18\x01 \x01(\tR\vchunkHandle\x12#\n" +
+	"\rchunk_version\x18\x02 \x01(\x05R\fchunkVersion\x12\x12\n" +
+	"\x04data\x18\x03 \x01(\fR\x04data\x12\x1a\n" +
+	"\bchecksum\x18\x04 \x01(\rR\bchecksum\x12/\n" +
+	"\x13secondary_addresses\x18\x05 \x03(\tR\x12secondaryAddresses\x12(\n" +
+	"\x10lease_expires_at\x18\x06 \x01(\x03R\x0eleaseExpiresAt\x12!\n" +
+	"\faccess_token\x18\a \x01(\tR\vaccessToken\x12\x1f\n" +
+	"\vlease_token\x18\b \x01(\tR\n" +
+	"leaseToken\"M\n" +
+	"\x14RecordAppendResponse\x12\x16\n" +
+	"\x06offset\x18\x01 \x01(\x03R\x06offset\x12\x1d\n" +
+	"\n" +
+	"chunk_full\x18\x02 \x01(\bR\tchunkFull\"\xec\x01\n" +
+	"\x12ApplyAppendRequest\x12!\n" +
+	"\fchunk_handle\x18\x01 \x01(\tR\vchunkHandle\x12#\n" +
+	"\rchunk_version\x18\x02 \x01(\x05R\fchunkVersion\x12\x16\n" +
+	"\x06offset\x18\x03 \x01(\x03R\x06offset\x12\x12\n" +
+	"\x04data\x18\x04 \x01(\fR\x04data\x12\x1a\n" +
+	"\bchecksum\x18\x05 \x01(\rR\bchecksum\x12!\n" +
+	"\faccess_token\x18\x06 \x01(\tR\vaccessToken\x12#\n" +
+	"\rforward_token\x18\a \x01(\tR\fforwardToken\"/\n" +
+	"\x13ApplyAppendResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess*M\n" +
+	"\vListSortKey\x12\x12\n" +
+	"\x0eLIST_SORT_NAME\x10\x00\x12\x12\n" +
+	"\x0eLIST_SORT_SIZE\x10\x01\x12\x16\n" +
+	"\x12LIST_SORT_MODIFIED\x10\x02*s\n" +
+	"\rFileEventType\x12\x1a\n" +
+	"\x16FILE_EVENT_UNSPECIFIED\x10\x00\x12\x16\n" +
+	"\x12FILE_EVENT_CREATED\x10\x01\x12\x16\n" +
+	"\x12FILE_EVENT_DELETED\x10\x02\x12\x16\n" +
+	"\x12FILE_EVENT_RENAMED\x10\x032\xf3\x17\n" +
 	"\x06Master\x12=\n" +
 	"\n" +
-	"UploadFile\x12\x16.dfs.UploadFileRequest\x1a\x17.dfs.UploadFileResponse\x12C\n" +
+	"UploadFile\x12\x16.dfs.UploadFileRequest\x1a\x17.dfs.UploadFileResponse\x12I\n" +
+	"\x0eCompleteUpload\x12\x1a.dfs.CompleteUploadRequest\x1a\x1b.dfs.CompleteUploadResponse\x12@\n" +
+	"\vRenewUpload\x12\x17.dfs.RenewUploadRequest\x1a\x18.dfs.RenewUploadResponse\x12F\n" +
+	"\rAllocateChunk\x12\x19.dfs.AllocateChunkRequest\x1a\x1a.dfs.AllocateChunkResponse\x12C\n" +
 	"\fDownloadFile\x12\x18.dfs.DownloadFileRequest\x1a\x19.dfs.DownloadFileResponse\x12:\n" +
-	"\tListFiles\x12\x15.dfs.ListFilesRequest\x1a\x16.dfs.ListFilesResponse\x12:\n" +
-	"\tHeartbeat\x12\x15.dfs.HeartbeatRequest\x1a\x16.dfs.HeartbeatResponse\x12@\n" +
-	"\vReportChunk\x12\x17.dfs.ReportChunkRequest\x1a\x18.dfs.ReportChunkResponse2\x88\x01\n" +
+	"\tListFiles\x12\x15.dfs.ListFilesRequest\x1a\x16.dfs.ListFilesResponse\x12B\n" +
+	"\x0fListFilesStream\x12\x15.dfs.ListFilesRequest\x1a\x16.dfs.ListFilesResponse0\x01\x12@\n" +
+	"\vSearchFiles\x12\x17.dfs.SearchFilesRequest\x1a\x18.dfs.SearchFilesResponse\x12:\n" +
+	"\tHeartbeat\x12\x15.dfs.HeartbeatRequest\x1a\x16.dfs.HeartbeatResponse\x12X\n" +
+	"\x13RegisterChunkServer\x12\x1f.dfs.RegisterChunkServerRequest\x1a .dfs.RegisterChunkServerResponse\x12@\n" +
+	"\vReportChunk\x12\x17.dfs.ReportChunkRequest\x1a\x18.dfs.ReportChunkResponse\x127\n" +
+	"\bCopyFile\x12\x14.dfs.CopyFileRequest\x1a\x15.dfs.CopyFileResponse\x12:\n" +
+	"\tCloneFile\x12\x15.dfs.CloneFileRequest\x1a\x16.dfs.CloneFileResponse\x12=\n" +
+	"\n" +
+	"RenameFile\x12\x16.dfs.RenameFileRequest\x1a\x17.dfs.RenameFileResponse\x12,\n" +
+	"\x05Watch\x12\x11.dfs.WatchRequest\x1a\x0e.dfs.FileEvent0\x01\x12=\n" +
+	"\n" +
+	"DeleteFile\x12\x16.dfs.DeleteFileRequest\x1a\x17.dfs.DeleteFileResponse\x12C\n" +
+	"\fUndeleteFile\x12\x18.dfs.UndeleteFileRequest\x1a\x19.dfs.UndeleteFileResponse\x12@\n" +
+	"\vGetFileInfo\x12\x17.dfs.GetFileInfoRequest\x1a\x18.dfs.GetFileInfoResponse\x12I\n" +
+	"\x0eUpdateFileTags\x12\x1a.dfs.UpdateFileTagsRequest\x1a\x1b.dfs.UpdateFileTagsResponse\x12R\n" +
+	"\x11GetFileAttributes\x12\x1d.dfs.GetFileAttributesRequest\x1a\x1e.dfs.GetFileAttributesResponse\x12R\n" +
+	"\x11SetFileAttributes\x12\x1d.dfs.SetFileAttributesRequest\x1a\x1e.dfs.SetFileAttributesResponse\x12O\n" +
+	"\x10ListFileVersions\x12\x1c.dfs.ListFileVersionsRequest\x1a\x1d.dfs.ListFileVersionsResponse\x12:\n" +
+	"\tDiskUsage\x12\x15.dfs.DiskUsageRequest\x1a\x16.dfs.DiskUsageResponse\x12[\n" +
+	"\x14GetChunkDistribution\x12 .dfs.GetChunkDistributionRequest\x1a!.dfs.GetChunkDistributionResponse\x12O\n" +
+	"\x10ReportLostChunks\x12\x1c.dfs.ReportLostChunksRequest\x1a\x1d.dfs.ReportLostChunksResponse\x12U\n" +
+	"\x12ReportCorruptChunk\x12\x1e.dfs.ReportCorruptChunkRequest\x1a\x1f.dfs.ReportCorruptChunkResponse\x12X\n" +
+	"\x13ListUnaccessedFiles\x12\x1f.dfs.ListUnaccessedFilesRequest\x1a .dfs.ListUnaccessedFilesResponse\x12L\n" +
+	"\x0fGetClusterStats\x12\x1b.dfs.GetClusterStatsRequest\x1a\x1c.dfs.GetClusterStatsResponse\x12F\n" +
+	"\rPrepareAppend\x12\x19.dfs.PrepareAppendRequest\x1a\x1a.dfs.PrepareAppendResponse\x12I\n" +
+	"\x0eCompleteAppend\x12\x1a.dfs.CompleteAppendRequest\x1a\x1b.dfs.CompleteAppendResponse\x12d\n" +
+	"\x17GetGeoReplicationStatus\x12#.dfs.GetGeoReplicationStatusRequest\x1a$.dfs.GetGeoReplicationStatusResponse\x12I\n" +
+	"\x0eReclaimDeleted\x12\x1a.dfs.ReclaimDeletedRequest\x1a\x1b.dfs.ReclaimDeletedResponse\x12F\n" +
+	"\rGetServerInfo\x12\x19.dfs.GetServerInfoRequest\x1a\x1a.dfs.GetServerInfoResponse\x12L\n" +
+	"\x0fPresignDownload\x12\x1b.dfs.PresignDownloadRequest\x1a\x1c.dfs.PresignDownloadResponse\x121\n" +
+	"\x06WhoAmI\x12\x12.dfs.WhoAmIRequest\x1a\x13.dfs.WhoAmIResponse\x12U\n" +
+	"\x12GetDelegationToken\x12\x1e.dfs.GetDelegationTokenRequest\x1a\x1f.dfs.GetDelegationTokenResponse\x12[\n" +
+	"\x14RenewDelegationToken\x12 .dfs.RenewDelegationTokenRequest\x1a!.dfs.RenewDelegationTokenResponse\x12^\n" +
+	"\x15RevokeDelegationToken\x12!.dfs.RevokeDelegationTokenRequest\x1a\".dfs.RevokeDelegationTokenResponse\x12@\n" +
+	"\vSetFileMode\x12\x17.dfs.SetFileModeRequest\x1a\x18.dfs.SetFileModeResponse\x12C\n" +
+	"\fSetFileOwner\x12\x18.dfs.SetFileOwnerRequest\x1a\x19.dfs.SetFileOwnerResponse\x12@\n" +
+	"\vSymlinkFile\x12\x17.dfs.SymlinkFileRequest\x1a\x18.dfs.SymlinkFileResponse\x127\n" +
+	"\bLinkFile\x12\x14.dfs.LinkFileRequest\x1a\x15.dfs.LinkFileResponse2\xeb\x05\n" +
 	"\vChunkServer\x12=\n" +
 	"\n" +
 	"WriteChunk\x12\x16.dfs.WriteChunkRequest\x1a\x17.dfs.WriteChunkResponse\x12:\n" +
-	"\tReadChunk\x12\x15.dfs.ReadChunkRequest\x1a\x16.dfs.ReadChunkResponseB\bZ\x06/protob\x06proto3"
+	"\tReadChunk\x12\x15.dfs.ReadChunkRequest\x1a\x16.dfs.ReadChunkResponse\x12B\n" +
+	"\x0fReadChunkStream\x12\x15.dfs.ReadChunkRequest\x1a\x16.dfs.ReadChunkResponse0\x01\x12:\n" +
+	"\tCopyChunk\x12\x15.dfs.CopyChunkRequest\x1a\x16.dfs.CopyChunkResponse\x12@\n" +
+	"\vDeleteChunk\x12\x17.dfs.DeleteChunkRequest\x1a\x18.dfs.DeleteChunkResponse\x12I\n" +
+	"\x0eReplicateChunk\x12\x1a.dfs.ReplicateChunkRequest\x1a\x1b.dfs.ReplicateChunkResponse\x12C\n" +
+	"\fRecordAppend\x12\x18.dfs.RecordAppendRequest\x1a\x19.dfs.RecordAppendResponse\x12@\n" +
+	"\vApplyAppend\x12\x17.dfs.ApplyAppendRequest\x1a\x18.dfs.ApplyAppendResponse\x12F\n" +
+	"\rGetServerInfo\x12\x19.dfs.GetServerInfoRequest\x1a\x1a.dfs.GetServerInfoResponse\x12C\n" +
+	"\fRecallChunks\x12\x18.dfs.RecallChunksRequest\x1a\x19.dfs.RecallChunksResponse\x12@\n" +
+	"\vVerifyChunk\x12\x17.dfs.VerifyChunkRequest\x1a\x18.dfs.VerifyChunkResponseB\bZ\x06/protob\x06proto3"
 
 var (
 	file_proto_dfs_proto_rawDescOnce sync.Once
@@ -872,48 +7710,284 @@ func file_proto_dfs_proto_rawDescGZIP() []byte {
 	return file_proto_dfs_proto_rawDescData
 }
 
-var file_proto_dfs_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
+var file_proto_dfs_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_proto_dfs_proto_msgTypes = make([]protoimpl.MessageInfo, 123)
 var file_proto_dfs_proto_goTypes = []any{
-	(*UploadFileRequest)(nil),    // 0: dfs.UploadFileRequest
-	(*ChunkLocation)(nil),        // 1: dfs.ChunkLocation
-	(*UploadFileResponse)(nil),   // 2: dfs.UploadFileResponse
-	(*DownloadFileRequest)(nil),  // 3: dfs.DownloadFileRequest
-	(*DownloadFileResponse)(nil), // 4: dfs.DownloadFileResponse
-	(*ListFilesRequest)(nil),     // 5: dfs.ListFilesRequest
-	(*FileInfo)(nil),             // 6: dfs.FileInfo
-	(*ListFilesResponse)(nil),    // 7: dfs.ListFilesResponse
-	(*HeartbeatRequest)(nil),     // 8: dfs.HeartbeatRequest
-	(*HeartbeatResponse)(nil),    // 9: dfs.HeartbeatResponse
-	(*ReportChunkRequest)(nil),   // 10: dfs.ReportChunkRequest
-	(*ReportChunkResponse)(nil),  // 11: dfs.ReportChunkResponse
-	(*WriteChunkRequest)(nil),    // 12: dfs.WriteChunkRequest
-	(*WriteChunkResponse)(nil),   // 13: dfs.WriteChunkResponse
-	(*ReadChunkRequest)(nil),     // 14: dfs.ReadChunkRequest
-	(*ReadChunkResponse)(nil),    // 15: dfs.ReadChunkResponse
+	(ListSortKey)(0),                        // 0: dfs.ListSortKey
+	(FileEventType)(0),                      // 1: dfs.FileEventType
+	(*UploadFileRequest)(nil),               // 2: dfs.UploadFileRequest
+	(*PlacementHints)(nil),                  // 3: dfs.PlacementHints
+	(*ChunkLocation)(nil),                   // 4: dfs.ChunkLocation
+	(*UploadFileResponse)(nil),              // 5: dfs.UploadFileResponse
+	(*CompleteUploadRequest)(nil),           // 6: dfs.CompleteUploadRequest
+	(*CompleteUploadResponse)(nil),          // 7: dfs.CompleteUploadResponse
+	(*RenewUploadRequest)(nil),              // 8: dfs.RenewUploadRequest
+	(*RenewUploadResponse)(nil),             // 9: dfs.RenewUploadResponse
+	(*AllocateChunkRequest)(nil),            // 10: dfs.AllocateChunkRequest
+	(*AllocateChunkResponse)(nil),           // 11: dfs.AllocateChunkResponse
+	(*PrepareAppendRequest)(nil),            // 12: dfs.PrepareAppendRequest
+	(*PrepareAppendResponse)(nil),           // 13: dfs.PrepareAppendResponse
+	(*CompleteAppendRequest)(nil),           // 14: dfs.CompleteAppendRequest
+	(*CompleteAppendResponse)(nil),          // 15: dfs.CompleteAppendResponse
+	(*DownloadFileRequest)(nil),             // 16: dfs.DownloadFileRequest
+	(*DownloadFileResponse)(nil),            // 17: dfs.DownloadFileResponse
+	(*ListFilesRequest)(nil),                // 18: dfs.ListFilesRequest
+	(*FileInfo)(nil),                        // 19: dfs.FileInfo
+	(*ListFilesResponse)(nil),               // 20: dfs.ListFilesResponse
+	(*SearchFilesRequest)(nil),              // 21: dfs.SearchFilesRequest
+	(*SearchFilesResponse)(nil),             // 22: dfs.SearchFilesResponse
+	(*HeartbeatRequest)(nil),                // 23: dfs.HeartbeatRequest
+	(*LoadMetrics)(nil),                     // 24: dfs.LoadMetrics
+	(*HeartbeatResponse)(nil),               // 25: dfs.HeartbeatResponse
+	(*RegisterChunkServerRequest)(nil),      // 26: dfs.RegisterChunkServerRequest
+	(*StorageDirectory)(nil),                // 27: dfs.StorageDirectory
+	(*RegisterChunkServerResponse)(nil),     // 28: dfs.RegisterChunkServerResponse
+	(*ReportChunkRequest)(nil),              // 29: dfs.ReportChunkRequest
+	(*ReportChunkResponse)(nil),             // 30: dfs.ReportChunkResponse
+	(*ReportLostChunksRequest)(nil),         // 31: dfs.ReportLostChunksRequest
+	(*ReportLostChunksResponse)(nil),        // 32: dfs.ReportLostChunksResponse
+	(*ReportCorruptChunkRequest)(nil),       // 33: dfs.ReportCorruptChunkRequest
+	(*ReportCorruptChunkResponse)(nil),      // 34: dfs.ReportCorruptChunkResponse
+	(*CopyFileRequest)(nil),                 // 35: dfs.CopyFileRequest
+	(*CopyFileResponse)(nil),                // 36: dfs.CopyFileResponse
+	(*CloneFileRequest)(nil),                // 37: dfs.CloneFileRequest
+	(*CloneFileResponse)(nil),               // 38: dfs.CloneFileResponse
+	(*RenameFileRequest)(nil),               // 39: dfs.RenameFileRequest
+	(*RenameFileResponse)(nil),              // 40: dfs.RenameFileResponse
+	(*WatchRequest)(nil),                    // 41: dfs.WatchRequest
+	(*FileEvent)(nil),                       // 42: dfs.FileEvent
+	(*DeleteFileRequest)(nil),               // 43: dfs.DeleteFileRequest
+	(*DeleteFileResponse)(nil),              // 44: dfs.DeleteFileResponse
+	(*UndeleteFileRequest)(nil),             // 45: dfs.UndeleteFileRequest
+	(*UndeleteFileResponse)(nil),            // 46: dfs.UndeleteFileResponse
+	(*GetFileInfoRequest)(nil),              // 47: dfs.GetFileInfoRequest
+	(*GetFileInfoResponse)(nil),             // 48: dfs.GetFileInfoResponse
+	(*ListFileVersionsRequest)(nil),         // 49: dfs.ListFileVersionsRequest
+	(*FileVersion)(nil),                     // 50: dfs.FileVersion
+	(*ListFileVersionsResponse)(nil),        // 51: dfs.ListFileVersionsResponse
+	(*UpdateFileTagsRequest)(nil),           // 52: dfs.UpdateFileTagsRequest
+	(*UpdateFileTagsResponse)(nil),          // 53: dfs.UpdateFileTagsResponse
+	(*FileAttributes)(nil),                  // 54: dfs.FileAttributes
+	(*GetFileAttributesRequest)(nil),        // 55: dfs.GetFileAttributesRequest
+	(*GetFileAttributesResponse)(nil),       // 56: dfs.GetFileAttributesResponse
+	(*SetFileAttributesRequest)(nil),        // 57: dfs.SetFileAttributesRequest
+	(*SetFileAttributesResponse)(nil),       // 58: dfs.SetFileAttributesResponse
+	(*DiskUsageRequest)(nil),                // 59: dfs.DiskUsageRequest
+	(*DiskUsageEntry)(nil),                  // 60: dfs.DiskUsageEntry
+	(*DiskUsageResponse)(nil),               // 61: dfs.DiskUsageResponse
+	(*ListUnaccessedFilesRequest)(nil),      // 62: dfs.ListUnaccessedFilesRequest
+	(*ListUnaccessedFilesResponse)(nil),     // 63: dfs.ListUnaccessedFilesResponse
+	(*GetChunkDistributionRequest)(nil),     // 64: dfs.GetChunkDistributionRequest
+	(*ChunkServerUsage)(nil),                // 65: dfs.ChunkServerUsage
+	(*ReplicationBucket)(nil),               // 66: dfs.ReplicationBucket
+	(*GetChunkDistributionResponse)(nil),    // 67: dfs.GetChunkDistributionResponse
+	(*GetClusterStatsRequest)(nil),          // 68: dfs.GetClusterStatsRequest
+	(*GetClusterStatsResponse)(nil),         // 69: dfs.GetClusterStatsResponse
+	(*ReclaimDeletedRequest)(nil),           // 70: dfs.ReclaimDeletedRequest
+	(*ReclaimDeletedResponse)(nil),          // 71: dfs.ReclaimDeletedResponse
+	(*GetGeoReplicationStatusRequest)(nil),  // 72: dfs.GetGeoReplicationStatusRequest
+	(*GetGeoReplicationStatusResponse)(nil), // 73: dfs.GetGeoReplicationStatusResponse
+	(*PresignDownloadRequest)(nil),          // 74: dfs.PresignDownloadRequest
+	(*PresignDownloadResponse)(nil),         // 75: dfs.PresignDownloadResponse
+	(*WhoAmIRequest)(nil),                   // 76: dfs.WhoAmIRequest
+	(*WhoAmIResponse)(nil),                  // 77: dfs.WhoAmIResponse
+	(*GetDelegationTokenRequest)(nil),       // 78: dfs.GetDelegationTokenRequest
+	(*GetDelegationTokenResponse)(nil),      // 79: dfs.GetDelegationTokenResponse
+	(*RenewDelegationTokenRequest)(nil),     // 80: dfs.RenewDelegationTokenRequest
+	(*RenewDelegationTokenResponse)(nil),    // 81: dfs.RenewDelegationTokenResponse
+	(*RevokeDelegationTokenRequest)(nil),    // 82: dfs.RevokeDelegationTokenRequest
+	(*RevokeDelegationTokenResponse)(nil),   // 83: dfs.RevokeDelegationTokenResponse
+	(*SetFileModeRequest)(nil),              // 84: dfs.SetFileModeRequest
+	(*SetFileModeResponse)(nil),             // 85: dfs.SetFileModeResponse
+	(*SetFileOwnerRequest)(nil),             // 86: dfs.SetFileOwnerRequest
+	(*SetFileOwnerResponse)(nil),            // 87: dfs.SetFileOwnerResponse
+	(*SymlinkFileRequest)(nil),              // 88: dfs.SymlinkFileRequest
+	(*SymlinkFileResponse)(nil),             // 89: dfs.SymlinkFileResponse
+	(*LinkFileRequest)(nil),                 // 90: dfs.LinkFileRequest
+	(*LinkFileResponse)(nil),                // 91: dfs.LinkFileResponse
+	(*GetServerInfoRequest)(nil),            // 92: dfs.GetServerInfoRequest
+	(*GetServerInfoResponse)(nil),           // 93: dfs.GetServerInfoResponse
+	(*WriteChunkRequest)(nil),               // 94: dfs.WriteChunkRequest
+	(*WriteChunkResponse)(nil),              // 95: dfs.WriteChunkResponse
+	(*ReadChunkRequest)(nil),                // 96: dfs.ReadChunkRequest
+	(*ReadChunkResponse)(nil),               // 97: dfs.ReadChunkResponse
+	(*CopyChunkRequest)(nil),                // 98: dfs.CopyChunkRequest
+	(*CopyChunkResponse)(nil),               // 99: dfs.CopyChunkResponse
+	(*DeleteChunkRequest)(nil),              // 100: dfs.DeleteChunkRequest
+	(*DeleteChunkResponse)(nil),             // 101: dfs.DeleteChunkResponse
+	(*VerifyChunkRequest)(nil),              // 102: dfs.VerifyChunkRequest
+	(*VerifyChunkResponse)(nil),             // 103: dfs.VerifyChunkResponse
+	(*ReplicateChunkRequest)(nil),           // 104: dfs.ReplicateChunkRequest
+	(*ReplicateChunkResponse)(nil),          // 105: dfs.ReplicateChunkResponse
+	(*ArchivedChunk)(nil),                   // 106: dfs.ArchivedChunk
+	(*RecalledChunk)(nil),                   // 107: dfs.RecalledChunk
+	(*RecallChunksRequest)(nil),             // 108: dfs.RecallChunksRequest
+	(*RecallChunksResponse)(nil),            // 109: dfs.RecallChunksResponse
+	(*RecordAppendRequest)(nil),             // 110: dfs.RecordAppendRequest
+	(*RecordAppendResponse)(nil),            // 111: dfs.RecordAppendResponse
+	(*ApplyAppendRequest)(nil),              // 112: dfs.ApplyAppendRequest
+	(*ApplyAppendResponse)(nil),             // 113: dfs.ApplyAppendResponse
+	nil,                                     // 114: dfs.UploadFileRequest.TagsEntry
+	nil,                                     // 115: dfs.ListFilesRequest.TagsEntry
+	nil,                                     // 116: dfs.FileInfo.TagsEntry
+	nil,                                     // 117: dfs.SearchFilesRequest.TagsEntry
+	nil,                                     // 118: dfs.HeartbeatRequest.ChunkReadsEntry
+	nil,                                     // 119: dfs.RegisterChunkServerRequest.LabelsEntry
+	nil,                                     // 120: dfs.UpdateFileTagsRequest.SetEntry
+	nil,                                     // 121: dfs.UpdateFileTagsResponse.TagsEntry
+	nil,                                     // 122: dfs.FileAttributes.TagsEntry
+	nil,                                     // 123: dfs.SetFileAttributesRequest.SetTagsEntry
+	nil,                                     // 124: dfs.ChunkServerUsage.LabelsEntry
 }
 var file_proto_dfs_proto_depIdxs = []int32{
-	1,  // 0: dfs.UploadFileResponse.chunk_locations:type_name -> dfs.ChunkLocation
-	1,  // 1: dfs.DownloadFileResponse.chunk_location:type_name -> dfs.ChunkLocation
-	6,  // 2: dfs.ListFilesResponse.files:type_name -> dfs.FileInfo
-	0,  // 3: dfs.Master.UploadFile:input_type -> dfs.UploadFileRequest
-	3,  // 4: dfs.Master.DownloadFile:input_type -> dfs.DownloadFileRequest
-	5,  // 5: dfs.Master.ListFiles:input_type -> dfs.ListFilesRequest
-	8,  // 6: dfs.Master.Heartbeat:input_type -> dfs.HeartbeatRequest
-	10, // 7: dfs.Master.ReportChunk:input_type -> dfs.ReportChunkRequest
-	12, // 8: dfs.ChunkServer.WriteChunk:input_type -> dfs.WriteChunkRequest
-	14, // 9: dfs.ChunkServer.ReadChunk:input_type -> dfs.ReadChunkRequest
-	2,  // 10: dfs.Master.UploadFile:output_type -> dfs.UploadFileResponse
-	4,  // 11: dfs.Master.DownloadFile:output_type -> dfs.DownloadFileResponse
-	7,  // 12: dfs.Master.ListFiles:output_type -> dfs.ListFilesResponse
-	9,  // 13: dfs.Master.Heartbeat:output_type -> dfs.HeartbeatResponse
-	11, // 14: dfs.Master.ReportChunk:output_type -> dfs.ReportChunkResponse
-	13, // 15: dfs.ChunkServer.WriteChunk:output_type -> dfs.WriteChunkResponse
-	15, // 16: dfs.ChunkServer.ReadChunk:output_type -> dfs.ReadChunkResponse
-	10, // [10:17] is the sub-list for method output_type
-	3,  // [3:10] is the sub-list for method input_type
-	3,  // [3:3] is the sub-list for extension type_name
-	3,  // [3:3] is the sub-list for extension extendee
-	0,  // [0:3] is the sub-list for field type_name
+	3,   // 0: dfs.UploadFileRequest.hints:type_name -> dfs.PlacementHints
+	114, // 1: dfs.UploadFileRequest.tags:type_name -> dfs.UploadFileRequest.TagsEntry
+	4,   // 2: dfs.UploadFileResponse.chunk_locations:type_name -> dfs.ChunkLocation
+	4,   // 3: dfs.AllocateChunkResponse.chunk_location:type_name -> dfs.ChunkLocation
+	4,   // 4: dfs.PrepareAppendResponse.chunk_location:type_name -> dfs.ChunkLocation
+	4,   // 5: dfs.DownloadFileResponse.chunk_location:type_name -> dfs.ChunkLocation
+	115, // 6: dfs.ListFilesRequest.tags:type_name -> dfs.ListFilesRequest.TagsEntry
+	0,   // 7: dfs.ListFilesRequest.sort_by:type_name -> dfs.ListSortKey
+	116, // 8: dfs.FileInfo.tags:type_name -> dfs.FileInfo.TagsEntry
+	19,  // 9: dfs.ListFilesResponse.files:type_name -> dfs.FileInfo
+	117, // 10: dfs.SearchFilesRequest.tags:type_name -> dfs.SearchFilesRequest.TagsEntry
+	19,  // 11: dfs.SearchFilesResponse.files:type_name -> dfs.FileInfo
+	24,  // 12: dfs.HeartbeatRequest.load:type_name -> dfs.LoadMetrics
+	118, // 13: dfs.HeartbeatRequest.chunk_reads:type_name -> dfs.HeartbeatRequest.ChunkReadsEntry
+	119, // 14: dfs.RegisterChunkServerRequest.labels:type_name -> dfs.RegisterChunkServerRequest.LabelsEntry
+	27,  // 15: dfs.RegisterChunkServerRequest.storage_directories:type_name -> dfs.StorageDirectory
+	1,   // 16: dfs.FileEvent.type:type_name -> dfs.FileEventType
+	19,  // 17: dfs.GetFileInfoResponse.file:type_name -> dfs.FileInfo
+	4,   // 18: dfs.GetFileInfoResponse.chunk_locations:type_name -> dfs.ChunkLocation
+	50,  // 19: dfs.ListFileVersionsResponse.versions:type_name -> dfs.FileVersion
+	120, // 20: dfs.UpdateFileTagsRequest.set:type_name -> dfs.UpdateFileTagsRequest.SetEntry
+	121, // 21: dfs.UpdateFileTagsResponse.tags:type_name -> dfs.UpdateFileTagsResponse.TagsEntry
+	122, // 22: dfs.FileAttributes.tags:type_name -> dfs.FileAttributes.TagsEntry
+	54,  // 23: dfs.GetFileAttributesResponse.attributes:type_name -> dfs.FileAttributes
+	123, // 24: dfs.SetFileAttributesRequest.set_tags:type_name -> dfs.SetFileAttributesRequest.SetTagsEntry
+	54,  // 25: dfs.SetFileAttributesResponse.attributes:type_name -> dfs.FileAttributes
+	60,  // 26: dfs.DiskUsageResponse.total:type_name -> dfs.DiskUsageEntry
+	60,  // 27: dfs.DiskUsageResponse.entries:type_name -> dfs.DiskUsageEntry
+	19,  // 28: dfs.ListUnaccessedFilesResponse.files:type_name -> dfs.FileInfo
+	124, // 29: dfs.ChunkServerUsage.labels:type_name -> dfs.ChunkServerUsage.LabelsEntry
+	65,  // 30: dfs.GetChunkDistributionResponse.servers:type_name -> dfs.ChunkServerUsage
+	66,  // 31: dfs.GetChunkDistributionResponse.replication_histogram:type_name -> dfs.ReplicationBucket
+	19,  // 32: dfs.SetFileModeResponse.file:type_name -> dfs.FileInfo
+	19,  // 33: dfs.SetFileOwnerResponse.file:type_name -> dfs.FileInfo
+	106, // 34: dfs.RecallChunksRequest.archived_chunks:type_name -> dfs.ArchivedChunk
+	107, // 35: dfs.RecallChunksRequest.chunks:type_name -> dfs.RecalledChunk
+	107, // 36: dfs.RecallChunksResponse.chunks:type_name -> dfs.RecalledChunk
+	2,   // 37: dfs.Master.UploadFile:input_type -> dfs.UploadFileRequest
+	6,   // 38: dfs.Master.CompleteUpload:input_type -> dfs.CompleteUploadRequest
+	8,   // 39: dfs.Master.RenewUpload:input_type -> dfs.RenewUploadRequest
+	10,  // 40: dfs.Master.AllocateChunk:input_type -> dfs.AllocateChunkRequest
+	16,  // 41: dfs.Master.DownloadFile:input_type -> dfs.DownloadFileRequest
+	18,  // 42: dfs.Master.ListFiles:input_type -> dfs.ListFilesRequest
+	18,  // 43: dfs.Master.ListFilesStream:input_type -> dfs.ListFilesRequest
+	21,  // 44: dfs.Master.SearchFiles:input_type -> dfs.SearchFilesRequest
+	23,  // 45: dfs.Master.Heartbeat:input_type -> dfs.HeartbeatRequest
+	26,  // 46: dfs.Master.RegisterChunkServer:input_type -> dfs.RegisterChunkServerRequest
+	29,  // 47: dfs.Master.ReportChunk:input_type -> dfs.ReportChunkRequest
+	35,  // 48: dfs.Master.CopyFile:input_type -> dfs.CopyFileRequest
+	37,  // 49: dfs.Master.CloneFile:input_type -> dfs.CloneFileRequest
+	39,  // 50: dfs.Master.RenameFile:input_type -> dfs.RenameFileRequest
+	41,  // 51: dfs.Master.Watch:input_type -> dfs.WatchRequest
+	43,  // 52: dfs.Master.DeleteFile:input_type -> dfs.DeleteFileRequest
+	45,  // 53: dfs.Master.UndeleteFile:input_type -> dfs.UndeleteFileRequest
+	47,  // 54: dfs.Master.GetFileInfo:input_type -> dfs.GetFileInfoRequest
+	52,  // 55: dfs.Master.UpdateFileTags:input_type -> dfs.UpdateFileTagsRequest
+	55,  // 56: dfs.Master.GetFileAttributes:input_type -> dfs.GetFileAttributesRequest
+	57,  // 57: dfs.Master.SetFileAttributes:input_type -> dfs.SetFileAttributesRequest
+	49,  // 58: dfs.Master.ListFileVersions:input_type -> dfs.ListFileVersionsRequest
+	59,  // 59: dfs.Master.DiskUsage:input_type -> dfs.DiskUsageRequest
+	64,  // 60: dfs.Master.GetChunkDistribution:input_type -> dfs.GetChunkDistributionRequest
+	31,  // 61: dfs.Master.ReportLostChunks:input_type -> dfs.ReportLostChunksRequest
+	33,  // 62: dfs.Master.ReportCorruptChunk:input_type -> dfs.ReportCorruptChunkRequest
+	62,  // 63: dfs.Master.ListUnaccessedFiles:input_type -> dfs.ListUnaccessedFilesRequest
+	68,  // 64: dfs.Master.GetClusterStats:input_type -> dfs.GetClusterStatsRequest
+	12,  // 65: dfs.Master.PrepareAppend:input_type -> dfs.PrepareAppendRequest
+	14,  // 66: dfs.Master.CompleteAppend:input_type -> dfs.CompleteAppendRequest
+	72,  // 67: dfs.Master.GetGeoReplicationStatus:input_type -> dfs.GetGeoReplicationStatusRequest
+	70,  // 68: dfs.Master.ReclaimDeleted:input_type -> dfs.ReclaimDeletedRequest
+	92,  // 69: dfs.Master.GetServerInfo:input_type -> dfs.GetServerInfoRequest
+	74,  // 70: dfs.Master.PresignDownload:input_type -> dfs.PresignDownloadRequest
+	76,  // 71: dfs.Master.WhoAmI:input_type -> dfs.WhoAmIRequest
+	78,  // 72: dfs.Master.GetDelegationToken:input_type -> dfs.GetDelegationTokenRequest
+	80,  // 73: dfs.Master.RenewDelegationToken:input_type -> dfs.RenewDelegationTokenRequest
+	82,  // 74: dfs.Master.RevokeDelegationToken:input_type -> dfs.RevokeDelegationTokenRequest
+	84,  // 75: dfs.Master.SetFileMode:input_type -> dfs.SetFileModeRequest
+	86,  // 76: dfs.Master.SetFileOwner:input_type -> dfs.SetFileOwnerRequest
+	88,  // 77: dfs.Master.SymlinkFile:input_type -> dfs.SymlinkFileRequest
+	90,  // 78: dfs.Master.LinkFile:input_type -> dfs.LinkFileRequest
+	94,  // 79: dfs.ChunkServer.WriteChunk:input_type -> dfs.WriteChunkRequest
+	96,  // 80: dfs.ChunkServer.ReadChunk:input_type -> dfs.ReadChunkRequest
+	96,  // 81: dfs.ChunkServer.ReadChunkStream:input_type -> dfs.ReadChunkRequest
+	98,  // 82: dfs.ChunkServer.CopyChunk:input_type -> dfs.CopyChunkRequest
+	100, // 83: dfs.ChunkServer.DeleteChunk:input_type -> dfs.DeleteChunkRequest
+	104, // 84: dfs.ChunkServer.ReplicateChunk:input_type -> dfs.ReplicateChunkRequest
+	110, // 85: dfs.ChunkServer.RecordAppend:input_type -> dfs.RecordAppendRequest
+	112, // 86: dfs.ChunkServer.ApplyAppend:input_type -> dfs.ApplyAppendRequest
+	92,  // 87: dfs.ChunkServer.GetServerInfo:input_type -> dfs.GetServerInfoRequest
+	108, // 88: dfs.ChunkServer.RecallChunks:input_type -> dfs.RecallChunksRequest
+	102, // 89: dfs.ChunkServer.VerifyChunk:input_type -> dfs.VerifyChunkRequest
+	5,   // 90: dfs.Master.UploadFile:output_type -> dfs.UploadFileResponse
+	7,   // 91: dfs.Master.CompleteUpload:output_type -> dfs.CompleteUploadResponse
+	9,   // 92: dfs.Master.RenewUpload:output_type -> dfs.RenewUploadResponse
+	11,  // 93: dfs.Master.AllocateChunk:output_type -> dfs.AllocateChunkResponse
+	17,  // 94: dfs.Master.DownloadFile:output_type -> dfs.DownloadFileResponse
+	20,  // 95: dfs.Master.ListFiles:output_type -> dfs.ListFilesResponse
+	20,  // 96: dfs.Master.ListFilesStream:output_type -> dfs.ListFilesResponse
+	22,  // 97: dfs.Master.SearchFiles:output_type -> dfs.SearchFilesResponse
+	25,  // 98: dfs.Master.Heartbeat:output_type -> dfs.HeartbeatResponse
+	28,  // 99: dfs.Master.RegisterChunkServer:output_type -> dfs.RegisterChunkServerResponse
+	30,  // 100: dfs.Master.ReportChunk:output_type -> dfs.ReportChunkResponse
+	36,  // 101: dfs.Master.CopyFile:output_type -> dfs.CopyFileResponse
+	38,  // 102: dfs.Master.CloneFile:output_type -> dfs.CloneFileResponse
+	40,  // 103: dfs.Master.RenameFile:output_type -> dfs.RenameFileResponse
+	42,  // 104: dfs.Master.Watch:output_type -> dfs.FileEvent
+	44,  // 105: dfs.Master.DeleteFile:output_type -> dfs.DeleteFileResponse
+	46,  // 106: dfs.Master.UndeleteFile:output_type -> dfs.UndeleteFileResponse
+	48,  // 107: dfs.Master.GetFileInfo:output_type -> dfs.GetFileInfoResponse
+	53,  // 108: dfs.Master.UpdateFileTags:output_type -> dfs.UpdateFileTagsResponse
+	56,  // 109: dfs.Master.GetFileAttributes:output_type -> dfs.GetFileAttributesResponse
+	58,  // 110: dfs.Master.SetFileAttributes:output_type -> dfs.SetFileAttributesResponse
+	51,  // 111: dfs.Master.ListFileVersions:output_type -> dfs.ListFileVersionsResponse
+	61,  // 112: dfs.Master.DiskUsage:output_type -> dfs.DiskUsageResponse
+	67,  // 113: dfs.Master.GetChunkDistribution:output_type -> dfs.GetChunkDistributionResponse
+	32,  // 114: dfs.Master.ReportLostChunks:output_type -> dfs.ReportLostChunksResponse
+	34,  // 115: dfs.Master.ReportCorruptChunk:output_type -> dfs.ReportCorruptChunkResponse
+	63,  // 116: dfs.Master.ListUnaccessedFiles:output_type -> dfs.ListUnaccessedFilesResponse
+	69,  // 117: dfs.Master.GetClusterStats:output_type -> dfs.GetClusterStatsResponse
+	13,  // 118: dfs.Master.PrepareAppend:output_type -> dfs.PrepareAppendResponse
+	15,  // 119: dfs.Master.CompleteAppend:output_type -> dfs.CompleteAppendResponse
+	73,  // 120: dfs.Master.GetGeoReplicationStatus:output_type -> dfs.GetGeoReplicationStatusResponse
+	71,  // 121: dfs.Master.ReclaimDeleted:output_type -> dfs.ReclaimDeletedResponse
+	93,  // 122: dfs.Master.GetServerInfo:output_type -> dfs.GetServerInfoResponse
+	75,  // 123: dfs.Master.PresignDownload:output_type -> dfs.PresignDownloadResponse
+	77,  // 124: dfs.Master.WhoAmI:output_type -> dfs.WhoAmIResponse
+	79,  // 125: dfs.Master.GetDelegationToken:output_type -> dfs.GetDelegationTokenResponse
+	81,  // 126: dfs.Master.RenewDelegationToken:output_type -> dfs.RenewDelegationTokenResponse
+	83,  // 127: dfs.Master.RevokeDelegationToken:output_type -> dfs.RevokeDelegationTokenResponse
+	85,  // 128: dfs.Master.SetFileMode:output_type -> dfs.SetFileModeResponse
+	87,  // 129: dfs.Master.SetFileOwner:output_type -> dfs.SetFileOwnerResponse
+	89,  // 130: dfs.Master.SymlinkFile:output_type -> dfs.SymlinkFileResponse
+	91,  // 131: dfs.Master.LinkFile:output_type -> dfs.LinkFileResponse
+	95,  // 132: dfs.ChunkServer.WriteChunk:output_type -> dfs.WriteChunkResponse
+	97,  // 133: dfs.ChunkServer.ReadChunk:output_type -> dfs.ReadChunkResponse
+	97,  // 134: dfs.ChunkServer.ReadChunkStream:output_type -> dfs.ReadChunkResponse
+	99,  // 135: dfs.ChunkServer.CopyChunk:output_type -> dfs.CopyChunkResponse
+	101, // 136: dfs.ChunkServer.DeleteChunk:output_type -> dfs.DeleteChunkResponse
+	105, // 137: dfs.ChunkServer.ReplicateChunk:output_type -> dfs.ReplicateChunkResponse
+	111, // 138: dfs.ChunkServer.RecordAppend:output_type -> dfs.RecordAppendResponse
+	113, // 139: dfs.ChunkServer.ApplyAppend:output_type -> dfs.ApplyAppendResponse
+	93,  // 140: dfs.ChunkServer.GetServerInfo:output_type -> dfs.GetServerInfoResponse
+	109, // 141: dfs.ChunkServer.RecallChunks:output_type -> dfs.RecallChunksResponse
+	103, // 142: dfs.ChunkServer.VerifyChunk:output_type -> dfs.VerifyChunkResponse
+	90,  // [90:143] is the sub-list for method output_type
+	37,  // [37:90] is the sub-list for method input_type
+	37,  // [37:37] is the sub-list for extension type_name
+	37,  // [37:37] is the sub-list for extension extendee
+	0,   // [0:37] is the sub-list for field type_name
 }
 
 func init() { file_proto_dfs_proto_init() }
@@ -921,18 +7995,31 @@ func file_proto_dfs_proto_init() {
 	if File_proto_dfs_proto != nil {
 		return
 	}
+	file_proto_dfs_proto_msgTypes[0].OneofWrappers = []any{}
+	file_proto_dfs_proto_msgTypes[4].OneofWrappers = []any{}
+	file_proto_dfs_proto_msgTypes[10].OneofWrappers = []any{}
+	file_proto_dfs_proto_msgTypes[16].OneofWrappers = []any{}
+	file_proto_dfs_proto_msgTypes[19].OneofWrappers = []any{}
+	file_proto_dfs_proto_msgTypes[33].OneofWrappers = []any{}
+	file_proto_dfs_proto_msgTypes[35].OneofWrappers = []any{}
+	file_proto_dfs_proto_msgTypes[37].OneofWrappers = []any{}
+	file_proto_dfs_proto_msgTypes[41].OneofWrappers = []any{}
+	file_proto_dfs_proto_msgTypes[55].OneofWrappers = []any{}
+	file_proto_dfs_proto_msgTypes[92].OneofWrappers = []any{}
+	file_proto_dfs_proto_msgTypes[95].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_dfs_proto_rawDesc), len(file_proto_dfs_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   16,
+			NumEnums:      2,
+			NumMessages:   123,
 			NumExtensions: 0,
 			NumServices:   2,
 		},
 		GoTypes:           file_proto_dfs_proto_goTypes,
 		DependencyIndexes: file_proto_dfs_proto_depIdxs,
+		EnumInfos:         file_proto_dfs_proto_enumTypes,
 		MessageInfos:      file_proto_dfs_proto_msgTypes,
 	}.Build()
 	File_proto_dfs_proto = out.File
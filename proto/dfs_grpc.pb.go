@@ -20,11 +20,48 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	Master_UploadFile_FullMethodName   = "/dfs.Master/UploadFile"
-	Master_DownloadFile_FullMethodName = "/dfs.Master/DownloadFile"
-	Master_ListFiles_FullMethodName    = "/dfs.Master/ListFiles"
-	Master_Heartbeat_FullMethodName    = "/dfs.Master/Heartbeat"
-	Master_ReportChunk_FullMethodName  = "/dfs.Master/ReportChunk"
+	Master_UploadFile_FullMethodName              = "/dfs.Master/UploadFile"
+	Master_CompleteUpload_FullMethodName          = "/dfs.Master/CompleteUpload"
+	Master_RenewUpload_FullMethodName             = "/dfs.Master/RenewUpload"
+	Master_AllocateChunk_FullMethodName           = "/dfs.Master/AllocateChunk"
+	Master_DownloadFile_FullMethodName            = "/dfs.Master/DownloadFile"
+	Master_ListFiles_FullMethodName               = "/dfs.Master/ListFiles"
+	Master_ListFilesStream_FullMethodName         = "/dfs.Master/ListFilesStream"
+	Master_SearchFiles_FullMethodName             = "/dfs.Master/SearchFiles"
+	Master_Heartbeat_FullMethodName               = "/dfs.Master/Heartbeat"
+	Master_RegisterChunkServer_FullMethodName     = "/dfs.Master/RegisterChunkServer"
+	Master_ReportChunk_FullMethodName             = "/dfs.Master/ReportChunk"
+	Master_CopyFile_FullMethodName                = "/dfs.Master/CopyFile"
+	Master_CloneFile_FullMethodName               = "/dfs.Master/CloneFile"
+	Master_RenameFile_FullMethodName              = "/dfs.Master/RenameFile"
+	Master_Watch_FullMethodName                   = "/dfs.Master/Watch"
+	Master_DeleteFile_FullMethodName              = "/dfs.Master/DeleteFile"
+	Master_UndeleteFile_FullMethodName            = "/dfs.Master/UndeleteFile"
+	Master_GetFileInfo_FullMethodName             = "/dfs.Master/GetFileInfo"
+	Master_UpdateFileTags_FullMethodName          = "/dfs.Master/UpdateFileTags"
+	Master_GetFileAttributes_FullMethodName       = "/dfs.Master/GetFileAttributes"
+	Master_SetFileAttributes_FullMethodName       = "/dfs.Master/SetFileAttributes"
+	Master_ListFileVersions_FullMethodName        = "/dfs.Master/ListFileVersions"
+	Master_DiskUsage_FullMethodName               = "/dfs.Master/DiskUsage"
+	Master_GetChunkDistribution_FullMethodName    = "/dfs.Master/GetChunkDistribution"
+	Master_ReportLostChunks_FullMethodName        = "/dfs.Master/ReportLostChunks"
+	Master_ReportCorruptChunk_FullMethodName      = "/dfs.Master/ReportCorruptChunk"
+	Master_ListUnaccessedFiles_FullMethodName     = "/dfs.Master/ListUnaccessedFiles"
+	Master_GetClusterStats_FullMethodName         = "/dfs.Master/GetClusterStats"
+	Master_PrepareAppend_FullMethodName           = "/dfs.Master/PrepareAppend"
+	Master_CompleteAppend_FullMethodName          = "/dfs.Master/CompleteAppend"
+	Master_GetGeoReplicationStatus_FullMethodName = "/dfs.Master/GetGeoReplicationStatus"
+	Master_ReclaimDeleted_FullMethodName          = "/dfs.Master/ReclaimDeleted"
+	Master_GetServerInfo_FullMethodName           = "/dfs.Master/GetServerInfo"
+	Master_PresignDownload_FullMethodName         = "/dfs.Master/PresignDownload"
+	Master_WhoAmI_FullMethodName                  = "/dfs.Master/WhoAmI"
+	Master_GetDelegationToken_FullMethodName      = "/dfs.Master/GetDelegationToken"
+	Master_RenewDelegationToken_FullMethodName    = "/dfs.Master/RenewDelegationToken"
+	Master_RevokeDelegationToken_FullMethodName   = "/dfs.Master/RevokeDelegationToken"
+	Master_SetFileMode_FullMethodName             = "/dfs.Master/SetFileMode"
+	Master_SetFileOwner_FullMethodName            = "/dfs.Master/SetFileOwner"
+	Master_SymlinkFile_FullMethodName             = "/dfs.Master/SymlinkFile"
+	Master_LinkFile_FullMethodName                = "/dfs.Master/LinkFile"
 )
 
 // MasterClient is the client API for Master service.
@@ -35,14 +72,90 @@ const (
 type MasterClient interface {
 	// UploadFile: returns chunk handles and chunk server locations
 	UploadFile(ctx context.Context, in *UploadFileRequest, opts ...grpc.CallOption) (*UploadFileResponse, error)
+	// CompleteUpload: ends an upload once its chunks are written, or after the client gave up on it
+	CompleteUpload(ctx context.Context, in *CompleteUploadRequest, opts ...grpc.CallOption) (*CompleteUploadResponse, error)
+	// RenewUpload: extends the session of an upload still writing its chunks
+	RenewUpload(ctx context.Context, in *RenewUploadRequest, opts ...grpc.CallOption) (*RenewUploadResponse, error)
+	// AllocateChunk: adds the next chunk to a streaming upload whose size isn't known up front
+	AllocateChunk(ctx context.Context, in *AllocateChunkRequest, opts ...grpc.CallOption) (*AllocateChunkResponse, error)
 	// DownloadFile: returns file metadata and chunk locations for download
 	DownloadFile(ctx context.Context, in *DownloadFileRequest, opts ...grpc.CallOption) (*DownloadFileResponse, error)
 	// ListFiles: lists all the files in the system
 	ListFiles(ctx context.Context, in *ListFilesRequest, opts ...grpc.CallOption) (*ListFilesResponse, error)
+	// ListFilesStream: lists files in batches sent as they are read, for namespaces too large for a single response
+	ListFilesStream(ctx context.Context, in *ListFilesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ListFilesResponse], error)
+	// SearchFiles: finds files by name substring, tags, size and time using an index kept by the master
+	SearchFiles(ctx context.Context, in *SearchFilesRequest, opts ...grpc.CallOption) (*SearchFilesResponse, error)
 	// Heartbeat: checks whether the chunk server is alive or not using heartbeats
 	Heartbeat(ctx context.Context, in *HeartbeatRequest, opts ...grpc.CallOption) (*HeartbeatResponse, error)
+	// RegisterChunkServer: announces a starting chunk server and returns the cluster parameters it must use
+	RegisterChunkServer(ctx context.Context, in *RegisterChunkServerRequest, opts ...grpc.CallOption) (*RegisterChunkServerResponse, error)
 	// ReportChunk: reports chunk storage completion
 	ReportChunk(ctx context.Context, in *ReportChunkRequest, opts ...grpc.CallOption) (*ReportChunkResponse, error)
+	// CopyFile: duplicates a file inside the dfs without routing data through the client
+	CopyFile(ctx context.Context, in *CopyFileRequest, opts ...grpc.CallOption) (*CopyFileResponse, error)
+	// CloneFile: creates a file sharing the source's chunks, which are only copied once either file appends to them
+	CloneFile(ctx context.Context, in *CloneFileRequest, opts ...grpc.CallOption) (*CloneFileResponse, error)
+	// Rename a file, keeping its chunks
+	RenameFile(ctx context.Context, in *RenameFileRequest, opts ...grpc.CallOption) (*RenameFileResponse, error)
+	// Watch: streams namespace events for files matching a prefix
+	Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[FileEvent], error)
+	// DeleteFile: removes a file and its chunks from the system
+	DeleteFile(ctx context.Context, in *DeleteFileRequest, opts ...grpc.CallOption) (*DeleteFileResponse, error)
+	// UndeleteFile: restores a deleted file whose chunks weren't reclaimed yet
+	UndeleteFile(ctx context.Context, in *UndeleteFileRequest, opts ...grpc.CallOption) (*UndeleteFileResponse, error)
+	// GetFileInfo: returns metadata and chunk locations of a single file
+	GetFileInfo(ctx context.Context, in *GetFileInfoRequest, opts ...grpc.CallOption) (*GetFileInfoResponse, error)
+	// Add, change or remove tags of an existing file
+	UpdateFileTags(ctx context.Context, in *UpdateFileTagsRequest, opts ...grpc.CallOption) (*UpdateFileTagsResponse, error)
+	// Read the mutable attributes of a file
+	GetFileAttributes(ctx context.Context, in *GetFileAttributesRequest, opts ...grpc.CallOption) (*GetFileAttributesResponse, error)
+	// Change the mutable attributes of an existing file without uploading it again
+	SetFileAttributes(ctx context.Context, in *SetFileAttributesRequest, opts ...grpc.CallOption) (*SetFileAttributesResponse, error)
+	// List the current and kept previous versions of a file
+	ListFileVersions(ctx context.Context, in *ListFileVersionsRequest, opts ...grpc.CallOption) (*ListFileVersionsResponse, error)
+	// DiskUsage: reports space consumed by files under a prefix
+	DiskUsage(ctx context.Context, in *DiskUsageRequest, opts ...grpc.CallOption) (*DiskUsageResponse, error)
+	// GetChunkDistribution: reports chunks held per chunk server and the replication histogram
+	GetChunkDistribution(ctx context.Context, in *GetChunkDistributionRequest, opts ...grpc.CallOption) (*GetChunkDistributionResponse, error)
+	// ReportLostChunks: reports chunks a chunk server no longer holds, e.g. after a disk failure
+	ReportLostChunks(ctx context.Context, in *ReportLostChunksRequest, opts ...grpc.CallOption) (*ReportLostChunksResponse, error)
+	// ReportCorruptChunk: reports a replica that failed checksum verification so it can be repaired from a good copy.
+	// The master has the replica's server verify it before dropping it, and never drops a chunk's last replica
+	ReportCorruptChunk(ctx context.Context, in *ReportCorruptChunkRequest, opts ...grpc.CallOption) (*ReportCorruptChunkResponse, error)
+	// ListUnaccessedFiles: returns the largest files not read for a while, to drive cleanup decisions
+	ListUnaccessedFiles(ctx context.Context, in *ListUnaccessedFilesRequest, opts ...grpc.CallOption) (*ListUnaccessedFilesResponse, error)
+	// GetClusterStats: returns aggregate capacity, namespace and chunk server statistics of the cluster
+	GetClusterStats(ctx context.Context, in *GetClusterStatsRequest, opts ...grpc.CallOption) (*GetClusterStatsResponse, error)
+	// PrepareAppend: returns the chunk records are appended to and its primary replica, creating the file or adding a chunk as needed
+	PrepareAppend(ctx context.Context, in *PrepareAppendRequest, opts ...grpc.CallOption) (*PrepareAppendResponse, error)
+	// CompleteAppend: grows a file to cover a record appended to every replica of its chunk
+	CompleteAppend(ctx context.Context, in *CompleteAppendRequest, opts ...grpc.CallOption) (*CompleteAppendResponse, error)
+	// GetGeoReplicationStatus: reports how far mirroring to the remote cluster lags behind
+	GetGeoReplicationStatus(ctx context.Context, in *GetGeoReplicationStatusRequest, opts ...grpc.CallOption) (*GetGeoReplicationStatusResponse, error)
+	// ReclaimDeleted: deletes the replicas of deleted chunks now instead of after the reclaim delay
+	ReclaimDeleted(ctx context.Context, in *ReclaimDeletedRequest, opts ...grpc.CallOption) (*ReclaimDeletedResponse, error)
+	// GetServerInfo: returns the master's software version, build commit and supported protocol features
+	GetServerInfo(ctx context.Context, in *GetServerInfoRequest, opts ...grpc.CallOption) (*GetServerInfoResponse, error)
+	// PresignDownload: returns a url downloading the current version of a file over http until it expires,
+	// for sharing the file with systems that have no DFS client
+	PresignDownload(ctx context.Context, in *PresignDownloadRequest, opts ...grpc.CallOption) (*PresignDownloadResponse, error)
+	// WhoAmI: returns the user and groups the master authenticated the caller as
+	WhoAmI(ctx context.Context, in *WhoAmIRequest, opts ...grpc.CallOption) (*WhoAmIResponse, error)
+	// GetDelegationToken: mints a read-only token limited to a prefix, for batch jobs to use instead of the user's own token
+	GetDelegationToken(ctx context.Context, in *GetDelegationTokenRequest, opts ...grpc.CallOption) (*GetDelegationTokenResponse, error)
+	// RenewDelegationToken: extends a delegation token, up to the maximum lifetime it was minted with
+	RenewDelegationToken(ctx context.Context, in *RenewDelegationTokenRequest, opts ...grpc.CallOption) (*RenewDelegationTokenResponse, error)
+	// RevokeDelegationToken: stops the master accepting a delegation token and its renewals
+	RevokeDelegationToken(ctx context.Context, in *RevokeDelegationTokenRequest, opts ...grpc.CallOption) (*RevokeDelegationTokenResponse, error)
+	// SetFileMode: changes the permission bits of a file, like chmod
+	SetFileMode(ctx context.Context, in *SetFileModeRequest, opts ...grpc.CallOption) (*SetFileModeResponse, error)
+	// SetFileOwner: changes the owner or group of a file, like chown
+	SetFileOwner(ctx context.Context, in *SetFileOwnerRequest, opts ...grpc.CallOption) (*SetFileOwnerResponse, error)
+	// SymlinkFile: adds a symlink that downloads, stat, copies and clones follow to another file
+	SymlinkFile(ctx context.Context, in *SymlinkFileRequest, opts ...grpc.CallOption) (*SymlinkFileResponse, error)
+	// LinkFile: adds a hard link, another name sharing a file's chunks, owner and mode
+	LinkFile(ctx context.Context, in *LinkFileRequest, opts ...grpc.CallOption) (*LinkFileResponse, error)
 }
 
 type masterClient struct {
@@ -63,6 +176,36 @@ func (c *masterClient) UploadFile(ctx context.Context, in *UploadFileRequest, op
 	return out, nil
 }
 
+func (c *masterClient) CompleteUpload(ctx context.Context, in *CompleteUploadRequest, opts ...grpc.CallOption) (*CompleteUploadResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CompleteUploadResponse)
+	err := c.cc.Invoke(ctx, Master_CompleteUpload_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterClient) RenewUpload(ctx context.Context, in *RenewUploadRequest, opts ...grpc.CallOption) (*RenewUploadResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RenewUploadResponse)
+	err := c.cc.Invoke(ctx, Master_RenewUpload_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterClient) AllocateChunk(ctx context.Context, in *AllocateChunkRequest, opts ...grpc.CallOption) (*AllocateChunkResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AllocateChunkResponse)
+	err := c.cc.Invoke(ctx, Master_AllocateChunk_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *masterClient) DownloadFile(ctx context.Context, in *DownloadFileRequest, opts ...grpc.CallOption) (*DownloadFileResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(DownloadFileResponse)
@@ -70,187 +213,1384 @@ func (c *masterClient) DownloadFile(ctx context.Context, in *DownloadFileRequest
 	if err != nil {
 		return nil, err
 	}
-	return out, nil
+	return out, nil
+}
+
+func (c *masterClient) ListFiles(ctx context.Context, in *ListFilesRequest, opts ...grpc.CallOption) (*ListFilesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListFilesResponse)
+	err := c.cc.Invoke(ctx, Master_ListFiles_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterClient) ListFilesStream(ctx context.Context, in *ListFilesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ListFilesResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Master_ServiceDesc.Streams[0], Master_ListFilesStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ListFilesRequest, ListFilesResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Master_ListFilesStreamClient = grpc.ServerStreamingClient[ListFilesResponse]
+
+func (c *masterClient) SearchFiles(ctx context.Context, in *SearchFilesRequest, opts ...grpc.CallOption) (*SearchFilesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchFilesResponse)
+	err := c.cc.Invoke(ctx, Master_SearchFiles_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterClient) Heartbeat(ctx context.Context, in *HeartbeatRequest, opts ...grpc.CallOption) (*HeartbeatResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HeartbeatResponse)
+	err := c.cc.Invoke(ctx, Master_Heartbeat_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterClient) RegisterChunkServer(ctx context.Context, in *RegisterChunkServerRequest, opts ...grpc.CallOption) (*RegisterChunkServerResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RegisterChunkServerResponse)
+	err := c.cc.Invoke(ctx, Master_RegisterChunkServer_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterClient) ReportChunk(ctx context.Context, in *ReportChunkRequest, opts ...grpc.CallOption) (*ReportChunkResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReportChunkResponse)
+	err := c.cc.Invoke(ctx, Master_ReportChunk_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterClient) CopyFile(ctx context.Context, in *CopyFileRequest, opts ...grpc.CallOption) (*CopyFileResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CopyFileResponse)
+	err := c.cc.Invoke(ctx, Master_CopyFile_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterClient) CloneFile(ctx context.Context, in *CloneFileRequest, opts ...grpc.CallOption) (*CloneFileResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CloneFileResponse)
+	err := c.cc.Invoke(ctx, Master_CloneFile_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterClient) RenameFile(ctx context.Context, in *RenameFileRequest, opts ...grpc.CallOption) (*RenameFileResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RenameFileResponse)
+	err := c.cc.Invoke(ctx, Master_RenameFile_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterClient) Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[FileEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Master_ServiceDesc.Streams[1], Master_Watch_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchRequest, FileEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Master_WatchClient = grpc.ServerStreamingClient[FileEvent]
+
+func (c *masterClient) DeleteFile(ctx context.Context, in *DeleteFileRequest, opts ...grpc.CallOption) (*DeleteFileResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteFileResponse)
+	err := c.cc.Invoke(ctx, Master_DeleteFile_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterClient) UndeleteFile(ctx context.Context, in *UndeleteFileRequest, opts ...grpc.CallOption) (*UndeleteFileResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UndeleteFileResponse)
+	err := c.cc.Invoke(ctx, Master_UndeleteFile_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterClient) GetFileInfo(ctx context.Context, in *GetFileInfoRequest, opts ...grpc.CallOption) (*GetFileInfoResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetFileInfoResponse)
+	err := c.cc.Invoke(ctx, Master_GetFileInfo_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterClient) UpdateFileTags(ctx context.Context, in *UpdateFileTagsRequest, opts ...grpc.CallOption) (*UpdateFileTagsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateFileTagsResponse)
+	err := c.cc.Invoke(ctx, Master_UpdateFileTags_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterClient) GetFileAttributes(ctx context.Context, in *GetFileAttributesRequest, opts ...grpc.CallOption) (*GetFileAttributesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetFileAttributesResponse)
+	err := c.cc.Invoke(ctx, Master_GetFileAttributes_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterClient) SetFileAttributes(ctx context.Context, in *SetFileAttributesRequest, opts ...grpc.CallOption) (*SetFileAttributesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetFileAttributesResponse)
+	err := c.cc.Invoke(ctx, Master_SetFileAttributes_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterClient) ListFileVersions(ctx context.Context, in *ListFileVersionsRequest, opts ...grpc.CallOption) (*ListFileVersionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListFileVersionsResponse)
+	err := c.cc.Invoke(ctx, Master_ListFileVersions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterClient) DiskUsage(ctx context.Context, in *DiskUsageRequest, opts ...grpc.CallOption) (*DiskUsageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DiskUsageResponse)
+	err := c.cc.Invoke(ctx, Master_DiskUsage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterClient) GetChunkDistribution(ctx context.Context, in *GetChunkDistributionRequest, opts ...grpc.CallOption) (*GetChunkDistributionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetChunkDistributionResponse)
+	err := c.cc.Invoke(ctx, Master_GetChunkDistribution_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterClient) ReportLostChunks(ctx context.Context, in *ReportLostChunksRequest, opts ...grpc.CallOption) (*ReportLostChunksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReportLostChunksResponse)
+	err := c.cc.Invoke(ctx, Master_ReportLostChunks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterClient) ReportCorruptChunk(ctx context.Context, in *ReportCorruptChunkRequest, opts ...grpc.CallOption) (*ReportCorruptChunkResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReportCorruptChunkResponse)
+	err := c.cc.Invoke(ctx, Master_ReportCorruptChunk_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterClient) ListUnaccessedFiles(ctx context.Context, in *ListUnaccessedFilesRequest, opts ...grpc.CallOption) (*ListUnaccessedFilesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListUnaccessedFilesResponse)
+	err := c.cc.Invoke(ctx, Master_ListUnaccessedFiles_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterClient) GetClusterStats(ctx context.Context, in *GetClusterStatsRequest, opts ...grpc.CallOption) (*GetClusterStatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetClusterStatsResponse)
+	err := c.cc.Invoke(ctx, Master_GetClusterStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterClient) PrepareAppend(ctx context.Context, in *PrepareAppendRequest, opts ...grpc.CallOption) (*PrepareAppendResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PrepareAppendResponse)
+	err := c.cc.Invoke(ctx, Master_PrepareAppend_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterClient) CompleteAppend(ctx context.Context, in *CompleteAppendRequest, opts ...grpc.CallOption) (*CompleteAppendResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CompleteAppendResponse)
+	err := c.cc.Invoke(ctx, Master_CompleteAppend_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterClient) GetGeoReplicationStatus(ctx context.Context, in *GetGeoReplicationStatusRequest, opts ...grpc.CallOption) (*GetGeoReplicationStatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetGeoReplicationStatusResponse)
+	err := c.cc.Invoke(ctx, Master_GetGeoReplicationStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterClient) ReclaimDeleted(ctx context.Context, in *ReclaimDeletedRequest, opts ...grpc.CallOption) (*ReclaimDeletedResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReclaimDeletedResponse)
+	err := c.cc.Invoke(ctx, Master_ReclaimDeleted_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterClient) GetServerInfo(ctx context.Context, in *GetServerInfoRequest, opts ...grpc.CallOption) (*GetServerInfoResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetServerInfoResponse)
+	err := c.cc.Invoke(ctx, Master_GetServerInfo_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterClient) PresignDownload(ctx context.Context, in *PresignDownloadRequest, opts ...grpc.CallOption) (*PresignDownloadResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PresignDownloadResponse)
+	err := c.cc.Invoke(ctx, Master_PresignDownload_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterClient) WhoAmI(ctx context.Context, in *WhoAmIRequest, opts ...grpc.CallOption) (*WhoAmIResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WhoAmIResponse)
+	err := c.cc.Invoke(ctx, Master_WhoAmI_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterClient) GetDelegationToken(ctx context.Context, in *GetDelegationTokenRequest, opts ...grpc.CallOption) (*GetDelegationTokenResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetDelegationTokenResponse)
+	err := c.cc.Invoke(ctx, Master_GetDelegationToken_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterClient) RenewDelegationToken(ctx context.Context, in *RenewDelegationTokenRequest, opts ...grpc.CallOption) (*RenewDelegationTokenResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RenewDelegationTokenResponse)
+	err := c.cc.Invoke(ctx, Master_RenewDelegationToken_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterClient) RevokeDelegationToken(ctx context.Context, in *RevokeDelegationTokenRequest, opts ...grpc.CallOption) (*RevokeDelegationTokenResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RevokeDelegationTokenResponse)
+	err := c.cc.Invoke(ctx, Master_RevokeDelegationToken_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterClient) SetFileMode(ctx context.Context, in *SetFileModeRequest, opts ...grpc.CallOption) (*SetFileModeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetFileModeResponse)
+	err := c.cc.Invoke(ctx, Master_SetFileMode_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterClient) SetFileOwner(ctx context.Context, in *SetFileOwnerRequest, opts ...grpc.CallOption) (*SetFileOwnerResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetFileOwnerResponse)
+	err := c.cc.Invoke(ctx, Master_SetFileOwner_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterClient) SymlinkFile(ctx context.Context, in *SymlinkFileRequest, opts ...grpc.CallOption) (*SymlinkFileResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SymlinkFileResponse)
+	err := c.cc.Invoke(ctx, Master_SymlinkFile_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterClient) LinkFile(ctx context.Context, in *LinkFileRequest, opts ...grpc.CallOption) (*LinkFileResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LinkFileResponse)
+	err := c.cc.Invoke(ctx, Master_LinkFile_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// MasterServer is the server API for Master service.
+// All implementations must embed UnimplementedMasterServer
+// for forward compatibility.
+//
+// Master Service: handles file metadata and chunk location
+type MasterServer interface {
+	// UploadFile: returns chunk handles and chunk server locations
+	UploadFile(context.Context, *UploadFileRequest) (*UploadFileResponse, error)
+	// CompleteUpload: ends an upload once its chunks are written, or after the client gave up on it
+	CompleteUpload(context.Context, *CompleteUploadRequest) (*CompleteUploadResponse, error)
+	// RenewUpload: extends the session of an upload still writing its chunks
+	RenewUpload(context.Context, *RenewUploadRequest) (*RenewUploadResponse, error)
+	// AllocateChunk: adds the next chunk to a streaming upload whose size isn't known up front
+	AllocateChunk(context.Context, *AllocateChunkRequest) (*AllocateChunkResponse, error)
+	// DownloadFile: returns file metadata and chunk locations for download
+	DownloadFile(context.Context, *DownloadFileRequest) (*DownloadFileResponse, error)
+	// ListFiles: lists all the files in the system
+	ListFiles(context.Context, *ListFilesRequest) (*ListFilesResponse, error)
+	// ListFilesStream: lists files in batches sent as they are read, for namespaces too large for a single response
+	ListFilesStream(*ListFilesRequest, grpc.ServerStreamingServer[ListFilesResponse]) error
+	// SearchFiles: finds files by name substring, tags, size and time using an index kept by the master
+	SearchFiles(context.Context, *SearchFilesRequest) (*SearchFilesResponse, error)
+	// Heartbeat: checks whether the chunk server is alive or not using heartbeats
+	Heartbeat(context.Context, *HeartbeatRequest) (*HeartbeatResponse, error)
+	// RegisterChunkServer: announces a starting chunk server and returns the cluster parameters it must use
+	RegisterChunkServer(context.Context, *RegisterChunkServerRequest) (*RegisterChunkServerResponse, error)
+	// ReportChunk: reports chunk storage completion
+	ReportChunk(context.Context, *ReportChunkRequest) (*ReportChunkResponse, error)
+	// CopyFile: duplicates a file inside the dfs without routing data through the client
+	CopyFile(context.Context, *CopyFileRequest) (*CopyFileResponse, error)
+	// CloneFile: creates a file sharing the source's chunks, which are only copied once either file appends to them
+	CloneFile(context.Context, *CloneFileRequest) (*CloneFileResponse, error)
+	// Rename a file, keeping its chunks
+	RenameFile(context.Context, *RenameFileRequest) (*RenameFileResponse, error)
+	// Watch: streams namespace events for files matching a prefix
+	Watch(*WatchRequest, grpc.ServerStreamingServer[FileEvent]) error
+	// DeleteFile: removes a file and its chunks from the system
+	DeleteFile(context.Context, *DeleteFileRequest) (*DeleteFileResponse, error)
+	// UndeleteFile: restores a deleted file whose chunks weren't reclaimed yet
+	UndeleteFile(context.Context, *UndeleteFileRequest) (*UndeleteFileResponse, error)
+	// GetFileInfo: returns metadata and chunk locations of a single file
+	GetFileInfo(context.Context, *GetFileInfoRequest) (*GetFileInfoResponse, error)
+	// Add, change or remove tags of an existing file
+	UpdateFileTags(context.Context, *UpdateFileTagsRequest) (*UpdateFileTagsResponse, error)
+	// Read the mutable attributes of a file
+	GetFileAttributes(context.Context, *GetFileAttributesRequest) (*GetFileAttributesResponse, error)
+	// Change the mutable attributes of an existing file without uploading it again
+	SetFileAttributes(context.Context, *SetFileAttributesRequest) (*SetFileAttributesResponse, error)
+	// List the current and kept previous versions of a file
+	ListFileVersions(context.Context, *ListFileVersionsRequest) (*ListFileVersionsResponse, error)
+	// DiskUsage: reports space consumed by files under a prefix
+	DiskUsage(context.Context, *DiskUsageRequest) (*DiskUsageResponse, error)
+	// GetChunkDistribution: reports chunks held per chunk server and the replication histogram
+	GetChunkDistribution(context.Context, *GetChunkDistributionRequest) (*GetChunkDistributionResponse, error)
+	// ReportLostChunks: reports chunks a chunk server no longer holds, e.g. after a disk failure
+	ReportLostChunks(context.Context, *ReportLostChunksRequest) (*ReportLostChunksResponse, error)
+	// ReportCorruptChunk: reports a replica that failed checksum verification so it can be repaired from a good copy.
+	// The master has the replica's server verify it before dropping it, and never drops a chunk's last replica
+	ReportCorruptChunk(context.Context, *ReportCorruptChunkRequest) (*ReportCorruptChunkResponse, error)
+	// ListUnaccessedFiles: returns the largest files not read for a while, to drive cleanup decisions
+	ListUnaccessedFiles(context.Context, *ListUnaccessedFilesRequest) (*ListUnaccessedFilesResponse, error)
+	// GetClusterStats: returns aggregate capacity, namespace and chunk server statistics of the cluster
+	GetClusterStats(context.Context, *GetClusterStatsRequest) (*GetClusterStatsResponse, error)
+	// PrepareAppend: returns the chunk records are appended to and its primary replica, creating the file or adding a chunk as needed
+	PrepareAppend(context.Context, *PrepareAppendRequest) (*PrepareAppendResponse, error)
+	// CompleteAppend: grows a file to cover a record appended to every replica of its chunk
+	CompleteAppend(context.Context, *CompleteAppendRequest) (*CompleteAppendResponse, error)
+	// GetGeoReplicationStatus: reports how far mirroring to the remote cluster lags behind
+	GetGeoReplicationStatus(context.Context, *GetGeoReplicationStatusRequest) (*GetGeoReplicationStatusResponse, error)
+	// ReclaimDeleted: deletes the replicas of deleted chunks now instead of after the reclaim delay
+	ReclaimDeleted(context.Context, *ReclaimDeletedRequest) (*ReclaimDeletedResponse, error)
+	// GetServerInfo: returns the master's software version, build commit and supported protocol features
+	GetServerInfo(context.Context, *GetServerInfoRequest) (*GetServerInfoResponse, error)
+	// PresignDownload: returns a url downloading the current version of a file over http until it expires,
+	// for sharing the file with systems that have no DFS client
+	PresignDownload(context.Context, *PresignDownloadRequest) (*PresignDownloadResponse, error)
+	// WhoAmI: returns the user and groups the master authenticated the caller as
+	WhoAmI(context.Context, *WhoAmIRequest) (*WhoAmIResponse, error)
+	// GetDelegationToken: mints a read-only token limited to a prefix, for batch jobs to use instead of the user's own token
+	GetDelegationToken(context.Context, *GetDelegationTokenRequest) (*GetDelegationTokenResponse, error)
+	// RenewDelegationToken: extends a delegation token, up to the maximum lifetime it was minted with
+	RenewDelegationToken(context.Context, *RenewDelegationTokenRequest) (*RenewDelegationTokenResponse, error)
+	// RevokeDelegationToken: stops the master accepting a delegation token and its renewals
+	RevokeDelegationToken(context.Context, *RevokeDelegationTokenRequest) (*RevokeDelegationTokenResponse, error)
+	// SetFileMode: changes the permission bits of a file, like chmod
+	SetFileMode(context.Context, *SetFileModeRequest) (*SetFileModeResponse, error)
+	// SetFileOwner: changes the owner or group of a file, like chown
+	SetFileOwner(context.Context, *SetFileOwnerRequest) (*SetFileOwnerResponse, error)
+	// SymlinkFile: adds a symlink that downloads, stat, copies and clones follow to another file
+	SymlinkFile(context.Context, *SymlinkFileRequest) (*SymlinkFileResponse, error)
+	// LinkFile: adds a hard link, another name sharing a file's chunks, owner and mode
+	LinkFile(context.Context, *LinkFileRequest) (*LinkFileResponse, error)
+	mustEmbedUnimplementedMasterServer()
+}
+
+// UnimplementedMasterServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedMasterServer struct{}
+
+func (UnimplementedMasterServer) UploadFile(context.Context, *UploadFileRequest) (*UploadFileResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UploadFile not implemented")
+}
+func (UnimplementedMasterServer) CompleteUpload(context.Context, *CompleteUploadRequest) (*CompleteUploadResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CompleteUpload not implemented")
+}
+func (UnimplementedMasterServer) RenewUpload(context.Context, *RenewUploadRequest) (*RenewUploadResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RenewUpload not implemented")
+}
+func (UnimplementedMasterServer) AllocateChunk(context.Context, *AllocateChunkRequest) (*AllocateChunkResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AllocateChunk not implemented")
+}
+func (UnimplementedMasterServer) DownloadFile(context.Context, *DownloadFileRequest) (*DownloadFileResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DownloadFile not implemented")
+}
+func (UnimplementedMasterServer) ListFiles(context.Context, *ListFilesRequest) (*ListFilesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListFiles not implemented")
+}
+func (UnimplementedMasterServer) ListFilesStream(*ListFilesRequest, grpc.ServerStreamingServer[ListFilesResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method ListFilesStream not implemented")
+}
+func (UnimplementedMasterServer) SearchFiles(context.Context, *SearchFilesRequest) (*SearchFilesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchFiles not implemented")
+}
+func (UnimplementedMasterServer) Heartbeat(context.Context, *HeartbeatRequest) (*HeartbeatResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Heartbeat not implemented")
+}
+func (UnimplementedMasterServer) RegisterChunkServer(context.Context, *RegisterChunkServerRequest) (*RegisterChunkServerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RegisterChunkServer not implemented")
+}
+func (UnimplementedMasterServer) ReportChunk(context.Context, *ReportChunkRequest) (*ReportChunkResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReportChunk not implemented")
+}
+func (UnimplementedMasterServer) CopyFile(context.Context, *CopyFileRequest) (*CopyFileResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CopyFile not implemented")
+}
+func (UnimplementedMasterServer) CloneFile(context.Context, *CloneFileRequest) (*CloneFileResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CloneFile not implemented")
+}
+func (UnimplementedMasterServer) RenameFile(context.Context, *RenameFileRequest) (*RenameFileResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RenameFile not implemented")
+}
+func (UnimplementedMasterServer) Watch(*WatchRequest, grpc.ServerStreamingServer[FileEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method Watch not implemented")
+}
+func (UnimplementedMasterServer) DeleteFile(context.Context, *DeleteFileRequest) (*DeleteFileResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteFile not implemented")
+}
+func (UnimplementedMasterServer) UndeleteFile(context.Context, *UndeleteFileRequest) (*UndeleteFileResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UndeleteFile not implemented")
+}
+func (UnimplementedMasterServer) GetFileInfo(context.Context, *GetFileInfoRequest) (*GetFileInfoResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetFileInfo not implemented")
+}
+func (UnimplementedMasterServer) UpdateFileTags(context.Context, *UpdateFileTagsRequest) (*UpdateFileTagsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateFileTags not implemented")
+}
+func (UnimplementedMasterServer) GetFileAttributes(context.Context, *GetFileAttributesRequest) (*GetFileAttributesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetFileAttributes not implemented")
+}
+func (UnimplementedMasterServer) SetFileAttributes(context.Context, *SetFileAttributesRequest) (*SetFileAttributesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetFileAttributes not implemented")
+}
+func (UnimplementedMasterServer) ListFileVersions(context.Context, *ListFileVersionsRequest) (*ListFileVersionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListFileVersions not implemented")
+}
+func (UnimplementedMasterServer) DiskUsage(context.Context, *DiskUsageRequest) (*DiskUsageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DiskUsage not implemented")
+}
+func (UnimplementedMasterServer) GetChunkDistribution(context.Context, *GetChunkDistributionRequest) (*GetChunkDistributionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetChunkDistribution not implemented")
+}
+func (UnimplementedMasterServer) ReportLostChunks(context.Context, *ReportLostChunksRequest) (*ReportLostChunksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReportLostChunks not implemented")
+}
+func (UnimplementedMasterServer) ReportCorruptChunk(context.Context, *ReportCorruptChunkRequest) (*ReportCorruptChunkResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReportCorruptChunk not implemented")
+}
+func (UnimplementedMasterServer) ListUnaccessedFiles(context.Context, *ListUnaccessedFilesRequest) (*ListUnaccessedFilesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListUnaccessedFiles not implemented")
+}
+func (UnimplementedMasterServer) GetClusterStats(context.Context, *GetClusterStatsRequest) (*GetClusterStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetClusterStats not implemented")
+}
+func (UnimplementedMasterServer) PrepareAppend(context.Context, *PrepareAppendRequest) (*PrepareAppendResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PrepareAppend not implemented")
+}
+func (UnimplementedMasterServer) CompleteAppend(context.Context, *CompleteAppendRequest) (*CompleteAppendResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CompleteAppend not implemented")
+}
+func (UnimplementedMasterServer) GetGeoReplicationStatus(context.Context, *GetGeoReplicationStatusRequest) (*GetGeoReplicationStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetGeoReplicationStatus not implemented")
+}
+func (UnimplementedMasterServer) ReclaimDeleted(context.Context, *ReclaimDeletedRequest) (*ReclaimDeletedResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReclaimDeleted not implemented")
+}
+func (UnimplementedMasterServer) GetServerInfo(context.Context, *GetServerInfoRequest) (*GetServerInfoResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetServerInfo not implemented")
+}
+func (UnimplementedMasterServer) PresignDownload(context.Context, *PresignDownloadRequest) (*PresignDownloadResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PresignDownload not implemented")
+}
+func (UnimplementedMasterServer) WhoAmI(context.Context, *WhoAmIRequest) (*WhoAmIResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method WhoAmI not implemented")
+}
+func (UnimplementedMasterServer) GetDelegationToken(context.Context, *GetDelegationTokenRequest) (*GetDelegationTokenResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDelegationToken not implemented")
+}
+func (UnimplementedMasterServer) RenewDelegationToken(context.Context, *RenewDelegationTokenRequest) (*RenewDelegationTokenResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RenewDelegationToken not implemented")
+}
+func (UnimplementedMasterServer) RevokeDelegationToken(context.Context, *RevokeDelegationTokenRequest) (*RevokeDelegationTokenResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeDelegationToken not implemented")
+}
+func (UnimplementedMasterServer) SetFileMode(context.Context, *SetFileModeRequest) (*SetFileModeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetFileMode not implemented")
+}
+func (UnimplementedMasterServer) SetFileOwner(context.Context, *SetFileOwnerRequest) (*SetFileOwnerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetFileOwner not implemented")
+}
+func (UnimplementedMasterServer) SymlinkFile(context.Context, *SymlinkFileRequest) (*SymlinkFileResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SymlinkFile not implemented")
+}
+func (UnimplementedMasterServer) LinkFile(context.Context, *LinkFileRequest) (*LinkFileResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LinkFile not implemented")
+}
+func (UnimplementedMasterServer) mustEmbedUnimplementedMasterServer() {}
+func (UnimplementedMasterServer) testEmbeddedByValue()                {}
+
+// UnsafeMasterServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to MasterServer will
+// result in compilation errors.
+type UnsafeMasterServer interface {
+	mustEmbedUnimplementedMasterServer()
+}
+
+func RegisterMasterServer(s grpc.ServiceRegistrar, srv MasterServer) {
+	// If the following call pancis, it indicates UnimplementedMasterServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Master_ServiceDesc, srv)
+}
+
+func _Master_UploadFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UploadFileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServer).UploadFile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Master_UploadFile_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).UploadFile(ctx, req.(*UploadFileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Master_CompleteUpload_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CompleteUploadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServer).CompleteUpload(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Master_CompleteUpload_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).CompleteUpload(ctx, req.(*CompleteUploadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Master_RenewUpload_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RenewUploadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServer).RenewUpload(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Master_RenewUpload_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).RenewUpload(ctx, req.(*RenewUploadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Master_AllocateChunk_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AllocateChunkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServer).AllocateChunk(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Master_AllocateChunk_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).AllocateChunk(ctx, req.(*AllocateChunkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Master_DownloadFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DownloadFileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServer).DownloadFile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Master_DownloadFile_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).DownloadFile(ctx, req.(*DownloadFileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Master_ListFiles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListFilesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServer).ListFiles(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Master_ListFiles_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).ListFiles(ctx, req.(*ListFilesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Master_ListFilesStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListFilesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MasterServer).ListFilesStream(m, &grpc.GenericServerStream[ListFilesRequest, ListFilesResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Master_ListFilesStreamServer = grpc.ServerStreamingServer[ListFilesResponse]
+
+func _Master_SearchFiles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchFilesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServer).SearchFiles(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Master_SearchFiles_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).SearchFiles(ctx, req.(*SearchFilesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Master_Heartbeat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HeartbeatRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServer).Heartbeat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Master_Heartbeat_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).Heartbeat(ctx, req.(*HeartbeatRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Master_RegisterChunkServer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterChunkServerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServer).RegisterChunkServer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Master_RegisterChunkServer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).RegisterChunkServer(ctx, req.(*RegisterChunkServerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Master_ReportChunk_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReportChunkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServer).ReportChunk(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Master_ReportChunk_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).ReportChunk(ctx, req.(*ReportChunkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Master_CopyFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CopyFileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServer).CopyFile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Master_CopyFile_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).CopyFile(ctx, req.(*CopyFileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Master_CloneFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CloneFileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServer).CloneFile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Master_CloneFile_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).CloneFile(ctx, req.(*CloneFileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Master_RenameFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RenameFileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServer).RenameFile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Master_RenameFile_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).RenameFile(ctx, req.(*RenameFileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Master_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MasterServer).Watch(m, &grpc.GenericServerStream[WatchRequest, FileEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Master_WatchServer = grpc.ServerStreamingServer[FileEvent]
+
+func _Master_DeleteFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteFileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServer).DeleteFile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Master_DeleteFile_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).DeleteFile(ctx, req.(*DeleteFileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Master_UndeleteFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UndeleteFileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServer).UndeleteFile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Master_UndeleteFile_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).UndeleteFile(ctx, req.(*UndeleteFileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Master_GetFileInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetFileInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServer).GetFileInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Master_GetFileInfo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).GetFileInfo(ctx, req.(*GetFileInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Master_UpdateFileTags_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateFileTagsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServer).UpdateFileTags(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Master_UpdateFileTags_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).UpdateFileTags(ctx, req.(*UpdateFileTagsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Master_GetFileAttributes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetFileAttributesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServer).GetFileAttributes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Master_GetFileAttributes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).GetFileAttributes(ctx, req.(*GetFileAttributesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Master_SetFileAttributes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetFileAttributesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServer).SetFileAttributes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Master_SetFileAttributes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).SetFileAttributes(ctx, req.(*SetFileAttributesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Master_ListFileVersions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListFileVersionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServer).ListFileVersions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Master_ListFileVersions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).ListFileVersions(ctx, req.(*ListFileVersionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Master_DiskUsage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DiskUsageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServer).DiskUsage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Master_DiskUsage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).DiskUsage(ctx, req.(*DiskUsageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Master_GetChunkDistribution_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetChunkDistributionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServer).GetChunkDistribution(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Master_GetChunkDistribution_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).GetChunkDistribution(ctx, req.(*GetChunkDistributionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Master_ReportLostChunks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReportLostChunksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServer).ReportLostChunks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Master_ReportLostChunks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).ReportLostChunks(ctx, req.(*ReportLostChunksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Master_ReportCorruptChunk_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReportCorruptChunkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServer).ReportCorruptChunk(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Master_ReportCorruptChunk_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).ReportCorruptChunk(ctx, req.(*ReportCorruptChunkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (c *masterClient) ListFiles(ctx context.Context, in *ListFilesRequest, opts ...grpc.CallOption) (*ListFilesResponse, error) {
-	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	out := new(ListFilesResponse)
-	err := c.cc.Invoke(ctx, Master_ListFiles_FullMethodName, in, out, cOpts...)
-	if err != nil {
+func _Master_ListUnaccessedFiles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListUnaccessedFilesRequest)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return out, nil
+	if interceptor == nil {
+		return srv.(MasterServer).ListUnaccessedFiles(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Master_ListUnaccessedFiles_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).ListUnaccessedFiles(ctx, req.(*ListUnaccessedFilesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (c *masterClient) Heartbeat(ctx context.Context, in *HeartbeatRequest, opts ...grpc.CallOption) (*HeartbeatResponse, error) {
-	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	out := new(HeartbeatResponse)
-	err := c.cc.Invoke(ctx, Master_Heartbeat_FullMethodName, in, out, cOpts...)
-	if err != nil {
+func _Master_GetClusterStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetClusterStatsRequest)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return out, nil
+	if interceptor == nil {
+		return srv.(MasterServer).GetClusterStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Master_GetClusterStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).GetClusterStats(ctx, req.(*GetClusterStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (c *masterClient) ReportChunk(ctx context.Context, in *ReportChunkRequest, opts ...grpc.CallOption) (*ReportChunkResponse, error) {
-	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	out := new(ReportChunkResponse)
-	err := c.cc.Invoke(ctx, Master_ReportChunk_FullMethodName, in, out, cOpts...)
-	if err != nil {
+func _Master_PrepareAppend_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PrepareAppendRequest)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return out, nil
+	if interceptor == nil {
+		return srv.(MasterServer).PrepareAppend(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Master_PrepareAppend_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).PrepareAppend(ctx, req.(*PrepareAppendRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-// MasterServer is the server API for Master service.
-// All implementations must embed UnimplementedMasterServer
-// for forward compatibility.
-//
-// Master Service: handles file metadata and chunk location
-type MasterServer interface {
-	// UploadFile: returns chunk handles and chunk server locations
-	UploadFile(context.Context, *UploadFileRequest) (*UploadFileResponse, error)
-	// DownloadFile: returns file metadata and chunk locations for download
-	DownloadFile(context.Context, *DownloadFileRequest) (*DownloadFileResponse, error)
-	// ListFiles: lists all the files in the system
-	ListFiles(context.Context, *ListFilesRequest) (*ListFilesResponse, error)
-	// Heartbeat: checks whether the chunk server is alive or not using heartbeats
-	Heartbeat(context.Context, *HeartbeatRequest) (*HeartbeatResponse, error)
-	// ReportChunk: reports chunk storage completion
-	ReportChunk(context.Context, *ReportChunkRequest) (*ReportChunkResponse, error)
-	mustEmbedUnimplementedMasterServer()
+func _Master_CompleteAppend_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CompleteAppendRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServer).CompleteAppend(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Master_CompleteAppend_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).CompleteAppend(ctx, req.(*CompleteAppendRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-// UnimplementedMasterServer must be embedded to have
-// forward compatible implementations.
-//
-// NOTE: this should be embedded by value instead of pointer to avoid a nil
-// pointer dereference when methods are called.
-type UnimplementedMasterServer struct{}
-
-func (UnimplementedMasterServer) UploadFile(context.Context, *UploadFileRequest) (*UploadFileResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method UploadFile not implemented")
+func _Master_GetGeoReplicationStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetGeoReplicationStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServer).GetGeoReplicationStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Master_GetGeoReplicationStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).GetGeoReplicationStatus(ctx, req.(*GetGeoReplicationStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
-func (UnimplementedMasterServer) DownloadFile(context.Context, *DownloadFileRequest) (*DownloadFileResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method DownloadFile not implemented")
+
+func _Master_ReclaimDeleted_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReclaimDeletedRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServer).ReclaimDeleted(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Master_ReclaimDeleted_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).ReclaimDeleted(ctx, req.(*ReclaimDeletedRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
-func (UnimplementedMasterServer) ListFiles(context.Context, *ListFilesRequest) (*ListFilesResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method ListFiles not implemented")
+
+func _Master_GetServerInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetServerInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServer).GetServerInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Master_GetServerInfo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).GetServerInfo(ctx, req.(*GetServerInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
-func (UnimplementedMasterServer) Heartbeat(context.Context, *HeartbeatRequest) (*HeartbeatResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Heartbeat not implemented")
+
+func _Master_PresignDownload_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PresignDownloadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServer).PresignDownload(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Master_PresignDownload_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).PresignDownload(ctx, req.(*PresignDownloadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
-func (UnimplementedMasterServer) ReportChunk(context.Context, *ReportChunkRequest) (*ReportChunkResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method ReportChunk not implemented")
+
+func _Master_WhoAmI_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WhoAmIRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServer).WhoAmI(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Master_WhoAmI_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).WhoAmI(ctx, req.(*WhoAmIRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
-func (UnimplementedMasterServer) mustEmbedUnimplementedMasterServer() {}
-func (UnimplementedMasterServer) testEmbeddedByValue()                {}
 
-// UnsafeMasterServer may be embedded to opt out of forward compatibility for this service.
-// Use of this interface is not recommended, as added methods to MasterServer will
-// result in compilation errors.
-type UnsafeMasterServer interface {
-	mustEmbedUnimplementedMasterServer()
+func _Master_GetDelegationToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDelegationTokenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServer).GetDelegationToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Master_GetDelegationToken_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).GetDelegationToken(ctx, req.(*GetDelegationTokenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func RegisterMasterServer(s grpc.ServiceRegistrar, srv MasterServer) {
-	// If the following call pancis, it indicates UnimplementedMasterServer was
-	// embedded by pointer and is nil.  This will cause panics if an
-	// unimplemented method is ever invoked, so we test this at initialization
-	// time to prevent it from happening at runtime later due to I/O.
-	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
-		t.testEmbeddedByValue()
+func _Master_RenewDelegationToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RenewDelegationTokenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
 	}
-	s.RegisterService(&Master_ServiceDesc, srv)
+	if interceptor == nil {
+		return srv.(MasterServer).RenewDelegationToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Master_RenewDelegationToken_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).RenewDelegationToken(ctx, req.(*RenewDelegationTokenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func _Master_UploadFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(UploadFileRequest)
+func _Master_RevokeDelegationToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevokeDelegationTokenRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(MasterServer).UploadFile(ctx, in)
+		return srv.(MasterServer).RevokeDelegationToken(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: Master_UploadFile_FullMethodName,
+		FullMethod: Master_RevokeDelegationToken_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(MasterServer).UploadFile(ctx, req.(*UploadFileRequest))
+		return srv.(MasterServer).RevokeDelegationToken(ctx, req.(*RevokeDelegationTokenRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Master_DownloadFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(DownloadFileRequest)
+func _Master_SetFileMode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetFileModeRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(MasterServer).DownloadFile(ctx, in)
+		return srv.(MasterServer).SetFileMode(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: Master_DownloadFile_FullMethodName,
+		FullMethod: Master_SetFileMode_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(MasterServer).DownloadFile(ctx, req.(*DownloadFileRequest))
+		return srv.(MasterServer).SetFileMode(ctx, req.(*SetFileModeRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Master_ListFiles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ListFilesRequest)
+func _Master_SetFileOwner_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetFileOwnerRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(MasterServer).ListFiles(ctx, in)
+		return srv.(MasterServer).SetFileOwner(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: Master_ListFiles_FullMethodName,
+		FullMethod: Master_SetFileOwner_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(MasterServer).ListFiles(ctx, req.(*ListFilesRequest))
+		return srv.(MasterServer).SetFileOwner(ctx, req.(*SetFileOwnerRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Master_Heartbeat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(HeartbeatRequest)
+func _Master_SymlinkFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SymlinkFileRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(MasterServer).Heartbeat(ctx, in)
+		return srv.(MasterServer).SymlinkFile(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: Master_Heartbeat_FullMethodName,
+		FullMethod: Master_SymlinkFile_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(MasterServer).Heartbeat(ctx, req.(*HeartbeatRequest))
+		return srv.(MasterServer).SymlinkFile(ctx, req.(*SymlinkFileRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Master_ReportChunk_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ReportChunkRequest)
+func _Master_LinkFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LinkFileRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(MasterServer).ReportChunk(ctx, in)
+		return srv.(MasterServer).LinkFile(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: Master_ReportChunk_FullMethodName,
+		FullMethod: Master_LinkFile_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(MasterServer).ReportChunk(ctx, req.(*ReportChunkRequest))
+		return srv.(MasterServer).LinkFile(ctx, req.(*LinkFileRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -263,33 +1603,193 @@ var Master_ServiceDesc = grpc.ServiceDesc{
 	HandlerType: (*MasterServer)(nil),
 	Methods: []grpc.MethodDesc{
 		{
-			MethodName: "UploadFile",
-			Handler:    _Master_UploadFile_Handler,
+			MethodName: "UploadFile",
+			Handler:    _Master_UploadFile_Handler,
+		},
+		{
+			MethodName: "CompleteUpload",
+			Handler:    _Master_CompleteUpload_Handler,
+		},
+		{
+			MethodName: "RenewUpload",
+			Handler:    _Master_RenewUpload_Handler,
+		},
+		{
+			MethodName: "AllocateChunk",
+			Handler:    _Master_AllocateChunk_Handler,
+		},
+		{
+			MethodName: "DownloadFile",
+			Handler:    _Master_DownloadFile_Handler,
+		},
+		{
+			MethodName: "ListFiles",
+			Handler:    _Master_ListFiles_Handler,
+		},
+		{
+			MethodName: "SearchFiles",
+			Handler:    _Master_SearchFiles_Handler,
+		},
+		{
+			MethodName: "Heartbeat",
+			Handler:    _Master_Heartbeat_Handler,
+		},
+		{
+			MethodName: "RegisterChunkServer",
+			Handler:    _Master_RegisterChunkServer_Handler,
+		},
+		{
+			MethodName: "ReportChunk",
+			Handler:    _Master_ReportChunk_Handler,
+		},
+		{
+			MethodName: "CopyFile",
+			Handler:    _Master_CopyFile_Handler,
+		},
+		{
+			MethodName: "CloneFile",
+			Handler:    _Master_CloneFile_Handler,
+		},
+		{
+			MethodName: "RenameFile",
+			Handler:    _Master_RenameFile_Handler,
+		},
+		{
+			MethodName: "DeleteFile",
+			Handler:    _Master_DeleteFile_Handler,
+		},
+		{
+			MethodName: "UndeleteFile",
+			Handler:    _Master_UndeleteFile_Handler,
+		},
+		{
+			MethodName: "GetFileInfo",
+			Handler:    _Master_GetFileInfo_Handler,
+		},
+		{
+			MethodName: "UpdateFileTags",
+			Handler:    _Master_UpdateFileTags_Handler,
 		},
 		{
-			MethodName: "DownloadFile",
-			Handler:    _Master_DownloadFile_Handler,
+			MethodName: "GetFileAttributes",
+			Handler:    _Master_GetFileAttributes_Handler,
 		},
 		{
-			MethodName: "ListFiles",
-			Handler:    _Master_ListFiles_Handler,
+			MethodName: "SetFileAttributes",
+			Handler:    _Master_SetFileAttributes_Handler,
 		},
 		{
-			MethodName: "Heartbeat",
-			Handler:    _Master_Heartbeat_Handler,
+			MethodName: "ListFileVersions",
+			Handler:    _Master_ListFileVersions_Handler,
 		},
 		{
-			MethodName: "ReportChunk",
-			Handler:    _Master_ReportChunk_Handler,
+			MethodName: "DiskUsage",
+			Handler:    _Master_DiskUsage_Handler,
+		},
+		{
+			MethodName: "GetChunkDistribution",
+			Handler:    _Master_GetChunkDistribution_Handler,
+		},
+		{
+			MethodName: "ReportLostChunks",
+			Handler:    _Master_ReportLostChunks_Handler,
+		},
+		{
+			MethodName: "ReportCorruptChunk",
+			Handler:    _Master_ReportCorruptChunk_Handler,
+		},
+		{
+			MethodName: "ListUnaccessedFiles",
+			Handler:    _Master_ListUnaccessedFiles_Handler,
+		},
+		{
+			MethodName: "GetClusterStats",
+			Handler:    _Master_GetClusterStats_Handler,
+		},
+		{
+			MethodName: "PrepareAppend",
+			Handler:    _Master_PrepareAppend_Handler,
+		},
+		{
+			MethodName: "CompleteAppend",
+			Handler:    _Master_CompleteAppend_Handler,
+		},
+		{
+			MethodName: "GetGeoReplicationStatus",
+			Handler:    _Master_GetGeoReplicationStatus_Handler,
+		},
+		{
+			MethodName: "ReclaimDeleted",
+			Handler:    _Master_ReclaimDeleted_Handler,
+		},
+		{
+			MethodName: "GetServerInfo",
+			Handler:    _Master_GetServerInfo_Handler,
+		},
+		{
+			MethodName: "PresignDownload",
+			Handler:    _Master_PresignDownload_Handler,
+		},
+		{
+			MethodName: "WhoAmI",
+			Handler:    _Master_WhoAmI_Handler,
+		},
+		{
+			MethodName: "GetDelegationToken",
+			Handler:    _Master_GetDelegationToken_Handler,
+		},
+		{
+			MethodName: "RenewDelegationToken",
+			Handler:    _Master_RenewDelegationToken_Handler,
+		},
+		{
+			MethodName: "RevokeDelegationToken",
+			Handler:    _Master_RevokeDelegationToken_Handler,
+		},
+		{
+			MethodName: "SetFileMode",
+			Handler:    _Master_SetFileMode_Handler,
+		},
+		{
+			MethodName: "SetFileOwner",
+			Handler:    _Master_SetFileOwner_Handler,
+		},
+		{
+			MethodName: "SymlinkFile",
+			Handler:    _Master_SymlinkFile_Handler,
+		},
+		{
+			MethodName: "LinkFile",
+			Handler:    _Master_LinkFile_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ListFilesStream",
+			Handler:       _Master_ListFilesStream_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Watch",
+			Handler:       _Master_Watch_Handler,
+			ServerStreams: true,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/dfs.proto",
 }
 
 const (
-	ChunkServer_WriteChunk_FullMethodName = "/dfs.ChunkServer/WriteChunk"
-	ChunkServer_ReadChunk_FullMethodName  = "/dfs.ChunkServer/ReadChunk"
+	ChunkServer_WriteChunk_FullMethodName      = "/dfs.ChunkServer/WriteChunk"
+	ChunkServer_ReadChunk_FullMethodName       = "/dfs.ChunkServer/ReadChunk"
+	ChunkServer_ReadChunkStream_FullMethodName = "/dfs.ChunkServer/ReadChunkStream"
+	ChunkServer_CopyChunk_FullMethodName       = "/dfs.ChunkServer/CopyChunk"
+	ChunkServer_DeleteChunk_FullMethodName     = "/dfs.ChunkServer/DeleteChunk"
+	ChunkServer_ReplicateChunk_FullMethodName  = "/dfs.ChunkServer/ReplicateChunk"
+	ChunkServer_RecordAppend_FullMethodName    = "/dfs.ChunkServer/RecordAppend"
+	ChunkServer_ApplyAppend_FullMethodName     = "/dfs.ChunkServer/ApplyAppend"
+	ChunkServer_GetServerInfo_FullMethodName   = "/dfs.ChunkServer/GetServerInfo"
+	ChunkServer_RecallChunks_FullMethodName    = "/dfs.ChunkServer/RecallChunks"
+	ChunkServer_VerifyChunk_FullMethodName     = "/dfs.ChunkServer/VerifyChunk"
 )
 
 // ChunkServerClient is the client API for ChunkServer service.
@@ -302,6 +1802,24 @@ type ChunkServerClient interface {
 	WriteChunk(ctx context.Context, in *WriteChunkRequest, opts ...grpc.CallOption) (*WriteChunkResponse, error)
 	// ReadChunk: reads a chunk from the provided server
 	ReadChunk(ctx context.Context, in *ReadChunkRequest, opts ...grpc.CallOption) (*ReadChunkResponse, error)
+	// ReadChunkStream: streams a chunk in fixed size pieces instead of one message holding the whole chunk
+	ReadChunkStream(ctx context.Context, in *ReadChunkRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ReadChunkResponse], error)
+	// CopyChunk: copies a locally stored chunk to a new chunk handle
+	CopyChunk(ctx context.Context, in *CopyChunkRequest, opts ...grpc.CallOption) (*CopyChunkResponse, error)
+	// DeleteChunk: deletes a chunk from the provided server
+	DeleteChunk(ctx context.Context, in *DeleteChunkRequest, opts ...grpc.CallOption) (*DeleteChunkResponse, error)
+	// ReplicateChunk: pulls a chunk directly from a peer chunk server and stores it locally
+	ReplicateChunk(ctx context.Context, in *ReplicateChunkRequest, opts ...grpc.CallOption) (*ReplicateChunkResponse, error)
+	// RecordAppend: appends a record at an offset chosen by this server as the chunk's primary and writes it to the secondaries
+	RecordAppend(ctx context.Context, in *RecordAppendRequest, opts ...grpc.CallOption) (*RecordAppendResponse, error)
+	// ApplyAppend: writes a record at the offset chosen by the primary, sent by the primary to the secondaries
+	ApplyAppend(ctx context.Context, in *ApplyAppendRequest, opts ...grpc.CallOption) (*ApplyAppendResponse, error)
+	// GetServerInfo: returns the chunk server's software version, build commit and supported protocol features
+	GetServerInfo(ctx context.Context, in *GetServerInfoRequest, opts ...grpc.CallOption) (*GetServerInfoResponse, error)
+	// RecallChunks: decompresses the chunks of an archived file into new chunks written to their replicas, run for the master
+	RecallChunks(ctx context.Context, in *RecallChunksRequest, opts ...grpc.CallOption) (*RecallChunksResponse, error)
+	// VerifyChunk: checks the stored chunk against its checksum, dropping a corrupt replica, run for the master
+	VerifyChunk(ctx context.Context, in *VerifyChunkRequest, opts ...grpc.CallOption) (*VerifyChunkResponse, error)
 }
 
 type chunkServerClient struct {
@@ -332,6 +1850,105 @@ func (c *chunkServerClient) ReadChunk(ctx context.Context, in *ReadChunkRequest,
 	return out, nil
 }
 
+func (c *chunkServerClient) ReadChunkStream(ctx context.Context, in *ReadChunkRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ReadChunkResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ChunkServer_ServiceDesc.Streams[0], ChunkServer_ReadChunkStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ReadChunkRequest, ReadChunkResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ChunkServer_ReadChunkStreamClient = grpc.ServerStreamingClient[ReadChunkResponse]
+
+func (c *chunkServerClient) CopyChunk(ctx context.Context, in *CopyChunkRequest, opts ...grpc.CallOption) (*CopyChunkResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CopyChunkResponse)
+	err := c.cc.Invoke(ctx, ChunkServer_CopyChunk_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chunkServerClient) DeleteChunk(ctx context.Context, in *DeleteChunkRequest, opts ...grpc.CallOption) (*DeleteChunkResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteChunkResponse)
+	err := c.cc.Invoke(ctx, ChunkServer_DeleteChunk_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chunkServerClient) ReplicateChunk(ctx context.Context, in *ReplicateChunkRequest, opts ...grpc.CallOption) (*ReplicateChunkResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReplicateChunkResponse)
+	err := c.cc.Invoke(ctx, ChunkServer_ReplicateChunk_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chunkServerClient) RecordAppend(ctx context.Context, in *RecordAppendRequest, opts ...grpc.CallOption) (*RecordAppendResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RecordAppendResponse)
+	err := c.cc.Invoke(ctx, ChunkServer_RecordAppend_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chunkServerClient) ApplyAppend(ctx context.Context, in *ApplyAppendRequest, opts ...grpc.CallOption) (*ApplyAppendResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ApplyAppendResponse)
+	err := c.cc.Invoke(ctx, ChunkServer_ApplyAppend_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chunkServerClient) GetServerInfo(ctx context.Context, in *GetServerInfoRequest, opts ...grpc.CallOption) (*GetServerInfoResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetServerInfoResponse)
+	err := c.cc.Invoke(ctx, ChunkServer_GetServerInfo_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chunkServerClient) RecallChunks(ctx context.Context, in *RecallChunksRequest, opts ...grpc.CallOption) (*RecallChunksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RecallChunksResponse)
+	err := c.cc.Invoke(ctx, ChunkServer_RecallChunks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chunkServerClient) VerifyChunk(ctx context.Context, in *VerifyChunkRequest, opts ...grpc.CallOption) (*VerifyChunkResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(VerifyChunkResponse)
+	err := c.cc.Invoke(ctx, ChunkServer_VerifyChunk_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ChunkServerServer is the server API for ChunkServer service.
 // All implementations must embed UnimplementedChunkServerServer
 // for forward compatibility.
@@ -342,6 +1959,24 @@ type ChunkServerServer interface {
 	WriteChunk(context.Context, *WriteChunkRequest) (*WriteChunkResponse, error)
 	// ReadChunk: reads a chunk from the provided server
 	ReadChunk(context.Context, *ReadChunkRequest) (*ReadChunkResponse, error)
+	// ReadChunkStream: streams a chunk in fixed size pieces instead of one message holding the whole chunk
+	ReadChunkStream(*ReadChunkRequest, grpc.ServerStreamingServer[ReadChunkResponse]) error
+	// CopyChunk: copies a locally stored chunk to a new chunk handle
+	CopyChunk(context.Context, *CopyChunkRequest) (*CopyChunkResponse, error)
+	// DeleteChunk: deletes a chunk from the provided server
+	DeleteChunk(context.Context, *DeleteChunkRequest) (*DeleteChunkResponse, error)
+	// ReplicateChunk: pulls a chunk directly from a peer chunk server and stores it locally
+	ReplicateChunk(context.Context, *ReplicateChunkRequest) (*ReplicateChunkResponse, error)
+	// RecordAppend: appends a record at an offset chosen by this server as the chunk's primary and writes it to the secondaries
+	RecordAppend(context.Context, *RecordAppendRequest) (*RecordAppendResponse, error)
+	// ApplyAppend: writes a record at the offset chosen by the primary, sent by the primary to the secondaries
+	ApplyAppend(context.Context, *ApplyAppendRequest) (*ApplyAppendResponse, error)
+	// GetServerInfo: returns the chunk server's software version, build commit and supported protocol features
+	GetServerInfo(context.Context, *GetServerInfoRequest) (*GetServerInfoResponse, error)
+	// RecallChunks: decompresses the chunks of an archived file into new chunks written to their replicas, run for the master
+	RecallChunks(context.Context, *RecallChunksRequest) (*RecallChunksResponse, error)
+	// VerifyChunk: checks the stored chunk against its checksum, dropping a corrupt replica, run for the master
+	VerifyChunk(context.Context, *VerifyChunkRequest) (*VerifyChunkResponse, error)
 	mustEmbedUnimplementedChunkServerServer()
 }
 
@@ -358,6 +1993,33 @@ func (UnimplementedChunkServerServer) WriteChunk(context.Context, *WriteChunkReq
 func (UnimplementedChunkServerServer) ReadChunk(context.Context, *ReadChunkRequest) (*ReadChunkResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ReadChunk not implemented")
 }
+func (UnimplementedChunkServerServer) ReadChunkStream(*ReadChunkRequest, grpc.ServerStreamingServer[ReadChunkResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method ReadChunkStream not implemented")
+}
+func (UnimplementedChunkServerServer) CopyChunk(context.Context, *CopyChunkRequest) (*CopyChunkResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CopyChunk not implemented")
+}
+func (UnimplementedChunkServerServer) DeleteChunk(context.Context, *DeleteChunkRequest) (*DeleteChunkResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteChunk not implemented")
+}
+func (UnimplementedChunkServerServer) ReplicateChunk(context.Context, *ReplicateChunkRequest) (*ReplicateChunkResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReplicateChunk not implemented")
+}
+func (UnimplementedChunkServerServer) RecordAppend(context.Context, *RecordAppendRequest) (*RecordAppendResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RecordAppend not implemented")
+}
+func (UnimplementedChunkServerServer) ApplyAppend(context.Context, *ApplyAppendRequest) (*ApplyAppendResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ApplyAppend not implemented")
+}
+func (UnimplementedChunkServerServer) GetServerInfo(context.Context, *GetServerInfoRequest) (*GetServerInfoResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetServerInfo not implemented")
+}
+func (UnimplementedChunkServerServer) RecallChunks(context.Context, *RecallChunksRequest) (*RecallChunksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RecallChunks not implemented")
+}
+func (UnimplementedChunkServerServer) VerifyChunk(context.Context, *VerifyChunkRequest) (*VerifyChunkResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method VerifyChunk not implemented")
+}
 func (UnimplementedChunkServerServer) mustEmbedUnimplementedChunkServerServer() {}
 func (UnimplementedChunkServerServer) testEmbeddedByValue()                     {}
 
@@ -415,6 +2077,161 @@ func _ChunkServer_ReadChunk_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ChunkServer_ReadChunkStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ReadChunkRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ChunkServerServer).ReadChunkStream(m, &grpc.GenericServerStream[ReadChunkRequest, ReadChunkResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ChunkServer_ReadChunkStreamServer = grpc.ServerStreamingServer[ReadChunkResponse]
+
+func _ChunkServer_CopyChunk_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CopyChunkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChunkServerServer).CopyChunk(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChunkServer_CopyChunk_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChunkServerServer).CopyChunk(ctx, req.(*CopyChunkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChunkServer_DeleteChunk_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteChunkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChunkServerServer).DeleteChunk(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChunkServer_DeleteChunk_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChunkServerServer).DeleteChunk(ctx, req.(*DeleteChunkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChunkServer_ReplicateChunk_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReplicateChunkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChunkServerServer).ReplicateChunk(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChunkServer_ReplicateChunk_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChunkServerServer).ReplicateChunk(ctx, req.(*ReplicateChunkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChunkServer_RecordAppend_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RecordAppendRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChunkServerServer).RecordAppend(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChunkServer_RecordAppend_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChunkServerServer).RecordAppend(ctx, req.(*RecordAppendRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChunkServer_ApplyAppend_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ApplyAppendRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChunkServerServer).ApplyAppend(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChunkServer_ApplyAppend_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChunkServerServer).ApplyAppend(ctx, req.(*ApplyAppendRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChunkServer_GetServerInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetServerInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChunkServerServer).GetServerInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChunkServer_GetServerInfo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChunkServerServer).GetServerInfo(ctx, req.(*GetServerInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChunkServer_RecallChunks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RecallChunksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChunkServerServer).RecallChunks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChunkServer_RecallChunks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChunkServerServer).RecallChunks(ctx, req.(*RecallChunksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChunkServer_VerifyChunk_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyChunkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChunkServerServer).VerifyChunk(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChunkServer_VerifyChunk_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChunkServerServer).VerifyChunk(ctx, req.(*VerifyChunkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // ChunkServer_ServiceDesc is the grpc.ServiceDesc for ChunkServer service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -430,7 +2247,45 @@ var ChunkServer_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ReadChunk",
 			Handler:    _ChunkServer_ReadChunk_Handler,
 		},
+		{
+			MethodName: "CopyChunk",
+			Handler:    _ChunkServer_CopyChunk_Handler,
+		},
+		{
+			MethodName: "DeleteChunk",
+			Handler:    _ChunkServer_DeleteChunk_Handler,
+		},
+		{
+			MethodName: "ReplicateChunk",
+			Handler:    _ChunkServer_ReplicateChunk_Handler,
+		},
+		{
+			MethodName: "RecordAppend",
+			Handler:    _ChunkServer_RecordAppend_Handler,
+		},
+		{
+			MethodName: "ApplyAppend",
+			Handler:    _ChunkServer_ApplyAppend_Handler,
+		},
+		{
+			MethodName: "GetServerInfo",
+			Handler:    _ChunkServer_GetServerInfo_Handler,
+		},
+		{
+			MethodName: "RecallChunks",
+			Handler:    _ChunkServer_RecallChunks_Handler,
+		},
+		{
+			MethodName: "VerifyChunk",
+			Handler:    _ChunkServer_VerifyChunk_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ReadChunkStream",
+			Handler:       _ChunkServer_ReadChunkStream_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/dfs.proto",
 }
@@ -0,0 +1,154 @@
+// Package testutil runs a whole DFS cluster inside a test process, so tests can exercise uploads,
+// downloads and recovery without launching the master and chunk server binaries
+package testutil
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/harshvardha/distributed_file_system/chunkserver"
+	"github.com/harshvardha/distributed_file_system/client"
+	"github.com/harshvardha/distributed_file_system/common"
+	"github.com/harshvardha/distributed_file_system/master"
+)
+
+// readyTimeout is how long StartCluster waits for the chunk servers to register with the master
+const readyTimeout = 10 * time.Second
+
+// Options configures a test cluster, the zero value starting a master and common.ReplicationFactor chunk servers
+type Options struct {
+	ChunkServers  int                   // chunk servers to start, common.ReplicationFactor when 0
+	Master        master.Config         // master settings, the metadata is kept in memory unless set
+	ChunkServer   chunkserver.Config    // settings of every chunk server, chunks aren't fsynced unless set
+	ClientOptions []client.ClientOption // options of Cluster.Client
+}
+
+// Cluster is a master and its chunk servers listening on ephemeral localhost ports, with chunks
+// stored in temporary directories removed when the test ends
+type Cluster struct {
+	MasterAddress        string
+	Master               *master.Server
+	ChunkServerAddresses []string
+	ChunkServers         []*chunkserver.Server
+	Client               *client.Client // client of the cluster, closed when the test ends
+
+	t       testing.TB
+	serving sync.WaitGroup // goroutines running the servers
+	errs    chan error     // servers failing on their own, reported on the test's goroutine by stop
+}
+
+// StartCluster starts a cluster and waits until every chunk server has registered with the master.
+// The cluster is stopped when the test and its subtests end
+func StartCluster(t testing.TB, options Options) *Cluster {
+	t.Helper()
+
+	if options.ChunkServers == 0 {
+		options.ChunkServers = common.ReplicationFactor
+	}
+	if options.Master.MetadataBackend == "" {
+		options.Master.MetadataBackend = master.BackendMemory
+	}
+	if options.ChunkServer.SyncMode == "" {
+		options.ChunkServer.SyncMode = chunkserver.SyncNone
+	}
+
+	cluster := &Cluster{t: t, errs: make(chan error, options.ChunkServers+1)}
+	t.Cleanup(cluster.stop)
+
+	listener := listenLocal(t)
+	cluster.MasterAddress = listener.Addr().String()
+	masterServer, err := master.NewServer(cluster.MasterAddress, options.Master)
+	if err != nil {
+		listener.Close()
+		t.Fatalf("failed to create master: %v", err)
+	}
+	cluster.Master = masterServer
+	cluster.serve("master", func() error { return masterServer.Serve(listener) })
+
+	for i := range options.ChunkServers {
+		listener := listenLocal(t)
+		address := listener.Addr().String()
+
+		server, err := chunkserver.NewServer(address, []string{t.TempDir()}, cluster.MasterAddress, options.ChunkServer)
+		if err != nil {
+			listener.Close()
+			t.Fatalf("failed to create chunk server %d: %v", i, err)
+		}
+		cluster.ChunkServerAddresses = append(cluster.ChunkServerAddresses, address)
+		cluster.ChunkServers = append(cluster.ChunkServers, server)
+		cluster.serve("chunk server "+address, func() error { return server.Serve(listener) })
+	}
+
+	cluster.Client = client.NewClient(cluster.MasterAddress, options.ClientOptions...)
+	if err := cluster.WaitForChunkServers(options.ChunkServers, readyTimeout); err != nil {
+		t.Fatal(err)
+	}
+
+	return cluster
+}
+
+// WaitForChunkServers waits until the master counts count live chunk servers, e.g. after
+// stopping one of them, which the master only declares dead once its heartbeats time out
+func (c *Cluster) WaitForChunkServers(count int, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	live := int32(0)
+	for {
+		stats, err := c.Client.GetClusterStats()
+		if err == nil {
+			live = stats.LiveChunkServers
+			if int(live) == count {
+				return nil
+			}
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%d of %d chunk servers live after %s: %v", live, count, timeout, err)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// stop stops the client and every server, waiting for them to return and reporting those that failed
+func (c *Cluster) stop() {
+	if c.Client != nil {
+		c.Client.Close()
+	}
+	for _, server := range c.ChunkServers {
+		server.Stop()
+	}
+	if c.Master != nil {
+		c.Master.Stop()
+	}
+
+	c.serving.Wait()
+	close(c.errs)
+	for err := range c.errs {
+		c.t.Errorf("%v", err)
+	}
+}
+
+// listenLocal binds an ephemeral localhost port, so the address is known before the server starts
+func listenLocal(t testing.TB) net.Listener {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	return listener
+}
+
+// serve runs a server in the background until it is stopped, passing servers failing on their own to stop,
+// as only the test's goroutine may fail the test
+func (c *Cluster) serve(name string, run func() error) {
+	c.serving.Add(1)
+	go func() {
+		defer c.serving.Done()
+		if err := run(); err != nil {
+			c.errs <- fmt.Errorf("%s failed: %v", name, err)
+		}
+	}()
+}
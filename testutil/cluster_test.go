@@ -0,0 +1,270 @@
+package testutil_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harshvardha/distributed_file_system/client"
+	"github.com/harshvardha/distributed_file_system/common"
+	"github.com/harshvardha/distributed_file_system/master"
+	pb "github.com/harshvardha/distributed_file_system/proto"
+	"github.com/harshvardha/distributed_file_system/testutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+func TestClusterUploadDownload(t *testing.T) {
+	cluster := testutil.StartCluster(t, testutil.Options{})
+
+	// spans two chunks, the second one partial
+	data := make([]byte, common.ChunkSize+1234)
+	rand.New(rand.NewSource(1)).Read(data)
+	if err := cluster.Client.UploadReader(bytes.NewReader(data), "data.bin"); err != nil {
+		t.Fatalf("failed to upload: %v", err)
+	}
+
+	var downloaded bytes.Buffer
+	if err := cluster.Client.Download("data.bin", &downloaded); err != nil {
+		t.Fatalf("failed to download: %v", err)
+	}
+	if !bytes.Equal(downloaded.Bytes(), data) {
+		t.Fatalf("downloaded %d bytes differing from the %d uploaded", downloaded.Len(), len(data))
+	}
+}
+
+func TestClusterGeoReplication(t *testing.T) {
+	remote := testutil.StartCluster(t, testutil.Options{})
+	local := testutil.StartCluster(t, testutil.Options{Master: master.Config{
+		GeoReplication: master.GeoReplicationConfig{
+			RemoteMaster: remote.MasterAddress,
+			ScanInterval: 100 * time.Millisecond,
+		},
+	}})
+
+	data := []byte("mirrored to the remote cluster")
+	if err := local.Client.UploadReader(bytes.NewReader(data), "mirrored.txt"); err != nil {
+		t.Fatalf("failed to upload: %v", err)
+	}
+
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		var downloaded bytes.Buffer
+		err := remote.Client.Download("mirrored.txt", &downloaded)
+		if err == nil && bytes.Equal(downloaded.Bytes(), data) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("file not mirrored to the remote cluster: %v", err)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	// the local master is stopped first, with its replicator still connected to the remote cluster
+}
+
+// userIdentities authenticates every token as the user it names
+type userIdentities struct{}
+
+func (userIdentities) Authenticate(ctx context.Context, token string) (*master.Identity, error) {
+	return &master.Identity{User: token}, nil
+}
+
+// startSecuredCluster starts a cluster whose master and chunk servers share a chunk token key, returning the
+// signer of that key along with it
+func startSecuredCluster(t *testing.T, options testutil.Options) (*testutil.Cluster, *common.ChunkTokens) {
+	t.Helper()
+
+	tokens, err := common.NewChunkTokens([]byte("test chunk token key"), 0)
+	if err != nil {
+		t.Fatalf("failed to create chunk tokens: %v", err)
+	}
+	options.Master.ChunkTokens = tokens
+	options.ChunkServer.ChunkTokens = tokens
+
+	return testutil.StartCluster(t, options), tokens
+}
+
+// dial connects to a server of the cluster, closing the connection when the test ends
+func dial(t *testing.T, address string) *grpc.ClientConn {
+	t.Helper()
+
+	conn, err := grpc.NewClient(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to connect to %s: %v", address, err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return conn
+}
+
+// upload uploads data as filename and returns the location of its only chunk
+func upload(t *testing.T, c *client.Client, filename string, data []byte) *pb.ChunkLocation {
+	t.Helper()
+
+	if err := c.UploadReader(bytes.NewReader(data), filename); err != nil {
+		t.Fatalf("failed to upload %s: %v", filename, err)
+	}
+	info, err := c.GetFileInfo(filename)
+	if err != nil {
+		t.Fatalf("failed to stat %s: %v", filename, err)
+	}
+	if len(info.ChunkLocations) != 1 {
+		t.Fatalf("%s has %d chunks, expected 1", filename, len(info.ChunkLocations))
+	}
+
+	return info.ChunkLocations[0]
+}
+
+func TestClusterRejectsWritesWithoutLease(t *testing.T) {
+	cluster, tokens := startSecuredCluster(t, testutil.Options{})
+
+	data := []byte("written through the primary")
+	location := upload(t, cluster.Client, "leased.txt", data)
+
+	expired := time.Now().Add(-time.Minute)
+	for _, address := range location.ChunkServerAddresses {
+		chunkClient := pb.NewChunkServerClient(dial(t, address))
+		for name, req := range map[string]*pb.WriteChunkRequest{
+			"no lease": {},
+			"expired lease": {
+				LeaseExpiresAt: expired.UnixNano(),
+				LeaseToken:     tokens.SignLease(location.ChunkHandle, address, location.ChunkVersion, expired),
+			},
+		} {
+			req.ChunkHandle = location.ChunkHandle
+			req.ChunkVersion = location.ChunkVersion
+			req.Data = []byte("written past the primary")
+			req.Overwrite = true
+			req.AccessToken = tokens.Sign(location.ChunkHandle, common.ChunkWrite)
+
+			_, err := chunkClient.WriteChunk(context.Background(), req)
+			if status.Code(err) != codes.FailedPrecondition {
+				t.Errorf("write with %s to %s returned %v, expected FailedPrecondition", name, address, err)
+			}
+		}
+	}
+
+	var downloaded bytes.Buffer
+	if err := cluster.Client.Download("leased.txt", &downloaded); err != nil {
+		t.Fatalf("failed to download: %v", err)
+	}
+	if !bytes.Equal(downloaded.Bytes(), data) {
+		t.Fatalf("downloaded %q, expected %q", downloaded.Bytes(), data)
+	}
+}
+
+func TestClusterKeepsLastReplicaReportedCorrupt(t *testing.T) {
+	cluster := testutil.StartCluster(t, testutil.Options{ChunkServers: 1})
+
+	location := upload(t, cluster.Client, "single.txt", []byte("only one replica"))
+	address := cluster.ChunkServerAddresses[0]
+
+	// the replica is gone, so the master's verification finds it corrupt
+	_, err := pb.NewChunkServerClient(dial(t, address)).DeleteChunk(context.Background(), &pb.DeleteChunkRequest{ChunkHandle: location.ChunkHandle})
+	if err != nil {
+		t.Fatalf("failed to delete the replica: %v", err)
+	}
+
+	_, err = pb.NewMasterClient(dial(t, cluster.MasterAddress)).ReportCorruptChunk(context.Background(), &pb.ReportCorruptChunkRequest{
+		ChunkServerAddress: address,
+		ChunkHandle:        location.ChunkHandle,
+		Reason:             "test",
+	})
+	if err != nil {
+		t.Fatalf("failed to report the replica: %v", err)
+	}
+
+	info, err := cluster.Client.GetFileInfo("single.txt")
+	if err != nil {
+		t.Fatalf("failed to stat: %v", err)
+	}
+	if locations := info.ChunkLocations[0].ChunkServerAddresses; !slices.Equal(locations, []string{address}) {
+		t.Fatalf("chunk located at %v after the report, expected %s", locations, address)
+	}
+}
+
+func TestClusterDelegationTokenScopedToPrefix(t *testing.T) {
+	cluster, _ := startSecuredCluster(t, testutil.Options{
+		Master:        master.Config{Identities: userIdentities{}},
+		ClientOptions: []client.ClientOption{client.WithToken("alice")},
+	})
+
+	inside := upload(t, cluster.Client, "jobs/input.txt", []byte("read by the job"))
+	outside := upload(t, cluster.Client, "private/notes.txt", []byte("not for the job"))
+
+	delegation, err := cluster.Client.GetDelegationToken("jobs/", time.Hour, 0)
+	if err != nil {
+		t.Fatalf("failed to get a delegation token: %v", err)
+	}
+	job := client.NewClient(cluster.MasterAddress, client.WithToken(delegation.Token))
+	t.Cleanup(func() { job.Close() })
+
+	var downloaded bytes.Buffer
+	if err := job.Download("jobs/input.txt", &downloaded); err != nil {
+		t.Fatalf("failed to download inside the prefix: %v", err)
+	}
+	if _, err := job.GetFileInfo("private/notes.txt"); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("stat outside the prefix returned %v, expected PermissionDenied", err)
+	}
+
+	// the read token of a chunk inside the prefix doesn't read chunks outside it
+	jobLocations, err := job.GetFileInfo("jobs/input.txt")
+	if err != nil {
+		t.Fatalf("failed to stat inside the prefix: %v", err)
+	}
+	token := jobLocations.ChunkLocations[0].AccessToken
+	if jobLocations.ChunkLocations[0].ChunkHandle != inside.ChunkHandle {
+		t.Fatalf("stat returned chunk %s, expected %s", jobLocations.ChunkLocations[0].ChunkHandle, inside.ChunkHandle)
+	}
+	for _, address := range outside.ChunkServerAddresses {
+		_, err := pb.NewChunkServerClient(dial(t, address)).ReadChunk(context.Background(), &pb.ReadChunkRequest{
+			ChunkHandle: outside.ChunkHandle,
+			AccessToken: token,
+		})
+		if status.Code(err) != codes.PermissionDenied {
+			t.Errorf("read of a chunk outside the prefix from %s returned %v, expected PermissionDenied", address, err)
+		}
+	}
+}
+
+func TestClusterMastersShareEtcdNamespace(t *testing.T) {
+	endpoints := os.Getenv("DFS_TEST_ETCD_ENDPOINTS")
+	if endpoints == "" {
+		t.Skip("DFS_TEST_ETCD_ENDPOINTS names no etcd cluster to share")
+	}
+
+	config := master.Config{
+		MetadataBackend: master.BackendEtcd,
+		Etcd: master.EtcdConfig{
+			Endpoints: strings.Split(endpoints, ","),
+			Prefix:    fmt.Sprintf("/dfs-test-%d/", time.Now().UnixNano()),
+		},
+	}
+	first := testutil.StartCluster(t, testutil.Options{Master: config})
+	second := testutil.StartCluster(t, testutil.Options{Master: config})
+
+	if err := first.Client.UploadReader(bytes.NewReader([]byte("written on the first master")), "shared.txt"); err != nil {
+		t.Fatalf("failed to upload: %v", err)
+	}
+
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		files, err := second.Client.ListFiles()
+		if err == nil && slices.ContainsFunc(files, func(file *pb.FileInfo) bool { return file.Filename == "shared.txt" }) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("second master doesn't list the file: %v", err)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}